@@ -12,8 +12,21 @@ import (
 )
 
 // GetAlbumsAlphabetical returns a paginated list of albums sorted alphabetically.
-// Supports query parameters: page (default 1), per_page (default 24, max 48)
+// Supports query parameters: page (default 1), per_page (default 24, max 48),
+// compilations (only or exclude, default both)
 func (app *Application) GetAlbumsAlphabetical(w http.ResponseWriter, r *http.Request) {
+  var isCompilation sql.NullBool
+  switch r.URL.Query().Get("compilations") {
+  case "":
+  case "only":
+    isCompilation = sql.NullBool{Bool: true, Valid: true}
+  case "exclude":
+    isCompilation = sql.NullBool{Bool: false, Valid: true}
+  default:
+    helpers.ErrorJSON(w, errors.New("compilations must be only or exclude"), http.StatusBadRequest)
+    return
+  }
+
   page := int64(1)
   if p := r.URL.Query().Get("page"); p != "" {
     parsed, err := strconv.ParseInt(p, 10, 64)
@@ -36,7 +49,7 @@ func (app *Application) GetAlbumsAlphabetical(w http.ResponseWriter, r *http.Req
 
   offset := (page - 1) * perPage
 
-  total, err := app.Queries.GetAlbumsCount(r.Context())
+  total, err := app.Queries.GetFilteredAlbumsCount(r.Context(), isCompilation)
   if err != nil {
     app.Logger.Error("failed to get albums count", "error", err)
     helpers.ErrorJSON(w, errors.New("failed to fetch albums count"))
@@ -44,8 +57,9 @@ func (app *Application) GetAlbumsAlphabetical(w http.ResponseWriter, r *http.Req
   }
 
   albums, err := app.Queries.GetAlbumsAlphabetical(r.Context(), database.GetAlbumsAlphabeticalParams{
-    Limit:  perPage,
-    Offset: offset,
+    IsCompilation: isCompilation,
+    Limit:         perPage,
+    Offset:        offset,
   })
 
   if err != nil {
@@ -154,10 +168,23 @@ func (app *Application) GetAlbumDetails(w http.ResponseWriter, r *http.Request)
     totalDuration += track.Duration
   }
 
-  // Build unique album genres from track genres
+  // Build unique album genres from track genres, unless they were edited by hand
   genreSet := make(map[string]struct{})
-  for _, g := range trackGenres {
-    genreSet[g.Tag] = struct{}{}
+  if helpers.IsFieldLocked(album.LockedFields, "genres") {
+    directGenres, err := qtx.GetGenresByAlbumIDDirect(ctx, id)
+    if err != nil {
+      app.Logger.Error("failed to get direct genres for album", "error", err, "album_id", id)
+      helpers.ErrorJSON(w, errors.New("failed to fetch album genres from server"))
+      return
+    }
+
+    for _, g := range directGenres {
+      genreSet[g.Tag] = struct{}{}
+    }
+  } else {
+    for _, g := range trackGenres {
+      genreSet[g.Tag] = struct{}{}
+    }
   }
   albumGenres := make([]string, 0, len(genreSet))
 
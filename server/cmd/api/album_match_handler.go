@@ -0,0 +1,243 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/spotify"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// albumMatchCandidate is a Spotify search result offered when fixing an album's match.
+type albumMatchCandidate struct {
+	SpotifyID   string   `json:"spotify_id"`
+	Title       string   `json:"title"`
+	Artists     []string `json:"artists"`
+	ReleaseDate string   `json:"release_date"`
+	Year        int      `json:"year"`
+	TotalTracks int      `json:"total_tracks"`
+	Cover       string   `json:"cover"`
+	Score       float64  `json:"score"`
+	Current     bool     `json:"current"`
+}
+
+// MatchAlbumRequest holds the Spotify id an album is matched to.
+type MatchAlbumRequest struct {
+	SpotifyID string `json:"spotify_id"`
+}
+
+// SearchAlbumMatch searches Spotify for the albums an album could be. The query and
+// artist default to the album's title and album artist; candidates are scored like the
+// scanner does, against the album's year and number of tracks.
+func (app *Application) SearchAlbumMatch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid album id"), http.StatusBadRequest)
+		return
+	}
+
+	if app.Spotify == nil {
+		helpers.ErrorJSON(w, errors.New("spotify is not configured"), http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := r.Context()
+
+	album, err := app.Queries.GetAlbumByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("album not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get album", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to search matches"))
+		return
+	}
+
+	tracks, err := app.Queries.GetTracksByAlbumID(ctx, sql.NullInt64{Int64: album.ID, Valid: true})
+	if err != nil {
+		app.Logger.Error("failed to get album tracks", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to search matches"))
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		query = album.Title
+	}
+
+	artist := strings.TrimSpace(r.URL.Query().Get("artist"))
+	if artist == "" && !album.IsCompilation && !app.isVariousArtists(album.Musician.String) {
+		artist = album.Musician.String
+	}
+
+	results, err := app.Spotify.SearchAlbums(query, artist)
+	if err != nil && !errors.Is(err, spotify.ErrNotFound) {
+		app.Logger.Error("failed to search spotify", "error", err, "query", query, "artist", artist)
+		helpers.ErrorJSON(w, errors.New("failed to search spotify"), http.StatusBadGateway)
+		return
+	}
+
+	local := localAlbum{year: int(album.Year.Int64), trackCount: len(tracks)}
+
+	candidates := make([]albumMatchCandidate, 0, len(results))
+	for i := range results {
+		result := &results[i]
+
+		candidate := albumMatchCandidate{
+			SpotifyID:   result.ID.String(),
+			Title:       result.Name,
+			Artists:     make([]string, 0, len(result.Artists)),
+			ReleaseDate: result.ReleaseDate,
+			TotalTracks: int(result.TotalTracks),
+			Score:       spotifyAlbumMatchScore(query, artist, local, result),
+			Current:     album.SpotifyID.Valid && album.SpotifyID.String == result.ID.String(),
+		}
+		for _, a := range result.Artists {
+			candidate.Artists = append(candidate.Artists, a.Name)
+		}
+		if result.ReleaseDate != "" {
+			candidate.Year = result.ReleaseDateTime().Year()
+		}
+		if len(result.Images) > 0 {
+			candidate.Cover = result.Images[0].URL
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"query":      query,
+			"artist":     artist,
+			"threshold":  helpers.SPOTIFY_ALBUM_MATCH_THRESHOLD,
+			"candidates": candidates,
+		},
+	})
+}
+
+// MatchAlbum matches an album to a Spotify album chosen by the user. Its Spotify id,
+// popularity, release date, track total and cover are replaced, and the match is
+// locked so later scans keep it. A hand-edited year is kept.
+func (app *Application) MatchAlbum(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid album id"), http.StatusBadRequest)
+		return
+	}
+
+	var req MatchAlbumRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	req.SpotifyID = strings.TrimSpace(req.SpotifyID)
+	if req.SpotifyID == "" {
+		helpers.ErrorJSON(w, errors.New("spotify_id is required"), http.StatusBadRequest)
+		return
+	}
+
+	if app.Spotify == nil {
+		helpers.ErrorJSON(w, errors.New("spotify is not configured"), http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Fetched before the transaction so the scanner isn't blocked on the network
+	spotifyAlbum, err := app.Spotify.GetAlbumByID(req.SpotifyID)
+	if err != nil {
+		app.Logger.Error("failed to get album from spotify", "error", err, "spotify_id", req.SpotifyID)
+		helpers.ErrorJSON(w, errors.New("failed to fetch album from spotify"), http.StatusBadGateway)
+		return
+	}
+
+	// Serialize with the scanners, which write the same rows inside their batch transactions
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to match album"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	album, err := qtx.GetAlbumByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("album not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get album", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to match album"))
+		return
+	}
+
+	// Another album with this Spotify id would be the same album under two entries
+	spotifyID := sql.NullString{String: spotifyAlbum.ID.String(), Valid: true}
+	other, err := qtx.GetAlbumBySpotifyID(ctx, spotifyID)
+	if err == nil && other.ID != album.ID {
+		helpers.ErrorJSON(w, errors.New("another album is already matched to this spotify id"), http.StatusConflict)
+		return
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		app.Logger.Error("failed to get album by spotify id", "error", err, "spotify_id", req.SpotifyID)
+		helpers.ErrorJSON(w, errors.New("failed to match album"))
+		return
+	}
+
+	params := database.UpdateAlbumSpotifyMatchParams{
+		ID:                album.ID,
+		SpotifyID:         spotifyID,
+		SpotifyPopularity: helpers.NullFloat64(float64(spotifyAlbum.Popularity)),
+		TotalTracks:       helpers.NullInt64(int64(spotifyAlbum.TotalTracks)),
+		Year:              album.Year,
+	}
+
+	if releaseDate := spotifyAlbum.ReleaseDateTime(); spotifyAlbum.ReleaseDate != "" && !releaseDate.IsZero() {
+		params.ReleaseDate = sql.NullString{String: releaseDate.Format("2006-01-02"), Valid: true}
+		if !helpers.IsFieldLocked(album.LockedFields, "year") {
+			params.Year = sql.NullInt64{Int64: int64(releaseDate.Year()), Valid: true}
+		}
+	}
+
+	if len(spotifyAlbum.Images) > 0 {
+		params.Cover = sql.NullString{String: spotifyAlbum.Images[0].URL, Valid: true}
+	}
+
+	album, err = qtx.UpdateAlbumSpotifyMatch(ctx, params)
+	if err != nil {
+		app.Logger.Error("failed to update album match", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to match album"))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit album match", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to match album"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"album": album,
+		},
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestAlbumMatch tests that candidates are scored against the album's tracks, and
+// that a manual match is locked so later scans keep it.
+func TestAlbumMatch(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	settings := *app.Settings()
+	settings.MusicSpotifyEnrichment = true
+	app.SetSettings(&settings)
+	app.Spotify = newAlbumMatchSpotify()
+
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		"/music/queen/01.flac":    {Title: "Bohemian Rhapsody", AlbumArtist: "Queen", Album: "Greatest Hits", Track: "1/17", Date: "1981"},
+		"/music/nobodies/01.flac": {Title: "Intro", AlbumArtist: "The Nobodies", Album: "Live", Date: "2003"},
+	}}
+
+	ctx := context.Background()
+
+	files := []trackFile{
+		{path: "/music/queen/01.flac", ext: "flac", size: 4},
+		{path: "/music/nobodies/01.flac", ext: "flac", size: 4},
+	}
+	if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != len(files) || errCount != 0 {
+		t.Fatalf("Expected %d tracks scanned, got %d scanned and %d errors", len(files), scanned, errCount)
+	}
+
+	albumBy := func(musician, title string) database.Album {
+		t.Helper()
+		albums, err := app.Queries.GetAlbumsByTitle(ctx, database.GetAlbumsByTitleParams{
+			Title:    title,
+			Musician: helpers.NullString(musician),
+		})
+		if err != nil || len(albums) != 1 {
+			t.Fatalf("Expected one %q album, got %d (%v)", title, len(albums), err)
+		}
+		return albums[0]
+	}
+
+	hits := albumBy("Queen", "Greatest Hits")
+	live := albumBy("The Nobodies", "Live")
+
+	request := func(handler http.HandlerFunc, method string, id int64, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", strconv.FormatInt(id, 10))
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	rr := request(app.SearchAlbumMatch, http.MethodGet, hits.ID, "/api/music/albums/1/match/search", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		Data struct {
+			Artist     string                `json:"artist"`
+			Candidates []albumMatchCandidate `json:"candidates"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	candidates := resp.Data.Candidates
+	if resp.Data.Artist != "Queen" || len(candidates) != 2 {
+		t.Fatalf("Expected two candidates for Queen, got %q and %+v", resp.Data.Artist, candidates)
+	}
+	if candidates[0].SpotifyID != "queen-hits" || !candidates[0].Current || candidates[0].Score <= candidates[1].Score {
+		t.Errorf("Expected the current Queen album first, got %+v", candidates)
+	}
+
+	match := func(id int64, body string) *httptest.ResponseRecorder {
+		return request(app.MatchAlbum, http.MethodPost, id, "/api/music/albums/1/match", body)
+	}
+
+	if rr := match(live.ID, `{"spotify_id": "unknown"}`); rr.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d for an unknown spotify id, got %d", http.StatusBadGateway, rr.Code)
+	}
+
+	if rr := match(live.ID, `{"spotify_id": "queen-hits"}`); rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %d for a spotify id matched to another album, got %d", http.StatusConflict, rr.Code)
+	}
+
+	if rr := match(live.ID, `{"spotify_id": "pearl-jam-live"}`); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	live = albumBy("The Nobodies", "Live")
+	if live.SpotifyID.String != "pearl-jam-live" || live.Year.Int64 != 1994 || !live.SpotifyMatchLocked || live.SpotifyMatchScore.Valid {
+		t.Errorf("Expected the locked pearl-jam-live (1994) match, got %q (%d, locked=%v, score=%v)", live.SpotifyID.String, live.Year.Int64, live.SpotifyMatchLocked, live.SpotifyMatchScore)
+	}
+
+	// A rescan of the changed file keeps the manual match
+	files = []trackFile{{path: "/music/nobodies/01.flac", ext: "flac", size: 8}}
+	if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != 1 || errCount != 0 {
+		t.Fatalf("Expected the track to be rescanned, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	live = albumBy("The Nobodies", "Live")
+	if live.SpotifyID.String != "pearl-jam-live" || !live.SpotifyMatchLocked {
+		t.Errorf("Expected the rescan to keep the manual match, got %q (locked=%v)", live.SpotifyID.String, live.SpotifyMatchLocked)
+	}
+}
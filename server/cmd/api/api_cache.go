@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"sync"
+	"time"
+)
+
+// apiResponseCache keeps the raw responses of one metadata provider in the api_cache
+// table. Scanners query providers inside their write transaction, which another
+// connection can't write past, so entries are buffered and written in the background
+// once no scanner holds ScannerDBMu. Until then they are served from memory.
+type apiResponseCache struct {
+	app      *Application
+	provider string
+
+	mu       sync.Mutex
+	pending  map[string]database.UpsertApiCacheEntryParams
+	flushing bool
+}
+
+func (app *Application) newAPIResponseCache(provider string) *apiResponseCache {
+	return &apiResponseCache{
+		app:      app,
+		provider: provider,
+		pending:  make(map[string]database.UpsertApiCacheEntryParams),
+	}
+}
+
+// Get returns the cached response for key unless it expired.
+func (c *apiResponseCache) Get(key string) ([]byte, bool) {
+	now := helpers.SQLiteTime(time.Now())
+
+	c.mu.Lock()
+	entry, ok := c.pending[key]
+	c.mu.Unlock()
+	if ok {
+		return entry.Body, entry.ExpiresAt > now
+	}
+
+	body, err := c.app.Queries.GetApiCacheEntry(context.Background(), database.GetApiCacheEntryParams{
+		Provider:  c.provider,
+		CacheKey:  key,
+		ExpiresAt: now,
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			c.app.Logger.Warn("failed to read api cache", "provider", c.provider, "error", err)
+		}
+		return nil, false
+	}
+
+	return body, true
+}
+
+// Set caches a response for ttl.
+func (c *apiResponseCache) Set(key string, body []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending[key] = database.UpsertApiCacheEntryParams{
+		Provider:  c.provider,
+		CacheKey:  key,
+		Body:      body,
+		ExpiresAt: helpers.SQLiteTime(time.Now().Add(ttl)),
+	}
+
+	if !c.flushing {
+		c.flushing = true
+		go c.flush()
+	}
+}
+
+// flush writes the pending entries and deletes expired ones. Entries
+// set while it runs are written by the next flush.
+func (c *apiResponseCache) flush() {
+	c.app.ScannerDBMu.Lock()
+	defer c.app.ScannerDBMu.Unlock()
+
+	c.mu.Lock()
+	c.flushing = false
+	entries := make([]database.UpsertApiCacheEntryParams, 0, len(c.pending))
+	for _, entry := range c.pending {
+		entries = append(entries, entry)
+	}
+	c.mu.Unlock()
+
+	if err := c.write(entries); err != nil {
+		c.app.Logger.Warn("failed to write api cache", "provider", c.provider, "error", err)
+		return
+	}
+
+	// Entries replaced meanwhile stay pending for the next flush
+	c.mu.Lock()
+	for _, entry := range entries {
+		if c.pending[entry.CacheKey].ExpiresAt == entry.ExpiresAt {
+			delete(c.pending, entry.CacheKey)
+		}
+	}
+	c.mu.Unlock()
+}
+
+func (c *apiResponseCache) write(entries []database.UpsertApiCacheEntryParams) error {
+	ctx := context.Background()
+
+	tx, err := c.app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := c.app.Queries.WithTx(tx)
+
+	for _, entry := range entries {
+		if err := qtx.UpsertApiCacheEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := qtx.DeleteExpiredApiCacheEntries(ctx, helpers.SQLiteTime(time.Now())); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAPIResponseCache tests that entries set during a scan are served from memory
+// and written once the scanner releases the database.
+func TestAPIResponseCache(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	cache := app.newAPIResponseCache("tmdb")
+
+	// A scan holds the database while it fetches metadata
+	app.ScannerDBMu.Lock()
+	cache.Set("/movie/603", []byte(`{"id": 603}`), time.Hour)
+	cache.Set("/movie/1", []byte(`{"id": 1}`), -time.Hour)
+
+	if body, ok := cache.Get("/movie/603"); !ok || string(body) != `{"id": 603}` {
+		t.Errorf("Expected the pending entry, got %q (%v)", body, ok)
+	}
+	if _, ok := cache.Get("/movie/1"); ok {
+		t.Error("Expected the expired entry to be a miss")
+	}
+
+	var rows int
+	if err := app.DB.QueryRow("SELECT COUNT(*) FROM api_cache").Scan(&rows); err != nil || rows != 0 {
+		t.Fatalf("Expected nothing written during the scan, got %d rows (%v)", rows, err)
+	}
+	app.ScannerDBMu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cache.mu.Lock()
+		pending := len(cache.pending)
+		cache.mu.Unlock()
+		if pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the entries to be written, %d still pending", pending)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The expired entry was purged on write
+	if err := app.DB.QueryRow("SELECT COUNT(*) FROM api_cache").Scan(&rows); err != nil || rows != 1 {
+		t.Errorf("Expected 1 row, got %d (%v)", rows, err)
+	}
+
+	if body, ok := cache.Get("/movie/603"); !ok || string(body) != `{"id": 603}` {
+		t.Errorf("Expected the stored entry, got %q (%v)", body, ok)
+	}
+
+	// Providers don't share entries
+	if _, ok := app.newAPIResponseCache("spotify").Get("/movie/603"); ok {
+		t.Error("Expected another provider's cache to miss")
+	}
+}
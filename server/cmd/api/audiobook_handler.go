@@ -0,0 +1,650 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxBookmarkNoteLength caps the note a user can attach to a bookmark.
+const maxBookmarkNoteLength = 1000
+
+// AudiobookProgressResponse is where a user stopped listening to a book. Chapter is
+// the index of the chapter the position falls in, -1 when the book has no chapters.
+type AudiobookProgressResponse struct {
+	Position  int64  `json:"position"`
+	Finished  bool   `json:"finished"`
+	Chapter   int64  `json:"chapter"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// UpdateAudiobookProgressRequest is the body of UpdateAudiobookProgress. Position is
+// in milliseconds from the start of the book.
+type UpdateAudiobookProgressRequest struct {
+	Position int64 `json:"position"`
+	Finished bool  `json:"finished"`
+}
+
+// CreateAudiobookBookmarkRequest is the body of CreateAudiobookBookmark.
+type CreateAudiobookBookmarkRequest struct {
+	Position int64  `json:"position"`
+	Note     string `json:"note"`
+}
+
+// chapterAt returns the index of the chapter position falls in, -1 without chapters.
+// Positions past the last chapter belong to it.
+func chapterAt(chapters []database.AudiobookChapter, position int64) int64 {
+	index := int64(-1)
+	for _, chapter := range chapters {
+		if chapter.StartTime > position && index >= 0 {
+			break
+		}
+		index = chapter.ChapterIndex
+	}
+	return index
+}
+
+// clampPosition keeps a position within a book, whose duration may be unknown (0).
+func clampPosition(position, duration int64) int64 {
+	if duration > 0 && position > duration {
+		return duration
+	}
+	return position
+}
+
+// GetAudiobooksAlphabetical returns a paginated list of books sorted by title, with the
+// user's progress in each.
+// Supports query parameters: limit (default 50, max 100), offset (default 0)
+func (app *Application) GetAudiobooksAlphabetical(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	limit, offset := parseStatsPaginationParams(r, 50, 100)
+
+	total, err := app.Queries.GetAudiobooksCount(r.Context())
+	if err != nil {
+		app.Logger.Error("failed to get audiobooks count", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobooks count"))
+		return
+	}
+
+	audiobooks, err := app.Queries.GetAudiobooksAlphabetical(r.Context(), database.GetAudiobooksAlphabeticalParams{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get audiobooks", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobooks"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"audiobooks": audiobooks,
+			"total":      total,
+			"offset":     offset,
+			"limit":      limit,
+			"has_more":   offset+limit < total,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetAudiobooksInProgress returns the books the user started but hasn't finished,
+// most recently played first.
+// Supports query parameter: limit (default 20, max 100)
+func (app *Application) GetAudiobooksInProgress(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	limit, _ := parseStatsPaginationParams(r, 20, 100)
+
+	audiobooks, err := app.Queries.GetAudiobooksInProgress(r.Context(), database.GetAudiobooksInProgressParams{
+		UserID: userID,
+		Limit:  limit,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get audiobooks in progress", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobooks"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"audiobooks": audiobooks,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetAuthorsAlphabetical returns a paginated list of audiobook authors sorted by name,
+// with the number of books of each.
+// Supports query parameters: limit (default 50, max 100), offset (default 0)
+func (app *Application) GetAuthorsAlphabetical(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parseStatsPaginationParams(r, 50, 100)
+
+	total, err := app.Queries.GetAuthorsCount(r.Context())
+	if err != nil {
+		app.Logger.Error("failed to get authors count", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch authors count"))
+		return
+	}
+
+	authors, err := app.Queries.GetAuthorsAlphabetical(r.Context(), database.GetAuthorsAlphabeticalParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get authors", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch authors"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"authors":  authors,
+			"total":    total,
+			"offset":   offset,
+			"limit":    limit,
+			"has_more": offset+limit < total,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetAuthorDetails returns an author with their books, grouped by series in reading order.
+func (app *Application) GetAuthorDetails(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid author id"), http.StatusBadRequest)
+		return
+	}
+
+	author, err := app.Queries.GetAuthorByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("author not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get author", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch author from server"))
+		return
+	}
+
+	audiobooks, err := app.Queries.GetAudiobooksByAuthor(r.Context(), database.GetAudiobooksByAuthorParams{
+		UserID:   userID,
+		AuthorID: sql.NullInt64{Int64: id, Valid: true},
+	})
+	if err != nil {
+		app.Logger.Error("failed to get author audiobooks", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch author audiobooks"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"author":     author,
+			"audiobooks": audiobooks,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetAudiobookDetails returns a book with its files, chapters, and the user's
+// progress and bookmarks.
+func (app *Application) GetAudiobookDetails(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid audiobook id"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	audiobook, err := app.Queries.GetAudiobookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("audiobook not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get audiobook", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobook from server"))
+		return
+	}
+
+	files, err := app.Queries.GetAudiobookFiles(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get audiobook files", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobook files"))
+		return
+	}
+
+	chapters, err := app.Queries.GetAudiobookChapters(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get audiobook chapters", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobook chapters"))
+		return
+	}
+
+	progress := AudiobookProgressResponse{Chapter: chapterAt(chapters, 0)}
+
+	saved, err := app.Queries.GetAudiobookProgress(ctx, database.GetAudiobookProgressParams{
+		UserID:      userID,
+		AudiobookID: id,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		app.Logger.Error("failed to get audiobook progress", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobook progress"))
+		return
+	}
+	if err == nil {
+		progress = AudiobookProgressResponse{
+			Position:  saved.Position,
+			Finished:  saved.Finished,
+			Chapter:   chapterAt(chapters, saved.Position),
+			UpdatedAt: saved.UpdatedAt,
+		}
+	}
+
+	bookmarks, err := app.Queries.GetAudiobookBookmarks(ctx, database.GetAudiobookBookmarksParams{
+		UserID:      userID,
+		AudiobookID: id,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get audiobook bookmarks", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobook bookmarks"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"audiobook": audiobook,
+			"files":     files,
+			"chapters":  chapters,
+			"progress":  progress,
+			"bookmarks": bookmarks,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// StreamAudiobookFile streams one of a book's files with support for range requests.
+// Formats browsers can't decode are transcoded like music tracks.
+func (app *Application) StreamAudiobookFile(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid audiobook id"), http.StatusBadRequest)
+		return
+	}
+
+	fileID, err := strconv.ParseInt(chi.URLParam(r, "fileID"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid file id"), http.StatusBadRequest)
+		return
+	}
+
+	file, err := app.Queries.GetAudiobookFile(r.Context(), fileID)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && file.AudiobookID != id {
+		helpers.ErrorJSON(w, errors.New("audiobook file not found"), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		app.Logger.Error("failed to get audiobook file for streaming", "error", err, "id", fileID)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobook file from server"))
+		return
+	}
+
+	if helpers.NeedsAudioTranscode(file.Container, file.Codec) {
+		app.streamTranscodedAudiobook(w, r, file, 0, 0)
+		return
+	}
+
+	app.serveAudiobookFile(w, r, file)
+}
+
+// StreamAudiobookChapter streams a single chapter of a book. A chapter that is a whole
+// file is served as is; one cut from a larger file, such as an m4b, is transcoded
+// from its start to its end.
+func (app *Application) StreamAudiobookChapter(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid audiobook id"), http.StatusBadRequest)
+		return
+	}
+
+	index, err := strconv.ParseInt(chi.URLParam(r, "index"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid chapter index"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	chapter, err := app.Queries.GetAudiobookChapter(ctx, database.GetAudiobookChapterParams{
+		AudiobookID:  id,
+		ChapterIndex: index,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("chapter not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get chapter for streaming", "error", err, "id", id, "index", index)
+		helpers.ErrorJSON(w, errors.New("failed to fetch chapter from server"))
+		return
+	}
+
+	file, err := app.Queries.GetAudiobookFile(ctx, chapter.FileID)
+	if err != nil {
+		app.Logger.Error("failed to get audiobook file for streaming", "error", err, "id", chapter.FileID)
+		helpers.ErrorJSON(w, errors.New("failed to fetch audiobook file from server"))
+		return
+	}
+
+	// Chapter times count from the start of the book, ffmpeg needs them within the file
+	start := chapter.StartTime - file.StartOffset
+	end := chapter.EndTime - file.StartOffset
+
+	if start <= 0 && end >= file.Duration && !helpers.NeedsAudioTranscode(file.Container, file.Codec) {
+		app.serveAudiobookFile(w, r, file)
+		return
+	}
+
+	app.streamTranscodedAudiobook(w, r, file, start, end)
+}
+
+// serveAudiobookFile serves a book's file from disk with support for range requests.
+func (app *Application) serveAudiobookFile(w http.ResponseWriter, r *http.Request, file database.AudiobookFile) {
+	f, err := os.Open(file.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			app.Logger.Error("audiobook file not found on disk", "path", file.FilePath, "id", file.ID)
+			helpers.ErrorJSON(w, errors.New("audiobook file not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to open audiobook file", "error", err, "path", file.FilePath)
+		helpers.ErrorJSON(w, errors.New("failed to open audiobook file"))
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		app.Logger.Error("failed to stat audiobook file", "error", err, "path", file.FilePath)
+		helpers.ErrorJSON(w, errors.New("failed to read audiobook file"))
+		return
+	}
+
+	w.Header().Set("Content-Type", file.MimeType)
+
+	http.ServeContent(w, r, file.FileName, stat.ModTime(), f)
+}
+
+// streamTranscodedAudiobook transcodes part of a book's file, from start to end in
+// milliseconds within the file, to FLAC on the fly. An end of 0 plays to the end of
+// the file. Like transcoded tracks, range requests are not supported.
+func (app *Application) streamTranscodedAudiobook(w http.ResponseWriter, r *http.Request, file database.AudiobookFile, start, end int64) {
+	if app.Ffmpeg == nil {
+		helpers.ErrorJSON(w, errors.New("transcoding is not available"), http.StatusNotImplemented)
+		return
+	}
+
+	if _, err := os.Stat(file.FilePath); err != nil {
+		if os.IsNotExist(err) {
+			app.Logger.Error("audiobook file not found on disk", "path", file.FilePath, "id", file.ID)
+			helpers.ErrorJSON(w, errors.New("audiobook file not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to stat audiobook file", "error", err, "path", file.FilePath)
+		helpers.ErrorJSON(w, errors.New("failed to read audiobook file"))
+		return
+	}
+
+	w.Header().Set("Content-Type", helpers.AUDIO_TRANSCODE_MIME_TYPE)
+	w.Header().Set("Accept-Ranges", "none")
+
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var err error
+	if start > 0 || end > 0 {
+		err = app.Ffmpeg.TranscodeAudioRange(r.Context(), file.FilePath,
+			time.Duration(start)*time.Millisecond, time.Duration(end)*time.Millisecond, w)
+	} else {
+		err = app.Ffmpeg.TranscodeAudio(r.Context(), file.FilePath, w)
+	}
+
+	if err != nil && r.Context().Err() == nil {
+		// The response may already be partially written, so the error can only be logged
+		app.Logger.Error("failed to transcode audiobook file", "error", err, "id", file.ID, "path", file.FilePath)
+	}
+}
+
+// UpdateAudiobookProgress saves where the user stopped listening to a book.
+// Positions past the end of the book are stored as its end.
+func (app *Application) UpdateAudiobookProgress(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid audiobook id"), http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateAudiobookProgressRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.Position < 0 {
+		helpers.ErrorJSON(w, errors.New("position must not be negative"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	audiobook, err := app.Queries.GetAudiobookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("audiobook not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get audiobook", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update progress"))
+		return
+	}
+
+	progress, err := app.Queries.UpsertAudiobookProgress(ctx, database.UpsertAudiobookProgressParams{
+		UserID:      userID,
+		AudiobookID: id,
+		Position:    clampPosition(req.Position, audiobook.Duration),
+		Finished:    req.Finished,
+	})
+	if err != nil {
+		app.Logger.Error("failed to save audiobook progress", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update progress"))
+		return
+	}
+
+	chapters, err := app.Queries.GetAudiobookChapters(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get audiobook chapters", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update progress"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"progress": AudiobookProgressResponse{
+				Position:  progress.Position,
+				Finished:  progress.Finished,
+				Chapter:   chapterAt(chapters, progress.Position),
+				UpdatedAt: progress.UpdatedAt,
+			},
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// CreateAudiobookBookmark saves a position in a book with an optional note.
+func (app *Application) CreateAudiobookBookmark(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid audiobook id"), http.StatusBadRequest)
+		return
+	}
+
+	var req CreateAudiobookBookmarkRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.Position < 0 {
+		helpers.ErrorJSON(w, errors.New("position must not be negative"), http.StatusBadRequest)
+		return
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if len(note) > maxBookmarkNoteLength {
+		helpers.ErrorJSON(w, errors.New("note must be at most 1000 characters"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	audiobook, err := app.Queries.GetAudiobookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("audiobook not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get audiobook", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to create bookmark"))
+		return
+	}
+
+	bookmark, err := app.Queries.CreateAudiobookBookmark(ctx, database.CreateAudiobookBookmarkParams{
+		UserID:      userID,
+		AudiobookID: id,
+		Position:    clampPosition(req.Position, audiobook.Duration),
+		Note:        note,
+	})
+	if err != nil {
+		app.Logger.Error("failed to create audiobook bookmark", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to create bookmark"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"bookmark": bookmark,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, res)
+}
+
+// DeleteAudiobookBookmark deletes one of the user's bookmarks in a book.
+func (app *Application) DeleteAudiobookBookmark(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid audiobook id"), http.StatusBadRequest)
+		return
+	}
+
+	bookmarkID, err := strconv.ParseInt(chi.URLParam(r, "bookmarkID"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid bookmark id"), http.StatusBadRequest)
+		return
+	}
+
+	_, err = app.Queries.DeleteAudiobookBookmark(r.Context(), database.DeleteAudiobookBookmarkParams{
+		ID:          bookmarkID,
+		UserID:      userID,
+		AudiobookID: id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("bookmark not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to delete audiobook bookmark", "error", err, "id", bookmarkID)
+		helpers.ErrorJSON(w, errors.New("failed to delete bookmark"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error:   false,
+		Message: "Bookmark deleted successfully",
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
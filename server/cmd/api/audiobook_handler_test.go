@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+)
+
+// audiobookRequest builds a request for a user with the given chi URL params.
+func audiobookRequest(t *testing.T, app *Application, method, target string, userID int64, body string, params map[string]string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+
+	ctx, err := app.SessionManager.Load(ctx, "")
+	if err != nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	if userID != 0 {
+		app.SessionManager.Put(ctx, helpers.COOKIE_USER_ID, userID)
+	}
+
+	return req.WithContext(ctx)
+}
+
+// setupAudiobookTestApp creates an app with two users and a book of two files: an
+// mp3 holding the first chapter and an m4b holding the other two.
+func setupAudiobookTestApp(t *testing.T) (*Application, database.Audiobook, []int64) {
+	t.Helper()
+
+	app := setupTestAppWithLogger(t)
+	app.SessionManager = scs.New()
+
+	ctx := context.Background()
+
+	var users []int64
+	for _, email := range []string{"one@example.com", "two@example.com"} {
+		user, err := app.Queries.CreateUser(ctx, database.CreateUserParams{Name: email, Email: email, Password: "x"})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		users = append(users, user.ID)
+	}
+
+	dir := t.TempDir()
+
+	book, err := app.Queries.UpsertAudiobook(ctx, database.UpsertAudiobookParams{
+		Title: "Book", SortTitle: "Book", Path: dir, Duration: 300_000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert audiobook: %v", err)
+	}
+
+	files := []database.UpsertAudiobookFileParams{
+		{FileName: "01.mp3", Container: "mp3", MimeType: "audio/mpeg", Codec: "mp3", Duration: 100_000},
+		{FileName: "02.m4b", Container: "m4a", MimeType: "audio/mp4", Codec: "aac", Duration: 200_000, StartOffset: 100_000},
+	}
+
+	var fileIDs []int64
+	for i, params := range files {
+		params.AudiobookID = book.ID
+		params.FilePath = filepath.Join(dir, params.FileName)
+		params.FileIndex = int64(i)
+
+		if err := os.WriteFile(params.FilePath, []byte("audio data"), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+
+		file, err := app.Queries.UpsertAudiobookFile(ctx, params)
+		if err != nil {
+			t.Fatalf("Failed to insert file: %v", err)
+		}
+		fileIDs = append(fileIDs, file.ID)
+	}
+
+	chapters := []database.CreateAudiobookChapterParams{
+		{FileID: fileIDs[0], Title: "One", StartTime: 0, EndTime: 100_000},
+		{FileID: fileIDs[1], Title: "Two", StartTime: 100_000, EndTime: 160_000},
+		{FileID: fileIDs[1], Title: "Three", StartTime: 160_000, EndTime: 300_000},
+	}
+	for i, params := range chapters {
+		params.AudiobookID = book.ID
+		params.ChapterIndex = int64(i)
+		if err := app.Queries.CreateAudiobookChapter(ctx, params); err != nil {
+			t.Fatalf("Failed to insert chapter: %v", err)
+		}
+	}
+
+	return app, book, users
+}
+
+func TestUpdateAudiobookProgress(t *testing.T) {
+	app, book, users := setupAudiobookTestApp(t)
+	defer app.DB.Close()
+
+	id := map[string]string{"id": "1"}
+	target := "/api/audiobooks/1/progress"
+
+	tests := []struct {
+		name     string
+		userID   int64
+		body     string
+		status   int
+		position int64
+		chapter  int64
+	}{
+		{"within the second chapter", users[0], `{"position": 120000}`, http.StatusOK, 120_000, 1},
+		{"past the end", users[0], `{"position": 999999, "finished": true}`, http.StatusOK, 300_000, 2},
+		{"negative position", users[0], `{"position": -1}`, http.StatusBadRequest, 0, 0},
+		{"unknown field", users[0], `{"offset": 10}`, http.StatusBadRequest, 0, 0},
+		{"not logged in", 0, `{"position": 10}`, http.StatusUnauthorized, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			app.UpdateAudiobookProgress(rr, audiobookRequest(t, app, http.MethodPut, target, tt.userID, tt.body, id))
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data struct {
+					Progress AudiobookProgressResponse `json:"progress"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if res.Data.Progress.Position != tt.position || res.Data.Progress.Chapter != tt.chapter {
+				t.Errorf("Expected position %d in chapter %d, got %+v", tt.position, tt.chapter, res.Data.Progress)
+			}
+		})
+	}
+
+	// Progress is per user
+	rr := httptest.NewRecorder()
+	app.GetAudiobookDetails(rr, audiobookRequest(t, app, http.MethodGet, "/api/audiobooks/1", users[1], "", id))
+
+	var res struct {
+		Data struct {
+			Progress AudiobookProgressResponse   `json:"progress"`
+			Chapters []database.AudiobookChapter `json:"chapters"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if res.Data.Progress.Position != 0 || res.Data.Progress.Finished || len(res.Data.Chapters) != 3 {
+		t.Errorf("Expected no progress for the second user, got %+v", res.Data)
+	}
+
+	// The first user's book is finished, so it isn't in progress anymore
+	progress, err := app.Queries.GetAudiobookProgress(context.Background(), database.GetAudiobookProgressParams{UserID: users[0], AudiobookID: book.ID})
+	if err != nil || !progress.Finished {
+		t.Errorf("Expected the book to be finished, got %+v (%v)", progress, err)
+	}
+
+	inProgress, err := app.Queries.GetAudiobooksInProgress(context.Background(), database.GetAudiobooksInProgressParams{UserID: users[0], Limit: 10})
+	if err != nil || len(inProgress) != 0 {
+		t.Errorf("Expected no books in progress, got %d (%v)", len(inProgress), err)
+	}
+}
+
+func TestAudiobookBookmarks(t *testing.T) {
+	app, book, users := setupAudiobookTestApp(t)
+	defer app.DB.Close()
+
+	id := map[string]string{"id": "1"}
+
+	rr := httptest.NewRecorder()
+	app.CreateAudiobookBookmark(rr, audiobookRequest(t, app, http.MethodPost, "/api/audiobooks/1/bookmarks", users[0], `{"position": 42000, "note": "  Great quote "}`, id))
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var res struct {
+		Data struct {
+			Bookmark database.AudiobookBookmark `json:"bookmark"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	bookmark := res.Data.Bookmark
+	if bookmark.Position != 42_000 || bookmark.Note != "Great quote" || bookmark.AudiobookID != book.ID {
+		t.Errorf("Unexpected bookmark: %+v", bookmark)
+	}
+
+	deleteBookmark := func(userID int64, bookmarkID int64) int {
+		rr := httptest.NewRecorder()
+		params := map[string]string{"id": "1", "bookmarkID": strconv.FormatInt(bookmarkID, 10)}
+		app.DeleteAudiobookBookmark(rr, audiobookRequest(t, app, http.MethodDelete, "/api/audiobooks/1/bookmarks/x", userID, "", params))
+		return rr.Code
+	}
+
+	// Another user's bookmark can't be deleted
+	if status := deleteBookmark(users[1], bookmark.ID); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for another user's bookmark, got %d", status)
+	}
+	if status := deleteBookmark(users[0], bookmark.ID); status != http.StatusOK {
+		t.Errorf("Expected the bookmark to be deleted, got %d", status)
+	}
+	if status := deleteBookmark(users[0], bookmark.ID); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a deleted bookmark, got %d", status)
+	}
+
+	// Missing books
+	rr = httptest.NewRecorder()
+	app.CreateAudiobookBookmark(rr, audiobookRequest(t, app, http.MethodPost, "/api/audiobooks/9/bookmarks", users[0], `{"position": 1}`, map[string]string{"id": "9"}))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing book, got %d", rr.Code)
+	}
+}
+
+// TestStreamAudiobookChapter tests that a chapter spanning a whole file is served as is,
+// and that a chapter within a larger file is cut by ffmpeg at file-relative times.
+func TestStreamAudiobookChapter(t *testing.T) {
+	app, _, users := setupAudiobookTestApp(t)
+	defer app.DB.Close()
+
+	ffmpeg := &fakeFfmpeg{}
+	app.Ffmpeg = ffmpeg
+
+	stream := func(index string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		params := map[string]string{"id": "1", "index": index}
+		app.StreamAudiobookChapter(rr, audiobookRequest(t, app, http.MethodGet, "/api/audiobooks/1/chapters/"+index+"/stream", users[0], "", params))
+		return rr
+	}
+
+	rr := stream("0")
+	if rr.Code != http.StatusOK || rr.Body.String() != "audio data" || rr.Header().Get("Content-Type") != "audio/mpeg" {
+		t.Errorf("Expected the whole file, got %d %q (%s)", rr.Code, rr.Body.String(), rr.Header().Get("Content-Type"))
+	}
+	if ffmpeg.path != "" {
+		t.Errorf("Expected no transcoding, got %s", ffmpeg.path)
+	}
+
+	rr = stream("2")
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != helpers.AUDIO_TRANSCODE_MIME_TYPE {
+		t.Fatalf("Expected a transcoded stream, got %d (%s)", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	if filepath.Base(ffmpeg.path) != "02.m4b" || ffmpeg.start != 60*time.Second || ffmpeg.end != 200*time.Second {
+		t.Errorf("Expected 02.m4b from 60s to 200s, got %s from %s to %s", ffmpeg.path, ffmpeg.start, ffmpeg.end)
+	}
+
+	if rr = stream("3"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing chapter, got %d", rr.Code)
+	}
+}
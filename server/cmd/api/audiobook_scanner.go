@@ -0,0 +1,614 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// audiobookFile holds path, extension, and size of a book's audio file collected during directory walk.
+type audiobookFile struct {
+	path string
+	ext  string
+	size int64
+}
+
+// audiobookDir is a book as found on disk: a folder of audio files, or a single file.
+type audiobookDir struct {
+	// path is the book's folder, or the file itself for single-file books
+	path  string
+	files []audiobookFile
+	size  int64
+}
+
+// ScanAudiobooksLibrary walks through the configured audiobooks directory, groups the
+// audio files into books, and stores the books with their files and chapters.
+func (app *Application) ScanAudiobooksLibrary() {
+	if app.Wait != nil {
+		app.Wait.Add(1)
+		defer app.Wait.Done()
+	}
+
+	settings := app.Settings()
+	if !settings.AudiobooksDir.Valid || settings.AudiobooksDir.String == "" {
+		app.Logger.Error("audiobooks directory not configured")
+		return
+	}
+
+	root := settings.AudiobooksDir.String
+	app.Logger.Info(fmt.Sprintf("scanning audiobooks directory: %s", root))
+
+	ctx := context.Background()
+	errorCount := 0
+	booksScanned := 0
+	booksSkipped := 0
+	startTime := time.Now()
+
+	// A book is only complete once the walk has left its folder, so all of them are
+	// collected first. This holds paths only, the files are probed per batch.
+	books := make(map[string]*audiobookDir)
+	var order []string
+
+	filter := newLibraryFilter(root, nil, settings.AudiobooksIgnorePatterns, 0)
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			app.Logger.Error(fmt.Sprintf("error walking directory: %s", err.Error()))
+			errorCount++
+			return nil
+		}
+
+		if entry.IsDir() {
+			skip, err := filter.skipDir(path)
+			if err != nil {
+				app.Logger.Error(err.Error())
+				errorCount++
+			}
+
+			if skip {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		ext := strings.ToLower(helpers.GetFileExtension(path))
+		if !helpers.IsAudiobookExtension(ext) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			app.Logger.Error(fmt.Sprintf("failed to get file info for %s: %s", path, err.Error()))
+			errorCount++
+			return nil
+		}
+
+		if filter.skipFile(path, info.Size()) {
+			return nil
+		}
+
+		bookPath := audiobookPath(root, path, ext)
+		book, ok := books[bookPath]
+		if !ok {
+			book = &audiobookDir{path: bookPath}
+			books[bookPath] = book
+			order = append(order, bookPath)
+		}
+		book.files = append(book.files, audiobookFile{path: path, ext: ext, size: info.Size()})
+		book.size += info.Size()
+
+		return nil
+	})
+
+	if err != nil {
+		app.Logger.Error(fmt.Sprintf("unexpected error walking audiobooks directory: %s", err.Error()))
+		return
+	}
+
+	batch := make([]audiobookDir, 0, helpers.SCANNER_BATCH_SIZE)
+	for i, bookPath := range order {
+		book := books[bookPath]
+		sortAudiobookFiles(book.files)
+		batch = append(batch, *book)
+
+		if len(batch) >= helpers.SCANNER_BATCH_SIZE || i == len(order)-1 {
+			scanned, skipped, errors := app.processAudiobooksBatch(ctx, batch)
+			booksScanned += scanned
+			booksSkipped += skipped
+			errorCount += errors
+			batch = batch[:0]
+		}
+	}
+
+	removed, err := app.removeMissingAudiobooks(ctx, root)
+	if err != nil {
+		app.Logger.Error(fmt.Sprintf("failed to remove missing audiobooks: %s", err.Error()))
+		errorCount++
+	}
+
+	app.Logger.Info(fmt.Sprintf("audiobooks scanner completed: %d scanned, %d skipped, %d removed, %d errors in %s",
+		booksScanned, booksSkipped, removed, errorCount, helpers.FormatDuration(time.Since(startTime))))
+}
+
+// removeMissingAudiobooks deletes the books under dir none of whose files exist any
+// more, along with their progress, files and chapters. This includes the books left
+// without files once these became books of their own. Returns the number of books removed.
+func (app *Application) removeMissingAudiobooks(ctx context.Context, dir string) (int, error) {
+	// An unmounted library would look as if every book was gone
+	if _, err := os.Stat(dir); err != nil {
+		return 0, fmt.Errorf("audiobooks directory unavailable: %w", err)
+	}
+
+	books, err := app.Queries.GetAudiobooksByDirectory(ctx, filepath.Clean(dir)+string(filepath.Separator))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get audiobooks: %w", err)
+	}
+
+	// Stat outside the transaction so file I/O doesn't hold the scanner lock
+	missing := []database.GetAudiobooksByDirectoryRow{}
+	for _, book := range books {
+		files, err := app.Queries.GetAudiobookFiles(ctx, book.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get files of audiobook %d: %w", book.ID, err)
+		}
+
+		gone := true
+		for _, file := range files {
+			if _, err := os.Stat(file.FilePath); !errors.Is(err, fs.ErrNotExist) {
+				gone = false
+				break
+			}
+		}
+
+		if gone {
+			missing = append(missing, book)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	for _, book := range missing {
+		if err := qtx.DeleteAudiobook(ctx, book.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete audiobook %s: %w", book.Path, err)
+		}
+		app.clearScanError(ctx, qtx, book.Path)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(missing), nil
+}
+
+// audiobookPath returns the path of the book a file belongs to. m4b files and files
+// directly in the library root are books of their own; any other file belongs to the
+// book of its folder, with per-disc subfolders ("CD1", "Disc 2") folded into their parent.
+func audiobookPath(root, path, ext string) string {
+	if ext == "m4b" || filepath.Dir(path) == filepath.Clean(root) {
+		return path
+	}
+	return albumDirectory(path)
+}
+
+// readAudiobookDir collects the files of the book at path, as the walk would have
+// grouped them, for rescanning a single book.
+func readAudiobookDir(path string) (audiobookDir, error) {
+	book := audiobookDir{path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return book, err
+	}
+
+	if !info.IsDir() {
+		ext := strings.ToLower(helpers.GetFileExtension(path))
+		book.files = []audiobookFile{{path: path, ext: ext, size: info.Size()}}
+		book.size = info.Size()
+		return book, nil
+	}
+
+	dirs := []string{path}
+	for i := 0; i < len(dirs); i++ {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return book, err
+		}
+
+		for _, entry := range entries {
+			entryPath := filepath.Join(dirs[i], entry.Name())
+			if entry.IsDir() {
+				if i == 0 && discFolderPattern.MatchString(entry.Name()) {
+					dirs = append(dirs, entryPath)
+				}
+				continue
+			}
+
+			ext := strings.ToLower(helpers.GetFileExtension(entryPath))
+			if ext == "m4b" || !helpers.IsAudiobookExtension(ext) {
+				continue
+			}
+
+			fileInfo, err := entry.Info()
+			if err != nil {
+				return book, err
+			}
+
+			book.files = append(book.files, audiobookFile{path: entryPath, ext: ext, size: fileInfo.Size()})
+			book.size += fileInfo.Size()
+		}
+	}
+
+	sortAudiobookFiles(book.files)
+	return book, nil
+}
+
+// sortAudiobookFiles puts a book's files in playback order: by folder, then by file
+// name with numbers compared by value, so "Chapter 2" comes before "Chapter 10".
+func sortAudiobookFiles(files []audiobookFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		dirI, dirJ := filepath.Dir(files[i].path), filepath.Dir(files[j].path)
+		if dirI != dirJ {
+			return naturalLess(dirI, dirJ)
+		}
+		return naturalLess(filepath.Base(files[i].path), filepath.Base(files[j].path))
+	})
+}
+
+// naturalLess compares strings case-insensitively, with runs of digits compared as numbers.
+func naturalLess(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+
+	for a != "" && b != "" {
+		digitsA := len(a) - len(strings.TrimLeftFunc(a, unicode.IsDigit))
+		digitsB := len(b) - len(strings.TrimLeftFunc(b, unicode.IsDigit))
+
+		if digitsA > 0 && digitsB > 0 {
+			numA := strings.TrimLeft(a[:digitsA], "0")
+			numB := strings.TrimLeft(b[:digitsB], "0")
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+			if numA != numB {
+				return numA < numB
+			}
+			a, b = a[digitsA:], b[digitsB:]
+			continue
+		}
+
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+
+	return len(a) < len(b)
+}
+
+// processAudiobooksBatch processes a batch of books within a single transaction.
+// Uses skip-on-error strategy: failed books don't rollback successful ones.
+// Holds ScannerDBMu so only one scanner writes to the DB at a time.
+func (app *Application) processAudiobooksBatch(ctx context.Context, books []audiobookDir) (scanned, skipped, errCount int) {
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error(fmt.Sprintf("failed to start transaction: %s", err.Error()))
+		return 0, 0, len(books)
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+	ledger := newScanLedger(helpers.SCAN_LIBRARY_AUDIOBOOKS)
+
+	for _, book := range books {
+		if checkAudiobookUnchanged(ctx, qtx, book) {
+			skipped++
+			continue
+		}
+
+		err = manageSavepoint(ctx, tx, fmt.Sprintf("sp_book_%d", scanned+skipped+errCount), func() error {
+			return app.processAudiobook(ctx, qtx, book)
+		})
+		if err != nil {
+			app.Logger.Error(fmt.Sprintf("failed to process %s: %s", book.path, err.Error()))
+			ledger.fail(book.path, err)
+			errCount++
+			continue
+		}
+
+		ledger.succeed(book.path)
+		scanned++
+	}
+
+	err = tx.Commit()
+	app.writeScanLedger(ctx, ledger, err)
+	if err != nil {
+		app.Logger.Error(fmt.Sprintf("failed to commit batch: %s", err.Error()))
+		return 0, 0, len(books)
+	}
+
+	return scanned, skipped, errCount
+}
+
+// checkAudiobookUnchanged reports whether a book exists with the same total size and
+// number of files (likely unchanged). A folder split into single-file books is
+// unchanged when each of its files is, also once only one of them is left.
+func checkAudiobookUnchanged(ctx context.Context, qtx *database.Queries, book audiobookDir) bool {
+	_, err := qtx.CheckAudiobookUnchanged(ctx, database.CheckAudiobookUnchangedParams{
+		Path:      book.path,
+		Size:      book.size,
+		FileCount: int64(len(book.files)),
+	})
+	if err == nil {
+		return true
+	}
+
+	if len(book.files) == 0 || book.path == book.files[0].path {
+		return false
+	}
+
+	for _, file := range book.files {
+		_, err := qtx.CheckAudiobookUnchanged(ctx, database.CheckAudiobookUnchangedParams{
+			Path:      file.path,
+			Size:      file.size,
+			FileCount: 1,
+		})
+		if err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// processAudiobook probes a book's files and stores the book (see saveAudiobook). The
+// files of a folder tagged with different albums are single-file books sharing their
+// author's folder, so each of them is stored as a book of its own.
+func (app *Application) processAudiobook(ctx context.Context, qtx *database.Queries, book audiobookDir) error {
+	if len(book.files) == 0 {
+		return errors.New("book has no audio files")
+	}
+
+	probes := make([]*ffprobe.FfprobeResult, len(book.files))
+	albums := make(map[string]bool)
+	for i, file := range book.files {
+		info, err := app.Ffprobe.GetMetadata(file.path)
+		if err != nil {
+			return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("ffprobe failed: %w", err))
+		}
+		probes[i] = info
+
+		if album := strings.TrimSpace(info.AudioTags().Album); album != "" {
+			albums[strings.ToLower(album)] = true
+		}
+	}
+
+	if book.path == book.files[0].path || len(albums) < 2 {
+		return app.saveAudiobook(ctx, qtx, book, probes)
+	}
+
+	for i, file := range book.files {
+		single := audiobookDir{path: file.path, files: []audiobookFile{file}, size: file.size}
+		if err := app.saveAudiobook(ctx, qtx, single, probes[i:i+1]); err != nil {
+			return fmt.Errorf("%s: %w", file.path, err)
+		}
+	}
+
+	return nil
+}
+
+// saveAudiobook upserts a probed book, its author, its files and its chapters.
+// Chapters come from the files' chapter markers, as m4b files have them; files
+// without markers count as one chapter each.
+func (app *Application) saveAudiobook(ctx context.Context, qtx *database.Queries, book audiobookDir, probes []*ffprobe.FfprobeResult) error {
+	// The book is described by its first file
+	tags := probes[0].AudioTags()
+	singleFile := book.path == book.files[0].path
+
+	params := database.UpsertAudiobookParams{
+		Path: book.path,
+		Size: book.size,
+	}
+
+	// Title - the album of a folder of chapter files, the title of a single file,
+	// then the folder or file name
+	name := filepath.Base(book.path)
+	if singleFile {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		params.Title = firstNonEmpty(tags.Title, tags.Album, name)
+		params.SortTitle = firstNonEmpty(tags.SortName, params.Title)
+	} else {
+		params.Title = firstNonEmpty(tags.Album, name)
+		params.SortTitle = firstNonEmpty(tags.SortAlbum, params.Title)
+	}
+
+	// Author - album artist, or artist
+	if author := firstNonEmpty(tags.AlbumArtist, tags.Artist); author != "" {
+		row, err := qtx.UpsertAuthor(ctx, database.UpsertAuthorParams{
+			Name:     author,
+			SortName: firstNonEmpty(tags.SortArtist, author),
+		})
+		if err != nil {
+			return fmt.Errorf("author failed: %w", err)
+		}
+		params.AuthorID = sql.NullInt64{Int64: row.ID, Valid: true}
+	}
+
+	// Narrators are often tagged as composer where there's no narrator tag
+	params.Narrator = helpers.NullString(firstNonEmpty(tags.Narrator, tags.Composer))
+	params.Series = helpers.NullString(tags.Series)
+	if index, err := strconv.ParseFloat(strings.TrimSpace(tags.SeriesPart), 64); err == nil {
+		params.SeriesIndex = sql.NullFloat64{Float64: index, Valid: true}
+	}
+	params.Description = helpers.NullString(firstNonEmpty(tags.Synopsis, tags.Description, tags.Comment))
+
+	if tags.Date != "" {
+		date, err := helpers.ParseDate(tags.Date)
+		if err == nil {
+			params.Year = sql.NullInt64{Int64: int64(date.Year()), Valid: true}
+		}
+	}
+
+	// Files follow each other, so each one starts where the previous one ended
+	fileParams := make([]database.UpsertAudiobookFileParams, len(book.files))
+	for i, file := range book.files {
+		audio := audioTrackParams(probes[i], file.path, file.ext)
+
+		fileParams[i] = database.UpsertAudiobookFileParams{
+			FilePath:    file.path,
+			FileName:    audio.FileName,
+			FileIndex:   int64(i),
+			Container:   audio.Container,
+			MimeType:    audio.MimeType,
+			Codec:       audio.Codec,
+			Size:        file.size,
+			Duration:    audio.Duration,
+			BitRate:     audio.BitRate,
+			StartOffset: params.Duration,
+		}
+		params.Duration += audio.Duration
+	}
+
+	audiobook, err := qtx.UpsertAudiobook(ctx, params)
+	if err != nil {
+		return fmt.Errorf("upsert audiobook failed: %w", err)
+	}
+
+	fileIDs := make([]int64, len(fileParams))
+	paths := make([]string, len(fileParams))
+	for i, fp := range fileParams {
+		fp.AudiobookID = audiobook.ID
+
+		file, err := qtx.UpsertAudiobookFile(ctx, fp)
+		if err != nil {
+			return fmt.Errorf("upsert audiobook file failed: %w", err)
+		}
+		fileIDs[i] = file.ID
+		paths[i] = fp.FilePath
+	}
+
+	pathsJSON, err := json.Marshal(paths)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.DeleteStaleAudiobookFiles(ctx, database.DeleteStaleAudiobookFilesParams{
+		AudiobookID: audiobook.ID,
+		FilePaths:   string(pathsJSON),
+	})
+	if err != nil {
+		return fmt.Errorf("delete stale audiobook files failed: %w", err)
+	}
+
+	// Chapters are rebuilt on every scan. Listening progress is kept as a position in
+	// the book, so it survives chapters moving around.
+	if err := qtx.DeleteAudiobookChapters(ctx, audiobook.ID); err != nil {
+		return fmt.Errorf("delete chapters failed: %w", err)
+	}
+
+	var chapterIndex int64
+	for i, info := range probes {
+		offset := fileParams[i].StartOffset
+
+		for _, chapter := range audiobookChapters(info, fileParams[i], singleFile, params.Title) {
+			title := chapter.title
+			if title == "" {
+				title = fmt.Sprintf("Chapter %d", chapterIndex+1)
+			}
+
+			err := qtx.CreateAudiobookChapter(ctx, database.CreateAudiobookChapterParams{
+				AudiobookID:  audiobook.ID,
+				FileID:       fileIDs[i],
+				ChapterIndex: chapterIndex,
+				Title:        title,
+				StartTime:    offset + chapter.start,
+				EndTime:      offset + chapter.end,
+			})
+			if err != nil {
+				return fmt.Errorf("create chapter failed: %w", err)
+			}
+			chapterIndex++
+		}
+	}
+
+	return nil
+}
+
+// fileChapter is a chapter within one file, in milliseconds from the file's start.
+type fileChapter struct {
+	title      string
+	start, end int64
+}
+
+// audiobookChapters returns the chapters of one of a book's files. Without chapter
+// markers the whole file is one chapter, named after its title tag or file name,
+// or after the book for single-file books.
+func audiobookChapters(info *ffprobe.FfprobeResult, file database.UpsertAudiobookFileParams, singleFile bool, bookTitle string) []fileChapter {
+	var chapters []fileChapter
+	for _, chapter := range info.Chapters {
+		start, err := helpers.ParseDurationMs(chapter.StartTime)
+		if err != nil {
+			continue
+		}
+
+		end, err := helpers.ParseDurationMs(chapter.EndTime)
+		if err != nil || end > file.Duration && file.Duration > 0 {
+			end = file.Duration
+		}
+
+		if end <= start {
+			continue
+		}
+
+		chapters = append(chapters, fileChapter{title: strings.TrimSpace(chapter.Tags.Title), start: start, end: end})
+	}
+
+	if len(chapters) > 0 {
+		return chapters
+	}
+
+	title := bookTitle
+	if !singleFile {
+		title = firstNonEmpty(info.AudioTags().Title, strings.TrimSuffix(file.FileName, filepath.Ext(file.FileName)))
+	}
+
+	return []fileChapter{{title: title, start: 0, end: file.Duration}}
+}
+
+// firstNonEmpty returns the first of values that isn't blank, trimmed.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+)
+
+// fakeAudiobookProbe is what fakeAudiobookFfprobe reports for one file.
+type fakeAudiobookProbe struct {
+	duration string
+	codec    string
+	tags     ffprobe.FormatTags
+	chapters []ffprobe.Chapter
+}
+
+// fakeAudiobookFfprobe returns canned metadata per file, with chapter markers.
+type fakeAudiobookFfprobe struct {
+	files map[string]fakeAudiobookProbe
+}
+
+func (f *fakeAudiobookFfprobe) GetMetadata(filePath string) (*ffprobe.FfprobeResult, error) {
+	probe := f.files[filePath]
+
+	codec := probe.codec
+	if codec == "" {
+		codec = "mp3"
+	}
+
+	return &ffprobe.FfprobeResult{
+		Format:   ffprobe.Format{Duration: probe.duration, BitRate: "64000", Tags: probe.tags},
+		Streams:  []ffprobe.Stream{{Index: 0, CodecType: "audio", CodecName: codec, Channels: 1}},
+		Chapters: probe.chapters,
+	}, nil
+}
+
+// fakeChapter builds an ffprobe chapter from start and end in seconds.
+func fakeChapter(start, end, title string) ffprobe.Chapter {
+	chapter := ffprobe.Chapter{StartTime: start, EndTime: end}
+	chapter.Tags.Title = title
+	return chapter
+}
+
+// writeAudiobookFiles creates files under root, creating their folders as needed.
+func writeAudiobookFiles(t *testing.T, root string, paths ...string) {
+	t.Helper()
+
+	for _, path := range paths {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(full), err)
+		}
+		if err := os.WriteFile(full, []byte("audio"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", full, err)
+		}
+	}
+}
+
+// TestScanAudiobooksLibrary tests that multi-file books are grouped per folder with
+// their disc folders, that m4b files and loose files are books of their own, and that
+// chapters come from chapter markers or, without them, from the files.
+func TestScanAudiobooksLibrary(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	root := t.TempDir()
+	settings := *app.Settings()
+	settings.AudiobooksDir = helpers.NullString(root)
+	app.SetSettings(&settings)
+
+	writeAudiobookFiles(t, root,
+		"Author A/Book One/CD2/01.mp3",
+		"Author A/Book One/CD1/10.mp3",
+		"Author A/Book One/CD1/2.mp3",
+		"Author A/Book One/cover.jpg",
+		"Author B/Long Book.m4b",
+		"Author B/Short Book.m4b",
+		"Loose.mp3",
+	)
+
+	path := func(rel string) string { return filepath.Join(root, rel) }
+
+	bookOneTags := ffprobe.FormatTags{
+		Album: "Book One", Artist: "Author A", Composer: "Reader N",
+		Series: "The Saga", SeriesPart: "2", Date: "2019", Comment: "A long story",
+	}
+	withTitle := func(tags ffprobe.FormatTags, title string) ffprobe.FormatTags {
+		tags.Title = title
+		return tags
+	}
+
+	app.Ffprobe = &fakeAudiobookFfprobe{files: map[string]fakeAudiobookProbe{
+		path("Author A/Book One/CD1/2.mp3"):  {duration: "60.000", tags: withTitle(bookOneTags, "Opening")},
+		path("Author A/Book One/CD1/10.mp3"): {duration: "90.500", tags: bookOneTags},
+		path("Author A/Book One/CD2/01.mp3"): {duration: "30.000", tags: withTitle(bookOneTags, "Epilogue")},
+		path("Author B/Long Book.m4b"): {
+			duration: "600.000",
+			codec:    "aac",
+			tags:     ffprobe.FormatTags{Title: "Long Book", AlbumArtist: "Author B", Narrator: "Reader M", Synopsis: "Full synopsis", Description: "Short"},
+			chapters: []ffprobe.Chapter{
+				fakeChapter("0.000000", "120.000000", "Prologue"),
+				fakeChapter("120.000000", "400.000000", ""),
+				fakeChapter("400.000000", "600.000000", "Finale"),
+			},
+		},
+		path("Author B/Short Book.m4b"): {duration: "45.000", codec: "aac", tags: ffprobe.FormatTags{Artist: "Author B"}},
+		path("Loose.mp3"):               {duration: "10.000"},
+	}}
+
+	app.ScanAudiobooksLibrary()
+
+	ctx := context.Background()
+
+	audiobooks, err := app.Queries.GetAudiobooksAlphabetical(ctx, database.GetAudiobooksAlphabeticalParams{UserID: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to get audiobooks: %v", err)
+	}
+
+	books := make(map[string]database.GetAudiobooksAlphabeticalRow)
+	var titles []string
+	for _, book := range audiobooks {
+		books[book.Title] = book
+		titles = append(titles, book.Title)
+	}
+
+	if expected := []string{"Book One", "Long Book", "Loose", "Short Book"}; !reflect.DeepEqual(titles, expected) {
+		t.Fatalf("Expected books %v, got %v", expected, titles)
+	}
+
+	bookOne := books["Book One"]
+	if bookOne.Path != path("Author A/Book One") || bookOne.Duration != 180_500 || bookOne.Size != 15 {
+		t.Errorf("Unexpected book: %+v", bookOne)
+	}
+	if bookOne.AuthorName.String != "Author A" || bookOne.Narrator.String != "Reader N" || bookOne.Series.String != "The Saga" ||
+		bookOne.SeriesIndex.Float64 != 2 || bookOne.Year.Int64 != 2019 || bookOne.Description.String != "A long story" {
+		t.Errorf("Unexpected book tags: %+v", bookOne)
+	}
+
+	files, err := app.Queries.GetAudiobookFiles(ctx, bookOne.ID)
+	if err != nil {
+		t.Fatalf("Failed to get files: %v", err)
+	}
+
+	var order []string
+	var offsets []int64
+	for _, file := range files {
+		order = append(order, file.FilePath)
+		offsets = append(offsets, file.StartOffset)
+	}
+
+	expectedOrder := []string{path("Author A/Book One/CD1/2.mp3"), path("Author A/Book One/CD1/10.mp3"), path("Author A/Book One/CD2/01.mp3")}
+	if !reflect.DeepEqual(order, expectedOrder) || !reflect.DeepEqual(offsets, []int64{0, 60_000, 150_500}) {
+		t.Errorf("Expected files %v at 0, 60000, 150500, got %v at %v", expectedOrder, order, offsets)
+	}
+
+	chapterTimes := func(id int64) ([]string, [][2]int64) {
+		t.Helper()
+		chapters, err := app.Queries.GetAudiobookChapters(ctx, id)
+		if err != nil {
+			t.Fatalf("Failed to get chapters: %v", err)
+		}
+
+		var titles []string
+		var times [][2]int64
+		for _, chapter := range chapters {
+			titles = append(titles, chapter.Title)
+			times = append(times, [2]int64{chapter.StartTime, chapter.EndTime})
+		}
+		return titles, times
+	}
+
+	titles, times := chapterTimes(bookOne.ID)
+	if expected := []string{"Opening", "10", "Epilogue"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("Expected chapters %v, got %v", expected, titles)
+	}
+	if expected := [][2]int64{{0, 60_000}, {60_000, 150_500}, {150_500, 180_500}}; !reflect.DeepEqual(times, expected) {
+		t.Errorf("Expected chapter times %v, got %v", expected, times)
+	}
+
+	longBook := books["Long Book"]
+	if longBook.Path != path("Author B/Long Book.m4b") || longBook.Narrator.String != "Reader M" || longBook.Description.String != "Full synopsis" {
+		t.Errorf("Unexpected m4b book: %+v", longBook)
+	}
+
+	titles, times = chapterTimes(longBook.ID)
+	if expected := []string{"Prologue", "Chapter 2", "Finale"}; !reflect.DeepEqual(titles, expected) {
+		t.Errorf("Expected chapters %v, got %v", expected, titles)
+	}
+	if expected := [][2]int64{{0, 120_000}, {120_000, 400_000}, {400_000, 600_000}}; !reflect.DeepEqual(times, expected) {
+		t.Errorf("Expected chapter times %v, got %v", expected, times)
+	}
+
+	if titles, _ = chapterTimes(books["Short Book"].ID); !reflect.DeepEqual(titles, []string{"Short Book"}) {
+		t.Errorf("Expected a single chapter named after the book, got %v", titles)
+	}
+	if books["Short Book"].AuthorID != longBook.AuthorID {
+		t.Errorf("Expected both m4b books to share their author")
+	}
+
+	// Rescanning an unchanged book skips it, a book with a new file is rescanned
+	book, err := readAudiobookDir(path("Author A/Book One"))
+	if err != nil {
+		t.Fatalf("Failed to read book: %v", err)
+	}
+	if _, skipped, _ := app.processAudiobooksBatch(ctx, []audiobookDir{book}); skipped != 1 {
+		t.Errorf("Expected the unchanged book to be skipped, got %d", skipped)
+	}
+
+	if err := os.Remove(path("Author A/Book One/CD2/01.mp3")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	book, err = readAudiobookDir(path("Author A/Book One"))
+	if err != nil {
+		t.Fatalf("Failed to read book: %v", err)
+	}
+	if scanned, _, _ := app.processAudiobooksBatch(ctx, []audiobookDir{book}); scanned != 1 {
+		t.Fatalf("Expected the changed book to be rescanned, got %d", scanned)
+	}
+
+	if files, _ = app.Queries.GetAudiobookFiles(ctx, bookOne.ID); len(files) != 2 {
+		t.Errorf("Expected the removed file to be dropped, got %d files", len(files))
+	}
+	if titles, _ = chapterTimes(bookOne.ID); len(titles) != 2 {
+		t.Errorf("Expected 2 chapters after the rescan, got %v", titles)
+	}
+}
+
+// TestScanAudiobooksLibrary_SplitAndRemove tests that single-file books sharing their
+// author's folder stay apart, that ignore patterns apply, and that books whose files
+// are gone are removed.
+func TestScanAudiobooksLibrary_SplitAndRemove(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	root := t.TempDir()
+	settings := *app.Settings()
+	settings.AudiobooksDir = helpers.NullString(root)
+	settings.AudiobooksIgnorePatterns = sql.NullString{String: "Extras/", Valid: true}
+	app.SetSettings(&settings)
+
+	writeAudiobookFiles(t, root,
+		"Author A/First Book.mp3",
+		"Author A/Second Book.mp3",
+		"Author B/Only Book/01.mp3",
+		"Author B/Only Book/02.mp3",
+		"Author B/Extras/Interview.mp3",
+	)
+
+	path := func(rel string) string { return filepath.Join(root, rel) }
+
+	app.Ffprobe = &fakeAudiobookFfprobe{files: map[string]fakeAudiobookProbe{
+		path("Author A/First Book.mp3"):   {duration: "10.000", tags: ffprobe.FormatTags{Album: "First Book", Artist: "Author A"}},
+		path("Author A/Second Book.mp3"):  {duration: "20.000", tags: ffprobe.FormatTags{Album: "Second Book", Artist: "Author A"}},
+		path("Author B/Only Book/01.mp3"): {duration: "30.000", tags: ffprobe.FormatTags{Album: "Only Book", Artist: "Author B"}},
+		path("Author B/Only Book/02.mp3"): {duration: "30.000", tags: ffprobe.FormatTags{Album: "Only Book", Artist: "Author B"}},
+	}}
+
+	ctx := context.Background()
+
+	bookTitles := func() map[string]string {
+		t.Helper()
+		audiobooks, err := app.Queries.GetAudiobooksAlphabetical(ctx, database.GetAudiobooksAlphabeticalParams{UserID: 1, Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to get audiobooks: %v", err)
+		}
+
+		paths := make(map[string]string)
+		for _, book := range audiobooks {
+			paths[book.Title] = book.Path
+		}
+		return paths
+	}
+
+	app.ScanAudiobooksLibrary()
+
+	expected := map[string]string{
+		"First Book":  path("Author A/First Book.mp3"),
+		"Second Book": path("Author A/Second Book.mp3"),
+		"Only Book":   path("Author B/Only Book"),
+	}
+	if paths := bookTitles(); !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("Expected books %v, got %v", expected, paths)
+	}
+
+	// The split folder is unchanged as long as each of its books is
+	book, err := readAudiobookDir(path("Author A"))
+	if err != nil {
+		t.Fatalf("Failed to read book: %v", err)
+	}
+	if _, skipped, _ := app.processAudiobooksBatch(ctx, []audiobookDir{book}); skipped != 1 {
+		t.Errorf("Expected the split folder to be skipped, got %d", skipped)
+	}
+
+	if err := os.Remove(path("Author A/Second Book.mp3")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := os.RemoveAll(path("Author B/Only Book")); err != nil {
+		t.Fatalf("Failed to remove folder: %v", err)
+	}
+
+	app.ScanAudiobooksLibrary()
+
+	expected = map[string]string{"First Book": path("Author A/First Book.mp3")}
+	if paths := bookTitles(); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected the missing books to be removed, got %v", paths)
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	names := []string{"Chapter 10.mp3", "chapter 2.mp3", "Chapter 1.mp3", "Chapter 02b.mp3", "Appendix.mp3", "Chapter 001.mp3"}
+	sort.SliceStable(names, func(i, j int) bool { return naturalLess(names[i], names[j]) })
+
+	expected := []string{"Appendix.mp3", "Chapter 1.mp3", "Chapter 001.mp3", "chapter 2.mp3", "Chapter 02b.mp3", "Chapter 10.mp3"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected %v, got %v", expected, names)
+	}
+}
+
+func TestAudiobookPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/books/Author/Book/01.mp3", "/books/Author/Book"},
+		{"/books/Author/Book/Disc 2/01.mp3", "/books/Author/Book"},
+		{"/books/Author/Book.m4b", "/books/Author/Book.m4b"},
+		{"/books/Single.mp3", "/books/Single.mp3"},
+	}
+
+	for _, tt := range tests {
+		ext := helpers.GetFileExtension(tt.path)
+		if got := audiobookPath("/books/", tt.path, ext); got != tt.expected {
+			t.Errorf("audiobookPath(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// validGenreTypes mirrors the genre_type CHECK constraint on the genres table
+var validGenreTypes = map[string]bool{
+	"movie": true,
+	"show":  true,
+	"music": true,
+}
+
+type MergeGenresRequest struct {
+	SourceIDs []int64 `json:"source_ids"`
+	TargetID  int64   `json:"target_id"`
+}
+
+type AddGenreAliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// GetGenres returns every genre of a type (?type=music by default) with usage counts
+// and the normalized aliases that resolve to it
+func (app *Application) GetGenres(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	genreType := r.URL.Query().Get("type")
+	if genreType == "" {
+		genreType = "music"
+	}
+
+	if !validGenreTypes[genreType] {
+		helpers.ErrorJSON(w, errors.New("invalid genre type"), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := app.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch genres"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	genres, err := qtx.GetGenresWithCounts(ctx, genreType)
+	if err != nil {
+		app.Logger.Error("failed to get genres", "error", err, "type", genreType)
+		helpers.ErrorJSON(w, errors.New("failed to fetch genres"))
+		return
+	}
+
+	// All aliases of the type are fetched at once and grouped by genre
+	aliasRows, err := qtx.GetGenreAliasesByType(ctx, genreType)
+	if err != nil {
+		app.Logger.Error("failed to get genre aliases", "error", err, "type", genreType)
+		helpers.ErrorJSON(w, errors.New("failed to fetch genres"))
+		return
+	}
+
+	aliases := make(map[int64][]string, len(genres))
+	for _, alias := range aliasRows {
+		aliases[alias.GenreID] = append(aliases[alias.GenreID], alias.Alias)
+	}
+
+	type GenreResponse struct {
+		database.GetGenresWithCountsRow
+		Aliases []string `json:"aliases"`
+	}
+
+	response := make([]GenreResponse, 0, len(genres))
+	for _, genre := range genres {
+		genreAliases := aliases[genre.ID]
+		if genreAliases == nil {
+			genreAliases = []string{}
+		}
+
+		response = append(response, GenreResponse{
+			GetGenresWithCountsRow: genre,
+			Aliases:                genreAliases,
+		})
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"genres": response,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// MergeGenres folds one or more source genres into a target genre.
+// Tracks, albums, musicians and movies are re-linked to the target, the sources'
+// aliases (and their own normalized names) are pointed at the target so future
+// scans resolve to it, and the source genres are deleted.
+func (app *Application) MergeGenres(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req MergeGenresRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.TargetID == 0 || len(req.SourceIDs) == 0 {
+		helpers.ErrorJSON(w, errors.New("source_ids and target_id are required"), http.StatusBadRequest)
+		return
+	}
+
+	// Serialize with the scanners, which link genres inside their own batch transactions
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to merge genres"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	target, err := qtx.GetGenreByID(ctx, req.TargetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("target genre not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get target genre", "error", err, "id", req.TargetID)
+		helpers.ErrorJSON(w, errors.New("failed to merge genres"))
+		return
+	}
+
+	merged := 0
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == target.ID {
+			continue
+		}
+
+		source, err := qtx.GetGenreByID(ctx, sourceID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				helpers.ErrorJSON(w, errors.New("source genre not found"), http.StatusNotFound)
+				return
+			}
+
+			app.Logger.Error("failed to get source genre", "error", err, "id", sourceID)
+			helpers.ErrorJSON(w, errors.New("failed to merge genres"))
+			return
+		}
+
+		if source.GenreType != target.GenreType {
+			helpers.ErrorJSON(w, errors.New("genres of different types cannot be merged"), http.StatusBadRequest)
+			return
+		}
+
+		if err := app.mergeGenre(ctx, qtx, source, target); err != nil {
+			app.Logger.Error("failed to merge genre", "error", err, "source_id", source.ID, "target_id", target.ID)
+			helpers.ErrorJSON(w, errors.New("failed to merge genres"))
+			return
+		}
+
+		merged++
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit genre merge", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to merge genres"))
+		return
+	}
+
+	app.Logger.Info("genres merged", "target_id", target.ID, "target", target.Tag, "merged", merged)
+
+	res := helpers.JSONResponse{
+		Error:   false,
+		Message: "Genres merged successfully",
+		Data: map[string]any{
+			"genre":  target,
+			"merged": merged,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// mergeGenre moves every link and alias of source onto target and deletes source.
+// Junction rows that would duplicate an existing target link are skipped by the
+// UPDATE OR IGNORE queries and removed by the cascade when source is deleted.
+func (app *Application) mergeGenre(ctx context.Context, qtx *database.Queries, source, target database.Genre) error {
+	if err := qtx.MergeTrackGenres(ctx, database.MergeTrackGenresParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeAlbumGenres(ctx, database.MergeAlbumGenresParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeMusicianGenres(ctx, database.MergeMusicianGenresParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeMovieGenres(ctx, database.MergeMovieGenresParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeGenreAliases(ctx, database.MergeGenreAliasesParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	// The source tag itself may never have been aliased (e.g. genres created before aliases existed)
+	if key := helpers.NormalizeKey(source.Tag); key != "" {
+		err := qtx.UpsertGenreAlias(ctx, database.UpsertGenreAliasParams{
+			Alias:     key,
+			GenreType: target.GenreType,
+			GenreID:   target.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return qtx.DeleteGenre(ctx, source.ID)
+}
+
+// AddGenreAlias makes an additional spelling resolve to an existing genre on future scans
+func (app *Application) AddGenreAlias(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid genre id"), http.StatusBadRequest)
+		return
+	}
+
+	var req AddGenreAliasRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	key := helpers.NormalizeKey(req.Alias)
+	if key == "" {
+		helpers.ErrorJSON(w, errors.New("alias must contain letters or digits"), http.StatusBadRequest)
+		return
+	}
+
+	genre, err := app.Queries.GetGenreByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("genre not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get genre", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to add genre alias"))
+		return
+	}
+
+	err = app.Queries.UpsertGenreAlias(ctx, database.UpsertGenreAliasParams{
+		Alias:     key,
+		GenreType: genre.GenreType,
+		GenreID:   genre.ID,
+	})
+	if err != nil {
+		app.Logger.Error("failed to add genre alias", "error", err, "id", id, "alias", key)
+		helpers.ErrorJSON(w, errors.New("failed to add genre alias"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error:   false,
+		Message: "Genre alias added successfully",
+		Data: map[string]any{
+			"genre_id": genre.ID,
+			"alias":    key,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
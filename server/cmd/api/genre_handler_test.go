@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"igloo/cmd/internal/database"
+)
+
+// TestResolveGenre_SpellingVariants tests that spelling variants resolve to the first genre created.
+func TestResolveGenre_SpellingVariants(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	first, err := app.resolveGenre(ctx, app.Queries, "Hip-Hop", "music")
+	if err != nil {
+		t.Fatalf("resolveGenre failed: %v", err)
+	}
+
+	for _, tag := range []string{"Hip Hop", "hiphop", "HIP-HOP"} {
+		genre, err := app.resolveGenre(ctx, app.Queries, tag, "music")
+		if err != nil {
+			t.Fatalf("resolveGenre(%q) failed: %v", tag, err)
+		}
+
+		if genre.ID != first.ID {
+			t.Errorf("resolveGenre(%q) = genre %d, want %d", tag, genre.ID, first.ID)
+		}
+	}
+
+	// The same spelling for another genre type is a separate genre
+	movieGenre, err := app.resolveGenre(ctx, app.Queries, "Hip Hop", "movie")
+	if err != nil {
+		t.Fatalf("resolveGenre failed: %v", err)
+	}
+
+	if movieGenre.ID == first.ID {
+		t.Error("Expected movie genre to be separate from music genre")
+	}
+}
+
+// TestMergeGenres_RelinksAndAliases tests that merging moves track and album links to the
+// target, drops duplicate links, deletes the source and aliases its name to the target.
+func TestMergeGenres_RelinksAndAliases(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	// In-memory databases are per connection, keep the transaction on the same one
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	target, err := app.Queries.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{Tag: "Rock", GenreType: "music"})
+	if err != nil {
+		t.Fatalf("Failed to create target genre: %v", err)
+	}
+
+	source, err := app.Queries.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{Tag: "Rock & Roll", GenreType: "music"})
+	if err != nil {
+		t.Fatalf("Failed to create source genre: %v", err)
+	}
+
+	album, err := app.Queries.UpsertAlbum(ctx, database.UpsertAlbumParams{Title: "Album", SortTitle: "Album"})
+	if err != nil {
+		t.Fatalf("Failed to create album: %v", err)
+	}
+
+	track, err := app.Queries.UpsertTrack(ctx, database.UpsertTrackParams{
+		Title:     "Track",
+		SortTitle: "Track",
+		FilePath:  "/music/track.flac",
+		FileName:  "track.flac",
+		Container: "flac",
+		Codec:     "flac",
+		Channels:  "2",
+		MimeType:  "audio/flac",
+		Size:      1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create track: %v", err)
+	}
+
+	// The track has both genres, the album only the source
+	for _, genreID := range []int64{target.ID, source.ID} {
+		err = app.Queries.CreateTrackGenre(ctx, database.CreateTrackGenreParams{TrackID: track.ID, GenreID: genreID})
+		if err != nil {
+			t.Fatalf("Failed to link track genre: %v", err)
+		}
+	}
+
+	err = app.Queries.UpsertAlbumGenre(ctx, database.UpsertAlbumGenreParams{AlbumID: album.ID, GenreID: source.ID})
+	if err != nil {
+		t.Fatalf("Failed to link album genre: %v", err)
+	}
+
+	body, _ := json.Marshal(MergeGenresRequest{SourceIDs: []int64{source.ID}, TargetID: target.ID})
+	req := httptest.NewRequest(http.MethodPost, "/api/genres/merge", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	app.MergeGenres(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var trackLinks int
+	err = app.DB.QueryRow("SELECT COUNT(*) FROM track_genres WHERE track_id = ? AND genre_id = ?", track.ID, target.ID).Scan(&trackLinks)
+	if err != nil {
+		t.Fatalf("Failed to count track genres: %v", err)
+	}
+
+	if trackLinks != 1 {
+		t.Errorf("Expected 1 track link to target, got %d", trackLinks)
+	}
+
+	albumGenres, err := app.Queries.GetGenresByAlbumIDDirect(ctx, album.ID)
+	if err != nil {
+		t.Fatalf("Failed to get album genres: %v", err)
+	}
+
+	if len(albumGenres) != 1 || albumGenres[0].ID != target.ID {
+		t.Errorf("Expected album to be linked to target genre only, got %+v", albumGenres)
+	}
+
+	if _, err := app.Queries.GetGenreByID(ctx, source.ID); err == nil {
+		t.Error("Expected source genre to be deleted")
+	}
+
+	resolved, err := app.resolveGenre(ctx, app.Queries, "Rock and Roll", "music")
+	if err != nil {
+		t.Fatalf("resolveGenre failed: %v", err)
+	}
+
+	if resolved.ID != target.ID {
+		t.Errorf("Expected merged spelling to resolve to target %d, got %d", target.ID, resolved.ID)
+	}
+}
+
+// TestMergeGenres_DifferentTypes tests that genres of different types cannot be merged.
+func TestMergeGenres_DifferentTypes(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	target, err := app.Queries.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{Tag: "Drama", GenreType: "movie"})
+	if err != nil {
+		t.Fatalf("Failed to create target genre: %v", err)
+	}
+
+	source, err := app.Queries.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{Tag: "Drama", GenreType: "music"})
+	if err != nil {
+		t.Fatalf("Failed to create source genre: %v", err)
+	}
+
+	body, _ := json.Marshal(MergeGenresRequest{SourceIDs: []int64{source.ID}, TargetID: target.ID})
+	req := httptest.NewRequest(http.MethodPost, "/api/genres/merge", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	app.MergeGenres(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if _, err := app.Queries.GetGenreByID(ctx, source.ID); err != nil {
+		t.Errorf("Expected source genre to remain, got %v", err)
+	}
+}
+
+// TestMigrateGenreAliases tests that genres created before aliases existed are merged
+// when they only differ in spelling, keeping the oldest, and that running it again
+// changes nothing.
+func TestMigrateGenreAliases(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	genres := map[string]database.Genre{}
+	for _, tag := range []string{"Hip Hop", "hip-hop", "Jazz"} {
+		genre, err := app.Queries.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{Tag: tag, GenreType: "music"})
+		if err != nil {
+			t.Fatalf("Failed to create genre %q: %v", tag, err)
+		}
+		genres[tag] = genre
+	}
+
+	track, err := app.Queries.UpsertTrack(ctx, database.UpsertTrackParams{
+		Title:     "Track",
+		SortTitle: "Track",
+		FilePath:  "/music/track.flac",
+		FileName:  "track.flac",
+		Container: "flac",
+		Codec:     "flac",
+		Channels:  "2",
+		MimeType:  "audio/flac",
+		Size:      1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create track: %v", err)
+	}
+
+	err = app.Queries.CreateTrackGenre(ctx, database.CreateTrackGenreParams{TrackID: track.ID, GenreID: genres["hip-hop"].ID})
+	if err != nil {
+		t.Fatalf("Failed to link track genre: %v", err)
+	}
+
+	for range 2 {
+		if err := app.migrateGenreAliases(ctx); err != nil {
+			t.Fatalf("migrateGenreAliases failed: %v", err)
+		}
+	}
+
+	if _, err := app.Queries.GetGenreByID(ctx, genres["hip-hop"].ID); err == nil {
+		t.Error("Expected the newer spelling to be merged away")
+	}
+
+	var linked int64
+	err = app.DB.QueryRow("SELECT genre_id FROM track_genres WHERE track_id = ?", track.ID).Scan(&linked)
+	if err != nil || linked != genres["Hip Hop"].ID {
+		t.Errorf("Expected the track to be linked to Hip Hop %d, got %d (%v)", genres["Hip Hop"].ID, linked, err)
+	}
+
+	for tag, expected := range map[string]int64{"HIPHOP": genres["Hip Hop"].ID, "jazz": genres["Jazz"].ID} {
+		resolved, err := app.resolveGenre(ctx, app.Queries, tag, "music")
+		if err != nil || resolved.ID != expected {
+			t.Errorf("Expected %q to resolve to %d, got %d (%v)", tag, expected, resolved.ID, err)
+		}
+	}
+}
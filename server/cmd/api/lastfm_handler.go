@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/lastfm"
+)
+
+var errLastfmNotConfigured = errors.New("last.fm is not configured")
+
+// UpdateNowPlayingRequest represents the request body for the now playing update.
+type UpdateNowPlayingRequest struct {
+	TrackID int64 `json:"track_id"`
+}
+
+// GetLastfmStatus returns whether the user linked a Last.fm account, and how many of
+// their plays wait to be scrobbled.
+func (app *Application) GetLastfmStatus(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+
+	data := map[string]any{
+		"configured": app.Lastfm != nil,
+		"linked":     false,
+		"username":   "",
+		"pending":    0,
+	}
+
+	session, err := app.Queries.GetLastfmSession(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		app.Logger.Error("failed to get lastfm session", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch last.fm status"))
+		return
+	}
+
+	if err == nil {
+		pending, err := app.Queries.CountLastfmScrobbles(ctx, userID)
+		if err != nil {
+			app.Logger.Error("failed to count lastfm scrobbles", "error", err)
+			helpers.ErrorJSON(w, errors.New("failed to fetch last.fm status"))
+			return
+		}
+
+		data["linked"] = true
+		data["username"] = session.Username
+		data["pending"] = pending
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  data,
+	}
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// StartLastfmAuth returns the Last.fm page the user grants access on. Last.fm sends
+// the user back to LastfmCallback with a state kept in the session, so another site
+// can't send the user there to link an account of its own.
+func (app *Application) StartLastfmAuth(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	if app.Lastfm == nil {
+		helpers.ErrorJSON(w, errLastfmNotConfigured, http.StatusServiceUnavailable)
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	state := rand.Text()
+	app.SessionManager.Put(r.Context(), helpers.COOKIE_LASTFM_STATE, state)
+	callback := scheme + "://" + r.Host + "/api/lastfm/callback?" + url.Values{"state": {state}}.Encode()
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  map[string]any{"url": app.Lastfm.AuthURL(callback)},
+	}
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// LastfmCallback exchanges the token Last.fm redirected the user with for a session,
+// then sends the user back to the account settings. Callbacks without the state
+// StartLastfmAuth put in the session are refused.
+func (app *Application) LastfmCallback(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	if app.Lastfm == nil {
+		helpers.ErrorJSON(w, errLastfmNotConfigured, http.StatusServiceUnavailable)
+		return
+	}
+
+	// The state is single use
+	state := app.SessionManager.PopString(r.Context(), helpers.COOKIE_LASTFM_STATE)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(r.URL.Query().Get("state"))) != 1 {
+		app.Logger.Warn("lastfm callback with an invalid state", "user_id", userID)
+		http.Redirect(w, r, "/settings/account?lastfm=error", http.StatusSeeOther)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		http.Redirect(w, r, "/settings/account?lastfm=denied", http.StatusSeeOther)
+		return
+	}
+
+	session, err := app.Lastfm.GetSession(token)
+	if err != nil {
+		app.Logger.Warn("failed to get lastfm session", "error", err, "user_id", userID)
+		http.Redirect(w, r, "/settings/account?lastfm=error", http.StatusSeeOther)
+		return
+	}
+
+	_, err = app.Queries.UpsertLastfmSession(r.Context(), database.UpsertLastfmSessionParams{
+		UserID:     userID,
+		Username:   session.Name,
+		SessionKey: session.Key,
+	})
+	if err != nil {
+		app.Logger.Error("failed to save lastfm session", "error", err)
+		http.Redirect(w, r, "/settings/account?lastfm=error", http.StatusSeeOther)
+		return
+	}
+
+	app.Logger.Info("lastfm account linked", "user_id", userID, "username", session.Name)
+	http.Redirect(w, r, "/settings/account?lastfm=linked", http.StatusSeeOther)
+}
+
+// UnlinkLastfm forgets the user's Last.fm session and drops the plays still waiting
+// to be scrobbled.
+func (app *Application) UnlinkLastfm(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	if err := app.unlinkLastfm(r.Context(), userID); err != nil {
+		app.Logger.Error("failed to unlink lastfm account", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to unlink last.fm account"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  map[string]any{"linked": false},
+	}
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// UpdateNowPlaying tells Last.fm the user started playing a track. Users without a
+// linked account get a "sent" of false, failures are logged and don't interrupt
+// playback.
+func (app *Application) UpdateNowPlaying(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateNowPlayingRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	if req.TrackID == 0 {
+		helpers.ErrorJSON(w, errors.New("track_id is required"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	sent := false
+	if app.Lastfm != nil {
+		session, track, err := app.lastfmScrobbleTarget(ctx, userID, req.TrackID)
+		if err != nil {
+			app.Logger.Error("failed to get lastfm now playing track", "error", err)
+		} else if session != nil && track != nil {
+			err = app.Lastfm.UpdateNowPlaying(session.SessionKey, *track)
+			switch {
+			case errors.Is(err, lastfm.ErrInvalidSession):
+				app.Logger.Warn("lastfm session was revoked, unlinking account", "user_id", userID)
+				if err := app.unlinkLastfm(ctx, userID); err != nil {
+					app.Logger.Error("failed to unlink lastfm account", "error", err)
+				}
+			case err != nil:
+				app.Logger.Warn("failed to update lastfm now playing", "error", err, "user_id", userID)
+			default:
+				sent = true
+			}
+		}
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  map[string]any{"sent": sent},
+	}
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// lastfmScrobbleTarget returns the user's Last.fm session and what Last.fm is told
+// about the track. Both are nil when the user has no linked account, the track is nil
+// when it has no artist to scrobble it under.
+func (app *Application) lastfmScrobbleTarget(ctx context.Context, userID, trackID int64) (*database.LastfmSession, *lastfm.Track, error) {
+	session, err := app.Queries.GetLastfmSession(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	info, err := app.Queries.GetTrackScrobbleInfo(ctx, trackID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &session, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	artist := info.Artist.String
+	if artist == "" {
+		artist = info.AlbumArtist.String
+	}
+	if artist == "" || info.Title == "" {
+		return &session, nil, nil
+	}
+
+	return &session, &lastfm.Track{
+		Artist:      artist,
+		Track:       info.Title,
+		Album:       info.Album.String,
+		AlbumArtist: info.AlbumArtist.String,
+		TrackNumber: int(info.TrackIndex),
+		Duration:    int(info.Duration / 1000),
+		MBID:        info.MusicbrainzTrackID.String,
+	}, nil
+}
+
+// unlinkLastfm deletes the user's Last.fm session and queued scrobbles.
+func (app *Application) unlinkLastfm(ctx context.Context, userID int64) error {
+	if err := app.Queries.DeleteLastfmScrobblesByUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return app.Queries.DeleteLastfmSession(ctx, userID)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/lastfm"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// fakeLastfm records what is sent to Last.fm and fails every request with err.
+type fakeLastfm struct {
+	err        error
+	nowPlaying []lastfm.Track
+	scrobbled  [][]lastfm.Scrobble
+}
+
+func (f *fakeLastfm) AuthURL(callbackURL string) string {
+	return "https://www.last.fm/api/auth/?cb=" + callbackURL
+}
+
+func (f *fakeLastfm) GetSession(token string) (*lastfm.Session, error) {
+	if token != "token" {
+		return nil, errors.New("invalid token")
+	}
+	return &lastfm.Session{Name: "freddie", Key: "session-key"}, nil
+}
+
+func (f *fakeLastfm) UpdateNowPlaying(sessionKey string, track lastfm.Track) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.nowPlaying = append(f.nowPlaying, track)
+	return nil
+}
+
+func (f *fakeLastfm) Scrobble(sessionKey string, scrobbles []lastfm.Scrobble) (*lastfm.ScrobbleResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.scrobbled = append(f.scrobbled, scrobbles)
+	return &lastfm.ScrobbleResult{Accepted: len(scrobbles)}, nil
+}
+
+// TestLastfmScrobbleEligible tests Last.fm's rules for counting a play.
+func TestLastfmScrobbleEligible(t *testing.T) {
+	tests := []struct {
+		name      string
+		duration  int64
+		played    int64
+		completed bool
+		eligible  bool
+	}{
+		{"half played", 180, 90, false, true},
+		{"less than half", 180, 60, false, false},
+		{"four minutes of a long track", 1200, 240, false, true},
+		{"completed", 180, 30, true, true},
+		{"too short", 30, 30, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if eligible := lastfmScrobbleEligible(tt.duration, tt.played, tt.completed); eligible != tt.eligible {
+				t.Errorf("Expected eligible %v, got %v", tt.eligible, eligible)
+			}
+		})
+	}
+}
+
+// TestLastfmScrobbling tests linking an account, that only eligible plays are queued,
+// that failed scrobbles stay queued until Last.fm takes them, and that a revoked
+// session unlinks the account.
+func TestLastfmScrobbling(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	app.SessionManager = scs.New()
+	fake := &fakeLastfm{}
+	app.Lastfm = fake
+
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		"/music/queen/01.flac": {Title: "Bohemian Rhapsody", Artist: "Queen", AlbumArtist: "Queen", Album: "A Night at the Opera", Track: "11/12"},
+	}}
+
+	ctx := context.Background()
+
+	if scanned, _, errCount := app.processMusicBatch(ctx, []trackFile{{path: "/music/queen/01.flac", ext: "flac", size: 4}}); scanned != 1 || errCount != 0 {
+		t.Fatalf("Expected the track to be scanned, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	var trackID int64
+	if err := app.DB.QueryRow("SELECT id FROM tracks WHERE file_path = ?", "/music/queen/01.flac").Scan(&trackID); err != nil {
+		t.Fatalf("Failed to get track: %v", err)
+	}
+
+	user, err := app.Queries.CreateUser(ctx, database.CreateUserParams{Name: "Freddie", Email: "freddie@example.com", Password: "x"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	request := func(handler http.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, audiobookRequest(t, app, method, target, user.ID, body, nil))
+		return rr
+	}
+
+	play := func(durationPlayed int64) {
+		t.Helper()
+		body := `{"track_id": ` + strconv.FormatInt(trackID, 10) + `, "duration_played": ` + strconv.FormatInt(durationPlayed, 10) + `}`
+		if rr := request(app.RecordPlayEvent, http.MethodPost, "/api/music/user-stats/play", body); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	pending := func() int64 {
+		t.Helper()
+		count, err := app.Queries.CountLastfmScrobbles(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("Failed to count scrobbles: %v", err)
+		}
+		return count
+	}
+
+	// Plays of unlinked accounts are not queued
+	play(120)
+	if count := pending(); count != 0 {
+		t.Errorf("Expected no scrobble for an unlinked account, got %d", count)
+	}
+
+	// link starts the auth and follows Last.fm back to the callback in the same
+	// session, with the state it was given unless another one is passed
+	link := func(state string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := audiobookRequest(t, app, http.MethodGet, "/api/lastfm/auth", user.ID, "", nil)
+		rr := httptest.NewRecorder()
+		app.StartLastfmAuth(rr, req)
+
+		var res struct {
+			Data struct {
+				URL string `json:"url"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatalf("Failed to decode the auth url: %v", err)
+		}
+		callback, err := url.Parse(strings.TrimPrefix(res.Data.URL, "https://www.last.fm/api/auth/?cb="))
+		if err != nil {
+			t.Fatalf("Failed to parse the callback url: %v", err)
+		}
+		if state == "" {
+			state = callback.Query().Get("state")
+		}
+
+		rr = httptest.NewRecorder()
+		target := callback.Path + "?" + url.Values{"state": {state}, "token": {"token"}}.Encode()
+		app.LastfmCallback(rr, httptest.NewRequest(http.MethodGet, target, nil).WithContext(req.Context()))
+		return rr
+	}
+
+	// A callback the user didn't start is refused
+	rr := link("forged")
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/settings/account?lastfm=error" {
+		t.Fatalf("Expected a redirect to the error settings, got %d to %q", rr.Code, rr.Header().Get("Location"))
+	}
+	rr = request(app.LastfmCallback, http.MethodGet, "/api/lastfm/callback?token=token", "")
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/settings/account?lastfm=error" {
+		t.Fatalf("Expected a redirect to the error settings, got %d to %q", rr.Code, rr.Header().Get("Location"))
+	}
+	if _, err := app.Queries.GetLastfmSession(ctx, user.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected no linked session, got %v", err)
+	}
+
+	rr = link("")
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/settings/account?lastfm=linked" {
+		t.Fatalf("Expected a redirect to the linked settings, got %d to %q", rr.Code, rr.Header().Get("Location"))
+	}
+
+	nowPlaying := `{"track_id": ` + strconv.FormatInt(trackID, 10) + `}`
+	if rr := request(app.UpdateNowPlaying, http.MethodPost, "/api/music/user-stats/now-playing", nowPlaying); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	if len(fake.nowPlaying) != 1 || fake.nowPlaying[0].Artist != "Queen" || fake.nowPlaying[0].Duration != 180 || fake.nowPlaying[0].TrackNumber != 11 {
+		t.Errorf("Expected Queen's 180s track 11 to be playing, got %+v", fake.nowPlaying)
+	}
+
+	play(60)
+	if count := pending(); count != 0 {
+		t.Errorf("Expected no scrobble for a third of the track, got %d", count)
+	}
+
+	play(120)
+	if count := pending(); count != 1 {
+		t.Fatalf("Expected one queued scrobble, got %d", count)
+	}
+
+	// Last.fm is unreachable, the play waits for a later attempt
+	fake.err = errors.New("connection refused")
+	app.SubmitLastfmScrobbles()
+
+	var attempts int64
+	var nextAttemptAt string
+	err = app.DB.QueryRow("SELECT attempts, next_attempt_at FROM lastfm_scrobbles WHERE user_id = ?", user.ID).Scan(&attempts, &nextAttemptAt)
+	if err != nil {
+		t.Fatalf("Expected the scrobble to stay queued: %v", err)
+	}
+	if now := time.Now().UTC().Format(time.DateTime); attempts != 1 || nextAttemptAt <= now {
+		t.Errorf("Expected one attempt and a later retry than %s, got %d and %s", now, attempts, nextAttemptAt)
+	}
+
+	// Not due yet
+	fake.err = nil
+	app.SubmitLastfmScrobbles()
+	if len(fake.scrobbled) != 0 {
+		t.Fatalf("Expected the scrobble to wait for its retry, got %v", fake.scrobbled)
+	}
+
+	if _, err := app.DB.Exec("UPDATE lastfm_scrobbles SET next_attempt_at = '2000-01-01 00:00:00'"); err != nil {
+		t.Fatalf("Failed to make the scrobble due: %v", err)
+	}
+	app.SubmitLastfmScrobbles()
+
+	if len(fake.scrobbled) != 1 || len(fake.scrobbled[0]) != 1 {
+		t.Fatalf("Expected one scrobble to be sent, got %v", fake.scrobbled)
+	}
+	if scrobble := fake.scrobbled[0][0]; scrobble.Track.Track != "Bohemian Rhapsody" || time.Since(time.Unix(scrobble.Timestamp, 0)) < 2*time.Minute {
+		t.Errorf("Expected Bohemian Rhapsody started two minutes ago, got %+v", scrobble)
+	}
+	if count := pending(); count != 0 {
+		t.Errorf("Expected the sent scrobble to leave the queue, got %d", count)
+	}
+
+	// Last.fm keeps refusing the play, it is dropped on the last attempt
+	play(120)
+	fake.err = errors.New("invalid API key")
+	_, err = app.DB.Exec("UPDATE lastfm_scrobbles SET attempts = ?", helpers.LASTFM_SCROBBLE_MAX_ATTEMPTS-2)
+	if err != nil {
+		t.Fatalf("Failed to set the attempts: %v", err)
+	}
+	app.SubmitLastfmScrobbles()
+	if count := pending(); count != 1 {
+		t.Fatalf("Expected the scrobble to be retried once more, got %d", count)
+	}
+	if _, err := app.DB.Exec("UPDATE lastfm_scrobbles SET next_attempt_at = '2000-01-01 00:00:00'"); err != nil {
+		t.Fatalf("Failed to make the scrobble due: %v", err)
+	}
+	app.SubmitLastfmScrobbles()
+	if count := pending(); count != 0 {
+		t.Errorf("Expected the refused scrobble to be dropped, got %d", count)
+	}
+
+	// The user revoked the session on Last.fm
+	play(120)
+	fake.err = lastfm.ErrInvalidSession
+	app.SubmitLastfmScrobbles()
+
+	if _, err := app.Queries.GetLastfmSession(ctx, user.ID); err == nil {
+		t.Error("Expected the revoked session to be unlinked")
+	}
+	if count := pending(); count != 0 {
+		t.Errorf("Expected the queue of the unlinked account to be dropped, got %d", count)
+	}
+}
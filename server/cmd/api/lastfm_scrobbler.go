@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/lastfm"
+)
+
+// lastfmScrobbleQueued wakes the scrobbler when a play is queued, so it goes out
+// without waiting for the next tick. Plays queued while it runs share one wake up.
+var lastfmScrobbleQueued = make(chan struct{}, 1)
+
+// lastfmScrobbleEligible reports whether Last.fm counts a play as a listen: the track
+// is longer than 30 seconds, and was played for half its length or 4 minutes, or to
+// the end. Both durations are in seconds.
+func lastfmScrobbleEligible(duration, played int64, completed bool) bool {
+	length := time.Duration(duration) * time.Second
+	if length <= helpers.LASTFM_MIN_TRACK_DURATION {
+		return false
+	}
+
+	return completed || time.Duration(played)*time.Second >= min(length/2, helpers.LASTFM_SCROBBLE_PLAYED_TIME)
+}
+
+// queueLastfmScrobble queues a play for the scrobbler when the user linked a Last.fm
+// account and the play meets Last.fm's rules. The play started durationPlayed
+// seconds ago.
+func (app *Application) queueLastfmScrobble(ctx context.Context, userID, trackID, durationPlayed int64, completed bool) error {
+	if app.Lastfm == nil {
+		return nil
+	}
+
+	session, track, err := app.lastfmScrobbleTarget(ctx, userID, trackID)
+	if err != nil || session == nil || track == nil {
+		return err
+	}
+
+	if !lastfmScrobbleEligible(int64(track.Duration), durationPlayed, completed) {
+		return nil
+	}
+
+	err = app.Queries.CreateLastfmScrobble(ctx, database.CreateLastfmScrobbleParams{
+		UserID:      userID,
+		Artist:      track.Artist,
+		Track:       track.Track,
+		Album:       helpers.NullString(track.Album),
+		AlbumArtist: helpers.NullString(track.AlbumArtist),
+		TrackNumber: helpers.NullInt64(int64(track.TrackNumber)),
+		Duration:    helpers.NullInt64(int64(track.Duration)),
+		Mbid:        helpers.NullString(track.MBID),
+		PlayedAt:    time.Now().Unix() - durationPlayed,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case lastfmScrobbleQueued <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// RunLastfmScrobbler sends the queued scrobbles that are due, on every tick and
+// whenever a play is queued.
+func (app *Application) RunLastfmScrobbler() {
+	ticker := time.NewTicker(helpers.LASTFM_SCROBBLE_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-lastfmScrobbleQueued:
+		}
+
+		app.SubmitLastfmScrobbles()
+	}
+}
+
+// SubmitLastfmScrobbles sends the due scrobbles of every linked account, oldest first.
+// Sent plays leave the queue; when Last.fm can't be reached they wait longer after
+// every attempt, and plays too old for Last.fm are dropped. A revoked session unlinks
+// the account.
+func (app *Application) SubmitLastfmScrobbles() {
+	if app.Wait != nil {
+		app.Wait.Add(1)
+		defer app.Wait.Done()
+	}
+
+	if app.Lastfm == nil {
+		return
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := app.Queries.DeleteExpiredLastfmScrobbles(ctx, now.Add(-helpers.LASTFM_SCROBBLE_MAX_AGE).Unix()); err != nil {
+		app.Logger.Error("failed to delete expired lastfm scrobbles", "error", err)
+	}
+
+	due, err := app.Queries.GetDueLastfmScrobbles(ctx, database.GetDueLastfmScrobblesParams{
+		Now:   helpers.SQLiteTime(now),
+		Limit: helpers.LASTFM_SCROBBLE_BATCH_SIZE * 10,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get due lastfm scrobbles", "error", err)
+		return
+	}
+
+	// Rows come grouped by user, send each user's plays in batches
+	for start := 0; start < len(due); {
+		end := start + 1
+		for end < len(due) && end-start < helpers.LASTFM_SCROBBLE_BATCH_SIZE && due[end].UserID == due[start].UserID {
+			end++
+		}
+
+		app.submitLastfmBatch(ctx, due[start:end], now)
+		start = end
+	}
+}
+
+// submitLastfmBatch sends the scrobbles of one user and updates the queue with the
+// outcome.
+func (app *Application) submitLastfmBatch(ctx context.Context, batch []database.GetDueLastfmScrobblesRow, now time.Time) {
+	userID := batch[0].UserID
+
+	scrobbles := make([]lastfm.Scrobble, len(batch))
+	for i, s := range batch {
+		scrobbles[i] = lastfm.Scrobble{
+			Track: lastfm.Track{
+				Artist:      s.Artist,
+				Track:       s.Track,
+				Album:       s.Album.String,
+				AlbumArtist: s.AlbumArtist.String,
+				TrackNumber: int(s.TrackNumber.Int64),
+				Duration:    int(s.Duration.Int64),
+				MBID:        s.Mbid.String,
+			},
+			Timestamp: s.PlayedAt,
+		}
+	}
+
+	result, err := app.Lastfm.Scrobble(batch[0].SessionKey, scrobbles)
+	if errors.Is(err, lastfm.ErrInvalidSession) {
+		app.Logger.Warn("lastfm session was revoked, unlinking account", "user_id", userID)
+		if err := app.unlinkLastfm(ctx, userID); err != nil {
+			app.Logger.Error("failed to unlink lastfm account", "error", err)
+		}
+		return
+	}
+
+	if err != nil {
+		// Other errors, like a wrong API key, need the admin, so they get a few
+		// attempts before the plays are dropped
+		temporary := lastfm.IsTemporary(err)
+		if temporary {
+			app.Logger.Warn("failed to submit lastfm scrobbles, will retry", "error", err, "user_id", userID, "count", len(batch))
+		} else {
+			app.Logger.Error("failed to submit lastfm scrobbles", "error", err, "user_id", userID, "count", len(batch))
+		}
+
+		lastError := helpers.NullString(err.Error())
+		for _, s := range batch {
+			if !temporary && s.Attempts+1 >= helpers.LASTFM_SCROBBLE_MAX_ATTEMPTS {
+				app.Logger.Warn("dropping lastfm scrobble after too many failed attempts", "id", s.ID, "user_id", userID, "attempts", s.Attempts+1)
+				if err := app.Queries.DeleteLastfmScrobble(ctx, s.ID); err != nil {
+					app.Logger.Error("failed to delete failed lastfm scrobble", "error", err, "id", s.ID)
+				}
+				continue
+			}
+
+			delay := min(helpers.LASTFM_RETRY_BASE_DELAY<<min(s.Attempts, 16), helpers.LASTFM_MAX_RETRY_DELAY)
+			err := app.Queries.RetryLastfmScrobble(ctx, database.RetryLastfmScrobbleParams{
+				LastError:     lastError,
+				NextAttemptAt: helpers.SQLiteTime(now.Add(delay)),
+				ID:            s.ID,
+			})
+			if err != nil {
+				app.Logger.Error("failed to reschedule lastfm scrobble", "error", err, "id", s.ID)
+			}
+		}
+		return
+	}
+
+	for _, s := range batch {
+		if err := app.Queries.DeleteLastfmScrobble(ctx, s.ID); err != nil {
+			app.Logger.Error("failed to delete sent lastfm scrobble", "error", err, "id", s.ID)
+		}
+	}
+
+	app.Logger.Info("submitted lastfm scrobbles", "user_id", userID, "accepted", result.Accepted, "ignored", result.Ignored)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/listenbrainz"
+)
+
+// SetListenbrainzTokenRequest represents the request body for saving a ListenBrainz
+// user token.
+type SetListenbrainzTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// GetListenbrainzStatus returns whether the user saved a ListenBrainz token, how many
+// of their listens wait to be submitted, and how their history import went.
+func (app *Application) GetListenbrainzStatus(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+
+	_, importing := listenbrainzImports.Load(userID)
+	data := map[string]any{
+		"linked":            false,
+		"username":          "",
+		"pending":           0,
+		"importing":         importing,
+		"imported_at":       nil,
+		"imported_listens":  0,
+		"unmatched_listens": 0,
+	}
+
+	account, err := app.Queries.GetListenbrainzAccount(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		app.Logger.Error("failed to get listenbrainz account", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch listenbrainz status"))
+		return
+	}
+
+	if err == nil {
+		pending, err := app.Queries.CountListenbrainzListens(ctx, userID)
+		if err != nil {
+			app.Logger.Error("failed to count listenbrainz listens", "error", err)
+			helpers.ErrorJSON(w, errors.New("failed to fetch listenbrainz status"))
+			return
+		}
+
+		data["linked"] = true
+		data["username"] = account.Username
+		data["pending"] = pending
+		data["imported_listens"] = account.ImportedListens
+		data["unmatched_listens"] = account.UnmatchedListens
+		if account.ImportedAt.Valid {
+			data["imported_at"] = account.ImportedAt.String
+		}
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  data,
+	}
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// SetListenbrainzToken checks a user token with ListenBrainz and saves it, listens
+// are submitted from then on.
+func (app *Application) SetListenbrainzToken(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	var req SetListenbrainzTokenRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid request body"), http.StatusBadRequest)
+		return
+	}
+
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		helpers.ErrorJSON(w, errors.New("token is required"), http.StatusBadRequest)
+		return
+	}
+
+	username, err := app.Listenbrainz.ValidateToken(token)
+	if err != nil {
+		if errors.Is(err, listenbrainz.ErrInvalidToken) {
+			helpers.ErrorJSON(w, errors.New("listenbrainz doesn't know this token"), http.StatusBadRequest)
+			return
+		}
+		app.Logger.Warn("failed to validate listenbrainz token", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to reach listenbrainz"), http.StatusBadGateway)
+		return
+	}
+
+	account, err := app.Queries.UpsertListenbrainzAccount(r.Context(), database.UpsertListenbrainzAccountParams{
+		UserID:   userID,
+		Username: username,
+		Token:    token,
+	})
+	if err != nil {
+		app.Logger.Error("failed to save listenbrainz token", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to save listenbrainz token"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  map[string]any{"linked": true, "username": account.Username},
+	}
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// UnlinkListenbrainz forgets the user's token and drops the listens still waiting to
+// be submitted. Imported plays stay in the play history.
+func (app *Application) UnlinkListenbrainz(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	if err := app.unlinkListenbrainz(r.Context(), userID); err != nil {
+		app.Logger.Error("failed to unlink listenbrainz account", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to unlink listenbrainz account"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  map[string]any{"linked": false},
+	}
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// StartListenbrainzImport imports the user's ListenBrainz history in the background.
+// Importing again goes through the whole history, but only adds the listens that
+// aren't in the play history yet.
+func (app *Application) StartListenbrainzImport(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	account, err := app.Queries.GetListenbrainzAccount(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("no listenbrainz token saved"), http.StatusBadRequest)
+			return
+		}
+		app.Logger.Error("failed to get listenbrainz account", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to start listenbrainz import"))
+		return
+	}
+
+	if _, running := listenbrainzImports.LoadOrStore(userID, struct{}{}); running {
+		helpers.ErrorJSON(w, errors.New("listenbrainz history is already being imported"), http.StatusConflict)
+		return
+	}
+
+	go func() {
+		defer listenbrainzImports.Delete(userID)
+
+		matched, unmatched, err := app.ImportListenbrainzHistory(context.Background(), account)
+		if err != nil {
+			app.Logger.Error("failed to import listenbrainz history", "error", err, "user_id", userID)
+			return
+		}
+
+		app.Logger.Info("imported listenbrainz history", "user_id", userID, "matched", matched, "unmatched", unmatched)
+	}()
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data:  map[string]any{"importing": true},
+	}
+	helpers.WriteJSON(w, http.StatusAccepted, res)
+}
+
+// unlinkListenbrainz deletes the user's token and queued listens.
+func (app *Application) unlinkListenbrainz(ctx context.Context, userID int64) error {
+	if err := app.Queries.DeleteListenbrainzListensByUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return app.Queries.DeleteListenbrainzAccount(ctx, userID)
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/listenbrainz"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+const (
+	bohemianRecordingID = "b1a9c0e9-d987-4042-ae91-78d6a3267d69"
+	operaReleaseID      = "e2c3e6f3-2b26-4f5c-a2f6-5d5b4f8b7c1e"
+	queenArtistID       = "0383dadf-2a4e-4d10-a46a-e9e041da8eb3"
+)
+
+// fakeListenbrainz records the submitted listens, fails every request with err and
+// rejects batches holding a listen of the track named reject. GetListens pages
+// through history, newest first.
+type fakeListenbrainz struct {
+	err       error
+	reject    string
+	submitted [][]listenbrainz.Listen
+	history   []listenbrainz.Listen
+}
+
+func (f *fakeListenbrainz) ValidateToken(token string) (string, error) {
+	if token != "token" {
+		return "", listenbrainz.ErrInvalidToken
+	}
+	return "freddie", nil
+}
+
+func (f *fakeListenbrainz) SubmitListens(token string, listens []listenbrainz.Listen) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, l := range listens {
+		if l.Track == f.reject {
+			return listenbrainz.ErrRejected
+		}
+	}
+	f.submitted = append(f.submitted, listens)
+	return nil
+}
+
+func (f *fakeListenbrainz) GetListens(token, username string, maxTs int64, count int) ([]listenbrainz.Listen, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var listens []listenbrainz.Listen
+	for _, l := range f.history {
+		if (maxTs == 0 || l.ListenedAt < maxTs) && len(listens) < count {
+			listens = append(listens, l)
+		}
+	}
+	return listens, nil
+}
+
+// TestListenbrainzListenEligible tests ListenBrainz's rules for counting a play.
+func TestListenbrainzListenEligible(t *testing.T) {
+	tests := []struct {
+		name      string
+		duration  int64
+		played    int64
+		completed bool
+		eligible  bool
+	}{
+		{"half played", 180000, 90, false, true},
+		{"less than half", 180000, 60, false, false},
+		{"four minutes of a long track", 1200000, 240, false, true},
+		{"completed", 180000, 30, true, true},
+		{"unknown duration", 0, 200, false, false},
+		{"four minutes of an unknown duration", 0, 240, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if eligible := listenbrainzListenEligible(tt.duration, tt.played, tt.completed); eligible != tt.eligible {
+				t.Errorf("Expected eligible %v, got %v", tt.eligible, eligible)
+			}
+		})
+	}
+}
+
+// TestListenbrainzSubmission tests saving a token, that only eligible plays are queued
+// with their MusicBrainz IDs, that failed listens stay queued, that a rejected listen
+// doesn't hold back the others, and that an unknown token unlinks the account.
+func TestListenbrainzSubmission(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	app.SessionManager = scs.New()
+	fake := &fakeListenbrainz{}
+	app.Listenbrainz = fake
+
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		"/music/queen/01.flac": {
+			Title: "Bohemian Rhapsody", Artist: "Queen", AlbumArtist: "Queen", Album: "A Night at the Opera", Track: "11/12",
+			MusicBrainzTrackID: bohemianRecordingID, MusicBrainzAlbumID: operaReleaseID, MusicBrainzArtistID: queenArtistID,
+		},
+	}}
+
+	ctx := context.Background()
+
+	if scanned, _, errCount := app.processMusicBatch(ctx, []trackFile{{path: "/music/queen/01.flac", ext: "flac", size: 4}}); scanned != 1 || errCount != 0 {
+		t.Fatalf("Expected the track to be scanned, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	var trackID int64
+	if err := app.DB.QueryRow("SELECT id FROM tracks WHERE file_path = ?", "/music/queen/01.flac").Scan(&trackID); err != nil {
+		t.Fatalf("Failed to get track: %v", err)
+	}
+
+	user, err := app.Queries.CreateUser(ctx, database.CreateUserParams{Name: "Freddie", Email: "freddie@example.com", Password: "x"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	request := func(handler http.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler(rr, audiobookRequest(t, app, method, target, user.ID, body, nil))
+		return rr
+	}
+
+	play := func(durationPlayed int64) {
+		t.Helper()
+		body := `{"track_id": ` + strconv.FormatInt(trackID, 10) + `, "duration_played": ` + strconv.FormatInt(durationPlayed, 10) + `}`
+		if rr := request(app.RecordPlayEvent, http.MethodPost, "/api/music/user-stats/play", body); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+		}
+	}
+
+	pending := func() int64 {
+		t.Helper()
+		count, err := app.Queries.CountListenbrainzListens(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("Failed to count listens: %v", err)
+		}
+		return count
+	}
+
+	makeDue := func() {
+		t.Helper()
+		if _, err := app.DB.Exec("UPDATE listenbrainz_listens SET next_attempt_at = '2000-01-01 00:00:00'"); err != nil {
+			t.Fatalf("Failed to make the listens due: %v", err)
+		}
+	}
+
+	// Plays without a token are not queued
+	play(120)
+	if count := pending(); count != 0 {
+		t.Errorf("Expected no listen without a token, got %d", count)
+	}
+
+	if rr := request(app.SetListenbrainzToken, http.MethodPut, "/api/listenbrainz/token", `{"token": "wrong"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown token, got %d", http.StatusBadRequest, rr.Code)
+	}
+	if rr := request(app.SetListenbrainzToken, http.MethodPut, "/api/listenbrainz/token", `{"token": " token "}`); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	account, err := app.Queries.GetListenbrainzAccount(ctx, user.ID)
+	if err != nil || account.Username != "freddie" || account.Token != "token" {
+		t.Fatalf("Expected freddie's trimmed token to be saved, got %+v and %v", account, err)
+	}
+
+	play(60)
+	if count := pending(); count != 0 {
+		t.Errorf("Expected no listen for a third of the track, got %d", count)
+	}
+
+	play(120)
+	if count := pending(); count != 1 {
+		t.Fatalf("Expected one queued listen, got %d", count)
+	}
+
+	// ListenBrainz is unreachable, the listen waits for a later attempt
+	fake.err = errors.New("connection refused")
+	app.SubmitListenbrainzListens()
+
+	var attempts int64
+	var nextAttemptAt string
+	err = app.DB.QueryRow("SELECT attempts, next_attempt_at FROM listenbrainz_listens WHERE user_id = ?", user.ID).Scan(&attempts, &nextAttemptAt)
+	if err != nil {
+		t.Fatalf("Expected the listen to stay queued: %v", err)
+	}
+	if now := time.Now().UTC().Format(time.DateTime); attempts != 1 || nextAttemptAt <= now {
+		t.Errorf("Expected one attempt and a later retry than %s, got %d and %s", now, attempts, nextAttemptAt)
+	}
+
+	// Not due yet
+	fake.err = nil
+	app.SubmitListenbrainzListens()
+	if len(fake.submitted) != 0 {
+		t.Fatalf("Expected the listen to wait for its retry, got %v", fake.submitted)
+	}
+
+	makeDue()
+	app.SubmitListenbrainzListens()
+
+	if len(fake.submitted) != 1 || len(fake.submitted[0]) != 1 {
+		t.Fatalf("Expected one listen to be submitted, got %v", fake.submitted)
+	}
+	listen := fake.submitted[0][0]
+	if listen.Track != "Bohemian Rhapsody" || listen.Release != "A Night at the Opera" || listen.TrackNumber != 11 || listen.Duration != 180000 {
+		t.Errorf("Expected track 11 of A Night at the Opera lasting 180s, got %+v", listen)
+	}
+	if listen.RecordingMBID != bohemianRecordingID || listen.ReleaseMBID != operaReleaseID || len(listen.ArtistMBIDs) != 1 || listen.ArtistMBIDs[0] != queenArtistID {
+		t.Errorf("Expected the MusicBrainz IDs of the track, got %+v", listen)
+	}
+	if time.Since(time.Unix(listen.ListenedAt, 0)) < 2*time.Minute {
+		t.Errorf("Expected the listen to have started two minutes ago, got %d", listen.ListenedAt)
+	}
+	if count := pending(); count != 0 {
+		t.Errorf("Expected the submitted listen to leave the queue, got %d", count)
+	}
+
+	// An invalid listen is dropped, the rest of its batch is still submitted
+	play(120)
+	play(120)
+	if _, err := app.DB.Exec("UPDATE listenbrainz_listens SET track = 'Invalid' WHERE id = (SELECT MIN(id) FROM listenbrainz_listens)"); err != nil {
+		t.Fatalf("Failed to break a listen: %v", err)
+	}
+	fake.reject = "Invalid"
+	app.SubmitListenbrainzListens()
+
+	if count := pending(); count != 0 {
+		t.Errorf("Expected the rejected listen to be dropped, got %d queued", count)
+	}
+	if last := fake.submitted[len(fake.submitted)-1]; len(fake.submitted) != 2 || len(last) != 1 || last[0].Track != "Bohemian Rhapsody" {
+		t.Errorf("Expected the valid listen to be submitted alone, got %v", fake.submitted)
+	}
+
+	// The user reset the token on ListenBrainz
+	play(120)
+	fake.err = listenbrainz.ErrInvalidToken
+	app.SubmitListenbrainzListens()
+
+	if _, err := app.Queries.GetListenbrainzAccount(ctx, user.ID); err == nil {
+		t.Error("Expected the account to be unlinked")
+	}
+	if count := pending(); count != 0 {
+		t.Errorf("Expected the queue of the unlinked account to be dropped, got %d", count)
+	}
+}
+
+// TestImportListenbrainzHistory tests that listens are matched by recording ID, then by
+// artist, title and release, that igloo's own listens and unknown tracks are skipped,
+// that the stats are rebuilt from the whole play history, and that importing again
+// adds nothing twice.
+func TestImportListenbrainzHistory(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	app.SessionManager = scs.New()
+	fake := &fakeListenbrainz{}
+	app.Listenbrainz = fake
+
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		"/music/queen/opera/11.flac": {
+			Title: "Bohemian Rhapsody", AlbumArtist: "Queen", Album: "A Night at the Opera", Track: "11/12",
+			MusicBrainzTrackID: bohemianRecordingID,
+		},
+		"/music/queen/hits/01.flac": {Title: "Bohemian Rhapsody", AlbumArtist: "Queen", Album: "Greatest Hits", Track: "1/17"},
+		"/music/abba/01.flac":       {Title: "Waterloo", Artist: "ABBA", AlbumArtist: "Various Artists", Album: "Eurovision Winners"},
+	}}
+
+	ctx := context.Background()
+
+	files := []trackFile{
+		{path: "/music/queen/opera/11.flac", ext: "flac", size: 4},
+		{path: "/music/queen/hits/01.flac", ext: "flac", size: 4},
+		{path: "/music/abba/01.flac", ext: "flac", size: 4},
+	}
+	if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != len(files) || errCount != 0 {
+		t.Fatalf("Expected %d tracks scanned, got %d scanned and %d errors", len(files), scanned, errCount)
+	}
+
+	trackIDs := map[string]int64{}
+	for _, f := range files {
+		var id int64
+		if err := app.DB.QueryRow("SELECT id FROM tracks WHERE file_path = ?", f.path).Scan(&id); err != nil {
+			t.Fatalf("Failed to get track %s: %v", f.path, err)
+		}
+		trackIDs[f.path] = id
+	}
+	opera, hits, waterloo := trackIDs[files[0].path], trackIDs[files[1].path], trackIDs[files[2].path]
+
+	user, err := app.Queries.CreateUser(ctx, database.CreateUserParams{Name: "Freddie", Email: "freddie@example.com", Password: "x"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	// A play made in igloo, which ListenBrainz has as igloo's own listen
+	body := `{"track_id": ` + strconv.FormatInt(waterloo, 10) + `, "duration_played": 180, "completed": true}`
+	rr := httptest.NewRecorder()
+	app.RecordPlayEvent(rr, audiobookRequest(t, app, http.MethodPost, "/api/music/user-stats/play", user.ID, body, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	account, err := app.Queries.UpsertListenbrainzAccount(ctx, database.UpsertListenbrainzAccountParams{UserID: user.ID, Username: "freddie", Token: "token"})
+	if err != nil {
+		t.Fatalf("Failed to save account: %v", err)
+	}
+
+	fake.history = []listenbrainz.Listen{
+		{ListenedAt: time.Now().Unix(), Artist: "ABBA", Track: "Waterloo", SubmissionClient: "igloo"},
+		{ListenedAt: 1700003000, Artist: "Queen", Track: "Bohemian Rhapsody (Remastered 2011)", Release: "Greatest Hits"},
+		{ListenedAt: 1700002000, Artist: "Queen", Track: "Something Else", Release: "Greatest Hits", RecordingMBID: bohemianRecordingID},
+		{ListenedAt: 1700001000, Artist: "The Nobodies", Track: "Intro"},
+		{ListenedAt: 1700000000, Artist: "abba", Track: "Waterloo", SubmissionClient: "Spotify"},
+	}
+
+	imported, unmatched, err := app.ImportListenbrainzHistory(ctx, account)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if imported != 3 || unmatched != 1 {
+		t.Errorf("Expected 3 plays imported and 1 listen unmatched, got %d and %d", imported, unmatched)
+	}
+
+	stats := func(trackID int64) (playCount int64, firstPlayedAt string) {
+		t.Helper()
+		err := app.DB.QueryRow("SELECT play_count, first_played_at FROM user_track_stats WHERE user_id = ? AND track_id = ?", user.ID, trackID).Scan(&playCount, &firstPlayedAt)
+		if err != nil {
+			t.Fatalf("Failed to get stats of track %d: %v", trackID, err)
+		}
+		return playCount, firstPlayedAt
+	}
+
+	at := func(ts int64) string {
+		return time.Unix(ts, 0).UTC().Format(time.DateTime)
+	}
+
+	// The release picks the Greatest Hits track, the recording ID the album track
+	if count, first := stats(hits); count != 1 || first != at(1700003000) {
+		t.Errorf("Expected one Greatest Hits play at %s, got %d at %s", at(1700003000), count, first)
+	}
+	if count, first := stats(opera); count != 1 || first != at(1700002000) {
+		t.Errorf("Expected one album play at %s, got %d at %s", at(1700002000), count, first)
+	}
+	// Matched by track artist, on top of the play made in igloo
+	if count, first := stats(waterloo); count != 2 || first != at(1700000000) {
+		t.Errorf("Expected two Waterloo plays, the first at %s, got %d at %s", at(1700000000), count, first)
+	}
+
+	account, err = app.Queries.GetListenbrainzAccount(ctx, user.ID)
+	if err != nil || !account.ImportedAt.Valid || account.ImportedListens != 3 || account.UnmatchedListens != 1 {
+		t.Errorf("Expected the import to be saved, got %+v and %v", account, err)
+	}
+
+	// Importing again finds the same listens already played
+	imported, _, err = app.ImportListenbrainzHistory(ctx, account)
+	if err != nil || imported != 0 {
+		t.Errorf("Expected nothing imported twice, got %d and %v", imported, err)
+	}
+
+	var plays int64
+	if err := app.DB.QueryRow("SELECT COUNT(*) FROM user_play_history WHERE user_id = ?", user.ID).Scan(&plays); err != nil {
+		t.Fatalf("Failed to count plays: %v", err)
+	}
+	if plays != 4 {
+		t.Errorf("Expected 4 plays in the history, got %d", plays)
+	}
+
+	// Imported listens count as plays of the whole track
+	var incomplete int64
+	if err := app.DB.QueryRow("SELECT COUNT(*) FROM user_play_history WHERE user_id = ? AND NOT completed", user.ID).Scan(&incomplete); err != nil {
+		t.Fatalf("Failed to count incomplete plays: %v", err)
+	}
+	if incomplete != 0 {
+		t.Errorf("Expected every play to be completed, got %d incomplete", incomplete)
+	}
+}
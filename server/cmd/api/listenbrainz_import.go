@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/listenbrainz"
+)
+
+// listenbrainzImports holds the ids of the users whose history is being imported, so
+// an import never runs twice at once.
+var listenbrainzImports sync.Map
+
+// listenMatchTrack is a local track listens can be matched to.
+type listenMatchTrack struct {
+	id int64
+	// album is normalized, to prefer the track of the listen's release
+	album string
+	// duration is in milliseconds
+	duration int64
+}
+
+// listenMatcher finds the local track of a listen: by its MusicBrainz recording ID,
+// then by artist and title, preferring the track of the same release.
+type listenMatcher struct {
+	byMBID map[string]listenMatchTrack
+	byName map[string][]listenMatchTrack
+}
+
+// listenMatchKey keys a track by artist and title, without the edition notes that
+// differ between releases of a recording, like "(Remastered 2011)".
+func listenMatchKey(artist, title string) string {
+	return helpers.NormalizeKey(artist) + "\x00" + normalizeAlbumTitle(title)
+}
+
+// newListenMatcher indexes the tracks under their track artist and album artist.
+func newListenMatcher(tracks []database.GetAllTrackScrobbleInfoRow) *listenMatcher {
+	m := &listenMatcher{
+		byMBID: make(map[string]listenMatchTrack),
+		byName: make(map[string][]listenMatchTrack),
+	}
+
+	for _, t := range tracks {
+		track := listenMatchTrack{id: t.ID, album: normalizeAlbumTitle(t.Album.String), duration: t.Duration}
+
+		if t.MusicbrainzTrackID.Valid && t.MusicbrainzTrackID.String != "" {
+			m.byMBID[strings.ToLower(t.MusicbrainzTrackID.String)] = track
+		}
+
+		keys := map[string]bool{}
+		for _, artist := range []string{t.Artist.String, t.AlbumArtist.String} {
+			if key := listenMatchKey(artist, t.Title); artist != "" && !keys[key] {
+				keys[key] = true
+				m.byName[key] = append(m.byName[key], track)
+			}
+		}
+	}
+
+	return m
+}
+
+// match returns the local track of a listen, false when there is none.
+func (m *listenMatcher) match(l listenbrainz.Listen) (listenMatchTrack, bool) {
+	if l.RecordingMBID != "" {
+		if track, ok := m.byMBID[strings.ToLower(l.RecordingMBID)]; ok {
+			return track, true
+		}
+	}
+
+	candidates := m.byName[listenMatchKey(l.Artist, l.Track)]
+	if len(candidates) == 0 {
+		return listenMatchTrack{}, false
+	}
+
+	if release := normalizeAlbumTitle(l.Release); release != "" {
+		for _, track := range candidates {
+			if track.album == release {
+				return track, true
+			}
+		}
+	}
+
+	return candidates[0], true
+}
+
+// ImportListenbrainzHistory adds the listens of a user's ListenBrainz history that match
+// local tracks to the play history, then rebuilds the user's track stats from it.
+// Listens igloo submitted are in the play history already and skipped, as are the plays
+// an earlier import added. Returns the number of plays imported and of listens without
+// a local track.
+func (app *Application) ImportListenbrainzHistory(ctx context.Context, account database.ListenbrainzAccount) (int, int, error) {
+	if app.Wait != nil {
+		app.Wait.Add(1)
+		defer app.Wait.Done()
+	}
+
+	tracks, err := app.Queries.GetAllTrackScrobbleInfo(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get tracks: %w", err)
+	}
+	matcher := newListenMatcher(tracks)
+
+	times, err := app.Queries.GetUserPlayTimes(ctx, account.UserID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get play history: %w", err)
+	}
+	played := make(map[string]bool, len(times))
+	for _, t := range times {
+		played[strconv.FormatInt(t.TrackID, 10)+"@"+t.PlayedAt] = true
+	}
+
+	var imported, unmatched int
+	var importErr error
+	var maxTs int64
+	for {
+		listens, err := app.getListenbrainzListens(account, maxTs)
+		if err != nil {
+			importErr = fmt.Errorf("failed to get listens: %w", err)
+			break
+		}
+		if len(listens) == 0 {
+			break
+		}
+
+		added, missing, err := app.importListens(ctx, account.UserID, listens, matcher, played)
+		imported += added
+		unmatched += missing
+		if err != nil {
+			importErr = fmt.Errorf("failed to import listens: %w", err)
+			break
+		}
+
+		if len(listens) < helpers.LISTENBRAINZ_IMPORT_PAGE_SIZE {
+			break
+		}
+		// Pages go back in time, from the oldest listen of the last one
+		maxTs = listens[len(listens)-1].ListenedAt
+	}
+
+	// The plays imported before a failure are kept, so their stats are rebuilt as well
+	if imported > 0 {
+		if err := app.Queries.RebuildUserTrackStats(ctx, account.UserID); err != nil {
+			return imported, unmatched, fmt.Errorf("failed to rebuild track stats: %w", err)
+		}
+	}
+
+	if importErr != nil {
+		return imported, unmatched, importErr
+	}
+
+	err = app.Queries.UpdateListenbrainzImport(ctx, database.UpdateListenbrainzImportParams{
+		ImportedListens:  int64(imported),
+		UnmatchedListens: int64(unmatched),
+		UserID:           account.UserID,
+	})
+	if err != nil {
+		return imported, unmatched, fmt.Errorf("failed to save import: %w", err)
+	}
+
+	return imported, unmatched, nil
+}
+
+// getListenbrainzListens fetches a page of the history, asking again when ListenBrainz
+// was unreachable.
+func (app *Application) getListenbrainzListens(account database.ListenbrainzAccount, maxTs int64) ([]listenbrainz.Listen, error) {
+	for attempt := 0; ; attempt++ {
+		listens, err := app.Listenbrainz.GetListens(account.Token, account.Username, maxTs, helpers.LISTENBRAINZ_IMPORT_PAGE_SIZE)
+		if err == nil || !listenbrainz.IsTemporary(err) || attempt >= helpers.LISTENBRAINZ_IMPORT_MAX_RETRIES {
+			return listens, err
+		}
+
+		app.Logger.Warn("failed to get listenbrainz listens, will retry", "error", err, "user_id", account.UserID)
+		time.Sleep(helpers.LISTENBRAINZ_IMPORT_RETRY_DELAY)
+	}
+}
+
+// importListens adds a page of listens to the play history in one transaction. A
+// listen counts as a play of the whole track, played is updated with the new plays.
+func (app *Application) importListens(ctx context.Context, userID int64, listens []listenbrainz.Listen, matcher *listenMatcher, played map[string]bool) (int, int, error) {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	var imported, unmatched int
+	for _, l := range listens {
+		if l.SubmissionClient == helpers.LISTENBRAINZ_SUBMISSION_CLIENT {
+			continue
+		}
+
+		track, ok := matcher.match(l)
+		if !ok {
+			unmatched++
+			continue
+		}
+
+		playedAt := helpers.SQLiteTime(time.Unix(l.ListenedAt, 0))
+		key := strconv.FormatInt(track.id, 10) + "@" + playedAt
+		if played[key] {
+			continue
+		}
+
+		err := qtx.ImportPlayEvent(ctx, database.ImportPlayEventParams{
+			UserID:         userID,
+			TrackID:        track.id,
+			PlayedAt:       playedAt,
+			DurationPlayed: track.duration / 1000,
+			Completed:      true,
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+
+		played[key] = true
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return imported, unmatched, nil
+}
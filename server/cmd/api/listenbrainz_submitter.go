@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/listenbrainz"
+)
+
+// listenbrainzListenQueued wakes the submitter when a listen is queued, so it goes out
+// without waiting for the next tick. Listens queued while it runs share one wake up.
+var listenbrainzListenQueued = make(chan struct{}, 1)
+
+// listenbrainzListenEligible reports whether ListenBrainz counts a play as a listen:
+// half the track or 4 minutes were played, or the track was played to the end.
+// duration is in milliseconds, 0 when unknown, and played in seconds.
+func listenbrainzListenEligible(duration, played int64, completed bool) bool {
+	required := helpers.LISTENBRAINZ_LISTEN_PLAYED_TIME
+	if duration > 0 {
+		required = min(time.Duration(duration)*time.Millisecond/2, required)
+	}
+
+	return completed || time.Duration(played)*time.Second >= required
+}
+
+// queueListenbrainzListen queues a play for the submitter when the user saved a
+// ListenBrainz token and the play counts as a listen. The play started durationPlayed
+// seconds ago.
+func (app *Application) queueListenbrainzListen(ctx context.Context, userID, trackID, durationPlayed int64, completed bool) error {
+	if _, err := app.Queries.GetListenbrainzAccount(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	info, err := app.Queries.GetTrackScrobbleInfo(ctx, trackID)
+	if err != nil {
+		return err
+	}
+
+	artist := info.Artist.String
+	if artist == "" {
+		artist = info.AlbumArtist.String
+	}
+	if artist == "" || info.Title == "" {
+		return nil
+	}
+
+	if !listenbrainzListenEligible(info.Duration, durationPlayed, completed) {
+		return nil
+	}
+
+	err = app.Queries.CreateListenbrainzListen(ctx, database.CreateListenbrainzListenParams{
+		UserID:        userID,
+		Artist:        artist,
+		Track:         info.Title,
+		Release:       info.Album,
+		RecordingMbid: info.MusicbrainzTrackID,
+		ReleaseMbid:   info.AlbumMbid,
+		ArtistMbid:    info.ArtistMbid,
+		TrackNumber:   helpers.NullInt64(info.TrackIndex),
+		Duration:      helpers.NullInt64(info.Duration),
+		ListenedAt:    time.Now().Unix() - durationPlayed,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case listenbrainzListenQueued <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// RunListenbrainzSubmitter submits the queued listens that are due, on every tick and
+// whenever a listen is queued.
+func (app *Application) RunListenbrainzSubmitter() {
+	ticker := time.NewTicker(helpers.LISTENBRAINZ_SUBMIT_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-listenbrainzListenQueued:
+		}
+
+		app.SubmitListenbrainzListens()
+	}
+}
+
+// SubmitListenbrainzListens submits the due listens of every user with a token, oldest
+// first. Submitted listens leave the queue and failed ones wait longer after every
+// attempt, only listens ListenBrainz rejects are dropped. An unknown token unlinks the
+// account.
+func (app *Application) SubmitListenbrainzListens() {
+	if app.Wait != nil {
+		app.Wait.Add(1)
+		defer app.Wait.Done()
+	}
+
+	if app.Listenbrainz == nil {
+		return
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+
+	due, err := app.Queries.GetDueListenbrainzListens(ctx, database.GetDueListenbrainzListensParams{
+		Now:   helpers.SQLiteTime(now),
+		Limit: helpers.LISTENBRAINZ_SUBMIT_BATCH_SIZE * 10,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get due listenbrainz listens", "error", err)
+		return
+	}
+
+	// Rows come grouped by user, submit each user's listens in batches
+	for start := 0; start < len(due); {
+		end := start + 1
+		for end < len(due) && end-start < helpers.LISTENBRAINZ_SUBMIT_BATCH_SIZE && due[end].UserID == due[start].UserID {
+			end++
+		}
+
+		app.submitListenbrainzBatch(ctx, due[start:end], now)
+		start = end
+	}
+}
+
+// submitListenbrainzBatch submits the listens of one user and updates the queue with
+// the outcome.
+func (app *Application) submitListenbrainzBatch(ctx context.Context, batch []database.GetDueListenbrainzListensRow, now time.Time) {
+	userID := batch[0].UserID
+
+	listens := make([]listenbrainz.Listen, len(batch))
+	for i, l := range batch {
+		listens[i] = listenbrainz.Listen{
+			ListenedAt:    l.ListenedAt,
+			Artist:        l.Artist,
+			Track:         l.Track,
+			Release:       l.Release.String,
+			RecordingMBID: l.RecordingMbid.String,
+			ReleaseMBID:   l.ReleaseMbid.String,
+			TrackNumber:   int(l.TrackNumber.Int64),
+			Duration:      l.Duration.Int64,
+		}
+		if l.ArtistMbid.Valid {
+			listens[i].ArtistMBIDs = []string{l.ArtistMbid.String}
+		}
+	}
+
+	err := app.Listenbrainz.SubmitListens(batch[0].Token, listens)
+	if errors.Is(err, listenbrainz.ErrInvalidToken) {
+		app.Logger.Warn("listenbrainz token is no longer valid, unlinking account", "user_id", userID)
+		if err := app.unlinkListenbrainz(ctx, userID); err != nil {
+			app.Logger.Error("failed to unlink listenbrainz account", "error", err)
+		}
+		return
+	}
+
+	// One invalid listen fails the whole batch, the others are submitted alone and
+	// only the invalid one is dropped
+	if errors.Is(err, listenbrainz.ErrRejected) {
+		if len(batch) > 1 {
+			for i := range batch {
+				app.submitListenbrainzBatch(ctx, batch[i:i+1], now)
+			}
+			return
+		}
+
+		app.Logger.Error("listenbrainz rejected listen, dropping it", "error", err, "user_id", userID, "artist", batch[0].Artist, "track", batch[0].Track)
+		if err := app.Queries.DeleteListenbrainzListen(ctx, batch[0].ID); err != nil {
+			app.Logger.Error("failed to delete rejected listenbrainz listen", "error", err, "id", batch[0].ID)
+		}
+		return
+	}
+
+	if err != nil {
+		// Other errors, like a wrong LISTENBRAINZ_BASE_URL, need the admin, retry them as well
+		if listenbrainz.IsTemporary(err) {
+			app.Logger.Warn("failed to submit listenbrainz listens, will retry", "error", err, "user_id", userID, "count", len(batch))
+		} else {
+			app.Logger.Error("failed to submit listenbrainz listens, will retry", "error", err, "user_id", userID, "count", len(batch))
+		}
+
+		lastError := helpers.NullString(err.Error())
+		for _, l := range batch {
+			delay := min(helpers.LISTENBRAINZ_RETRY_BASE_DELAY<<min(l.Attempts, 16), helpers.LISTENBRAINZ_MAX_RETRY_DELAY)
+			err := app.Queries.RetryListenbrainzListen(ctx, database.RetryListenbrainzListenParams{
+				LastError:     lastError,
+				NextAttemptAt: helpers.SQLiteTime(now.Add(delay)),
+				ID:            l.ID,
+			})
+			if err != nil {
+				app.Logger.Error("failed to reschedule listenbrainz listen", "error", err, "id", l.ID)
+			}
+		}
+		return
+	}
+
+	for _, l := range batch {
+		if err := app.Queries.DeleteListenbrainzListen(ctx, l.ID); err != nil {
+			app.Logger.Error("failed to delete submitted listenbrainz listen", "error", err, "id", l.ID)
+		}
+	}
+
+	app.Logger.Info("submitted listenbrainz listens", "user_id", userID, "count", len(batch))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TrackLyricsResponse is a track's lyrics as plain text and, when synced, as
+// timed lines. Lines is empty for unsynced lyrics.
+type TrackLyricsResponse struct {
+	TrackID   int64               `json:"track_id"`
+	Synced    bool                `json:"synced"`
+	Source    string              `json:"source"`
+	Language  string              `json:"language"`
+	Plain     string              `json:"plain"`
+	Lines     []helpers.LyricLine `json:"lines"`
+	UpdatedAt string              `json:"updated_at"`
+}
+
+// PutTrackLyricsRequest is the JSON body of PutTrackLyrics. Lyrics may be plain
+// text or LRC.
+type PutTrackLyricsRequest struct {
+	Lyrics   string `json:"lyrics"`
+	Language string `json:"language"`
+}
+
+func newTrackLyricsResponse(lyrics database.TrackLyric) TrackLyricsResponse {
+	res := TrackLyricsResponse{
+		TrackID:   lyrics.TrackID,
+		Source:    lyrics.Source,
+		Language:  lyrics.Language.String,
+		Plain:     lyrics.Content,
+		Lines:     []helpers.LyricLine{},
+		UpdatedAt: lyrics.UpdatedAt,
+	}
+
+	if lines, ok := helpers.ParseLRC(lyrics.Content); ok {
+		res.Synced = true
+		res.Plain = helpers.PlainLyrics(lines)
+		res.Lines = lines
+	}
+
+	return res
+}
+
+// GetTrackLyrics returns a track's lyrics.
+func (app *Application) GetTrackLyrics(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid track id"), http.StatusBadRequest)
+		return
+	}
+
+	lyrics, err := app.Queries.GetTrackLyrics(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("lyrics not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get lyrics", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch lyrics from server"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"lyrics": newTrackLyricsResponse(lyrics),
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// PutTrackLyrics replaces a track's lyrics with lyrics entered by a user, either as
+// JSON or as an uploaded .lrc or text file in the "file" field of a multipart form.
+// Later scans keep them even if the file or its sidecar has lyrics of its own.
+func (app *Application) PutTrackLyrics(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid track id"), http.StatusBadRequest)
+		return
+	}
+
+	var req PutTrackLyricsRequest
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, helpers.LYRICS_MAX_UPLOAD_SIZE)
+
+		if err := r.ParseMultipartForm(helpers.LYRICS_MAX_UPLOAD_SIZE); err != nil {
+			if strings.Contains(err.Error(), "request body too large") {
+				helpers.ErrorJSON(w, errors.New("file too large, maximum size is 1MB"), http.StatusRequestEntityTooLarge)
+			} else {
+				helpers.ErrorJSON(w, errors.New("failed to parse form data"), http.StatusBadRequest)
+			}
+			return
+		}
+
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			helpers.ErrorJSON(w, errors.New("no file uploaded"), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			helpers.ErrorJSON(w, errors.New("failed to read file"), http.StatusBadRequest)
+			return
+		}
+
+		req.Lyrics = helpers.DecodeLyricsText(data)
+		req.Language = r.FormValue("language")
+	} else if err := helpers.ReadJSON(w, r, &req, helpers.LYRICS_MAX_UPLOAD_SIZE); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	content := helpers.DecodeLyricsText([]byte(req.Lyrics))
+	if content == "" {
+		helpers.ErrorJSON(w, errors.New("lyrics are required"), http.StatusBadRequest)
+		return
+	}
+
+	language := strings.ToLower(strings.TrimSpace(req.Language))
+	_, synced := helpers.ParseLRC(content)
+
+	ctx := r.Context()
+
+	if _, err := app.Queries.GetTrack(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("track not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get track", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update lyrics"))
+		return
+	}
+
+	app.ScannerDBMu.Lock()
+	lyrics, err := app.Queries.UpsertUserLyrics(ctx, database.UpsertUserLyricsParams{
+		TrackID:  id,
+		Content:  content,
+		Synced:   synced,
+		Language: sql.NullString{String: language, Valid: language != ""},
+	})
+	app.ScannerDBMu.Unlock()
+
+	if err != nil {
+		app.Logger.Error("failed to save lyrics", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update lyrics"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"lyrics": newTrackLyricsResponse(lyrics),
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
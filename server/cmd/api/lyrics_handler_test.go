@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// lyricsRequest builds a request for a track's lyrics endpoint.
+func lyricsRequest(method string, trackID int64, body *bytes.Buffer, contentType string) *http.Request {
+	id := strconv.FormatInt(trackID, 10)
+
+	var req *http.Request
+	if body == nil {
+		req = httptest.NewRequest(method, "/api/music/tracks/"+id+"/lyrics", nil)
+	} else {
+		req = httptest.NewRequest(method, "/api/music/tracks/"+id+"/lyrics", body)
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+// decodeLyrics decodes the lyrics of a lyrics endpoint response.
+func decodeLyrics(t *testing.T, rr *httptest.ResponseRecorder) TrackLyricsResponse {
+	t.Helper()
+
+	var res struct {
+		Data struct {
+			Lyrics TrackLyricsResponse `json:"lyrics"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return res.Data.Lyrics
+}
+
+// TestProcessMusicBatch_Lyrics tests that lyrics are read from tags and .lrc sidecars,
+// that an edited sidecar is rescanned although the audio file is unchanged, and that
+// lyrics entered by a user survive later scans.
+func TestProcessMusicBatch_Lyrics(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	dir := t.TempDir()
+	tagged := filepath.Join(dir, "01 Tagged.flac")
+	sidecar := filepath.Join(dir, "02 Sidecar.flac")
+	plain := filepath.Join(dir, "03 Plain.flac")
+
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		tagged:  {Title: "Tagged", Artist: "Artist", Album: "Album", Lyrics: "[00:01.00]From the tag\n[00:03.50]Second line", LyricsLanguage: "eng"},
+		sidecar: {Title: "Sidecar", Artist: "Artist", Album: "Album", Lyrics: "Overridden by the sidecar"},
+		plain:   {Title: "Plain", Artist: "Artist", Album: "Album", Lyrics: "Just words\r\nMore words"},
+	}}
+
+	for _, path := range []string{tagged, sidecar, plain} {
+		if err := os.WriteFile(path, []byte("fLaC"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	lrcPath := filepath.Join(dir, "02 sidecar.LRC")
+	if err := os.WriteFile(lrcPath, []byte("[ti:Sidecar]\n[00:02.00]From the sidecar\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write sidecar: %v", err)
+	}
+
+	ctx := context.Background()
+
+	filesOf := func() []trackFile {
+		sidecars := app.lyricsSidecarsInDir(dir)
+		var files []trackFile
+		for _, path := range []string{tagged, sidecar, plain} {
+			files = append(files, trackFile{path: path, ext: "flac", size: 4, lyrics: sidecars[path]})
+		}
+		return files
+	}
+
+	files := filesOf()
+	if files[1].lyrics == nil || files[0].lyrics != nil {
+		t.Fatalf("Expected only the second track to have a sidecar, got %+v", files)
+	}
+
+	if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != 3 || errCount != 0 {
+		t.Fatalf("Expected 3 tracks scanned, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	trackID := func(path string) int64 {
+		t.Helper()
+		var id int64
+		if err := app.DB.QueryRow("SELECT id FROM tracks WHERE file_path = ?", path).Scan(&id); err != nil {
+			t.Fatalf("Failed to find %s: %v", path, err)
+		}
+		return id
+	}
+
+	getLyrics := func(id int64) TrackLyricsResponse {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		app.GetTrackLyrics(rr, lyricsRequest(http.MethodGet, id, nil, ""))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		return decodeLyrics(t, rr)
+	}
+
+	lyrics := getLyrics(trackID(tagged))
+	expected := []helpers.LyricLine{{Time: 1000, Text: "From the tag"}, {Time: 3500, Text: "Second line"}}
+	if !lyrics.Synced || lyrics.Source != helpers.LYRICS_SOURCE_EMBEDDED || lyrics.Language != "eng" || !reflect.DeepEqual(lyrics.Lines, expected) {
+		t.Errorf("Unexpected tag lyrics: %+v", lyrics)
+	}
+	if lyrics.Plain != "From the tag\nSecond line" {
+		t.Errorf("Expected the plain text without timestamps, got %q", lyrics.Plain)
+	}
+
+	lyrics = getLyrics(trackID(sidecar))
+	if lyrics.Source != helpers.LYRICS_SOURCE_SIDECAR || lyrics.Plain != "From the sidecar" {
+		t.Errorf("Expected the sidecar to win over the tag, got %+v", lyrics)
+	}
+
+	lyrics = getLyrics(trackID(plain))
+	if lyrics.Synced || lyrics.Plain != "Just words\nMore words" || len(lyrics.Lines) != 0 {
+		t.Errorf("Unexpected plain lyrics: %+v", lyrics)
+	}
+
+	// Unchanged files and sidecars
+	if _, skipped, _ := app.processMusicBatch(ctx, filesOf()); skipped != 3 {
+		t.Errorf("Expected 3 unchanged tracks to be skipped, got %d", skipped)
+	}
+
+	// An edited sidecar is rescanned
+	later := time.Now().Add(time.Hour)
+	if err := os.WriteFile(lrcPath, []byte("[00:02.00]Edited sidecar\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write sidecar: %v", err)
+	}
+	if err := os.Chtimes(lrcPath, later, later); err != nil {
+		t.Fatalf("Failed to touch sidecar: %v", err)
+	}
+
+	if scanned, skipped, _ := app.processMusicBatch(ctx, filesOf()); scanned != 1 || skipped != 2 {
+		t.Errorf("Expected the track with the edited sidecar to be rescanned, got %d scanned and %d skipped", scanned, skipped)
+	}
+
+	if lyrics = getLyrics(trackID(sidecar)); lyrics.Plain != "Edited sidecar" {
+		t.Errorf("Expected the edited sidecar, got %q", lyrics.Plain)
+	}
+
+	// A user correction wins over the sidecar from then on
+	body, _ := json.Marshal(PutTrackLyricsRequest{Lyrics: "[00:02.50]Corrected", Language: " ENG "})
+	rr := httptest.NewRecorder()
+	app.PutTrackLyrics(rr, lyricsRequest(http.MethodPut, trackID(sidecar), bytes.NewBuffer(body), "application/json"))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if lyrics = decodeLyrics(t, rr); lyrics.Source != helpers.LYRICS_SOURCE_USER || lyrics.Language != "eng" || lyrics.Plain != "Corrected" {
+		t.Errorf("Unexpected corrected lyrics: %+v", lyrics)
+	}
+
+	later = later.Add(time.Hour)
+	if err := os.Chtimes(lrcPath, later, later); err != nil {
+		t.Fatalf("Failed to touch sidecar: %v", err)
+	}
+
+	if _, skipped, _ := app.processMusicBatch(ctx, filesOf()); skipped != 3 {
+		t.Errorf("Expected user lyrics to count as unchanged, got %d skipped", skipped)
+	}
+
+	// A changed audio file is rescanned, but keeps the user's lyrics
+	files = filesOf()
+	files[1].size = 8
+	if scanned, _, _ := app.processMusicBatch(ctx, files); scanned != 1 {
+		t.Fatalf("Expected the changed file to be rescanned, got %d", scanned)
+	}
+
+	if lyrics = getLyrics(trackID(sidecar)); lyrics.Source != helpers.LYRICS_SOURCE_USER || lyrics.Plain != "Corrected" {
+		t.Errorf("Expected the user's lyrics to survive the scan, got %+v", lyrics)
+	}
+}
+
+func TestPutTrackLyrics(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	newTrack := func(path string) int64 {
+		t.Helper()
+		track, err := app.Queries.UpsertTrack(context.Background(), database.UpsertTrackParams{
+			Title: filepath.Base(path), FilePath: path, FileName: filepath.Base(path),
+			Container: "flac", MimeType: "audio/flac",
+		})
+		if err != nil {
+			t.Fatalf("Failed to insert track: %v", err)
+		}
+		return track.ID
+	}
+
+	trackID := newTrack("/music/song.flac")
+
+	// Upload as a .lrc file
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "song.lrc")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write([]byte("\xef\xbb\xbf[00:10.00]Uploaded\r\n[00:05.00]Earlier\r\n"))
+	form.WriteField("language", "deu")
+	form.Close()
+
+	rr := httptest.NewRecorder()
+	app.PutTrackLyrics(rr, lyricsRequest(http.MethodPut, trackID, &body, form.FormDataContentType()))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	lyrics := decodeLyrics(t, rr)
+	expected := []helpers.LyricLine{{Time: 5000, Text: "Earlier"}, {Time: 10_000, Text: "Uploaded"}}
+	if !lyrics.Synced || lyrics.Language != "deu" || !reflect.DeepEqual(lyrics.Lines, expected) {
+		t.Errorf("Unexpected uploaded lyrics: %+v", lyrics)
+	}
+
+	tests := []struct {
+		name   string
+		id     int64
+		body   string
+		status int
+	}{
+		{"empty lyrics", trackID, `{"lyrics": "  \n "}`, http.StatusBadRequest},
+		{"unknown field", trackID, `{"text": "Hello"}`, http.StatusBadRequest},
+		{"missing track", trackID + 100, `{"lyrics": "Hello"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			app.PutTrackLyrics(rr, lyricsRequest(http.MethodPut, tt.id, bytes.NewBufferString(tt.body), "application/json"))
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	// A track without lyrics
+	other := newTrack("/music/other.flac")
+	rr = httptest.NewRecorder()
+	app.GetTrackLyrics(rr, lyricsRequest(http.MethodGet, other, nil, ""))
+
+	if rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), "lyrics not found") {
+		t.Errorf("Expected 404, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+// TestProcessMusicBatch_LyricsSourceChanges tests that unchanged tracks are read again
+// for their lyrics when they were scanned before lyrics were supported, and when their
+// sidecar is removed.
+func TestProcessMusicBatch_LyricsSourceChanges(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	dir := t.TempDir()
+	tagged := filepath.Join(dir, "01 Tagged.flac")
+	sidecar := filepath.Join(dir, "02 Sidecar.flac")
+
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		tagged:  {Title: "Tagged", Artist: "Artist", Album: "Album", Lyrics: "From the tag"},
+		sidecar: {Title: "Sidecar", Artist: "Artist", Album: "Album"},
+	}}
+
+	for _, path := range []string{tagged, sidecar} {
+		if err := os.WriteFile(path, []byte("fLaC"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	lrcPath := filepath.Join(dir, "02 Sidecar.lrc")
+	if err := os.WriteFile(lrcPath, []byte("[00:02.00]From the sidecar\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write sidecar: %v", err)
+	}
+
+	ctx := context.Background()
+
+	filesOf := func() []trackFile {
+		sidecars := app.lyricsSidecarsInDir(dir)
+		return []trackFile{
+			{path: tagged, ext: "flac", size: 4, lyrics: sidecars[tagged]},
+			{path: sidecar, ext: "flac", size: 4, lyrics: sidecars[sidecar]},
+		}
+	}
+
+	if scanned, _, errCount := app.processMusicBatch(ctx, filesOf()); scanned != 2 || errCount != 0 {
+		t.Fatalf("Expected 2 tracks scanned, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	lyricsOf := func(path string) (content string, found bool) {
+		t.Helper()
+		err := app.DB.QueryRow(`SELECT l.content FROM track_lyrics l JOIN tracks t ON t.id = l.track_id
+			WHERE t.file_path = ?`, path).Scan(&content)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("Failed to get lyrics of %s: %v", path, err)
+		}
+		return content, err == nil
+	}
+
+	// A library scanned before lyrics were supported
+	if _, err := app.DB.Exec("DELETE FROM track_lyrics"); err != nil {
+		t.Fatalf("Failed to delete lyrics: %v", err)
+	}
+	if _, err := app.DB.Exec("UPDATE tracks SET lyrics_scanned_at = NULL"); err != nil {
+		t.Fatalf("Failed to reset lyrics scans: %v", err)
+	}
+
+	if scanned, _, _ := app.processMusicBatch(ctx, filesOf()); scanned != 2 {
+		t.Errorf("Expected both tracks to be read again for their lyrics, got %d scanned", scanned)
+	}
+	if content, _ := lyricsOf(tagged); content != "From the tag" {
+		t.Errorf("Expected the tag lyrics of the unchanged file, got %q", content)
+	}
+
+	if _, skipped, _ := app.processMusicBatch(ctx, filesOf()); skipped != 2 {
+		t.Errorf("Expected 2 unchanged tracks to be skipped, got %d", skipped)
+	}
+
+	// A removed sidecar takes its lyrics with it
+	if err := os.Remove(lrcPath); err != nil {
+		t.Fatalf("Failed to remove sidecar: %v", err)
+	}
+
+	if scanned, skipped, _ := app.processMusicBatch(ctx, filesOf()); scanned != 1 || skipped != 1 {
+		t.Errorf("Expected the track that lost its sidecar to be rescanned, got %d scanned and %d skipped", scanned, skipped)
+	}
+	if content, found := lyricsOf(sidecar); found {
+		t.Errorf("Expected the sidecar lyrics to be removed, got %q", content)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"os/signal"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -20,7 +21,10 @@ import (
 	"igloo/cmd/internal/ffmpeg"
 	"igloo/cmd/internal/ffprobe"
 	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/lastfm"
+	"igloo/cmd/internal/listenbrainz"
 	applogger "igloo/cmd/internal/logger"
+	"igloo/cmd/internal/podcast"
 	"igloo/cmd/internal/spotify"
 	"igloo/cmd/internal/tmdb"
 
@@ -35,17 +39,23 @@ import (
 type Application struct {
 	DB             *sql.DB
 	Queries        *database.Queries
-	Settings       *database.Setting
+	settings       atomic.Pointer[database.Setting]
 	Logger         *slog.Logger
 	LoggerCloser   func() error
 	Ffprobe        ffprobe.FfprobeInterface
+	Ffmpeg         ffmpeg.FfmpegInterface
 	Spotify        spotify.SpotifyInterface
 	Tmdb           tmdb.TmdbInterface
+	Lastfm         lastfm.LastfmInterface
+	Listenbrainz   listenbrainz.ListenbrainzInterface
+	Podcast        podcast.PodcastInterface
 	SessionManager *scs.SessionManager
 	Wait           *sync.WaitGroup
 	Router         *chi.Mux
 	Server         *http.Server
 	ScannerDBMu    sync.Mutex
+	// albumTrackCounts caches the album folder track counts of the running music scan
+	albumTrackCounts albumTrackCounts
 }
 
 // SQL contains the database schema, embedded at compile time.
@@ -135,6 +145,12 @@ func InitApp() (*Application, error) {
 		return nil, fmt.Errorf("failed to prepare database queries: %v", err)
 	}
 
+	// Merge genres that only differ in spelling and were created before genre aliases.
+	err = app.migrateGenreAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate genre aliases: %v", err)
+	}
+
 	// Load or create application settings.
 	// Reads existing settings from DB, or creates defaults from env vars.
 	err = app.InitSettings(ctx)
@@ -168,10 +184,22 @@ func InitApp() (*Application, error) {
 	}
 	app.Ffprobe = ffprobeApp
 
+	// Initialize ffmpeg for transcoding media browsers can't play natively.
+	ffmpegApp, err := ffmpeg.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize ffmpeg: %v", err)
+	}
+	app.Ffmpeg = ffmpegApp
+
+	// Initialize the podcast client used to poll feeds and download episodes.
+	app.Podcast = podcast.New()
+
 	// Initialize Spotify client if credentials are configured.
 	// This is optional - the app works without Spotify integration.
-	if app.Settings.SpotifyClientID.Valid && app.Settings.SpotifyClientSecret.Valid {
-		s, err := spotify.New(app.Settings.SpotifyClientID.String, app.Settings.SpotifyClientSecret.String)
+	if app.Settings().SpotifyClientID.Valid && app.Settings().SpotifyClientSecret.Valid {
+		s, err := spotify.New(app.Settings().SpotifyClientID.String, app.Settings().SpotifyClientSecret.String, spotify.Config{
+			Cache: app.newAPIResponseCache("spotify"),
+		})
 		if err != nil {
 			app.Logger.Warn("failed to initialize spotify client", "error", err)
 		} else {
@@ -182,8 +210,12 @@ func InitApp() (*Application, error) {
 
 	// Initialize TMDB client if TMDB key is configured.
 	// This is optional - the app works without TMDB integration.
-	if app.Settings.TmdbKey.Valid {
-		tmdb, err := tmdb.New(app.Settings.TmdbKey.String)
+	if app.Settings().TmdbKey.Valid {
+		tmdb, err := tmdb.New(app.Settings().TmdbKey.String, tmdb.Config{
+			// TMDB_BASE_URL points the client at another server, like a local mock
+			BaseURL: os.Getenv("TMDB_BASE_URL"),
+			Cache:   app.newAPIResponseCache("tmdb"),
+		})
 		if err != nil {
 			app.Logger.Warn("failed to initialize tmdb client", "error", err)
 		} else {
@@ -192,16 +224,59 @@ func InitApp() (*Application, error) {
 		}
 	}
 
-	// Start movies library scanner in background if TMDB key is set and movies directory is configured.
-	if app.Settings.TmdbKey.Valid && app.Settings.MoviesDir.Valid && app.Settings.MoviesDir.String != "" {
+	// Initialize Last.fm client if the API account is configured.
+	// This is optional - users can only link Last.fm accounts with it.
+	if os.Getenv("LASTFM_API_KEY") != "" || os.Getenv("LASTFM_SHARED_SECRET") != "" {
+		lastfm, err := lastfm.New(os.Getenv("LASTFM_API_KEY"), os.Getenv("LASTFM_SHARED_SECRET"), lastfm.Config{
+			// LASTFM_BASE_URL points the client at another server, like a local stub
+			BaseURL: os.Getenv("LASTFM_BASE_URL"),
+		})
+		if err != nil {
+			app.Logger.Warn("failed to initialize lastfm client", "error", err)
+		} else {
+			app.Lastfm = lastfm
+			app.Logger.Info("lastfm client initialized successfully")
+		}
+	}
+
+	// Initialize the ListenBrainz client, users submit listens with their own token.
+	app.Listenbrainz = listenbrainz.New(listenbrainz.Config{
+		// LISTENBRAINZ_BASE_URL points the client at another server, like a self-hosted one
+		BaseURL: os.Getenv("LISTENBRAINZ_BASE_URL"),
+	})
+
+	// Start movies library scanner in background if movies directory is configured.
+	// TMDB is one of its metadata providers, the scanner runs without it.
+	if app.Settings().MoviesDir.Valid && app.Settings().MoviesDir.String != "" {
 		go app.ScanMoviesLibrary()
 	}
 
 	// Start music library scanner in background if music directory is configured.
-	if app.Settings.MusicDir.Valid && app.Settings.MusicDir.String != "" {
+	if app.Settings().MusicDir.Valid && app.Settings().MusicDir.String != "" {
 		go app.ScanMusicLibrary()
 	}
 
+	// Start audiobooks library scanner in background if audiobooks directory is configured.
+	if app.Settings().AudiobooksDir.Valid && app.Settings().AudiobooksDir.String != "" {
+		go app.ScanAudiobooksLibrary()
+	}
+
+	// Periodically poll podcast feeds for new episodes.
+	go app.RunPodcastPoller()
+
+	// Periodically refresh stale TMDB and Spotify metadata if either is configured.
+	if app.Tmdb != nil || app.Spotify != nil {
+		go app.RunMetadataRefresher()
+	}
+
+	// Send queued Last.fm scrobbles, retrying the ones Last.fm couldn't take.
+	if app.Lastfm != nil {
+		go app.RunLastfmScrobbler()
+	}
+
+	// Submit queued ListenBrainz listens, retrying the ones ListenBrainz couldn't take.
+	go app.RunListenbrainzSubmitter()
+
 	app.InitRouter()
 
 	return &app, nil
@@ -246,19 +321,49 @@ func (app *Application) InitDB() error {
 // Uses CREATE TABLE IF NOT EXISTS so it's safe to run on every startup.
 // This ensures the database schema is always up to date with the application.
 func (app *Application) InitTables() error {
-	_, err := app.DB.Exec(SQL)
+	ctx := context.Background()
+
+	// Add columns introduced after a table was first released (e.g. movies.poster_path)
+	// before the schema creates indexes on them.
+	err := app.migrateColumns(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Rebuild tables whose constraints changed before the schema recreates the
+	// indexes dropped with the old tables.
+	err = app.migrateTables(ctx)
 	if err != nil {
 		return err
 	}
 
-	// One-off migration: add poster_path to movies if missing (e.g. existing DBs created before this column).
-	_, _ = app.DB.Exec("ALTER TABLE movies ADD COLUMN poster_path TEXT")
+	_, err = app.DB.Exec(SQL)
+	if err != nil {
+		return err
+	}
+
+	err = app.migrateMediaVersions(ctx)
+	if err != nil {
+		return err
+	}
 
 	app.Logger.Info("database tables initialized successfully")
 
 	return nil
 }
 
+// Settings returns the current application settings. Handlers replace the record as a
+// whole rather than editing it, so the returned settings never change under the caller;
+// code reading several fields should read them from one call.
+func (app *Application) Settings() *database.Setting {
+	return app.settings.Load()
+}
+
+// SetSettings replaces the application settings, e.g. after an update was saved.
+func (app *Application) SetSettings(settings *database.Setting) {
+	app.settings.Store(settings)
+}
+
 // InitSettings loads application settings from the database.
 // If no settings exist (first run), creates a new settings record
 // populated from environment variables with sensible defaults.
@@ -267,7 +372,23 @@ func (app *Application) InitSettings(ctx context.Context) error {
 	if err == nil {
 		// Settings exist - use them.
 		app.Logger.Info("loaded existing settings from database")
-		app.Settings = &settings
+
+		// Libraries added after the settings were created are still read from the
+		// environment until they are set
+		if (!settings.AudiobooksDir.Valid && os.Getenv("AUDIOBOOKS_DIR") != "") ||
+			(!settings.PodcastsDir.Valid && os.Getenv("PODCASTS_DIR") != "") {
+			settings, err = app.Queries.BackfillLibraryDirs(ctx, database.BackfillLibraryDirsParams{
+				AudiobooksDir: helpers.NullString(os.Getenv("AUDIOBOOKS_DIR")),
+				PodcastsDir:   helpers.NullString(os.Getenv("PODCASTS_DIR")),
+				ID:            settings.ID,
+			})
+			if err != nil {
+				return err
+			}
+			app.Logger.Info("set library directories from environment")
+		}
+
+		app.SetSettings(&settings)
 		return nil
 	}
 
@@ -311,6 +432,8 @@ func (app *Application) InitSettings(ctx context.Context) error {
 		MoviesDir:                  helpers.NullString(os.Getenv("MOVIES_DIR")),
 		ShowsDir:                   helpers.NullString(os.Getenv("SHOWS_DIR")),
 		MusicDir:                   helpers.NullString(os.Getenv("MUSIC_DIR")),
+		AudiobooksDir:              helpers.NullString(os.Getenv("AUDIOBOOKS_DIR")),
+		PodcastsDir:                helpers.NullString(os.Getenv("PODCASTS_DIR")),
 		StaticDir:                  staticDir,
 		LogsDir:                    logsDir,
 	}
@@ -322,65 +445,87 @@ func (app *Application) InitSettings(ctx context.Context) error {
 
 	app.Logger.Info("default settings created successfully")
 
-	app.Settings = &settings
+	app.SetSettings(&settings)
 
 	return nil
 }
 
 // InitDirs ensures all required directories exist, creating them if necessary.
 // Required directories (static, logs) are always created.
-// Optional media directories (movies, shows, music) are only created if configured.
+// Optional media directories (movies, shows, music, audiobooks, podcasts) are only created if configured.
 func (app *Application) InitDirs() error {
 	// Create required directories - these are needed for the app to function.
-	created, err := helpers.GetOrCreateDir(app.Settings.StaticDir)
+	created, err := helpers.GetOrCreateDir(app.Settings().StaticDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize static directory: %w", err)
 	}
 
 	if created {
-		app.Logger.Info("created static directory", "path", app.Settings.StaticDir)
+		app.Logger.Info("created static directory", "path", app.Settings().StaticDir)
 	}
 
-	created, err = helpers.GetOrCreateDir(app.Settings.LogsDir)
+	created, err = helpers.GetOrCreateDir(app.Settings().LogsDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize logs directory: %w", err)
 	}
 
 	if created {
-		app.Logger.Info("created logs directory", "path", app.Settings.LogsDir)
+		app.Logger.Info("created logs directory", "path", app.Settings().LogsDir)
 	}
 
 	// Create optional media directories only if they are configured.
-	if app.Settings.MoviesDir.Valid {
-		created, err = helpers.GetOrCreateDir(app.Settings.MoviesDir.String)
+	if app.Settings().MoviesDir.Valid {
+		created, err = helpers.GetOrCreateDir(app.Settings().MoviesDir.String)
 		if err != nil {
 			app.Logger.Error("failed to initialize movies directory", "error", err)
 		}
 
 		if created {
-			app.Logger.Info("created movies directory", "path", app.Settings.MoviesDir.String)
+			app.Logger.Info("created movies directory", "path", app.Settings().MoviesDir.String)
 		}
 	}
 
-	if app.Settings.ShowsDir.Valid {
-		created, err = helpers.GetOrCreateDir(app.Settings.ShowsDir.String)
+	if app.Settings().ShowsDir.Valid {
+		created, err = helpers.GetOrCreateDir(app.Settings().ShowsDir.String)
 		if err != nil {
 			app.Logger.Error("failed to initialize shows directory", "error", err)
 		}
 
 		if created {
-			app.Logger.Info("created shows directory", "path", app.Settings.ShowsDir.String)
+			app.Logger.Info("created shows directory", "path", app.Settings().ShowsDir.String)
 		}
 	}
 
-	if app.Settings.MusicDir.Valid {
-		created, err = helpers.GetOrCreateDir(app.Settings.MusicDir.String)
+	if app.Settings().MusicDir.Valid {
+		created, err = helpers.GetOrCreateDir(app.Settings().MusicDir.String)
 		if err != nil {
 			app.Logger.Error("failed to initialize music directory", "error", err)
 		}
 
 		if created {
-			app.Logger.Info("created music directory", "path", app.Settings.MusicDir.String)
+			app.Logger.Info("created music directory", "path", app.Settings().MusicDir.String)
+		}
+	}
+
+	if app.Settings().AudiobooksDir.Valid {
+		created, err = helpers.GetOrCreateDir(app.Settings().AudiobooksDir.String)
+		if err != nil {
+			app.Logger.Error("failed to initialize audiobooks directory", "error", err)
+		}
+
+		if created {
+			app.Logger.Info("created audiobooks directory", "path", app.Settings().AudiobooksDir.String)
+		}
+	}
+
+	if app.Settings().PodcastsDir.Valid {
+		created, err = helpers.GetOrCreateDir(app.Settings().PodcastsDir.String)
+		if err != nil {
+			app.Logger.Error("failed to initialize podcasts directory", "error", err)
+		}
+
+		if created {
+			app.Logger.Info("created podcasts directory", "path", app.Settings().PodcastsDir.String)
 		}
 	}
 
@@ -511,6 +656,20 @@ func (app *Application) InitRouter() {
 		// Static file serving (avatars, etc.)
 		r.Get("/static/*", app.ServeStaticFiles)
 
+		r.Route("/lastfm", func(r chi.Router) {
+			r.Get("/", app.GetLastfmStatus)
+			r.Delete("/", app.UnlinkLastfm)
+			r.Get("/auth", app.StartLastfmAuth)
+			r.Get("/callback", app.LastfmCallback)
+		})
+
+		r.Route("/listenbrainz", func(r chi.Router) {
+			r.Get("/", app.GetListenbrainzStatus)
+			r.Delete("/", app.UnlinkListenbrainz)
+			r.Put("/token", app.SetListenbrainzToken)
+			r.Post("/import", app.StartListenbrainzImport)
+		})
+
 		r.Route("/tmdb", func(r chi.Router) {
 			r.Get("/movies/in-theaters", app.GetMoviesInTheaters)
 			r.Get("/movies/{id}", app.GetMovieByTmdbID)
@@ -519,13 +678,79 @@ func (app *Application) InitRouter() {
 		r.Route("/movies", func(r chi.Router) {
 			r.Get("/latest", app.GetLatestMovies)
 			r.Get("/details/{id}", app.GetMovieDetails)
+			r.Get("/collections", app.GetMovieCollections)
+			r.Get("/collections/{id}", app.GetMovieCollection)
 			r.Get("/{id}/stream", app.StreamMovie)
+			r.Get("/{id}/extras/{extraID}/stream", app.StreamLocalExtra)
+
+			r.Group(func(r chi.Router) {
+				r.Use(app.IsAdmin)
+				r.Get("/{id}/match/search", app.SearchMovieMatch)
+				r.Post("/{id}/match", app.MatchMovie)
+				r.Patch("/{id}", app.PatchMovie)
+			})
+		})
+
+		r.Route("/people", func(r chi.Router) {
+			r.Get("/", app.GetPeople)
+			r.Get("/{id}", app.GetPerson)
 		})
 
 		r.Route("/settings", func(r chi.Router) {
 			r.Get("/", app.GetSettings)
 			r.Post("/scan/music", app.TriggerMusicScan)
+			r.Post("/scan/audiobooks", app.TriggerAudiobookScan)
 			r.Post("/scan/movies", app.TriggerMovieScan)
+
+			r.Group(func(r chi.Router) {
+				r.Use(app.IsAdmin)
+				r.Put("/scanner", app.UpdateScannerSettings)
+				r.Put("/metadata-language", app.UpdateMetadataLanguageSettings)
+				r.Put("/metadata-refresh", app.UpdateMetadataRefreshSettings)
+				r.Put("/podcasts", app.UpdatePodcastSettings)
+				r.Post("/refresh/metadata", app.TriggerMetadataRefresh)
+				r.Get("/scan-errors", app.GetScanErrors)
+				r.Post("/scan-errors/retry", app.RetryScanErrors)
+			})
+		})
+
+		r.Route("/genres", func(r chi.Router) {
+			r.Get("/", app.GetGenres)
+
+			r.Group(func(r chi.Router) {
+				r.Use(app.IsAdmin)
+				r.Post("/merge", app.MergeGenres)
+				r.Post("/{id}/aliases", app.AddGenreAlias)
+			})
+		})
+
+		r.Route("/audiobooks", func(r chi.Router) {
+			r.Get("/", app.GetAudiobooksAlphabetical)
+			r.Get("/in-progress", app.GetAudiobooksInProgress)
+			r.Get("/authors", app.GetAuthorsAlphabetical)
+			r.Get("/authors/{id}", app.GetAuthorDetails)
+			r.Get("/{id}", app.GetAudiobookDetails)
+			r.Get("/{id}/files/{fileID}/stream", app.StreamAudiobookFile)
+			r.Get("/{id}/chapters/{index}/stream", app.StreamAudiobookChapter)
+			r.Put("/{id}/progress", app.UpdateAudiobookProgress)
+			r.Post("/{id}/bookmarks", app.CreateAudiobookBookmark)
+			r.Delete("/{id}/bookmarks/{bookmarkID}", app.DeleteAudiobookBookmark)
+		})
+
+		r.Route("/podcasts", func(r chi.Router) {
+			r.Get("/", app.GetPodcasts)
+			r.Get("/{id}", app.GetPodcastDetails)
+			r.Get("/episodes/{id}/stream", app.StreamPodcastEpisode)
+			r.Put("/episodes/{id}/progress", app.UpdatePodcastEpisodeProgress)
+
+			r.Group(func(r chi.Router) {
+				r.Use(app.IsAdmin)
+				r.Post("/", app.SubscribePodcast)
+				r.Put("/{id}", app.UpdatePodcastDownloadSettings)
+				r.Delete("/{id}", app.UnsubscribePodcast)
+				r.Post("/{id}/refresh", app.RefreshPodcast)
+				r.Post("/episodes/{id}/download", app.DownloadPodcastEpisode)
+			})
 		})
 
 		r.Route("/music", func(r chi.Router) {
@@ -536,11 +761,23 @@ func (app *Application) InitRouter() {
 				r.Get("/details/{id}", app.GetAlbumDetails)
 				r.Get("/latest", app.GetLatestAlbums)
 				r.Delete("/{id}", app.DeleteAlbum)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.IsAdmin)
+					r.Patch("/{id}", app.PatchAlbum)
+					r.Get("/{id}/match/search", app.SearchAlbumMatch)
+					r.Post("/{id}/match", app.MatchAlbum)
+				})
 			})
 
 			r.Route("/musicians", func(r chi.Router) {
 				r.Get("/", app.GetMusiciansAlphabetical)
 				r.Get("/{id}", app.GetMusicianDetails)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.IsAdmin)
+					r.Patch("/{id}", app.PatchMusician)
+				})
 			})
 
 			r.Route("/tracks", func(r chi.Router) {
@@ -548,8 +785,15 @@ func (app *Application) InitRouter() {
 				r.Get("/shuffle", app.GetShuffleTracks)
 				r.Get("/details/{id}", app.GetTrackByID)
 				r.Get("/{id}/stream", app.StreamTrack)
+				r.Get("/{id}/lyrics", app.GetTrackLyrics)
+				r.Put("/{id}/lyrics", app.PutTrackLyrics)
 				r.Post("/{id}/like", app.ToggleLikeTrack)
 				r.Get("/liked", app.GetLikedTrackIDs)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.IsAdmin)
+					r.Patch("/{id}", app.PatchTrack)
+				})
 			})
 
 			r.Route("/playlists", func(r chi.Router) {
@@ -569,6 +813,7 @@ func (app *Application) InitRouter() {
 
 			r.Route("/user-stats", func(r chi.Router) {
 				r.Post("/play", app.RecordPlayEvent)
+				r.Post("/now-playing", app.UpdateNowPlaying)
 				r.Get("/overview", app.GetUserListeningStats)
 				r.Get("/top-tracks", app.GetUserTopTracks)
 				r.Get("/top-musicians", app.GetUserTopMusicians)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"igloo/cmd/internal/database"
@@ -28,6 +29,18 @@ func setupTestLogger(t *testing.T, app *Application) {
 	app.Logger = logger
 }
 
+// newTestApplication creates an Application on db with a debug logger. Zero-value
+// settings stand in for the record InitSettings loads at startup.
+func newTestApplication(t *testing.T, db *sql.DB) *Application {
+	t.Helper()
+
+	app := &Application{DB: db}
+	app.SetSettings(&database.Setting{})
+	setupTestLogger(t, app)
+
+	return app
+}
+
 func TestInitDB(t *testing.T) {
 	// Create a temporary directory for the test database
 	tmpDir := t.TempDir()
@@ -109,8 +122,7 @@ func TestInitTables(t *testing.T) {
 	}
 	defer db.Close()
 
-	app := &Application{DB: db}
-	setupTestLogger(t, app)
+	app := newTestApplication(t, db)
 
 	err = app.InitTables()
 	if err != nil {
@@ -155,8 +167,7 @@ func TestInitTables_Indexes(t *testing.T) {
 	}
 	defer db.Close()
 
-	app := &Application{DB: db}
-	setupTestLogger(t, app)
+	app := newTestApplication(t, db)
 
 	err = app.InitTables()
 	if err != nil {
@@ -205,8 +216,7 @@ func TestInitTables_Idempotent(t *testing.T) {
 	}
 	defer db.Close()
 
-	app := &Application{DB: db}
-	setupTestLogger(t, app)
+	app := newTestApplication(t, db)
 
 	// Run InitTables twice - should not fail
 	err = app.InitTables()
@@ -220,6 +230,308 @@ func TestInitTables_Idempotent(t *testing.T) {
 	}
 }
 
+// TestInitTables_MigratesTracksChecks tests that a tracks table created with the
+// original mp3/flac/m4a CHECK constraints is rebuilt without losing rows or links.
+func TestInitTables_MigratesTracksChecks(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "igloo.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	app := newTestApplication(t, db)
+
+	// The tracks table as created by earlier versions
+	_, err = db.Exec(`CREATE TABLE tracks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT NOT NULL,
+		sort_title TEXT NOT NULL,
+		file_path TEXT NOT NULL UNIQUE,
+		file_name TEXT NOT NULL,
+		container TEXT NOT NULL CHECK (container IN ('mp3', 'flac', 'm4a')),
+		mime_type TEXT NOT NULL CHECK (mime_type IN ('audio/mpeg', 'audio/flac', 'audio/mp4')),
+		codec TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		track_index INTEGER NOT NULL,
+		duration INTEGER NOT NULL,
+		disc INTEGER NOT NULL,
+		channels TEXT NOT NULL,
+		channel_layout TEXT NOT NULL,
+		bit_rate INTEGER NOT NULL,
+		profile TEXT NOT NULL,
+		release_date TEXT,
+		year INTEGER,
+		composer TEXT,
+		copyright TEXT,
+		language TEXT,
+		album_id INTEGER,
+		musician_id INTEGER,
+		created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (album_id) REFERENCES albums (id) ON DELETE CASCADE ON UPDATE CASCADE,
+		FOREIGN KEY (musician_id) REFERENCES musicians (id) ON DELETE SET NULL ON UPDATE CASCADE
+	)`)
+	if err != nil {
+		t.Fatalf("Failed to create old tracks table: %v", err)
+	}
+
+	// Create the remaining tables around the old tracks table. Columns are migrated
+	// first, like InitTables does, so the schema's indexes on them can be created.
+	err = app.migrateColumns(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to migrate columns: %v", err)
+	}
+
+	_, err = db.Exec(SQL)
+	if err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO tracks (id, title, sort_title, file_path, file_name, container, mime_type, codec,
+		size, track_index, duration, disc, channels, channel_layout, bit_rate, profile)
+		VALUES (1, 'Track', 'Track', '/music/track.flac', 'track.flac', 'flac', 'audio/flac', 'flac', 1, 1, 1, 1, '2', 'stereo', 1, '')`)
+	if err != nil {
+		t.Fatalf("Failed to insert track: %v", err)
+	}
+
+	_, err = db.Exec("INSERT INTO genres (id, tag, genre_type) VALUES (1, 'Rock', 'music')")
+	if err != nil {
+		t.Fatalf("Failed to insert genre: %v", err)
+	}
+
+	_, err = db.Exec("INSERT INTO track_genres (track_id, genre_id) VALUES (1, 1)")
+	if err != nil {
+		t.Fatalf("Failed to insert track genre: %v", err)
+	}
+
+	err = app.InitTables()
+	if err != nil {
+		t.Fatalf("InitTables failed: %v", err)
+	}
+
+	var title string
+	err = db.QueryRow("SELECT title FROM tracks WHERE id = 1").Scan(&title)
+	if err != nil || title != "Track" {
+		t.Errorf("Expected existing track to survive migration, got %q: %v", title, err)
+	}
+
+	var links int
+	err = db.QueryRow("SELECT COUNT(*) FROM track_genres WHERE track_id = 1").Scan(&links)
+	if err != nil || links != 1 {
+		t.Errorf("Expected track genre link to survive migration, got %d: %v", links, err)
+	}
+
+	_, err = db.Exec(`INSERT INTO tracks (title, sort_title, file_path, file_name, container, mime_type, codec,
+		size, track_index, duration, disc, channels, channel_layout, bit_rate, profile)
+		VALUES ('Opus', 'Opus', '/music/track.opus', 'track.opus', 'opus', 'audio/ogg', 'opus', 1, 1, 1, 1, '2', 'stereo', 1, '')`)
+	if err != nil {
+		t.Errorf("Expected opus track to be accepted after migration: %v", err)
+	}
+
+	var index string
+	err = db.QueryRow("SELECT name FROM sqlite_master WHERE type='index' AND name='idx_track_title'").Scan(&index)
+	if err != nil {
+		t.Errorf("Expected idx_track_title to be recreated: %v", err)
+	}
+
+	// Running again must leave the migrated table alone
+	err = app.InitTables()
+	if err != nil {
+		t.Fatalf("Second InitTables call failed: %v", err)
+	}
+}
+
+// TestInitTables_MigratesMusicianNames tests that a musicians table created with a unique
+// name is rebuilt so same-named musicians can be told apart by their MusicBrainz id.
+func TestInitTables_MigratesMusicianNames(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "igloo.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	app := newTestApplication(t, db)
+
+	// The musicians table as created by earlier versions
+	_, err = db.Exec(`CREATE TABLE musicians (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		sort_name TEXT NOT NULL,
+		summary TEXT,
+		spotify_popularity REAL,
+		spotify_followers INTEGER,
+		spotify_id TEXT UNIQUE,
+		thumb TEXT,
+		created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		t.Fatalf("Failed to create old musicians table: %v", err)
+	}
+
+	_, err = db.Exec("INSERT INTO musicians (id, name, sort_name) VALUES (1, 'Nirvana', 'Nirvana')")
+	if err != nil {
+		t.Fatalf("Failed to insert musician: %v", err)
+	}
+
+	err = app.InitTables()
+	if err != nil {
+		t.Fatalf("InitTables failed: %v", err)
+	}
+
+	var name string
+	err = db.QueryRow("SELECT name FROM musicians WHERE id = 1").Scan(&name)
+	if err != nil || name != "Nirvana" {
+		t.Errorf("Expected existing musician to survive migration, got %q: %v", name, err)
+	}
+
+	// The 60s British band and the 90s American one
+	_, err = db.Exec(`INSERT INTO musicians (name, sort_name, musicbrainz_id) VALUES
+		('Nirvana', 'Nirvana', '5b11f4ce-a62d-471e-81fc-a69a8278c7da'),
+		('Nirvana', 'Nirvana', '9282c8b4-ca0b-4c6b-b7e3-4f7762dfc4d6')`)
+	if err != nil {
+		t.Errorf("Expected same-named musicians with different ids to be accepted: %v", err)
+	}
+
+	_, err = db.Exec("INSERT INTO musicians (name, sort_name) VALUES ('Nirvana', 'Nirvana')")
+	if err == nil {
+		t.Error("Expected a second musician without an id to conflict on the name")
+	}
+
+	// Running again must leave the migrated table alone
+	err = app.InitTables()
+	if err != nil {
+		t.Fatalf("Second InitTables call failed: %v", err)
+	}
+}
+
+// TestInitTables_MigratesMediaVersions tests that a library scanned before media versions
+// existed gets a version per movie file, with its streams linked to it, and that movies
+// sharing a TMDB id are merged into the oldest one.
+func TestInitTables_MigratesMediaVersions(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "igloo.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	app := newTestApplication(t, db)
+
+	movies, err := schemaCreateTable("movies")
+	if err != nil {
+		t.Fatalf("Failed to find movies table: %v", err)
+	}
+
+	videoStreams, err := schemaCreateTable("video_streams")
+	if err != nil {
+		t.Fatalf("Failed to find video_streams table: %v", err)
+	}
+
+	// The video_streams table as created by earlier versions
+	videoStreams = strings.Replace(videoStreams, "media_version_id INTEGER,", "", 1)
+	videoStreams = strings.Replace(videoStreams, ",\n    FOREIGN KEY (media_version_id) REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE", "", 1)
+	if strings.Contains(videoStreams, "media_version_id") {
+		t.Fatalf("Failed to strip media_version_id from %s", videoStreams)
+	}
+
+	for _, statement := range []string{
+		movies,
+		videoStreams,
+		`INSERT INTO movies (id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id)
+		VALUES (1, 'Alien', '/movies/alien-2160p.mkv', 'alien-2160p.mkv', 1, 'mkv', 'video/x-matroska', 0, 348),
+		(2, 'Alien', '/movies/alien-1080p.mkv', 'alien-1080p.mkv', 1, 'mkv', 'video/x-matroska', 0, 348),
+		(3, 'Heat', '/movies/heat.mkv', 'heat.mkv', 1, 'mkv', 'video/x-matroska', 0, NULL)`,
+		`INSERT INTO video_streams (movie_id, stream_index, codec, bit_rate, width, height, frame_rate)
+		VALUES (1, 0, 'hevc', 1, 3840, 2160, 24), (2, 0, 'h264', 1, 1920, 1080, 24), (3, 0, 'h264', 1, 1920, 1080, 24)`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Failed to set up old schema: %v", err)
+		}
+	}
+
+	err = app.InitTables()
+	if err != nil {
+		t.Fatalf("InitTables failed: %v", err)
+	}
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM movies").Scan(&count)
+	if err != nil || count != 2 {
+		t.Errorf("Expected duplicate movie to be merged into 2 movies, got %d: %v", count, err)
+	}
+
+	err = db.QueryRow("SELECT COUNT(*) FROM media_versions WHERE movie_id = 1").Scan(&count)
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 versions for movie 1, got %d: %v", count, err)
+	}
+
+	err = db.QueryRow(`SELECT COUNT(*) FROM video_streams vs
+		INNER JOIN media_versions mv ON mv.id = vs.media_version_id AND mv.movie_id = vs.movie_id`).Scan(&count)
+	if err != nil || count != 3 {
+		t.Errorf("Expected all 3 video streams to be linked to a version of their movie, got %d: %v", count, err)
+	}
+
+	// Running again must not duplicate anything
+	err = app.InitTables()
+	if err != nil {
+		t.Fatalf("Second InitTables call failed: %v", err)
+	}
+
+	err = db.QueryRow("SELECT COUNT(*) FROM media_versions").Scan(&count)
+	if err != nil || count != 3 {
+		t.Errorf("Expected 3 versions after second run, got %d: %v", count, err)
+	}
+
+	// Once migrated, movies sharing a TMDB id are no longer merged at startup
+	_, err = db.Exec(`INSERT INTO movies (id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id)
+		VALUES (4, 'Alien', '/movies/alien-remake.mkv', 'alien-remake.mkv', 1, 'mkv', 'video/x-matroska', 0, 348);
+		INSERT INTO media_versions (movie_id, file_path, file_name, size, container, mime_type)
+		VALUES (4, '/movies/alien-remake.mkv', 'alien-remake.mkv', 1, 'mkv', 'video/x-matroska')`)
+	if err != nil {
+		t.Fatalf("Failed to insert movie: %v", err)
+	}
+
+	err = app.InitTables()
+	if err != nil {
+		t.Fatalf("Third InitTables call failed: %v", err)
+	}
+
+	err = db.QueryRow("SELECT COUNT(*) FROM movies").Scan(&count)
+	if err != nil || count != 3 {
+		t.Errorf("Expected the migrated database to be left alone with 3 movies, got %d: %v", count, err)
+	}
+}
+
+// TestSchema_ContainerChecksMatchHelpers tests that every container the scanners
+// can produce is allowed by the tracks and movies CHECK constraints.
+func TestSchema_ContainerChecksMatchHelpers(t *testing.T) {
+	tracks, err := schemaCreateTable("tracks")
+	if err != nil {
+		t.Fatalf("Failed to find tracks table: %v", err)
+	}
+
+	for ext, container := range helpers.AudioContainers {
+		if !strings.Contains(tracks, "'"+container+"'") {
+			t.Errorf("tracks.container CHECK is missing %q (extension %q)", container, ext)
+		}
+
+		if !strings.Contains(tracks, "'"+helpers.AudioMimeTypes[container]+"'") {
+			t.Errorf("tracks.mime_type CHECK is missing %q", helpers.AudioMimeTypes[container])
+		}
+	}
+
+	movies, err := schemaCreateTable("movies")
+	if err != nil {
+		t.Fatalf("Failed to find movies table: %v", err)
+	}
+
+	for _, container := range helpers.VideoContainers {
+		if !strings.Contains(movies, "'"+container.Name+"'") {
+			t.Errorf("movies.container CHECK is missing %q", container.Name)
+		}
+	}
+}
+
 func TestInitTables_UsersSchema(t *testing.T) {
 	db, err := sql.Open("sqlite3", ":memory:?_foreign_keys=on")
 	if err != nil {
@@ -227,8 +539,7 @@ func TestInitTables_UsersSchema(t *testing.T) {
 	}
 	defer db.Close()
 
-	app := &Application{DB: db}
-	setupTestLogger(t, app)
+	app := newTestApplication(t, db)
 
 	err = app.InitTables()
 	if err != nil {
@@ -283,8 +594,7 @@ func TestInitTables_ForeignKeys(t *testing.T) {
 	}
 	defer db.Close()
 
-	app := &Application{DB: db}
-	setupTestLogger(t, app)
+	app := newTestApplication(t, db)
 
 	err = app.InitTables()
 	if err != nil {
@@ -339,8 +649,7 @@ func setupTestApp(t *testing.T) *Application {
 		t.Fatalf("Failed to open in-memory database: %v", err)
 	}
 
-	app := &Application{DB: db}
-	setupTestLogger(t, app)
+	app := newTestApplication(t, db)
 
 	err = app.InitTables()
 	if err != nil {
@@ -368,7 +677,7 @@ func TestInitSettings_CreatesDefaultSettings(t *testing.T) {
 		"SPOTIFY_CLIENT_ID", "SPOTIFY_CLIENT_SECRET",
 		"HARDWARE_ACCELERATION_DEVICE",
 		"ENABLE_LOGGER", "ENABLE_WATCHER", "DOWNLOAD_IMAGES",
-		"MOVIES_DIR", "SHOWS_DIR", "MUSIC_DIR",
+		"MOVIES_DIR", "SHOWS_DIR", "MUSIC_DIR", "AUDIOBOOKS_DIR", "PODCASTS_DIR",
 		"STATIC_DIR", "LOGS_DIR",
 	}
 	for _, v := range envVars {
@@ -381,57 +690,57 @@ func TestInitSettings_CreatesDefaultSettings(t *testing.T) {
 	}
 
 	// Verify settings were created and stored
-	if app.Settings == nil {
+	if app.Settings() == nil {
 		t.Fatal("Settings should not be nil after InitSettings")
 	}
 
 	// Verify default values for required string fields
-	if app.Settings.StaticDir != "static" {
-		t.Errorf("Expected StaticDir 'static', got '%s'", app.Settings.StaticDir)
+	if app.Settings().StaticDir != "static" {
+		t.Errorf("Expected StaticDir 'static', got '%s'", app.Settings().StaticDir)
 	}
-	if app.Settings.LogsDir != "logs" {
-		t.Errorf("Expected LogsDir 'logs', got '%s'", app.Settings.LogsDir)
+	if app.Settings().LogsDir != "logs" {
+		t.Errorf("Expected LogsDir 'logs', got '%s'", app.Settings().LogsDir)
 	}
 
 	// Verify default value for HardwareAccelerationDevice (defaults to "cpu")
-	if app.Settings.HardwareAccelerationDevice.String != "cpu" {
-		t.Errorf("Expected HardwareAccelerationDevice 'cpu', got '%s'", app.Settings.HardwareAccelerationDevice.String)
+	if app.Settings().HardwareAccelerationDevice.String != "cpu" {
+		t.Errorf("Expected HardwareAccelerationDevice 'cpu', got '%s'", app.Settings().HardwareAccelerationDevice.String)
 	}
-	if !app.Settings.HardwareAccelerationDevice.Valid {
+	if !app.Settings().HardwareAccelerationDevice.Valid {
 		t.Error("Expected HardwareAccelerationDevice to be valid")
 	}
 
 	// Verify boolean defaults (all false)
-	if app.Settings.EnableLogger != false {
+	if app.Settings().EnableLogger != false {
 		t.Error("Expected EnableLogger to be false by default")
 	}
-	if app.Settings.EnableWatcher != false {
+	if app.Settings().EnableWatcher != false {
 		t.Error("Expected EnableWatcher to be false by default")
 	}
-	if app.Settings.DownloadImages != false {
+	if app.Settings().DownloadImages != false {
 		t.Error("Expected DownloadImages to be false by default")
 	}
 
 	// Verify optional NullString fields are invalid when not set
-	if app.Settings.TmdbKey.Valid {
+	if app.Settings().TmdbKey.Valid {
 		t.Error("Expected TmdbKey to be invalid when not set")
 	}
-	if app.Settings.JellyfinToken.Valid {
+	if app.Settings().JellyfinToken.Valid {
 		t.Error("Expected JellyfinToken to be invalid when not set")
 	}
-	if app.Settings.SpotifyClientID.Valid {
+	if app.Settings().SpotifyClientID.Valid {
 		t.Error("Expected SpotifyClientID to be invalid when not set")
 	}
-	if app.Settings.SpotifyClientSecret.Valid {
+	if app.Settings().SpotifyClientSecret.Valid {
 		t.Error("Expected SpotifyClientSecret to be invalid when not set")
 	}
-	if app.Settings.MoviesDir.Valid {
+	if app.Settings().MoviesDir.Valid {
 		t.Error("Expected MoviesDir to be invalid when not set")
 	}
-	if app.Settings.ShowsDir.Valid {
+	if app.Settings().ShowsDir.Valid {
 		t.Error("Expected ShowsDir to be invalid when not set")
 	}
-	if app.Settings.MusicDir.Valid {
+	if app.Settings().MusicDir.Valid {
 		t.Error("Expected MusicDir to be invalid when not set")
 	}
 }
@@ -478,47 +787,47 @@ func TestInitSettings_UsesEnvVars(t *testing.T) {
 	}
 
 	// Verify NullString fields from env vars
-	if app.Settings.TmdbKey.String != "test-tmdb-key" || !app.Settings.TmdbKey.Valid {
-		t.Errorf("Expected TmdbKey 'test-tmdb-key' (valid), got '%s' (valid=%v)", app.Settings.TmdbKey.String, app.Settings.TmdbKey.Valid)
+	if app.Settings().TmdbKey.String != "test-tmdb-key" || !app.Settings().TmdbKey.Valid {
+		t.Errorf("Expected TmdbKey 'test-tmdb-key' (valid), got '%s' (valid=%v)", app.Settings().TmdbKey.String, app.Settings().TmdbKey.Valid)
 	}
-	if app.Settings.JellyfinToken.String != "test-jellyfin-token" || !app.Settings.JellyfinToken.Valid {
-		t.Errorf("Expected JellyfinToken 'test-jellyfin-token' (valid), got '%s' (valid=%v)", app.Settings.JellyfinToken.String, app.Settings.JellyfinToken.Valid)
+	if app.Settings().JellyfinToken.String != "test-jellyfin-token" || !app.Settings().JellyfinToken.Valid {
+		t.Errorf("Expected JellyfinToken 'test-jellyfin-token' (valid), got '%s' (valid=%v)", app.Settings().JellyfinToken.String, app.Settings().JellyfinToken.Valid)
 	}
-	if app.Settings.SpotifyClientID.String != "test-spotify-id" || !app.Settings.SpotifyClientID.Valid {
-		t.Errorf("Expected SpotifyClientID 'test-spotify-id' (valid), got '%s' (valid=%v)", app.Settings.SpotifyClientID.String, app.Settings.SpotifyClientID.Valid)
+	if app.Settings().SpotifyClientID.String != "test-spotify-id" || !app.Settings().SpotifyClientID.Valid {
+		t.Errorf("Expected SpotifyClientID 'test-spotify-id' (valid), got '%s' (valid=%v)", app.Settings().SpotifyClientID.String, app.Settings().SpotifyClientID.Valid)
 	}
-	if app.Settings.SpotifyClientSecret.String != "test-spotify-secret" || !app.Settings.SpotifyClientSecret.Valid {
-		t.Errorf("Expected SpotifyClientSecret 'test-spotify-secret' (valid), got '%s' (valid=%v)", app.Settings.SpotifyClientSecret.String, app.Settings.SpotifyClientSecret.Valid)
+	if app.Settings().SpotifyClientSecret.String != "test-spotify-secret" || !app.Settings().SpotifyClientSecret.Valid {
+		t.Errorf("Expected SpotifyClientSecret 'test-spotify-secret' (valid), got '%s' (valid=%v)", app.Settings().SpotifyClientSecret.String, app.Settings().SpotifyClientSecret.Valid)
 	}
-	if app.Settings.HardwareAccelerationDevice.String != "nvidia" || !app.Settings.HardwareAccelerationDevice.Valid {
-		t.Errorf("Expected HardwareAccelerationDevice 'nvidia' (valid), got '%s' (valid=%v)", app.Settings.HardwareAccelerationDevice.String, app.Settings.HardwareAccelerationDevice.Valid)
+	if app.Settings().HardwareAccelerationDevice.String != "nvidia" || !app.Settings().HardwareAccelerationDevice.Valid {
+		t.Errorf("Expected HardwareAccelerationDevice 'nvidia' (valid), got '%s' (valid=%v)", app.Settings().HardwareAccelerationDevice.String, app.Settings().HardwareAccelerationDevice.Valid)
 	}
-	if app.Settings.MoviesDir.String != "/movies" || !app.Settings.MoviesDir.Valid {
-		t.Errorf("Expected MoviesDir '/movies' (valid), got '%s' (valid=%v)", app.Settings.MoviesDir.String, app.Settings.MoviesDir.Valid)
+	if app.Settings().MoviesDir.String != "/movies" || !app.Settings().MoviesDir.Valid {
+		t.Errorf("Expected MoviesDir '/movies' (valid), got '%s' (valid=%v)", app.Settings().MoviesDir.String, app.Settings().MoviesDir.Valid)
 	}
-	if app.Settings.ShowsDir.String != "/shows" || !app.Settings.ShowsDir.Valid {
-		t.Errorf("Expected ShowsDir '/shows' (valid), got '%s' (valid=%v)", app.Settings.ShowsDir.String, app.Settings.ShowsDir.Valid)
+	if app.Settings().ShowsDir.String != "/shows" || !app.Settings().ShowsDir.Valid {
+		t.Errorf("Expected ShowsDir '/shows' (valid), got '%s' (valid=%v)", app.Settings().ShowsDir.String, app.Settings().ShowsDir.Valid)
 	}
-	if app.Settings.MusicDir.String != "/music" || !app.Settings.MusicDir.Valid {
-		t.Errorf("Expected MusicDir '/music' (valid), got '%s' (valid=%v)", app.Settings.MusicDir.String, app.Settings.MusicDir.Valid)
+	if app.Settings().MusicDir.String != "/music" || !app.Settings().MusicDir.Valid {
+		t.Errorf("Expected MusicDir '/music' (valid), got '%s' (valid=%v)", app.Settings().MusicDir.String, app.Settings().MusicDir.Valid)
 	}
 
 	// Verify required string fields from env vars
-	if app.Settings.StaticDir != "custom-static" {
-		t.Errorf("Expected StaticDir 'custom-static', got '%s'", app.Settings.StaticDir)
+	if app.Settings().StaticDir != "custom-static" {
+		t.Errorf("Expected StaticDir 'custom-static', got '%s'", app.Settings().StaticDir)
 	}
-	if app.Settings.LogsDir != "custom-logs" {
-		t.Errorf("Expected LogsDir 'custom-logs', got '%s'", app.Settings.LogsDir)
+	if app.Settings().LogsDir != "custom-logs" {
+		t.Errorf("Expected LogsDir 'custom-logs', got '%s'", app.Settings().LogsDir)
 	}
 
 	// Verify boolean fields from env vars
-	if app.Settings.EnableLogger != true {
+	if app.Settings().EnableLogger != true {
 		t.Error("Expected EnableLogger to be true")
 	}
-	if app.Settings.EnableWatcher != true {
+	if app.Settings().EnableWatcher != true {
 		t.Error("Expected EnableWatcher to be true")
 	}
-	if app.Settings.DownloadImages != true {
+	if app.Settings().DownloadImages != true {
 		t.Error("Expected DownloadImages to be true")
 	}
 }
@@ -547,20 +856,62 @@ func TestInitSettings_LoadsExistingSettings(t *testing.T) {
 	}
 
 	// Verify the existing settings were loaded
-	if app.Settings.TmdbKey.String != "existing-key" {
-		t.Errorf("Expected TmdbKey 'existing-key', got '%s'", app.Settings.TmdbKey.String)
+	if app.Settings().TmdbKey.String != "existing-key" {
+		t.Errorf("Expected TmdbKey 'existing-key', got '%s'", app.Settings().TmdbKey.String)
 	}
-	if app.Settings.StaticDir != "existing-static" {
-		t.Errorf("Expected StaticDir 'existing-static', got '%s'", app.Settings.StaticDir)
+	if app.Settings().StaticDir != "existing-static" {
+		t.Errorf("Expected StaticDir 'existing-static', got '%s'", app.Settings().StaticDir)
 	}
-	if app.Settings.HardwareAccelerationDevice.String != "nvidia" {
-		t.Errorf("Expected HardwareAccelerationDevice 'nvidia', got '%s'", app.Settings.HardwareAccelerationDevice.String)
+	if app.Settings().HardwareAccelerationDevice.String != "nvidia" {
+		t.Errorf("Expected HardwareAccelerationDevice 'nvidia', got '%s'", app.Settings().HardwareAccelerationDevice.String)
 	}
-	if app.Settings.EnableLogger != true {
+	if app.Settings().EnableLogger != true {
 		t.Error("Expected EnableLogger to be true from existing settings")
 	}
 }
 
+func TestInitSettings_BackfillsLibraryDirs(t *testing.T) {
+	app := setupTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	// Settings created before the audiobooks library existed
+	_, err := app.Queries.CreateSettings(ctx, database.CreateSettingsParams{
+		StaticDir: "static",
+		LogsDir:   "logs",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test settings: %v", err)
+	}
+
+	os.Setenv("AUDIOBOOKS_DIR", "/audiobooks")
+	defer os.Unsetenv("AUDIOBOOKS_DIR")
+	os.Unsetenv("PODCASTS_DIR")
+
+	if err := app.InitSettings(ctx); err != nil {
+		t.Fatalf("InitSettings failed: %v", err)
+	}
+	if app.Settings().AudiobooksDir.String != "/audiobooks" {
+		t.Errorf("Expected AudiobooksDir '/audiobooks', got '%s'", app.Settings().AudiobooksDir.String)
+	}
+
+	// A directory already set is kept, one still unset is filled in
+	os.Setenv("AUDIOBOOKS_DIR", "/elsewhere")
+	os.Setenv("PODCASTS_DIR", "/podcasts")
+	defer os.Unsetenv("PODCASTS_DIR")
+
+	if err := app.InitSettings(ctx); err != nil {
+		t.Fatalf("InitSettings failed: %v", err)
+	}
+	if app.Settings().AudiobooksDir.String != "/audiobooks" {
+		t.Errorf("Expected AudiobooksDir to stay '/audiobooks', got '%s'", app.Settings().AudiobooksDir.String)
+	}
+	if app.Settings().PodcastsDir.String != "/podcasts" {
+		t.Errorf("Expected PodcastsDir '/podcasts', got '%s'", app.Settings().PodcastsDir.String)
+	}
+}
+
 func TestInitSettings_Idempotent(t *testing.T) {
 	app := setupTestApp(t)
 	defer app.DB.Close()
@@ -573,7 +924,7 @@ func TestInitSettings_Idempotent(t *testing.T) {
 		t.Fatalf("First InitSettings call failed: %v", err)
 	}
 
-	firstSettingsID := app.Settings.ID
+	firstSettingsID := app.Settings().ID
 
 	err = app.InitSettings(ctx)
 	if err != nil {
@@ -581,8 +932,8 @@ func TestInitSettings_Idempotent(t *testing.T) {
 	}
 
 	// Should load the same settings, not create a new one
-	if app.Settings.ID != firstSettingsID {
-		t.Errorf("Expected same settings ID %d, got %d", firstSettingsID, app.Settings.ID)
+	if app.Settings().ID != firstSettingsID {
+		t.Errorf("Expected same settings ID %d, got %d", firstSettingsID, app.Settings().ID)
 	}
 }
 
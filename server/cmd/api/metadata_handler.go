@@ -0,0 +1,581 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Fields a user can edit by hand on each entity. An edited field is locked so the
+// scanners and enrichers keep the user's value; "genres" locks the genre links.
+var (
+	movieEditableFields    = []string{"title", "sort_title", "year", "overview", "genres"}
+	albumEditableFields    = []string{"title", "sort_title", "year", "genres"}
+	musicianEditableFields = []string{"name", "sort_name", "summary", "genres"}
+	trackEditableFields    = []string{"title", "sort_title", "year", "genres"}
+)
+
+// PatchMovieRequest holds the movie fields to change. Omitted fields are left as they
+// are; locked_fields, when given, replaces the lock set instead of locking the edits.
+type PatchMovieRequest struct {
+	Title        *string   `json:"title"`
+	SortTitle    *string   `json:"sort_title"`
+	Year         *int64    `json:"year"`
+	Overview     *string   `json:"overview"`
+	Genres       *[]string `json:"genres"`
+	LockedFields *[]string `json:"locked_fields"`
+}
+
+// PatchAlbumRequest holds the album fields to change, like PatchMovieRequest.
+type PatchAlbumRequest struct {
+	Title        *string   `json:"title"`
+	SortTitle    *string   `json:"sort_title"`
+	Year         *int64    `json:"year"`
+	Genres       *[]string `json:"genres"`
+	LockedFields *[]string `json:"locked_fields"`
+}
+
+// PatchMusicianRequest holds the musician fields to change, like PatchMovieRequest.
+type PatchMusicianRequest struct {
+	Name         *string   `json:"name"`
+	SortName     *string   `json:"sort_name"`
+	Summary      *string   `json:"summary"`
+	Genres       *[]string `json:"genres"`
+	LockedFields *[]string `json:"locked_fields"`
+}
+
+// PatchTrackRequest holds the track fields to change, like PatchMovieRequest.
+type PatchTrackRequest struct {
+	Title        *string   `json:"title"`
+	SortTitle    *string   `json:"sort_title"`
+	Year         *int64    `json:"year"`
+	Genres       *[]string `json:"genres"`
+	LockedFields *[]string `json:"locked_fields"`
+}
+
+// metadataEdit collects the fields changed by a PATCH request and the first invalid value.
+type metadataEdit struct {
+	edited []string
+	err    error
+}
+
+// text applies a required text field such as a title.
+func (e *metadataEdit) text(field string, value *string, target *string) {
+	if value == nil {
+		return
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		e.fail(fmt.Errorf("%s cannot be empty", field))
+		return
+	}
+	*target = trimmed
+	e.edited = append(e.edited, field)
+}
+
+// optionalText applies a text field that an empty string clears.
+func (e *metadataEdit) optionalText(field string, value *string, target *sql.NullString) {
+	if value == nil {
+		return
+	}
+	*target = helpers.NullString(strings.TrimSpace(*value))
+	e.edited = append(e.edited, field)
+}
+
+// year applies a year; 0 clears it.
+func (e *metadataEdit) year(value *int64, target *sql.NullInt64) {
+	if value == nil {
+		return
+	}
+	if *value < 0 || *value > 9999 {
+		e.fail(errors.New("invalid year"))
+		return
+	}
+	*target = sql.NullInt64{Int64: *value, Valid: *value > 0}
+	e.edited = append(e.edited, "year")
+}
+
+// genres validates replacement genre tags.
+func (e *metadataEdit) genres(value *[]string) {
+	if value == nil {
+		return
+	}
+	for _, tag := range *value {
+		if helpers.NormalizeKey(tag) == "" {
+			e.fail(fmt.Errorf("invalid genre %q", tag))
+			return
+		}
+	}
+	e.edited = append(e.edited, "genres")
+}
+
+func (e *metadataEdit) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// lockedFields returns the lock set after the edit: the requested set when given,
+// otherwise the current locks plus every edited field.
+func (e *metadataEdit) lockedFields(current string, requested *[]string, allowed []string) (string, error) {
+	if requested == nil {
+		return helpers.FormatLockedFields(append(helpers.ParseLockedFields(current), e.edited...)), nil
+	}
+
+	for _, field := range *requested {
+		if !slices.Contains(allowed, field) {
+			return "", fmt.Errorf("field %q cannot be locked", field)
+		}
+	}
+
+	return helpers.FormatLockedFields(*requested), nil
+}
+
+// replaceGenres replaces an entity's genre links with the given tags, resolved through
+// the genre aliases like scanned tags are.
+func (app *Application) replaceGenres(
+	ctx context.Context,
+	qtx *database.Queries,
+	tags []string,
+	genreType string,
+	clear func() error,
+	link func(genreID int64) error,
+) error {
+	if err := clear(); err != nil {
+		return fmt.Errorf("delete genres failed: %w", err)
+	}
+
+	for _, tag := range tags {
+		genre, err := app.resolveGenre(ctx, qtx, tag, genreType)
+		if err != nil {
+			return err
+		}
+
+		if err := link(genre.ID); err != nil {
+			return fmt.Errorf("link genre failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PatchMovie edits a movie's metadata by hand. Edited fields are locked so rescans,
+// TMDB matches and refreshes keep them.
+func (app *Application) PatchMovie(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid movie id"), http.StatusBadRequest)
+		return
+	}
+
+	var req PatchMovieRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Serialize with the scanners, which write the same rows inside their batch transactions
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update movie"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	movie, err := qtx.GetMovieByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("movie not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get movie", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update movie"))
+		return
+	}
+
+	params := database.UpdateMovieMetadataParams{
+		ID:        movie.ID,
+		Title:     movie.Title,
+		SortTitle: movie.SortTitle,
+		Year:      movie.Year,
+		Overview:  movie.Overview,
+	}
+
+	var edit metadataEdit
+	edit.text("title", req.Title, &params.Title)
+	edit.optionalText("sort_title", req.SortTitle, &params.SortTitle)
+	edit.year(req.Year, &params.Year)
+	edit.optionalText("overview", req.Overview, &params.Overview)
+	edit.genres(req.Genres)
+	if edit.err != nil {
+		helpers.ErrorJSON(w, edit.err, http.StatusBadRequest)
+		return
+	}
+
+	params.LockedFields, err = edit.lockedFields(movie.LockedFields, req.LockedFields, movieEditableFields)
+	if err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	movie, err = qtx.UpdateMovieMetadata(ctx, params)
+	if err != nil {
+		app.Logger.Error("failed to update movie metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update movie"))
+		return
+	}
+
+	if req.Genres != nil {
+		err := app.replaceGenres(ctx, qtx, *req.Genres, "movie",
+			func() error { return qtx.DeleteMovieGenres(ctx, movie.ID) },
+			func(genreID int64) error {
+				return qtx.CreateMovieGenre(ctx, database.CreateMovieGenreParams{MovieID: movie.ID, GenreID: genreID})
+			},
+		)
+		if err != nil {
+			app.Logger.Error("failed to replace movie genres", "error", err, "id", id)
+			helpers.ErrorJSON(w, errors.New("failed to update movie"))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit movie metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update movie"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"movie": movieDetailsMovieToMap(movie),
+		},
+	})
+}
+
+// PatchAlbum edits an album's metadata by hand. A renamed album remembers the title
+// from its tags so the scanner keeps linking its tracks to it.
+func (app *Application) PatchAlbum(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid album id"), http.StatusBadRequest)
+		return
+	}
+
+	var req PatchAlbumRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update album"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	album, err := qtx.GetAlbumByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("album not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get album", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update album"))
+		return
+	}
+
+	params := database.UpdateAlbumMetadataParams{
+		ID:        album.ID,
+		Title:     album.Title,
+		SortTitle: album.SortTitle,
+		Year:      album.Year,
+		ScanTitle: album.ScanTitle,
+	}
+
+	var edit metadataEdit
+	edit.text("title", req.Title, &params.Title)
+	edit.text("sort_title", req.SortTitle, &params.SortTitle)
+	edit.year(req.Year, &params.Year)
+	edit.genres(req.Genres)
+	if edit.err != nil {
+		helpers.ErrorJSON(w, edit.err, http.StatusBadRequest)
+		return
+	}
+
+	params.LockedFields, err = edit.lockedFields(album.LockedFields, req.LockedFields, albumEditableFields)
+	if err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if params.Title != album.Title && !album.ScanTitle.Valid {
+		params.ScanTitle = sql.NullString{String: album.Title, Valid: true}
+	}
+
+	album, err = qtx.UpdateAlbumMetadata(ctx, params)
+	if err != nil {
+		app.Logger.Error("failed to update album metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update album"))
+		return
+	}
+
+	if req.Genres != nil {
+		err := app.replaceGenres(ctx, qtx, *req.Genres, "music",
+			func() error { return qtx.DeleteAlbumGenres(ctx, album.ID) },
+			func(genreID int64) error {
+				return qtx.UpsertAlbumGenre(ctx, database.UpsertAlbumGenreParams{AlbumID: album.ID, GenreID: genreID})
+			},
+		)
+		if err != nil {
+			app.Logger.Error("failed to replace album genres", "error", err, "id", id)
+			helpers.ErrorJSON(w, errors.New("failed to update album"))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit album metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update album"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"album":         album,
+			"locked_fields": helpers.ParseLockedFields(album.LockedFields),
+		},
+	})
+}
+
+// PatchMusician edits a musician's metadata by hand. A renamed musician remembers the
+// name from the tags so the scanner keeps linking tracks to it.
+func (app *Application) PatchMusician(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid musician id"), http.StatusBadRequest)
+		return
+	}
+
+	var req PatchMusicianRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update musician"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	musician, err := qtx.GetMusicianByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("musician not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get musician", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update musician"))
+		return
+	}
+
+	params := database.UpdateMusicianMetadataParams{
+		ID:       musician.ID,
+		Name:     musician.Name,
+		SortName: musician.SortName,
+		Summary:  musician.Summary,
+		ScanName: musician.ScanName,
+	}
+
+	var edit metadataEdit
+	edit.text("name", req.Name, &params.Name)
+	edit.text("sort_name", req.SortName, &params.SortName)
+	edit.optionalText("summary", req.Summary, &params.Summary)
+	edit.genres(req.Genres)
+	if edit.err != nil {
+		helpers.ErrorJSON(w, edit.err, http.StatusBadRequest)
+		return
+	}
+
+	params.LockedFields, err = edit.lockedFields(musician.LockedFields, req.LockedFields, musicianEditableFields)
+	if err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if params.Name != musician.Name && !musician.ScanName.Valid {
+		params.ScanName = sql.NullString{String: musician.Name, Valid: true}
+	}
+
+	musician, err = qtx.UpdateMusicianMetadata(ctx, params)
+	if err != nil {
+		app.Logger.Error("failed to update musician metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update musician"))
+		return
+	}
+
+	if req.Genres != nil {
+		err := app.replaceGenres(ctx, qtx, *req.Genres, "music",
+			func() error { return qtx.DeleteMusicianGenres(ctx, musician.ID) },
+			func(genreID int64) error {
+				return qtx.UpsertMusicianGenre(ctx, database.UpsertMusicianGenreParams{MusicianID: musician.ID, GenreID: genreID})
+			},
+		)
+		if err != nil {
+			app.Logger.Error("failed to replace musician genres", "error", err, "id", id)
+			helpers.ErrorJSON(w, errors.New("failed to update musician"))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit musician metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update musician"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"musician":      musician,
+			"locked_fields": helpers.ParseLockedFields(musician.LockedFields),
+		},
+	})
+}
+
+// PatchTrack edits a track's metadata by hand. Edited fields are locked so rescans
+// keep them over the file's tags.
+func (app *Application) PatchTrack(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid track id"), http.StatusBadRequest)
+		return
+	}
+
+	var req PatchTrackRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update track"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	track, err := qtx.GetTrack(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("track not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get track", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update track"))
+		return
+	}
+
+	params := database.UpdateTrackMetadataParams{
+		ID:        track.ID,
+		Title:     track.Title,
+		SortTitle: track.SortTitle,
+		Year:      track.Year,
+	}
+
+	var edit metadataEdit
+	edit.text("title", req.Title, &params.Title)
+	edit.text("sort_title", req.SortTitle, &params.SortTitle)
+	edit.year(req.Year, &params.Year)
+	edit.genres(req.Genres)
+	if edit.err != nil {
+		helpers.ErrorJSON(w, edit.err, http.StatusBadRequest)
+		return
+	}
+
+	params.LockedFields, err = edit.lockedFields(track.LockedFields, req.LockedFields, trackEditableFields)
+	if err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	track, err = qtx.UpdateTrackMetadata(ctx, params)
+	if err != nil {
+		app.Logger.Error("failed to update track metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update track"))
+		return
+	}
+
+	if req.Genres != nil {
+		err := app.replaceGenres(ctx, qtx, *req.Genres, "music",
+			func() error { return qtx.DeleteTrackGenres(ctx, track.ID) },
+			func(genreID int64) error {
+				return qtx.CreateTrackGenre(ctx, database.CreateTrackGenreParams{TrackID: track.ID, GenreID: genreID})
+			},
+		)
+		if err != nil {
+			app.Logger.Error("failed to replace track genres", "error", err, "id", id)
+			helpers.ErrorJSON(w, errors.New("failed to update track"))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit track metadata", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update track"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"track":         track,
+			"locked_fields": helpers.ParseLockedFields(track.LockedFields),
+		},
+	})
+}
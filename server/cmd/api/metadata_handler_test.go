@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// patchRequest calls a PATCH handler for the entity with the given id.
+func patchRequest(handler http.HandlerFunc, id, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/api/"+id, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	return rr
+}
+
+// TestPatchMovie_LocksSurviveRescan tests that edited fields and genres are kept by a
+// rescan while the other fields still come from TMDB.
+func TestPatchMovie_LocksSurviveRescan(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	path := "/movies/Alien.mkv"
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{path: 1080}}
+	app.Tmdb = newFakeTmdb(t, tmdbAlien)
+
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile failed: %v", err)
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+	id := strconv.FormatInt(movie.ID, 10)
+
+	if rr := patchRequest(app.PatchMovie, id, `{"title": " "}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an empty title, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if rr := patchRequest(app.PatchMovie, id, `{"locked_fields": ["budget"]}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a field that cannot be locked, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr := patchRequest(app.PatchMovie, id, `{"title": "Alien: Director's Cut", "sort_title": "Alien 1", "year": 2003, "genres": ["Horror"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	// The changed file is rescanned and re-matched by TMDB
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 2000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile rescan failed: %v", err)
+	}
+
+	movie, err = app.Queries.GetMovieByID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.Title != "Alien: Director's Cut" || movie.SortTitle.String != "Alien 1" || movie.Year.Int64 != 2003 {
+		t.Errorf("Expected the edited title, sort title and year to survive the rescan, got %q (%q, %d)", movie.Title, movie.SortTitle.String, movie.Year.Int64)
+	}
+
+	if movie.ReleaseDate.String != "1979-05-25" {
+		t.Errorf("Expected the release date to still come from TMDB, got %q", movie.ReleaseDate.String)
+	}
+
+	if got := helpers.ParseLockedFields(movie.LockedFields); strings.Join(got, ",") != "genres,sort_title,title,year" {
+		t.Errorf("Expected genres, sort_title, title and year to be locked, got %v", got)
+	}
+
+	genres, err := app.Queries.GetGenresByMovieID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get genres: %v", err)
+	}
+
+	if len(genres) != 1 || genres[0].Tag != "Horror" {
+		t.Errorf("Expected the edited genres to survive the rescan, got %+v", genres)
+	}
+
+	// Unlocking hands the title back to the scanner
+	if rr := patchRequest(app.PatchMovie, id, `{"locked_fields": []}`); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 3000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile rescan failed: %v", err)
+	}
+
+	movie, err = app.Queries.GetMovieByID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.Title != "Alien" {
+		t.Errorf("Expected the unlocked title to be rescanned, got %q", movie.Title)
+	}
+}
+
+// TestPatchAlbum_RenameKeepsLink tests that renamed albums and musicians are still
+// found by the scanner under the names in the tags.
+func TestPatchAlbum_RenameKeepsLink(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	album, err := app.getOrCreateAlbum(ctx, app.Queries, "Abbey Road (Remastered)", "Abbey Road", "The Beatles", "", "", false, localAlbum{})
+	if err != nil {
+		t.Fatalf("getOrCreateAlbum failed: %v", err)
+	}
+
+	musician, err := app.getOrCreateMusician(ctx, app.Queries, "Beatles", "Beatles", "")
+	if err != nil {
+		t.Fatalf("getOrCreateMusician failed: %v", err)
+	}
+
+	rr := patchRequest(app.PatchAlbum, strconv.FormatInt(album.ID, 10), `{"title": "Abbey Road", "year": 1969, "genres": ["Rock"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	rr = patchRequest(app.PatchMusician, strconv.FormatInt(musician.ID, 10), `{"name": "The Beatles"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	rescanned, err := app.getOrCreateAlbum(ctx, app.Queries, "Abbey Road (Remastered)", "Abbey Road", "The Beatles", "", "", false, localAlbum{})
+	if err != nil {
+		t.Fatalf("getOrCreateAlbum rescan failed: %v", err)
+	}
+
+	if rescanned.ID != album.ID || rescanned.Title != "Abbey Road" || rescanned.Year.Int64 != 1969 {
+		t.Errorf("Expected the renamed album %d, got %d (%q, %d)", album.ID, rescanned.ID, rescanned.Title, rescanned.Year.Int64)
+	}
+
+	rescannedMusician, err := app.getOrCreateMusician(ctx, app.Queries, "Beatles", "Beatles", "")
+	if err != nil {
+		t.Fatalf("getOrCreateMusician rescan failed: %v", err)
+	}
+
+	if rescannedMusician.ID != musician.ID || rescannedMusician.Name != "The Beatles" {
+		t.Errorf("Expected the renamed musician %d, got %d (%q)", musician.ID, rescannedMusician.ID, rescannedMusician.Name)
+	}
+
+	genres, err := app.Queries.GetGenresByAlbumIDDirect(ctx, album.ID)
+	if err != nil {
+		t.Fatalf("Failed to get album genres: %v", err)
+	}
+
+	if len(genres) != 1 || genres[0].Tag != "Rock" {
+		t.Errorf("Expected the edited album genres, got %+v", genres)
+	}
+}
+
+// TestPatchTrack_LockedGenresIgnoreTags tests that a rescan of a track with hand-edited
+// genres creates no genres from its tag.
+func TestPatchTrack_LockedGenresIgnoreTags(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	params := database.UpsertTrackParams{
+		FilePath:  "/music/track.flac",
+		FileName:  "track.flac",
+		Container: "flac",
+		Codec:     "flac",
+		Channels:  "2",
+		MimeType:  "audio/flac",
+		Size:      1,
+	}
+
+	track, err := app.saveTrack(ctx, app.Queries, params, ffprobe.FormatTags{Title: "So What", Artist: "Miles Davis", Genre: "Jazz"})
+	if err != nil {
+		t.Fatalf("saveTrack failed: %v", err)
+	}
+
+	rr := patchRequest(app.PatchTrack, strconv.FormatInt(track.ID, 10), `{"genres": ["Modal Jazz"]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	if _, err := app.saveTrack(ctx, app.Queries, params, ffprobe.FormatTags{Title: "So What", Artist: "Miles Davis", Genre: "Bebop"}); err != nil {
+		t.Fatalf("saveTrack rescan failed: %v", err)
+	}
+
+	var created int
+	if err := app.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM genres WHERE tag = 'Bebop'").Scan(&created); err != nil {
+		t.Fatalf("Failed to count genres: %v", err)
+	}
+
+	if created != 0 {
+		t.Errorf("Expected no genre from the tag of a track with locked genres, got %d", created)
+	}
+
+	var tags []string
+	rows, err := app.DB.QueryContext(ctx, "SELECT g.tag FROM genres g INNER JOIN track_genres tg ON tg.genre_id = g.id WHERE tg.track_id = ?", track.ID)
+	if err != nil {
+		t.Fatalf("Failed to get track genres: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			t.Fatalf("Failed to scan genre: %v", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if strings.Join(tags, ",") != "Modal Jazz" {
+		t.Errorf("Expected the edited track genres, got %v", tags)
+	}
+}
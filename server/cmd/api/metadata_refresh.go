@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/tmdb"
+	"time"
+)
+
+// newMetadataRefreshLimiter spaces provider requests so a refresh stays under the
+// configured number of requests per minute.
+func newMetadataRefreshLimiter(perMinute int64) *helpers.RateLimiter {
+	return helpers.NewRateLimiter(float64(max(perMinute, 1))/60, 1)
+}
+
+// metadataRefreshCounts tallies the outcome of refreshing one kind of entity.
+type metadataRefreshCounts struct {
+	updated   int
+	unchanged int
+	errors    int
+}
+
+func (c *metadataRefreshCounts) add(changed bool, err error) {
+	switch {
+	case err != nil:
+		c.errors++
+	case changed:
+		c.updated++
+	default:
+		c.unchanged++
+	}
+}
+
+// RunMetadataRefresher periodically refreshes stale metadata in the background.
+// The staleness window is re-read on every tick, so setting it to 0 pauses the job.
+func (app *Application) RunMetadataRefresher() {
+	ticker := time.NewTicker(helpers.METADATA_REFRESH_CHECK_INTERVAL)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if app.Settings().MetadataRefreshDays <= 0 {
+			continue
+		}
+
+		refreshMutex.Lock()
+		if isRefreshing {
+			refreshMutex.Unlock()
+			continue
+		}
+		isRefreshing = true
+		refreshMutex.Unlock()
+
+		app.RefreshMetadata()
+
+		refreshMutex.Lock()
+		isRefreshing = false
+		refreshMutex.Unlock()
+	}
+}
+
+// RefreshMetadata re-fetches TMDB metadata for matched movies and Spotify metadata for
+// matched musicians whose data is older than the staleness window. Metadata is only
+// fetched once when a file is first seen, so ratings, certifications, cast and
+// popularity would otherwise go stale. Locked fields are left alone.
+func (app *Application) RefreshMetadata() {
+	if app.Wait != nil {
+		app.Wait.Add(1)
+		defer app.Wait.Done()
+	}
+
+	ctx := context.Background()
+	startTime := time.Now()
+
+	settings := app.Settings()
+	cutoff := helpers.SQLiteTime(time.Now().AddDate(0, 0, -int(settings.MetadataRefreshDays)))
+	limiter := newMetadataRefreshLimiter(settings.MetadataRefreshRate)
+
+	var movies, musicians metadataRefreshCounts
+
+	if app.Tmdb != nil {
+		movies = app.refreshStaleMovies(ctx, cutoff, limiter)
+	}
+
+	if app.spotifyEnrichment() {
+		musicians = app.refreshStaleMusicians(ctx, cutoff, limiter)
+	}
+
+	app.Logger.Info(fmt.Sprintf("metadata refresh completed: movies %d updated, %d unchanged, %d errors; musicians %d updated, %d unchanged, %d errors in %s",
+		movies.updated, movies.unchanged, movies.errors,
+		musicians.updated, musicians.unchanged, musicians.errors,
+		helpers.FormatDuration(time.Since(startTime))))
+}
+
+// refreshStaleMovies refreshes every TMDB-matched movie last refreshed before the cutoff.
+func (app *Application) refreshStaleMovies(ctx context.Context, cutoff string, limiter *helpers.RateLimiter) metadataRefreshCounts {
+	var counts metadataRefreshCounts
+
+	cache := newMovieScannerCache()
+	defer cache.Clear()
+
+	// Paged by id so a movie that fails is not picked up again in the same run
+	var afterID int64
+	for {
+		movies, err := app.Queries.GetStaleMovies(ctx, database.GetStaleMoviesParams{
+			Cutoff:  cutoff,
+			AfterID: afterID,
+			Limit:   helpers.SCANNER_BATCH_SIZE,
+		})
+		if err != nil {
+			app.Logger.Error("failed to get stale movies", "error", err)
+			counts.errors++
+			return counts
+		}
+
+		if len(movies) == 0 {
+			return counts
+		}
+
+		for _, movie := range movies {
+			afterID = movie.ID
+
+			if err := limiter.Wait(ctx); err != nil {
+				counts.errors++
+				return counts
+			}
+			changed, err := app.refreshMovie(ctx, movie, cache)
+			if err != nil {
+				app.Logger.Error("failed to refresh movie metadata", "error", err, "id", movie.ID, "tmdb_id", movie.TmdbID.Int64)
+			}
+			counts.add(changed, err)
+		}
+	}
+}
+
+// refreshMovie re-fetches a movie from TMDB. The movie row is only rewritten when a
+// field changed, and only the cast, crew, genres and companies that changed are written.
+func (app *Application) refreshMovie(ctx context.Context, movie database.Movie, cache *movieScannerCache) (bool, error) {
+	// Fetched before the transaction so the scanners aren't blocked on the network
+	locale := app.movieMetadataLocale()
+	tmdbMovie := &tmdb.TmdbMovie{TmdbID: int(movie.TmdbID.Int64)}
+	if err := app.Tmdb.RefreshTmdbMovieByID(tmdbMovie, locale); err != nil {
+		return false, fmt.Errorf("tmdb lookup failed: %w", err)
+	}
+	app.fetchMovieCollection(tmdbMovie)
+
+	params := database.RefreshMovieMetadataParams{
+		ID:            movie.ID,
+		Title:         tmdbMovie.Title,
+		OriginalTitle: helpers.NullString(tmdbMovie.OriginalTitle),
+		ImdbID:        helpers.NullString(tmdbMovie.ImdbID),
+		PosterPath:    helpers.NullString(tmdbMovie.PosterPath),
+		BackdropPath:  helpers.NullString(tmdbMovie.BackdropPath),
+		ReleaseDate:   helpers.NullString(tmdbMovie.ReleaseDate),
+		Overview:      helpers.NullString(tmdbMovie.Overview),
+		TagLine:       helpers.NullString(tmdbMovie.Tagline),
+		Certification: helpers.NullString(tmdbMovie.Certification(locale.Country)),
+		CriticRating:  helpers.NullFloat64(tmdbMovie.VoteAverage),
+		Revenue:       helpers.NullFloat64(float64(tmdbMovie.Revenue)),
+		Budget:        helpers.NullFloat64(float64(tmdbMovie.Budget)),
+		RunTime:       helpers.NullInt64(int64(tmdbMovie.Runtime)),
+	}
+
+	if year := extractYearFromReleaseDate(tmdbMovie.ReleaseDate); year > 0 {
+		params.Year = helpers.NullInt64(int64(year))
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	changed := movieMetadataChanged(movie, params)
+	if changed {
+		movie, err = qtx.RefreshMovieMetadata(ctx, params)
+	} else {
+		err = qtx.TouchMovieMetadataRefreshed(ctx, movie.ID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("update movie failed: %w", err)
+	}
+
+	if err := app.processTmdbEntities(ctx, qtx, movie, tmdbMovie, cache); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return changed, nil
+}
+
+// movieMetadataChanged reports whether re-fetched TMDB data differs from the stored
+// movie in a field the refresh may write.
+func movieMetadataChanged(movie database.Movie, params database.RefreshMovieMetadataParams) bool {
+	if !helpers.IsFieldLocked(movie.LockedFields, "title") && movie.Title != params.Title {
+		return true
+	}
+	if !helpers.IsFieldLocked(movie.LockedFields, "year") && movie.Year != params.Year {
+		return true
+	}
+	if !helpers.IsFieldLocked(movie.LockedFields, "overview") && movie.Overview != params.Overview {
+		return true
+	}
+
+	return movie.OriginalTitle != params.OriginalTitle ||
+		movie.ImdbID != params.ImdbID ||
+		movie.PosterPath != params.PosterPath ||
+		movie.BackdropPath != params.BackdropPath ||
+		movie.ReleaseDate != params.ReleaseDate ||
+		movie.TagLine != params.TagLine ||
+		movie.Certification != params.Certification ||
+		movie.CriticRating != params.CriticRating ||
+		movie.Revenue != params.Revenue ||
+		movie.Budget != params.Budget ||
+		movie.RunTime != params.RunTime
+}
+
+// refreshStaleMusicians refreshes every Spotify-matched musician last refreshed before the cutoff.
+func (app *Application) refreshStaleMusicians(ctx context.Context, cutoff string, limiter *helpers.RateLimiter) metadataRefreshCounts {
+	var counts metadataRefreshCounts
+
+	var afterID int64
+	for {
+		musicians, err := app.Queries.GetStaleMusicians(ctx, database.GetStaleMusiciansParams{
+			Cutoff:  cutoff,
+			AfterID: afterID,
+			Limit:   helpers.SCANNER_BATCH_SIZE,
+		})
+		if err != nil {
+			app.Logger.Error("failed to get stale musicians", "error", err)
+			counts.errors++
+			return counts
+		}
+
+		if len(musicians) == 0 {
+			return counts
+		}
+
+		for _, musician := range musicians {
+			afterID = musician.ID
+
+			if err := limiter.Wait(ctx); err != nil {
+				counts.errors++
+				return counts
+			}
+			changed, err := app.refreshMusician(ctx, musician)
+			if err != nil {
+				app.Logger.Error("failed to refresh musician metadata", "error", err, "id", musician.ID, "spotify_id", musician.SpotifyID.String)
+			}
+			counts.add(changed, err)
+		}
+	}
+}
+
+// refreshMusician re-fetches a musician's popularity, followers, image and genres from
+// Spotify. The row is only rewritten when one of them changed.
+func (app *Application) refreshMusician(ctx context.Context, musician database.Musician) (bool, error) {
+	artist, err := app.Spotify.GetArtistByID(musician.SpotifyID.String)
+	if err != nil {
+		return false, fmt.Errorf("spotify lookup failed: %w", err)
+	}
+
+	params := database.RefreshMusicianMetadataParams{
+		ID:                musician.ID,
+		Summary:           sql.NullString{String: generateMusicianSummary(artist), Valid: true},
+		SpotifyPopularity: helpers.NullFloat64(float64(artist.Popularity)),
+		SpotifyFollowers:  helpers.NullInt64(int64(artist.Followers.Count)),
+		Thumb:             musician.Thumb,
+	}
+
+	if len(artist.Images) > 0 {
+		params.Thumb = sql.NullString{String: artist.Images[0].URL, Valid: true}
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	changed := (!helpers.IsFieldLocked(musician.LockedFields, "summary") && musician.Summary != params.Summary) ||
+		musician.SpotifyPopularity != params.SpotifyPopularity ||
+		musician.SpotifyFollowers != params.SpotifyFollowers ||
+		musician.Thumb != params.Thumb
+	if changed {
+		musician, err = qtx.RefreshMusicianMetadata(ctx, params)
+	} else {
+		err = qtx.TouchMusicianMetadataRefreshed(ctx, musician.ID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("update musician failed: %w", err)
+	}
+
+	app.processSpotifyGenres(ctx, qtx, musician, artist.Genres)
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return changed, nil
+}
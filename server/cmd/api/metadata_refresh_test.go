@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"igloo/cmd/internal/database"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// fakeSpotify serves artists from memory by Spotify id, and album searches by
+// "artist - title".
+type fakeSpotify struct {
+	artists map[string]*spotify.FullArtist
+	albums  map[string][]spotify.SimpleAlbum
+}
+
+func (f *fakeSpotify) SearchAlbums(title, artist string) ([]spotify.SimpleAlbum, error) {
+	if albums, ok := f.albums[artist+" - "+title]; ok {
+		return albums, nil
+	}
+	return nil, errors.New("album not found")
+}
+
+func (f *fakeSpotify) GetAlbumByID(id string) (*spotify.FullAlbum, error) {
+	for _, albums := range f.albums {
+		for _, album := range albums {
+			if album.ID.String() == id {
+				return &spotify.FullAlbum{SimpleAlbum: album, Popularity: 60}, nil
+			}
+		}
+	}
+	return nil, errors.New("album not found")
+}
+
+func (f *fakeSpotify) SearchArtistByName(artistName string) (*spotify.FullArtist, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeSpotify) GetArtistByID(id string) (*spotify.FullArtist, error) {
+	if artist, ok := f.artists[id]; ok {
+		return artist, nil
+	}
+	return nil, errors.New("artist not found")
+}
+
+// TestRefreshMetadata tests that stale movies and musicians are re-fetched, that
+// locked fields are kept, and that fresh entries are left alone.
+func TestRefreshMetadata(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	settings := *app.Settings()
+	settings.MetadataRefreshDays = 30
+	settings.MetadataRefreshRate = 60000
+	settings.MusicSpotifyEnrichment = true
+	app.SetSettings(&settings)
+
+	ctx := context.Background()
+
+	path := "/movies/Alien.mkv"
+	fake := newFakeTmdb(t, tmdbAlien)
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{path: 1080}}
+	app.Tmdb = fake
+
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile failed: %v", err)
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	musician, err := app.Queries.UpsertMusician(ctx, database.UpsertMusicianParams{
+		Name:              "Jerry Goldsmith",
+		SortName:          "Goldsmith, Jerry",
+		Summary:           sql.NullString{String: "Film composer.", Valid: true},
+		SpotifyPopularity: sql.NullFloat64{Float64: 40, Valid: true},
+		SpotifyID:         sql.NullString{String: "goldsmith", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create musician: %v", err)
+	}
+
+	cast, err := app.Queries.GetCastByMovieID(ctx, movie.ID)
+	if err != nil || len(cast) != 2 {
+		t.Fatalf("Expected two cast members, got %d (%v)", len(cast), err)
+	}
+
+	// Nothing is stale yet
+	app.RefreshMetadata()
+
+	movie, err = app.Queries.GetMovieByID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.MetadataRefreshedAt.Valid {
+		t.Errorf("Expected a freshly scanned movie not to be refreshed, got %q", movie.MetadataRefreshedAt.String)
+	}
+
+	// The title and summary were edited by hand, then TMDB and Spotify changed
+	if _, err := app.DB.Exec(`UPDATE movies SET title = 'Alien (1979)', locked_fields = '["title"]', created_at = '2000-01-01 00:00:00'`); err != nil {
+		t.Fatalf("Failed to age movie: %v", err)
+	}
+
+	if _, err := app.DB.Exec(`UPDATE musicians SET locked_fields = '["summary"]', created_at = '2000-01-01 00:00:00'`); err != nil {
+		t.Fatalf("Failed to age musician: %v", err)
+	}
+
+	fake.movies[0].Title = "Alien: The Director's Cut"
+	fake.movies[0].VoteAverage = 8.5
+	fake.movies[0].Tagline = "In space no one can hear you scream."
+	fake.movies[0].Credits.Cast = fake.movies[0].Credits.Cast[:1]
+
+	app.Spotify = &fakeSpotify{artists: map[string]*spotify.FullArtist{
+		"goldsmith": {
+			SimpleArtist: spotify.SimpleArtist{Name: "Jerry Goldsmith", ID: "goldsmith"},
+			Popularity:   55,
+			Followers:    spotify.Followers{Count: 250000},
+			Genres:       []string{"soundtrack"},
+		},
+	}}
+
+	app.RefreshMetadata()
+
+	movie, err = app.Queries.GetMovieByID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if !movie.MetadataRefreshedAt.Valid {
+		t.Fatal("Expected the stale movie to be refreshed")
+	}
+
+	if movie.Title != "Alien (1979)" {
+		t.Errorf("Expected the locked title to be kept, got %q", movie.Title)
+	}
+
+	if movie.CriticRating.Float64 != 8.5 || movie.TagLine.String != "In space no one can hear you scream." {
+		t.Errorf("Expected the rating and tagline to be refreshed, got %v and %q", movie.CriticRating.Float64, movie.TagLine.String)
+	}
+
+	// Only the cast member TMDB dropped is written, the other row is kept as it is
+	refreshed, err := app.Queries.GetCastByMovieID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get cast: %v", err)
+	}
+
+	if len(refreshed) != 1 || refreshed[0].ID != cast[0].ID {
+		t.Errorf("Expected cast member %d to be kept and the other removed, got %+v", cast[0].ID, refreshed)
+	}
+
+	musician, err = app.Queries.GetMusicianByID(ctx, musician.ID)
+	if err != nil {
+		t.Fatalf("Failed to get musician: %v", err)
+	}
+
+	if !musician.MetadataRefreshedAt.Valid || musician.SpotifyPopularity.Float64 != 55 || musician.SpotifyFollowers.Int64 != 250000 {
+		t.Errorf("Expected the popularity and followers to be refreshed, got %v and %d", musician.SpotifyPopularity.Float64, musician.SpotifyFollowers.Int64)
+	}
+
+	if musician.Summary.String != "Film composer." {
+		t.Errorf("Expected the locked summary to be kept, got %q", musician.Summary.String)
+	}
+
+	genres, err := app.Queries.GetGenresByMusicianID(ctx, musician.ID)
+	if err != nil {
+		t.Fatalf("Failed to get genres: %v", err)
+	}
+
+	if len(genres) != 1 {
+		t.Errorf("Expected the spotify genre to be linked, got %+v", genres)
+	}
+
+	// Both are fresh again
+	stale, err := app.Queries.GetStaleMovies(ctx, database.GetStaleMoviesParams{Cutoff: "2001-01-01 00:00:00", Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to get stale movies: %v", err)
+	}
+
+	if len(stale) != 0 {
+		t.Errorf("Expected no stale movies after the refresh, got %d", len(stale))
+	}
+}
+
+// TestRefreshMetadata_SpotifyEnrichmentDisabled tests that musicians are left alone when
+// Spotify enrichment is disabled for the music library.
+func TestRefreshMetadata_SpotifyEnrichmentDisabled(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	settings := *app.Settings()
+	settings.MetadataRefreshDays = 30
+	settings.MetadataRefreshRate = 60000
+	settings.MusicSpotifyEnrichment = false
+	app.SetSettings(&settings)
+
+	ctx := context.Background()
+
+	musician, err := app.Queries.UpsertMusician(ctx, database.UpsertMusicianParams{
+		Name:      "Jerry Goldsmith",
+		SortName:  "Goldsmith, Jerry",
+		SpotifyID: sql.NullString{String: "goldsmith", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create musician: %v", err)
+	}
+
+	if _, err := app.DB.Exec(`UPDATE musicians SET created_at = '2000-01-01 00:00:00'`); err != nil {
+		t.Fatalf("Failed to age musician: %v", err)
+	}
+
+	app.Spotify = &fakeSpotify{artists: map[string]*spotify.FullArtist{
+		"goldsmith": {SimpleArtist: spotify.SimpleArtist{Name: "Jerry Goldsmith", ID: "goldsmith"}, Popularity: 55},
+	}}
+
+	app.RefreshMetadata()
+
+	musician, err = app.Queries.GetMusicianByID(ctx, musician.ID)
+	if err != nil {
+		t.Fatalf("Failed to get musician: %v", err)
+	}
+
+	if musician.MetadataRefreshedAt.Valid || musician.SpotifyPopularity.Valid {
+		t.Errorf("Expected the musician not to be refreshed, got popularity %v", musician.SpotifyPopularity.Float64)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"database/sql"
 	"errors"
 	"igloo/cmd/internal/helpers"
 	"net/http"
@@ -28,3 +29,32 @@ func (app *Application) IsAuth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// a simple middleware to restrict a route to admin users
+func (app *Application) IsAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+		if userID == 0 {
+			helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+			return
+		}
+
+		user, err := app.Queries.GetUser(r.Context(), userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+			} else {
+				app.Logger.Error("failed to fetch user for admin check", "error", err, "user_id", userID)
+				helpers.ErrorJSON(w, errors.New(helpers.INTERNAL_SERVER_ERROR))
+			}
+			return
+		}
+
+		if !user.IsAdmin {
+			helpers.ErrorJSON(w, errors.New(helpers.FORBIDDEN_MESSAGE), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+)
+
+// columnMigration describes a column added to a table after it was first released.
+// SQLite has no ADD COLUMN IF NOT EXISTS, so columns that are already present are skipped.
+type columnMigration struct {
+	table      string
+	column     string
+	definition string
+}
+
+// columnMigrations run before the schema so its indexes on the new columns can be created.
+var columnMigrations = []columnMigration{
+	{table: "movies", column: "poster_path", definition: "TEXT"},
+	{table: "movies", column: "backdrop_path", definition: "TEXT"},
+	// streams and chapters are stored per media version
+	{table: "video_streams", column: "media_version_id", definition: "INTEGER REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE"},
+	{table: "audio_streams", column: "media_version_id", definition: "INTEGER REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE"},
+	{table: "subtitles", column: "media_version_id", definition: "INTEGER REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE"},
+	{table: "chapters", column: "media_version_id", definition: "INTEGER REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE"},
+	// scanner ignore rules
+	{table: "settings", column: "movies_ignore_patterns", definition: "TEXT"},
+	{table: "settings", column: "music_ignore_patterns", definition: "TEXT"},
+	{table: "settings", column: "movies_min_size", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "settings", column: "movies_min_duration", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "settings", column: "music_min_size", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{table: "settings", column: "music_min_duration", definition: "INTEGER NOT NULL DEFAULT 0"},
+	// manual TMDB matches
+	{table: "movies", column: "match_locked", definition: "BOOLEAN NOT NULL DEFAULT 0"},
+	// hand-edited metadata and its field locks
+	{table: "movies", column: "locked_fields", definition: "TEXT NOT NULL DEFAULT '[]'"},
+	{table: "albums", column: "scan_title", definition: "TEXT"},
+	{table: "albums", column: "locked_fields", definition: "TEXT NOT NULL DEFAULT '[]'"},
+	{table: "musicians", column: "scan_name", definition: "TEXT"},
+	{table: "musicians", column: "locked_fields", definition: "TEXT NOT NULL DEFAULT '[]'"},
+	{table: "tracks", column: "locked_fields", definition: "TEXT NOT NULL DEFAULT '[]'"},
+	// periodic metadata refresh
+	{table: "settings", column: "metadata_refresh_days", definition: "INTEGER NOT NULL DEFAULT 30"},
+	{table: "settings", column: "metadata_refresh_rate", definition: "INTEGER NOT NULL DEFAULT 30"},
+	{table: "movies", column: "metadata_refreshed_at", definition: "TEXT"},
+	{table: "musicians", column: "metadata_refreshed_at", definition: "TEXT"},
+	// CUE sheet virtual tracks
+	{table: "tracks", column: "source_path", definition: "TEXT"},
+	{table: "tracks", column: "start_offset", definition: "INTEGER"},
+	{table: "tracks", column: "end_offset", definition: "INTEGER"},
+	// compilations and albums without an album artist
+	{table: "settings", column: "various_artists_name", definition: "TEXT NOT NULL DEFAULT 'Various Artists'"},
+	{table: "albums", column: "is_compilation", definition: "BOOLEAN NOT NULL DEFAULT 0"},
+	{table: "albums", column: "directory", definition: "TEXT"},
+	// MusicBrainz identifiers, their unique indexes are created by the schema
+	{table: "tracks", column: "musicbrainz_track_id", definition: "TEXT"},
+	{table: "albums", column: "musicbrainz_album_id", definition: "TEXT"},
+	{table: "albums", column: "musicbrainz_release_group_id", definition: "TEXT"},
+	{table: "musicians", column: "musicbrainz_id", definition: "TEXT"},
+	// tracks scanned before lyrics were supported are read again for them
+	{table: "tracks", column: "lyrics_scanned_at", definition: "TEXT"},
+	// audiobook library
+	{table: "settings", column: "audiobooks_dir", definition: "TEXT"},
+	{table: "settings", column: "audiobooks_ignore_patterns", definition: "TEXT"},
+	// podcasts
+	{table: "settings", column: "podcasts_dir", definition: "TEXT"},
+	{table: "settings", column: "podcast_poll_minutes", definition: "INTEGER NOT NULL DEFAULT 60"},
+	// movie metadata provider chain
+	{table: "settings", column: "movies_metadata_providers", definition: "TEXT NOT NULL DEFAULT 'nfo,tmdb,embedded'"},
+	// metadata language and certification country
+	{table: "settings", column: "metadata_language", definition: "TEXT NOT NULL DEFAULT 'en-US'"},
+	{table: "settings", column: "metadata_fallback_language", definition: "TEXT NOT NULL DEFAULT 'en-US'"},
+	{table: "settings", column: "certification_country", definition: "TEXT NOT NULL DEFAULT 'US'"},
+	{table: "settings", column: "movies_metadata_language", definition: "TEXT"},
+	{table: "settings", column: "movies_metadata_fallback_language", definition: "TEXT"},
+	{table: "settings", column: "movies_certification_country", definition: "TEXT"},
+	{table: "movies", column: "original_title", definition: "TEXT"},
+	// per-library Spotify enrichment
+	{table: "settings", column: "music_spotify_enrichment", definition: "BOOLEAN NOT NULL DEFAULT 1"},
+	// Spotify album match confidence
+	{table: "albums", column: "spotify_match_score", definition: "REAL"},
+	{table: "albums", column: "spotify_match_locked", definition: "BOOLEAN NOT NULL DEFAULT 0"},
+	// hand-edited movie sort titles
+	{table: "movies", column: "sort_title", definition: "TEXT"},
+	// movie collections, NULL until the movies scanned before them are linked to theirs
+	{table: "settings", column: "collections_backfilled_at", definition: "TEXT"},
+}
+
+// migrateColumns adds every missing column in columnMigrations. Tables that don't
+// exist yet are skipped, the schema creates them with all their columns.
+func (app *Application) migrateColumns(ctx context.Context) error {
+	for _, m := range columnMigrations {
+		// pragma_table_info has no rows for a missing table, so both are checked
+		var tables, columns int
+		err := app.DB.QueryRowContext(ctx, `SELECT
+			(SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?),
+			(SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?)`,
+			m.table, m.table, m.column).Scan(&tables, &columns)
+		if err != nil {
+			return fmt.Errorf("migrate %s.%s: %w", m.table, m.column, err)
+		}
+
+		if tables == 0 || columns > 0 {
+			continue
+		}
+
+		_, err = app.DB.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition))
+		if err != nil {
+			return fmt.Errorf("migrate %s.%s: %w", m.table, m.column, err)
+		}
+
+		app.Logger.Info("added column to table", "table", m.table, "column", m.column)
+	}
+
+	return nil
+}
+
+// tableMigration describes a table whose definition changed in a way SQLite's
+// ALTER TABLE can't express (e.g. a new CHECK constraint value).
+// marker is a fragment of the new CREATE TABLE statement; tables whose stored
+// definition already contains it are up to date. Constraints moved out of the
+// table set index instead, the index replacing them: tables that already have
+// it are up to date.
+type tableMigration struct {
+	table  string
+	marker string
+	index  string
+}
+
+// tableMigrations are applied in order by migrateTables.
+var tableMigrations = []tableMigration{
+	// tracks.container gained ogg, opus, wav, aiff, wv and ape
+	{table: "tracks", marker: "'opus'"},
+	// movies.container gained m4v, mov, ts, m2ts, wmv and mpg (see helpers.VideoContainers)
+	{table: "movies", marker: "'m2ts'"},
+	// musicians.name and albums (title, musician) are only unique without a MusicBrainz id
+	{table: "musicians", index: "idx_musician_name_unmatched"},
+	{table: "albums", index: "idx_album_title_musician_unmatched"},
+	// scan_errors.library gained audiobooks
+	{table: "scan_errors", marker: "'audiobooks'"},
+}
+
+// migrateTables rebuilds every table in tableMigrations that is out of date.
+// It runs before the schema, which recreates the indexes dropped with the old
+// tables and would otherwise create the indexes marking them up to date.
+func (app *Application) migrateTables(ctx context.Context) error {
+	for _, m := range tableMigrations {
+		rebuilt, err := app.rebuildTable(ctx, m)
+		if err != nil {
+			return fmt.Errorf("migrate %s: %w", m.table, err)
+		}
+
+		if rebuilt {
+			app.Logger.Info("migrated table to new schema", "table", m.table)
+		}
+	}
+
+	return nil
+}
+
+// rebuildTable recreates a table from its CREATE TABLE statement in the embedded
+// schema, following SQLite's documented procedure: create the new table, copy the
+// rows, drop the old table and rename the new one. Columns are copied by name so
+// columns added later with ALTER TABLE don't need to be in the same position.
+//
+// Foreign keys are switched off on a dedicated connection while the table is
+// swapped, otherwise dropping the old table would cascade to every row that
+// references it.
+func (app *Application) rebuildTable(ctx context.Context, m tableMigration) (bool, error) {
+	table := m.table
+
+	var stored string
+	err := app.DB.QueryRowContext(ctx, "SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&stored)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if m.index != "" {
+		var indexes int
+		err := app.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ? AND tbl_name = ?", m.index, table).Scan(&indexes)
+		if err != nil {
+			return false, err
+		}
+		if indexes > 0 {
+			return false, nil
+		}
+	} else if strings.Contains(stored, m.marker) {
+		return false, nil
+	}
+
+	createSQL, err := schemaCreateTable(table)
+	if err != nil {
+		return false, err
+	}
+
+	tmp := table + "_new"
+	createSQL = strings.Replace(createSQL, table, tmp, 1)
+
+	conn, err := app.DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var foreignKeys bool
+	err = conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys)
+	if err != nil {
+		return false, err
+	}
+
+	// PRAGMA foreign_keys is a no-op inside a transaction, so it's toggled around it
+	if foreignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return false, err
+		}
+		defer conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, createSQL); err != nil {
+		return false, fmt.Errorf("create %s: %w", tmp, err)
+	}
+
+	oldColumns, err := tableColumns(ctx, tx, table)
+	if err != nil {
+		return false, err
+	}
+
+	newColumns, err := tableColumns(ctx, tx, tmp)
+	if err != nil {
+		return false, err
+	}
+
+	existing := make(map[string]bool, len(oldColumns))
+	for _, column := range oldColumns {
+		existing[column] = true
+	}
+
+	// Columns that only exist in the new definition take their defaults
+	columns := make([]string, 0, len(newColumns))
+	for _, column := range newColumns {
+		if existing[column] {
+			columns = append(columns, column)
+		}
+	}
+
+	columnList := strings.Join(columns, ", ")
+	statements := []string{
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", tmp, columnList, columnList, table),
+		fmt.Sprintf("DROP TABLE %s", table),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmp, table),
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return false, fmt.Errorf("%s: %w", statement, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// tableColumns returns the column names of a table in declaration order.
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+
+	return columns, rows.Err()
+}
+
+// schemaCreateTable extracts a table's CREATE TABLE statement from the embedded schema.
+func schemaCreateTable(table string) (string, error) {
+	re := regexp.MustCompile(`(?s)CREATE\s+TABLE\s+IF\s+NOT\s+EXISTS\s+` + regexp.QuoteMeta(table) + `\s*\(.*?\);\s*\n`)
+
+	statement := re.FindString(SQL)
+	if statement == "" {
+		return "", fmt.Errorf("table %s not found in schema", table)
+	}
+
+	return statement, nil
+}
+
+// mediaVersionTables hold rows that belong to a single media version of a movie.
+var mediaVersionTables = []string{"video_streams", "audio_streams", "subtitles", "chapters"}
+
+// migrateMediaVersions moves libraries scanned before media versions existed to the
+// new layout: every movie file gets a media_versions row, streams and chapters are
+// linked to the version of their movie, and movies sharing a TMDB id are merged into
+// the oldest one. It only runs while a movie file or a stream has no version yet,
+// and each step only touches rows that haven't been migrated, so an interrupted
+// migration picks up where it stopped.
+func (app *Application) migrateMediaVersions(ctx context.Context) error {
+	pending := []string{"SELECT 1 FROM movies WHERE file_path NOT IN (SELECT file_path FROM media_versions)"}
+	for _, table := range mediaVersionTables {
+		pending = append(pending, fmt.Sprintf("SELECT 1 FROM %s WHERE media_version_id IS NULL", table))
+	}
+
+	var needed bool
+	err := app.DB.QueryRowContext(ctx, "SELECT EXISTS ("+strings.Join(pending, " UNION ALL ")+")").Scan(&needed)
+	if err != nil {
+		return fmt.Errorf("check media versions: %w", err)
+	}
+	if !needed {
+		return nil
+	}
+
+	statements := []string{
+		`INSERT INTO media_versions (movie_id, file_path, file_name, size, container, mime_type)
+		SELECT id, file_path, file_name, size, container, mime_type FROM movies
+		WHERE file_path NOT IN (SELECT file_path FROM media_versions)`,
+	}
+
+	for _, table := range mediaVersionTables {
+		statements = append(statements, fmt.Sprintf(`UPDATE %[1]s SET media_version_id = (
+			SELECT mv.id FROM media_versions mv INNER JOIN movies m ON m.file_path = mv.file_path
+			WHERE m.id = %[1]s.movie_id
+		) WHERE media_version_id IS NULL`, table))
+	}
+
+	// Duplicates of a movie are the rows whose tmdb_id is shared with a smaller id
+	const duplicates = `SELECT m.id FROM movies m WHERE m.tmdb_id IS NOT NULL
+		AND m.id > (SELECT MIN(o.id) FROM movies o WHERE o.tmdb_id = m.tmdb_id)`
+	const original = `(SELECT MIN(o.id) FROM movies o WHERE o.tmdb_id = (SELECT d.tmdb_id FROM movies d WHERE d.id = %s.movie_id))`
+
+	for _, table := range append([]string{"media_versions"}, mediaVersionTables...) {
+		statements = append(statements, fmt.Sprintf("UPDATE %s SET movie_id = %s WHERE movie_id IN (%s)",
+			table, fmt.Sprintf(original, table), duplicates))
+	}
+
+	// Cast, crew, genres and other links of the duplicates go with them
+	statements = append(statements, fmt.Sprintf("DELETE FROM movies WHERE id IN (%s)", duplicates))
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrate media versions: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// migrateGenreAliases gives every genre without an alias its normalized key, so scans
+// resolve spelling variants to it. Genres whose key already belongs to an older genre,
+// like "hip-hop" created next to "Hip Hop" before aliases existed, are merged into it.
+// Only genres without aliases are touched, so it is a no-op once they all have one.
+func (app *Application) migrateGenreAliases(ctx context.Context) error {
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	genres, err := qtx.GetUnaliasedGenres(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate genre aliases: %w", err)
+	}
+
+	merged := 0
+	for _, genre := range genres {
+		key := helpers.NormalizeKey(genre.Tag)
+		if key == "" {
+			continue
+		}
+
+		target, err := qtx.GetGenreByAlias(ctx, database.GetGenreByAliasParams{
+			Alias:     key,
+			GenreType: genre.GenreType,
+		})
+		if err == nil {
+			if err := app.mergeGenre(ctx, qtx, genre, target); err != nil {
+				return fmt.Errorf("migrate genre %q: %w", genre.Tag, err)
+			}
+			merged++
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("migrate genre %q: %w", genre.Tag, err)
+		}
+
+		err = qtx.UpsertGenreAlias(ctx, database.UpsertGenreAliasParams{
+			Alias:     key,
+			GenreType: genre.GenreType,
+			GenreID:   genre.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("migrate genre %q: %w", genre.Tag, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if merged > 0 {
+		app.Logger.Info("merged genres differing only in spelling", "merged", merged)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetMovieCollections returns a paginated list of the collections the library has movies
+// of, sorted by name, with how many of their movies it has and how many it misses.
+// Supports query parameters: limit (default 50, max 100), offset (default 0)
+func (app *Application) GetMovieCollections(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parseStatsPaginationParams(r, 50, 100)
+
+	total, err := app.Queries.GetCollectionsCount(r.Context())
+	if err != nil {
+		app.Logger.Error("failed to get collections count", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch collections count"))
+		return
+	}
+
+	rows, err := app.Queries.GetCollections(r.Context(), database.GetCollectionsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get collections", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch collections"))
+		return
+	}
+
+	collections := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		collections = append(collections, map[string]any{
+			"id":            row.ID,
+			"tmdb_id":       row.TmdbID,
+			"name":          row.Name,
+			"poster":        tmdbImageOrNil(row.PosterPath, helpers.TMDB_POSTER_SIZE),
+			"movie_count":   row.MovieCount,
+			"missing_count": row.MissingCount,
+		})
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"collections": collections,
+			"total":       total,
+			"offset":      offset,
+			"limit":       limit,
+			"has_more":    offset+limit < total,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetMovieCollection returns a collection with its movies in the library and the ones
+// it misses, both in release order.
+func (app *Application) GetMovieCollection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid collection id"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	collection, err := app.Queries.GetCollectionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("collection not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get collection", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch collection from server"))
+		return
+	}
+
+	movieRows, err := app.Queries.GetCollectionMovies(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get collection movies", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch collection movies"))
+		return
+	}
+
+	missingRows, err := app.Queries.GetMissingCollectionParts(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get missing collection parts", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch collection movies"))
+		return
+	}
+
+	movies := make([]map[string]any, 0, len(movieRows))
+	for _, row := range movieRows {
+		year := any(nil)
+		if row.Year.Valid {
+			year = row.Year.Int64
+		}
+
+		movies = append(movies, map[string]any{
+			"id":           row.ID,
+			"tmdb_id":      row.TmdbID.Int64,
+			"title":        row.Title,
+			"poster":       tmdbImageOrNil(row.PosterPath, helpers.TMDB_POSTER_SIZE),
+			"year":         year,
+			"release_date": row.ReleaseDate.String,
+		})
+	}
+
+	missing := make([]map[string]any, 0, len(missingRows))
+	for _, row := range missingRows {
+		year := any(nil)
+		if y := extractYearFromReleaseDate(row.ReleaseDate.String); y > 0 {
+			year = y
+		}
+
+		missing = append(missing, map[string]any{
+			"tmdb_id":      row.TmdbID,
+			"title":        row.Title,
+			"poster":       tmdbImageOrNil(row.PosterPath, helpers.TMDB_POSTER_SIZE),
+			"year":         year,
+			"release_date": row.ReleaseDate.String,
+			"overview":     row.Overview.String,
+		})
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"collection": map[string]any{
+				"id":       collection.ID,
+				"tmdb_id":  collection.TmdbID,
+				"name":     collection.Name,
+				"overview": collection.Overview.String,
+				"poster":   tmdbImageOrNil(collection.PosterPath, helpers.TMDB_POSTER_SIZE),
+				"backdrop": tmdbImageOrNil(collection.BackdropPath, helpers.TMDB_IMAGE_SIZE),
+			},
+			"movies":  movies,
+			"missing": missing,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// tmdbImageOrNil returns the URL of a stored TMDB image path, or nil when there is none.
+func tmdbImageOrNil(path sql.NullString, size string) any {
+	if !path.Valid || path.String == "" {
+		return nil
+	}
+	return helpers.TmdbImageURL(path.String, size)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"igloo/cmd/internal/tmdb"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const tmdbAlienCollection = `{"belongs_to_collection": {"id": 8091, "name": "Alien Collection", "poster_path": "/alien-collection.jpg"}}`
+
+// withCollection adds the Alien collection reference to a TMDB movie document.
+func withCollection(t *testing.T, document string) string {
+	t.Helper()
+
+	var movie map[string]any
+	if err := json.Unmarshal([]byte(document), &movie); err != nil {
+		t.Fatalf("Failed to parse tmdb movie: %v", err)
+	}
+	if err := json.Unmarshal([]byte(tmdbAlienCollection), &movie); err != nil {
+		t.Fatalf("Failed to parse collection: %v", err)
+	}
+
+	data, err := json.Marshal(movie)
+	if err != nil {
+		t.Fatalf("Failed to encode tmdb movie: %v", err)
+	}
+	return string(data)
+}
+
+// TestMovieCollections tests that scanned movies are grouped in their TMDB collection,
+// listed in release order, and that the parts the library lacks are reported as missing.
+func TestMovieCollections(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	paths := []string{"/movies/Aliens (1986).mkv", "/movies/Alien (1979).mkv"}
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{paths[0]: 1080, paths[1]: 1080}}
+
+	fake := newFakeTmdb(t, withCollection(t, tmdbAliens), withCollection(t, tmdbAlien))
+	fake.collections = []tmdb.TmdbCollection{{
+		ID:         8091,
+		Name:       "Alien Collection",
+		Overview:   "A space horror saga.",
+		PosterPath: "/alien-collection.jpg",
+		Parts: []tmdb.TmdbMovie{
+			{TmdbID: 679, Title: "Aliens", ReleaseDate: "1986-07-18"},
+			{TmdbID: 8077, Title: "Alien³", ReleaseDate: "1992-05-22"},
+			{TmdbID: 348, Title: "Alien", ReleaseDate: "1979-05-25"},
+		},
+	}}
+	app.Tmdb = fake
+
+	cache := newMovieScannerCache()
+	for _, path := range paths {
+		if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, cache); err != nil {
+			t.Fatalf("processMovieFile(%q) failed: %v", path, err)
+		}
+	}
+
+	// The overview and parts are fetched once the files are scanned
+	if err := app.updateMovieCollections(ctx, cache); err != nil {
+		t.Fatalf("updateMovieCollections failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	app.GetMovieCollections(rr, httptest.NewRequest(http.MethodGet, "/api/movies/collections", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var list struct {
+		Data struct {
+			Collections []struct {
+				ID           int64  `json:"id"`
+				Name         string `json:"name"`
+				MovieCount   int64  `json:"movie_count"`
+				MissingCount int64  `json:"missing_count"`
+			} `json:"collections"`
+			Total int64 `json:"total"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if list.Data.Total != 1 || len(list.Data.Collections) != 1 {
+		t.Fatalf("Expected a single collection, got %+v", list.Data)
+	}
+	collection := list.Data.Collections[0]
+	if collection.Name != "Alien Collection" || collection.MovieCount != 2 || collection.MissingCount != 1 {
+		t.Errorf("Unexpected collection: %+v", collection)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/movies/collections/1", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	rr = httptest.NewRecorder()
+	app.GetMovieCollection(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var details struct {
+		Data struct {
+			Collection struct {
+				Overview string `json:"overview"`
+				Poster   string `json:"poster"`
+			} `json:"collection"`
+			Movies []struct {
+				Title string `json:"title"`
+			} `json:"movies"`
+			Missing []struct {
+				TmdbID int64  `json:"tmdb_id"`
+				Title  string `json:"title"`
+				Year   int    `json:"year"`
+			} `json:"missing"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &details); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if details.Data.Collection.Overview != "A space horror saga." || details.Data.Collection.Poster == "" {
+		t.Errorf("Expected the collection details from TMDB, got %+v", details.Data.Collection)
+	}
+	if len(details.Data.Movies) != 2 || details.Data.Movies[0].Title != "Alien" || details.Data.Movies[1].Title != "Aliens" {
+		t.Errorf("Expected the movies in release order, got %+v", details.Data.Movies)
+	}
+	if len(details.Data.Missing) != 1 || details.Data.Missing[0].TmdbID != 8077 || details.Data.Missing[0].Year != 1992 {
+		t.Errorf("Expected Alien³ to be missing, got %+v", details.Data.Missing)
+	}
+
+	// Unknown collections are not found
+	rctx.URLParams = chi.RouteParams{}
+	rctx.URLParams.Add("id", "999")
+	rr = httptest.NewRecorder()
+	app.GetMovieCollection(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown collection, got %d", rr.Code)
+	}
+}
+
+// TestBackfillMovieCollections tests that movies scanned before collections were
+// supported are linked to theirs once, along with the collection's overview and parts.
+func TestBackfillMovieCollections(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	if err := app.InitSettings(ctx); err != nil {
+		t.Fatalf("InitSettings failed: %v", err)
+	}
+
+	path := "/movies/Alien (1979).mkv"
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{path: 1080}}
+	app.Tmdb = newFakeTmdb(t, tmdbAlien)
+
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile failed: %v", err)
+	}
+
+	// Settings migrated from before collections have no backfill date
+	if _, err := app.DB.ExecContext(ctx, "UPDATE settings SET collections_backfilled_at = NULL"); err != nil {
+		t.Fatalf("Failed to reset the backfill date: %v", err)
+	}
+	settings, err := app.Queries.GetSettings(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get settings: %v", err)
+	}
+	app.SetSettings(&settings)
+
+	fake := newFakeTmdb(t, withCollection(t, tmdbAlien))
+	fake.collections = []tmdb.TmdbCollection{{
+		ID:       8091,
+		Name:     "Alien Collection",
+		Overview: "A space horror saga.",
+		Parts:    []tmdb.TmdbMovie{{TmdbID: 348, Title: "Alien"}, {TmdbID: 679, Title: "Aliens"}},
+	}}
+	app.Tmdb = fake
+
+	cache := newMovieScannerCache()
+	if err := app.backfillMovieCollections(ctx, cache); err != nil {
+		t.Fatalf("backfillMovieCollections failed: %v", err)
+	}
+	if err := app.updateMovieCollections(ctx, cache); err != nil {
+		t.Fatalf("updateMovieCollections failed: %v", err)
+	}
+
+	if !app.Settings().CollectionsBackfilledAt.Valid {
+		t.Error("Expected the backfill date to be saved")
+	}
+
+	collection, err := app.Queries.GetCollectionByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("Expected the collection to be stored: %v", err)
+	}
+	if collection.TmdbID != 8091 || collection.Overview.String != "A space horror saga." {
+		t.Errorf("Unexpected collection: %+v", collection)
+	}
+
+	movies, err := app.Queries.GetCollectionMovies(ctx, collection.ID)
+	if err != nil {
+		t.Fatalf("Failed to get collection movies: %v", err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Alien" {
+		t.Errorf("Expected Alien to be linked to its collection, got %+v", movies)
+	}
+
+	missing, err := app.Queries.GetMissingCollectionParts(ctx, collection.ID)
+	if err != nil {
+		t.Fatalf("Failed to get missing parts: %v", err)
+	}
+	if len(missing) != 1 || missing[0].TmdbID != 679 {
+		t.Errorf("Expected Aliens to be missing, got %+v", missing)
+	}
+}
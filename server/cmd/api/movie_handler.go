@@ -1,15 +1,16 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"igloo/cmd/internal/database"
 	"igloo/cmd/internal/helpers"
-	"mime"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -60,7 +61,146 @@ func (app *Application) GetLatestMovies(w http.ResponseWriter, r *http.Request)
 	helpers.WriteJSON(w, http.StatusOK, res)
 }
 
-// GetMovieDetails returns a movie with all related data (cast, crew, genres, production companies, extra videos).
+// errMediaVersionNotFound is returned by selectMediaVersion when ?version= names a
+// version that doesn't belong to the movie.
+var errMediaVersionNotFound = errors.New("media version not found")
+
+// mediaVersionResponse is a media version with its streams, as returned by GetMovieDetails.
+type mediaVersionResponse struct {
+	database.GetMediaVersionsByMovieIDRow
+	HDR          bool                   `json:"hdr"`
+	VideoStreams []database.VideoStream `json:"video_streams"`
+	AudioStreams []database.AudioStream `json:"audio_streams"`
+	Subtitles    []database.Subtitle    `json:"subtitles"`
+}
+
+// mediaVersionPreferences describe what a client can play, used to auto-pick a media version.
+// Parsed from the query string: ?codecs=h264,hevc&max_height=1080&hdr=false
+type mediaVersionPreferences struct {
+	codecs    map[string]bool // video codecs the client decodes, empty means any
+	maxHeight int64           // tallest video the client wants, 0 means any
+	noHDR     bool            // the client can't display HDR
+}
+
+func parseMediaVersionPreferences(query url.Values) mediaVersionPreferences {
+	prefs := mediaVersionPreferences{}
+
+	if c := query.Get("codecs"); c != "" {
+		prefs.codecs = make(map[string]bool)
+		for _, codec := range strings.Split(c, ",") {
+			if codec = strings.ToLower(strings.TrimSpace(codec)); codec != "" {
+				prefs.codecs[codec] = true
+			}
+		}
+	}
+
+	if h := query.Get("max_height"); h != "" {
+		if height, err := strconv.ParseInt(h, 10, 64); err == nil && height > 0 {
+			prefs.maxHeight = height
+		}
+	}
+
+	if hdr, err := strconv.ParseBool(query.Get("hdr")); err == nil {
+		prefs.noHDR = !hdr
+	}
+
+	return prefs
+}
+
+// isHDRTransfer reports whether a video stream's color transfer is an HDR one (PQ or HLG).
+func isHDRTransfer(colorTransfer sql.NullString) bool {
+	return colorTransfer.Valid && (colorTransfer.String == "smpte2084" || colorTransfer.String == "arib-std-b67")
+}
+
+// playable reports whether a version satisfies the client's preferences.
+func (p mediaVersionPreferences) playable(v database.GetMediaVersionsByMovieIDRow) bool {
+	if len(p.codecs) > 0 && !p.codecs[strings.ToLower(v.VideoCodec.String)] {
+		return false
+	}
+
+	if p.maxHeight > 0 && v.Height.Int64 > p.maxHeight {
+		return false
+	}
+
+	if p.noHDR && isHDRTransfer(v.ColorTransfer) {
+		return false
+	}
+
+	return true
+}
+
+// selectMediaVersion picks the version to play: the one named by ?version=, otherwise
+// the highest-resolution version the client can play according to its preferences.
+// When none matches, the lowest-resolution version is returned as the likeliest to play.
+// versions must be ordered by height, tallest first (as GetMediaVersionsByMovieID does).
+// Returns false when the movie has no versions.
+func selectMediaVersion(versions []database.GetMediaVersionsByMovieIDRow, query url.Values) (database.GetMediaVersionsByMovieIDRow, bool, error) {
+	if len(versions) == 0 {
+		return database.GetMediaVersionsByMovieIDRow{}, false, nil
+	}
+
+	if v := query.Get("version"); v != "" {
+		versionID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return database.GetMediaVersionsByMovieIDRow{}, false, errMediaVersionNotFound
+		}
+
+		for _, version := range versions {
+			if version.ID == versionID {
+				return version, true, nil
+			}
+		}
+
+		return database.GetMediaVersionsByMovieIDRow{}, false, errMediaVersionNotFound
+	}
+
+	prefs := parseMediaVersionPreferences(query)
+	for _, version := range versions {
+		if prefs.playable(version) {
+			return version, true, nil
+		}
+	}
+
+	return versions[len(versions)-1], true, nil
+}
+
+// getMediaVersions returns a movie's versions with their streams.
+func (app *Application) getMediaVersions(ctx context.Context, qtx *database.Queries, versions []database.GetMediaVersionsByMovieIDRow) ([]mediaVersionResponse, error) {
+	response := make([]mediaVersionResponse, 0, len(versions))
+
+	for _, version := range versions {
+		versionID := helpers.NullInt64(version.ID)
+
+		videoStreams, err := qtx.GetVideoStreamsByMediaVersionID(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+
+		audioStreams, err := qtx.GetAudioStreamsByMediaVersionID(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+
+		subtitles, err := qtx.GetSubtitlesByMediaVersionID(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, mediaVersionResponse{
+			GetMediaVersionsByMovieIDRow: version,
+			HDR:                          isHDRTransfer(version.ColorTransfer),
+			VideoStreams:                 videoStreams,
+			AudioStreams:                 audioStreams,
+			Subtitles:                    subtitles,
+		})
+	}
+
+	return response, nil
+}
+
+// GetMovieDetails returns a movie with all related data (cast, crew, genres, production companies,
+// extra videos, local extras and media versions). The version a client should play is returned as
+// selected_version_id, see selectMediaVersion for the ?version= and capability parameters.
 // Uses a read-only transaction so all data is from a single consistent snapshot.
 func (app *Application) GetMovieDetails(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
@@ -128,6 +268,38 @@ func (app *Application) GetMovieDetails(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	versions, err := qtx.GetMediaVersionsByMovieID(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get media versions for movie", "error", err, "movie_id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch movie versions from server"))
+		return
+	}
+
+	selected, ok, err := selectMediaVersion(versions, r.URL.Query())
+	if err != nil {
+		helpers.ErrorJSON(w, err, http.StatusNotFound)
+		return
+	}
+
+	selectedVersionID := any(nil)
+	if ok {
+		selectedVersionID = selected.ID
+	}
+
+	versionsData, err := app.getMediaVersions(ctx, qtx, versions)
+	if err != nil {
+		app.Logger.Error("failed to get media version streams for movie", "error", err, "movie_id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch movie versions from server"))
+		return
+	}
+
+	localExtras, err := qtx.GetLocalExtrasByMovieID(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get local extras for movie", "error", err, "movie_id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch movie extras from server"))
+		return
+	}
+
 	// Build movie response with poster as full URL
 	movieData := movieDetailsMovieToMap(movie)
 
@@ -220,6 +392,9 @@ func (app *Application) GetMovieDetails(w http.ResponseWriter, r *http.Request)
 			"genres":               genresData,
 			"production_companies": companiesData,
 			"extra_videos":         extraVideosData,
+			"local_extras":         localExtras,
+			"versions":             versionsData,
+			"selected_version_id":  selectedVersionID,
 		},
 	}
 	helpers.WriteJSON(w, http.StatusOK, res)
@@ -283,10 +458,20 @@ func movieDetailsMovieToMap(m database.Movie) map[string]any {
 	if m.Language.Valid {
 		language = m.Language.String
 	}
+	originalTitle := any(nil)
+	if m.OriginalTitle.Valid {
+		originalTitle = m.OriginalTitle.String
+	}
+	sortTitle := any(nil)
+	if m.SortTitle.Valid {
+		sortTitle = m.SortTitle.String
+	}
 
 	return map[string]any{
 		"id":              m.ID,
 		"title":           m.Title,
+		"original_title":  originalTitle,
+		"sort_title":      sortTitle,
 		"file_path":       m.FilePath,
 		"file_name":       m.FileName,
 		"size":            m.Size,
@@ -306,12 +491,15 @@ func movieDetailsMovieToMap(m database.Movie) map[string]any {
 		"revenue":         revenue,
 		"budget":          budget,
 		"run_time":        runTime,
+		"match_locked":    m.MatchLocked,
+		"locked_fields":   helpers.ParseLockedFields(m.LockedFields),
 		"created_at":      m.CreatedAt,
 		"updated_at":      m.UpdatedAt,
 	}
 }
 
-// StreamMovie streams the movie file for playback (direct stream, no transcoding).
+// StreamMovie streams a movie file for playback (direct stream, no transcoding).
+// The version is chosen like in GetMovieDetails: ?version= or the client's capabilities.
 func (app *Application) StreamMovie(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idParam, 10, 64)
@@ -320,48 +508,82 @@ func (app *Application) StreamMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	movie, err := app.Queries.GetMovieByID(r.Context(), id)
+	versions, err := app.Queries.GetMediaVersionsByMovieID(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			helpers.ErrorJSON(w, errors.New("movie not found"), http.StatusNotFound)
-			return
-		}
-
-		app.Logger.Error("failed to get movie for streaming", "error", err, "id", id)
+		app.Logger.Error("failed to get movie versions for streaming", "error", err, "id", id)
 		helpers.ErrorJSON(w, errors.New("failed to fetch movie from server"))
 		return
 	}
 
-	file, err := os.Open(movie.FilePath)
+	version, ok, err := selectMediaVersion(versions, r.URL.Query())
+	if err != nil {
+		helpers.ErrorJSON(w, err, http.StatusNotFound)
+		return
+	}
+
+	if !ok {
+		helpers.ErrorJSON(w, errors.New("movie not found"), http.StatusNotFound)
+		return
+	}
+
+	app.serveVideoFile(w, r, version.FilePath, version.FileName, version.Container)
+}
+
+// StreamLocalExtra streams a local extra (trailer, featurette...) of a movie, like StreamMovie.
+func (app *Application) StreamLocalExtra(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid movie id"), http.StatusBadRequest)
+		return
+	}
+
+	extraID, err := strconv.ParseInt(chi.URLParam(r, "extraID"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid extra id"), http.StatusBadRequest)
+		return
+	}
+
+	extra, err := app.Queries.GetLocalExtraByID(r.Context(), extraID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		app.Logger.Error("failed to get extra for streaming", "error", err, "id", extraID)
+		helpers.ErrorJSON(w, errors.New("failed to fetch extra from server"))
+		return
+	}
+
+	if err != nil || extra.MovieID != id {
+		helpers.ErrorJSON(w, errors.New("extra not found"), http.StatusNotFound)
+		return
+	}
+
+	app.serveVideoFile(w, r, extra.FilePath, extra.FileName, extra.Container)
+}
+
+// serveVideoFile serves a video file from disk with range support.
+func (app *Application) serveVideoFile(w http.ResponseWriter, r *http.Request, filePath, fileName, container string) {
+	file, err := os.Open(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			app.Logger.Error("movie file not found on disk", "path", movie.FilePath, "id", id)
-			helpers.ErrorJSON(w, errors.New("movie file not found"), http.StatusNotFound)
+			app.Logger.Error("video file not found on disk", "path", filePath)
+			helpers.ErrorJSON(w, errors.New("video file not found"), http.StatusNotFound)
 			return
 		}
 
-		app.Logger.Error("failed to open movie file", "error", err, "path", movie.FilePath)
-		helpers.ErrorJSON(w, errors.New("failed to open movie file"))
+		app.Logger.Error("failed to open video file", "error", err, "path", filePath)
+		helpers.ErrorJSON(w, errors.New("failed to open video file"))
 		return
 	}
 	defer file.Close()
 
 	stat, err := file.Stat()
 	if err != nil {
-		app.Logger.Error("failed to stat movie file", "error", err, "path", movie.FilePath)
-		helpers.ErrorJSON(w, errors.New("failed to read movie file"))
+		app.Logger.Error("failed to stat video file", "error", err, "path", filePath)
+		helpers.ErrorJSON(w, errors.New("failed to read video file"))
 		return
 	}
 
-	contentType := movie.MimeType
-	if contentType == "" {
-		ext := filepath.Ext(movie.FileName)
-		contentType = mime.TypeByExtension(ext)
-		if contentType == "" {
-			contentType = "application/octet-stream"
-		}
-	}
-	w.Header().Set("Content-Type", contentType)
+	// Derive the MIME type from the container so rows scanned before the
+	// container list was unified don't keep a stale or empty type
+	w.Header().Set("Content-Type", helpers.VideoMimeType(container))
 
-	http.ServeContent(w, r, movie.FileName, stat.ModTime(), file)
+	http.ServeContent(w, r, fileName, stat.ModTime(), file)
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"testing"
+
+	"igloo/cmd/internal/database"
+)
+
+func TestSelectMediaVersion(t *testing.T) {
+	// Ordered by height, tallest first, as GetMediaVersionsByMovieID returns them
+	versions := []database.GetMediaVersionsByMovieIDRow{
+		{
+			ID:            1,
+			VideoCodec:    sql.NullString{String: "hevc", Valid: true},
+			Height:        sql.NullInt64{Int64: 2160, Valid: true},
+			ColorTransfer: sql.NullString{String: "smpte2084", Valid: true},
+		},
+		{
+			ID:         2,
+			VideoCodec: sql.NullString{String: "hevc", Valid: true},
+			Height:     sql.NullInt64{Int64: 1080, Valid: true},
+		},
+		{
+			ID:         3,
+			VideoCodec: sql.NullString{String: "h264", Valid: true},
+			Height:     sql.NullInt64{Int64: 720, Valid: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int64
+		err      error
+	}{
+		{"no preferences picks the best version", "", 1, nil},
+		{"explicit version", "version=3", 3, nil},
+		{"explicit version ignores capabilities", "version=1&hdr=false", 1, nil},
+		{"unknown version", "version=9", 0, errMediaVersionNotFound},
+		{"invalid version", "version=abc", 0, errMediaVersionNotFound},
+		{"no hdr", "hdr=false", 2, nil},
+		{"max height", "max_height=1080", 2, nil},
+		{"codecs", "codecs=H264,vp9", 3, nil},
+		{"combined", "codecs=hevc,h264&max_height=2160&hdr=0", 2, nil},
+		{"nothing playable falls back to the smallest", "codecs=av1", 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("Failed to parse query: %v", err)
+			}
+
+			version, ok, err := selectMediaVersion(versions, query)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("selectMediaVersion(%q) error = %v, want %v", tt.query, err, tt.err)
+			}
+
+			if tt.err != nil {
+				return
+			}
+
+			if !ok || version.ID != tt.expected {
+				t.Errorf("selectMediaVersion(%q) = %d (ok %v), want %d", tt.query, version.ID, ok, tt.expected)
+			}
+		})
+	}
+
+	t.Run("no versions", func(t *testing.T) {
+		_, ok, err := selectMediaVersion(nil, url.Values{})
+		if ok || err != nil {
+			t.Errorf("Expected no selection for a movie without versions, got ok %v, err %v", ok, err)
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/tmdb"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// movieMatchCandidate is a TMDB search result offered when fixing a movie's match.
+type movieMatchCandidate struct {
+	TmdbID      int     `json:"tmdb_id"`
+	Title       string  `json:"title"`
+	ReleaseDate string  `json:"release_date"`
+	Year        int     `json:"year"`
+	Overview    string  `json:"overview"`
+	Poster      string  `json:"poster"`
+	Popularity  float64 `json:"popularity"`
+	VoteAverage float64 `json:"vote_average"`
+	Score       float64 `json:"score"`
+	TitleMatch  bool    `json:"title_match"`
+	Current     bool    `json:"current"`
+}
+
+// MatchMovieRequest holds the TMDB id a movie is matched to.
+type MatchMovieRequest struct {
+	TmdbID int `json:"tmdb_id"`
+}
+
+// SearchMovieMatch searches TMDB for the films a movie could be. The query defaults to
+// the movie's title; ?year= narrows the search to films released that year, searching
+// every year when none was, and boosts them in the scores like the scanner does.
+func (app *Application) SearchMovieMatch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid movie id"), http.StatusBadRequest)
+		return
+	}
+
+	year := 0
+	if yearParam := r.URL.Query().Get("year"); yearParam != "" {
+		year, err = strconv.Atoi(yearParam)
+		if err != nil || year < 0 {
+			helpers.ErrorJSON(w, errors.New("invalid year"), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if app.Tmdb == nil {
+		helpers.ErrorJSON(w, errors.New("tmdb is not configured"), http.StatusServiceUnavailable)
+		return
+	}
+
+	movie, err := app.Queries.GetMovieByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("movie not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get movie", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to search matches"))
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		query = movie.Title
+	}
+
+	locale := app.movieMetadataLocale()
+	results, err := app.Tmdb.SearchMoviesByTitleAndYear(query, year, locale)
+	if year > 0 && errors.Is(err, tmdb.ErrNoMoviesFound) {
+		// A year off by one (festival vs. theatrical release) shouldn't hide the film
+		results, err = app.Tmdb.SearchMoviesByTitleAndYear(query, 0, locale)
+	}
+	if err != nil && !errors.Is(err, tmdb.ErrNoMoviesFound) {
+		app.Logger.Error("failed to search tmdb", "error", err, "query", query)
+		helpers.ErrorJSON(w, errors.New("failed to search tmdb"), http.StatusBadGateway)
+		return
+	}
+
+	candidates := make([]movieMatchCandidate, 0, len(results))
+	for i := range results {
+		result := &results[i]
+		candidates = append(candidates, movieMatchCandidate{
+			TmdbID:      result.TmdbID,
+			Title:       result.Title,
+			ReleaseDate: result.ReleaseDate,
+			Year:        extractYearFromReleaseDate(result.ReleaseDate),
+			Overview:    result.Overview,
+			Poster:      helpers.TmdbImageURL(result.PosterPath, helpers.TMDB_POSTER_SIZE),
+			Popularity:  result.Popularity,
+			VoteAverage: result.VoteAverage,
+			Score:       tmdbMatchScore(result, year),
+			TitleMatch:  titleMatchConfidence(query, result.Title, result.OriginalTitle),
+			Current:     movie.TmdbID.Valid && movie.TmdbID.Int64 == int64(result.TmdbID),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"query":      query,
+			"candidates": candidates,
+		},
+	})
+}
+
+// MatchMovie matches a movie to a TMDB entry chosen by the user. Its metadata and
+// cast, crew, genres, companies and videos are replaced in one transaction, and the
+// match is locked so later scans re-fetch it instead of searching by file name.
+// When another movie already has the TMDB id, the movie's files are merged into it as
+// media versions and the merged movie is returned.
+func (app *Application) MatchMovie(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid movie id"), http.StatusBadRequest)
+		return
+	}
+
+	var req MatchMovieRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.TmdbID <= 0 {
+		helpers.ErrorJSON(w, errors.New("tmdb_id is required"), http.StatusBadRequest)
+		return
+	}
+
+	if app.Tmdb == nil {
+		helpers.ErrorJSON(w, errors.New("tmdb is not configured"), http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Fetched before the transaction so the scanners aren't blocked on the network
+	locale := app.movieMetadataLocale()
+	tmdbMovie := &tmdb.TmdbMovie{TmdbID: req.TmdbID}
+	if err := app.Tmdb.RefreshTmdbMovieByID(tmdbMovie, locale); err != nil {
+		app.Logger.Error("failed to get movie from tmdb", "error", err, "tmdb_id", req.TmdbID)
+		helpers.ErrorJSON(w, errors.New("failed to fetch movie from tmdb"), http.StatusBadGateway)
+		return
+	}
+	app.fetchMovieCollection(tmdbMovie)
+
+	// Serialize with the scanners, which write the same rows inside their batch transactions
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to begin transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to match movie"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	movie, err := qtx.GetMovieByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("movie not found"), http.StatusNotFound)
+			return
+		}
+		app.Logger.Error("failed to get movie", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to match movie"))
+		return
+	}
+
+	// Another movie with this TMDB id is the same film: the files of this one become
+	// versions of it, and the match is applied to the merged movie
+	other, err := qtx.GetMovieByTmdbID(ctx, helpers.NullInt64(int64(req.TmdbID)))
+	if err == nil && other.ID != movie.ID {
+		if err := mergeMovie(ctx, qtx, movie, other); err != nil {
+			app.Logger.Error("failed to merge movie", "error", err, "id", id, "target_id", other.ID)
+			helpers.ErrorJSON(w, errors.New("failed to match movie"))
+			return
+		}
+		movie = other
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		app.Logger.Error("failed to get movie by tmdb id", "error", err, "tmdb_id", req.TmdbID)
+		helpers.ErrorJSON(w, errors.New("failed to match movie"))
+		return
+	}
+
+	params := database.UpdateMovieMatchParams{
+		ID:            movie.ID,
+		Title:         tmdbMovie.Title,
+		OriginalTitle: helpers.NullString(tmdbMovie.OriginalTitle),
+		Adult:         tmdbMovie.Adult,
+		TmdbID:        helpers.NullInt64(int64(tmdbMovie.TmdbID)),
+		ImdbID:        helpers.NullString(tmdbMovie.ImdbID),
+		PosterPath:    helpers.NullString(tmdbMovie.PosterPath),
+		BackdropPath:  helpers.NullString(tmdbMovie.BackdropPath),
+		Language:      helpers.NullString(tmdbMovie.OriginalLang),
+		ReleaseDate:   helpers.NullString(tmdbMovie.ReleaseDate),
+		Overview:      helpers.NullString(tmdbMovie.Overview),
+		TagLine:       helpers.NullString(tmdbMovie.Tagline),
+		Certification: helpers.NullString(tmdbMovie.Certification(locale.Country)),
+		CriticRating:  helpers.NullFloat64(tmdbMovie.VoteAverage),
+		Revenue:       helpers.NullFloat64(float64(tmdbMovie.Revenue)),
+		Budget:        helpers.NullFloat64(float64(tmdbMovie.Budget)),
+		RunTime:       helpers.NullInt64(int64(tmdbMovie.Runtime)),
+	}
+
+	if year := extractYearFromReleaseDate(tmdbMovie.ReleaseDate); year > 0 {
+		params.Year = helpers.NullInt64(int64(year))
+	}
+
+	movie, err = qtx.UpdateMovieMatch(ctx, params)
+	if err != nil {
+		app.Logger.Error("failed to update movie match", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to match movie"))
+		return
+	}
+
+	// processTmdbEntities replaces the old film's cast, crew and other links
+	cache := newMovieScannerCache()
+	defer cache.Clear()
+
+	if err := app.processTmdbEntities(ctx, qtx, movie, tmdbMovie, cache); err != nil {
+		app.Logger.Error("failed to replace movie entities", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to match movie"))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit movie match", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to match movie"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"movie": movieDetailsMovieToMap(movie),
+		},
+	})
+}
+
+// mergeMovie moves the files of source (its media versions with their streams and
+// chapters, and its local extras) to target, then deletes source. Metadata, cast and
+// crew of source go with it, target keeps its own.
+func mergeMovie(ctx context.Context, qtx *database.Queries, source, target database.Movie) error {
+	if err := qtx.MergeMediaVersions(ctx, database.MergeMediaVersionsParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeVideoStreams(ctx, database.MergeVideoStreamsParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeAudioStreams(ctx, database.MergeAudioStreamsParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeSubtitles(ctx, database.MergeSubtitlesParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeChapters(ctx, database.MergeChaptersParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	if err := qtx.MergeLocalExtras(ctx, database.MergeLocalExtrasParams{TargetID: target.ID, SourceID: source.ID}); err != nil {
+		return err
+	}
+
+	return qtx.DeleteMovie(ctx, source.ID)
+}
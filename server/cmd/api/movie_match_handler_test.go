@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/tmdb"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// fakeTmdb serves movies and collections from memory. Searches match titles
+// case-insensitively, and the locales movies are fetched in are recorded.
+type fakeTmdb struct {
+	movies      []tmdb.TmdbMovie
+	collections []tmdb.TmdbCollection
+	locales     []tmdb.Locale
+}
+
+// newFakeTmdb builds a fakeTmdb from TMDB movie JSON documents.
+func newFakeTmdb(t *testing.T, documents ...string) *fakeTmdb {
+	t.Helper()
+
+	f := &fakeTmdb{}
+	for _, document := range documents {
+		var movie tmdb.TmdbMovie
+		if err := json.Unmarshal([]byte(document), &movie); err != nil {
+			t.Fatalf("Failed to parse tmdb movie: %v", err)
+		}
+		f.movies = append(f.movies, movie)
+	}
+	return f
+}
+
+func (f *fakeTmdb) GetTmdbMovieByID(movie *tmdb.TmdbMovie, locale tmdb.Locale) error {
+	f.locales = append(f.locales, locale)
+	for _, m := range f.movies {
+		if m.TmdbID == movie.TmdbID {
+			*movie = m
+			return nil
+		}
+	}
+	return errors.New("unable to get movie from tmdb")
+}
+
+func (f *fakeTmdb) RefreshTmdbMovieByID(movie *tmdb.TmdbMovie, locale tmdb.Locale) error {
+	return f.GetTmdbMovieByID(movie, locale)
+}
+
+func (f *fakeTmdb) GetTmdbMovieByTitle(movie *tmdb.TmdbMovie) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeTmdb) SearchMoviesByTitleAndYear(title string, year int, locale tmdb.Locale) ([]tmdb.TmdbMovie, error) {
+	results := []tmdb.TmdbMovie{}
+	for _, m := range f.movies {
+		if !strings.Contains(strings.ToLower(m.Title), strings.ToLower(title)) {
+			continue
+		}
+		if year > 0 && extractYearFromReleaseDate(m.ReleaseDate) != year {
+			continue
+		}
+		results = append(results, m)
+	}
+	if len(results) == 0 {
+		return nil, tmdb.ErrNoMoviesFound
+	}
+	return results, nil
+}
+
+func (f *fakeTmdb) GetMoviesInTheaters(locale tmdb.Locale) ([]*tmdb.TmdbMovie, error) {
+	return nil, nil
+}
+
+func (f *fakeTmdb) GetTmdbPopularMovies(locale tmdb.Locale) ([]*tmdb.TmdbMovie, error) {
+	return nil, nil
+}
+
+func (f *fakeTmdb) GetTmdbCollection(id int, locale tmdb.Locale) (*tmdb.TmdbCollection, error) {
+	for _, c := range f.collections {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, errors.New("unable to get collection from tmdb")
+}
+
+const (
+	tmdbAlien  = `{"id": 348, "title": "Alien", "release_date": "1979-05-25", "popularity": 50, "credits": {"cast": [{"id": 10, "name": "Sigourney Weaver", "character": "Ripley", "order": 0}, {"id": 11, "name": "Tom Skerritt", "character": "Dallas", "order": 1}]}, "genres": [{"id": 878, "name": "Science Fiction"}]}`
+	tmdbAliens = `{"id": 679, "title": "Aliens", "release_date": "1986-07-18", "popularity": 40, "tagline": "This time it's war.", "credits": {"cast": [{"id": 10, "name": "Sigourney Weaver", "character": "Ellen Ripley", "order": 0}], "crew": [{"id": 20, "name": "James Cameron", "job": "Director", "department": "Directing"}]}, "genres": [{"id": 28, "name": "Action"}]}`
+)
+
+// TestMatchMovie tests that a manual match replaces the movie's metadata and entities,
+// and that later scans keep it instead of searching by file name again.
+func TestMatchMovie(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	// The file holds the sequel, but without a year the search picks the more popular original
+	path := "/movies/Alien.mkv"
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{path: 1080}}
+	app.Tmdb = newFakeTmdb(t, tmdbAlien, tmdbAliens)
+
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile failed: %v", err)
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.TmdbID.Int64 != 348 {
+		t.Fatalf("Expected the scan to pick tmdb id 348, got %d", movie.TmdbID.Int64)
+	}
+
+	match := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/movies/1/match", strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "1")
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		app.MatchMovie(rr, req)
+		return rr
+	}
+
+	if rr := match(`{"tmdb_id": 999}`); rr.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d for an unknown tmdb id, got %d", http.StatusBadGateway, rr.Code)
+	}
+
+	if rr := match(`{"tmdb_id": 679}`); rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	movie, err = app.Queries.GetMovieByID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.Title != "Aliens" || movie.TmdbID.Int64 != 679 || movie.Year.Int64 != 1986 || !movie.MatchLocked {
+		t.Errorf("Expected the locked Aliens (1986) match, got %q (%d, %d, locked=%v)", movie.Title, movie.TmdbID.Int64, movie.Year.Int64, movie.MatchLocked)
+	}
+
+	cast, err := app.Queries.GetCastByMovieID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get cast: %v", err)
+	}
+
+	if len(cast) != 1 || cast[0].Character != "Ellen Ripley" {
+		t.Errorf("Expected the cast to be replaced, got %+v", cast)
+	}
+
+	genres, err := app.Queries.GetGenresByMovieID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get genres: %v", err)
+	}
+
+	if len(genres) != 1 || genres[0].Tag != "Action" {
+		t.Errorf("Expected the genres to be replaced, got %+v", genres)
+	}
+
+	// A rescan of the changed file keeps the manual match
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 2000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile rescan failed: %v", err)
+	}
+
+	movie, err = app.Queries.GetMovieByID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.TmdbID.Int64 != 679 || movie.Title != "Aliens" {
+		t.Errorf("Expected the rescan to keep tmdb id 679, got %d (%q)", movie.TmdbID.Int64, movie.Title)
+	}
+}
+
+// TestMatchMovie_MergesIntoExistingMovie tests that matching a movie to the TMDB id of
+// another movie moves its file there as a media version instead of failing.
+func TestMatchMovie_MergesIntoExistingMovie(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	// The 1080p copy is named so its search finds nothing, so it becomes a movie of its own
+	original := "/movies/Alien (1979).mkv"
+	remux := "/movies/Alien 1080p.mkv"
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{original: 2160, remux: 1080}}
+	app.Tmdb = newFakeTmdb(t, tmdbAlien)
+
+	cache := newMovieScannerCache()
+	for _, path := range []string{original, remux} {
+		if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, cache); err != nil {
+			t.Fatalf("processMovieFile(%q) failed: %v", path, err)
+		}
+	}
+
+	target, err := app.Queries.GetMovieByFilePath(ctx, original)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	source, err := app.Queries.GetMovieByFilePath(ctx, remux)
+	if err != nil || source.ID == target.ID || source.TmdbID.Valid {
+		t.Fatalf("Expected the copy to be an unmatched movie of its own, got %+v: %v", source, err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/movies/2/match", strings.NewReader(`{"tmdb_id": 348}`))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", strconv.FormatInt(source.ID, 10))
+	req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	app.MatchMovie(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Data struct {
+			Movie struct {
+				ID int64 `json:"id"`
+			} `json:"movie"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil || response.Data.Movie.ID != target.ID {
+		t.Errorf("Expected the merged movie %d to be returned, got %s", target.ID, rr.Body.String())
+	}
+
+	if _, err := app.Queries.GetMovieByID(ctx, source.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected the merged movie to be deleted, got %v", err)
+	}
+
+	versions, err := app.Queries.GetMediaVersionsByMovieID(ctx, target.ID)
+	if err != nil {
+		t.Fatalf("Failed to get versions: %v", err)
+	}
+
+	if len(versions) != 2 || versions[1].FilePath != remux || versions[1].Height.Int64 != 1080 {
+		t.Errorf("Expected the copy to be the second version with its streams, got %+v", versions)
+	}
+}
+
+// TestSearchMovieMatch tests that the search is narrowed to the year when it has
+// results there, and that the current match is flagged.
+func TestSearchMovieMatch(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	app.Tmdb = newFakeTmdb(t, tmdbAlien, tmdbAliens)
+
+	if _, err := app.DB.Exec(`INSERT INTO movies (title, file_path, file_name, size, container, mime_type, adult, tmdb_id)
+		VALUES ('Alien', '/movies/alien.mkv', 'alien.mkv', 1, 'mkv', 'video/x-matroska', 0, 348)`); err != nil {
+		t.Fatalf("Failed to insert movie: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		status   int
+		expected []int
+	}{
+		{"defaults to the title", "", http.StatusOK, []int{348, 679}},
+		{"year", "?q=alien&year=1986", http.StatusOK, []int{679}},
+		{"year without results", "?q=alien&year=1990", http.StatusOK, []int{348, 679}},
+		{"no results", "?q=heat", http.StatusOK, []int{}},
+		{"invalid year", "?year=abc", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/movies/1/match/search"+tt.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "1")
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			app.SearchMovieMatch(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data struct {
+					Candidates []movieMatchCandidate `json:"candidates"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			ids := []int{}
+			for _, candidate := range response.Data.Candidates {
+				ids = append(ids, candidate.TmdbID)
+				if candidate.Current != (candidate.TmdbID == 348) {
+					t.Errorf("Expected only tmdb id 348 to be current, got %+v", candidate)
+				}
+			}
+
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("Expected candidates %v, got %v", tt.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Errorf("Expected candidates %v, got %v", tt.expected, ids)
+					break
+				}
+			}
+		})
+	}
+}
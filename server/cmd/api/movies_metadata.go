@@ -0,0 +1,209 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/tmdb"
+	"strings"
+)
+
+// MovieMetadataProvider is a source of movie metadata the scanner can be configured
+// with, like a local NFO file, the tags embedded in the video or TMDB.
+type MovieMetadataProvider interface {
+	// Name is the provider's name in the movies_metadata_providers setting.
+	Name() string
+	// FetchMovieMetadata returns what the provider knows about a movie file, or nil
+	// when it knows nothing. Errors are logged and the scan continues without it.
+	FetchMovieMetadata(ctx context.Context, query *MovieMetadataQuery) (*MovieMetadata, error)
+}
+
+// MovieMetadataQuery is what providers are told about a movie file. Title and year come
+// from the file name, TmdbID from a manual match. Ids, title and year found by a
+// provider replace unset or file name values for the providers after it, so an NFO
+// with a TMDB id saves TMDB a search.
+type MovieMetadataQuery struct {
+	Path   string
+	Title  string
+	Year   int
+	TmdbID int64
+	ImdbID string
+	Info   *ffprobe.FfprobeResult
+}
+
+// MovieMetadata is the metadata of a movie, zero values meaning unknown. Credits,
+// production companies and extra videos are only stored from TMDB, whose ids the
+// people and companies tables are keyed by, so Tmdb carries its raw movie.
+type MovieMetadata struct {
+	TmdbID        int64
+	ImdbID        string
+	Title         string
+	OriginalTitle string
+	Year          int
+	ReleaseDate   string
+	Overview      string
+	Tagline       string
+	Certification string
+	Language      string
+	CriticRating  float64
+	Revenue       int64
+	Budget        int64
+	Runtime       int // minutes
+	PosterPath    string
+	BackdropPath  string
+	Adult         bool
+	Genres        []string
+	Tmdb          *tmdb.TmdbMovie
+}
+
+// movieMetadataProviders are the providers a movie library can be configured with, by
+// name. A constructor returns nil when its provider isn't available, like TMDB without
+// an API key. Register new sources here.
+var movieMetadataProviders = map[string]func(app *Application) MovieMetadataProvider{
+	"nfo": func(*Application) MovieMetadataProvider {
+		return nfoMovieMetadataProvider{}
+	},
+	"embedded": func(*Application) MovieMetadataProvider {
+		return embeddedMovieMetadataProvider{}
+	},
+	"tmdb": func(app *Application) MovieMetadataProvider {
+		if app.Tmdb == nil {
+			return nil
+		}
+		return tmdbMovieMetadataProvider{client: app.Tmdb, locale: app.movieMetadataLocale()}
+	},
+}
+
+// parseMovieMetadataProviders parses a comma-separated provider list, highest priority
+// first. Names are case-insensitive and repeats are dropped; an empty list is the default.
+func parseMovieMetadataProviders(s string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := movieMetadataProviders[name]; !ok {
+			return nil, fmt.Errorf("unknown movie metadata provider %q", name)
+		}
+
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return parseMovieMetadataProviders(helpers.MOVIES_METADATA_PROVIDERS)
+	}
+
+	return names, nil
+}
+
+// movieMetadataChain returns the available providers of the movie library in priority order.
+func (app *Application) movieMetadataChain() []MovieMetadataProvider {
+	names, err := parseMovieMetadataProviders(app.Settings().MoviesMetadataProviders)
+	if err != nil {
+		app.Logger.Warn("invalid movie metadata providers, using the default", "error", err)
+		names, _ = parseMovieMetadataProviders(helpers.MOVIES_METADATA_PROVIDERS)
+	}
+
+	var chain []MovieMetadataProvider
+	for _, name := range names {
+		if provider := movieMetadataProviders[name](app); provider != nil {
+			chain = append(chain, provider)
+		}
+	}
+
+	return chain
+}
+
+// movieMetadataLocale returns the TMDB locale of the movies library: its overrides
+// where set, else the global metadata language, fallback language and country.
+func (app *Application) movieMetadataLocale() tmdb.Locale {
+	settings := app.Settings()
+	return tmdb.Locale{
+		Language:         cmp.Or(settings.MoviesMetadataLanguage.String, settings.MetadataLanguage),
+		FallbackLanguage: cmp.Or(settings.MoviesMetadataFallbackLanguage.String, settings.MetadataFallbackLanguage),
+		Country:          cmp.Or(settings.MoviesCertificationCountry.String, settings.CertificationCountry),
+	}
+}
+
+// fetchMovieMetadata asks every provider of the chain about a movie file and merges
+// their answers field by field: a field is taken from the first provider that knows
+// it. A manual match's TMDB id wins over any provider's, and the file name's title
+// and year are used when no provider knows them. A failed TMDB search or lookup is
+// returned, tagged with the tmdb phase, along with what the other providers knew.
+func (app *Application) fetchMovieMetadata(ctx context.Context, chain []MovieMetadataProvider, query MovieMetadataQuery) (*MovieMetadata, error) {
+	merged := &MovieMetadata{TmdbID: query.TmdbID}
+	fileName := &MovieMetadata{Title: query.Title, Year: query.Year}
+
+	var tmdbErr error
+	for _, provider := range chain {
+		meta, err := provider.FetchMovieMetadata(ctx, &query)
+		if err != nil {
+			app.Logger.Warn("movie metadata provider failed", "provider", provider.Name(), "path", query.Path, "error", err)
+			if provider.Name() == "tmdb" {
+				tmdbErr = scanPhaseError(helpers.SCAN_PHASE_TMDB, fmt.Errorf("tmdb lookup failed: %w", err))
+			}
+			continue
+		}
+		if meta == nil {
+			continue
+		}
+
+		merged.merge(meta)
+
+		if query.TmdbID == 0 {
+			query.TmdbID = merged.TmdbID
+		}
+		if query.ImdbID == "" {
+			query.ImdbID = merged.ImdbID
+		}
+		if merged.Title != "" {
+			query.Title = merged.Title
+		}
+		if merged.Year > 0 {
+			query.Year = merged.Year
+		}
+	}
+
+	merged.merge(fileName)
+	return merged, tmdbErr
+}
+
+// merge sets every field of m that is unknown to its value in other.
+func (m *MovieMetadata) merge(other *MovieMetadata) {
+	mergeField(&m.TmdbID, other.TmdbID)
+	mergeField(&m.ImdbID, other.ImdbID)
+	mergeField(&m.Title, other.Title)
+	mergeField(&m.OriginalTitle, other.OriginalTitle)
+	mergeField(&m.Year, other.Year)
+	mergeField(&m.ReleaseDate, other.ReleaseDate)
+	mergeField(&m.Overview, other.Overview)
+	mergeField(&m.Tagline, other.Tagline)
+	mergeField(&m.Certification, other.Certification)
+	mergeField(&m.Language, other.Language)
+	mergeField(&m.CriticRating, other.CriticRating)
+	mergeField(&m.Revenue, other.Revenue)
+	mergeField(&m.Budget, other.Budget)
+	mergeField(&m.Runtime, other.Runtime)
+	mergeField(&m.PosterPath, other.PosterPath)
+	mergeField(&m.BackdropPath, other.BackdropPath)
+	mergeField(&m.Adult, other.Adult)
+	mergeField(&m.Tmdb, other.Tmdb)
+
+	if len(m.Genres) == 0 {
+		m.Genres = other.Genres
+	}
+}
+
+// mergeField sets *field to value when it is still the zero value.
+func mergeField[T comparable](field *T, value T) {
+	var zero T
+	if *field == zero {
+		*field = value
+	}
+}
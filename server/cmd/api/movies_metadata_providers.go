@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/tmdb"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// nfoMovieMetadataProvider reads the Kodi NFO next to a movie: <video name>.nfo, else
+// the movie.nfo of its directory.
+type nfoMovieMetadataProvider struct{}
+
+func (nfoMovieMetadataProvider) Name() string {
+	return "nfo"
+}
+
+func (nfoMovieMetadataProvider) FetchMovieMetadata(ctx context.Context, query *MovieMetadataQuery) (*MovieMetadata, error) {
+	dir := filepath.Dir(query.Path)
+	base := strings.TrimSuffix(filepath.Base(query.Path), filepath.Ext(query.Path))
+
+	for _, path := range []string{filepath.Join(dir, base+".nfo"), filepath.Join(dir, helpers.MOVIE_NFO_FILE_NAME)} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		nfo, err := helpers.ParseMovieNfo(data)
+		if err != nil {
+			// Release group NFOs share the extension, they are no error
+			if errors.Is(err, helpers.ErrNotMovieNfo) {
+				continue
+			}
+			return nil, err
+		}
+
+		return &MovieMetadata{
+			TmdbID:        nfo.TmdbID,
+			ImdbID:        nfo.ImdbID,
+			Title:         nfo.Title,
+			OriginalTitle: nfo.OriginalTitle,
+			Year:          nfo.Year,
+			ReleaseDate:   nfo.Premiered,
+			Overview:      nfo.Plot,
+			Tagline:       nfo.Tagline,
+			Certification: nfo.Certification,
+			CriticRating:  nfo.Rating,
+			Runtime:       nfo.Runtime,
+			Genres:        nfo.Genres,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// embeddedMovieMetadataProvider reads the container tags ffprobe found in the file.
+// Rips often carry the release name as title, so it ranks below TMDB by default and
+// titles that look like one are ignored, leaving the file name's.
+type embeddedMovieMetadataProvider struct{}
+
+func (embeddedMovieMetadataProvider) Name() string {
+	return "embedded"
+}
+
+func (embeddedMovieMetadataProvider) FetchMovieMetadata(ctx context.Context, query *MovieMetadataQuery) (*MovieMetadata, error) {
+	if query.Info == nil {
+		return nil, nil
+	}
+
+	tags := query.Info.Format.Tags
+	meta := &MovieMetadata{
+		Genres: helpers.SplitGenres(tags.Genre),
+	}
+
+	if title := strings.TrimSpace(tags.Title); !helpers.IsReleaseName(title) {
+		meta.Title = title
+	}
+
+	// MP4 keeps the long description in ldes (synopsis) and a short one in desc
+	for _, overview := range []string{tags.Synopsis, tags.Description} {
+		if overview = strings.TrimSpace(overview); overview != "" {
+			meta.Overview = overview
+			break
+		}
+	}
+
+	if date := strings.TrimSpace(tags.Date); len(date) >= 4 {
+		meta.Year, _ = strconv.Atoi(date[:4])
+	}
+
+	return meta, nil
+}
+
+// tmdbMovieMetadataProvider looks a movie up on TMDB: by id when a manual match or an
+// earlier provider knows it, else by searching its title and year. Texts and the
+// certification are in the library's locale.
+type tmdbMovieMetadataProvider struct {
+	client tmdb.TmdbInterface
+	locale tmdb.Locale
+}
+
+func (tmdbMovieMetadataProvider) Name() string {
+	return "tmdb"
+}
+
+func (p tmdbMovieMetadataProvider) FetchMovieMetadata(ctx context.Context, query *MovieMetadataQuery) (*MovieMetadata, error) {
+	if query.TmdbID > 0 {
+		movie := &tmdb.TmdbMovie{TmdbID: int(query.TmdbID)}
+		if err := p.client.GetTmdbMovieByID(movie, p.locale); err != nil {
+			return nil, err
+		}
+		return movieMetadataFromTmdb(movie, p.locale.Country), nil
+	}
+
+	searchResults, err := p.client.SearchMoviesByTitleAndYear(query.Title, query.Year, p.locale)
+	if err != nil {
+		if errors.Is(err, tmdb.ErrNoMoviesFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(searchResults) == 0 {
+		return nil, nil
+	}
+
+	bestMatch := selectBestTmdbMatch(searchResults, query.Year)
+	if bestMatch == nil {
+		// No year match: use first result only if title is a plausible match (avoid wrong film)
+		first := &searchResults[0]
+		if !titleMatchConfidence(query.Title, first.Title, first.OriginalTitle) {
+			return nil, nil
+		}
+		bestMatch = first
+	}
+
+	if err := p.client.GetTmdbMovieByID(bestMatch, p.locale); err != nil {
+		return nil, err
+	}
+
+	return movieMetadataFromTmdb(bestMatch, p.locale.Country), nil
+}
+
+// movieMetadataFromTmdb converts a TMDB movie fetched by id, with the certification
+// of country.
+func movieMetadataFromTmdb(movie *tmdb.TmdbMovie, country string) *MovieMetadata {
+	meta := &MovieMetadata{
+		TmdbID:        int64(movie.TmdbID),
+		ImdbID:        movie.ImdbID,
+		Title:         movie.Title,
+		OriginalTitle: movie.OriginalTitle,
+		Year:          extractYearFromReleaseDate(movie.ReleaseDate),
+		ReleaseDate:   movie.ReleaseDate,
+		Overview:      movie.Overview,
+		Tagline:       movie.Tagline,
+		Certification: movie.Certification(country),
+		Language:      movie.OriginalLang,
+		CriticRating:  movie.VoteAverage,
+		Revenue:       movie.Revenue,
+		Budget:        movie.Budget,
+		Runtime:       movie.Runtime,
+		PosterPath:    movie.PosterPath,
+		BackdropPath:  movie.BackdropPath,
+		Adult:         movie.Adult,
+		Tmdb:          movie,
+	}
+
+	for _, genre := range movie.Genres {
+		meta.Genres = append(meta.Genres, genre.Name)
+	}
+
+	return meta
+}
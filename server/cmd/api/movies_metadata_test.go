@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/tmdb"
+)
+
+// fakeMovieMetadataProvider answers every query with a fixed result and records the queries.
+type fakeMovieMetadataProvider struct {
+	name    string
+	meta    *MovieMetadata
+	err     error
+	queries []MovieMetadataQuery
+}
+
+func (f *fakeMovieMetadataProvider) Name() string {
+	return f.name
+}
+
+func (f *fakeMovieMetadataProvider) FetchMovieMetadata(ctx context.Context, query *MovieMetadataQuery) (*MovieMetadata, error) {
+	f.queries = append(f.queries, *query)
+	return f.meta, f.err
+}
+
+// TestFetchMovieMetadata tests that fields are taken from the first provider that knows
+// them, and that ids found early in the chain reach the providers after it.
+func TestFetchMovieMetadata(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	local := &fakeMovieMetadataProvider{name: "local", meta: &MovieMetadata{
+		Title:    "Alien",
+		TmdbID:   348,
+		Overview: "Local plot",
+	}}
+	failing := &fakeMovieMetadataProvider{name: "failing", err: errors.New("unavailable")}
+	remote := &fakeMovieMetadataProvider{name: "remote", meta: &MovieMetadata{
+		Title:       "Alien (remote)",
+		TmdbID:      1,
+		ReleaseDate: "1979-05-25",
+		Year:        1979,
+		Overview:    "Remote plot",
+		Genres:      []string{"Science Fiction"},
+	}}
+
+	query := MovieMetadataQuery{Path: "/movies/alien.mkv", Title: "alien", Year: 0}
+	meta, err := app.fetchMovieMetadata(context.Background(), []MovieMetadataProvider{local, failing, remote}, query)
+	if err != nil {
+		t.Errorf("Expected only TMDB failures to be returned, got %v", err)
+	}
+
+	expected := &MovieMetadata{
+		Title:       "Alien",
+		TmdbID:      348,
+		ReleaseDate: "1979-05-25",
+		Year:        1979,
+		Overview:    "Local plot",
+		Genres:      []string{"Science Fiction"},
+	}
+	if !reflect.DeepEqual(meta, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, meta)
+	}
+
+	if len(remote.queries) != 1 || remote.queries[0].TmdbID != 348 || remote.queries[0].Title != "Alien" {
+		t.Errorf("Expected the local id and title to reach the remote provider, got %+v", remote.queries)
+	}
+
+	// A manual match wins over every provider, the file name fills what none knows
+	meta, _ = app.fetchMovieMetadata(context.Background(), []MovieMetadataProvider{local}, MovieMetadataQuery{Title: "alien", Year: 1979, TmdbID: 679})
+	if meta.TmdbID != 679 || meta.Title != "Alien" || meta.Year != 1979 {
+		t.Errorf("Expected the locked id and the file name's year, got %+v", meta)
+	}
+
+	// A TMDB failure is returned in the tmdb phase, with what the others knew
+	tmdbDown := &fakeMovieMetadataProvider{name: "tmdb", err: errors.New("unavailable")}
+	meta, err = app.fetchMovieMetadata(context.Background(), []MovieMetadataProvider{tmdbDown, local}, query)
+	if err == nil || scanErrorPhase(err) != helpers.SCAN_PHASE_TMDB {
+		t.Errorf("Expected a tmdb phase error, got %v", err)
+	}
+	if meta.Title != "Alien" {
+		t.Errorf("Expected the local title despite the TMDB failure, got %q", meta.Title)
+	}
+}
+
+func TestParseMovieMetadataProviders(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+		wantErr  bool
+	}{
+		{"", []string{"nfo", "tmdb", "embedded"}, false},
+		{" TMDB , nfo,tmdb,", []string{"tmdb", "nfo"}, false},
+		{"embedded", []string{"embedded"}, false},
+		{"nfo,imdb", nil, true},
+	}
+
+	for _, tt := range tests {
+		names, err := parseMovieMetadataProviders(tt.value)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("parseMovieMetadataProviders(%q) = %v, %v; expected %v", tt.value, names, err, tt.expected)
+		}
+	}
+}
+
+// TestProcessMovieFile_MetadataProviders tests a scan with an NFO next to the file,
+// with and without TMDB and in both priority orders.
+func TestProcessMovieFile_MetadataProviders(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "alien.1979.mkv")
+
+	nfo := `<movie>
+  <title>Alien</title>
+  <plot>The NFO plot.</plot>
+  <genre>Horror</genre>
+  <uniqueid type="tmdb">348</uniqueid>
+</movie>`
+	if err := os.WriteFile(filepath.Join(dir, "alien.1979.nfo"), []byte(nfo), 0o644); err != nil {
+		t.Fatalf("Failed to write nfo: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		providers string
+		tmdb      bool
+		overview  string
+		genre     string
+		cast      int
+	}{
+		{"nfo without tmdb", "nfo,tmdb,embedded", false, "The NFO plot.", "Horror", 0},
+		{"nfo first", "nfo,tmdb", true, "The NFO plot.", "Horror", 2},
+		{"tmdb first", "tmdb,nfo", true, "The NFO plot.", "Science Fiction", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := setupTestAppWithLogger(t)
+			defer app.DB.Close()
+
+			ctx := context.Background()
+
+			app.Ffprobe = &fakeFfprobe{heights: map[string]int{path: 1080}}
+			settings := *app.Settings()
+			settings.MoviesMetadataProviders = tt.providers
+			app.SetSettings(&settings)
+			if tt.tmdb {
+				app.Tmdb = newFakeTmdb(t, tmdbAlien)
+			}
+
+			if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, newMovieScannerCache()); err != nil {
+				t.Fatalf("processMovieFile failed: %v", err)
+			}
+
+			movie, err := app.Queries.GetMovieByFilePath(ctx, path)
+			if err != nil {
+				t.Fatalf("Failed to get movie: %v", err)
+			}
+
+			if movie.Title != "Alien" || movie.TmdbID.Int64 != 348 || movie.Overview.String != tt.overview {
+				t.Errorf("Unexpected movie: %q (tmdb %d): %q", movie.Title, movie.TmdbID.Int64, movie.Overview.String)
+			}
+
+			if tt.tmdb && movie.Year.Int64 != 1979 {
+				t.Errorf("Expected the year from TMDB, got %d", movie.Year.Int64)
+			}
+
+			genres, err := app.Queries.GetGenresByMovieID(ctx, movie.ID)
+			if err != nil {
+				t.Fatalf("Failed to get genres: %v", err)
+			}
+			if len(genres) != 1 || genres[0].Tag != tt.genre {
+				t.Errorf("Expected genre %q, got %+v", tt.genre, genres)
+			}
+
+			cast, err := app.Queries.GetCastByMovieID(ctx, movie.ID)
+			if err != nil {
+				t.Fatalf("Failed to get cast: %v", err)
+			}
+			if len(cast) != tt.cast {
+				t.Errorf("Expected %d cast members, got %d", tt.cast, len(cast))
+			}
+		})
+	}
+}
+
+func TestEmbeddedMovieMetadataProvider(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	info := &ffprobe.FfprobeResult{Format: ffprobe.Format{Tags: ffprobe.FormatTags{
+		Title:       "Heat",
+		Date:        "1995-12-15",
+		Genre:       "Crime / Drama",
+		Description: "Short",
+		Synopsis:    "A long description.",
+	}}}
+
+	meta, err := embeddedMovieMetadataProvider{}.FetchMovieMetadata(context.Background(), &MovieMetadataQuery{Info: info})
+	if err != nil {
+		t.Fatalf("FetchMovieMetadata failed: %v", err)
+	}
+
+	expected := &MovieMetadata{Title: "Heat", Year: 1995, Overview: "A long description.", Genres: []string{"Crime", "Drama"}}
+	if !reflect.DeepEqual(meta, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, meta)
+	}
+
+	// A release name as title leaves the file name's
+	info.Format.Tags.Title = "Heat.1995.1080p.BluRay.x264-GRP"
+	query := MovieMetadataQuery{Title: "Heat", Year: 1995, Info: info}
+
+	meta, _ = app.fetchMovieMetadata(context.Background(), []MovieMetadataProvider{embeddedMovieMetadataProvider{}}, query)
+	if meta.Title != "Heat" {
+		t.Errorf("Expected the file name's title, got %q", meta.Title)
+	}
+}
+
+// TestProcessMovieFile_MetadataLocale tests that TMDB is asked in the movies library's
+// locale, and that the localized title, the original one and the country's age rating
+// are stored.
+func TestProcessMovieFile_MetadataLocale(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	path := "/movies/Alien (1979).mkv"
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{path: 1080}}
+	fake := newFakeTmdb(t, `{"id": 348, "title": "Alien, el octavo pasajero", "original_title": "Alien", "release_date": "1979-05-25",
+		"release_dates": {"results": [{"iso_3166_1": "US", "release_dates": [{"certification": "R"}]}, {"iso_3166_1": "ES", "release_dates": [{"certification": "18"}]}]}}`)
+	app.Tmdb = fake
+
+	settings := *app.Settings()
+	settings.MetadataLanguage = "en-US"
+	settings.MetadataFallbackLanguage = "en-US"
+	settings.CertificationCountry = "US"
+	settings.MoviesMetadataLanguage = sql.NullString{String: "es-ES", Valid: true}
+	settings.MoviesCertificationCountry = sql.NullString{String: "ES", Valid: true}
+	app.SetSettings(&settings)
+
+	if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile failed: %v", err)
+	}
+
+	expected := tmdb.Locale{Language: "es-ES", FallbackLanguage: "en-US", Country: "ES"}
+	if len(fake.locales) != 1 || fake.locales[0] != expected {
+		t.Errorf("Expected TMDB to be asked in %+v, got %+v", expected, fake.locales)
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.Title != "Alien, el octavo pasajero" || movie.OriginalTitle.String != "Alien" || movie.Certification.String != "18" {
+		t.Errorf("Unexpected movie: %q (%q), rated %q", movie.Title, movie.OriginalTitle.String, movie.Certification.String)
+	}
+}
+
+// TestUpdateMetadataLanguageSettings tests that languages and countries are validated
+// and normalized, and that blank movies library overrides fall back to the global locale.
+func TestUpdateMetadataLanguageSettings(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	if err := app.InitSettings(t.Context()); err != nil {
+		t.Fatalf("InitSettings failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid language", `{"metadata_language": "spanish"}`, http.StatusBadRequest},
+		{"invalid country", `{"movies_certification_country": "ESP"}`, http.StatusBadRequest},
+		{"valid", `{"metadata_language": "es_es", "metadata_fallback_language": "en-US", "certification_country": "es", "movies_metadata_language": "ca-ES"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/settings/metadata-language", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			app.UpdateMetadataLanguageSettings(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	settings, err := app.Queries.GetSettings(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get settings: %v", err)
+	}
+
+	if settings.MetadataLanguage != "es-ES" || settings.CertificationCountry != "ES" || settings.MoviesMetadataLanguage.String != "ca-ES" || settings.MoviesCertificationCountry.Valid {
+		t.Errorf("Unexpected stored settings: %+v", settings)
+	}
+
+	expected := tmdb.Locale{Language: "ca-ES", FallbackLanguage: "en-US", Country: "ES"}
+	if locale := app.movieMetadataLocale(); locale != expected {
+		t.Errorf("Expected locale %+v, got %+v", expected, locale)
+	}
+}
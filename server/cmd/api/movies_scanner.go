@@ -2,11 +2,16 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"igloo/cmd/internal/database"
 	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/tmdb"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -26,7 +31,8 @@ func (app *Application) ScanMoviesLibrary() {
 		defer app.Wait.Done()
 	}
 
-	if !app.Settings.MoviesDir.Valid || app.Settings.MoviesDir.String == "" {
+	settings := app.Settings()
+	if !settings.MoviesDir.Valid || settings.MoviesDir.String == "" {
 		app.Logger.Error("movies directory not configured")
 		return
 	}
@@ -43,8 +49,12 @@ func (app *Application) ScanMoviesLibrary() {
 
 	// Batch buffer to collect movies before processing
 	batch := make([]movieFile, 0, helpers.SCANNER_BATCH_SIZE)
+	extras := []movieExtraFile{}
 
-	err := filepath.WalkDir(app.Settings.MoviesDir.String, func(path string, entry fs.DirEntry, err error) error {
+	// Ignore patterns, .nomedia markers and the minimum size are applied during the walk
+	filter := newLibraryFilter(settings.MoviesDir.String, helpers.DefaultMovieIgnorePatterns, settings.MoviesIgnorePatterns, settings.MoviesMinSize)
+
+	err := filepath.WalkDir(settings.MoviesDir.String, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			app.Logger.Error(fmt.Sprintf("error walking directory: %s", err.Error()))
 			errorCount++
@@ -52,10 +62,20 @@ func (app *Application) ScanMoviesLibrary() {
 		}
 
 		if entry.IsDir() {
+			skip, err := filter.skipDir(path)
+			if err != nil {
+				app.Logger.Error(err.Error())
+				errorCount++
+			}
+
+			if skip {
+				return filepath.SkipDir
+			}
+
 			return nil
 		}
 
-		ext := helpers.GetFileExtension(path)
+		ext := strings.ToLower(helpers.GetFileExtension(path))
 		if !helpers.ValidVideoExtensions[ext] {
 			return nil
 		}
@@ -67,7 +87,20 @@ func (app *Application) ScanMoviesLibrary() {
 			return nil
 		}
 
-		batch = append(batch, movieFile{path: path, ext: ext, size: info.Size()})
+		if filter.skipFile(path, info.Size()) {
+			return nil
+		}
+
+		file := movieFile{path: path, ext: ext, size: info.Size()}
+
+		// Extras are attached to their movie once every movie has been scanned,
+		// so a trailer sorted before its movie still finds it
+		if extra, ok := helpers.ParseMovieExtra(path); ok {
+			extras = append(extras, movieExtraFile{movieFile: file, extra: extra})
+			return nil
+		}
+
+		batch = append(batch, file)
 
 		// Process batch when full
 		if len(batch) >= helpers.SCANNER_BATCH_SIZE {
@@ -94,8 +127,202 @@ func (app *Application) ScanMoviesLibrary() {
 		errorCount += errors
 	}
 
-	app.Logger.Info(fmt.Sprintf("movies scanner completed: %d scanned, %d skipped, %d errors in %s",
-		moviesScanned, moviesSkipped, errorCount, helpers.FormatDuration(time.Since(startTime))))
+	// Attach local extras (trailers, featurettes...) to the movies scanned above
+	for start := 0; start < len(extras); start += helpers.SCANNER_BATCH_SIZE {
+		end := min(start+helpers.SCANNER_BATCH_SIZE, len(extras))
+		scanned, skipped, errors := app.processMovieExtrasBatch(ctx, extras[start:end])
+		moviesScanned += scanned
+		moviesSkipped += skipped
+		errorCount += errors
+	}
+
+	// Link the movies scanned before collections were supported, then store the overview
+	// and parts of every collection linked above
+	if err := app.backfillMovieCollections(ctx, cache); err != nil {
+		app.Logger.Error(fmt.Sprintf("failed to backfill movie collections: %s", err.Error()))
+		errorCount++
+	}
+
+	if err := app.updateMovieCollections(ctx, cache); err != nil {
+		app.Logger.Error(fmt.Sprintf("failed to update movie collections: %s", err.Error()))
+		errorCount++
+	}
+
+	// Drop the versions whose file is gone, promoting another version of their movie
+	removed, err := app.removeMissingMovieFiles(ctx, settings.MoviesDir.String)
+	if err != nil {
+		app.Logger.Error(fmt.Sprintf("failed to remove missing movie files: %s", err.Error()))
+		errorCount++
+	}
+
+	app.Logger.Info(fmt.Sprintf("movies scanner completed: %d scanned, %d skipped, %d removed, %d errors in %s",
+		moviesScanned, moviesSkipped, removed, errorCount, helpers.FormatDuration(time.Since(startTime))))
+}
+
+// removeMissingMovieFiles deletes the media versions under dir whose file no longer
+// exists. A movie whose primary file is gone is pointed at its best remaining version
+// (the tallest, then the oldest), and deleted along with its last version.
+// Returns the number of versions removed.
+func (app *Application) removeMissingMovieFiles(ctx context.Context, dir string) (int, error) {
+	// An unmounted library would look as if every file was gone
+	if _, err := os.Stat(dir); err != nil {
+		return 0, fmt.Errorf("movies directory unavailable: %w", err)
+	}
+
+	versions, err := app.Queries.GetMediaVersionsByDirectory(ctx, filepath.Clean(dir)+string(filepath.Separator))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get media versions: %w", err)
+	}
+
+	// Stat outside the transaction so file I/O doesn't hold the scanner lock
+	missing := []database.GetMediaVersionsByDirectoryRow{}
+	for _, version := range versions {
+		if _, err := os.Stat(version.FilePath); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, version)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	for _, version := range missing {
+		if err := qtx.DeleteMediaVersionByFilePath(ctx, version.FilePath); err != nil {
+			return 0, fmt.Errorf("failed to delete media version %s: %w", version.FilePath, err)
+		}
+		app.clearScanError(ctx, qtx, version.FilePath)
+
+		movie, err := qtx.GetMovieByID(ctx, version.MovieID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get movie %d: %w", version.MovieID, err)
+		}
+		if movie.FilePath != version.FilePath {
+			continue
+		}
+
+		remaining, err := qtx.GetMediaVersionsByMovieID(ctx, movie.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get versions of movie %d: %w", movie.ID, err)
+		}
+
+		if len(remaining) == 0 {
+			if err := qtx.DeleteMovie(ctx, movie.ID); err != nil {
+				return 0, fmt.Errorf("failed to delete movie %d: %w", movie.ID, err)
+			}
+			continue
+		}
+
+		primary := remaining[0]
+		err = qtx.UpdateMovieFile(ctx, database.UpdateMovieFileParams{
+			FilePath:  primary.FilePath,
+			FileName:  primary.FileName,
+			Size:      primary.Size,
+			Container: primary.Container,
+			MimeType:  primary.MimeType,
+			ID:        movie.ID,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to promote %s: %w", primary.FilePath, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(missing), nil
+}
+
+// backfillMovieCollections links the TMDB-matched movies scanned before collections were
+// supported to their collection, once. Each batch of movies is fetched from TMDB before
+// its transaction so the scanners aren't blocked on the network.
+func (app *Application) backfillMovieCollections(ctx context.Context, cache *movieScannerCache) error {
+	settings := app.Settings()
+	if settings.CollectionsBackfilledAt.Valid || app.Tmdb == nil {
+		return nil
+	}
+
+	locale := app.movieMetadataLocale()
+
+	var afterID int64
+	for {
+		movies, err := app.Queries.GetMoviesWithoutCollection(ctx, database.GetMoviesWithoutCollectionParams{
+			AfterID: afterID,
+			Limit:   helpers.SCANNER_BATCH_SIZE,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get movies without collection: %w", err)
+		}
+
+		if len(movies) == 0 {
+			break
+		}
+		afterID = movies[len(movies)-1].ID
+
+		collections := map[int64]*tmdb.TmdbCollection{}
+		for _, movie := range movies {
+			tmdbMovie := &tmdb.TmdbMovie{TmdbID: int(movie.TmdbID.Int64)}
+			if err := app.Tmdb.GetTmdbMovieByID(tmdbMovie, locale); err != nil {
+				app.Logger.Warn("failed to get movie from tmdb", "error", err, "id", movie.ID, "tmdb_id", movie.TmdbID.Int64)
+				continue
+			}
+
+			if tmdbMovie.BelongsToCollection != nil && tmdbMovie.BelongsToCollection.ID != 0 {
+				collections[movie.ID] = tmdbMovie.BelongsToCollection
+			}
+		}
+
+		if err := app.linkMovieCollections(ctx, collections, cache); err != nil {
+			return err
+		}
+	}
+
+	updated, err := app.Queries.SetCollectionsBackfilled(ctx, settings.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save collections backfill: %w", err)
+	}
+	app.SetSettings(&updated)
+
+	return nil
+}
+
+// linkMovieCollections links movies, by id, to their collection in a single transaction.
+func (app *Application) linkMovieCollections(ctx context.Context, collections map[int64]*tmdb.TmdbCollection, cache *movieScannerCache) error {
+	if len(collections) == 0 {
+		return nil
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	for movieID, collection := range collections {
+		if err := app.processMovieCollection(ctx, qtx, movieID, collection, cache); err != nil {
+			return fmt.Errorf("failed to link movie %d to its collection: %w", movieID, err)
+		}
+	}
+
+	return tx.Commit()
 }
 
 // processMoviesBatch processes a batch of movie files within a single transaction.
@@ -112,6 +339,7 @@ func (app *Application) processMoviesBatch(ctx context.Context, files []movieFil
 	defer tx.Rollback()
 
 	qtx := app.Queries.WithTx(tx)
+	ledger := newScanLedger(helpers.SCAN_LIBRARY_MOVIES)
 
 	for _, file := range files {
 		// Check if movie exists with same path and size (file unchanged)
@@ -120,7 +348,7 @@ func (app *Application) processMoviesBatch(ctx context.Context, files []movieFil
 			Size:     file.size,
 		})
 
-		if err == nil {
+		if err == nil || checkSampleUnchanged(ctx, qtx, file.path, file.size) {
 			skipped++
 			continue
 		}
@@ -129,21 +357,38 @@ func (app *Application) processMoviesBatch(ctx context.Context, files []movieFil
 		// Use savepoint to allow per-movie rollback on failure while continuing with other movies
 		savepointName := fmt.Sprintf("sp_movie_%d", scanned+skipped+errCount)
 
+		// An incomplete file is kept: only its failure is recorded
+		var incomplete *incompleteScanError
 		err = manageSavepoint(ctx, tx, savepointName, func() error {
-
-			return app.processMovieFile(ctx, qtx, file.path, file.ext, file.size, cache)
+			err := app.processMovieFile(ctx, qtx, file.path, file.ext, file.size, cache)
+			if errors.As(err, &incomplete) {
+				return nil
+			}
+			return err
 		})
+		if err == nil && incomplete != nil {
+			err = incomplete
+		}
+
+		if errors.Is(err, errSampleFile) {
+			app.recordSampleFile(ctx, qtx, helpers.SCAN_LIBRARY_MOVIES, file.path, file.size)
+			skipped++
+			continue
+		}
 
 		if err != nil {
 			app.Logger.Error(fmt.Sprintf("failed to process %s: %s", file.path, err.Error()))
+			ledger.fail(file.path, err)
 			errCount++
 			continue
 		}
 
+		ledger.succeed(file.path)
 		scanned++
 	}
 
 	err = tx.Commit()
+	app.writeScanLedger(ctx, ledger, err)
 	if err != nil {
 		app.Logger.Error(fmt.Sprintf("failed to commit batch: %s", err.Error()))
 		return 0, 0, len(files)
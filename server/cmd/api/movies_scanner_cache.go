@@ -5,11 +5,12 @@ import (
 	"sync"
 )
 
-// movieScannerCache holds cached artists and production companies during movie scanning
-// to reduce TMDB API calls for frequently repeated entities.
+// movieScannerCache holds cached artists, production companies and collections during
+// movie scanning to reduce TMDB API calls for frequently repeated entities.
 type movieScannerCache struct {
 	artists            map[int]*database.Artist
 	productionCompanies map[int]*database.ProductionCompany
+	collections        map[int]*database.Collection
 	mu                 sync.RWMutex
 }
 
@@ -18,6 +19,7 @@ func newMovieScannerCache() *movieScannerCache {
 	return &movieScannerCache{
 		artists:             make(map[int]*database.Artist),
 		productionCompanies: make(map[int]*database.ProductionCompany),
+		collections:         make(map[int]*database.Collection),
 	}
 }
 
@@ -53,6 +55,33 @@ func (c *movieScannerCache) SetProductionCompany(tmdbID int, company *database.P
 	c.productionCompanies[tmdbID] = company
 }
 
+// GetCollection retrieves a cached collection by TMDB ID.
+// Returns the collection and true if found, nil and false otherwise.
+func (c *movieScannerCache) GetCollection(tmdbID int) (*database.Collection, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	collection, ok := c.collections[tmdbID]
+	return collection, ok
+}
+
+// SetCollection caches a collection by TMDB ID.
+func (c *movieScannerCache) SetCollection(tmdbID int, collection *database.Collection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collections[tmdbID] = collection
+}
+
+// Collections returns the cached collections.
+func (c *movieScannerCache) Collections() []*database.Collection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	collections := make([]*database.Collection, 0, len(c.collections))
+	for _, collection := range c.collections {
+		collections = append(collections, collection)
+	}
+	return collections
+}
+
 // Clear removes all cached data.
 // Should be called after scan completes to free memory.
 func (c *movieScannerCache) Clear() {
@@ -60,4 +89,5 @@ func (c *movieScannerCache) Clear() {
 	defer c.mu.Unlock()
 	c.artists = make(map[int]*database.Artist)
 	c.productionCompanies = make(map[int]*database.ProductionCompany)
+	c.collections = make(map[int]*database.Collection)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"igloo/cmd/internal/database"
@@ -9,7 +10,9 @@ import (
 	"strings"
 )
 
-// processProductionCompanies processes production companies from TMDB data.
+// processProductionCompanies links a movie to its production companies from TMDB data.
+// Only the changes are written: companies already linked and unchanged are left alone
+// and links to companies TMDB no longer lists are removed.
 func (app *Application) processProductionCompanies(
 	ctx context.Context,
 	qtx *database.Queries,
@@ -22,12 +25,25 @@ func (app *Application) processProductionCompanies(
 	},
 	cache *movieScannerCache,
 ) error {
-	// Delete all existing production company links
-	if err := qtx.DeleteMovieProductionCompanies(ctx, movieID); err != nil {
-		return fmt.Errorf("delete movie production companies failed: %w", err)
+	current, err := qtx.GetProductionCompaniesByMovieID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("get movie production companies failed: %w", err)
+	}
+
+	linked := make(map[int64]database.GetProductionCompaniesByMovieIDRow, len(current))
+	for _, company := range current {
+		linked[company.TmdbID] = company
 	}
 
 	for _, company := range companies {
+		existing, ok := linked[int64(company.ID)]
+		delete(linked, int64(company.ID))
+		if ok && existing.Name == company.Name &&
+			existing.Logo == buildTmdbImageURL(company.LogoPath) &&
+			existing.Country == helpers.NullString(company.OriginCountry) {
+			continue
+		}
+
 		// Check cache first
 		var dbCompany *database.ProductionCompany
 		cached, ok := cache.GetProductionCompany(company.ID)
@@ -62,10 +78,20 @@ func (app *Application) processProductionCompanies(
 		}
 	}
 
+	for _, stale := range linked {
+		if err := qtx.DeleteMovieProductionCompany(ctx, database.DeleteMovieProductionCompanyParams{
+			MovieID:             movieID,
+			ProductionCompanyID: stale.ID,
+		}); err != nil {
+			return fmt.Errorf("delete movie production company failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// processCast processes cast members from TMDB data.
+// processCast links a movie to its cast from TMDB data. Members already linked with the
+// same character are left alone and those TMDB no longer lists are removed.
 func (app *Application) processCast(
 	ctx context.Context,
 	qtx *database.Queries,
@@ -79,6 +105,17 @@ func (app *Application) processCast(
 	},
 	cache *movieScannerCache,
 ) error {
+	current, err := qtx.GetCastByMovieID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("get movie cast failed: %w", err)
+	}
+
+	type castKey struct{ artistID, order int64 }
+	linked := make(map[castKey]database.GetCastByMovieIDRow, len(current))
+	for _, member := range current {
+		linked[castKey{member.ArtistID, member.CastOrder}] = member
+	}
+
 	for _, castMember := range cast {
 		// Get or create artist from cache
 		artist, err := app.getOrCreateArtistFromCache(ctx, qtx, castMember.ID, castMember.Name, castMember.ProfilePath, cache)
@@ -86,6 +123,13 @@ func (app *Application) processCast(
 			return fmt.Errorf("get or create artist failed: %w", err)
 		}
 
+		key := castKey{artist.ID, int64(castMember.Order)}
+		existing, ok := linked[key]
+		delete(linked, key)
+		if ok && existing.Character == castMember.Character {
+			continue
+		}
+
 		// Upsert cast record
 		if _, err := qtx.UpsertCast(ctx, database.UpsertCastParams{
 			MovieID:   movieID,
@@ -97,10 +141,17 @@ func (app *Application) processCast(
 		}
 	}
 
+	for _, stale := range linked {
+		if err := qtx.DeleteCastMember(ctx, stale.ID); err != nil {
+			return fmt.Errorf("delete cast member failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// processCrew processes crew members from TMDB data.
+// processCrew links a movie to its crew from TMDB data. Members already linked are
+// left alone and those TMDB no longer lists are removed.
 func (app *Application) processCrew(
 	ctx context.Context,
 	qtx *database.Queries,
@@ -114,6 +165,20 @@ func (app *Application) processCrew(
 	},
 	cache *movieScannerCache,
 ) error {
+	current, err := qtx.GetCrewByMovieID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("get movie crew failed: %w", err)
+	}
+
+	type crewKey struct {
+		artistID        int64
+		job, department string
+	}
+	linked := make(map[crewKey]database.GetCrewByMovieIDRow, len(current))
+	for _, member := range current {
+		linked[crewKey{member.ArtistID, member.Job, member.Department}] = member
+	}
+
 	for _, crewMember := range crew {
 		// Get or create artist from cache
 		artist, err := app.getOrCreateArtistFromCache(ctx, qtx, crewMember.ID, crewMember.Name, crewMember.ProfilePath, cache)
@@ -121,6 +186,13 @@ func (app *Application) processCrew(
 			return fmt.Errorf("get or create artist failed: %w", err)
 		}
 
+		key := crewKey{artist.ID, crewMember.Job, crewMember.Department}
+		_, ok := linked[key]
+		delete(linked, key)
+		if ok {
+			continue
+		}
+
 		// Upsert crew record
 		if _, err := qtx.UpsertCrew(ctx, database.UpsertCrewParams{
 			MovieID:    movieID,
@@ -132,6 +204,12 @@ func (app *Application) processCrew(
 		}
 	}
 
+	for _, stale := range linked {
+		if err := qtx.DeleteCrewMember(ctx, stale.ID); err != nil {
+			return fmt.Errorf("delete crew member failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -201,31 +279,36 @@ func (app *Application) processExtraVideos(
 	return nil
 }
 
-// processMovieGenres processes genres from TMDB data.
+// processMovieGenres replaces the genres of a movie with the named ones. Only the
+// changes are written: genres already linked stay and the others are unlinked.
 func (app *Application) processMovieGenres(
 	ctx context.Context,
 	qtx *database.Queries,
 	movieID int64,
-	genres []struct {
-		ID   int    `json:"id"`
-		Name string `json:"name"`
-	},
+	genres []string,
 ) error {
-	// Delete all existing genre links
-	if err := qtx.DeleteMovieGenres(ctx, movieID); err != nil {
-		return fmt.Errorf("delete movie genres failed: %w", err)
+	current, err := qtx.GetGenresByMovieID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("get movie genres failed: %w", err)
+	}
+
+	linked := make(map[int64]bool, len(current))
+	for _, genre := range current {
+		linked[genre.ID] = true
 	}
 
 	for _, genre := range genres {
-		// Get or create genre with type "movie"
-		dbGenre, err := qtx.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{
-			Tag:       genre.Name,
-			GenreType: "movie",
-		})
+		// Resolve genre with type "movie" through its aliases, creating it if unknown
+		dbGenre, err := app.resolveGenre(ctx, qtx, genre, "movie")
 		if err != nil {
 			return fmt.Errorf("get or create genre failed: %w", err)
 		}
 
+		if linked[dbGenre.ID] {
+			delete(linked, dbGenre.ID)
+			continue
+		}
+
 		// Create movie-genre relationship
 		if err := qtx.CreateMovieGenre(ctx, database.CreateMovieGenreParams{
 			MovieID: movieID,
@@ -235,5 +318,167 @@ func (app *Application) processMovieGenres(
 		}
 	}
 
+	for genreID := range linked {
+		if err := qtx.DeleteMovieGenre(ctx, database.DeleteMovieGenreParams{
+			MovieID: movieID,
+			GenreID: genreID,
+		}); err != nil {
+			return fmt.Errorf("delete movie genre failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// processMovieCollection links a movie to its TMDB collection, or unlinks it when TMDB
+// puts it in none. The collection is either the movie's reference or, when it was
+// fetched before the transaction, the full collection whose parts are stored too. The
+// first movie of a collection in a scan stores it; see updateMovieCollections for the
+// overview and parts of the collections linked from references.
+func (app *Application) processMovieCollection(
+	ctx context.Context,
+	qtx *database.Queries,
+	movieID int64,
+	collection *tmdb.TmdbCollection,
+	cache *movieScannerCache,
+) error {
+	if collection == nil || collection.ID == 0 {
+		if err := qtx.DeleteMovieCollection(ctx, movieID); err != nil {
+			return fmt.Errorf("delete movie collection failed: %w", err)
+		}
+		return nil
+	}
+
+	dbCollection, ok := cache.GetCollection(collection.ID)
+	if !ok {
+		stored, err := app.storeCollection(ctx, qtx, collection)
+		if err != nil {
+			return err
+		}
+
+		dbCollection = &stored
+		cache.SetCollection(collection.ID, dbCollection)
+	}
+
+	if err := qtx.SetMovieCollection(ctx, database.SetMovieCollectionParams{
+		MovieID:      movieID,
+		CollectionID: dbCollection.ID,
+	}); err != nil {
+		return fmt.Errorf("set movie collection failed: %w", err)
+	}
+
+	return nil
+}
+
+// storeCollection upserts a TMDB collection. A reference has no overview nor parts, so
+// the ones stored by an earlier scan are kept.
+func (app *Application) storeCollection(ctx context.Context, qtx *database.Queries, collection *tmdb.TmdbCollection) (database.Collection, error) {
+	stored, err := qtx.UpsertCollection(ctx, database.UpsertCollectionParams{
+		TmdbID:       int64(collection.ID),
+		Name:         collection.Name,
+		Overview:     helpers.NullString(collection.Overview),
+		PosterPath:   helpers.NullString(collection.PosterPath),
+		BackdropPath: helpers.NullString(collection.BackdropPath),
+	})
+	if err != nil {
+		return stored, fmt.Errorf("upsert collection failed: %w", err)
+	}
+
+	if len(collection.Parts) > 0 {
+		if err := app.processCollectionParts(ctx, qtx, stored.ID, collection.Parts); err != nil {
+			return stored, err
+		}
+	}
+
+	return stored, nil
+}
+
+// fetchMovieCollection replaces the collection reference of a TMDB movie with the full
+// collection, so its overview and parts are stored along with the movie. The reference
+// is kept when the collection can't be fetched.
+func (app *Application) fetchMovieCollection(tmdbMovie *tmdb.TmdbMovie) {
+	ref := tmdbMovie.BelongsToCollection
+	if ref == nil || ref.ID == 0 {
+		return
+	}
+
+	collection, err := app.Tmdb.GetTmdbCollection(ref.ID, app.movieMetadataLocale())
+	if err != nil {
+		app.Logger.Warn("failed to get collection from tmdb", "error", err, "tmdb_id", ref.ID)
+		return
+	}
+
+	collection.Name = cmp.Or(collection.Name, ref.Name)
+	tmdbMovie.BelongsToCollection = collection
+}
+
+// updateMovieCollections fetches the overview and parts of the collections a scan linked
+// movies to and stores them. The collections are fetched before the transaction so the
+// scanners aren't blocked on the network; those that can't be fetched keep their reference.
+func (app *Application) updateMovieCollections(ctx context.Context, cache *movieScannerCache) error {
+	locale := app.movieMetadataLocale()
+
+	collections := []*tmdb.TmdbCollection{}
+	for _, stored := range cache.Collections() {
+		collection, err := app.Tmdb.GetTmdbCollection(int(stored.TmdbID), locale)
+		if err != nil {
+			app.Logger.Warn("failed to get collection from tmdb", "error", err, "tmdb_id", stored.TmdbID)
+			continue
+		}
+		collection.Name = cmp.Or(collection.Name, stored.Name)
+		collections = append(collections, collection)
+	}
+	if len(collections) == 0 {
+		return nil
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	for _, collection := range collections {
+		if _, err := app.storeCollection(ctx, qtx, collection); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// processCollectionParts replaces the parts of a collection with its movies on TMDB.
+func (app *Application) processCollectionParts(
+	ctx context.Context,
+	qtx *database.Queries,
+	collectionID int64,
+	parts []tmdb.TmdbMovie,
+) error {
+	if err := qtx.DeleteCollectionParts(ctx, collectionID); err != nil {
+		return fmt.Errorf("delete collection parts failed: %w", err)
+	}
+
+	for _, part := range parts {
+		if part.TmdbID == 0 {
+			continue
+		}
+
+		if err := qtx.CreateCollectionPart(ctx, database.CreateCollectionPartParams{
+			CollectionID: collectionID,
+			TmdbID:       int64(part.TmdbID),
+			Title:        part.Title,
+			ReleaseDate:  helpers.NullString(part.ReleaseDate),
+			Overview:     helpers.NullString(part.Overview),
+			PosterPath:   helpers.NullString(part.PosterPath),
+		}); err != nil {
+			return fmt.Errorf("create collection part failed: %w", err)
+		}
+	}
+
 	return nil
 }
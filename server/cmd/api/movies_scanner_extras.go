@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// movieExtraFile is a video file recognized as a local extra during the directory walk.
+type movieExtraFile struct {
+	movieFile
+	extra helpers.MovieExtra
+}
+
+// processMovieExtrasBatch processes a batch of local extras within a single transaction,
+// with the same skip-on-error strategy and locking as processMoviesBatch.
+func (app *Application) processMovieExtrasBatch(ctx context.Context, files []movieExtraFile) (scanned, skipped, errCount int) {
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, len(files)
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+	ledger := newScanLedger(helpers.SCAN_LIBRARY_MOVIES)
+
+	for _, file := range files {
+		// Check if extra exists with same path and size (file unchanged)
+		_, err = qtx.CheckLocalExtraUnchanged(ctx, database.CheckLocalExtraUnchangedParams{
+			FilePath: file.path,
+			Size:     file.size,
+		})
+
+		if err == nil {
+			skipped++
+			continue
+		}
+
+		savepointName := fmt.Sprintf("sp_extra_%d", scanned+skipped+errCount)
+
+		err = manageSavepoint(ctx, tx, savepointName, func() error {
+			return app.processMovieExtraFile(ctx, qtx, file)
+		})
+
+		if err != nil {
+			app.Logger.Error(fmt.Sprintf("failed to process extra %s: %s", file.path, err.Error()))
+			ledger.fail(file.path, err)
+			errCount++
+			continue
+		}
+
+		ledger.succeed(file.path)
+		scanned++
+	}
+
+	err = tx.Commit()
+	app.writeScanLedger(ctx, ledger, err)
+	if err != nil {
+		app.Logger.Error(fmt.Sprintf("failed to commit extras batch: %s", err.Error()))
+		return 0, 0, len(files)
+	}
+
+	return scanned, skipped, errCount
+}
+
+// processMovieExtraFile probes a local extra and attaches it to the movie it was found next to.
+func (app *Application) processMovieExtraFile(ctx context.Context, qtx *database.Queries, file movieExtraFile) error {
+	movieID, err := app.findMovieForExtra(ctx, qtx, file.extra)
+	if err != nil {
+		return scanPhaseError(helpers.SCAN_PHASE_MATCH, err)
+	}
+
+	info, err := app.Ffprobe.GetMetadata(file.path)
+	if err != nil {
+		return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("ffprobe failed (required): %w", err))
+	}
+
+	container, ok := helpers.DetectVideoContainer(info.Format.FormatName, file.ext)
+	if !ok {
+		return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("unsupported video container %q", info.Format.FormatName))
+	}
+
+	params := database.UpsertLocalExtraParams{
+		MovieID:   movieID,
+		Title:     file.extra.Title,
+		Type:      file.extra.Type,
+		FilePath:  file.path,
+		FileName:  filepath.Base(file.path),
+		Size:      file.size,
+		Container: container,
+		MimeType:  helpers.VideoMimeType(container),
+	}
+
+	if info.Format.Size != "" {
+		size, err := strconv.ParseInt(info.Format.Size, 10, 64)
+		if err == nil && size > 0 {
+			params.Size = size
+		}
+	}
+
+	if info.Format.Duration != "" {
+		duration, err := helpers.ParseDurationMs(info.Format.Duration)
+		if err == nil {
+			params.Duration = duration
+		}
+	}
+
+	_, err = qtx.UpsertLocalExtra(ctx, params)
+	if err != nil {
+		return fmt.Errorf("upsert local extra failed: %w", err)
+	}
+
+	return nil
+}
+
+// findMovieForExtra returns the movie stored in the extra's movie directory. An extra
+// named after one of several movies in the same directory ("Movie (2010)-trailer.mkv")
+// goes to that movie; otherwise the directory must hold a single movie.
+func (app *Application) findMovieForExtra(ctx context.Context, qtx *database.Queries, extra helpers.MovieExtra) (int64, error) {
+	versions, err := qtx.GetMediaVersionsByDirectory(ctx, extra.MovieDir+string(filepath.Separator))
+	if err != nil {
+		return 0, fmt.Errorf("get movies in directory failed: %w", err)
+	}
+
+	movieIDs := map[int64]bool{}
+	var movieID int64
+
+	for _, version := range versions {
+		// Only movies directly in the directory, not in its subdirectories
+		if filepath.Dir(version.FilePath) != extra.MovieDir {
+			continue
+		}
+
+		if extra.Prefix != "" {
+			_, name := helpers.ParseEdition(filepath.Base(version.FilePath))
+			name = strings.TrimSpace(strings.TrimSuffix(name, filepath.Ext(name)))
+			if strings.EqualFold(name, extra.Prefix) {
+				return version.MovieID, nil
+			}
+		}
+
+		movieIDs[version.MovieID] = true
+		movieID = version.MovieID
+	}
+
+	switch len(movieIDs) {
+	case 0:
+		return 0, fmt.Errorf("no movie found in %s", extra.MovieDir)
+	case 1:
+		return movieID, nil
+	default:
+		return 0, fmt.Errorf("several movies found in %s, can't tell which one the extra belongs to", extra.MovieDir)
+	}
+}
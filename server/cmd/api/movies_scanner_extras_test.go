@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"igloo/cmd/internal/helpers"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestProcessMovieExtrasBatch tests that extras folders and suffixed files are attached
+// to the movie next to them, and that extras without a movie are reported as errors.
+func TestProcessMovieExtrasBatch(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	// Extras folders are recognized next to a movie file, Heat is on disk but not scanned
+	root := t.TempDir()
+	moviePath := filepath.Join(root, "Alien (1979)", "Alien (1979).mkv")
+	for _, path := range []string{moviePath, filepath.Join(root, "Heat (1995)", "Heat (1995).mkv")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte("movie"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{moviePath: 1080}}
+
+	if err := app.processMovieFile(ctx, app.Queries, moviePath, "mkv", 1000, newMovieScannerCache()); err != nil {
+		t.Fatalf("processMovieFile failed: %v", err)
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, moviePath)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	files := []movieExtraFile{}
+	for _, path := range []string{
+		filepath.Join(root, "Alien (1979)", "Featurettes", "Making Of.mkv"),
+		filepath.Join(root, "Alien (1979)", "Alien (1979)-trailer.mkv"),
+		filepath.Join(root, "Heat (1995)", "Trailers", "Teaser.mkv"),
+	} {
+		extra, ok := helpers.ParseMovieExtra(path)
+		if !ok {
+			t.Fatalf("Expected %q to be an extra", path)
+		}
+
+		files = append(files, movieExtraFile{movieFile: movieFile{path: path, ext: "mkv", size: 1000}, extra: extra})
+	}
+
+	scanned, skipped, errCount := app.processMovieExtrasBatch(ctx, files)
+	if scanned != 2 || skipped != 0 || errCount != 1 {
+		t.Errorf("Expected 2 scanned, 0 skipped, 1 error, got %d, %d, %d", scanned, skipped, errCount)
+	}
+
+	extras, err := app.Queries.GetLocalExtrasByMovieID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get extras: %v", err)
+	}
+
+	if len(extras) != 2 {
+		t.Fatalf("Expected 2 extras, got %d", len(extras))
+	}
+
+	if extras[0].Type != "featurette" || extras[0].Title != "Making Of" {
+		t.Errorf("Expected the featurette first, got %+v", extras[0])
+	}
+
+	if extras[1].Type != "trailer" || extras[1].Container != "mkv" {
+		t.Errorf("Expected an mkv trailer second, got %+v", extras[1])
+	}
+
+	// Unchanged extras are skipped on the next scan
+	scanned, skipped, _ = app.processMovieExtrasBatch(ctx, files[:2])
+	if scanned != 0 || skipped != 2 {
+		t.Errorf("Expected 2 skipped on rescan, got %d scanned, %d skipped", scanned, skipped)
+	}
+}
+
+// TestStreamLocalExtra tests that an extra is streamed only through its own movie.
+func TestStreamLocalExtra(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	filePath := filepath.Join(t.TempDir(), "Alien (1979)-trailer.mp4")
+	if err := os.WriteFile(filePath, []byte("trailer"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := app.DB.Exec(`INSERT INTO movies (title, file_path, file_name, size, container, mime_type, adult)
+		VALUES ('Alien', '/movies/alien.mkv', 'alien.mkv', 1, 'mkv', 'video/x-matroska', 0)`)
+	if err != nil {
+		t.Fatalf("Failed to insert movie: %v", err)
+	}
+
+	movieID, _ := result.LastInsertId()
+
+	result, err = app.DB.Exec(`INSERT INTO local_extras (movie_id, title, type, file_path, file_name, size, container, mime_type, duration)
+		VALUES (?, 'Alien (1979)', 'trailer', ?, 'Alien (1979)-trailer.mp4', 7, 'mp4', 'video/mp4', 1000)`, movieID, filePath)
+	if err != nil {
+		t.Fatalf("Failed to insert extra: %v", err)
+	}
+
+	extraID, _ := result.LastInsertId()
+
+	tests := []struct {
+		name    string
+		movieID int64
+		status  int
+	}{
+		{"own movie", movieID, http.StatusOK},
+		{"other movie", movieID + 1, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/movies/1/extras/1/stream", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", strconv.FormatInt(tt.movieID, 10))
+			rctx.URLParams.Add("extraID", strconv.FormatInt(extraID, 10))
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			app.StreamLocalExtra(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+
+			if tt.status == http.StatusOK {
+				if rr.Body.String() != "trailer" || rr.Header().Get("Content-Type") != "video/mp4" {
+					t.Errorf("Expected the trailer as video/mp4, got %q (%s)", rr.Body.String(), rr.Header().Get("Content-Type"))
+				}
+			}
+		})
+	}
+}
@@ -2,24 +2,30 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"igloo/cmd/internal/database"
 	"igloo/cmd/internal/helpers"
 	"igloo/cmd/internal/tmdb"
-	"mime"
 	"path/filepath"
 	"strconv"
 	"strings"
 )
 
 // processMovieFile extracts metadata from a movie file and upserts it into the database.
-// Handles TMDB lookup, FFPROBE extraction, and related entities (cast, crew, genres, etc.).
+// Handles FFPROBE extraction, the metadata provider chain (NFO, embedded tags, TMDB),
+// and related entities (cast, crew, genres, etc.).
+// Files of a movie that is already in the library (same TMDB id, or same title and year
+// with an edition tag) become additional media versions of it rather than new movies.
+// A file stored without its TMDB metadata returns an *incompleteScanError.
 func (app *Application) processMovieFile(ctx context.Context, qtx *database.Queries, path, ext string, fileSize int64, cache *movieScannerCache) error {
-	// Step 1: Extract title and year from filename
-	titleYear, err := helpers.GetTitleAndYearFromFileName(filepath.Base(path))
+	// Step 1: Extract edition, title and year from filename
+	edition, baseName := helpers.ParseEdition(filepath.Base(path))
+
+	titleYear, err := helpers.GetTitleAndYearFromFileName(baseName)
 	if err != nil {
 		// Fallback: use filename without extension as title, year = 0
-		baseName := filepath.Base(path)
 		ext := filepath.Ext(baseName)
 		titleYear = &helpers.TitleYearResponse{
 			Title: strings.TrimSuffix(baseName, ext),
@@ -27,49 +33,64 @@ func (app *Application) processMovieFile(ctx context.Context, qtx *database.Quer
 		}
 	}
 
-	// Step 2: TMDB Search (if TMDB is configured)
-	var tmdbMovie *tmdb.TmdbMovie
-
-	if app.Tmdb != nil {
-		searchResults, err := app.Tmdb.SearchMoviesByTitleAndYear(titleYear.Title, titleYear.Year)
-		if err == nil && len(searchResults) > 0 {
-			bestMatch := selectBestTmdbMatch(searchResults, titleYear.Year)
-			if bestMatch == nil {
-				// No year match: use first result only if title is a plausible match (avoid wrong film)
-				first := &searchResults[0]
-				if titleMatchConfidence(titleYear.Title, first.Title) {
-					bestMatch = first
-				}
-			}
+	// Step 2: FFPROBE Metadata Extraction (required)
+	// Runs before the TMDB search so samples below the minimum duration cost no API calls
+	info, err := app.Ffprobe.GetMetadata(path)
+	if err != nil {
+		return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("ffprobe failed (required): %w", err))
+	}
 
-			if bestMatch != nil {
-				err = app.Tmdb.GetTmdbMovieByID(bestMatch)
-				if err == nil {
-					tmdbMovie = bestMatch
-				}
-			}
+	if info.Format.Duration != "" {
+		duration, err := helpers.ParseDurationMs(info.Format.Duration)
+		if err == nil && belowMinDuration(duration, app.Settings().MoviesMinDuration) {
+			return errSampleFile
 		}
 	}
 
-	// Step 4: FFPROBE Metadata Extraction (required)
-	info, err := app.Ffprobe.GetMetadata(path)
+	// Step 3: Metadata lookup through the library's provider chain
+	// A manual match is kept: its TMDB id is re-fetched instead of searched by file name
+	lockedID, err := lockedTmdbID(ctx, qtx, path)
 	if err != nil {
-		return fmt.Errorf("ffprobe failed (required): %w", err)
+		return fmt.Errorf("get manual match failed: %w", err)
 	}
 
-	// Step 5: Build movie parameters
-	mimeType := mime.TypeByExtension("." + ext)
-	if mimeType == "" {
-		mimeType = "application/octet-stream"
+	meta, tmdbErr := app.fetchMovieMetadata(ctx, app.movieMetadataChain(), MovieMetadataQuery{
+		Path:   path,
+		Title:  titleYear.Title,
+		Year:   titleYear.Year,
+		TmdbID: lockedID,
+		Info:   info,
+	})
+
+	// Step 4: Build movie parameters
+	// The container comes from the demuxer ffprobe picked, not the extension
+	container, ok := helpers.DetectVideoContainer(info.Format.FormatName, ext)
+	if !ok {
+		return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("unsupported video container %q", info.Format.FormatName))
 	}
 
 	params := database.UpsertMovieParams{
-		Title:     titleYear.Title,
-		FilePath:  path,
-		FileName:  filepath.Base(path),
-		Container: ext,
-		MimeType:  mimeType,
-		Adult:     false, // Default to false, will be set from TMDB if available
+		Title:         meta.Title,
+		OriginalTitle: helpers.NullString(meta.OriginalTitle),
+		FilePath:      path,
+		FileName:      filepath.Base(path),
+		Container:     container,
+		MimeType:      helpers.VideoMimeType(container),
+		Adult:         meta.Adult,
+		TmdbID:        helpers.NullInt64(meta.TmdbID),
+		ImdbID:        helpers.NullString(meta.ImdbID),
+		PosterPath:    helpers.NullString(meta.PosterPath),
+		BackdropPath:  helpers.NullString(meta.BackdropPath),
+		Language:      helpers.NullString(meta.Language),
+		Year:          helpers.NullInt64(int64(meta.Year)),
+		ReleaseDate:   helpers.NullString(meta.ReleaseDate),
+		Overview:      helpers.NullString(meta.Overview),
+		TagLine:       helpers.NullString(meta.Tagline),
+		Certification: helpers.NullString(meta.Certification),
+		CriticRating:  helpers.NullFloat64(meta.CriticRating),
+		Revenue:       helpers.NullFloat64(float64(meta.Revenue)),
+		Budget:        helpers.NullFloat64(float64(meta.Budget)),
+		RunTime:       helpers.NullInt64(int64(meta.Runtime)),
 	}
 
 	// Parse size from FFPROBE, fallback to fileSize from directory walk
@@ -81,93 +102,224 @@ func (app *Application) processMovieFile(ctx context.Context, qtx *database.Quer
 		}
 	}
 
-	// Map TMDB data if available
-	if tmdbMovie != nil {
-		params.TmdbID = helpers.NullInt64(int64(tmdbMovie.TmdbID))
-		params.ImdbID = helpers.NullString(tmdbMovie.ImdbID)
-		params.PosterPath = helpers.NullString(tmdbMovie.PosterPath)
-		params.BackdropPath = helpers.NullString(tmdbMovie.BackdropPath)
-		params.Title = tmdbMovie.Title
-		params.Adult = tmdbMovie.Adult
-		params.Language = helpers.NullString(tmdbMovie.OriginalLang)
-		params.Overview = helpers.NullString(tmdbMovie.Overview)
-		params.TagLine = helpers.NullString(tmdbMovie.Tagline)
-		params.Certification = helpers.NullString(tmdbMovie.Certification())
-		params.CriticRating = helpers.NullFloat64(tmdbMovie.VoteAverage)
-		params.Revenue = helpers.NullFloat64(float64(tmdbMovie.Revenue))
-		params.Budget = helpers.NullFloat64(float64(tmdbMovie.Budget))
-		params.RunTime = helpers.NullInt64(int64(tmdbMovie.Runtime))
-
-		// Parse release date
-		if tmdbMovie.ReleaseDate != "" {
-			params.ReleaseDate = helpers.NullString(tmdbMovie.ReleaseDate)
-			// Extract year from release date
-			if year := extractYearFromReleaseDate(tmdbMovie.ReleaseDate); year > 0 {
-				params.Year = helpers.NullInt64(int64(year))
-			}
+	// Step 5: Find the movie this file is a version of, or upsert a new one.
+	// The movie row (and its cast, crew, genres...) belongs to its first file, so
+	// additional versions only fill the fields it left empty, then add a
+	// media_versions row and their own streams.
+	movie, found, err := app.findMovieForVersion(ctx, qtx, path, edition, params)
+	if err != nil {
+		return fmt.Errorf("find movie failed: %w", err)
+	}
+
+	primary := !found || movie.FilePath == path
+	// Entities are only taken from another version when it brings the TMDB match the
+	// primary file didn't have
+	withEntities := primary || (!movie.TmdbID.Valid && params.TmdbID.Valid)
+	if primary {
+		movie, err = qtx.UpsertMovie(ctx, params)
+		if err != nil {
+			return fmt.Errorf("upsert movie failed: %w", err)
 		}
 	} else {
-		// Use year from filename if TMDB not available
-		if titleYear.Year > 0 {
-			params.Year = helpers.NullInt64(int64(titleYear.Year))
+		movie, err = qtx.FillMovieMetadata(ctx, database.FillMovieMetadataParams{
+			OriginalTitle: params.OriginalTitle,
+			TmdbID:        params.TmdbID,
+			ImdbID:        params.ImdbID,
+			PosterPath:    params.PosterPath,
+			BackdropPath:  params.BackdropPath,
+			Language:      params.Language,
+			Year:          params.Year,
+			ReleaseDate:   params.ReleaseDate,
+			Overview:      params.Overview,
+			TagLine:       params.TagLine,
+			Certification: params.Certification,
+			CriticRating:  params.CriticRating,
+			Revenue:       params.Revenue,
+			Budget:        params.Budget,
+			RunTime:       params.RunTime,
+			ID:            movie.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("fill movie metadata failed: %w", err)
+		}
+	}
+
+	version, err := qtx.UpsertMediaVersion(ctx, database.UpsertMediaVersionParams{
+		MovieID:   movie.ID,
+		FilePath:  path,
+		FileName:  params.FileName,
+		Size:      params.Size,
+		Container: params.Container,
+		MimeType:  params.MimeType,
+		Edition:   helpers.NullString(edition),
+	})
+	if err != nil {
+		return fmt.Errorf("upsert media version failed: %w", err)
+	}
+
+	// Step 6: Process related entities
+	if withEntities {
+		if err := app.processMovieEntities(ctx, qtx, movie, meta, cache); err != nil {
+			return scanPhaseError(helpers.SCAN_PHASE_TMDB, err)
+		}
+	}
+
+	// Step 7: Process streams and chapters (from FFPROBE)
+	// Video streams are required - if none found, skip movie (invalid file)
+	videoStreamCount, err := app.processMovieStreams(ctx, qtx, movie.ID, version.ID, info.Streams)
+	if err != nil {
+		return fmt.Errorf("process movie streams failed: %w", err)
+	}
+	if videoStreamCount == 0 {
+		return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("no video stream found - invalid movie file"))
+	}
+
+	if err := app.processChapters(ctx, qtx, movie.ID, version.ID, info.Chapters); err != nil {
+		return fmt.Errorf("process chapters failed: %w", err)
+	}
+
+	// The file is kept with what the other providers knew, and scanned again later
+	if tmdbErr != nil {
+		return &incompleteScanError{err: tmdbErr}
+	}
+
+	return nil
+}
+
+// findMovieForVersion looks up the movie a file belongs to: the movie with the same
+// TMDB id, else the movie the file was grouped into on an earlier scan, else (for
+// edition-tagged files without a TMDB match) the movie with the same title and year.
+// Returns false when the file is a new movie.
+func (app *Application) findMovieForVersion(
+	ctx context.Context,
+	qtx *database.Queries,
+	path string,
+	edition string,
+	params database.UpsertMovieParams,
+) (database.Movie, bool, error) {
+	if params.TmdbID.Valid {
+		movie, err := qtx.GetMovieByTmdbID(ctx, params.TmdbID)
+		if err == nil {
+			return movie, true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.Movie{}, false, err
 		}
 	}
 
-	// Step 6: Upsert movie
-	movie, err := qtx.UpsertMovie(ctx, params)
+	version, err := qtx.GetMediaVersionByFilePath(ctx, path)
+	if err == nil {
+		movie, err := qtx.GetMovieByID(ctx, version.MovieID)
+		if err == nil {
+			return movie, true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.Movie{}, false, err
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return database.Movie{}, false, err
+	}
+
+	if edition != "" && !params.TmdbID.Valid {
+		movie, err := qtx.GetMovieByTitleAndYear(ctx, database.GetMovieByTitleAndYearParams{
+			Title: params.Title,
+			Year:  params.Year,
+		})
+		if err == nil {
+			return movie, true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.Movie{}, false, err
+		}
+	}
+
+	return database.Movie{}, false, nil
+}
+
+// lockedTmdbID returns the TMDB id of a manually matched movie the file already belongs
+// to, or 0 when the file is new or its movie was matched automatically.
+func lockedTmdbID(ctx context.Context, qtx *database.Queries, path string) (int64, error) {
+	version, err := qtx.GetMediaVersionByFilePath(ctx, path)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	movie, err := qtx.GetMovieByID(ctx, version.MovieID)
 	if err != nil {
-		return fmt.Errorf("upsert movie failed: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if !movie.MatchLocked || !movie.TmdbID.Valid {
+		return 0, nil
 	}
 
-	// Step 7: Process related entities (only if TMDB data available)
+	return movie.TmdbID.Int64, nil
+}
+
+// processTmdbEntities links a movie to the production companies, cast, crew, genres,
+// extra videos and collection of its TMDB entry. Genres edited by hand are kept.
+func (app *Application) processTmdbEntities(ctx context.Context, qtx *database.Queries, movie database.Movie, tmdbMovie *tmdb.TmdbMovie, cache *movieScannerCache) error {
+	return app.processMovieEntities(ctx, qtx, movie, movieMetadataFromTmdb(tmdbMovie, app.movieMetadataLocale().Country), cache)
+}
+
+// processMovieEntities links a movie to its genres and, when it was found on TMDB, the
+// production companies, cast, crew, extra videos and collection of its TMDB entry. Genres edited
+// by hand are kept, and so are those of a movie no provider knows genres for.
+func (app *Application) processMovieEntities(ctx context.Context, qtx *database.Queries, movie database.Movie, meta *MovieMetadata, cache *movieScannerCache) error {
+	movieID := movie.ID
+	tmdbMovie := meta.Tmdb
+
 	if tmdbMovie != nil {
 		// Process production companies
-		if err := app.processProductionCompanies(ctx, qtx, movie.ID, tmdbMovie.ProductionCompanies, cache); err != nil {
+		if err := app.processProductionCompanies(ctx, qtx, movieID, tmdbMovie.ProductionCompanies, cache); err != nil {
 			return fmt.Errorf("process production companies failed: %w", err)
 		}
 
 		// Process cast
-		if err := app.processCast(ctx, qtx, movie.ID, tmdbMovie.Credits.Cast, cache); err != nil {
+		if err := app.processCast(ctx, qtx, movieID, tmdbMovie.Credits.Cast, cache); err != nil {
 			return fmt.Errorf("process cast failed: %w", err)
 		}
 
 		// Process crew
-		if err := app.processCrew(ctx, qtx, movie.ID, tmdbMovie.Credits.Crew, cache); err != nil {
+		if err := app.processCrew(ctx, qtx, movieID, tmdbMovie.Credits.Crew, cache); err != nil {
 			return fmt.Errorf("process crew failed: %w", err)
 		}
+	}
 
-		// Process genres
-		if err := app.processMovieGenres(ctx, qtx, movie.ID, tmdbMovie.Genres); err != nil {
+	// Process genres
+	if len(meta.Genres) > 0 && !helpers.IsFieldLocked(movie.LockedFields, "genres") {
+		if err := app.processMovieGenres(ctx, qtx, movieID, meta.Genres); err != nil {
 			return fmt.Errorf("process genres failed: %w", err)
 		}
+	}
 
-		// Process extra videos (trailers, special features)
-		if err := app.processExtraVideos(ctx, qtx, movie.ID, tmdbMovie.Videos.Results); err != nil {
+	// Process extra videos (trailers, special features)
+	if tmdbMovie != nil {
+		if err := app.processExtraVideos(ctx, qtx, movieID, tmdbMovie.Videos.Results); err != nil {
 			return fmt.Errorf("process extra videos failed: %w", err)
 		}
-	}
-
-	// Step 8: Process streams and chapters (from FFPROBE)
-	// Video streams are required - if none found, skip movie (invalid file)
-	videoStreamCount, err := app.processMovieStreams(ctx, qtx, movie.ID, info.Streams)
-	if err != nil {
-		return fmt.Errorf("process movie streams failed: %w", err)
-	}
-	if videoStreamCount == 0 {
-		return fmt.Errorf("no video stream found - invalid movie file")
-	}
 
-	if err := app.processChapters(ctx, qtx, movie.ID, info.Chapters); err != nil {
-		return fmt.Errorf("process chapters failed: %w", err)
+		// Process collection
+		if err := app.processMovieCollection(ctx, qtx, movieID, tmdbMovie.BelongsToCollection, cache); err != nil {
+			return fmt.Errorf("process collection failed: %w", err)
+		}
 	}
 
 	return nil
 }
 
 // titleMatchConfidence returns true if the search title (from filename) plausibly
-// matches the TMDB movie title (e.g. one contains the other after normalizing),
-// to avoid assigning the wrong film when falling back to "first result".
-func titleMatchConfidence(searchTitle, movieTitle string) bool {
+// matches one of the TMDB movie's titles (e.g. one contains the other after
+// normalizing), to avoid assigning the wrong film when falling back to "first result".
+// The movie's original title counts too, as files are often named after it while
+// TMDB answers in the library's language. Empty titles are skipped.
+func titleMatchConfidence(searchTitle string, movieTitles ...string) bool {
 	norm := func(s string) string {
 		s = strings.ToLower(strings.TrimSpace(s))
 		var b strings.Builder
@@ -182,11 +334,22 @@ func titleMatchConfidence(searchTitle, movieTitle string) bool {
 		}
 		return strings.Join(strings.Fields(b.String()), " ")
 	}
-	s, m := norm(searchTitle), norm(movieTitle)
-	if len(s) < 2 || len(m) < 2 {
+	s := norm(searchTitle)
+	if len(s) < 2 {
 		return true
 	}
-	return strings.Contains(s, m) || strings.Contains(m, s)
+
+	for _, movieTitle := range movieTitles {
+		if movieTitle == "" {
+			continue
+		}
+
+		m := norm(movieTitle)
+		if len(m) < 2 || strings.Contains(s, m) || strings.Contains(m, s) {
+			return true
+		}
+	}
+	return false
 }
 
 // selectBestTmdbMatch selects the best matching movie from TMDB search results when
@@ -208,7 +371,7 @@ func selectBestTmdbMatch(results []tmdb.TmdbMovie, targetYear int) *tmdb.TmdbMov
 			if movieYear != targetYear {
 				continue
 			}
-			score := helpers.TMDB_YEAR_MATCH_SCORE + movie.Popularity + movie.VoteAverage*10
+			score := tmdbMatchScore(movie, targetYear)
 			if score > bestScore {
 				bestScore = score
 				bestMatch = movie
@@ -222,7 +385,7 @@ func selectBestTmdbMatch(results []tmdb.TmdbMovie, targetYear int) *tmdb.TmdbMov
 	var bestScore float64 = -1
 	for i := range results {
 		movie := &results[i]
-		score := movie.Popularity + movie.VoteAverage*10
+		score := tmdbMatchScore(movie, targetYear)
 		if score > bestScore {
 			bestScore = score
 			bestMatch = movie
@@ -230,3 +393,13 @@ func selectBestTmdbMatch(results []tmdb.TmdbMovie, targetYear int) *tmdb.TmdbMov
 	}
 	return bestMatch
 }
+
+// tmdbMatchScore ranks a TMDB search result by popularity and vote average, with
+// TMDB_YEAR_MATCH_SCORE added when its release year is targetYear.
+func tmdbMatchScore(movie *tmdb.TmdbMovie, targetYear int) float64 {
+	score := movie.Popularity + movie.VoteAverage*10
+	if targetYear > 0 && extractYearFromReleaseDate(movie.ReleaseDate) == targetYear {
+		score += helpers.TMDB_YEAR_MATCH_SCORE
+	}
+	return score
+}
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
 	"igloo/cmd/internal/tmdb"
 )
 
@@ -265,3 +272,180 @@ func TestSelectBestTmdbMatch(t *testing.T) {
 		}
 	})
 }
+
+func TestTitleMatchConfidence(t *testing.T) {
+	tests := []struct {
+		name        string
+		searchTitle string
+		titles      []string
+		expected    bool
+	}{
+		{"same title", "alien", []string{"Alien"}, true},
+		{"contained", "alien", []string{"Alien: Romulus"}, true},
+		{"other film", "alien", []string{"Predator"}, false},
+		{"original title", "alien", []string{"Alien, le huitième passager", "Alien"}, true},
+		{"no original title", "heat", []string{"Predator", ""}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := titleMatchConfidence(tt.searchTitle, tt.titles...); got != tt.expected {
+				t.Errorf("titleMatchConfidence(%q, %q) = %v, want %v", tt.searchTitle, tt.titles, got, tt.expected)
+			}
+		})
+	}
+}
+
+// fakeFfprobe returns a fixed Matroska result with one video stream of the configured height per path.
+type fakeFfprobe struct {
+	heights map[string]int
+}
+
+func (f *fakeFfprobe) GetMetadata(filePath string) (*ffprobe.FfprobeResult, error) {
+	return &ffprobe.FfprobeResult{
+		Format: ffprobe.Format{FormatName: "matroska,webm", Size: "1000"},
+		Streams: []ffprobe.Stream{
+			{Index: 0, CodecType: "video", CodecName: "hevc", Width: f.heights[filePath] * 16 / 9, Height: f.heights[filePath]},
+			{Index: 1, CodecType: "audio", CodecName: "aac", Channels: 2},
+		},
+	}, nil
+}
+
+// TestProcessMovieFile_GroupsEditions tests that edition-tagged files of the same title
+// and year become media versions of one movie, each with its own streams.
+func TestProcessMovieFile_GroupsEditions(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	theatrical := "/movies/Alien (1979).mkv"
+	directorsCut := "/movies/Alien (1979) {edition-Director's Cut}.mkv"
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{theatrical: 2160, directorsCut: 1080}}
+
+	cache := newMovieScannerCache()
+	for _, path := range []string{theatrical, directorsCut} {
+		if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, cache); err != nil {
+			t.Fatalf("processMovieFile(%q) failed: %v", path, err)
+		}
+	}
+
+	// Re-scanning a version keeps it in the same movie
+	if err := app.processMovieFile(ctx, app.Queries, directorsCut, "mkv", 1000, cache); err != nil {
+		t.Fatalf("processMovieFile rescan failed: %v", err)
+	}
+
+	var movies int
+	if err := app.DB.QueryRow("SELECT COUNT(*) FROM movies").Scan(&movies); err != nil {
+		t.Fatalf("Failed to count movies: %v", err)
+	}
+
+	if movies != 1 {
+		t.Fatalf("Expected 1 movie, got %d", movies)
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, theatrical)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.Title != "Alien" {
+		t.Errorf("Expected title %q, got %q", "Alien", movie.Title)
+	}
+
+	versions, err := app.Queries.GetMediaVersionsByMovieID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get versions: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %d", len(versions))
+	}
+
+	// Ordered by height, tallest first
+	if versions[0].FilePath != theatrical || versions[0].Edition.Valid {
+		t.Errorf("Expected first version to be the untagged 2160p file, got %+v", versions[0])
+	}
+
+	if versions[1].FilePath != directorsCut || versions[1].Edition.String != "Director's Cut" {
+		t.Errorf("Expected second version to be the Director's Cut, got %+v", versions[1])
+	}
+
+	for _, version := range versions {
+		streams, err := app.Queries.GetVideoStreamsByMediaVersionID(ctx, helpers.NullInt64(version.ID))
+		if err != nil {
+			t.Fatalf("Failed to get video streams: %v", err)
+		}
+
+		if len(streams) != 1 || streams[0].Height != version.Height.Int64 {
+			t.Errorf("Expected one video stream for version %d, got %+v", version.ID, streams)
+		}
+	}
+}
+
+// TestRemoveMissingMovieFiles tests that a movie whose primary file is gone is pointed
+// at its remaining version, and removed once its last version is gone too.
+func TestRemoveMissingMovieFiles(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	theatrical := filepath.Join(dir, "Alien (1979).mkv")
+	directorsCut := filepath.Join(dir, "Alien (1979) {edition-Director's Cut}.mkv")
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{theatrical: 2160, directorsCut: 1080}}
+
+	cache := newMovieScannerCache()
+	for _, path := range []string{theatrical, directorsCut} {
+		if err := os.WriteFile(path, []byte("movie"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+		if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, cache); err != nil {
+			t.Fatalf("processMovieFile(%q) failed: %v", path, err)
+		}
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, theatrical)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if err := os.Remove(theatrical); err != nil {
+		t.Fatalf("Failed to remove %s: %v", theatrical, err)
+	}
+
+	removed, err := app.removeMissingMovieFiles(ctx, dir)
+	if err != nil || removed != 1 {
+		t.Fatalf("Expected 1 version removed, got %d: %v", removed, err)
+	}
+
+	promoted, err := app.Queries.GetMovieByID(ctx, movie.ID)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+	if promoted.FilePath != directorsCut {
+		t.Errorf("Expected the Director's Cut to become the primary file, got %q", promoted.FilePath)
+	}
+
+	// Re-scanning the promoted version updates the movie as its primary file
+	if err := app.processMovieFile(ctx, app.Queries, directorsCut, "mkv", 1000, cache); err != nil {
+		t.Fatalf("processMovieFile rescan failed: %v", err)
+	}
+
+	if err := os.Remove(directorsCut); err != nil {
+		t.Fatalf("Failed to remove %s: %v", directorsCut, err)
+	}
+
+	if _, err := app.removeMissingMovieFiles(ctx, dir); err != nil {
+		t.Fatalf("removeMissingMovieFiles failed: %v", err)
+	}
+
+	if _, err := app.Queries.GetMovieByID(ctx, movie.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected the movie to be removed with its last version, got %v", err)
+	}
+
+	// A library that is unavailable is left alone
+	if _, err := app.removeMissingMovieFiles(ctx, filepath.Join(dir, "unmounted")); err == nil {
+		t.Error("Expected an error for a missing movies directory")
+	}
+}
@@ -10,37 +10,38 @@ import (
 	"strconv"
 )
 
-// processMovieStreams processes all video, audio, and subtitle streams from FFPROBE data
-// in a single pass. Returns the number of video streams processed and an error.
+// processMovieStreams processes all video, audio, and subtitle streams of one media
+// version from FFPROBE data in a single pass. Returns the number of video streams processed and an error.
 func (app *Application) processMovieStreams(
 	ctx context.Context,
 	qtx *database.Queries,
 	movieID int64,
+	versionID int64,
 	streams []ffprobe.Stream,
 ) (videoStreamCount int, err error) {
-	if err := qtx.DeleteMovieVideoStreams(ctx, movieID); err != nil {
-		return 0, fmt.Errorf("delete movie video streams failed: %w", err)
+	if err := qtx.DeleteMediaVersionVideoStreams(ctx, helpers.NullInt64(versionID)); err != nil {
+		return 0, fmt.Errorf("delete version video streams failed: %w", err)
 	}
-	if err := qtx.DeleteMovieAudioStreams(ctx, movieID); err != nil {
-		return 0, fmt.Errorf("delete movie audio streams failed: %w", err)
+	if err := qtx.DeleteMediaVersionAudioStreams(ctx, helpers.NullInt64(versionID)); err != nil {
+		return 0, fmt.Errorf("delete version audio streams failed: %w", err)
 	}
-	if err := qtx.DeleteMovieSubtitles(ctx, movieID); err != nil {
-		return 0, fmt.Errorf("delete movie subtitles failed: %w", err)
+	if err := qtx.DeleteMediaVersionSubtitles(ctx, helpers.NullInt64(versionID)); err != nil {
+		return 0, fmt.Errorf("delete version subtitles failed: %w", err)
 	}
 
 	for _, stream := range streams {
 		switch stream.CodecType {
 		case "video":
-			if err := app.insertVideoStream(ctx, qtx, movieID, stream); err != nil {
+			if err := app.insertVideoStream(ctx, qtx, movieID, versionID, stream); err != nil {
 				return 0, err
 			}
 			videoStreamCount++
 		case "audio":
-			if err := app.insertAudioStream(ctx, qtx, movieID, stream); err != nil {
+			if err := app.insertAudioStream(ctx, qtx, movieID, versionID, stream); err != nil {
 				return 0, err
 			}
 		case "subtitle":
-			if err := app.insertSubtitleStream(ctx, qtx, movieID, stream); err != nil {
+			if err := app.insertSubtitleStream(ctx, qtx, movieID, versionID, stream); err != nil {
 				return 0, err
 			}
 		}
@@ -49,7 +50,7 @@ func (app *Application) processMovieStreams(
 	return videoStreamCount, nil
 }
 
-func (app *Application) insertVideoStream(ctx context.Context, qtx *database.Queries, movieID int64, stream ffprobe.Stream) error {
+func (app *Application) insertVideoStream(ctx context.Context, qtx *database.Queries, movieID, versionID int64, stream ffprobe.Stream) error {
 	bitRate := helpers.ParseBitRate(stream.BitRate)
 	var codecLevel sql.NullInt64
 	if stream.Level > 0 {
@@ -72,6 +73,7 @@ func (app *Application) insertVideoStream(ctx context.Context, qtx *database.Que
 
 	_, err := qtx.InsertVideoStream(ctx, database.InsertVideoStreamParams{
 		MovieID:        movieID,
+		MediaVersionID: helpers.NullInt64(versionID),
 		StreamIndex:    int64(stream.Index),
 		Codec:          stream.CodecName,
 		CodecProfile:   helpers.NullString(stream.Profile),
@@ -98,7 +100,7 @@ func (app *Application) insertVideoStream(ctx context.Context, qtx *database.Que
 	return nil
 }
 
-func (app *Application) insertAudioStream(ctx context.Context, qtx *database.Queries, movieID int64, stream ffprobe.Stream) error {
+func (app *Application) insertAudioStream(ctx context.Context, qtx *database.Queries, movieID, versionID int64, stream ffprobe.Stream) error {
 	bitRate := helpers.ParseBitRate(stream.BitRate)
 	var sampleRate sql.NullInt64
 	if stream.SampleRate != "" {
@@ -107,16 +109,17 @@ func (app *Application) insertAudioStream(ctx context.Context, qtx *database.Que
 		}
 	}
 	_, err := qtx.InsertAudioStream(ctx, database.InsertAudioStreamParams{
-		MovieID:       movieID,
-		StreamIndex:   int64(stream.Index),
-		Codec:         stream.CodecName,
-		CodecProfile:  helpers.NullString(stream.Profile),
-		BitRate:       bitRate,
-		SampleRate:    sampleRate,
-		Channels:      int64(stream.Channels),
-		ChannelLayout: helpers.NullString(stream.ChannelLayout),
-		Language:      helpers.NullString(stream.Tags.Language),
-		Title:         helpers.NullString(stream.Tags.Title),
+		MovieID:        movieID,
+		MediaVersionID: helpers.NullInt64(versionID),
+		StreamIndex:    int64(stream.Index),
+		Codec:          stream.CodecName,
+		CodecProfile:   helpers.NullString(stream.Profile),
+		BitRate:        bitRate,
+		SampleRate:     sampleRate,
+		Channels:       int64(stream.Channels),
+		ChannelLayout:  helpers.NullString(stream.ChannelLayout),
+		Language:       helpers.NullString(stream.Tags.Language),
+		Title:          helpers.NullString(stream.Tags.Title),
 	})
 	if err != nil {
 		return fmt.Errorf("insert audio stream failed: %w", err)
@@ -124,15 +127,16 @@ func (app *Application) insertAudioStream(ctx context.Context, qtx *database.Que
 	return nil
 }
 
-func (app *Application) insertSubtitleStream(ctx context.Context, qtx *database.Queries, movieID int64, stream ffprobe.Stream) error {
+func (app *Application) insertSubtitleStream(ctx context.Context, qtx *database.Queries, movieID, versionID int64, stream ffprobe.Stream) error {
 	_, err := qtx.InsertSubtitle(ctx, database.InsertSubtitleParams{
-		MovieID:     movieID,
-		StreamIndex: int64(stream.Index),
-		Codec:       stream.CodecName,
-		Language:    helpers.NullString(stream.Tags.Language),
-		Title:       helpers.NullString(stream.Tags.Title),
-		IsForced:    false,
-		IsDefault:   false,
+		MovieID:        movieID,
+		MediaVersionID: helpers.NullInt64(versionID),
+		StreamIndex:    int64(stream.Index),
+		Codec:          stream.CodecName,
+		Language:       helpers.NullString(stream.Tags.Language),
+		Title:          helpers.NullString(stream.Tags.Title),
+		IsForced:       false,
+		IsDefault:      false,
 	})
 	if err != nil {
 		return fmt.Errorf("insert subtitle failed: %w", err)
@@ -140,16 +144,17 @@ func (app *Application) insertSubtitleStream(ctx context.Context, qtx *database.
 	return nil
 }
 
-// processChapters processes chapters from FFPROBE data.
+// processChapters processes the chapters of one media version from FFPROBE data.
 func (app *Application) processChapters(
 	ctx context.Context,
 	qtx *database.Queries,
 	movieID int64,
+	versionID int64,
 	chapters []ffprobe.Chapter,
 ) error {
-	// Delete all existing chapters
-	if err := qtx.DeleteMovieChapters(ctx, helpers.NullInt64(movieID)); err != nil {
-		return fmt.Errorf("delete movie chapters failed: %w", err)
+	// Delete all existing chapters of this version
+	if err := qtx.DeleteMediaVersionChapters(ctx, helpers.NullInt64(versionID)); err != nil {
+		return fmt.Errorf("delete version chapters failed: %w", err)
 	}
 
 	for _, chapter := range chapters {
@@ -166,10 +171,11 @@ func (app *Application) processChapters(
 
 		// Leave thumb empty (chapter thumbnail generation will be implemented later)
 		_, err := qtx.InsertChapter(ctx, database.InsertChapterParams{
-			MovieID:   helpers.NullInt64(movieID),
-			Title:     chapter.Tags.Title,
-			StartTime: startTime,
-			Thumb:     sql.NullString{}, // Empty for now
+			MovieID:        helpers.NullInt64(movieID),
+			MediaVersionID: helpers.NullInt64(versionID),
+			Title:          chapter.Tags.Title,
+			StartTime:      startTime,
+			Thumb:          sql.NullString{}, // Empty for now
 		})
 		if err != nil {
 			return fmt.Errorf("insert chapter failed: %w", err)
@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"igloo/cmd/internal/database"
 	"igloo/cmd/internal/helpers"
 	"io/fs"
+	"maps"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -16,6 +19,10 @@ type trackFile struct {
 	path string
 	ext  string
 	size int64
+	// cue is set when a CUE sheet splits the file into virtual tracks
+	cue *cueAudioFile
+	// lyrics is set when a .lrc file with the same name sits next to the file
+	lyrics *lyricsSidecar
 }
 
 // ScanMusicLibrary walks through the configured music directory, extracts metadata
@@ -26,12 +33,13 @@ func (app *Application) ScanMusicLibrary() {
 		defer app.Wait.Done()
 	}
 
-	if !app.Settings.MusicDir.Valid || app.Settings.MusicDir.String == "" {
+	settings := app.Settings()
+	if !settings.MusicDir.Valid || settings.MusicDir.String == "" {
 		app.Logger.Error("music directory not configured")
 		return
 	}
 
-	app.Logger.Info(fmt.Sprintf("scanning music directory: %s", app.Settings.MusicDir.String))
+	app.Logger.Info(fmt.Sprintf("scanning music directory: %s", settings.MusicDir.String))
 
 	ctx := context.Background()
 	errorCount := 0
@@ -39,10 +47,21 @@ func (app *Application) ScanMusicLibrary() {
 	tracksSkipped := 0
 	startTime := time.Now()
 
+	// Album folders are counted again by every scan
+	app.albumTrackCounts.reset()
+	defer app.albumTrackCounts.reset()
+
 	// Batch buffer to collect tracks before processing
 	batch := make([]trackFile, 0, helpers.SCANNER_BATCH_SIZE)
 
-	err := filepath.WalkDir(app.Settings.MusicDir.String, func(path string, entry fs.DirEntry, err error) error {
+	// Ignore patterns, .nomedia markers and the minimum size are applied during the walk
+	filter := newLibraryFilter(settings.MusicDir.String, nil, settings.MusicIgnorePatterns, settings.MusicMinSize)
+
+	// Audio files split by a CUE sheet, collected as each directory is entered
+	cueFiles := make(map[string]*cueAudioFile)
+	lyricsFiles := make(map[string]*lyricsSidecar)
+
+	err := filepath.WalkDir(settings.MusicDir.String, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			app.Logger.Error(fmt.Sprintf("error walking directory: %s", err.Error()))
 			errorCount++
@@ -50,10 +69,23 @@ func (app *Application) ScanMusicLibrary() {
 		}
 
 		if entry.IsDir() {
+			skip, err := filter.skipDir(path)
+			if err != nil {
+				app.Logger.Error(err.Error())
+				errorCount++
+			}
+
+			if skip {
+				return filepath.SkipDir
+			}
+
+			maps.Copy(cueFiles, app.cueSheetsInDir(path))
+			maps.Copy(lyricsFiles, app.lyricsSidecarsInDir(path))
+
 			return nil
 		}
 
-		ext := helpers.GetFileExtension(path)
+		ext := strings.ToLower(helpers.GetFileExtension(path))
 		if !helpers.ValidAudioExtensions[ext] {
 			return nil
 		}
@@ -65,7 +97,11 @@ func (app *Application) ScanMusicLibrary() {
 			return nil
 		}
 
-		batch = append(batch, trackFile{path: path, ext: ext, size: info.Size()})
+		if filter.skipFile(path, info.Size()) {
+			return nil
+		}
+
+		batch = append(batch, trackFile{path: path, ext: ext, size: info.Size(), cue: cueFiles[path], lyrics: lyricsFiles[path]})
 
 		// Process batch when full
 		if len(batch) >= helpers.SCANNER_BATCH_SIZE {
@@ -92,14 +128,13 @@ func (app *Application) ScanMusicLibrary() {
 		errorCount += errors
 	}
 
-	app.Spotify.ClearAllCaches()
-
 	app.Logger.Info(fmt.Sprintf("music scanner completed: %d scanned, %d skipped, %d errors in %s",
 		tracksScanned, tracksSkipped, errorCount, helpers.FormatDuration(time.Since(startTime))))
 }
 
 // processMusicBatch processes a batch of audio files within a single transaction.
-// Uses skip-on-error strategy: failed tracks don't rollback successful ones.
+// Uses skip-on-error strategy: each file gets a savepoint, so a failed track doesn't
+// rollback successful ones nor leave half its rows behind.
 // Holds ScannerDBMu so only one scanner (music or movie) writes to the DB at a time.
 func (app *Application) processMusicBatch(ctx context.Context, files []trackFile) (scanned, skipped, errCount int) {
 	app.ScannerDBMu.Lock()
@@ -113,31 +148,60 @@ func (app *Application) processMusicBatch(ctx context.Context, files []trackFile
 	defer tx.Rollback()
 
 	qtx := app.Queries.WithTx(tx)
+	ledger := newScanLedger(helpers.SCAN_LIBRARY_MUSIC)
 
 	for _, file := range files {
-		// Check if track exists with same path and size (file unchanged)
-		_, err = qtx.CheckTrackUnchanged(ctx, database.CheckTrackUnchangedParams{
-			FilePath: file.path,
-			Size:     file.size,
-		})
+		if checkSampleUnchanged(ctx, qtx, file.path, file.size) {
+			skipped++
+			continue
+		}
 
-		if err == nil {
+		if file.cue != nil {
+			if checkCueTracksUnchanged(ctx, qtx, file) {
+				skipped++
+				continue
+			}
+
+			err = manageSavepoint(ctx, tx, fmt.Sprintf("sp_track_%d", scanned+skipped+errCount), func() error {
+				return app.processCueFile(ctx, qtx, file.path, file.ext, file.cue, file.lyrics)
+			})
+		} else {
+			// Check if track exists with same path and size (file unchanged)
+			// and its lyrics sidecar, if any, wasn't edited since
+			_, err = qtx.CheckTrackUnchanged(ctx, database.CheckTrackUnchangedParams{
+				FilePath: file.path,
+				Size:     file.size,
+			})
+
+			if err == nil && checkLyricsUnchanged(ctx, qtx, file) {
+				skipped++
+				continue
+			}
+
+			// File is new or size changed - process it
+			err = manageSavepoint(ctx, tx, fmt.Sprintf("sp_track_%d", scanned+skipped+errCount), func() error {
+				return app.processTrackFile(ctx, qtx, file.path, file.ext, file.lyrics)
+			})
+		}
+		if errors.Is(err, errSampleFile) {
+			app.recordSampleFile(ctx, qtx, helpers.SCAN_LIBRARY_MUSIC, file.path, file.size)
 			skipped++
 			continue
 		}
 
-		// File is new or size changed - process it
-		err = app.processTrackFile(ctx, qtx, file.path, file.ext)
 		if err != nil {
 			app.Logger.Error(fmt.Sprintf("failed to process %s: %s", file.path, err.Error()))
+			ledger.fail(file.path, err)
 			errCount++
 			continue
 		}
 
+		ledger.succeed(file.path)
 		scanned++
 	}
 
 	err = tx.Commit()
+	app.writeScanLedger(ctx, ledger, err)
 	if err != nil {
 		app.Logger.Error(fmt.Sprintf("failed to commit batch: %s", err.Error()))
 		return 0, 0, len(files)
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// localAlbum holds what the local files tell about an album, so Spotify candidates
+// can be checked against it. Zero values are unknown and left out of the score.
+type localAlbum struct {
+	year       int
+	trackCount int
+	// directory is counted for audio files when the tags carry no track total
+	directory string
+}
+
+// newLocalAlbum reads the release year and track total ("3/12") of a track's tags.
+func newLocalAlbum(path string, tags ffprobe.FormatTags) localAlbum {
+	local := localAlbum{directory: albumDirectory(path)}
+
+	if date, err := helpers.ParseDate(tags.Date); err == nil {
+		local.year = date.Year()
+	} else if len(tags.Date) >= 4 {
+		if date, err := helpers.ParseDate(tags.Date[:4]); err == nil {
+			local.year = date.Year()
+		}
+	}
+
+	if _, total, ok := strings.Cut(tags.Track, "/"); ok {
+		if n, err := helpers.ParseSlashNumber(total); err == nil && n > 0 {
+			local.trackCount = int(n)
+		}
+	}
+
+	return local
+}
+
+// albumTrackCounts remembers how many audio files the album folders counted during a
+// music scan hold, so the tracks of an album without track totals don't each walk
+// their folder again.
+type albumTrackCounts struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// get returns the count of a folder, counting it on first use.
+func (c *albumTrackCounts) get(directory string, count func(string) int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n, ok := c.counts[directory]; ok {
+		return n
+	}
+
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	n := count(directory)
+	c.counts[directory] = n
+	return n
+}
+
+// reset forgets the counts, so the next scan sees files added since.
+func (c *albumTrackCounts) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = nil
+}
+
+// tracks returns the track count, counting the audio files of the album's folder
+// and its disc subfolders when the tags carry none. Folders are counted once per
+// scan (see albumTrackCounts).
+func (l localAlbum) tracks(counts *albumTrackCounts) int {
+	if l.trackCount > 0 || l.directory == "" {
+		return l.trackCount
+	}
+
+	return counts.get(l.directory, countAlbumTracks)
+}
+
+// countAlbumTracks counts the audio files of an album folder and its disc subfolders.
+func countAlbumTracks(directory string) int {
+	count := 0
+	filepath.WalkDir(directory, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != directory && !discFolderPattern.MatchString(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+		if helpers.ValidAudioExtensions[ext] {
+			count++
+		}
+		return nil
+	})
+
+	return count
+}
+
+// albumTitleSuffixPattern matches edition notes that differ between releases of the
+// same album: "(Remastered)", "[Deluxe Edition]", " - 2009 Remaster".
+var albumTitleSuffixPattern = regexp.MustCompile(`(?i)\s*(\([^)]*\)|\[[^\]]*\]|\s-\s.*(remaster|edition|version|mix).*)`)
+
+// normalizeAlbumTitle lowercases a title and drops edition notes and everything but
+// letters and digits.
+func normalizeAlbumTitle(title string) string {
+	stripped := albumTitleSuffixPattern.ReplaceAllString(title, "")
+	if strings.TrimSpace(stripped) == "" {
+		stripped = title
+	}
+
+	return helpers.NormalizeKey(stripped)
+}
+
+// similarity returns how alike two normalized strings are, from 0 to 1, based on
+// their edit distance.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	// Levenshtein distance, one row at a time
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// spotifyAlbumMatchScore rates how likely a Spotify album is the local one, from 0
+// to 1. The title weighs most, then the artist, release year and track count; facts
+// the local files don't know are left out of the weighting. The artist outweighs the
+// year and track count together, so an album of the same name by someone else can't
+// pass SPOTIFY_ALBUM_MATCH_THRESHOLD on them.
+func spotifyAlbumMatchScore(title, artist string, local localAlbum, candidate *spotify.SimpleAlbum) float64 {
+	const (
+		titleWeight  = 0.4
+		artistWeight = 0.35
+		yearWeight   = 0.15
+		tracksWeight = 0.1
+	)
+
+	score := titleWeight * similarity(normalizeAlbumTitle(title), normalizeAlbumTitle(candidate.Name))
+	weight := titleWeight
+
+	if name := helpers.NormalizeKey(artist); name != "" {
+		best := 0.0
+		for _, a := range candidate.Artists {
+			best = max(best, similarity(name, helpers.NormalizeKey(a.Name)))
+		}
+		score += artistWeight * best
+		weight += artistWeight
+	}
+
+	if year := candidate.ReleaseDateTime().Year(); local.year > 0 && candidate.ReleaseDate != "" {
+		switch diff := local.year - year; {
+		case diff == 0:
+			score += yearWeight
+		case diff == 1 || diff == -1:
+			score += yearWeight / 2
+		}
+		weight += yearWeight
+	}
+
+	if total := int(candidate.TotalTracks); local.trackCount > 0 && total > 0 {
+		diff := math.Abs(float64(local.trackCount - total))
+		score += tracksWeight * (1 - diff/float64(max(local.trackCount, total)))
+		weight += tracksWeight
+	}
+
+	return score / weight
+}
+
+// bestSpotifyAlbum returns the best scoring candidate and its score, nil without any.
+func bestSpotifyAlbum(title, artist string, local localAlbum, candidates []spotify.SimpleAlbum) (*spotify.SimpleAlbum, float64) {
+	var best *spotify.SimpleAlbum
+	bestScore := -1.0
+	for i := range candidates {
+		if score := spotifyAlbumMatchScore(title, artist, local, &candidates[i]); score > bestScore {
+			best, bestScore = &candidates[i], score
+		}
+	}
+	return best, bestScore
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// spotifyAlbum builds a Spotify search result.
+func spotifyAlbum(id, name, artist, releaseDate string, totalTracks int) spotify.SimpleAlbum {
+	return spotify.SimpleAlbum{
+		ID:          spotify.ID(id),
+		Name:        name,
+		Artists:     []spotify.SimpleArtist{{Name: artist}},
+		ReleaseDate: releaseDate,
+		// Spotify sends the precision, "day" for full dates
+		ReleaseDatePrecision: "day",
+		TotalTracks:          spotify.Numeric(totalTracks),
+		Images:               []spotify.Image{{URL: "https://i.scdn.co/image/" + id}},
+	}
+}
+
+// newAlbumMatchSpotify returns a Spotify whose "Greatest Hits" search by Queen lists
+// ABBA's album first, and whose only "Live" album is by another band.
+func newAlbumMatchSpotify() *fakeSpotify {
+	return &fakeSpotify{albums: map[string][]spotify.SimpleAlbum{
+		"Queen - Greatest Hits": {
+			spotifyAlbum("abba-hits", "Greatest Hits", "ABBA", "1975-11-17", 14),
+			spotifyAlbum("queen-hits", "Greatest Hits (Remastered 2011)", "Queen", "1981-10-26", 17),
+		},
+		"The Nobodies - Live": {
+			spotifyAlbum("pearl-jam-live", "Live", "Pearl Jam", "1994-01-01", 9),
+		},
+	}}
+}
+
+// TestSpotifyAlbumMatchScore tests that candidates are scored on title, artist, year
+// and track count, and that unknown local facts don't count against them.
+func TestSpotifyAlbumMatchScore(t *testing.T) {
+	queen := spotifyAlbum("queen-hits", "Greatest Hits (Remastered 2011)", "Queen", "1981-10-26", 17)
+	abba := spotifyAlbum("abba-hits", "Greatest Hits", "ABBA", "1975-11-17", 14)
+
+	tests := []struct {
+		name      string
+		title     string
+		artist    string
+		local     localAlbum
+		candidate spotify.SimpleAlbum
+		match     bool
+	}{
+		{"same album", "Greatest Hits", "Queen", localAlbum{year: 1981, trackCount: 17}, queen, true},
+		{"edition notes ignored", "Greatest Hits [Deluxe Edition]", "Queen", localAlbum{}, queen, true},
+		{"reissue year", "Greatest Hits", "Queen", localAlbum{year: 1982, trackCount: 17}, queen, true},
+		{"same title by another artist", "Greatest Hits", "Queen", localAlbum{year: 1975, trackCount: 14}, abba, false},
+		{"another title", "A Night at the Opera", "Queen", localAlbum{}, queen, false},
+		{"compilation by title", "Greatest Hits", "", localAlbum{}, abba, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := spotifyAlbumMatchScore(tt.title, tt.artist, tt.local, &tt.candidate)
+			if score < 0 || score > 1 {
+				t.Fatalf("Expected a score between 0 and 1, got %v", score)
+			}
+			if match := score >= helpers.SPOTIFY_ALBUM_MATCH_THRESHOLD; match != tt.match {
+				t.Errorf("Expected match %v, got score %v", tt.match, score)
+			}
+		})
+	}
+
+	exact := spotifyAlbumMatchScore("Greatest Hits", "Queen", localAlbum{year: 1981, trackCount: 17}, &queen)
+	reissue := spotifyAlbumMatchScore("Greatest Hits", "Queen", localAlbum{year: 1982, trackCount: 18}, &queen)
+	if reissue >= exact {
+		t.Errorf("Expected year and track count differences to lower the score, got %v and %v", reissue, exact)
+	}
+}
+
+// TestLocalAlbumTracks tests that an album folder and its disc subfolders are counted
+// once per scan when the tags carry no track total.
+func TestLocalAlbumTracks(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"01.flac", "02.mp3", "cover.jpg", "CD2/01.flac", "Scans/01.flac"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	var counts albumTrackCounts
+	local := localAlbum{directory: dir}
+	if n := local.tracks(&counts); n != 3 {
+		t.Fatalf("Expected 3 tracks, got %d", n)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "03.flac"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("Failed to write 03.flac: %v", err)
+	}
+	if n := local.tracks(&counts); n != 3 {
+		t.Errorf("Expected the folder to be counted once, got %d tracks", n)
+	}
+
+	counts.reset()
+	if n := local.tracks(&counts); n != 4 {
+		t.Errorf("Expected 4 tracks after a reset, got %d", n)
+	}
+
+	// A track total in the tags is used as is
+	if n := (localAlbum{directory: dir, trackCount: 12}).tracks(&counts); n != 12 {
+		t.Errorf("Expected the tagged total, got %d", n)
+	}
+}
+
+// TestProcessMusicBatch_SpotifyAlbumMatch tests that the scanner matches albums by
+// artist and title, and stores the score of a rejected match without its Spotify data.
+func TestProcessMusicBatch_SpotifyAlbumMatch(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	settings := *app.Settings()
+	settings.MusicSpotifyEnrichment = true
+	app.SetSettings(&settings)
+	app.Spotify = newAlbumMatchSpotify()
+
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		"/music/queen/01.flac": {
+			Title: "Bohemian Rhapsody", Artist: "Queen", AlbumArtist: "Queen", Album: "Greatest Hits",
+			Track: "1/17", Date: "1981",
+		},
+		"/music/nobodies/01.flac": {
+			Title: "Intro", Artist: "The Nobodies", AlbumArtist: "The Nobodies", Album: "Live",
+			Track: "1/12", Date: "2003-05-01",
+		},
+	}}
+
+	ctx := context.Background()
+
+	files := []trackFile{
+		{path: "/music/queen/01.flac", ext: "flac", size: 4},
+		{path: "/music/nobodies/01.flac", ext: "flac", size: 4},
+	}
+	if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != len(files) || errCount != 0 {
+		t.Fatalf("Expected %d tracks scanned, got %d scanned and %d errors", len(files), scanned, errCount)
+	}
+
+	albumBy := func(musician, title string) database.Album {
+		t.Helper()
+		albums, err := app.Queries.GetAlbumsByTitle(ctx, database.GetAlbumsByTitleParams{
+			Title:    title,
+			Musician: helpers.NullString(musician),
+		})
+		if err != nil || len(albums) != 1 {
+			t.Fatalf("Expected one %q album, got %d (%v)", title, len(albums), err)
+		}
+		return albums[0]
+	}
+
+	hits := albumBy("Queen", "Greatest Hits")
+	if hits.SpotifyID.String != "queen-hits" || hits.Year.Int64 != 1981 || hits.SpotifyMatchScore.Float64 < helpers.SPOTIFY_ALBUM_MATCH_THRESHOLD {
+		t.Errorf("Expected Queen's Greatest Hits (1981) to be matched, got %q (%d) with score %v", hits.SpotifyID.String, hits.Year.Int64, hits.SpotifyMatchScore.Float64)
+	}
+
+	live := albumBy("The Nobodies", "Live")
+	if live.SpotifyID.Valid || live.Cover.Valid || !live.SpotifyMatchScore.Valid || live.SpotifyMatchScore.Float64 >= helpers.SPOTIFY_ALBUM_MATCH_THRESHOLD {
+		t.Errorf("Expected the Live match to be rejected with its score, got %q with score %v", live.SpotifyID.String, live.SpotifyMatchScore)
+	}
+}
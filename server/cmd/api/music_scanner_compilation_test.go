@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"igloo/cmd/internal/ffprobe"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// fakeTaggedFfprobe probes every file as a three minute FLAC with the tags set for its path.
+type fakeTaggedFfprobe struct {
+	tags map[string]ffprobe.FormatTags
+}
+
+func (f *fakeTaggedFfprobe) GetMetadata(filePath string) (*ffprobe.FfprobeResult, error) {
+	return &ffprobe.FfprobeResult{
+		Format: ffprobe.Format{FormatName: "flac", Size: "4", Duration: "180.000", BitRate: "900000", Tags: f.tags[filePath]},
+		Streams: []ffprobe.Stream{
+			{Index: 0, CodecType: "audio", CodecName: "flac", Channels: 2, ChannelLayout: "stereo"},
+		},
+	}, nil
+}
+
+// TestProcessMusicBatch_Compilations tests that compilation tracks by different artists
+// share one album under Various Artists, and that tracks without an album artist are
+// grouped by folder instead of splitting into one album per track artist.
+func TestProcessMusicBatch_Compilations(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		"/music/Now 1/01.flac":               {Title: "One", Artist: "Artist A", Album: "Now 1", Compilation: "1"},
+		"/music/Now 1/02.flac":               {Title: "Two", Artist: "Artist B", Album: "Now 1", Compilation: "1"},
+		"/music/Now 1/03.flac":               {Title: "Three", Artist: "Artist C", AlbumArtist: "VA", Album: "Now 1"},
+		"/music/Hits/CD1/01.flac":            {Title: "Four", Artist: "Artist D", Album: "Greatest Hits"},
+		"/music/Hits/CD2/01.flac":            {Title: "Five", Artist: "Artist E", Album: "Greatest Hits"},
+		"/music/Other Hits/01.flac":          {Title: "Six", Artist: "Artist F", Album: "Greatest Hits"},
+		"/music/Artist G/Debut/01.flac":      {Title: "Seven", Artist: "Artist G", AlbumArtist: "Artist G", Album: "Debut"},
+		"/music/Artist G/Debut/02 live.flac": {Title: "Eight", Artist: "Artist G & H", AlbumArtist: "Artist G", Album: "Debut"},
+	}}
+
+	ctx := context.Background()
+
+	var files []trackFile
+	for path := range app.Ffprobe.(*fakeTaggedFfprobe).tags {
+		files = append(files, trackFile{path: path, ext: "flac", size: 4})
+	}
+
+	if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != len(files) || errCount != 0 {
+		t.Fatalf("Expected %d tracks scanned, got %d scanned and %d errors", len(files), scanned, errCount)
+	}
+
+	albumOf := func(path string) int64 {
+		t.Helper()
+		var albumID int64
+		if err := app.DB.QueryRow("SELECT album_id FROM tracks WHERE file_path = ?", path).Scan(&albumID); err != nil {
+			t.Fatalf("Failed to get album of %s: %v", path, err)
+		}
+		return albumID
+	}
+
+	compilation := albumOf("/music/Now 1/01.flac")
+	if albumOf("/music/Now 1/02.flac") != compilation || albumOf("/music/Now 1/03.flac") != compilation {
+		t.Error("Expected the compilation tracks to share one album")
+	}
+
+	album, err := app.Queries.GetAlbumByID(ctx, compilation)
+	if err != nil {
+		t.Fatalf("Failed to get album: %v", err)
+	}
+
+	if !album.IsCompilation || album.Musician.String != "Various Artists" {
+		t.Errorf("Expected a compilation by Various Artists, got %v by %q", album.IsCompilation, album.Musician.String)
+	}
+
+	var linked int64
+	err = app.DB.QueryRow(`SELECT COUNT(*) FROM musician_albums ma JOIN musicians m ON m.id = ma.musician_id
+		WHERE ma.album_id = ? AND m.name = 'Various Artists'`, compilation).Scan(&linked)
+	if err != nil || linked != 1 {
+		t.Errorf("Expected the compilation to be linked to Various Artists, got %d (%v)", linked, err)
+	}
+
+	hits := albumOf("/music/Hits/CD1/01.flac")
+	if albumOf("/music/Hits/CD2/01.flac") != hits {
+		t.Error("Expected both discs to share one album")
+	}
+
+	if albumOf("/music/Other Hits/01.flac") == hits {
+		t.Error("Expected a same-titled album in another folder to stay separate")
+	}
+
+	if albumOf("/music/Artist G/Debut/01.flac") != albumOf("/music/Artist G/Debut/02 live.flac") {
+		t.Error("Expected the album artist to group tracks by different track artists")
+	}
+
+	tests := []struct {
+		query    string
+		status   int
+		expected int
+	}{
+		{"", http.StatusOK, 4},
+		{"?compilations=only", http.StatusOK, 1},
+		{"?compilations=exclude", http.StatusOK, 3},
+		{"?compilations=maybe", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/music/albums"+tt.query, nil)
+			rr := httptest.NewRecorder()
+
+			app.GetAlbumsAlphabetical(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data struct {
+					Albums []json.RawMessage `json:"albums"`
+					Total  int               `json:"total"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if len(res.Data.Albums) != tt.expected || res.Data.Total != tt.expected {
+				t.Errorf("Expected %d albums, got %d (total %d)", tt.expected, len(res.Data.Albums), res.Data.Total)
+			}
+		})
+	}
+}
+
+// TestProcessMusicBatch_FolderAlbumsShareSpotifyMatch tests that albums without an album
+// artist in two folders stay apart when both match the same Spotify album, and that the
+// track artist stands in for the album artist of the first one.
+func TestProcessMusicBatch_FolderAlbumsShareSpotifyMatch(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	settings := *app.Settings()
+	settings.MusicSpotifyEnrichment = true
+	app.SetSettings(&settings)
+	app.Spotify = &fakeSpotify{albums: map[string][]spotify.SimpleAlbum{
+		"ABBA - Greatest Hits": {spotifyAlbum("abba-hits", "Greatest Hits", "ABBA", "1975-11-17", 14)},
+	}}
+	app.Ffprobe = &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		"/music/Hits/01.flac":       {Title: "SOS", Artist: "ABBA", Album: "Greatest Hits"},
+		"/music/Other Hits/01.flac": {Title: "Mamma Mia", Artist: "ABBA", Album: "Greatest Hits"},
+	}}
+
+	ctx := context.Background()
+
+	for _, path := range []string{"/music/Hits/01.flac", "/music/Other Hits/01.flac"} {
+		files := []trackFile{{path: path, ext: "flac", size: 4}}
+		if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != 1 || errCount != 0 {
+			t.Fatalf("Expected %s to be scanned, got %d scanned and %d errors", path, scanned, errCount)
+		}
+	}
+
+	rows, err := app.DB.Query(`SELECT a.directory, a.spotify_id, a.musician FROM tracks t JOIN albums a ON a.id = t.album_id
+		ORDER BY t.file_path`)
+	if err != nil {
+		t.Fatalf("Failed to get albums: %v", err)
+	}
+	defer rows.Close()
+
+	var albums []struct{ directory, spotifyID, musician sql.NullString }
+	for rows.Next() {
+		var album struct{ directory, spotifyID, musician sql.NullString }
+		if err := rows.Scan(&album.directory, &album.spotifyID, &album.musician); err != nil {
+			t.Fatalf("Failed to scan album: %v", err)
+		}
+		albums = append(albums, album)
+	}
+
+	if len(albums) != 2 || albums[0].directory.String != "/music/Hits" || albums[1].directory.String != "/music/Other Hits" {
+		t.Fatalf("Expected one album per folder, got %+v", albums)
+	}
+	if albums[0].spotifyID.String != "abba-hits" || albums[1].spotifyID.Valid {
+		t.Errorf("Expected only the first folder to hold the Spotify album, got %q and %q", albums[0].spotifyID.String, albums[1].spotifyID.String)
+	}
+	// The title and artist are taken by the first folder, the second one keeps no artist
+	if albums[0].musician.String != "ABBA" || albums[1].musician.Valid {
+		t.Errorf("Expected the track artist on the first folder only, got %q and %q", albums[0].musician.String, albums[1].musician.String)
+	}
+}
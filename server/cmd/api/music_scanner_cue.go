@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cueAudioFile is an audio file split into virtual tracks by a CUE sheet.
+type cueAudioFile struct {
+	sheet *helpers.CueSheet
+	file  helpers.CueFile
+	// sheetModTime is when the sheet was last modified, so edits to it are rescanned
+	// even though the audio file itself is unchanged
+	sheetModTime time.Time
+}
+
+// cueSheetsInDir parses the CUE sheets in dir and returns the audio files they split,
+// keyed by path. A sheet that can't be parsed is logged and its files are indexed whole.
+func (app *Application) cueSheetsInDir(dir string) map[string]*cueAudioFile {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		app.Logger.Warn("failed to read directory for cue sheets", "error", err, "dir", dir)
+		return nil
+	}
+
+	// Rippers often write the sheet for a WAV image that was encoded to FLAC
+	// afterwards, so FILE names are also matched by their name without extension
+	names := make(map[string]string)
+	stems := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !helpers.ValidAudioExtensions[strings.ToLower(helpers.GetFileExtension(name))] {
+			continue
+		}
+		names[strings.ToLower(name)] = name
+		stems[strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))] = name
+	}
+
+	var files map[string]*cueAudioFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".cue") {
+			continue
+		}
+
+		cuePath := filepath.Join(dir, entry.Name())
+
+		info, err := entry.Info()
+		if err != nil {
+			app.Logger.Warn("failed to stat cue sheet", "error", err, "path", cuePath)
+			continue
+		}
+
+		data, err := os.ReadFile(cuePath)
+		if err != nil {
+			app.Logger.Warn("failed to read cue sheet", "error", err, "path", cuePath)
+			continue
+		}
+
+		sheet, err := helpers.ParseCueSheet(data)
+		if err != nil {
+			app.Logger.Warn("failed to parse cue sheet", "error", err, "path", cuePath)
+			continue
+		}
+
+		for _, file := range sheet.Files {
+			if len(file.Tracks) == 0 {
+				continue
+			}
+
+			// FILE names are relative to the sheet and may use Windows separators
+			name := filepath.Base(strings.ReplaceAll(file.Name, `\`, "/"))
+
+			audio, ok := names[strings.ToLower(name)]
+			if !ok {
+				audio, ok = stems[strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))]
+			}
+			if !ok {
+				app.Logger.Warn("cue sheet references a missing file", "path", cuePath, "file", file.Name)
+				continue
+			}
+
+			if files == nil {
+				files = make(map[string]*cueAudioFile)
+			}
+			files[filepath.Join(dir, audio)] = &cueAudioFile{sheet: sheet, file: file, sheetModTime: info.ModTime()}
+		}
+	}
+
+	return files
+}
+
+// cueTrackPath is the unique file_path of a virtual track: the audio file with the
+// track number appended. The audio file itself is stored in source_path.
+func cueTrackPath(path string, number int) string {
+	return fmt.Sprintf("%s#%02d", path, number)
+}
+
+// checkCueTracksUnchanged reports whether an audio file split by a CUE sheet was
+// already scanned with its current size and after the last change to the sheet and
+// to its lyrics sidecar, with the lyrics read from the source it has now.
+func checkCueTracksUnchanged(ctx context.Context, qtx *database.Queries, file trackFile) bool {
+	modTime := file.cue.sheetModTime
+	if file.lyrics != nil && file.lyrics.modTime.After(modTime) {
+		modTime = file.lyrics.modTime
+	}
+
+	_, err := qtx.CheckCueTracksUnchanged(ctx, database.CheckCueTracksUnchangedParams{
+		SourcePath:   sql.NullString{String: file.path, Valid: true},
+		Size:         file.size,
+		UpdatedAt:    helpers.SQLiteTime(modTime),
+		LyricsSource: lyricsSource(file.lyrics),
+	})
+	return err == nil
+}
+
+// cueTrackLyrics returns the part of the audio file's synced lyrics between start and
+// end, as LRC with times relative to start. end is 0 for the last track. Plain lyrics
+// can't be split between tracks, so they give none.
+func cueTrackLyrics(lyrics string, start, end int64) string {
+	lines, synced := helpers.ParseLRC(lyrics)
+	if !synced {
+		return ""
+	}
+
+	var track []helpers.LyricLine
+	for _, line := range lines {
+		if line.Time < start || (end > 0 && line.Time >= end) {
+			continue
+		}
+		track = append(track, helpers.LyricLine{Time: line.Time - start, Text: line.Text})
+	}
+
+	if len(track) == 0 {
+		return ""
+	}
+
+	return helpers.FormatLRC(track)
+}
+
+// processCueFile probes an audio file once and stores a virtual track for every
+// TRACK its CUE sheet lists for it. Sheet metadata wins over the file's own tags,
+// which are usually empty or describe the whole disc. Synced lyrics of the file are
+// split between its tracks. The file is not also indexed as a single track.
+func (app *Application) processCueFile(ctx context.Context, qtx *database.Queries, path, ext string, cue *cueAudioFile, sidecar *lyricsSidecar) error {
+	info, err := app.Ffprobe.GetMetadata(path)
+	if err != nil {
+		return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("ffprobe failed: %w", err))
+	}
+
+	base := audioTrackParams(info, path, ext)
+
+	if belowMinDuration(base.Duration, app.Settings().MusicMinDuration) {
+		return errSampleFile
+	}
+
+	fileTags := info.AudioTags()
+	fileLyrics := app.readTrackLyrics(path, ext, fileTags, sidecar)
+	tracks := cue.file.Tracks
+	paths := make([]string, 0, len(tracks))
+
+	for i, cueTrack := range tracks {
+		params := base
+		params.FilePath = cueTrackPath(path, cueTrack.Number)
+		params.SourcePath = sql.NullString{String: path, Valid: true}
+		params.StartOffset = sql.NullInt64{Int64: cueTrack.Start, Valid: true}
+
+		// A track ends where the next one starts, the last one at the end of the file
+		end := base.Duration
+		var lyricsEnd int64
+		if i+1 < len(tracks) {
+			end = tracks[i+1].Start
+			lyricsEnd = end
+			params.EndOffset = sql.NullInt64{Int64: end, Valid: true}
+		}
+		params.Duration = max(end-cueTrack.Start, 0)
+
+		tags := fileTags
+		tags.Title = cueTrack.Title
+		tags.SortName = ""
+		tags.Track = strconv.Itoa(cueTrack.Number)
+		// A recording id tagged on the disc image would be shared by every track
+		tags.MusicBrainzTrackID = ""
+
+		if cueTrack.Title == "" {
+			tags.Title = fmt.Sprintf("Track %02d", cueTrack.Number)
+		}
+
+		if cueTrack.Performer != "" {
+			tags.Artist = cueTrack.Performer
+			tags.SortArtist = ""
+		} else if cue.sheet.Performer != "" {
+			tags.Artist = cue.sheet.Performer
+			tags.SortArtist = ""
+		}
+
+		if cue.sheet.Title != "" {
+			tags.Album = cue.sheet.Title
+			tags.SortAlbum = ""
+		}
+
+		if cue.sheet.Performer != "" {
+			tags.AlbumArtist = cue.sheet.Performer
+		}
+
+		if cueTrack.Songwriter != "" {
+			tags.Composer = cueTrack.Songwriter
+		}
+
+		if cue.sheet.Date != "" {
+			tags.Date = cue.sheet.Date
+		}
+
+		if cue.sheet.Genre != "" {
+			tags.Genre = cue.sheet.Genre
+		}
+
+		track, err := app.saveTrack(ctx, qtx, params, tags)
+		if err != nil {
+			return fmt.Errorf("track %d: %w", cueTrack.Number, err)
+		}
+
+		lyrics := fileLyrics
+		lyrics.TrackID = track.ID
+		lyrics.Content = cueTrackLyrics(fileLyrics.Content, cueTrack.Start, lyricsEnd)
+		if err := saveScannedLyrics(ctx, qtx, lyrics); err != nil {
+			return fmt.Errorf("track %d: %w", cueTrack.Number, err)
+		}
+
+		paths = append(paths, params.FilePath)
+	}
+
+	filePaths, err := json.Marshal(paths)
+	if err != nil {
+		return fmt.Errorf("encode cue track paths failed: %w", err)
+	}
+
+	// Tracks removed from the sheet, and the whole-file track from before it was added
+	err = qtx.DeleteStaleCueTracks(ctx, database.DeleteStaleCueTracksParams{
+		SourcePath: sql.NullString{String: path, Valid: true},
+		FilePaths:  string(filePaths),
+	})
+	if err != nil {
+		return fmt.Errorf("delete stale cue tracks failed: %w", err)
+	}
+
+	if err := qtx.DeleteTrackByFilePath(ctx, path); err != nil {
+		return fmt.Errorf("delete whole-file track failed: %w", err)
+	}
+
+	return nil
+}
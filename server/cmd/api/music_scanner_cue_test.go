@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/ffprobe"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// fakeAudioFfprobe probes every file as a ten minute FLAC disc image without tags.
+type fakeAudioFfprobe struct{}
+
+func (f *fakeAudioFfprobe) GetMetadata(filePath string) (*ffprobe.FfprobeResult, error) {
+	return &ffprobe.FfprobeResult{
+		Format: ffprobe.Format{FormatName: "flac", Size: "4", Duration: "600.000", BitRate: "900000"},
+		Streams: []ffprobe.Stream{
+			{Index: 0, CodecType: "audio", CodecName: "flac", Channels: 2, ChannelLayout: "stereo"},
+		},
+	}, nil
+}
+
+// TestProcessMusicBatch_CueSheet tests that a disc image with a CUE sheet becomes one
+// virtual track per TRACK instead of a single track, and that those tracks are
+// streamed as ranges of the image.
+func TestProcessMusicBatch_CueSheet(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	app.Ffprobe = &fakeAudioFfprobe{}
+
+	ctx := context.Background()
+
+	// The sheet was written for the WAV rip, the image was encoded to FLAC afterwards
+	dir := t.TempDir()
+	image := filepath.Join(dir, "CD1.flac")
+	if err := os.WriteFile(image, []byte("fLaC"), 0o644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	cue := `REM GENRE Classical
+REM DATE 1963
+PERFORMER "Berliner Philharmoniker"
+TITLE "Symphony No. 5"
+FILE "CD1.wav" WAVE
+  TRACK 01 AUDIO
+    TITLE "Allegro con brio"
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    TITLE "Andante con moto"
+    INDEX 01 07:30:00
+`
+	if err := os.WriteFile(filepath.Join(dir, "CD1.cue"), []byte(cue), 0o644); err != nil {
+		t.Fatalf("Failed to write cue sheet: %v", err)
+	}
+
+	// Indexed whole before the sheet was added
+	if scanned, _, _ := app.processMusicBatch(ctx, []trackFile{{path: image, ext: "flac", size: 4}}); scanned != 1 {
+		t.Fatalf("Expected the image to be scanned, got %d", scanned)
+	}
+
+	cueFiles := app.cueSheetsInDir(dir)
+	if cueFiles[image] == nil {
+		t.Fatalf("Expected the sheet to split %s, got %v", image, cueFiles)
+	}
+
+	file := trackFile{path: image, ext: "flac", size: 4, cue: cueFiles[image]}
+	if scanned, _, errCount := app.processMusicBatch(ctx, []trackFile{file}); scanned != 1 || errCount != 0 {
+		t.Fatalf("Expected the image to be split, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	// Unchanged image and sheet
+	if _, skipped, _ := app.processMusicBatch(ctx, []trackFile{file}); skipped != 1 {
+		t.Errorf("Expected the unchanged image to be skipped, got %d", skipped)
+	}
+
+	rows, err := app.DB.Query("SELECT id, title, track_index, duration, start_offset, end_offset FROM tracks ORDER BY track_index")
+	if err != nil {
+		t.Fatalf("Failed to query tracks: %v", err)
+	}
+	defer rows.Close()
+
+	type cueTrack struct {
+		id, index, duration, start int64
+		title                      string
+		end                        *int64
+	}
+
+	var tracks []cueTrack
+	for rows.Next() {
+		var track cueTrack
+		if err := rows.Scan(&track.id, &track.title, &track.index, &track.duration, &track.start, &track.end); err != nil {
+			t.Fatalf("Failed to scan track: %v", err)
+		}
+		tracks = append(tracks, track)
+	}
+
+	if len(tracks) != 2 {
+		t.Fatalf("Expected two virtual tracks and no whole-file track, got %+v", tracks)
+	}
+
+	if tracks[0].title != "Allegro con brio" || tracks[0].duration != 450_000 || tracks[0].end == nil || *tracks[0].end != 450_000 {
+		t.Errorf("Unexpected first track: %+v", tracks[0])
+	}
+
+	if tracks[1].title != "Andante con moto" || tracks[1].start != 450_000 || tracks[1].duration != 150_000 || tracks[1].end != nil {
+		t.Errorf("Unexpected second track: %+v", tracks[1])
+	}
+
+	track, err := app.Queries.GetTrack(ctx, tracks[1].id)
+	if err != nil {
+		t.Fatalf("Failed to get track: %v", err)
+	}
+
+	album, err := app.Queries.GetAlbumByID(ctx, track.AlbumID.Int64)
+	if err != nil {
+		t.Fatalf("Failed to get album: %v", err)
+	}
+
+	if album.Title != "Symphony No. 5" || track.Year.Int64 != 1963 {
+		t.Errorf("Expected the sheet's album and date, got %q (%d)", album.Title, track.Year.Int64)
+	}
+
+	// The second track is streamed from 7:30 to the end of the image
+	ffmpeg := &fakeFfmpeg{}
+	app.Ffmpeg = ffmpeg
+
+	id := strconv.FormatInt(track.ID, 10)
+	req := httptest.NewRequest(http.MethodGet, "/api/music/tracks/"+id+"/stream", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	app.StreamTrack(rr, req)
+
+	if rr.Code != http.StatusOK || rr.Body.String() != "fLaC" {
+		t.Fatalf("Expected the transcoded range, got %d: %q", rr.Code, rr.Body.String())
+	}
+
+	if ffmpeg.path != image || ffmpeg.start != 450*time.Second || ffmpeg.end != 0 {
+		t.Errorf("Expected %s from 7m30s to the end, got %s from %s to %s", image, ffmpeg.path, ffmpeg.start, ffmpeg.end)
+	}
+}
+
+// TestProcessCueFile_LyricsAndRemovedTracks tests that the image's synced lyrics are
+// split between its tracks, and that a track removed from the middle of the sheet is
+// deleted.
+func TestProcessCueFile_LyricsAndRemovedTracks(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+	app.Ffprobe = &fakeAudioFfprobe{}
+
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	image := filepath.Join(dir, "CD1.flac")
+	if err := os.WriteFile(image, []byte("fLaC"), 0o644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	lrc := filepath.Join(dir, "CD1.lrc")
+	if err := os.WriteFile(lrc, []byte("[00:10.00]One\n[07:40.00]Two\n[09:05.00]Three"), 0o644); err != nil {
+		t.Fatalf("Failed to write lyrics: %v", err)
+	}
+
+	writeSheet := func(tracks string) *cueAudioFile {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "CD1.cue"), []byte("FILE \"CD1.flac\" WAVE\n"+tracks), 0o644); err != nil {
+			t.Fatalf("Failed to write cue sheet: %v", err)
+		}
+		return app.cueSheetsInDir(dir)[image]
+	}
+
+	sidecar := app.lyricsSidecarsInDir(dir)[image]
+	if sidecar == nil {
+		t.Fatal("Expected the lyrics sidecar of the image")
+	}
+
+	cue := writeSheet(`  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+  TRACK 02 AUDIO
+    INDEX 01 07:30:00
+  TRACK 03 AUDIO
+    INDEX 01 09:00:00
+`)
+	if err := app.processCueFile(ctx, app.Queries, image, "flac", cue, sidecar); err != nil {
+		t.Fatalf("processCueFile failed: %v", err)
+	}
+
+	lyricsOf := func(number int) string {
+		t.Helper()
+		var id int64
+		if err := app.DB.QueryRow("SELECT id FROM tracks WHERE file_path = ?", cueTrackPath(image, number)).Scan(&id); err != nil {
+			t.Fatalf("Failed to get track %d: %v", number, err)
+		}
+		lyrics, err := app.Queries.GetTrackLyrics(ctx, id)
+		if err != nil {
+			t.Fatalf("Failed to get lyrics of track %d: %v", number, err)
+		}
+		return lyrics.Content
+	}
+
+	if got := lyricsOf(2); got != "[00:10.00]Two" {
+		t.Errorf("Expected the second track's line relative to its start, got %q", got)
+	}
+
+	if got := lyricsOf(3); got != "[00:05.00]Three" {
+		t.Errorf("Expected the last track's line relative to its start, got %q", got)
+	}
+
+	// The middle track is merged into the first one
+	cue = writeSheet(`  TRACK 01 AUDIO
+    INDEX 01 00:00:00
+  TRACK 03 AUDIO
+    INDEX 01 09:00:00
+`)
+	if err := app.processCueFile(ctx, app.Queries, image, "flac", cue, sidecar); err != nil {
+		t.Fatalf("processCueFile rescan failed: %v", err)
+	}
+
+	var remaining int
+	if err := app.DB.QueryRow("SELECT COUNT(*) FROM tracks WHERE source_path = ?", image).Scan(&remaining); err != nil {
+		t.Fatalf("Failed to count tracks: %v", err)
+	}
+
+	if remaining != 2 {
+		t.Errorf("Expected the track removed from the sheet to be deleted, got %d tracks", remaining)
+	}
+
+	if got := lyricsOf(1); got != "[00:10.00]One\n[07:40.00]Two" {
+		t.Errorf("Expected the first track to hold both lines, got %q", got)
+	}
+}
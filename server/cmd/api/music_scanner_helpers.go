@@ -3,9 +3,13 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
 	"igloo/cmd/internal/helpers"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/zmb3/spotify/v2"
@@ -63,22 +67,86 @@ func generateMusicianSummary(artist *spotify.FullArtist) string {
 	return strings.Join(parts, " ") + "."
 }
 
+// musicBrainzIDPattern matches a single MusicBrainz identifier (a lower case UUID).
+var musicBrainzIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// musicBrainzID normalizes a MusicBrainz id tag. Anything but a single id returns "",
+// including an ARTISTID listing every artist of a track with featured artists.
+func musicBrainzID(tag string) string {
+	id := strings.ToLower(strings.TrimSpace(tag))
+	if !musicBrainzIDPattern.MatchString(id) {
+		return ""
+	}
+	return id
+}
+
 // getOrCreateMusician looks up or creates a musician in the database.
+// A MusicBrainz id identifies the musician on its own, so bands sharing a name stay
+// apart; without one the musician is matched by name.
 // If Spotify is configured, attempts to enrich the data with Spotify info.
 // Falls back to basic metadata if Spotify lookup fails.
-func (app *Application) getOrCreateMusician(ctx context.Context, qtx *database.Queries, name, sortName string) (*database.Musician, error) {
+func (app *Application) getOrCreateMusician(ctx context.Context, qtx *database.Queries, name, sortName, musicbrainzID string) (*database.Musician, error) {
+	mbid := helpers.NullString(musicbrainzID)
+
+	if mbid.Valid {
+		existing, err := qtx.GetMusicianByMusicbrainzID(ctx, mbid)
+		if err == nil {
+			return &existing, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		// A musician scanned before its files were tagged takes the id over
+		unmatched, err := qtx.GetUnmatchedMusicianByName(ctx, name)
+		if err == nil {
+			return app.setMusicianMusicbrainzID(ctx, qtx, unmatched, mbid)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	} else {
+		// A musician renamed by hand is still found through the name in the tags
+		renamed, err := qtx.GetMusicianByScanName(ctx, sql.NullString{String: name, Valid: true})
+		if err == nil {
+			return &renamed, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		// Untagged files of a musician known by its id are filed under it, unless
+		// several musicians share the name and the files can't be told apart
+		named, err := qtx.GetMusiciansByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(named) == 1 && named[0].MusicbrainzID.Valid {
+			return &named[0], nil
+		}
+	}
+
 	// Try Spotify lookup first if configured
-	if app.Spotify != nil {
+	if app.spotifyEnrichment() {
 		artist, err := app.Spotify.SearchArtistByName(name)
 		if err == nil && artist != nil {
 			// Check if we already have this Spotify artist
 			existing, err := qtx.GetMusicianBySpotifyID(ctx, sql.NullString{String: artist.ID.String(), Valid: true})
-			if err == nil {
+			if err == nil && mbid.Valid && existing.MusicbrainzID.Valid && existing.MusicbrainzID != mbid {
+				// A same-named musician with another MusicBrainz id already holds the
+				// Spotify artist, so this one is stored with basic data only
+				artist = nil
+			} else if err == nil {
 				// Even if musician exists, process Spotify genres to enrich the data
-				app.processSpotifyGenres(ctx, qtx, existing.ID, artist.Genres)
+				app.processSpotifyGenres(ctx, qtx, existing, artist.Genres)
+				if mbid.Valid && !existing.MusicbrainzID.Valid {
+					return app.setMusicianMusicbrainzID(ctx, qtx, existing, mbid)
+				}
 				return &existing, nil
 			}
+		}
 
+		if err == nil && artist != nil {
 			// Build thumb from Spotify artist images
 			var thumb sql.NullString
 			if len(artist.Images) > 0 {
@@ -97,13 +165,14 @@ func (app *Application) getOrCreateMusician(ctx context.Context, qtx *database.Q
 				SpotifyFollowers:  helpers.NullInt64(int64(artist.Followers.Count)),
 				SpotifyID:         sql.NullString{String: artist.ID.String(), Valid: true},
 				Thumb:             thumb,
+				MusicbrainzID:     mbid,
 			})
 			if err != nil {
 				return nil, err
 			}
 
 			// Process Spotify genres for this musician
-			app.processSpotifyGenres(ctx, qtx, musician.ID, artist.Genres)
+			app.processSpotifyGenres(ctx, qtx, musician, artist.Genres)
 
 			return &musician, nil
 		}
@@ -111,8 +180,9 @@ func (app *Application) getOrCreateMusician(ctx context.Context, qtx *database.Q
 
 	// Upsert with basic data only
 	musician, err := qtx.UpsertMusician(ctx, database.UpsertMusicianParams{
-		Name:     name,
-		SortName: sortName,
+		Name:          name,
+		SortName:      sortName,
+		MusicbrainzID: mbid,
 	})
 	if err != nil {
 		return nil, err
@@ -120,15 +190,36 @@ func (app *Application) getOrCreateMusician(ctx context.Context, qtx *database.Q
 	return &musician, nil
 }
 
+// setMusicianMusicbrainzID stores the MusicBrainz id of a musician first matched by name.
+func (app *Application) setMusicianMusicbrainzID(ctx context.Context, qtx *database.Queries, musician database.Musician, mbid sql.NullString) (*database.Musician, error) {
+	updated, err := qtx.SetMusicianMusicbrainzID(ctx, database.SetMusicianMusicbrainzIDParams{
+		MusicbrainzID: mbid,
+		ID:            musician.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set musician musicbrainz id failed: %w", err)
+	}
+	return &updated, nil
+}
+
+// spotifyEnrichment reports whether musicians and albums are looked up on Spotify: it
+// must be configured and enrichment enabled for the music library.
+func (app *Application) spotifyEnrichment() bool {
+	return app.Spotify != nil && app.Settings().MusicSpotifyEnrichment
+}
+
 // processSpotifyGenres creates genre entries and musician-genre relationships
-// for each genre provided by Spotify's artist data.
-func (app *Application) processSpotifyGenres(ctx context.Context, qtx *database.Queries, musicianID int64, spotifyGenres []string) {
+// for each genre provided by Spotify's artist data, unless the musician's genres
+// were edited by hand.
+func (app *Application) processSpotifyGenres(ctx context.Context, qtx *database.Queries, musician database.Musician, spotifyGenres []string) {
+	if helpers.IsFieldLocked(musician.LockedFields, "genres") {
+		return
+	}
+
+	musicianID := musician.ID
 	for _, genreTag := range spotifyGenres {
-		// Get or create the genre
-		genre, err := qtx.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{
-			Tag:       genreTag,
-			GenreType: "music",
-		})
+		// Resolve the genre through its aliases, creating it if unknown
+		genre, err := app.resolveGenre(ctx, qtx, genreTag, "music")
 		if err != nil {
 			app.Logger.Warn("failed to get/create Spotify genre",
 				"error", err,
@@ -153,27 +244,294 @@ func (app *Application) processSpotifyGenres(ctx context.Context, qtx *database.
 	}
 }
 
+// resolveGenre returns the canonical genre for a tag. The tag is reduced to its
+// normalized key and looked up in genre_aliases first, so "Hip-Hop" and "hip hop"
+// land on the same row; unknown keys create the genre and record the alias.
+func (app *Application) resolveGenre(ctx context.Context, qtx *database.Queries, tag, genreType string) (database.Genre, error) {
+	key := helpers.NormalizeKey(tag)
+	if key == "" {
+		return database.Genre{}, fmt.Errorf("genre %q has no letters or digits", tag)
+	}
+
+	genre, err := qtx.GetGenreByAlias(ctx, database.GetGenreByAliasParams{
+		Alias:     key,
+		GenreType: genreType,
+	})
+	if err == nil {
+		return genre, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.Genre{}, fmt.Errorf("genre alias lookup failed: %w", err)
+	}
+
+	genre, err = qtx.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{
+		Tag:       tag,
+		GenreType: genreType,
+	})
+	if err != nil {
+		return database.Genre{}, err
+	}
+
+	err = qtx.UpsertGenreAlias(ctx, database.UpsertGenreAliasParams{
+		Alias:     key,
+		GenreType: genreType,
+		GenreID:   genre.ID,
+	})
+	if err != nil {
+		return database.Genre{}, fmt.Errorf("genre alias failed: %w", err)
+	}
+
+	return genre, nil
+}
+
+// discFolderPattern matches per-disc subfolders ("CD1", "Disc 2", "disk_3") of an album.
+var discFolderPattern = regexp.MustCompile(`(?i)^(cd|disc|disk)[ _-]?\d+$`)
+
+// albumDirectory returns the folder an album without an album artist is keyed by:
+// the folder holding the track, or its parent for per-disc subfolders.
+func albumDirectory(path string) string {
+	dir := filepath.Dir(path)
+	if discFolderPattern.MatchString(filepath.Base(dir)) {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// isCompilationTag reports whether a COMPILATION/TCMP/cpil tag marks a compilation.
+func isCompilationTag(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// variousArtistsName returns the pseudo-musician compilations are filed under.
+func (app *Application) variousArtistsName() string {
+	if settings := app.Settings(); settings != nil && strings.TrimSpace(settings.VariousArtistsName) != "" {
+		return strings.TrimSpace(settings.VariousArtistsName)
+	}
+	return helpers.VARIOUS_ARTISTS_NAME
+}
+
+// isVariousArtists reports whether an album artist tag names the Various Artists
+// pseudo-musician rather than a real one.
+func (app *Application) isVariousArtists(albumArtist string) bool {
+	albumArtist = strings.TrimSpace(albumArtist)
+	return strings.EqualFold(albumArtist, app.variousArtistsName()) ||
+		strings.EqualFold(albumArtist, helpers.VARIOUS_ARTISTS_NAME) ||
+		strings.EqualFold(albumArtist, "VA")
+}
+
+// resolveTrackAlbum finds or creates the album a track belongs to. Albums are keyed by
+// their MusicBrainz release id, or by title and album artist, with two exceptions so
+// their tracks don't split into one album per track artist: compilations are filed
+// under the Various Artists pseudo-musician, and untagged albums without an album
+// artist are grouped by the folder holding their tracks.
+func (app *Application) resolveTrackAlbum(ctx context.Context, qtx *database.Queries, path string, tags ffprobe.FormatTags) (*database.Album, error) {
+	sortAlbum := tags.SortAlbum
+	if sortAlbum == "" {
+		sortAlbum = tags.Album
+	}
+
+	albumMBID := musicBrainzID(tags.MusicBrainzAlbumID)
+	releaseGroupID := musicBrainzID(tags.MusicBrainzReleaseGroupID)
+
+	if isCompilationTag(tags.Compilation) || app.isVariousArtists(tags.AlbumArtist) {
+		variousArtists := app.variousArtistsName()
+
+		album, err := app.getOrCreateAlbum(ctx, qtx, tags.Album, sortAlbum, variousArtists, albumMBID, releaseGroupID, false, newLocalAlbum(path, tags))
+		if err != nil {
+			return nil, err
+		}
+
+		if !album.IsCompilation {
+			if err := qtx.MarkAlbumCompilation(ctx, album.ID); err != nil {
+				return nil, fmt.Errorf("mark compilation failed: %w", err)
+			}
+			album.IsCompilation = true
+		}
+
+		// The pseudo-musician is never looked up on Spotify, it lists the compilations
+		musician, err := qtx.UpsertMusician(ctx, database.UpsertMusicianParams{
+			Name:     variousArtists,
+			SortName: variousArtists,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("various artists musician failed: %w", err)
+		}
+
+		err = qtx.CreateMusicianAlbum(ctx, database.CreateMusicianAlbumParams{
+			MusicianID: musician.ID,
+			AlbumID:    album.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("various artists relationship failed: %w", err)
+		}
+
+		return album, nil
+	}
+
+	if tags.AlbumArtist != "" || albumMBID != "" {
+		return app.getOrCreateAlbum(ctx, qtx, tags.Album, sortAlbum, tags.AlbumArtist, albumMBID, releaseGroupID, false, newLocalAlbum(path, tags))
+	}
+
+	dir := sql.NullString{String: albumDirectory(path), Valid: true}
+
+	existing, err := qtx.GetAlbumByDirectory(ctx, database.GetAlbumByDirectoryParams{
+		Directory: dir,
+		Title:     tags.Album,
+	})
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// The track artist stands in for the missing album artist
+	album, err := app.getOrCreateAlbum(ctx, qtx, tags.Album, sortAlbum, tags.Artist, "", "", true, newLocalAlbum(path, tags))
+	if err != nil {
+		return nil, err
+	}
+
+	if !album.Directory.Valid {
+		err = qtx.SetAlbumDirectory(ctx, database.SetAlbumDirectoryParams{
+			Directory: dir,
+			ID:        album.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("album directory failed: %w", err)
+		}
+		album.Directory = dir
+	}
+
+	return album, nil
+}
+
 // getOrCreateAlbum looks up or creates an album in the database.
-// If Spotify is configured, attempts to enrich the data with Spotify info.
-// Falls back to basic metadata if Spotify lookup fails.
-func (app *Application) getOrCreateAlbum(ctx context.Context, qtx *database.Queries, title, sortTitle, albumArtist string) (*database.Album, error) {
+// A MusicBrainz release id identifies the album on its own, so editions sharing a
+// title stay apart; without one the album is matched by title and album artist. Albums
+// with neither are grouped by the folder of their tracks and never matched to another
+// one: their artist is the track artist, left out when another album has the title
+// and artist already.
+// If Spotify is configured, attempts to enrich the data with Spotify info, checking
+// candidates against what the local files tell about the album (see matchSpotifyAlbum).
+// Falls back to basic metadata if Spotify lookup fails or no candidate is close enough.
+func (app *Application) getOrCreateAlbum(ctx context.Context, qtx *database.Queries, title, sortTitle, albumArtist, musicbrainzAlbumID, releaseGroupID string, grouped bool, local localAlbum) (*database.Album, error) {
+	mbid := helpers.NullString(musicbrainzAlbumID)
+	groupID := helpers.NullString(releaseGroupID)
+
+	// Grouped albums were looked up by folder already (see resolveTrackAlbum)
+	musician := helpers.NullString(albumArtist)
+	if grouped && musician.Valid {
+		taken, err := qtx.GetAlbumsByTitle(ctx, database.GetAlbumsByTitleParams{
+			Title:    title,
+			Musician: musician,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, album := range taken {
+			if !album.MusicbrainzAlbumID.Valid {
+				musician = sql.NullString{}
+				break
+			}
+		}
+	}
+
+	if mbid.Valid {
+		existing, err := qtx.GetAlbumByMusicbrainzID(ctx, mbid)
+		if err == nil {
+			if groupID.Valid && existing.MusicbrainzReleaseGroupID != groupID {
+				return app.setAlbumMusicbrainzIDs(ctx, qtx, existing, mbid, groupID)
+			}
+			return &existing, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		// An album scanned before its files were tagged takes the ids over
+		unmatched, err := qtx.GetUnmatchedAlbum(ctx, database.GetUnmatchedAlbumParams{
+			Musician: helpers.NullString(albumArtist),
+			Title:    title,
+		})
+		if err == nil {
+			return app.setAlbumMusicbrainzIDs(ctx, qtx, unmatched, mbid, groupID)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	} else if !grouped {
+		// An album renamed by hand is still found through the title in the tags
+		renamed, err := qtx.GetAlbumByScanTitle(ctx, database.GetAlbumByScanTitleParams{
+			ScanTitle: sql.NullString{String: title, Valid: true},
+			Musician:  helpers.NullString(albumArtist),
+		})
+		if err == nil {
+			return &renamed, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		// Untagged files of an album known by its release id are filed under it, unless
+		// several editions share the title and the files can't be told apart
+		titled, err := qtx.GetAlbumsByTitle(ctx, database.GetAlbumsByTitleParams{
+			Title:    title,
+			Musician: helpers.NullString(albumArtist),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(titled) == 1 && titled[0].MusicbrainzAlbumID.Valid {
+			return &titled[0], nil
+		}
+
+		// A Spotify album matched by hand is kept
+		for i := range titled {
+			if titled[i].SpotifyMatchLocked && !titled[i].MusicbrainzAlbumID.Valid {
+				return &titled[i], nil
+			}
+		}
+	}
+
 	// Try Spotify lookup first if configured
-	if app.Spotify != nil {
-		albumDetails, err := app.Spotify.SearchAndGetAlbumDetails(title)
+	var matchScore sql.NullFloat64
+	if app.spotifyEnrichment() {
+		albumDetails, score, err := app.matchSpotifyAlbum(title, albumArtist, local)
+		if err == nil {
+			matchScore = sql.NullFloat64{Float64: score, Valid: true}
+		}
 		if err == nil && albumDetails != nil {
 			// Check if we already have this Spotify album
 			existing, err := qtx.GetAlbumBySpotifyID(ctx, sql.NullString{String: albumDetails.ID.String(), Valid: true})
-			if err == nil {
+			if err == nil && (mbid.Valid && existing.MusicbrainzAlbumID.Valid && existing.MusicbrainzAlbumID != mbid ||
+				grouped && existing.Directory.String != local.directory) {
+				// Another edition or an album of another folder already holds the Spotify
+				// album, so this one is stored with basic data only
+				albumDetails = nil
+				matchScore = sql.NullFloat64{}
+			} else if err == nil {
+				if mbid.Valid && !existing.MusicbrainzAlbumID.Valid {
+					return app.setAlbumMusicbrainzIDs(ctx, qtx, existing, mbid, groupID)
+				}
 				return &existing, nil
 			}
+		}
 
+		if err == nil && albumDetails != nil {
 			// Build params with Spotify data
 			params := database.UpsertAlbumParams{
-				Title:             title,
-				SortTitle:         sortTitle,
-				SpotifyID:         sql.NullString{String: albumDetails.ID.String(), Valid: true},
-				SpotifyPopularity: helpers.NullFloat64(float64(albumDetails.Popularity)),
-				TotalTracks:       helpers.NullInt64(int64(albumDetails.TotalTracks)),
+				Title:                     title,
+				SortTitle:                 sortTitle,
+				SpotifyID:                 sql.NullString{String: albumDetails.ID.String(), Valid: true},
+				SpotifyPopularity:         helpers.NullFloat64(float64(albumDetails.Popularity)),
+				TotalTracks:               helpers.NullInt64(int64(albumDetails.TotalTracks)),
+				MusicbrainzAlbumID:        mbid,
+				MusicbrainzReleaseGroupID: groupID,
+				SpotifyMatchScore:         matchScore,
 			}
 
 			// Parse release date
@@ -184,9 +542,7 @@ func (app *Application) getOrCreateAlbum(ctx context.Context, qtx *database.Quer
 			}
 
 			// Album artist
-			if albumArtist != "" {
-				params.Musician = sql.NullString{String: albumArtist, Valid: true}
-			}
+			params.Musician = musician
 
 			// Store Spotify cover URL (local download planned for later)
 			if len(albumDetails.Images) > 0 {
@@ -199,16 +555,18 @@ func (app *Application) getOrCreateAlbum(ctx context.Context, qtx *database.Quer
 			}
 			return &album, nil
 		}
-		// Spotify failed, continue with basic metadata (silent failure as per design)
+		// Spotify failed or no candidate was close enough, continue with basic metadata
+		// (silent failure as per design)
 	}
 
 	// Upsert with basic data only
 	params := database.UpsertAlbumParams{
-		Title:     title,
-		SortTitle: sortTitle,
-	}
-	if albumArtist != "" {
-		params.Musician = sql.NullString{String: albumArtist, Valid: true}
+		Title:                     title,
+		SortTitle:                 sortTitle,
+		MusicbrainzAlbumID:        mbid,
+		MusicbrainzReleaseGroupID: groupID,
+		SpotifyMatchScore:         matchScore,
+		Musician:                  musician,
 	}
 
 	album, err := qtx.UpsertAlbum(ctx, params)
@@ -217,3 +575,53 @@ func (app *Application) getOrCreateAlbum(ctx context.Context, qtx *database.Quer
 	}
 	return &album, nil
 }
+
+// matchSpotifyAlbum searches Spotify for an album by artist and title and scores the
+// candidates against the local files. It returns the full details of the best one,
+// or nil when its score is below SPOTIFY_ALBUM_MATCH_THRESHOLD, along with that score.
+func (app *Application) matchSpotifyAlbum(title, albumArtist string, local localAlbum) (*spotify.FullAlbum, float64, error) {
+	// Compilations are searched by title only, the pseudo-musician is not on Spotify
+	artist := albumArtist
+	if app.isVariousArtists(artist) {
+		artist = ""
+	}
+
+	candidates, err := app.Spotify.SearchAlbums(title, artist)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	local.trackCount = local.tracks(&app.albumTrackCounts)
+	best, score := bestSpotifyAlbum(title, artist, local, candidates)
+	if best == nil {
+		return nil, 0, fmt.Errorf("no spotify albums for %q", title)
+	}
+	if score < helpers.SPOTIFY_ALBUM_MATCH_THRESHOLD {
+		app.Logger.Debug("rejected spotify album match", "title", title, "artist", artist, "candidate", best.Name, "score", score)
+		return nil, score, nil
+	}
+
+	album, err := app.Spotify.GetAlbumByID(best.ID.String())
+	if err != nil {
+		return nil, 0, err
+	}
+	return album, score, nil
+}
+
+// setAlbumMusicbrainzIDs stores the MusicBrainz release and release group ids of an
+// album first matched by title, or whose release group was tagged later.
+func (app *Application) setAlbumMusicbrainzIDs(ctx context.Context, qtx *database.Queries, album database.Album, mbid, groupID sql.NullString) (*database.Album, error) {
+	if !groupID.Valid {
+		groupID = album.MusicbrainzReleaseGroupID
+	}
+
+	updated, err := qtx.SetAlbumMusicbrainzIDs(ctx, database.SetAlbumMusicbrainzIDsParams{
+		MusicbrainzAlbumID:        mbid,
+		MusicbrainzReleaseGroupID: groupID,
+		ID:                        album.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set album musicbrainz ids failed: %w", err)
+	}
+	return &updated, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// lyricsSidecar is a .lrc file named after an audio file in the same directory.
+type lyricsSidecar struct {
+	path string
+	// modTime is when the sidecar was last modified, so edits to it are rescanned
+	// even though the audio file itself is unchanged
+	modTime time.Time
+}
+
+// lyricsSidecarsInDir returns the .lrc files in dir keyed by the path of the audio
+// file they belong to. Names are matched without extension and case.
+func (app *Application) lyricsSidecarsInDir(dir string) map[string]*lyricsSidecar {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		app.Logger.Warn("failed to read directory for lyrics", "error", err, "dir", dir)
+		return nil
+	}
+
+	sidecars := make(map[string]*lyricsSidecar)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(name), ".lrc") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			app.Logger.Warn("failed to stat lyrics file", "error", err, "path", filepath.Join(dir, name))
+			continue
+		}
+
+		stem := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+		sidecars[stem] = &lyricsSidecar{path: filepath.Join(dir, name), modTime: info.ModTime()}
+	}
+
+	if len(sidecars) == 0 {
+		return nil
+	}
+
+	var files map[string]*lyricsSidecar
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !helpers.ValidAudioExtensions[strings.ToLower(helpers.GetFileExtension(name))] {
+			continue
+		}
+
+		sidecar, ok := sidecars[strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))]
+		if !ok {
+			continue
+		}
+
+		if files == nil {
+			files = make(map[string]*lyricsSidecar)
+		}
+		files[filepath.Join(dir, name)] = sidecar
+	}
+
+	return files
+}
+
+// lyricsSource returns where the scanner reads a file's lyrics from: its .lrc sidecar
+// when it has one, its tags otherwise.
+func lyricsSource(sidecar *lyricsSidecar) string {
+	if sidecar != nil {
+		return helpers.LYRICS_SOURCE_SIDECAR
+	}
+	return helpers.LYRICS_SOURCE_EMBEDDED
+}
+
+// checkLyricsUnchanged reports whether a track's lyrics were read after its sidecar, if
+// any, was last modified and from the source the file has now, so tracks scanned before
+// lyrics were supported and tracks whose sidecar was added or removed are read again.
+func checkLyricsUnchanged(ctx context.Context, qtx *database.Queries, file trackFile) bool {
+	var modTime string
+	if file.lyrics != nil {
+		modTime = helpers.SQLiteTime(file.lyrics.modTime)
+	}
+
+	_, err := qtx.CheckLyricsUnchanged(ctx, database.CheckLyricsUnchangedParams{
+		FilePath:     file.path,
+		ModifiedAt:   sql.NullString{String: modTime, Valid: true},
+		LyricsSource: lyricsSource(file.lyrics),
+	})
+	return err == nil
+}
+
+// saveTrackLyrics stores the lyrics of an audio file, see readTrackLyrics.
+func (app *Application) saveTrackLyrics(ctx context.Context, qtx *database.Queries, trackID int64, path, ext string, tags ffprobe.FormatTags, sidecar *lyricsSidecar) error {
+	params := app.readTrackLyrics(path, ext, tags, sidecar)
+	params.TrackID = trackID
+	return saveScannedLyrics(ctx, qtx, params)
+}
+
+// readTrackLyrics returns the lyrics of an audio file, without a track id. A .lrc
+// sidecar wins over an ID3 SYLT frame, which wins over unsynchronized lyrics tags.
+// Content is empty when the file has none.
+func (app *Application) readTrackLyrics(path, ext string, tags ffprobe.FormatTags, sidecar *lyricsSidecar) database.UpsertScannedLyricsParams {
+	params := database.UpsertScannedLyricsParams{Source: helpers.LYRICS_SOURCE_EMBEDDED}
+
+	if sidecar != nil {
+		data, err := os.ReadFile(sidecar.path)
+		if err != nil {
+			app.Logger.Warn("failed to read lyrics file", "error", err, "path", sidecar.path)
+		} else {
+			params.Content = helpers.DecodeLyricsText(data)
+			params.Source = helpers.LYRICS_SOURCE_SIDECAR
+		}
+	}
+
+	// ffprobe doesn't expose SYLT frames, so MP3 files are read for them directly
+	if params.Content == "" && ext == "mp3" {
+		lines, language, err := helpers.ReadSyncedLyrics(path)
+		if err != nil {
+			app.Logger.Warn("failed to read synced lyrics", "error", err, "path", path)
+		}
+
+		if len(lines) > 0 {
+			params.Content = helpers.FormatLRC(lines)
+			params.Language = sql.NullString{String: language, Valid: language != ""}
+			params.Source = helpers.LYRICS_SOURCE_EMBEDDED
+		}
+	}
+
+	if params.Content == "" {
+		params.Content = helpers.DecodeLyricsText([]byte(tags.Lyrics))
+		params.Language = sql.NullString{String: tags.LyricsLanguage, Valid: tags.LyricsLanguage != ""}
+		params.Source = helpers.LYRICS_SOURCE_EMBEDDED
+	}
+
+	return params
+}
+
+// saveScannedLyrics stores scanned lyrics, or removes them when Content is empty, and
+// marks the track's lyrics as read. Tag lyrics written in LRC format count as synced.
+// Lyrics entered by a user are never replaced.
+func saveScannedLyrics(ctx context.Context, qtx *database.Queries, params database.UpsertScannedLyricsParams) error {
+	if params.Content == "" {
+		if err := qtx.DeleteScannedLyrics(ctx, params.TrackID); err != nil {
+			return fmt.Errorf("delete lyrics failed: %w", err)
+		}
+	} else {
+		_, params.Synced = helpers.ParseLRC(params.Content)
+
+		if err := qtx.UpsertScannedLyrics(ctx, params); err != nil {
+			return fmt.Errorf("upsert lyrics failed: %w", err)
+		}
+	}
+
+	if err := qtx.SetTrackLyricsScanned(ctx, params.TrackID); err != nil {
+		return fmt.Errorf("mark lyrics scanned failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+)
+
+const (
+	nirvanaUKID  = "9282c8b4-ca0b-4c6b-b7e3-4f7762dfc4d6"
+	nirvanaUSID  = "5b11f4ce-a62d-471e-81fc-a69a8278c7da"
+	nevermindID  = "1b022e01-4da6-387b-8658-8678046e4cef"
+	deluxeID     = "2fd6b5a9-3c13-4e7b-8b4b-7fb8a7c2b2a1"
+	nevermindRG  = "1b022e01-4da6-387b-8658-8678046e4cf0"
+	smellsLikeID = "5fb524f1-8cc8-4c04-a921-e34c0a911ea7"
+)
+
+// TestProcessMusicBatch_MusicBrainzIDs tests that MusicBrainz ids keep same-named
+// musicians and same-titled editions apart, and that musicians and albums scanned
+// before their files were tagged take the ids over instead of being duplicated.
+func TestProcessMusicBatch_MusicBrainzIDs(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	fake := &fakeTaggedFfprobe{tags: map[string]ffprobe.FormatTags{
+		// Scanned before the library was tagged
+		"/music/untagged/01.flac": {Title: "Lithium", Artist: "Nirvana", AlbumArtist: "Nirvana", Album: "Nevermind"},
+	}}
+	app.Ffprobe = fake
+
+	ctx := context.Background()
+
+	if scanned, _, errCount := app.processMusicBatch(ctx, []trackFile{{path: "/music/untagged/01.flac", ext: "flac", size: 4}}); scanned != 1 || errCount != 0 {
+		t.Fatalf("Expected the untagged track to be scanned, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	fake.tags = map[string]ffprobe.FormatTags{
+		"/music/nevermind/01.flac": {
+			Title: "Smells Like Teen Spirit", Artist: "Nirvana", AlbumArtist: "Nirvana", Album: "Nevermind",
+			MusicBrainzTrackID: smellsLikeID, MusicBrainzAlbumID: nevermindID,
+			MusicBrainzArtistID: nirvanaUSID, MusicBrainzReleaseGroupID: nevermindRG,
+		},
+		// Upper case and padding are normalized
+		"/music/deluxe/01.mp3": {
+			Title: "Smells Like Teen Spirit", Artist: "Nirvana", AlbumArtist: "Nirvana", Album: "Nevermind",
+			MusicBrainzAlbumID: " " + strings.ToUpper(deluxeID), MusicBrainzArtistID: nirvanaUSID,
+		},
+		"/music/local/01.flac": {
+			Title: "Tiny Goddess", Artist: "Nirvana", AlbumArtist: "Nirvana", Album: "Nirvana",
+			MusicBrainzArtistID: nirvanaUKID,
+		},
+		// Several artist ids identify no single musician
+		"/music/feat/01.flac": {
+			Title: "Duet", Artist: "Nirvana feat. Someone", Album: "Nevermind",
+			MusicBrainzAlbumID: nevermindID, MusicBrainzArtistID: nirvanaUSID + "; " + nirvanaUKID,
+		},
+	}
+
+	files := []trackFile{
+		{path: "/music/nevermind/01.flac", ext: "flac", size: 4},
+		{path: "/music/deluxe/01.mp3", ext: "mp3", size: 4},
+		{path: "/music/local/01.flac", ext: "flac", size: 4},
+		{path: "/music/feat/01.flac", ext: "flac", size: 4},
+	}
+	if scanned, _, errCount := app.processMusicBatch(ctx, files); scanned != len(files) || errCount != 0 {
+		t.Fatalf("Expected %d tracks scanned, got %d scanned and %d errors", len(files), scanned, errCount)
+	}
+
+	trackAt := func(path string) database.Track {
+		t.Helper()
+		var id int64
+		if err := app.DB.QueryRow("SELECT id FROM tracks WHERE file_path = ?", path).Scan(&id); err != nil {
+			t.Fatalf("Failed to find %s: %v", path, err)
+		}
+		track, err := app.Queries.GetTrack(ctx, id)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", path, err)
+		}
+		return track
+	}
+
+	untagged := trackAt("/music/untagged/01.flac")
+	nevermind := trackAt("/music/nevermind/01.flac")
+	deluxe := trackAt("/music/deluxe/01.mp3")
+	local := trackAt("/music/local/01.flac")
+	feat := trackAt("/music/feat/01.flac")
+
+	if nevermind.MusicbrainzTrackID.String != smellsLikeID {
+		t.Errorf("Expected the recording id to be stored, got %q", nevermind.MusicbrainzTrackID.String)
+	}
+
+	// The untagged musician and album took the ids over
+	if nevermind.MusicianID != untagged.MusicianID || nevermind.AlbumID != untagged.AlbumID {
+		t.Error("Expected the tagged track to join the musician and album scanned before tagging")
+	}
+
+	album, err := app.Queries.GetAlbumByID(ctx, nevermind.AlbumID.Int64)
+	if err != nil {
+		t.Fatalf("Failed to get album: %v", err)
+	}
+	if album.MusicbrainzAlbumID.String != nevermindID || album.MusicbrainzReleaseGroupID.String != nevermindRG {
+		t.Errorf("Unexpected album ids: %q, %q", album.MusicbrainzAlbumID.String, album.MusicbrainzReleaseGroupID.String)
+	}
+
+	if deluxe.AlbumID == nevermind.AlbumID {
+		t.Error("Expected the deluxe edition to be a separate album")
+	}
+	if deluxe.MusicianID != nevermind.MusicianID {
+		t.Error("Expected both editions to share the musician")
+	}
+
+	if local.MusicianID == nevermind.MusicianID {
+		t.Error("Expected the same-named band to be a separate musician")
+	}
+
+	us, err := app.Queries.GetMusicianByID(ctx, nevermind.MusicianID.Int64)
+	if err != nil {
+		t.Fatalf("Failed to get musician: %v", err)
+	}
+	uk, err := app.Queries.GetMusicianByID(ctx, local.MusicianID.Int64)
+	if err != nil {
+		t.Fatalf("Failed to get musician: %v", err)
+	}
+	if us.MusicbrainzID.String != nirvanaUSID || uk.MusicbrainzID.String != nirvanaUKID {
+		t.Errorf("Unexpected musician ids: %q, %q", us.MusicbrainzID.String, uk.MusicbrainzID.String)
+	}
+
+	featMusician, err := app.Queries.GetMusicianByID(ctx, feat.MusicianID.Int64)
+	if err != nil {
+		t.Fatalf("Failed to get musician: %v", err)
+	}
+	if featMusician.MusicbrainzID.Valid {
+		t.Errorf("Expected no id for a track with several artist ids, got %q", featMusician.MusicbrainzID.String)
+	}
+	if feat.AlbumID != nevermind.AlbumID {
+		t.Error("Expected the release id to find the album without an album artist")
+	}
+
+	// Untagged files are ambiguous now that two musicians are called Nirvana
+	fake.tags = map[string]ffprobe.FormatTags{
+		"/music/untagged/02.flac": {Title: "Polly", Artist: "Nirvana", AlbumArtist: "Nirvana", Album: "Nevermind"},
+	}
+	if scanned, _, errCount := app.processMusicBatch(ctx, []trackFile{{path: "/music/untagged/02.flac", ext: "flac", size: 4}}); scanned != 1 || errCount != 0 {
+		t.Fatalf("Expected the untagged track to be scanned, got %d scanned and %d errors", scanned, errCount)
+	}
+
+	polly := trackAt("/music/untagged/02.flac")
+	if polly.MusicianID.Int64 == us.ID || polly.MusicianID.Int64 == uk.ID {
+		t.Error("Expected an untagged track by an ambiguous name to get a musician without an id")
+	}
+	if polly.AlbumID == nevermind.AlbumID || polly.AlbumID == deluxe.AlbumID {
+		t.Error("Expected an untagged track of an ambiguous title to get an album without an id")
+	}
+}
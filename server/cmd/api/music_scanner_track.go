@@ -5,42 +5,55 @@ import (
 	"database/sql"
 	"fmt"
 	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
 	"igloo/cmd/internal/helpers"
 	"path/filepath"
 	"strconv"
 )
 
 // processTrackFile extracts metadata from an audio file and upserts it into the database.
-// Handles related entities (musician, album, genre) creation and linking.
-func (app *Application) processTrackFile(ctx context.Context, qtx *database.Queries, path, ext string) error {
+// Handles related entities (musician, album, genre) creation and linking, and the
+// track's lyrics, read from the file or its .lrc sidecar.
+func (app *Application) processTrackFile(ctx context.Context, qtx *database.Queries, path, ext string, sidecar *lyricsSidecar) error {
 	info, err := app.Ffprobe.GetMetadata(path)
 	if err != nil {
-		return fmt.Errorf("ffprobe failed: %w", err)
+		return scanPhaseError(helpers.SCAN_PHASE_FFPROBE, fmt.Errorf("ffprobe failed: %w", err))
 	}
 
-	params := database.UpsertTrackParams{
-		FilePath: path,
-		FileName: filepath.Base(path),
+	params := audioTrackParams(info, path, ext)
+
+	if belowMinDuration(params.Duration, app.Settings().MusicMinDuration) {
+		return errSampleFile
 	}
 
-	// Title - use filename if not available
-	if info.Format.Tags.Title != "" {
-		params.Title = info.Format.Tags.Title
-	} else {
-		params.Title = filepath.Base(path)
+	// The file is indexed whole, so tracks a CUE sheet used to cut from it are gone
+	if err := qtx.DeleteCueTracks(ctx, sql.NullString{String: path, Valid: true}); err != nil {
+		return fmt.Errorf("delete cue tracks failed: %w", err)
 	}
 
-	// Sort title - use title if not available
-	if info.Format.Tags.SortName != "" {
-		params.SortTitle = info.Format.Tags.SortName
-	} else {
-		params.SortTitle = params.Title
+	// Ogg Vorbis/Opus keep their tags on the audio stream, AudioTags merges both locations
+	tags := info.AudioTags()
+
+	track, err := app.saveTrack(ctx, qtx, params, tags)
+	if err != nil {
+		return err
+	}
+
+	return app.saveTrackLyrics(ctx, qtx, track.ID, path, ext, tags, sidecar)
+}
+
+// audioTrackParams fills the track fields that describe the audio file itself:
+// path, container, size, duration, bit rate and the audio stream.
+func audioTrackParams(info *ffprobe.FfprobeResult, path, ext string) database.UpsertTrackParams {
+	params := database.UpsertTrackParams{
+		FilePath: path,
+		FileName: filepath.Base(path),
 	}
 
-	// Container (file extension) and MIME type
-	params.Container = ext
+	// Container (normalized file extension) and MIME type
+	params.Container = helpers.AudioContainers[ext]
 
-	mimeType, ok := helpers.AudioMimeTypes[ext]
+	mimeType, ok := helpers.AudioMimeTypes[params.Container]
 	if ok {
 		params.MimeType = mimeType
 	}
@@ -61,73 +74,115 @@ func (app *Application) processTrackFile(ctx context.Context, qtx *database.Quer
 		}
 	}
 
+	// Parse bit rate
+	if info.Format.BitRate != "" {
+		params.BitRate = helpers.ParseBitRate(info.Format.BitRate)
+	}
+
+	// Extract audio stream info (codec, channels, profile, language).
+	// The codec comes from the stream, not the extension: m4a may hold AAC or ALAC,
+	// ogg may hold Vorbis, Opus or FLAC, and wav/aiff report their PCM sample format.
+	if stream, ok := info.AudioStream(); ok {
+		params.Codec = stream.CodecName
+		params.Profile = stream.Profile
+
+		// Channel info
+		if stream.ChannelLayout != "" {
+			params.Channels = stream.ChannelLayout
+			params.ChannelLayout = stream.ChannelLayout
+		} else {
+			params.Channels = strconv.Itoa(stream.Channels)
+			params.ChannelLayout = strconv.Itoa(stream.Channels)
+		}
+
+		// Language from stream tags
+		if stream.Tags.Language != "" {
+			params.Language = sql.NullString{String: stream.Tags.Language, Valid: true}
+		}
+	}
+
+	return params
+}
+
+// saveTrack fills the tagged fields of params, links the musician, album and genres
+// from the tags and upserts the track.
+func (app *Application) saveTrack(ctx context.Context, qtx *database.Queries, params database.UpsertTrackParams, tags ffprobe.FormatTags) (database.Track, error) {
+	// Title - use filename if not available
+	if tags.Title != "" {
+		params.Title = tags.Title
+	} else {
+		params.Title = params.FileName
+	}
+
+	// Sort title - use title if not available
+	if tags.SortName != "" {
+		params.SortTitle = tags.SortName
+	} else {
+		params.SortTitle = params.Title
+	}
+
 	// Parse track index from "1/12" format
-	if info.Format.Tags.Track != "" {
-		index, err := helpers.ParseSlashNumber(info.Format.Tags.Track)
+	if tags.Track != "" {
+		index, err := helpers.ParseSlashNumber(tags.Track)
 		if err == nil {
 			params.TrackIndex = index
 		}
 	}
 
-	// Parse bit rate
-	if info.Format.BitRate != "" {
-		params.BitRate = helpers.ParseBitRate(info.Format.BitRate)
-	}
-
 	// Parse disc number from "1/2" format
-	if info.Format.Tags.Disc != "" {
-		disc, err := helpers.ParseSlashNumber(info.Format.Tags.Disc)
+	if tags.Disc != "" {
+		disc, err := helpers.ParseSlashNumber(tags.Disc)
 		if err == nil {
 			params.Disc = disc
 		}
 	}
 
 	// Optional text fields
-	params.Copyright = helpers.NullString(info.Format.Tags.Copyright)
-	params.Composer = helpers.NullString(info.Format.Tags.Composer)
+	params.Copyright = helpers.NullString(tags.Copyright)
+	params.Composer = helpers.NullString(tags.Composer)
+	params.MusicbrainzTrackID = helpers.NullString(musicBrainzID(tags.MusicBrainzTrackID))
 
 	// Parse release date
-	if info.Format.Tags.Date != "" {
-		date, err := helpers.ParseDate(info.Format.Tags.Date)
+	if tags.Date != "" {
+		date, err := helpers.ParseDate(tags.Date)
 		if err == nil {
 			params.ReleaseDate = sql.NullString{String: date.Format("2006-01-02"), Valid: true}
 			params.Year = sql.NullInt64{Int64: int64(date.Year()), Valid: true}
 		}
 	}
 
-	// Get or create musician if artist tag exists
+	// Get or create musician if artist tag exists. Genres edited by hand on the
+	// track, musician or album are not touched by the tags.
 	var musicianID sql.NullInt64
+	var musicianGenresLocked, albumGenresLocked bool
 
-	if info.Format.Tags.Artist != "" {
-		sortArtist := info.Format.Tags.SortArtist
+	if tags.Artist != "" {
+		sortArtist := tags.SortArtist
 		if sortArtist == "" {
-			sortArtist = info.Format.Tags.Artist
+			sortArtist = tags.Artist
 		}
 
-		musician, err := app.getOrCreateMusician(ctx, qtx, info.Format.Tags.Artist, sortArtist)
+		musician, err := app.getOrCreateMusician(ctx, qtx, tags.Artist, sortArtist, musicBrainzID(tags.MusicBrainzArtistID))
 		if err != nil {
-			return fmt.Errorf("musician failed: %w", err)
+			return database.Track{}, fmt.Errorf("musician failed: %w", err)
 		}
 
 		musicianID = sql.NullInt64{Int64: musician.ID, Valid: true}
+		musicianGenresLocked = helpers.IsFieldLocked(musician.LockedFields, "genres")
 	}
 	params.MusicianID = musicianID
 
 	// Get or create album if album tag exists
 	var albumID sql.NullInt64
 
-	if info.Format.Tags.Album != "" {
-		sortAlbum := info.Format.Tags.SortAlbum
-		if sortAlbum == "" {
-			sortAlbum = info.Format.Tags.Album
-		}
-
-		album, err := app.getOrCreateAlbum(ctx, qtx, info.Format.Tags.Album, sortAlbum, info.Format.Tags.AlbumArtist)
+	if tags.Album != "" {
+		album, err := app.resolveTrackAlbum(ctx, qtx, params.FilePath, tags)
 		if err != nil {
-			return fmt.Errorf("album failed: %w", err)
+			return database.Track{}, fmt.Errorf("album failed: %w", err)
 		}
 
 		albumID = sql.NullInt64{Int64: album.ID, Valid: true}
+		albumGenresLocked = helpers.IsFieldLocked(album.LockedFields, "genres")
 	}
 	params.AlbumID = albumID
 
@@ -139,58 +194,34 @@ func (app *Application) processTrackFile(ctx context.Context, qtx *database.Quer
 		})
 
 		if err != nil {
-			return fmt.Errorf("musician-album relationship failed: %w", err)
-		}
-	}
-
-	// Extract audio stream info (codec, channels, profile, language)
-	for _, stream := range info.Streams {
-		if stream.CodecType == "audio" {
-			params.Codec = stream.CodecName
-			params.Profile = stream.Profile
-
-			// Channel info
-			if stream.ChannelLayout != "" {
-				params.Channels = stream.ChannelLayout
-				params.ChannelLayout = stream.ChannelLayout
-			} else {
-				params.Channels = strconv.Itoa(stream.Channels)
-				params.ChannelLayout = strconv.Itoa(stream.Channels)
-			}
-
-			// Language from stream tags
-			if stream.Tags.Language != "" {
-				params.Language = sql.NullString{String: stream.Tags.Language, Valid: true}
-			}
-
-			break // Only process first audio stream
+			return database.Track{}, fmt.Errorf("musician-album relationship failed: %w", err)
 		}
 	}
 
 	track, err := qtx.UpsertTrack(ctx, params)
 	if err != nil {
-		return fmt.Errorf("upsert track failed: %w", err)
+		return track, fmt.Errorf("upsert track failed: %w", err)
 	}
 
-	// Handle genre (optimized: only delete stale genres, skip if unchanged)
-	if info.Format.Tags.Genre != "" {
-		genre, err := qtx.GetOrCreateGenre(ctx, database.GetOrCreateGenreParams{
-			Tag:       info.Format.Tags.Genre,
-			GenreType: "music",
-		})
-
-		if err != nil {
-			return fmt.Errorf("genre failed: %w", err)
-		}
+	// Handle genres: a single tag may hold several ("Rock; Alternative"), and each
+	// one is resolved through genre_aliases so spelling variants share a genre row.
+	// Links are rebuilt on every scan so genres removed from the tag are dropped.
+	// Genres edited by hand on the track replace its tag, which is then ignored:
+	// no genre rows are created for it and nothing is passed on to the musician
+	// or album.
+	if helpers.IsFieldLocked(track.LockedFields, "genres") {
+		return track, nil
+	}
 
-		// Delete only stale genres (those that don't match the new one)
-		err = qtx.DeleteTrackGenresExcept(ctx, database.DeleteTrackGenresExceptParams{
-			TrackID: track.ID,
-			GenreID: genre.ID,
-		})
+	err = qtx.DeleteTrackGenres(ctx, track.ID)
+	if err != nil {
+		return track, fmt.Errorf("delete stale genres failed: %w", err)
+	}
 
+	for _, genreTag := range helpers.SplitGenres(tags.Genre) {
+		genre, err := app.resolveGenre(ctx, qtx, genreTag, "music")
 		if err != nil {
-			return fmt.Errorf("delete stale genres failed: %w", err)
+			return track, fmt.Errorf("genre failed: %w", err)
 		}
 
 		// Create track-genre relationship (ON CONFLICT DO NOTHING handles duplicates)
@@ -200,11 +231,11 @@ func (app *Application) processTrackFile(ctx context.Context, qtx *database.Quer
 		})
 
 		if err != nil {
-			return fmt.Errorf("track-genre relationship failed: %w", err)
+			return track, fmt.Errorf("track-genre relationship failed: %w", err)
 		}
 
 		// Create musician-genre relationship (if musician exists)
-		if musicianID.Valid {
+		if musicianID.Valid && !musicianGenresLocked {
 			err = qtx.UpsertMusicianGenre(ctx, database.UpsertMusicianGenreParams{
 				MusicianID: musicianID.Int64,
 				GenreID:    genre.ID,
@@ -220,7 +251,7 @@ func (app *Application) processTrackFile(ctx context.Context, qtx *database.Quer
 		}
 
 		// Create album-genre relationship (if album exists)
-		if albumID.Valid {
+		if albumID.Valid && !albumGenresLocked {
 			err = qtx.UpsertAlbumGenre(ctx, database.UpsertAlbumGenreParams{
 				AlbumID: albumID.Int64,
 				GenreID: genre.ID,
@@ -236,5 +267,5 @@ func (app *Application) processTrackFile(ctx context.Context, qtx *database.Quer
 		}
 	}
 
-	return nil
+	return track, nil
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// actingDepartment is the department cast roles are listed under, as TMDB names it.
+const actingDepartment = "Acting"
+
+// GetPeople returns a paginated list of the people credited in the library's movies,
+// the most credited first.
+// Supports query parameters: q (name search), limit (default 50, max 100), offset (default 0)
+func (app *Application) GetPeople(w http.ResponseWriter, r *http.Request) {
+	limit, offset := parseStatsPaginationParams(r, 50, 100)
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	pattern := helpers.EscapeLike(query)
+
+	total, err := app.Queries.SearchArtistsCount(r.Context(), pattern)
+	if err != nil {
+		app.Logger.Error("failed to get people count", "error", err, "query", query)
+		helpers.ErrorJSON(w, errors.New("failed to fetch people count"))
+		return
+	}
+
+	rows, err := app.Queries.SearchArtists(r.Context(), database.SearchArtistsParams{
+		Query:  pattern,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		app.Logger.Error("failed to search people", "error", err, "query", query)
+		helpers.ErrorJSON(w, errors.New("failed to fetch people"))
+		return
+	}
+
+	people := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		people = append(people, map[string]any{
+			"id":          row.ID,
+			"tmdb_id":     row.TmdbID,
+			"name":        row.Name,
+			"profile":     tmdbImageOrNil(row.Profile, helpers.TMDB_PROFILE_SIZE),
+			"movie_count": row.MovieCount,
+		})
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"people":   people,
+			"total":    total,
+			"offset":   offset,
+			"limit":    limit,
+			"has_more": offset+limit < total,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetPerson returns a person's profile with every movie of the library they acted in or
+// crewed on, grouped by department: Acting first, then the crew departments by name.
+func (app *Application) GetPerson(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid person id"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	artist, err := app.Queries.GetArtistByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("person not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get person", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch person from server"))
+		return
+	}
+
+	cast, err := app.Queries.GetCastByArtistID(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get cast credits for person", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch person credits"))
+		return
+	}
+
+	crew, err := app.Queries.GetCrewByArtistID(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get crew credits for person", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch person credits"))
+		return
+	}
+
+	departments := make([]map[string]any, 0)
+	movies := make(map[int64]bool)
+
+	if len(cast) > 0 {
+		credits := make([]map[string]any, 0, len(cast))
+		for _, c := range cast {
+			credit := personCreditToMap(c.MovieID, c.Title, c.PosterPath, c.Year, c.ReleaseDate)
+			credit["character"] = c.Character
+			credits = append(credits, credit)
+			movies[c.MovieID] = true
+		}
+		departments = append(departments, map[string]any{"department": actingDepartment, "credits": credits})
+	}
+
+	// crew credits come sorted by department
+	var credits []map[string]any
+	for i, c := range crew {
+		credit := personCreditToMap(c.MovieID, c.Title, c.PosterPath, c.Year, c.ReleaseDate)
+		credit["job"] = c.Job
+		credits = append(credits, credit)
+		movies[c.MovieID] = true
+
+		if i == len(crew)-1 || crew[i+1].Department != c.Department {
+			departments = append(departments, map[string]any{"department": c.Department, "credits": credits})
+			credits = nil
+		}
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"person": map[string]any{
+				"id":      artist.ID,
+				"tmdb_id": artist.TmdbID,
+				"name":    artist.Name,
+				"profile": tmdbImageOrNil(artist.Profile, helpers.TMDB_PROFILE_SIZE),
+			},
+			"movie_count": len(movies),
+			"departments": departments,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// personCreditToMap builds the movie part of a filmography entry.
+func personCreditToMap(movieID int64, title string, posterPath sql.NullString, year sql.NullInt64, releaseDate sql.NullString) map[string]any {
+	credit := map[string]any{
+		"movie_id":     movieID,
+		"title":        title,
+		"poster":       tmdbImageOrNil(posterPath, helpers.TMDB_POSTER_SIZE),
+		"year":         nil,
+		"release_date": releaseDate.String,
+	}
+	if year.Valid {
+		credit["year"] = year.Int64
+	}
+	return credit
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type peopleResponse struct {
+	Data struct {
+		People []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			MovieCount int64  `json:"movie_count"`
+		} `json:"people"`
+		Total   int64 `json:"total"`
+		HasMore bool  `json:"has_more"`
+	} `json:"data"`
+}
+
+type personResponse struct {
+	Data struct {
+		Person struct {
+			Name string `json:"name"`
+		} `json:"person"`
+		MovieCount  int `json:"movie_count"`
+		Departments []struct {
+			Department string `json:"department"`
+			Credits    []struct {
+				Title     string `json:"title"`
+				Year      int    `json:"year"`
+				Character string `json:"character"`
+				Job       string `json:"job"`
+			} `json:"credits"`
+		} `json:"departments"`
+	} `json:"data"`
+}
+
+// TestPeople tests the people search and a person's filmography across the movies of
+// the library.
+func TestPeople(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+
+	paths := []string{"/movies/Alien (1979).mkv", "/movies/Aliens (1986).mkv"}
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{paths[0]: 1080, paths[1]: 1080}}
+	app.Tmdb = newFakeTmdb(t, tmdbAlien, tmdbAliens)
+
+	cache := newMovieScannerCache()
+	for _, path := range paths {
+		if err := app.processMovieFile(ctx, app.Queries, path, "mkv", 1000, cache); err != nil {
+			t.Fatalf("processMovieFile(%q) failed: %v", path, err)
+		}
+	}
+
+	search := func(target string) peopleResponse {
+		t.Helper()
+
+		rr := httptest.NewRecorder()
+		app.GetPeople(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		var res peopleResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return res
+	}
+
+	all := search("/api/people?limit=2")
+	if all.Data.Total != 3 || len(all.Data.People) != 2 || !all.Data.HasMore {
+		t.Fatalf("Expected the first 2 of 3 people, got %+v", all.Data)
+	}
+	if all.Data.People[0].Name != "Sigourney Weaver" || all.Data.People[0].MovieCount != 2 {
+		t.Errorf("Expected the most credited person first, got %+v", all.Data.People[0])
+	}
+
+	found := search("/api/people?q=CAMERON")
+	if found.Data.Total != 1 || len(found.Data.People) != 1 || found.Data.People[0].Name != "James Cameron" {
+		t.Fatalf("Expected James Cameron, got %+v", found.Data)
+	}
+
+	// Wildcards are searched for literally
+	for _, q := range []string{"_", "%25", "%5C"} {
+		if found := search("/api/people?q=" + q); found.Data.Total != 0 || len(found.Data.People) != 0 {
+			t.Errorf("Expected nobody named with %q, got %+v", q, found.Data)
+		}
+	}
+
+	person := func(id string) (*httptest.ResponseRecorder, personResponse) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/people/"+id, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+		rr := httptest.NewRecorder()
+		app.GetPerson(rr, req)
+
+		var res personResponse
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return rr, res
+	}
+
+	rr, weaver := person(strconv.FormatInt(all.Data.People[0].ID, 10))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if weaver.Data.MovieCount != 2 || len(weaver.Data.Departments) != 1 || weaver.Data.Departments[0].Department != "Acting" {
+		t.Fatalf("Expected two movies under Acting, got %+v", weaver.Data)
+	}
+	credits := weaver.Data.Departments[0].Credits
+	if len(credits) != 2 || credits[0].Title != "Aliens" || credits[0].Character != "Ellen Ripley" || credits[1].Year != 1979 {
+		t.Errorf("Expected the roles newest first, got %+v", credits)
+	}
+
+	_, cameron := person(strconv.FormatInt(found.Data.People[0].ID, 10))
+	if len(cameron.Data.Departments) != 1 || cameron.Data.Departments[0].Department != "Directing" ||
+		len(cameron.Data.Departments[0].Credits) != 1 || cameron.Data.Departments[0].Credits[0].Job != "Director" {
+		t.Errorf("Expected a single directing credit, got %+v", cameron.Data)
+	}
+
+	if rr, _ := person("999"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown person, got %d", rr.Code)
+	}
+	if rr, _ := person("abc"); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid id, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/podcast"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SubscribePodcastRequest holds the RSS feed to subscribe to.
+type SubscribePodcastRequest struct {
+	FeedURL string `json:"feed_url"`
+}
+
+// UpdatePodcastDownloadSettingsRequest holds a podcast's download and retention rules.
+// KeepEpisodes and KeepDays of 0 keep every download.
+type UpdatePodcastDownloadSettingsRequest struct {
+	AutoDownload bool  `json:"auto_download"`
+	KeepEpisodes int64 `json:"keep_episodes"`
+	KeepDays     int64 `json:"keep_days"`
+	DeletePlayed bool  `json:"delete_played"`
+}
+
+// UpdatePodcastEpisodeProgressRequest holds where the user stopped listening, in
+// milliseconds, and whether they played the episode to the end.
+type UpdatePodcastEpisodeProgressRequest struct {
+	Position int64 `json:"position"`
+	Played   bool  `json:"played"`
+}
+
+// runPodcastDownloads manages the podcast's downloads in the background, as a
+// handler can't wait for episodes to download.
+func (app *Application) runPodcastDownloads(p database.Podcast) {
+	if app.Wait != nil {
+		app.Wait.Add(1)
+	}
+
+	go func() {
+		if app.Wait != nil {
+			defer app.Wait.Done()
+		}
+
+		app.managePodcastDownloads(context.Background(), p)
+	}()
+}
+
+// GetPodcasts returns the subscribed podcasts sorted by title.
+func (app *Application) GetPodcasts(w http.ResponseWriter, r *http.Request) {
+	podcasts, err := app.Queries.GetPodcasts(r.Context())
+	if err != nil {
+		app.Logger.Error("failed to get podcasts", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch podcasts"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"podcasts": podcasts,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// GetPodcastDetails returns a podcast with a page of its episodes, newest first, and
+// the user's progress in each.
+// Supports query parameters: limit (default 50, max 100), offset (default 0)
+func (app *Application) GetPodcastDetails(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid podcast id"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	p, err := app.Queries.GetPodcastByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("podcast not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get podcast", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch podcast from server"))
+		return
+	}
+
+	limit, offset := parseStatsPaginationParams(r, 50, 100)
+
+	total, err := app.Queries.GetPodcastEpisodesCount(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get podcast episodes count", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch podcast episodes count"))
+		return
+	}
+
+	episodes, err := app.Queries.GetPodcastEpisodes(ctx, database.GetPodcastEpisodesParams{
+		UserID:    userID,
+		PodcastID: id,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get podcast episodes", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch podcast episodes"))
+		return
+	}
+
+	res := helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"podcast":  p,
+			"episodes": episodes,
+			"total":    total,
+			"offset":   offset,
+			"limit":    limit,
+			"has_more": offset+limit < total,
+		},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
+// SubscribePodcast subscribes to an RSS feed. The feed is fetched right away, so
+// invalid feeds are rejected and the podcast is returned with its episodes stored.
+func (app *Application) SubscribePodcast(w http.ResponseWriter, r *http.Request) {
+	var req SubscribePodcastRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	feedURL := strings.TrimSpace(req.FeedURL)
+	parsed, err := url.Parse(feedURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		helpers.ErrorJSON(w, errors.New("feed url must be an http or https url"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	if _, err := app.Queries.GetPodcastByFeedURL(ctx, feedURL); err == nil {
+		helpers.ErrorJSON(w, errors.New("already subscribed to this podcast"), http.StatusConflict)
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		app.Logger.Error("failed to get podcast", "error", err, "feed", feedURL)
+		helpers.ErrorJSON(w, errors.New("failed to subscribe to podcast"))
+		return
+	}
+
+	res, err := app.Podcast.FetchFeed(ctx, feedURL, "", "")
+	if err != nil {
+		if errors.Is(err, podcast.ErrNotAFeed) {
+			helpers.ErrorJSON(w, errors.New("url is not a podcast feed"), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, podcast.ErrUnsupportedEncoding) || errors.Is(err, podcast.ErrTooLarge) {
+			helpers.ErrorJSON(w, err, http.StatusBadRequest)
+			return
+		}
+
+		app.Logger.Warn("failed to fetch podcast feed", "error", err, "feed", feedURL)
+		helpers.ErrorJSON(w, errors.New("failed to fetch podcast feed"), http.StatusBadGateway)
+		return
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		app.Logger.Error("failed to start transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to subscribe to podcast"))
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := app.Queries.WithTx(tx)
+
+	created, err := qtx.CreatePodcast(ctx, database.CreatePodcastParams{
+		FeedUrl: feedURL,
+		Title:   res.Feed.Title,
+	})
+	if err != nil {
+		app.Logger.Error("failed to create podcast", "error", err, "feed", feedURL)
+		helpers.ErrorJSON(w, errors.New("failed to subscribe to podcast"))
+		return
+	}
+
+	p, err := storePodcastFeed(ctx, qtx, created.ID, res)
+	if err != nil {
+		app.Logger.Error("failed to store podcast feed", "error", err, "feed", feedURL)
+		helpers.ErrorJSON(w, errors.New("failed to subscribe to podcast"))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.Logger.Error("failed to commit transaction", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to subscribe to podcast"))
+		return
+	}
+
+	app.Logger.Info("subscribed to podcast", "title", p.Title, "feed", feedURL, "episodes", len(res.Feed.Episodes))
+
+	helpers.WriteJSON(w, http.StatusCreated, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"podcast":       p,
+			"episode_count": len(res.Feed.Episodes),
+		},
+	})
+}
+
+// UpdatePodcastDownloadSettings replaces a podcast's download and retention rules
+// and applies them in the background.
+func (app *Application) UpdatePodcastDownloadSettings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid podcast id"), http.StatusBadRequest)
+		return
+	}
+
+	var req UpdatePodcastDownloadSettingsRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.KeepEpisodes < 0 || req.KeepDays < 0 {
+		helpers.ErrorJSON(w, errors.New("kept episodes and days can't be negative"), http.StatusBadRequest)
+		return
+	}
+
+	p, err := app.Queries.UpdatePodcastDownloadSettings(r.Context(), database.UpdatePodcastDownloadSettingsParams{
+		AutoDownload: req.AutoDownload,
+		KeepEpisodes: req.KeepEpisodes,
+		KeepDays:     req.KeepDays,
+		DeletePlayed: req.DeletePlayed,
+		ID:           id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("podcast not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to update podcast download settings", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update podcast"))
+		return
+	}
+
+	app.runPodcastDownloads(p)
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"podcast": p,
+		},
+	})
+}
+
+// UnsubscribePodcast deletes a podcast with its episodes and downloaded files.
+func (app *Application) UnsubscribePodcast(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid podcast id"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	p, err := app.Queries.GetPodcastByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("podcast not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get podcast", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to unsubscribe from podcast"))
+		return
+	}
+
+	downloads, err := app.Queries.GetDownloadedPodcastEpisodes(ctx, id)
+	if err != nil {
+		app.Logger.Error("failed to get podcast downloads", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to unsubscribe from podcast"))
+		return
+	}
+
+	if err := app.Queries.DeletePodcast(ctx, id); err != nil {
+		app.Logger.Error("failed to delete podcast", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to unsubscribe from podcast"))
+		return
+	}
+
+	dirs := make(map[string]bool)
+	for _, episode := range downloads {
+		if err := os.Remove(episode.FilePath.String); err != nil && !os.IsNotExist(err) {
+			app.Logger.Warn("failed to delete podcast download", "error", err, "path", episode.FilePath.String)
+		}
+		dirs[filepath.Dir(episode.FilePath.String)] = true
+	}
+
+	// only removes folders left empty
+	for dir := range dirs {
+		os.Remove(dir)
+	}
+
+	app.Logger.Info("unsubscribed from podcast", "title", p.Title, "downloads", len(downloads))
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error:   false,
+		Message: "Unsubscribed from podcast",
+	})
+}
+
+// RefreshPodcast polls a podcast's feed right away. New episodes are downloaded in
+// the background.
+func (app *Application) RefreshPodcast(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid podcast id"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	p, err := app.Queries.GetPodcastByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("podcast not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get podcast", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to refresh podcast"))
+		return
+	}
+
+	p, changed, err := app.refreshPodcast(ctx, p)
+	if err != nil {
+		app.Logger.Warn("failed to refresh podcast", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch podcast feed"), http.StatusBadGateway)
+		return
+	}
+
+	app.runPodcastDownloads(p)
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"podcast": p,
+			"updated": changed,
+		},
+	})
+}
+
+// DownloadPodcastEpisode starts downloading an episode in the background.
+func (app *Application) DownloadPodcastEpisode(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid episode id"), http.StatusBadRequest)
+		return
+	}
+
+	if !app.Settings().PodcastsDir.Valid || app.Settings().PodcastsDir.String == "" {
+		helpers.ErrorJSON(w, errPodcastsDirNotSet, http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := r.Context()
+
+	episode, err := app.Queries.GetPodcastEpisodeByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("episode not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get podcast episode", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to download episode"))
+		return
+	}
+
+	if episode.FilePath.Valid {
+		helpers.ErrorJSON(w, errors.New("episode is already downloaded"), http.StatusConflict)
+		return
+	}
+
+	if _, active := podcastDownloads.Load(id); active {
+		helpers.ErrorJSON(w, errEpisodeDownloadActive, http.StatusConflict)
+		return
+	}
+
+	p, err := app.Queries.GetPodcastByID(ctx, episode.PodcastID)
+	if err != nil {
+		app.Logger.Error("failed to get podcast", "error", err, "id", episode.PodcastID)
+		helpers.ErrorJSON(w, errors.New("failed to download episode"))
+		return
+	}
+
+	if app.Wait != nil {
+		app.Wait.Add(1)
+	}
+
+	go func() {
+		if app.Wait != nil {
+			defer app.Wait.Done()
+		}
+
+		if _, err := app.downloadPodcastEpisode(context.Background(), p, episode); err != nil && !errors.Is(err, errEpisodeDownloadActive) {
+			app.Logger.Warn("failed to download podcast episode", "error", err, "id", episode.ID, "url", episode.EnclosureUrl)
+		}
+	}()
+
+	helpers.WriteJSON(w, http.StatusAccepted, helpers.JSONResponse{
+		Error:   false,
+		Message: "Episode download started",
+	})
+}
+
+// StreamPodcastEpisode serves a downloaded episode with range support, or redirects
+// to the publisher's file when it isn't downloaded.
+func (app *Application) StreamPodcastEpisode(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid episode id"), http.StatusBadRequest)
+		return
+	}
+
+	episode, err := app.Queries.GetPodcastEpisodeByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("episode not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get podcast episode", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to fetch episode from server"))
+		return
+	}
+
+	if !episode.FilePath.Valid {
+		http.Redirect(w, r, episode.EnclosureUrl, http.StatusTemporaryRedirect)
+		return
+	}
+
+	f, err := os.Open(episode.FilePath.String)
+	if err != nil {
+		if os.IsNotExist(err) {
+			app.Logger.Warn("podcast download not found on disk", "path", episode.FilePath.String, "id", id)
+			http.Redirect(w, r, episode.EnclosureUrl, http.StatusTemporaryRedirect)
+			return
+		}
+
+		app.Logger.Error("failed to open podcast download", "error", err, "path", episode.FilePath.String)
+		helpers.ErrorJSON(w, errors.New("failed to open episode file"))
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		app.Logger.Error("failed to stat podcast download", "error", err, "path", episode.FilePath.String)
+		helpers.ErrorJSON(w, errors.New("failed to read episode file"))
+		return
+	}
+
+	mimeType := episode.EnclosureType
+	if !strings.HasPrefix(mimeType, "audio/") {
+		container := helpers.AudioContainers[helpers.GetFileExtension(episode.FilePath.String)]
+		mimeType = helpers.AudioMimeTypes[container]
+	}
+	if mimeType != "" {
+		w.Header().Set("Content-Type", mimeType)
+	}
+
+	http.ServeContent(w, r, filepath.Base(episode.FilePath.String), stat.ModTime(), f)
+}
+
+// UpdatePodcastEpisodeProgress saves where the user stopped listening to an episode.
+func (app *Application) UpdatePodcastEpisodeProgress(w http.ResponseWriter, r *http.Request) {
+	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
+	if userID == 0 {
+		helpers.ErrorJSON(w, errors.New(helpers.NOT_AUTHORIZED_MESSAGE), http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.ErrorJSON(w, errors.New("invalid episode id"), http.StatusBadRequest)
+		return
+	}
+
+	var req UpdatePodcastEpisodeProgressRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.Position < 0 {
+		helpers.ErrorJSON(w, errors.New("position must not be negative"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+
+	episode, err := app.Queries.GetPodcastEpisodeByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			helpers.ErrorJSON(w, errors.New("episode not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to get podcast episode", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update progress"))
+		return
+	}
+
+	progress, err := app.Queries.UpsertPodcastEpisodeProgress(ctx, database.UpsertPodcastEpisodeProgressParams{
+		UserID:    userID,
+		EpisodeID: id,
+		Position:  clampPosition(req.Position, episode.Duration),
+		Played:    req.Played,
+	})
+	if err != nil {
+		app.Logger.Error("failed to save podcast episode progress", "error", err, "id", id)
+		helpers.ErrorJSON(w, errors.New("failed to update progress"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"progress": progress,
+		},
+	})
+}
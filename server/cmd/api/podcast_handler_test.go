@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+)
+
+// TestStreamPodcastEpisode tests that a downloaded episode is served with range
+// support and that one that isn't is redirected to the publisher.
+func TestStreamPodcastEpisode(t *testing.T) {
+	app, userID := setupPodcastTestApp(t)
+	defer app.DB.Close()
+
+	server := newFeedServer(t, "2024-01-02", "2024-01-01")
+	p := subscribePodcast(t, app, server.URL+"/feed.xml")
+
+	ctx := context.Background()
+
+	episodes, err := app.Queries.GetPodcastEpisodes(ctx, database.GetPodcastEpisodesParams{UserID: userID, PodcastID: p.ID, Limit: 10})
+	if err != nil || len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d (%v)", len(episodes), err)
+	}
+
+	path := filepath.Join(app.Settings().PodcastsDir.String, "episode.mp3")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := app.Queries.SetPodcastEpisodeFile(ctx, database.SetPodcastEpisodeFileParams{
+		FilePath: helpers.NullString(path), FileSize: 10, ID: episodes[0].ID,
+	}); err != nil {
+		t.Fatalf("Failed to set episode file: %v", err)
+	}
+
+	stream := func(id int64, rangeHeader string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := audiobookRequest(t, app, http.MethodGet, "/api/podcasts/episodes/x/stream", userID, "", map[string]string{"id": fmt.Sprint(id)})
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		app.StreamPodcastEpisode(rr, req)
+		return rr
+	}
+
+	rr := stream(episodes[0].ID, "bytes=2-5")
+	if rr.Code != http.StatusPartialContent || rr.Body.String() != "2345" || rr.Header().Get("Content-Type") != "audio/mpeg" {
+		t.Errorf("Expected bytes 2-5 of the download, got %d %q (%s)", rr.Code, rr.Body.String(), rr.Header().Get("Content-Type"))
+	}
+
+	rr = stream(episodes[1].ID, "")
+	if rr.Code != http.StatusTemporaryRedirect || rr.Header().Get("Location") != episodes[1].EnclosureUrl {
+		t.Errorf("Expected a redirect to %s, got %d %s", episodes[1].EnclosureUrl, rr.Code, rr.Header().Get("Location"))
+	}
+
+	if rr = stream(999, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing episode, got %d", rr.Code)
+	}
+}
+
+func TestUpdatePodcastEpisodeProgress(t *testing.T) {
+	app, userID := setupPodcastTestApp(t)
+	defer app.DB.Close()
+
+	server := newFeedServer(t, "2024-01-01")
+	p := subscribePodcast(t, app, server.URL+"/feed.xml")
+
+	id := map[string]string{"id": "1"}
+	target := "/api/podcasts/episodes/1/progress"
+
+	tests := []struct {
+		name     string
+		userID   int64
+		body     string
+		status   int
+		position int64
+		played   bool
+	}{
+		{"halfway", userID, `{"position": 300000}`, http.StatusOK, 300_000, false},
+		{"past the end", userID, `{"position": 999999, "played": true}`, http.StatusOK, 600_000, true},
+		{"negative position", userID, `{"position": -1}`, http.StatusBadRequest, 0, false},
+		{"not logged in", 0, `{"position": 10}`, http.StatusUnauthorized, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			app.UpdatePodcastEpisodeProgress(rr, audiobookRequest(t, app, http.MethodPut, target, tt.userID, tt.body, id))
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				Data struct {
+					Progress database.PodcastEpisodeProgress `json:"progress"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if res.Data.Progress.Position != tt.position || res.Data.Progress.Played != tt.played {
+				t.Errorf("Expected position %d played %v, got %+v", tt.position, tt.played, res.Data.Progress)
+			}
+		})
+	}
+
+	// The progress shows in the user's episode list
+	rr := httptest.NewRecorder()
+	app.GetPodcastDetails(rr, audiobookRequest(t, app, http.MethodGet, "/api/podcasts/1", userID, "", map[string]string{"id": fmt.Sprint(p.ID)}))
+
+	var res struct {
+		Data struct {
+			Episodes []database.GetPodcastEpisodesRow `json:"episodes"`
+			Total    int64                            `json:"total"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if res.Data.Total != 1 || len(res.Data.Episodes) != 1 || !res.Data.Episodes[0].Played {
+		t.Errorf("Expected the played episode in the list, got %+v", res.Data)
+	}
+}
+
+// TestUpdatePodcastSettings tests that the podcasts folder can be set, is created, and
+// is kept when omitted, and that relative folders are rejected.
+func TestUpdatePodcastSettings(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	if err := app.InitSettings(t.Context()); err != nil {
+		t.Fatalf("InitSettings failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "podcasts")
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"relative folder", `{"podcasts_dir": "podcasts"}`, http.StatusBadRequest},
+		{"folder", fmt.Sprintf(`{"podcast_poll_minutes": 30, "podcasts_dir": %q}`, dir), http.StatusOK},
+		{"folder omitted", `{"podcast_poll_minutes": 15}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/settings/podcasts", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			app.UpdatePodcastSettings(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	if app.Settings().PodcastsDir.String != dir || app.Settings().PodcastPollMinutes != 15 {
+		t.Errorf("Unexpected settings: %q, %d", app.Settings().PodcastsDir.String, app.Settings().PodcastPollMinutes)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("Expected the podcasts folder to be created, got %v", err)
+	}
+}
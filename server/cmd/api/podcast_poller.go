@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/podcast"
+)
+
+var (
+	errPodcastsDirNotSet     = errors.New("podcasts directory is not configured")
+	errEpisodeDownloadActive = errors.New("episode is already being downloaded")
+)
+
+// podcastDownloads holds the ids of the episodes being downloaded, so the poller and
+// the download endpoint never fetch the same episode twice at once.
+var podcastDownloads sync.Map
+
+// RunPodcastPoller periodically polls the feeds that are due. The poll interval is
+// re-read on every tick, so setting it to 0 pauses polling.
+func (app *Application) RunPodcastPoller() {
+	ticker := time.NewTicker(helpers.PODCAST_POLL_CHECK_INTERVAL)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if app.Settings().PodcastPollMinutes <= 0 {
+			continue
+		}
+
+		podcastPollMutex.Lock()
+		if isPodcastPolling {
+			podcastPollMutex.Unlock()
+			continue
+		}
+		isPodcastPolling = true
+		podcastPollMutex.Unlock()
+
+		app.PollPodcasts()
+
+		podcastPollMutex.Lock()
+		isPodcastPolling = false
+		podcastPollMutex.Unlock()
+	}
+}
+
+// PollPodcasts refreshes every podcast not polled within the poll interval, then
+// downloads its new episodes and applies its retention rules.
+func (app *Application) PollPodcasts() {
+	if app.Wait != nil {
+		app.Wait.Add(1)
+		defer app.Wait.Done()
+	}
+
+	ctx := context.Background()
+
+	cutoff := helpers.SQLiteTime(time.Now().Add(-time.Duration(app.Settings().PodcastPollMinutes) * time.Minute))
+
+	podcasts, err := app.Queries.GetPodcastsDueForPoll(ctx, cutoff)
+	if err != nil {
+		app.Logger.Error("failed to get podcasts due for a poll", "error", err)
+		return
+	}
+
+	if len(podcasts) == 0 {
+		return
+	}
+
+	var updated, unchanged, errCount int
+	for _, p := range podcasts {
+		refreshed, changed, err := app.refreshPodcast(ctx, p)
+		if err != nil {
+			app.Logger.Warn("failed to poll podcast", "error", err, "id", p.ID, "feed", p.FeedUrl)
+			errCount++
+			continue
+		}
+
+		if changed {
+			updated++
+		} else {
+			unchanged++
+		}
+
+		app.managePodcastDownloads(ctx, refreshed)
+	}
+
+	app.Logger.Info(fmt.Sprintf("podcast poll completed: %d updated, %d unchanged, %d errors", updated, unchanged, errCount))
+}
+
+// refreshPodcast fetches the podcast's feed with the validators of the previous
+// fetch and stores new and changed episodes. Returns the updated podcast and whether
+// the feed changed. Failures are recorded in last_error.
+func (app *Application) refreshPodcast(ctx context.Context, p database.Podcast) (database.Podcast, bool, error) {
+	res, err := app.Podcast.FetchFeed(ctx, p.FeedUrl, p.Etag.String, p.LastModified.String)
+	if err != nil {
+		if dbErr := app.recordPodcastPoll(ctx, p.ID, err); dbErr != nil {
+			app.Logger.Error("failed to record podcast poll", "error", dbErr, "id", p.ID)
+		}
+		return p, false, err
+	}
+
+	if res.NotModified {
+		if err := app.recordPodcastPoll(ctx, p.ID, nil); err != nil {
+			return p, false, err
+		}
+		return p, false, nil
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	tx, err := app.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return p, false, err
+	}
+	defer tx.Rollback()
+
+	updated, err := storePodcastFeed(ctx, app.Queries.WithTx(tx), p.ID, res)
+	if err != nil {
+		return p, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return p, false, err
+	}
+
+	return updated, true, nil
+}
+
+// recordPodcastPoll stores when the podcast was polled, with the poll's error if it failed.
+func (app *Application) recordPodcastPoll(ctx context.Context, id int64, pollErr error) error {
+	var lastError sql.NullString
+	if pollErr != nil {
+		lastError = helpers.NullString(pollErr.Error())
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	return app.Queries.UpdatePodcastPolled(ctx, database.UpdatePodcastPolledParams{
+		LastError: lastError,
+		ID:        id,
+	})
+}
+
+// storePodcastFeed saves a fetched feed and upserts its episodes, matched by guid.
+func storePodcastFeed(ctx context.Context, qtx *database.Queries, podcastID int64, res *podcast.FeedResponse) (database.Podcast, error) {
+	feed := res.Feed
+
+	p, err := qtx.UpdatePodcastFeed(ctx, database.UpdatePodcastFeedParams{
+		Title:        feed.Title,
+		Description:  helpers.NullString(feed.Description),
+		Author:       helpers.NullString(feed.Author),
+		ImageUrl:     helpers.NullString(feed.ImageURL),
+		Link:         helpers.NullString(feed.Link),
+		Language:     helpers.NullString(feed.Language),
+		Etag:         helpers.NullString(res.ETag),
+		LastModified: helpers.NullString(res.LastModified),
+		ID:           podcastID,
+	})
+	if err != nil {
+		return p, fmt.Errorf("update podcast: %w", err)
+	}
+
+	for _, episode := range feed.Episodes {
+		err := qtx.UpsertPodcastEpisode(ctx, database.UpsertPodcastEpisodeParams{
+			PodcastID:     podcastID,
+			Guid:          episode.GUID,
+			Title:         episode.Title,
+			Description:   helpers.NullString(episode.Description),
+			PublishedAt:   helpers.NullString(episode.PublishedAt),
+			Duration:      episode.Duration,
+			EnclosureUrl:  episode.EnclosureURL,
+			EnclosureType: episode.EnclosureType,
+			EnclosureSize: episode.EnclosureSize,
+		})
+		if err != nil {
+			return p, fmt.Errorf("upsert episode %q: %w", episode.GUID, err)
+		}
+	}
+
+	return p, nil
+}
+
+// managePodcastDownloads downloads the podcast's new episodes when it auto-downloads,
+// then deletes the downloads its retention rules no longer keep.
+func (app *Application) managePodcastDownloads(ctx context.Context, p database.Podcast) {
+	settings := app.Settings()
+	if !settings.PodcastsDir.Valid || settings.PodcastsDir.String == "" {
+		return
+	}
+
+	if p.AutoDownload {
+		limit := p.KeepEpisodes
+		if limit <= 0 {
+			limit = helpers.PODCAST_AUTO_DOWNLOAD_LIMIT
+		}
+
+		episodes, err := app.Queries.GetPodcastEpisodesToDownload(ctx, database.GetPodcastEpisodesToDownloadParams{
+			PodcastID: p.ID,
+			Limit:     limit,
+		})
+		if err != nil {
+			app.Logger.Error("failed to get podcast episodes to download", "error", err, "id", p.ID)
+		}
+
+		for _, episode := range episodes {
+			// retention would delete it right away
+			if podcastEpisodeExpired(episode.PublishedAt, episode.DownloadedAt, p.KeepDays) {
+				continue
+			}
+
+			if _, err := app.downloadPodcastEpisode(ctx, p, episode); err != nil && !errors.Is(err, errEpisodeDownloadActive) {
+				app.Logger.Warn("failed to download podcast episode", "error", err, "id", episode.ID, "url", episode.EnclosureUrl)
+			}
+		}
+	}
+
+	removed, err := app.applyPodcastRetention(ctx, p)
+	if err != nil {
+		app.Logger.Error("failed to apply podcast retention", "error", err, "id", p.ID)
+	}
+	if removed > 0 {
+		app.Logger.Info("deleted podcast downloads", "podcast", p.Title, "count", removed)
+	}
+}
+
+// downloadPodcastEpisode downloads the episode into the podcast's folder under the
+// podcasts directory and returns the file's path. The file is written under a
+// temporary name and renamed once complete, so partial downloads are never served.
+func (app *Application) downloadPodcastEpisode(ctx context.Context, p database.Podcast, episode database.PodcastEpisode) (string, error) {
+	settings := app.Settings()
+	if !settings.PodcastsDir.Valid || settings.PodcastsDir.String == "" {
+		return "", errPodcastsDirNotSet
+	}
+
+	if _, active := podcastDownloads.LoadOrStore(episode.ID, true); active {
+		return "", errEpisodeDownloadActive
+	}
+	defer podcastDownloads.Delete(episode.ID)
+
+	dir := filepath.Join(settings.PodcastsDir.String, podcastFileName(p.Title))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+
+	size, err := app.Podcast.Download(ctx, episode.EnclosureUrl, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	name := podcastFileName(episode.Title)
+	if episode.PublishedAt.Valid && len(episode.PublishedAt.String) >= len(time.DateOnly) {
+		name = episode.PublishedAt.String[:len(time.DateOnly)] + " " + name
+	}
+
+	ext := podcastFileExtension(episode.EnclosureUrl, episode.EnclosureType)
+	path := filepath.Join(dir, name+"."+ext)
+
+	// two episodes can share a date and title
+	if _, err := os.Stat(path); err == nil {
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d).%s", name, episode.ID, ext))
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	app.ScannerDBMu.Lock()
+	err = app.Queries.SetPodcastEpisodeFile(ctx, database.SetPodcastEpisodeFileParams{
+		FilePath: helpers.NullString(path),
+		FileSize: size,
+		ID:       episode.ID,
+	})
+	app.ScannerDBMu.Unlock()
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	app.Logger.Info("downloaded podcast episode", "podcast", p.Title, "episode", episode.Title, "path", path)
+
+	return path, nil
+}
+
+// applyPodcastRetention deletes the downloads the podcast doesn't keep: past its
+// newest keep_episodes, published more than keep_days ago, or, with delete_played,
+// played by someone while nobody is halfway through. Returns how many were deleted.
+func (app *Application) applyPodcastRetention(ctx context.Context, p database.Podcast) (int, error) {
+	if p.KeepEpisodes <= 0 && p.KeepDays <= 0 && !p.DeletePlayed {
+		return 0, nil
+	}
+
+	episodes, err := app.Queries.GetDownloadedPodcastEpisodes(ctx, p.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i, episode := range episodes {
+		keep := (p.KeepEpisodes <= 0 || int64(i) < p.KeepEpisodes) &&
+			!podcastEpisodeExpired(episode.PublishedAt, episode.DownloadedAt, p.KeepDays) &&
+			(!p.DeletePlayed || episode.PlayedCount == 0 || episode.InProgressCount > 0)
+		if keep {
+			continue
+		}
+
+		if err := os.Remove(episode.FilePath.String); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+
+		app.ScannerDBMu.Lock()
+		err := app.Queries.ClearPodcastEpisodeFile(ctx, episode.ID)
+		app.ScannerDBMu.Unlock()
+		if err != nil {
+			return removed, err
+		}
+
+		removed++
+	}
+
+	return removed, nil
+}
+
+// podcastEpisodeExpired reports whether an episode is older than keepDays, going by
+// its publication date or, without one, when it was downloaded. 0 keeps everything.
+func podcastEpisodeExpired(publishedAt, downloadedAt sql.NullString, keepDays int64) bool {
+	if keepDays <= 0 {
+		return false
+	}
+
+	date := publishedAt
+	if !date.Valid {
+		date = downloadedAt
+	}
+
+	t, err := time.Parse(time.DateTime, date.String)
+	if err != nil {
+		return false
+	}
+
+	return t.Before(time.Now().UTC().AddDate(0, 0, -int(keepDays)))
+}
+
+// podcastFileName turns a podcast or episode title into a file name that is valid on
+// every platform.
+func podcastFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, title)
+
+	name = strings.Trim(strings.TrimSpace(name), ".")
+	if runes := []rune(name); len(runes) > 100 {
+		name = strings.TrimSpace(string(runes[:100]))
+	}
+
+	if name == "" {
+		return "untitled"
+	}
+	return name
+}
+
+// podcastFileExtension picks the extension of a downloaded episode: the enclosure
+// URL's when it's a known audio format, otherwise one matching its MIME type.
+func podcastFileExtension(url, mimeType string) string {
+	path := url
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	if ext := strings.ToLower(helpers.GetFileExtension(path)); helpers.AudioContainers[ext] != "" {
+		return ext
+	}
+
+	switch strings.ToLower(strings.TrimSpace(mimeType)) {
+	case "audio/mp4", "audio/x-m4a", "audio/aac":
+		return "m4a"
+	case "audio/ogg", "audio/opus":
+		return "ogg"
+	case "audio/flac":
+		return "flac"
+	case "audio/wav", "audio/x-wav":
+		return "wav"
+	}
+
+	return "mp3"
+}
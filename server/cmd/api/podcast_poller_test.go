@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/podcast"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// feedServer serves a podcast feed whose episodes can be changed between requests,
+// with an ETag that changes with them, and the episodes' audio files.
+type feedServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	episodes     []string // dates, newest first; episode N is episodes[len-N]
+	conditionals int
+	notModified  int
+	downloads    int
+}
+
+func newFeedServer(t *testing.T, dates ...string) *feedServer {
+	t.Helper()
+
+	fs := &feedServer{episodes: dates}
+	fs.Server = httptest.NewServer(http.HandlerFunc(fs.serve))
+	t.Cleanup(fs.Close)
+
+	return fs
+}
+
+func (fs *feedServer) serve(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/audio/") {
+		fs.downloads++
+		w.Write([]byte("audio for " + strings.TrimPrefix(r.URL.Path, "/audio/")))
+		return
+	}
+
+	etag := fmt.Sprintf(`"%d"`, len(fs.episodes))
+	if r.Header.Get("If-None-Match") != "" {
+		fs.conditionals++
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		fs.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var items strings.Builder
+	for i, date := range fs.episodes {
+		n := len(fs.episodes) - i
+		published, _ := time.Parse(time.DateOnly, date)
+		fmt.Fprintf(&items, `<item><title>Episode %d</title><guid>ep-%d</guid><pubDate>%s</pubDate>
+<itunes:duration>10:00</itunes:duration><enclosure url="%s/audio/%d.mp3?source=feed" type="audio/mpeg" length="100"/></item>`,
+			n, n, published.Format(time.RFC1123Z), fs.URL, n)
+	}
+
+	w.Header().Set("ETag", etag)
+	fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+<channel><title>Local Show</title><itunes:author>Host</itunes:author>%s</channel></rss>`, items.String())
+}
+
+func (fs *feedServer) setEpisodes(dates ...string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.episodes = dates
+}
+
+// setupPodcastTestApp creates an app with a user, a podcasts directory and the real
+// podcast client.
+func setupPodcastTestApp(t *testing.T) (*Application, int64) {
+	t.Helper()
+
+	app := setupTestAppWithLogger(t)
+	app.SessionManager = scs.New()
+	app.DB.SetMaxOpenConns(1)
+	app.Podcast = podcast.New()
+	settings := *app.Settings()
+	settings.PodcastsDir = helpers.NullString(t.TempDir())
+	app.SetSettings(&settings)
+
+	user, err := app.Queries.CreateUser(context.Background(), database.CreateUserParams{Name: "user", Email: "user@example.com", Password: "x"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	return app, user.ID
+}
+
+// subscribePodcast subscribes through the handler and returns the new podcast.
+func subscribePodcast(t *testing.T, app *Application, feedURL string) database.Podcast {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	app.SubscribePodcast(rr, audiobookRequest(t, app, http.MethodPost, "/api/podcasts", 0, `{"feed_url": "`+feedURL+`"}`, nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	p, err := app.Queries.GetPodcastByFeedURL(context.Background(), feedURL)
+	if err != nil {
+		t.Fatalf("Failed to get podcast: %v", err)
+	}
+	return p
+}
+
+// TestPollPodcasts tests that a subscribed feed is polled with its ETag, that an
+// unchanged feed is not re-read and that new episodes are stored when it changes.
+func TestPollPodcasts(t *testing.T) {
+	app, _ := setupPodcastTestApp(t)
+	defer app.DB.Close()
+
+	server := newFeedServer(t, "2024-01-02", "2024-01-01")
+	p := subscribePodcast(t, app, server.URL+"/feed.xml")
+
+	if p.Title != "Local Show" || p.Author.String != "Host" || p.Etag.String != `"2"` || !p.LastPolledAt.Valid {
+		t.Errorf("Unexpected podcast: %+v", p)
+	}
+
+	// Subscribing twice conflicts
+	rr := httptest.NewRecorder()
+	app.SubscribePodcast(rr, audiobookRequest(t, app, http.MethodPost, "/api/podcasts", 0, `{"feed_url": "`+server.URL+`/feed.xml"}`, nil))
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a second subscription, got %d", rr.Code)
+	}
+
+	ctx := context.Background()
+
+	settings := *app.Settings()
+	settings.PodcastPollMinutes = 60
+	app.SetSettings(&settings)
+	poll := func() {
+		t.Helper()
+		if _, err := app.DB.Exec("UPDATE podcasts SET last_polled_at = '2000-01-01 00:00:00'"); err != nil {
+			t.Fatalf("Failed to age podcasts: %v", err)
+		}
+		app.PollPodcasts()
+	}
+
+	// Just polled, so not due yet
+	app.PollPodcasts()
+	if server.conditionals != 0 {
+		t.Fatalf("Expected no poll within the interval, got %d", server.conditionals)
+	}
+
+	poll()
+
+	if server.notModified != 1 {
+		t.Fatalf("Expected the unchanged feed to answer 304, got %d conditional requests and %d not modified", server.conditionals, server.notModified)
+	}
+
+	server.setEpisodes("2024-01-03", "2024-01-02", "2024-01-01")
+	poll()
+
+	count, err := app.Queries.GetPodcastEpisodesCount(ctx, p.ID)
+	if err != nil || count != 3 {
+		t.Fatalf("Expected 3 episodes after the feed changed, got %d (%v)", count, err)
+	}
+
+	p, _ = app.Queries.GetPodcastByID(ctx, p.ID)
+	if p.Etag.String != `"3"` || p.LastError.Valid {
+		t.Errorf("Expected the new ETag to be stored, got %+v", p)
+	}
+
+	episodes, err := app.Queries.GetPodcastEpisodes(ctx, database.GetPodcastEpisodesParams{UserID: 1, PodcastID: p.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to get episodes: %v", err)
+	}
+	if episodes[0].Title != "Episode 3" || episodes[0].PublishedAt.String != "2024-01-03 00:00:00" || episodes[0].Duration != 600_000 {
+		t.Errorf("Unexpected newest episode: %+v", episodes[0])
+	}
+
+	// Failures are recorded on the podcast
+	server.Close()
+	if _, _, err := app.refreshPodcast(ctx, p); err == nil {
+		t.Fatal("Expected an error for an unreachable feed")
+	}
+
+	p, _ = app.Queries.GetPodcastByID(ctx, p.ID)
+	if !p.LastError.Valid {
+		t.Error("Expected the poll error to be recorded")
+	}
+}
+
+// TestPodcastDownloads tests that new episodes are downloaded up to the number kept,
+// that retention deletes downloads past it and played ones, and that deleted
+// episodes aren't downloaded again.
+func TestPodcastDownloads(t *testing.T) {
+	app, userID := setupPodcastTestApp(t)
+	defer app.DB.Close()
+
+	server := newFeedServer(t, "2024-01-02", "2024-01-01")
+	p := subscribePodcast(t, app, server.URL+"/feed.xml")
+
+	ctx := context.Background()
+
+	p, err := app.Queries.UpdatePodcastDownloadSettings(ctx, database.UpdatePodcastDownloadSettingsParams{
+		AutoDownload: true, KeepEpisodes: 2, DeletePlayed: true, ID: p.ID,
+	})
+	if err != nil {
+		t.Fatalf("Failed to update podcast: %v", err)
+	}
+
+	downloaded := func() []string {
+		t.Helper()
+		episodes, err := app.Queries.GetDownloadedPodcastEpisodes(ctx, p.ID)
+		if err != nil {
+			t.Fatalf("Failed to get downloads: %v", err)
+		}
+
+		var names []string
+		for _, episode := range episodes {
+			data, err := os.ReadFile(episode.FilePath.String)
+			if err != nil {
+				t.Fatalf("Failed to read download: %v", err)
+			}
+			if !strings.HasPrefix(string(data), "audio for ") {
+				t.Errorf("Unexpected download content %q", data)
+			}
+			names = append(names, filepath.Base(episode.FilePath.String))
+		}
+		return names
+	}
+
+	app.managePodcastDownloads(ctx, p)
+
+	if names := downloaded(); strings.Join(names, ",") != "2024-01-02 Episode 2.mp3,2024-01-01 Episode 1.mp3" {
+		t.Fatalf("Expected both episodes to be downloaded, got %v", names)
+	}
+	if server.downloads != 2 {
+		t.Errorf("Expected 2 downloads, got %d", server.downloads)
+	}
+
+	// A third episode pushes the oldest out
+	server.setEpisodes("2024-01-03", "2024-01-02", "2024-01-01")
+	p, _, err = app.refreshPodcast(ctx, p)
+	if err != nil {
+		t.Fatalf("Failed to refresh podcast: %v", err)
+	}
+	app.managePodcastDownloads(ctx, p)
+
+	if names := downloaded(); strings.Join(names, ",") != "2024-01-03 Episode 3.mp3,2024-01-02 Episode 2.mp3" {
+		t.Fatalf("Expected the newest two episodes, got %v", names)
+	}
+
+	// A played episode is deleted, not while another user is halfway through it
+	episodes, _ := app.Queries.GetDownloadedPodcastEpisodes(ctx, p.ID)
+	newest := episodes[0]
+
+	other, err := app.Queries.CreateUser(ctx, database.CreateUserParams{Name: "other", Email: "other@example.com", Password: "x"})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	for _, progress := range []database.UpsertPodcastEpisodeProgressParams{
+		{UserID: userID, EpisodeID: newest.ID, Position: 600_000, Played: true},
+		{UserID: other.ID, EpisodeID: newest.ID, Position: 1_000},
+	} {
+		if _, err := app.Queries.UpsertPodcastEpisodeProgress(ctx, progress); err != nil {
+			t.Fatalf("Failed to save progress: %v", err)
+		}
+	}
+
+	if removed, err := app.applyPodcastRetention(ctx, p); err != nil || removed != 0 {
+		t.Errorf("Expected the episode to be kept while in progress, got %d removed (%v)", removed, err)
+	}
+
+	if _, err := app.Queries.UpsertPodcastEpisodeProgress(ctx, database.UpsertPodcastEpisodeProgressParams{
+		UserID: other.ID, EpisodeID: newest.ID, Position: 600_000, Played: true,
+	}); err != nil {
+		t.Fatalf("Failed to save progress: %v", err)
+	}
+
+	app.managePodcastDownloads(ctx, p)
+
+	if names := downloaded(); strings.Join(names, ",") != "2024-01-02 Episode 2.mp3" {
+		t.Errorf("Expected the played episode to be deleted and not downloaded again, got %v", names)
+	}
+	if _, err := os.Stat(newest.FilePath.String); !os.IsNotExist(err) {
+		t.Errorf("Expected the played file to be removed, got %v", err)
+	}
+	if server.downloads != 3 {
+		t.Errorf("Expected 3 downloads in total, got %d", server.downloads)
+	}
+
+	// Unsubscribing removes the remaining downloads and their folder
+	rr := httptest.NewRecorder()
+	app.UnsubscribePodcast(rr, audiobookRequest(t, app, http.MethodDelete, "/api/podcasts/1", 0, "", map[string]string{"id": fmt.Sprint(p.ID)}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	entries, err := os.ReadDir(app.Settings().PodcastsDir.String)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected the podcasts directory to be empty, got %d entries (%v)", len(entries), err)
+	}
+}
+
+func TestPodcastEpisodeExpired(t *testing.T) {
+	old := time.Now().UTC().AddDate(0, 0, -10).Format(time.DateTime)
+	recent := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateTime)
+
+	tests := []struct {
+		name       string
+		published  string
+		downloaded string
+		keepDays   int64
+		expected   bool
+	}{
+		{"keeps everything", old, "", 0, false},
+		{"published too long ago", old, recent, 7, true},
+		{"published recently", recent, old, 7, false},
+		{"no date, downloaded long ago", "", old, 7, true},
+		{"no dates at all", "", "", 7, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podcastEpisodeExpired(helpers.NullString(tt.published), helpers.NullString(tt.downloaded), tt.keepDays); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPodcastFileNames(t *testing.T) {
+	if got := podcastFileName(` Who? What: "Why" / How. `); got != "Who_ What_ _Why_ _ How" {
+		t.Errorf("Unexpected file name %q", got)
+	}
+	if got := podcastFileName("..."); got != "untitled" {
+		t.Errorf("Expected untitled, got %q", got)
+	}
+
+	tests := []struct {
+		url, mimeType, expected string
+	}{
+		{"https://cdn.example.com/ep.M4A?token=1", "audio/mpeg", "m4a"},
+		{"https://cdn.example.com/redirect/ep", "audio/x-m4a", "m4a"},
+		{"https://cdn.example.com/ep.php", "", "mp3"},
+	}
+	for _, tt := range tests {
+		if got := podcastFileExtension(tt.url, tt.mimeType); got != tt.expected {
+			t.Errorf("podcastFileExtension(%q, %q) = %q, expected %q", tt.url, tt.mimeType, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxScanErrorRetries caps the files a single retry request rescans.
+const maxScanErrorRetries = 100
+
+// phaseError tags a scanner error with the step it happened in.
+type phaseError struct {
+	phase string
+	err   error
+}
+
+func (e *phaseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *phaseError) Unwrap() error {
+	return e.err
+}
+
+// scanPhaseError wraps err with the scanner phase it happened in, as stored in the
+// scan_errors ledger. Errors without a phase are recorded as database errors.
+func scanPhaseError(phase string, err error) error {
+	return &phaseError{phase: phase, err: err}
+}
+
+// scanErrorPhase returns the phase err was tagged with.
+func scanErrorPhase(err error) string {
+	var pe *phaseError
+	if errors.As(err, &pe) {
+		return pe.phase
+	}
+
+	return helpers.SCAN_PHASE_DB
+}
+
+// incompleteScanError marks a file that was stored without the metadata of a failed
+// step, e.g. while TMDB was unreachable. Its failure stays in the ledger, so the file
+// is scanned again until the step succeeds.
+type incompleteScanError struct {
+	err error
+}
+
+func (e *incompleteScanError) Error() string {
+	return e.err.Error()
+}
+
+func (e *incompleteScanError) Unwrap() error {
+	return e.err
+}
+
+// scanLedger collects the scan_errors changes of a batch. They are written once the
+// batch transaction is done, so failures are kept even when its commit fails.
+type scanLedger struct {
+	library string
+	failed  []scanLedgerEntry
+	scanned []string
+}
+
+type scanLedgerEntry struct {
+	path string
+	err  error
+}
+
+func newScanLedger(library string) *scanLedger {
+	return &scanLedger{library: library}
+}
+
+// fail notes a file that failed to scan.
+func (l *scanLedger) fail(path string, err error) {
+	l.failed = append(l.failed, scanLedgerEntry{path: path, err: err})
+}
+
+// succeed notes a file that scanned.
+func (l *scanLedger) succeed(path string) {
+	l.scanned = append(l.scanned, path)
+}
+
+// writeScanLedger stores the entries of a batch after its transaction. When the commit
+// failed, the files that scanned were rolled back and are recorded with commitErr.
+func (app *Application) writeScanLedger(ctx context.Context, ledger *scanLedger, commitErr error) {
+	for _, entry := range ledger.failed {
+		app.recordScanError(ctx, app.Queries, ledger.library, entry.path, entry.err)
+	}
+
+	for _, path := range ledger.scanned {
+		if commitErr != nil {
+			app.recordScanError(ctx, app.Queries, ledger.library, path, fmt.Errorf("commit failed: %w", commitErr))
+			continue
+		}
+		app.clearScanError(ctx, app.Queries, path)
+	}
+}
+
+// recordScanError stores a failed file in the scan_errors ledger. The logs rotate
+// quickly, so this is where failures stay visible.
+func (app *Application) recordScanError(ctx context.Context, qtx *database.Queries, library, path string, scanErr error) {
+	err := qtx.RecordScanError(ctx, database.RecordScanErrorParams{
+		FilePath: path,
+		Library:  library,
+		Phase:    scanErrorPhase(scanErr),
+		Error:    scanErr.Error(),
+	})
+	if err != nil {
+		app.Logger.Warn("failed to record scan error", "error", err, "path", path)
+	}
+}
+
+// clearScanError removes a file from the scan_errors ledger once it scans successfully.
+func (app *Application) clearScanError(ctx context.Context, qtx *database.Queries, path string) {
+	if err := qtx.DeleteScanError(ctx, path); err != nil {
+		app.Logger.Warn("failed to clear scan error", "error", err, "path", path)
+	}
+}
+
+// GetScanErrors returns the files that failed to scan, most recent first.
+// Supports query parameters: library (movies, music or audiobooks), limit (default 50, max 100), offset
+func (app *Application) GetScanErrors(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	library := r.URL.Query().Get("library")
+	if library != "" && library != helpers.SCAN_LIBRARY_MOVIES && library != helpers.SCAN_LIBRARY_MUSIC && library != helpers.SCAN_LIBRARY_AUDIOBOOKS {
+		helpers.ErrorJSON(w, errors.New("library must be movies, music or audiobooks"), http.StatusBadRequest)
+		return
+	}
+
+	limit, offset := parseStatsPaginationParams(r, 50, 100)
+
+	total, err := app.Queries.GetScanErrorsCount(ctx, library)
+	if err != nil {
+		app.Logger.Error("failed to get scan errors count", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch scan errors"))
+		return
+	}
+
+	scanErrors, err := app.Queries.GetScanErrors(ctx, database.GetScanErrorsParams{
+		Library: library,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		app.Logger.Error("failed to get scan errors", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to fetch scan errors"))
+		return
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"scan_errors": scanErrors,
+			"total":       total,
+			"limit":       limit,
+			"offset":      offset,
+			"has_more":    offset+limit < total,
+		},
+	})
+}
+
+// RetryScanErrorsRequest holds the ids of the scan errors to retry.
+type RetryScanErrorsRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+// scanRetryResult is the outcome of rescanning one failed file.
+type scanRetryResult struct {
+	ID       int64  `json:"id"`
+	FilePath string `json:"file_path,omitempty"`
+	// Status is scanned, skipped (unchanged or a sample), failed, missing (the file is
+	// gone and its entry was removed) or not_found (no such scan error)
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RetryScanErrors rescans the selected failed files right away. A file that succeeds,
+// or is skipped as unchanged, leaves the ledger; one that fails again has its entry updated.
+func (app *Application) RetryScanErrors(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req RetryScanErrorsRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if len(req.IDs) == 0 {
+		helpers.ErrorJSON(w, errors.New("ids are required"), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.IDs) > maxScanErrorRetries {
+		helpers.ErrorJSON(w, fmt.Errorf("at most %d scan errors can be retried at once", maxScanErrorRetries), http.StatusBadRequest)
+		return
+	}
+
+	cache := newMovieScannerCache()
+	defer cache.Clear()
+
+	results := make([]scanRetryResult, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		result, err := app.retryScanError(ctx, id, cache)
+		if err != nil {
+			app.Logger.Error("failed to retry scan error", "error", err, "id", id)
+			helpers.ErrorJSON(w, errors.New("failed to retry scan errors"))
+			return
+		}
+		results = append(results, result)
+	}
+
+	// Retried movies link their collection from its reference, see ScanMoviesLibrary
+	if err := app.updateMovieCollections(ctx, cache); err != nil {
+		app.Logger.Error("failed to update movie collections", "error", err)
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"results": results,
+		},
+	})
+}
+
+// retryScanError rescans one failed file through the same batch code as a library scan.
+func (app *Application) retryScanError(ctx context.Context, id int64, cache *movieScannerCache) (scanRetryResult, error) {
+	result := scanRetryResult{ID: id}
+
+	scanError, err := app.Queries.GetScanErrorByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		result.Status = "not_found"
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	result.FilePath = scanError.FilePath
+
+	info, err := os.Stat(scanError.FilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		result.Status = "missing"
+		return result, app.Queries.DeleteScanError(ctx, scanError.FilePath)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	ext := strings.ToLower(helpers.GetFileExtension(scanError.FilePath))
+
+	var scanned, skipped int
+	switch scanError.Library {
+	case helpers.SCAN_LIBRARY_MUSIC:
+		dir := filepath.Dir(scanError.FilePath)
+		file := trackFile{
+			path:   scanError.FilePath,
+			ext:    ext,
+			size:   info.Size(),
+			cue:    app.cueSheetsInDir(dir)[scanError.FilePath],
+			lyrics: app.lyricsSidecarsInDir(dir)[scanError.FilePath],
+		}
+		scanned, skipped, _ = app.processMusicBatch(ctx, []trackFile{file})
+	case helpers.SCAN_LIBRARY_AUDIOBOOKS:
+		// Audiobook errors are recorded for the whole book, a folder or a single file
+		book, err := readAudiobookDir(scanError.FilePath)
+		if err != nil {
+			return result, err
+		}
+		scanned, skipped, _ = app.processAudiobooksBatch(ctx, []audiobookDir{book})
+	default:
+		file := movieFile{path: scanError.FilePath, ext: ext, size: info.Size()}
+		if extra, ok := helpers.ParseMovieExtra(scanError.FilePath); ok {
+			scanned, skipped, _ = app.processMovieExtrasBatch(ctx, []movieExtraFile{{movieFile: file, extra: extra}})
+		} else {
+			scanned, skipped, _ = app.processMoviesBatch(ctx, []movieFile{file}, cache)
+		}
+	}
+
+	switch {
+	case scanned > 0:
+		result.Status = "scanned"
+	case skipped > 0:
+		result.Status = "skipped"
+		return result, app.Queries.DeleteScanError(ctx, scanError.FilePath)
+	default:
+		result.Status = "failed"
+
+		updated, err := app.Queries.GetScanErrorByID(ctx, id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return result, err
+		}
+		result.Error = updated.Error
+	}
+
+	return result, nil
+}
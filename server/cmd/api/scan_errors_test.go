@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+	"igloo/cmd/internal/tmdb"
+)
+
+// failingFfprobe fails for the paths in fail and probes everything else like fakeFfprobe.
+type failingFfprobe struct {
+	fakeFfprobe
+	fail map[string]bool
+}
+
+func (f *failingFfprobe) GetMetadata(filePath string) (*ffprobe.FfprobeResult, error) {
+	if f.fail[filePath] {
+		return nil, errors.New("invalid data found when processing input")
+	}
+	return f.fakeFfprobe.GetMetadata(filePath)
+}
+
+// TestScanErrors_RecordAndRetry tests that failed files are kept in the ledger with
+// their phase and attempt count, and leave it once a retry succeeds.
+func TestScanErrors_RecordAndRetry(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "Alien (1979).mkv")
+	if err := os.WriteFile(path, []byte("movie"), 0o644); err != nil {
+		t.Fatalf("Failed to write movie: %v", err)
+	}
+
+	probe := &failingFfprobe{fail: map[string]bool{path: true}}
+	app.Ffprobe = probe
+
+	file := movieFile{path: path, ext: "mkv", size: 5}
+	for range 2 {
+		if _, _, errCount := app.processMoviesBatch(ctx, []movieFile{file}, newMovieScannerCache()); errCount != 1 {
+			t.Fatalf("Expected the file to fail, got %d errors", errCount)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/settings/scan-errors?library=movies", nil)
+	rr := httptest.NewRecorder()
+	app.GetScanErrors(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var res struct {
+		Data struct {
+			ScanErrors []database.ScanError `json:"scan_errors"`
+			Total      int64                `json:"total"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if res.Data.Total != 1 || len(res.Data.ScanErrors) != 1 {
+		t.Fatalf("Expected one scan error, got %+v", res.Data)
+	}
+
+	entry := res.Data.ScanErrors[0]
+	if entry.Phase != helpers.SCAN_PHASE_FFPROBE || entry.Attempts != 2 || !strings.Contains(entry.Error, "invalid data") {
+		t.Errorf("Expected an ffprobe error seen twice, got %+v", entry)
+	}
+
+	// A file that was deleted since it failed is dropped from the ledger
+	gone := filepath.Join(filepath.Dir(path), "gone.flac")
+	if err := app.Queries.RecordScanError(ctx, database.RecordScanErrorParams{FilePath: gone, Library: helpers.SCAN_LIBRARY_MUSIC, Phase: helpers.SCAN_PHASE_DB, Error: "boom"}); err != nil {
+		t.Fatalf("Failed to record scan error: %v", err)
+	}
+
+	goneEntry, err := app.Queries.GetScanErrors(ctx, database.GetScanErrorsParams{Library: helpers.SCAN_LIBRARY_MUSIC, Limit: 1})
+	if err != nil || len(goneEntry) != 1 {
+		t.Fatalf("Failed to get music scan errors: %v", err)
+	}
+
+	// The file probes fine now
+	probe.fail = nil
+
+	body := `{"ids": [` + jsonInts(entry.ID, goneEntry[0].ID, 999) + `]}`
+	req = httptest.NewRequest(http.MethodPost, "/api/settings/scan-errors/retry", strings.NewReader(body))
+	rr = httptest.NewRecorder()
+	app.RetryScanErrors(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var retry struct {
+		Data struct {
+			Results []scanRetryResult `json:"results"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &retry); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	statuses := []string{}
+	for _, result := range retry.Data.Results {
+		statuses = append(statuses, result.Status)
+	}
+
+	if strings.Join(statuses, ",") != "scanned,missing,not_found" {
+		t.Errorf("Expected scanned, missing and not_found, got %v", statuses)
+	}
+
+	count, err := app.Queries.GetScanErrorsCount(ctx, "")
+	if err != nil {
+		t.Fatalf("Failed to count scan errors: %v", err)
+	}
+
+	if count != 0 {
+		t.Errorf("Expected the ledger to be empty after the retry, got %d entries", count)
+	}
+
+	if _, err := app.Queries.GetMovieByFilePath(ctx, path); err != nil {
+		t.Errorf("Expected the retried movie to be stored: %v", err)
+	}
+}
+
+// unavailableTmdb fails every search and lookup while down, like an unreachable TMDB.
+type unavailableTmdb struct {
+	*fakeTmdb
+	down bool
+}
+
+func (f *unavailableTmdb) GetTmdbMovieByID(movie *tmdb.TmdbMovie, locale tmdb.Locale) error {
+	if f.down {
+		return errors.New("connection refused")
+	}
+	return f.fakeTmdb.GetTmdbMovieByID(movie, locale)
+}
+
+func (f *unavailableTmdb) SearchMoviesByTitleAndYear(title string, year int, locale tmdb.Locale) ([]tmdb.TmdbMovie, error) {
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	return f.fakeTmdb.SearchMoviesByTitleAndYear(title, year, locale)
+}
+
+// TestScanErrors_TmdbFailure tests that a movie scanned while TMDB is unreachable is
+// stored, recorded in the tmdb phase, and matched by the next scan.
+func TestScanErrors_TmdbFailure(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+
+	path := "/movies/Alien (1979).mkv"
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{path: 1080}}
+	provider := &unavailableTmdb{fakeTmdb: newFakeTmdb(t, tmdbAlien), down: true}
+	app.Tmdb = provider
+
+	file := movieFile{path: path, ext: "mkv", size: 5}
+	if _, _, errCount := app.processMoviesBatch(ctx, []movieFile{file}, newMovieScannerCache()); errCount != 1 {
+		t.Fatalf("Expected the TMDB failure to be counted, got %d errors", errCount)
+	}
+
+	movie, err := app.Queries.GetMovieByFilePath(ctx, path)
+	if err != nil {
+		t.Fatalf("Expected the movie to be stored without TMDB: %v", err)
+	}
+
+	if movie.TmdbID.Valid {
+		t.Errorf("Expected no TMDB match, got %d", movie.TmdbID.Int64)
+	}
+
+	entries, err := app.Queries.GetScanErrors(ctx, database.GetScanErrorsParams{Library: helpers.SCAN_LIBRARY_MOVIES, Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to get scan errors: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].Phase != helpers.SCAN_PHASE_TMDB {
+		t.Fatalf("Expected one tmdb scan error, got %+v", entries)
+	}
+
+	// The unchanged file is scanned again once TMDB is back
+	provider.down = false
+	if scanned, _, _ := app.processMoviesBatch(ctx, []movieFile{file}, newMovieScannerCache()); scanned != 1 {
+		t.Fatalf("Expected the file to be scanned again, got %d scanned", scanned)
+	}
+
+	movie, err = app.Queries.GetMovieByFilePath(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to get movie: %v", err)
+	}
+
+	if movie.TmdbID.Int64 != 348 {
+		t.Errorf("Expected the movie to be matched, got tmdb id %d", movie.TmdbID.Int64)
+	}
+
+	count, err := app.Queries.GetScanErrorsCount(ctx, "")
+	if err != nil {
+		t.Fatalf("Failed to count scan errors: %v", err)
+	}
+
+	if count != 0 {
+		t.Errorf("Expected the ledger to be empty, got %d entries", count)
+	}
+}
+
+func jsonInts(ids ...int64) string {
+	data, _ := json.Marshal(ids)
+	return strings.Trim(string(data), "[]")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"igloo/cmd/internal/database"
+	"igloo/cmd/internal/helpers"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// errSampleFile is returned by the file processors for files shorter than the library's
+// minimum duration. Batches count these files as skipped rather than failed.
+var errSampleFile = errors.New("file is shorter than the minimum duration")
+
+// libraryFilter decides which directories and files of a library the scanner walks.
+// Directories holding a .nomedia marker or matching an ignore pattern are skipped
+// entirely, and .iglooignore files add their patterns for the subtree they are in.
+type libraryFilter struct {
+	matcher *helpers.IgnoreMatcher
+	minSize int64
+}
+
+// newLibraryFilter builds the filter for a library root from the library type's default
+// patterns, its settings patterns (newline-separated, gitignore syntax) and minimum file
+// size in bytes. The settings patterns come last, so they can re-include a default.
+func newLibraryFilter(root string, defaults []string, patterns sql.NullString, minSize int64) *libraryFilter {
+	return &libraryFilter{
+		matcher: helpers.NewIgnoreMatcher(root, append(slices.Clone(defaults), helpers.ParseIgnorePatterns(patterns.String)...)),
+		minSize: minSize,
+	}
+}
+
+// skipDir reports whether the walk should skip dir and everything below it.
+// When the directory is walked, its .iglooignore patterns are loaded first.
+func (f *libraryFilter) skipDir(dir string) (bool, error) {
+	if f.matcher.Match(dir, true) {
+		return true, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, helpers.NOMEDIA_FILE_NAME)); err == nil {
+		return true, nil
+	}
+
+	if err := f.matcher.AddIgnoreFile(dir); err != nil {
+		return false, fmt.Errorf("failed to read %s in %s: %w", helpers.IGNORE_FILE_NAME, dir, err)
+	}
+
+	return false, nil
+}
+
+// skipFile reports whether a file is ignored by pattern or is smaller than the minimum size.
+func (f *libraryFilter) skipFile(path string, size int64) bool {
+	if f.minSize > 0 && size < f.minSize {
+		return true
+	}
+
+	return f.matcher.Match(path, false)
+}
+
+// checkSampleUnchanged reports whether a file was skipped as a sample on an earlier
+// scan and hasn't changed since, so it isn't probed again.
+func checkSampleUnchanged(ctx context.Context, qtx *database.Queries, path string, size int64) bool {
+	_, err := qtx.CheckSampleFileUnchanged(ctx, database.CheckSampleFileUnchangedParams{
+		FilePath: path,
+		Size:     size,
+	})
+	return err == nil
+}
+
+// recordSampleFile remembers a file skipped as a sample until it changes or the
+// library's minimum duration does. A failure only costs a probe on the next scan.
+func (app *Application) recordSampleFile(ctx context.Context, qtx *database.Queries, library, path string, size int64) {
+	err := qtx.RecordSampleFile(ctx, database.RecordSampleFileParams{
+		FilePath: path,
+		Library:  library,
+		Size:     size,
+	})
+	if err != nil {
+		app.Logger.Warn("failed to record sample file", "error", err, "path", path)
+	}
+}
+
+// clearSampleFiles forgets the samples of a library when its minimum duration changed.
+func (app *Application) clearSampleFiles(ctx context.Context, library string, previous, current int64) {
+	if previous == current {
+		return
+	}
+
+	app.ScannerDBMu.Lock()
+	defer app.ScannerDBMu.Unlock()
+
+	if err := app.Queries.DeleteSampleFilesByLibrary(ctx, library); err != nil {
+		app.Logger.Warn("failed to clear sample files", "error", err, "library", library)
+	}
+}
+
+// belowMinDuration reports whether a file of durationMs milliseconds is shorter than
+// minSeconds. A zero minimum or an unknown duration never counts as a sample.
+func belowMinDuration(durationMs, minSeconds int64) bool {
+	return minSeconds > 0 && durationMs > 0 && durationMs < minSeconds*1000
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"igloo/cmd/internal/ffprobe"
+	"igloo/cmd/internal/helpers"
+)
+
+// TestScanMoviesLibrary_IgnoreRules tests that the walk skips .nomedia directories,
+// default, settings and .iglooignore patterns, and files below the minimum size.
+func TestScanMoviesLibrary_IgnoreRules(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	root := t.TempDir()
+	files := map[string]int{
+		"Alien (1979)/Alien (1979).mkv":      200,
+		"Heat (1995)/Heat (1995).mkv":        200,
+		"Heat (1995)/.nomedia":               0,
+		"Samples/Ran (1985) sample.mkv":      200,
+		"Ran (1985)/Ran (1985).mkv":          200,
+		"Ran (1985)/Ran (1985) proof.mkv":    200,
+		"Ran (1985)/.iglooignore":            0,
+		"Tiny (2001)/Tiny (2001).mkv":        10,
+		"Jaws (1975)/Jaws (1975).mkv.part":   200,
+		"Jaws (1975)/@eaDir/Jaws (1975).mkv": 200,
+		"Jaws (1975)/sample.mkv":             200,
+		"Jaws (1975)/Sample/Jaws (1975).mkv": 200,
+	}
+	for name, size := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	ignoreFile := filepath.Join(root, "Ran (1985)", ".iglooignore")
+	if err := os.WriteFile(ignoreFile, []byte("# proofs\n*.mkv\n!Ran (1985).mkv\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write .iglooignore: %v", err)
+	}
+
+	app.Ffprobe = &fakeFfprobe{heights: map[string]int{}}
+	settings := *app.Settings()
+	settings.MoviesDir = sql.NullString{String: root, Valid: true}
+	settings.MoviesIgnorePatterns = sql.NullString{String: "Samples/", Valid: true}
+	settings.MoviesMinSize = 100
+	app.SetSettings(&settings)
+
+	app.ScanMoviesLibrary()
+
+	rows, err := app.DB.Query("SELECT file_path FROM media_versions")
+	if err != nil {
+		t.Fatalf("Failed to query media versions: %v", err)
+	}
+	defer rows.Close()
+
+	scanned := []string{}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			t.Fatalf("Failed to scan row: %v", err)
+		}
+		scanned = append(scanned, strings.TrimPrefix(path, root+string(filepath.Separator)))
+	}
+	sort.Strings(scanned)
+
+	expected := []string{"Alien (1979)/Alien (1979).mkv", "Ran (1985)/Ran (1985).mkv"}
+	if strings.Join(scanned, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v to be scanned, got %v", expected, scanned)
+	}
+}
+
+// countingFfprobe counts the files probed, reporting each as a 10 second clip.
+type countingFfprobe struct {
+	fakeFfprobe
+	calls int
+}
+
+func (f *countingFfprobe) GetMetadata(filePath string) (*ffprobe.FfprobeResult, error) {
+	f.calls++
+	result, err := f.fakeFfprobe.GetMetadata(filePath)
+	result.Format.Duration = "10.000000"
+	return result, err
+}
+
+// TestProcessMoviesBatch_RemembersSamples tests that a file skipped for its duration
+// isn't probed again until it changes or the minimum duration does.
+func TestProcessMoviesBatch_RemembersSamples(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	app.DB.SetMaxOpenConns(1)
+
+	ctx := t.Context()
+	probe := &countingFfprobe{}
+	app.Ffprobe = probe
+	settings := *app.Settings()
+	settings.MoviesMinDuration = 60
+	app.SetSettings(&settings)
+
+	file := movieFile{path: "/movies/Alien (1979)/Alien (1979).mkv", ext: "mkv", size: 1000}
+	cache := newMovieScannerCache()
+
+	for range 2 {
+		scanned, skipped, errCount := app.processMoviesBatch(ctx, []movieFile{file}, cache)
+		if scanned != 0 || skipped != 1 || errCount != 0 {
+			t.Fatalf("Expected the sample to be skipped, got %d scanned, %d skipped, %d errors", scanned, skipped, errCount)
+		}
+	}
+	if probe.calls != 1 {
+		t.Errorf("Expected the unchanged sample to be probed once, got %d", probe.calls)
+	}
+
+	// A changed file is probed again
+	file.size = 2000
+	app.processMoviesBatch(ctx, []movieFile{file}, cache)
+	if probe.calls != 2 {
+		t.Errorf("Expected the changed sample to be probed again, got %d", probe.calls)
+	}
+
+	// As are all samples when the minimum duration changes
+	app.clearSampleFiles(ctx, helpers.SCAN_LIBRARY_MOVIES, 60, 5)
+	settings = *app.Settings()
+	settings.MoviesMinDuration = 5
+	app.SetSettings(&settings)
+	scanned, _, _ := app.processMoviesBatch(ctx, []movieFile{file}, cache)
+	if probe.calls != 3 || scanned != 1 {
+		t.Errorf("Expected the file to be probed and scanned, got %d calls and %d scanned", probe.calls, scanned)
+	}
+}
+
+func TestBelowMinDuration(t *testing.T) {
+	tests := []struct {
+		name       string
+		durationMs int64
+		minSeconds int64
+		expected   bool
+	}{
+		{"disabled", 1000, 0, false},
+		{"unknown duration", 0, 60, false},
+		{"shorter", 59999, 60, true},
+		{"exactly the minimum", 60000, 60, false},
+		{"longer", 90000, 60, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := belowMinDuration(tt.durationMs, tt.minSeconds); got != tt.expected {
+				t.Errorf("belowMinDuration(%d, %d) = %v, want %v", tt.durationMs, tt.minSeconds, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestUpdateScannerSettings tests that the ignore rules are stored and applied to the
+// in-memory settings, and that negative thresholds are rejected.
+func TestUpdateScannerSettings(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	if err := app.InitSettings(t.Context()); err != nil {
+		t.Fatalf("InitSettings failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"negative size", `{"movies_min_size": -1}`, http.StatusBadRequest},
+		{"unknown field", `{"ignore": "x"}`, http.StatusBadRequest},
+		{"valid", `{"movies_ignore_patterns": "Samples/\n*.iso\n", "music_min_duration": 30}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/settings/scanner", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+
+			app.UpdateScannerSettings(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	settings, err := app.Queries.GetSettings(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to get settings: %v", err)
+	}
+
+	if settings.MoviesIgnorePatterns.String != "Samples/\n*.iso" || settings.MusicMinDuration != 30 || settings.MoviesMinSize != 0 {
+		t.Errorf("Unexpected stored settings: %q, %d, %d", settings.MoviesIgnorePatterns.String, settings.MusicMinDuration, settings.MoviesMinSize)
+	}
+
+	if app.Settings().MusicMinDuration != 30 {
+		t.Errorf("Expected the in-memory settings to be updated, got %d", app.Settings().MusicMinDuration)
+	}
+}
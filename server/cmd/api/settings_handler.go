@@ -2,8 +2,12 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"igloo/cmd/internal/database"
 	"igloo/cmd/internal/helpers"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -15,6 +19,18 @@ var (
 	// movieScanMutex prevents multiple simultaneous movie scans
 	movieScanMutex  sync.Mutex
 	isMovieScanning bool
+
+	// audiobookScanMutex prevents multiple simultaneous audiobook scans
+	audiobookScanMutex  sync.Mutex
+	isAudiobookScanning bool
+
+	// refreshMutex prevents multiple simultaneous metadata refreshes
+	refreshMutex sync.Mutex
+	isRefreshing bool
+
+	// podcastPollMutex prevents multiple simultaneous podcast polls
+	podcastPollMutex sync.Mutex
+	isPodcastPolling bool
 )
 
 // GetSettings returns the application settings including library paths
@@ -31,9 +47,11 @@ func (app *Application) GetSettings(w http.ResponseWriter, r *http.Request) {
 	// Build response with library paths
 	// Only include paths that are configured (Valid = true)
 	responseData := map[string]any{
-		"music_dir":  nil,
-		"movies_dir": nil,
-		"shows_dir":  nil,
+		"music_dir":      nil,
+		"movies_dir":     nil,
+		"shows_dir":      nil,
+		"audiobooks_dir": nil,
+		"podcasts_dir":   nil,
 	}
 
 	if settings.MusicDir.Valid {
@@ -48,6 +66,41 @@ func (app *Application) GetSettings(w http.ResponseWriter, r *http.Request) {
 		responseData["shows_dir"] = settings.ShowsDir.String
 	}
 
+	if settings.AudiobooksDir.Valid {
+		responseData["audiobooks_dir"] = settings.AudiobooksDir.String
+	}
+
+	if settings.PodcastsDir.Valid {
+		responseData["podcasts_dir"] = settings.PodcastsDir.String
+	}
+
+	// Scanner ignore rules
+	responseData["movies_ignore_patterns"] = settings.MoviesIgnorePatterns.String
+	responseData["music_ignore_patterns"] = settings.MusicIgnorePatterns.String
+	responseData["audiobooks_ignore_patterns"] = settings.AudiobooksIgnorePatterns.String
+	responseData["movies_min_size"] = settings.MoviesMinSize
+	responseData["movies_min_duration"] = settings.MoviesMinDuration
+	responseData["music_min_size"] = settings.MusicMinSize
+	responseData["music_min_duration"] = settings.MusicMinDuration
+	responseData["movies_metadata_providers"] = settings.MoviesMetadataProviders
+	responseData["various_artists_name"] = settings.VariousArtistsName
+	responseData["music_spotify_enrichment"] = settings.MusicSpotifyEnrichment
+
+	// Metadata locale
+	responseData["metadata_language"] = settings.MetadataLanguage
+	responseData["metadata_fallback_language"] = settings.MetadataFallbackLanguage
+	responseData["certification_country"] = settings.CertificationCountry
+	responseData["movies_metadata_language"] = settings.MoviesMetadataLanguage.String
+	responseData["movies_metadata_fallback_language"] = settings.MoviesMetadataFallbackLanguage.String
+	responseData["movies_certification_country"] = settings.MoviesCertificationCountry.String
+
+	// Metadata refresh
+	responseData["metadata_refresh_days"] = settings.MetadataRefreshDays
+	responseData["metadata_refresh_rate"] = settings.MetadataRefreshRate
+
+	// Podcasts
+	responseData["podcast_poll_minutes"] = settings.PodcastPollMinutes
+
 	res := helpers.JSONResponse{
 		Error: false,
 		Data:  responseData,
@@ -56,6 +109,339 @@ func (app *Application) GetSettings(w http.ResponseWriter, r *http.Request) {
 	helpers.WriteJSON(w, http.StatusOK, res)
 }
 
+// UpdateScannerSettingsRequest holds the per-library ignore rules. Patterns are
+// newline-separated gitignore-style globs relative to the library root; sizes are
+// in bytes and durations in seconds, 0 disabling the threshold. MoviesMetadataProviders
+// lists the movie metadata providers by priority, comma-separated ("nfo,tmdb,embedded"),
+// and VariousArtistsName is the pseudo-musician compilations are filed under; both
+// are the default when empty.
+type UpdateScannerSettingsRequest struct {
+	MoviesIgnorePatterns     string `json:"movies_ignore_patterns"`
+	MusicIgnorePatterns      string `json:"music_ignore_patterns"`
+	AudiobooksIgnorePatterns string `json:"audiobooks_ignore_patterns"`
+	MoviesMinSize            int64  `json:"movies_min_size"`
+	MoviesMinDuration        int64  `json:"movies_min_duration"`
+	MusicMinSize             int64  `json:"music_min_size"`
+	MusicMinDuration         int64  `json:"music_min_duration"`
+	MoviesMetadataProviders  string `json:"movies_metadata_providers"`
+	VariousArtistsName       string `json:"various_artists_name"`
+	// MusicSpotifyEnrichment turns Spotify lookups for the music library on or off,
+	// omitting it keeps the current value
+	MusicSpotifyEnrichment *bool `json:"music_spotify_enrichment"`
+}
+
+// UpdateScannerSettings replaces the scanner ignore rules. They apply from the next scan.
+func (app *Application) UpdateScannerSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req UpdateScannerSettingsRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.MoviesMinSize < 0 || req.MoviesMinDuration < 0 || req.MusicMinSize < 0 || req.MusicMinDuration < 0 {
+		helpers.ErrorJSON(w, errors.New("minimum sizes and durations can't be negative"), http.StatusBadRequest)
+		return
+	}
+
+	providers, err := parseMovieMetadataProviders(req.MoviesMetadataProviders)
+	if err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	variousArtists := strings.TrimSpace(req.VariousArtistsName)
+	if variousArtists == "" {
+		variousArtists = helpers.VARIOUS_ARTISTS_NAME
+	}
+
+	previous := app.Settings()
+	spotifyEnrichment := previous.MusicSpotifyEnrichment
+	if req.MusicSpotifyEnrichment != nil {
+		spotifyEnrichment = *req.MusicSpotifyEnrichment
+	}
+
+	settings, err := app.Queries.UpdateScannerSettings(ctx, database.UpdateScannerSettingsParams{
+		MoviesIgnorePatterns:     helpers.NullString(strings.TrimSpace(req.MoviesIgnorePatterns)),
+		MusicIgnorePatterns:      helpers.NullString(strings.TrimSpace(req.MusicIgnorePatterns)),
+		AudiobooksIgnorePatterns: helpers.NullString(strings.TrimSpace(req.AudiobooksIgnorePatterns)),
+		MoviesMinSize:            req.MoviesMinSize,
+		MoviesMinDuration:        req.MoviesMinDuration,
+		MusicMinSize:             req.MusicMinSize,
+		MusicMinDuration:         req.MusicMinDuration,
+		MoviesMetadataProviders:  strings.Join(providers, ","),
+		VariousArtistsName:       variousArtists,
+		MusicSpotifyEnrichment:   spotifyEnrichment,
+		ID:                       previous.ID,
+	})
+	if err != nil {
+		app.Logger.Error("failed to update scanner settings", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update scanner settings"))
+		return
+	}
+
+	app.SetSettings(&settings)
+
+	// Samples are probed again against a new minimum duration
+	app.clearSampleFiles(ctx, helpers.SCAN_LIBRARY_MOVIES, previous.MoviesMinDuration, settings.MoviesMinDuration)
+	app.clearSampleFiles(ctx, helpers.SCAN_LIBRARY_MUSIC, previous.MusicMinDuration, settings.MusicMinDuration)
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"movies_ignore_patterns":     settings.MoviesIgnorePatterns.String,
+			"music_ignore_patterns":      settings.MusicIgnorePatterns.String,
+			"audiobooks_ignore_patterns": settings.AudiobooksIgnorePatterns.String,
+			"movies_min_size":            settings.MoviesMinSize,
+			"movies_min_duration":        settings.MoviesMinDuration,
+			"music_min_size":             settings.MusicMinSize,
+			"music_min_duration":         settings.MusicMinDuration,
+			"movies_metadata_providers":  settings.MoviesMetadataProviders,
+			"various_artists_name":       settings.VariousArtistsName,
+			"music_spotify_enrichment":   settings.MusicSpotifyEnrichment,
+		},
+	})
+}
+
+// UpdateMetadataLanguageSettingsRequest holds the metadata locale: the language of
+// titles and overviews ("es-ES"), the language filling texts without a translation
+// (empty disables the fallback) and the country whose age ratings are preferred ("ES").
+// The movies_ fields override them for the movies library, empty keeping the global value.
+type UpdateMetadataLanguageSettingsRequest struct {
+	MetadataLanguage               string `json:"metadata_language"`
+	MetadataFallbackLanguage       string `json:"metadata_fallback_language"`
+	CertificationCountry           string `json:"certification_country"`
+	MoviesMetadataLanguage         string `json:"movies_metadata_language"`
+	MoviesMetadataFallbackLanguage string `json:"movies_metadata_fallback_language"`
+	MoviesCertificationCountry     string `json:"movies_certification_country"`
+}
+
+// UpdateMetadataLanguageSettings replaces the metadata locale. It applies from the next
+// scan, match or refresh.
+func (app *Application) UpdateMetadataLanguageSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req UpdateMetadataLanguageSettingsRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.MetadataLanguage) == "" {
+		req.MetadataLanguage = helpers.METADATA_LANGUAGE
+	}
+	if strings.TrimSpace(req.CertificationCountry) == "" {
+		req.CertificationCountry = helpers.CERTIFICATION_COUNTRY
+	}
+
+	// Blank fields stay empty: no fallback, or the global value for the movies library
+	var language, fallback, country, moviesLanguage, moviesFallback, moviesCountry string
+	for _, field := range []struct {
+		value     string
+		normalize func(string) (string, error)
+		target    *string
+	}{
+		{req.MetadataLanguage, helpers.NormalizeLanguageTag, &language},
+		{req.MetadataFallbackLanguage, helpers.NormalizeLanguageTag, &fallback},
+		{req.CertificationCountry, helpers.NormalizeCountryCode, &country},
+		{req.MoviesMetadataLanguage, helpers.NormalizeLanguageTag, &moviesLanguage},
+		{req.MoviesMetadataFallbackLanguage, helpers.NormalizeLanguageTag, &moviesFallback},
+		{req.MoviesCertificationCountry, helpers.NormalizeCountryCode, &moviesCountry},
+	} {
+		if strings.TrimSpace(field.value) == "" {
+			continue
+		}
+
+		normalized, err := field.normalize(field.value)
+		if err != nil {
+			helpers.ErrorJSON(w, err, http.StatusBadRequest)
+			return
+		}
+		*field.target = normalized
+	}
+
+	settings, err := app.Queries.UpdateMetadataLanguageSettings(ctx, database.UpdateMetadataLanguageSettingsParams{
+		MetadataLanguage:               language,
+		MetadataFallbackLanguage:       fallback,
+		CertificationCountry:           country,
+		MoviesMetadataLanguage:         helpers.NullString(moviesLanguage),
+		MoviesMetadataFallbackLanguage: helpers.NullString(moviesFallback),
+		MoviesCertificationCountry:     helpers.NullString(moviesCountry),
+		ID:                             app.Settings().ID,
+	})
+	if err != nil {
+		app.Logger.Error("failed to update metadata language settings", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update metadata language settings"))
+		return
+	}
+
+	app.SetSettings(&settings)
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"metadata_language":                 settings.MetadataLanguage,
+			"metadata_fallback_language":        settings.MetadataFallbackLanguage,
+			"certification_country":             settings.CertificationCountry,
+			"movies_metadata_language":          settings.MoviesMetadataLanguage.String,
+			"movies_metadata_fallback_language": settings.MoviesMetadataFallbackLanguage.String,
+			"movies_certification_country":      settings.MoviesCertificationCountry.String,
+		},
+	})
+}
+
+// UpdateMetadataRefreshSettingsRequest holds the staleness window in days (0 pauses
+// the periodic refresh) and the provider requests allowed per minute.
+type UpdateMetadataRefreshSettingsRequest struct {
+	MetadataRefreshDays int64 `json:"metadata_refresh_days"`
+	MetadataRefreshRate int64 `json:"metadata_refresh_rate"`
+}
+
+// UpdateMetadataRefreshSettings replaces the metadata refresh settings. They apply from the next refresh.
+func (app *Application) UpdateMetadataRefreshSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req UpdateMetadataRefreshSettingsRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.MetadataRefreshDays < 0 {
+		helpers.ErrorJSON(w, errors.New("metadata refresh days can't be negative"), http.StatusBadRequest)
+		return
+	}
+
+	if req.MetadataRefreshRate < 1 {
+		helpers.ErrorJSON(w, errors.New("metadata refresh rate must be at least 1 request per minute"), http.StatusBadRequest)
+		return
+	}
+
+	settings, err := app.Queries.UpdateMetadataRefreshSettings(ctx, database.UpdateMetadataRefreshSettingsParams{
+		MetadataRefreshDays: req.MetadataRefreshDays,
+		MetadataRefreshRate: req.MetadataRefreshRate,
+		ID:                  app.Settings().ID,
+	})
+	if err != nil {
+		app.Logger.Error("failed to update metadata refresh settings", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update metadata refresh settings"))
+		return
+	}
+
+	app.SetSettings(&settings)
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"metadata_refresh_days": settings.MetadataRefreshDays,
+			"metadata_refresh_rate": settings.MetadataRefreshRate,
+		},
+	})
+}
+
+// UpdatePodcastSettingsRequest holds the minutes between podcast feed polls, 0
+// disabling polling, and the folder episodes are downloaded to. Omitting the folder
+// keeps the current one, an empty one turns downloads off.
+type UpdatePodcastSettingsRequest struct {
+	PodcastPollMinutes int64   `json:"podcast_poll_minutes"`
+	PodcastsDir        *string `json:"podcasts_dir"`
+}
+
+// UpdatePodcastSettings replaces the podcast poll interval and downloads folder. They
+// apply from the next poll.
+func (app *Application) UpdatePodcastSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req UpdatePodcastSettingsRequest
+	if err := helpers.ReadJSON(w, r, &req, 0); err != nil {
+		helpers.ErrorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if req.PodcastPollMinutes < 0 {
+		helpers.ErrorJSON(w, errors.New("podcast poll minutes can't be negative"), http.StatusBadRequest)
+		return
+	}
+
+	podcastsDir := app.Settings().PodcastsDir
+	if req.PodcastsDir != nil {
+		podcastsDir = helpers.NullString(strings.TrimSpace(*req.PodcastsDir))
+	}
+
+	if podcastsDir.Valid {
+		if !filepath.IsAbs(podcastsDir.String) {
+			helpers.ErrorJSON(w, errors.New("podcasts directory must be an absolute path"), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := helpers.GetOrCreateDir(podcastsDir.String); err != nil {
+			helpers.ErrorJSON(w, fmt.Errorf("podcasts directory unavailable: %w", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	settings, err := app.Queries.UpdatePodcastSettings(ctx, database.UpdatePodcastSettingsParams{
+		PodcastPollMinutes: req.PodcastPollMinutes,
+		PodcastsDir:        podcastsDir,
+		ID:                 app.Settings().ID,
+	})
+	if err != nil {
+		app.Logger.Error("failed to update podcast settings", "error", err)
+		helpers.ErrorJSON(w, errors.New("failed to update podcast settings"))
+		return
+	}
+
+	app.SetSettings(&settings)
+
+	helpers.WriteJSON(w, http.StatusOK, helpers.JSONResponse{
+		Error: false,
+		Data: map[string]any{
+			"podcast_poll_minutes": settings.PodcastPollMinutes,
+			"podcasts_dir":         settings.PodcastsDir.String,
+		},
+	})
+}
+
+// TriggerMetadataRefresh starts a refresh of stale movie and musician metadata
+// The refresh runs asynchronously in a goroutine and returns immediately
+func (app *Application) TriggerMetadataRefresh(w http.ResponseWriter, r *http.Request) {
+	if app.Tmdb == nil && app.Spotify == nil {
+		helpers.ErrorJSON(w, errors.New("neither tmdb nor spotify is configured"), http.StatusServiceUnavailable)
+		return
+	}
+
+	refreshMutex.Lock()
+	if isRefreshing {
+		refreshMutex.Unlock()
+		helpers.ErrorJSON(w, errors.New("metadata refresh is already in progress"))
+		return
+	}
+
+	isRefreshing = true
+	refreshMutex.Unlock()
+
+	// Start refresh in background goroutine
+	go func() {
+		defer func() {
+			refreshMutex.Lock()
+			isRefreshing = false
+			refreshMutex.Unlock()
+		}()
+
+		app.RefreshMetadata()
+	}()
+
+	app.Logger.Info("metadata refresh triggered via API")
+
+	res := helpers.JSONResponse{
+		Error:   false,
+		Message: "Metadata refresh started",
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
 // TriggerMusicScan triggers a new music library scan
 // The scan runs asynchronously in a goroutine and returns immediately
 func (app *Application) TriggerMusicScan(w http.ResponseWriter, r *http.Request) {
@@ -70,7 +456,8 @@ func (app *Application) TriggerMusicScan(w http.ResponseWriter, r *http.Request)
 	scanMutex.Unlock()
 
 	// Check if music directory is configured
-	if !app.Settings.MusicDir.Valid || app.Settings.MusicDir.String == "" {
+	settings := app.Settings()
+	if !settings.MusicDir.Valid || settings.MusicDir.String == "" {
 		scanMutex.Lock()
 		isScanning = false
 		scanMutex.Unlock()
@@ -89,7 +476,7 @@ func (app *Application) TriggerMusicScan(w http.ResponseWriter, r *http.Request)
 		app.ScanMusicLibrary()
 	}()
 
-	app.Logger.Info("music library scan triggered via API", "path", app.Settings.MusicDir.String)
+	app.Logger.Info("music library scan triggered via API", "path", settings.MusicDir.String)
 
 	res := helpers.JSONResponse{
 		Error:   false,
@@ -99,6 +486,50 @@ func (app *Application) TriggerMusicScan(w http.ResponseWriter, r *http.Request)
 	helpers.WriteJSON(w, http.StatusOK, res)
 }
 
+// TriggerAudiobookScan triggers a new audiobook library scan
+// The scan runs asynchronously in a goroutine and returns immediately
+func (app *Application) TriggerAudiobookScan(w http.ResponseWriter, r *http.Request) {
+	audiobookScanMutex.Lock()
+	if isAudiobookScanning {
+		audiobookScanMutex.Unlock()
+		helpers.ErrorJSON(w, errors.New("audiobook library scan is already in progress"))
+		return
+	}
+
+	isAudiobookScanning = true
+	audiobookScanMutex.Unlock()
+
+	// Check if audiobooks directory is configured
+	settings := app.Settings()
+	if !settings.AudiobooksDir.Valid || settings.AudiobooksDir.String == "" {
+		audiobookScanMutex.Lock()
+		isAudiobookScanning = false
+		audiobookScanMutex.Unlock()
+		helpers.ErrorJSON(w, errors.New("audiobooks directory is not configured"))
+		return
+	}
+
+	// Start scan in background goroutine
+	go func() {
+		defer func() {
+			audiobookScanMutex.Lock()
+			isAudiobookScanning = false
+			audiobookScanMutex.Unlock()
+		}()
+
+		app.ScanAudiobooksLibrary()
+	}()
+
+	app.Logger.Info("audiobook library scan triggered via API", "path", settings.AudiobooksDir.String)
+
+	res := helpers.JSONResponse{
+		Error:   false,
+		Message: "Audiobook library scan started",
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, res)
+}
+
 // TriggerMovieScan triggers a new movie library scan
 // The scan runs asynchronously in a goroutine and returns immediately
 func (app *Application) TriggerMovieScan(w http.ResponseWriter, r *http.Request) {
@@ -113,7 +544,8 @@ func (app *Application) TriggerMovieScan(w http.ResponseWriter, r *http.Request)
 	movieScanMutex.Unlock()
 
 	// Check if movies directory is configured
-	if !app.Settings.MoviesDir.Valid || app.Settings.MoviesDir.String == "" {
+	settings := app.Settings()
+	if !settings.MoviesDir.Valid || settings.MoviesDir.String == "" {
 		movieScanMutex.Lock()
 		isMovieScanning = false
 		movieScanMutex.Unlock()
@@ -132,7 +564,7 @@ func (app *Application) TriggerMovieScan(w http.ResponseWriter, r *http.Request)
 		app.ScanMoviesLibrary()
 	}()
 
-	app.Logger.Info("movie library scan triggered via API", "path", app.Settings.MoviesDir.String)
+	app.Logger.Info("movie library scan triggered via API", "path", settings.MoviesDir.String)
 
 	res := helpers.JSONResponse{
 		Error:   false,
@@ -24,13 +24,13 @@ func (app *Application) ServeStaticFiles(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Build the full file path
-	fullPath := filepath.Join(app.Settings.StaticDir, requestedPath)
+	fullPath := filepath.Join(app.Settings().StaticDir, requestedPath)
 
 	// Clean the path to prevent any remaining traversal attempts
 	fullPath = filepath.Clean(fullPath)
 
 	// Verify the path is still within the static directory
-	if !strings.HasPrefix(fullPath, filepath.Clean(app.Settings.StaticDir)) {
+	if !strings.HasPrefix(fullPath, filepath.Clean(app.Settings().StaticDir)) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
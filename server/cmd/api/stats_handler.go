@@ -18,7 +18,10 @@ type RecordPlayEventRequest struct {
 }
 
 // RecordPlayEvent records when a user plays a track.
-// Called by the frontend after playback threshold is met (30s or 80% completion).
+// Called by the frontend for plays of at least 30s or 80% completion, once half the
+// track or 4 minutes were heard or when the play stops before that. Plays meeting
+// Last.fm's and ListenBrainz's rules are queued for submission when the user linked
+// an account.
 func (app *Application) RecordPlayEvent(w http.ResponseWriter, r *http.Request) {
 	userID := app.SessionManager.GetInt64(r.Context(), helpers.COOKIE_USER_ID)
 	if userID == 0 {
@@ -75,6 +78,18 @@ func (app *Application) RecordPlayEvent(w http.ResponseWriter, r *http.Request)
 		// Don't fail the request, the play was still recorded
 	}
 
+	err = app.queueLastfmScrobble(ctx, userID, req.TrackID, req.DurationPlayed, req.Completed)
+	if err != nil {
+		app.Logger.Error("failed to queue lastfm scrobble", "error", err)
+		// Don't fail the request, the play was still recorded
+	}
+
+	err = app.queueListenbrainzListen(ctx, userID, req.TrackID, req.DurationPlayed, req.Completed)
+	if err != nil {
+		app.Logger.Error("failed to queue listenbrainz listen", "error", err)
+		// Don't fail the request, the play was still recorded
+	}
+
 	res := helpers.JSONResponse{
 		Error: false,
 		Data:  map[string]any{"recorded": true},
@@ -10,10 +10,11 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-// GetMoviesInTheaters returns the latest movies currently playing in theaters.
+// GetMoviesInTheaters returns the latest movies currently playing in theaters in the
+// movies library's country and language.
 // The response is limited to a maximum of 12 movies.
 func (app *Application) GetMoviesInTheaters(w http.ResponseWriter, r *http.Request) {
-	movies, err := app.Tmdb.GetMoviesInTheaters()
+	movies, err := app.Tmdb.GetMoviesInTheaters(app.movieMetadataLocale())
 	if err != nil {
 		app.Logger.Error("failed to get movies in theaters", "error", err)
 		helpers.ErrorJSON(w, errors.New("failed to fetch movies in theaters"))
@@ -44,7 +45,7 @@ func (app *Application) GetMovieByTmdbID(w http.ResponseWriter, r *http.Request)
 	}
 
 	movie := &tmdb.TmdbMovie{TmdbID: id}
-	err = app.Tmdb.GetTmdbMovieByID(movie)
+	err = app.Tmdb.GetTmdbMovieByID(movie, app.movieMetadataLocale())
 	if err != nil {
 		app.Logger.Error("failed to get movie from tmdb", "error", err, "tmdb_id", id)
 		helpers.ErrorJSON(w, errors.New("failed to fetch movie from tmdb"))
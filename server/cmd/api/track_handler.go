@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"igloo/cmd/internal/database"
 	"igloo/cmd/internal/helpers"
@@ -172,6 +173,12 @@ func (app *Application) StreamTrack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// CUE sheet tracks are cut from a larger file, which only ffmpeg can do
+	if track.SourcePath.Valid || helpers.NeedsAudioTranscode(track.Container, track.Codec) {
+		app.streamTranscodedTrack(w, r, track)
+		return
+	}
+
 	file, err := os.Open(track.FilePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -198,6 +205,57 @@ func (app *Application) StreamTrack(w http.ResponseWriter, r *http.Request) {
 	http.ServeContent(w, r, track.FileName, stat.ModTime(), file)
 }
 
+// streamTranscodedTrack serves a track browsers can't decode (AIFF, WavPack, APE, ALAC),
+// or a CUE sheet track cut from its source file, by transcoding it to FLAC on the fly.
+// The output length isn't known up front, so range requests are not supported and
+// the full stream is always sent.
+func (app *Application) streamTranscodedTrack(w http.ResponseWriter, r *http.Request, track database.Track) {
+	if app.Ffmpeg == nil {
+		helpers.ErrorJSON(w, errors.New("transcoding is not available"), http.StatusNotImplemented)
+		return
+	}
+
+	path := track.FilePath
+	if track.SourcePath.Valid {
+		path = track.SourcePath.String
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			app.Logger.Error("track file not found on disk", "path", path, "id", track.ID)
+			helpers.ErrorJSON(w, errors.New("track file not found"), http.StatusNotFound)
+			return
+		}
+
+		app.Logger.Error("failed to stat track file", "error", err, "path", path)
+		helpers.ErrorJSON(w, errors.New("failed to read track file"))
+		return
+	}
+
+	w.Header().Set("Content-Type", helpers.AUDIO_TRANSCODE_MIME_TYPE)
+	w.Header().Set("Accept-Ranges", "none")
+
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var err error
+	if track.SourcePath.Valid {
+		// Offsets are stored in milliseconds, an end of 0 plays to the end of the file
+		start := time.Duration(track.StartOffset.Int64) * time.Millisecond
+		end := time.Duration(track.EndOffset.Int64) * time.Millisecond
+		err = app.Ffmpeg.TranscodeAudioRange(r.Context(), path, start, end, w)
+	} else {
+		err = app.Ffmpeg.TranscodeAudio(r.Context(), path, w)
+	}
+
+	if err != nil && r.Context().Err() == nil {
+		// The response may already be partially written, so the error can only be logged
+		app.Logger.Error("failed to transcode track", "error", err, "id", track.ID, "path", path)
+	}
+}
+
 // GetTracksAlphabetical returns a paginated list of tracks sorted alphabetically.
 // Supports query parameters: limit (default 50, max 100), offset (default 0)
 func (app *Application) GetTracksAlphabetical(w http.ResponseWriter, r *http.Request) {
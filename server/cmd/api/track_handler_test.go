@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"igloo/cmd/internal/database"
 	"igloo/cmd/internal/helpers"
@@ -553,3 +555,67 @@ func TestStreamTrack_MultipleRanges(t *testing.T) {
 		t.Error("Expected different Content-Range headers for different byte ranges")
 	}
 }
+
+// fakeFfmpeg records the transcoded path and range and writes a fixed payload instead of running ffmpeg.
+type fakeFfmpeg struct {
+	path       string
+	start, end time.Duration
+}
+
+func (f *fakeFfmpeg) TranscodeAudio(ctx context.Context, filePath string, w io.Writer) error {
+	f.path = filePath
+	_, err := io.WriteString(w, "fLaC")
+	return err
+}
+
+func (f *fakeFfmpeg) TranscodeAudioRange(ctx context.Context, filePath string, start, end time.Duration, w io.Writer) error {
+	f.start, f.end = start, end
+	return f.TranscodeAudio(ctx, filePath, w)
+}
+
+// TestStreamTrack_TranscodesUnsupportedCodec tests that formats browsers can't play
+// (here ALAC in m4a) are served through ffmpeg as FLAC instead of the raw file.
+func TestStreamTrack_TranscodesUnsupportedCodec(t *testing.T) {
+	app := setupTestAppWithLogger(t)
+	defer app.DB.Close()
+
+	ffmpeg := &fakeFfmpeg{}
+	app.Ffmpeg = ffmpeg
+
+	filePath := filepath.Join(t.TempDir(), "alac.m4a")
+	if err := os.WriteFile(filePath, []byte("not really alac"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	track := insertTestTrack(t, app, filePath, "alac.m4a", "audio/mp4")
+
+	_, err := app.DB.Exec("UPDATE tracks SET codec = 'alac' WHERE id = ?", track.ID)
+	if err != nil {
+		t.Fatalf("Failed to update codec: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/music/tracks/1/stream", nil)
+	req.Header.Set("Range", "bytes=0-1")
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	app.StreamTrack(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	if contentType := rr.Header().Get("Content-Type"); contentType != helpers.AUDIO_TRANSCODE_MIME_TYPE {
+		t.Errorf("Expected Content-Type %s, got %s", helpers.AUDIO_TRANSCODE_MIME_TYPE, contentType)
+	}
+
+	if rr.Body.String() != "fLaC" {
+		t.Errorf("Expected transcoded body, got %q", rr.Body.String())
+	}
+
+	if ffmpeg.path != filePath {
+		t.Errorf("Expected ffmpeg to transcode %s, got %s", filePath, ffmpeg.path)
+	}
+}
@@ -305,7 +305,7 @@ func (app *Application) UploadUserAvatar(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Ensure avatars directory exists
-	avatarsDir := filepath.Join(app.Settings.StaticDir, "avatars")
+	avatarsDir := filepath.Join(app.Settings().StaticDir, "avatars")
 	if err := os.MkdirAll(avatarsDir, 0755); err != nil {
 		app.Logger.Error("failed to create avatars directory", "error", err)
 		helpers.ErrorJSON(w, errors.New(helpers.INTERNAL_SERVER_ERROR))
@@ -383,7 +383,7 @@ func isUploadedAvatar(avatarURL string) bool {
 func (app *Application) deleteAvatarFile(avatarURL string) {
 	// Extract the file path from the URL: /api/static/avatars/1.jpg -> avatars/1.jpg
 	relativePath := strings.TrimPrefix(avatarURL, "/api/static/")
-	fullPath := filepath.Join(app.Settings.StaticDir, relativePath)
+	fullPath := filepath.Join(app.Settings().StaticDir, relativePath)
 
 	if err := os.Remove(fullPath); err != nil {
 		if !os.IsNotExist(err) {
@@ -20,9 +20,58 @@ func (q *Queries) DeleteAlbum(ctx context.Context, id int64) error {
 	return err
 }
 
+const getAlbumByDirectory = `-- name: GetAlbumByDirectory :one
+SELECT
+  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+FROM
+  albums
+WHERE
+  directory = ?1
+  AND (
+    title = ?2
+    OR scan_title = ?2
+  )
+LIMIT
+  1
+`
+
+type GetAlbumByDirectoryParams struct {
+	Directory sql.NullString `json:"directory"`
+	Title     string         `json:"title"`
+}
+
+// Finds an album grouped by the folder holding its tracks.
+func (q *Queries) GetAlbumByDirectory(ctx context.Context, arg GetAlbumByDirectoryParams) (Album, error) {
+	row := q.queryRow(ctx, q.getAlbumByDirectoryStmt, getAlbumByDirectory, arg.Directory, arg.Title)
+	var i Album
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.Musician,
+		&i.SpotifyID,
+		&i.SpotifyPopularity,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.TotalTracks,
+		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getAlbumByID = `-- name: GetAlbumByID :one
 SELECT
-  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, created_at, updated_at
+  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
 FROM
   albums
 WHERE
@@ -45,6 +94,102 @@ func (q *Queries) GetAlbumByID(ctx context.Context, id int64) (Album, error) {
 		&i.Year,
 		&i.TotalTracks,
 		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAlbumByMusicbrainzID = `-- name: GetAlbumByMusicbrainzID :one
+SELECT
+  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+FROM
+  albums
+WHERE
+  musicbrainz_album_id = ?
+LIMIT
+  1
+`
+
+func (q *Queries) GetAlbumByMusicbrainzID(ctx context.Context, musicbrainzAlbumID sql.NullString) (Album, error) {
+	row := q.queryRow(ctx, q.getAlbumByMusicbrainzIDStmt, getAlbumByMusicbrainzID, musicbrainzAlbumID)
+	var i Album
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.Musician,
+		&i.SpotifyID,
+		&i.SpotifyPopularity,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.TotalTracks,
+		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAlbumByScanTitle = `-- name: GetAlbumByScanTitle :one
+SELECT
+  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+FROM
+  albums
+WHERE
+  scan_title = ?
+  AND musician IS ?
+LIMIT
+  1
+`
+
+type GetAlbumByScanTitleParams struct {
+	ScanTitle sql.NullString `json:"scan_title"`
+	Musician  sql.NullString `json:"musician"`
+}
+
+// Finds an album renamed by hand through the tag title the scanner still reads.
+func (q *Queries) GetAlbumByScanTitle(ctx context.Context, arg GetAlbumByScanTitleParams) (Album, error) {
+	row := q.queryRow(ctx, q.getAlbumByScanTitleStmt, getAlbumByScanTitle,
+		arg.ScanTitle,
+		arg.Musician,
+	)
+	var i Album
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.Musician,
+		&i.SpotifyID,
+		&i.SpotifyPopularity,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.TotalTracks,
+		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -53,7 +198,7 @@ func (q *Queries) GetAlbumByID(ctx context.Context, id int64) (Album, error) {
 
 const getAlbumBySpotifyID = `-- name: GetAlbumBySpotifyID :one
 SELECT
-  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, created_at, updated_at
+  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
 FROM
   albums
 WHERE
@@ -76,6 +221,14 @@ func (q *Queries) GetAlbumBySpotifyID(ctx context.Context, spotifyID sql.NullStr
 		&i.Year,
 		&i.TotalTracks,
 		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -88,9 +241,13 @@ SELECT
   title,
   cover,
   musician,
-  year
+  year,
+  is_compilation
 FROM
   albums
+WHERE
+  ?1 IS NULL
+  OR is_compilation = ?1
 ORDER BY
   CASE
     WHEN UPPER(SUBSTR(title, 1, 1)) BETWEEN 'A' AND 'Z'
@@ -102,22 +259,25 @@ LIMIT ? OFFSET ?
 `
 
 type GetAlbumsAlphabeticalParams struct {
-	Limit  int64 `json:"limit"`
-	Offset int64 `json:"offset"`
+	IsCompilation sql.NullBool `json:"is_compilation"`
+	Limit         int64        `json:"limit"`
+	Offset        int64        `json:"offset"`
 }
 
 type GetAlbumsAlphabeticalRow struct {
-	ID       int64          `json:"id"`
-	Title    string         `json:"title"`
-	Cover    sql.NullString `json:"cover"`
-	Musician sql.NullString `json:"musician"`
-	Year     sql.NullInt64  `json:"year"`
+	ID            int64          `json:"id"`
+	Title         string         `json:"title"`
+	Cover         sql.NullString `json:"cover"`
+	Musician      sql.NullString `json:"musician"`
+	Year          sql.NullInt64  `json:"year"`
+	IsCompilation bool           `json:"is_compilation"`
 }
 
 // Returns albums sorted alphabetically by title with pagination.
 // Non-alphabetic titles (numbers, symbols) are grouped under '#' and sorted first.
+// A NULL is_compilation returns compilations and regular albums alike.
 func (q *Queries) GetAlbumsAlphabetical(ctx context.Context, arg GetAlbumsAlphabeticalParams) ([]GetAlbumsAlphabeticalRow, error) {
-	rows, err := q.query(ctx, q.getAlbumsAlphabeticalStmt, getAlbumsAlphabetical, arg.Limit, arg.Offset)
+	rows, err := q.query(ctx, q.getAlbumsAlphabeticalStmt, getAlbumsAlphabetical, arg.IsCompilation, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +291,71 @@ func (q *Queries) GetAlbumsAlphabetical(ctx context.Context, arg GetAlbumsAlphab
 			&i.Cover,
 			&i.Musician,
 			&i.Year,
+			&i.IsCompilation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAlbumsByTitle = `-- name: GetAlbumsByTitle :many
+SELECT
+  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+FROM
+  albums
+WHERE
+  title = ?
+  AND musician IS ?
+ORDER BY
+  id ASC
+`
+
+type GetAlbumsByTitleParams struct {
+	Title    string         `json:"title"`
+	Musician sql.NullString `json:"musician"`
+}
+
+func (q *Queries) GetAlbumsByTitle(ctx context.Context, arg GetAlbumsByTitleParams) ([]Album, error) {
+	rows, err := q.query(ctx, q.getAlbumsByTitleStmt, getAlbumsByTitle,
+		arg.Title,
+		arg.Musician,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Album{}
+	for rows.Next() {
+		var i Album
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.SortTitle,
+			&i.Musician,
+			&i.SpotifyID,
+			&i.SpotifyPopularity,
+			&i.ReleaseDate,
+			&i.Year,
+			&i.TotalTracks,
+			&i.Cover,
+			&i.ScanTitle,
+			&i.LockedFields,
+			&i.IsCompilation,
+			&i.Directory,
+			&i.MusicbrainzAlbumID,
+			&i.MusicbrainzReleaseGroupID,
+			&i.SpotifyMatchScore,
+			&i.SpotifyMatchLocked,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -145,6 +370,23 @@ func (q *Queries) GetAlbumsAlphabetical(ctx context.Context, arg GetAlbumsAlphab
 	return items, nil
 }
 
+const getFilteredAlbumsCount = `-- name: GetFilteredAlbumsCount :one
+SELECT
+  COUNT(*)
+FROM
+  albums
+WHERE
+  ?1 IS NULL
+  OR is_compilation = ?1
+`
+
+func (q *Queries) GetFilteredAlbumsCount(ctx context.Context, isCompilation sql.NullBool) (int64, error) {
+	row := q.queryRow(ctx, q.getFilteredAlbumsCountStmt, getFilteredAlbumsCount, isCompilation)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getLatestAlbums = `-- name: GetLatestAlbums :many
 SELECT
   id,
@@ -197,6 +439,264 @@ func (q *Queries) GetLatestAlbums(ctx context.Context) ([]GetLatestAlbumsRow, er
 	return items, nil
 }
 
+const getUnmatchedAlbum = `-- name: GetUnmatchedAlbum :one
+SELECT
+  id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+FROM
+  albums
+WHERE
+  musicbrainz_album_id IS NULL
+  AND musician IS ?1
+  AND (
+    title = ?2
+    OR scan_title = ?2
+  )
+ORDER BY
+  scan_title IS NULL
+LIMIT
+  1
+`
+
+type GetUnmatchedAlbumParams struct {
+	Musician sql.NullString `json:"musician"`
+	Title    string         `json:"title"`
+}
+
+// Finds an album without a MusicBrainz release id by title and album artist, or by the
+// tag title of one renamed by hand.
+func (q *Queries) GetUnmatchedAlbum(ctx context.Context, arg GetUnmatchedAlbumParams) (Album, error) {
+	row := q.queryRow(ctx, q.getUnmatchedAlbumStmt, getUnmatchedAlbum,
+		arg.Musician,
+		arg.Title,
+	)
+	var i Album
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.Musician,
+		&i.SpotifyID,
+		&i.SpotifyPopularity,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.TotalTracks,
+		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const markAlbumCompilation = `-- name: MarkAlbumCompilation :exec
+UPDATE albums
+SET
+  is_compilation = 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?
+`
+
+func (q *Queries) MarkAlbumCompilation(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.markAlbumCompilationStmt, markAlbumCompilation, id)
+	return err
+}
+
+const setAlbumDirectory = `-- name: SetAlbumDirectory :exec
+UPDATE albums
+SET
+  directory = ?
+WHERE
+  id = ?
+`
+
+type SetAlbumDirectoryParams struct {
+	Directory sql.NullString `json:"directory"`
+	ID        int64          `json:"id"`
+}
+
+func (q *Queries) SetAlbumDirectory(ctx context.Context, arg SetAlbumDirectoryParams) error {
+	_, err := q.exec(ctx, q.setAlbumDirectoryStmt, setAlbumDirectory, arg.Directory, arg.ID)
+	return err
+}
+
+const setAlbumMusicbrainzIDs = `-- name: SetAlbumMusicbrainzIDs :one
+UPDATE albums
+SET
+  musicbrainz_album_id = ?,
+  musicbrainz_release_group_id = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+`
+
+type SetAlbumMusicbrainzIDsParams struct {
+	MusicbrainzAlbumID        sql.NullString `json:"musicbrainz_album_id"`
+	MusicbrainzReleaseGroupID sql.NullString `json:"musicbrainz_release_group_id"`
+	ID                        int64          `json:"id"`
+}
+
+func (q *Queries) SetAlbumMusicbrainzIDs(ctx context.Context, arg SetAlbumMusicbrainzIDsParams) (Album, error) {
+	row := q.queryRow(ctx, q.setAlbumMusicbrainzIDsStmt, setAlbumMusicbrainzIDs,
+		arg.MusicbrainzAlbumID,
+		arg.MusicbrainzReleaseGroupID,
+		arg.ID,
+	)
+	var i Album
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.Musician,
+		&i.SpotifyID,
+		&i.SpotifyPopularity,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.TotalTracks,
+		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateAlbumMetadata = `-- name: UpdateAlbumMetadata :one
+UPDATE albums
+SET
+  title = ?,
+  sort_title = ?,
+  year = ?,
+  scan_title = ?,
+  locked_fields = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+`
+
+type UpdateAlbumMetadataParams struct {
+	Title        string         `json:"title"`
+	SortTitle    string         `json:"sort_title"`
+	Year         sql.NullInt64  `json:"year"`
+	ScanTitle    sql.NullString `json:"scan_title"`
+	LockedFields string         `json:"locked_fields"`
+	ID           int64          `json:"id"`
+}
+
+// Applies a hand edit. The caller passes every editable field and the new lock set.
+func (q *Queries) UpdateAlbumMetadata(ctx context.Context, arg UpdateAlbumMetadataParams) (Album, error) {
+	row := q.queryRow(ctx, q.updateAlbumMetadataStmt, updateAlbumMetadata,
+		arg.Title,
+		arg.SortTitle,
+		arg.Year,
+		arg.ScanTitle,
+		arg.LockedFields,
+		arg.ID,
+	)
+	var i Album
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.Musician,
+		&i.SpotifyID,
+		&i.SpotifyPopularity,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.TotalTracks,
+		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateAlbumSpotifyMatch = `-- name: UpdateAlbumSpotifyMatch :one
+UPDATE albums
+SET
+  spotify_id = ?,
+  spotify_popularity = ?,
+  release_date = ?,
+  year = ?,
+  total_tracks = ?,
+  cover = ?,
+  spotify_match_score = NULL,
+  spotify_match_locked = 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
+`
+
+type UpdateAlbumSpotifyMatchParams struct {
+	SpotifyID         sql.NullString  `json:"spotify_id"`
+	SpotifyPopularity sql.NullFloat64 `json:"spotify_popularity"`
+	ReleaseDate       sql.NullString  `json:"release_date"`
+	Year              sql.NullInt64   `json:"year"`
+	TotalTracks       sql.NullInt64   `json:"total_tracks"`
+	Cover             sql.NullString  `json:"cover"`
+	ID                int64           `json:"id"`
+}
+
+// Matches an album to a Spotify album chosen by hand and locks the match, so scans
+// keep it.
+func (q *Queries) UpdateAlbumSpotifyMatch(ctx context.Context, arg UpdateAlbumSpotifyMatchParams) (Album, error) {
+	row := q.queryRow(ctx, q.updateAlbumSpotifyMatchStmt, updateAlbumSpotifyMatch,
+		arg.SpotifyID,
+		arg.SpotifyPopularity,
+		arg.ReleaseDate,
+		arg.Year,
+		arg.TotalTracks,
+		arg.Cover,
+		arg.ID,
+	)
+	var i Album
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.Musician,
+		&i.SpotifyID,
+		&i.SpotifyPopularity,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.TotalTracks,
+		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const upsertAlbum = `-- name: UpsertAlbum :one
 INSERT INTO
   albums (
@@ -208,37 +708,75 @@ INSERT INTO
     release_date,
     year,
     total_tracks,
-    cover
+    cover,
+    musicbrainz_album_id,
+    musicbrainz_release_group_id,
+    spotify_match_score
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (title, musician) DO
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (title, musician)
+WHERE
+  musicbrainz_album_id IS NULL DO
 UPDATE
 SET
-  sort_title = excluded.sort_title,
-  spotify_id = COALESCE(excluded.spotify_id, albums.spotify_id),
-  spotify_popularity = COALESCE(
-    excluded.spotify_popularity,
-    albums.spotify_popularity
+  sort_title = CASE
+    WHEN 'sort_title' IN (SELECT value FROM json_each(albums.locked_fields)) THEN albums.sort_title
+    ELSE excluded.sort_title
+  END,
+  spotify_id = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.spotify_id
+    ELSE excluded.spotify_id
+  END,
+  spotify_popularity = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.spotify_popularity
+    ELSE excluded.spotify_popularity
+  END,
+  release_date = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.release_date
+    ELSE excluded.release_date
+  END,
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(albums.locked_fields)) THEN albums.year
+    ELSE COALESCE(excluded.year, albums.year)
+  END,
+  total_tracks = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.total_tracks
+    ELSE excluded.total_tracks
+  END,
+  cover = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.cover
+    ELSE excluded.cover
+  END,
+  musicbrainz_release_group_id = COALESCE(
+    excluded.musicbrainz_release_group_id,
+    albums.musicbrainz_release_group_id
+  ),
+  spotify_match_score = COALESCE(
+    excluded.spotify_match_score,
+    albums.spotify_match_score
   ),
-  release_date = COALESCE(excluded.release_date, albums.release_date),
-  year = COALESCE(excluded.year, albums.year),
-  total_tracks = COALESCE(excluded.total_tracks, albums.total_tracks),
-  cover = COALESCE(excluded.cover, albums.cover),
-  updated_at = CURRENT_TIMESTAMP RETURNING id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, created_at, updated_at
+  updated_at = CURRENT_TIMESTAMP RETURNING id, title, sort_title, musician, spotify_id, spotify_popularity, release_date, year, total_tracks, cover, scan_title, locked_fields, is_compilation, directory, musicbrainz_album_id, musicbrainz_release_group_id, spotify_match_score, spotify_match_locked, created_at, updated_at
 `
 
 type UpsertAlbumParams struct {
-	Title             string          `json:"title"`
-	SortTitle         string          `json:"sort_title"`
-	Musician          sql.NullString  `json:"musician"`
-	SpotifyID         sql.NullString  `json:"spotify_id"`
-	SpotifyPopularity sql.NullFloat64 `json:"spotify_popularity"`
-	ReleaseDate       sql.NullString  `json:"release_date"`
-	Year              sql.NullInt64   `json:"year"`
-	TotalTracks       sql.NullInt64   `json:"total_tracks"`
-	Cover             sql.NullString  `json:"cover"`
+	Title                     string          `json:"title"`
+	SortTitle                 string          `json:"sort_title"`
+	Musician                  sql.NullString  `json:"musician"`
+	SpotifyID                 sql.NullString  `json:"spotify_id"`
+	SpotifyPopularity         sql.NullFloat64 `json:"spotify_popularity"`
+	ReleaseDate               sql.NullString  `json:"release_date"`
+	Year                      sql.NullInt64   `json:"year"`
+	TotalTracks               sql.NullInt64   `json:"total_tracks"`
+	Cover                     sql.NullString  `json:"cover"`
+	MusicbrainzAlbumID        sql.NullString  `json:"musicbrainz_album_id"`
+	MusicbrainzReleaseGroupID sql.NullString  `json:"musicbrainz_release_group_id"`
+	SpotifyMatchScore         sql.NullFloat64 `json:"spotify_match_score"`
 }
 
+// Albums with a MusicBrainz release id are never merged by title and album artist, only
+// albums without one conflict (see idx_album_title_musician_unmatched). A scored Spotify
+// lookup replaces the Spotify fields, rejected matches clearing them; without a score
+// they are kept.
 func (q *Queries) UpsertAlbum(ctx context.Context, arg UpsertAlbumParams) (Album, error) {
 	row := q.queryRow(ctx, q.upsertAlbumStmt, upsertAlbum,
 		arg.Title,
@@ -250,6 +788,9 @@ func (q *Queries) UpsertAlbum(ctx context.Context, arg UpsertAlbumParams) (Album
 		arg.Year,
 		arg.TotalTracks,
 		arg.Cover,
+		arg.MusicbrainzAlbumID,
+		arg.MusicbrainzReleaseGroupID,
+		arg.SpotifyMatchScore,
 	)
 	var i Album
 	err := row.Scan(
@@ -263,6 +804,14 @@ func (q *Queries) UpsertAlbum(ctx context.Context, arg UpsertAlbumParams) (Album
 		&i.Year,
 		&i.TotalTracks,
 		&i.Cover,
+		&i.ScanTitle,
+		&i.LockedFields,
+		&i.IsCompilation,
+		&i.Directory,
+		&i.MusicbrainzAlbumID,
+		&i.MusicbrainzReleaseGroupID,
+		&i.SpotifyMatchScore,
+		&i.SpotifyMatchLocked,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -30,24 +30,66 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.addTrackToPlaylistStmt, err = db.PrepareContext(ctx, addTrackToPlaylist); err != nil {
 		return nil, fmt.Errorf("error preparing query AddTrackToPlaylist: %w", err)
 	}
+	if q.backfillLibraryDirsStmt, err = db.PrepareContext(ctx, backfillLibraryDirs); err != nil {
+		return nil, fmt.Errorf("error preparing query BackfillLibraryDirs: %w", err)
+	}
 	if q.canUserEditPlaylistStmt, err = db.PrepareContext(ctx, canUserEditPlaylist); err != nil {
 		return nil, fmt.Errorf("error preparing query CanUserEditPlaylist: %w", err)
 	}
+	if q.checkAudiobookUnchangedStmt, err = db.PrepareContext(ctx, checkAudiobookUnchanged); err != nil {
+		return nil, fmt.Errorf("error preparing query CheckAudiobookUnchanged: %w", err)
+	}
+	if q.checkCueTracksUnchangedStmt, err = db.PrepareContext(ctx, checkCueTracksUnchanged); err != nil {
+		return nil, fmt.Errorf("error preparing query CheckCueTracksUnchanged: %w", err)
+	}
+	if q.checkLocalExtraUnchangedStmt, err = db.PrepareContext(ctx, checkLocalExtraUnchanged); err != nil {
+		return nil, fmt.Errorf("error preparing query CheckLocalExtraUnchanged: %w", err)
+	}
+	if q.checkLyricsUnchangedStmt, err = db.PrepareContext(ctx, checkLyricsUnchanged); err != nil {
+		return nil, fmt.Errorf("error preparing query CheckLyricsUnchanged: %w", err)
+	}
 	if q.checkMovieUnchangedStmt, err = db.PrepareContext(ctx, checkMovieUnchanged); err != nil {
 		return nil, fmt.Errorf("error preparing query CheckMovieUnchanged: %w", err)
 	}
+	if q.checkSampleFileUnchangedStmt, err = db.PrepareContext(ctx, checkSampleFileUnchanged); err != nil {
+		return nil, fmt.Errorf("error preparing query CheckSampleFileUnchanged: %w", err)
+	}
 	if q.checkTrackUnchangedStmt, err = db.PrepareContext(ctx, checkTrackUnchanged); err != nil {
 		return nil, fmt.Errorf("error preparing query CheckTrackUnchanged: %w", err)
 	}
 	if q.clearPlaylistStmt, err = db.PrepareContext(ctx, clearPlaylist); err != nil {
 		return nil, fmt.Errorf("error preparing query ClearPlaylist: %w", err)
 	}
+	if q.clearPodcastEpisodeFileStmt, err = db.PrepareContext(ctx, clearPodcastEpisodeFile); err != nil {
+		return nil, fmt.Errorf("error preparing query ClearPodcastEpisodeFile: %w", err)
+	}
+	if q.countLastfmScrobblesStmt, err = db.PrepareContext(ctx, countLastfmScrobbles); err != nil {
+		return nil, fmt.Errorf("error preparing query CountLastfmScrobbles: %w", err)
+	}
+	if q.countListenbrainzListensStmt, err = db.PrepareContext(ctx, countListenbrainzListens); err != nil {
+		return nil, fmt.Errorf("error preparing query CountListenbrainzListens: %w", err)
+	}
 	if q.countPlaylistTracksStmt, err = db.PrepareContext(ctx, countPlaylistTracks); err != nil {
 		return nil, fmt.Errorf("error preparing query CountPlaylistTracks: %w", err)
 	}
 	if q.countPlaylistsByUserIdStmt, err = db.PrepareContext(ctx, countPlaylistsByUserId); err != nil {
 		return nil, fmt.Errorf("error preparing query CountPlaylistsByUserId: %w", err)
 	}
+	if q.createAudiobookBookmarkStmt, err = db.PrepareContext(ctx, createAudiobookBookmark); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAudiobookBookmark: %w", err)
+	}
+	if q.createAudiobookChapterStmt, err = db.PrepareContext(ctx, createAudiobookChapter); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAudiobookChapter: %w", err)
+	}
+	if q.createCollectionPartStmt, err = db.PrepareContext(ctx, createCollectionPart); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCollectionPart: %w", err)
+	}
+	if q.createLastfmScrobbleStmt, err = db.PrepareContext(ctx, createLastfmScrobble); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateLastfmScrobble: %w", err)
+	}
+	if q.createListenbrainzListenStmt, err = db.PrepareContext(ctx, createListenbrainzListen); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateListenbrainzListen: %w", err)
+	}
 	if q.createMovieExtraVideoStmt, err = db.PrepareContext(ctx, createMovieExtraVideo); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateMovieExtraVideo: %w", err)
 	}
@@ -63,6 +105,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createPlaylistStmt, err = db.PrepareContext(ctx, createPlaylist); err != nil {
 		return nil, fmt.Errorf("error preparing query CreatePlaylist: %w", err)
 	}
+	if q.createPodcastStmt, err = db.PrepareContext(ctx, createPodcast); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePodcast: %w", err)
+	}
 	if q.createSettingsStmt, err = db.PrepareContext(ctx, createSettings); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateSettings: %w", err)
 	}
@@ -75,45 +120,144 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.deleteAlbumStmt, err = db.PrepareContext(ctx, deleteAlbum); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAlbum: %w", err)
 	}
-	if q.deleteMovieAudioStreamsStmt, err = db.PrepareContext(ctx, deleteMovieAudioStreams); err != nil {
-		return nil, fmt.Errorf("error preparing query DeleteMovieAudioStreams: %w", err)
+	if q.deleteAlbumGenresStmt, err = db.PrepareContext(ctx, deleteAlbumGenres); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAlbumGenres: %w", err)
+	}
+	if q.deleteAudiobookStmt, err = db.PrepareContext(ctx, deleteAudiobook); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAudiobook: %w", err)
+	}
+	if q.deleteAudiobookBookmarkStmt, err = db.PrepareContext(ctx, deleteAudiobookBookmark); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAudiobookBookmark: %w", err)
+	}
+	if q.deleteAudiobookChaptersStmt, err = db.PrepareContext(ctx, deleteAudiobookChapters); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAudiobookChapters: %w", err)
+	}
+	if q.deleteCastMemberStmt, err = db.PrepareContext(ctx, deleteCastMember); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCastMember: %w", err)
+	}
+	if q.deleteCollectionPartsStmt, err = db.PrepareContext(ctx, deleteCollectionParts); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCollectionParts: %w", err)
+	}
+	if q.deleteCrewMemberStmt, err = db.PrepareContext(ctx, deleteCrewMember); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCrewMember: %w", err)
+	}
+	if q.deleteCueTracksStmt, err = db.PrepareContext(ctx, deleteCueTracks); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCueTracks: %w", err)
+	}
+	if q.deleteExpiredApiCacheEntriesStmt, err = db.PrepareContext(ctx, deleteExpiredApiCacheEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredApiCacheEntries: %w", err)
+	}
+	if q.deleteExpiredLastfmScrobblesStmt, err = db.PrepareContext(ctx, deleteExpiredLastfmScrobbles); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredLastfmScrobbles: %w", err)
+	}
+	if q.deleteGenreStmt, err = db.PrepareContext(ctx, deleteGenre); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteGenre: %w", err)
+	}
+	if q.deleteLastfmScrobbleStmt, err = db.PrepareContext(ctx, deleteLastfmScrobble); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteLastfmScrobble: %w", err)
+	}
+	if q.deleteLastfmScrobblesByUserStmt, err = db.PrepareContext(ctx, deleteLastfmScrobblesByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteLastfmScrobblesByUser: %w", err)
+	}
+	if q.deleteLastfmSessionStmt, err = db.PrepareContext(ctx, deleteLastfmSession); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteLastfmSession: %w", err)
+	}
+	if q.deleteListenbrainzAccountStmt, err = db.PrepareContext(ctx, deleteListenbrainzAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteListenbrainzAccount: %w", err)
+	}
+	if q.deleteListenbrainzListenStmt, err = db.PrepareContext(ctx, deleteListenbrainzListen); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteListenbrainzListen: %w", err)
+	}
+	if q.deleteListenbrainzListensByUserStmt, err = db.PrepareContext(ctx, deleteListenbrainzListensByUser); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteListenbrainzListensByUser: %w", err)
+	}
+	if q.deleteMediaVersionAudioStreamsStmt, err = db.PrepareContext(ctx, deleteMediaVersionAudioStreams); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMediaVersionAudioStreams: %w", err)
 	}
-	if q.deleteMovieChaptersStmt, err = db.PrepareContext(ctx, deleteMovieChapters); err != nil {
-		return nil, fmt.Errorf("error preparing query DeleteMovieChapters: %w", err)
+	if q.deleteMediaVersionByFilePathStmt, err = db.PrepareContext(ctx, deleteMediaVersionByFilePath); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMediaVersionByFilePath: %w", err)
+	}
+	if q.deleteMediaVersionChaptersStmt, err = db.PrepareContext(ctx, deleteMediaVersionChapters); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMediaVersionChapters: %w", err)
+	}
+	if q.deleteMediaVersionSubtitlesStmt, err = db.PrepareContext(ctx, deleteMediaVersionSubtitles); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMediaVersionSubtitles: %w", err)
+	}
+	if q.deleteMediaVersionVideoStreamsStmt, err = db.PrepareContext(ctx, deleteMediaVersionVideoStreams); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMediaVersionVideoStreams: %w", err)
+	}
+	if q.deleteMovieStmt, err = db.PrepareContext(ctx, deleteMovie); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMovie: %w", err)
+	}
+	if q.deleteMovieCollectionStmt, err = db.PrepareContext(ctx, deleteMovieCollection); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMovieCollection: %w", err)
 	}
 	if q.deleteMovieExtraVideosStmt, err = db.PrepareContext(ctx, deleteMovieExtraVideos); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMovieExtraVideos: %w", err)
 	}
+	if q.deleteMovieGenreStmt, err = db.PrepareContext(ctx, deleteMovieGenre); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMovieGenre: %w", err)
+	}
 	if q.deleteMovieGenresStmt, err = db.PrepareContext(ctx, deleteMovieGenres); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMovieGenres: %w", err)
 	}
 	if q.deleteMovieProductionCompaniesStmt, err = db.PrepareContext(ctx, deleteMovieProductionCompanies); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteMovieProductionCompanies: %w", err)
 	}
-	if q.deleteMovieSubtitlesStmt, err = db.PrepareContext(ctx, deleteMovieSubtitles); err != nil {
-		return nil, fmt.Errorf("error preparing query DeleteMovieSubtitles: %w", err)
+	if q.deleteMovieProductionCompanyStmt, err = db.PrepareContext(ctx, deleteMovieProductionCompany); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMovieProductionCompany: %w", err)
 	}
-	if q.deleteMovieVideoStreamsStmt, err = db.PrepareContext(ctx, deleteMovieVideoStreams); err != nil {
-		return nil, fmt.Errorf("error preparing query DeleteMovieVideoStreams: %w", err)
+	if q.deleteMusicianGenresStmt, err = db.PrepareContext(ctx, deleteMusicianGenres); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMusicianGenres: %w", err)
 	}
 	if q.deletePlaylistStmt, err = db.PrepareContext(ctx, deletePlaylist); err != nil {
 		return nil, fmt.Errorf("error preparing query DeletePlaylist: %w", err)
 	}
+	if q.deletePodcastStmt, err = db.PrepareContext(ctx, deletePodcast); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePodcast: %w", err)
+	}
+	if q.deleteSampleFilesByLibraryStmt, err = db.PrepareContext(ctx, deleteSampleFilesByLibrary); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSampleFilesByLibrary: %w", err)
+	}
+	if q.deleteScanErrorStmt, err = db.PrepareContext(ctx, deleteScanError); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteScanError: %w", err)
+	}
+	if q.deleteScannedLyricsStmt, err = db.PrepareContext(ctx, deleteScannedLyrics); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteScannedLyrics: %w", err)
+	}
+	if q.deleteStaleAudiobookFilesStmt, err = db.PrepareContext(ctx, deleteStaleAudiobookFiles); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteStaleAudiobookFiles: %w", err)
+	}
+	if q.deleteStaleCueTracksStmt, err = db.PrepareContext(ctx, deleteStaleCueTracks); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteStaleCueTracks: %w", err)
+	}
+	if q.deleteTrackByFilePathStmt, err = db.PrepareContext(ctx, deleteTrackByFilePath); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTrackByFilePath: %w", err)
+	}
 	if q.deleteTrackGenresStmt, err = db.PrepareContext(ctx, deleteTrackGenres); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteTrackGenres: %w", err)
 	}
-	if q.deleteTrackGenresExceptStmt, err = db.PrepareContext(ctx, deleteTrackGenresExcept); err != nil {
-		return nil, fmt.Errorf("error preparing query DeleteTrackGenresExcept: %w", err)
-	}
 	if q.deleteUserStmt, err = db.PrepareContext(ctx, deleteUser); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteUser: %w", err)
 	}
+	if q.fillMovieMetadataStmt, err = db.PrepareContext(ctx, fillMovieMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query FillMovieMetadata: %w", err)
+	}
 	if q.getAdminUserStmt, err = db.PrepareContext(ctx, getAdminUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAdminUser: %w", err)
 	}
+	if q.getAlbumByDirectoryStmt, err = db.PrepareContext(ctx, getAlbumByDirectory); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAlbumByDirectory: %w", err)
+	}
 	if q.getAlbumByIDStmt, err = db.PrepareContext(ctx, getAlbumByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAlbumByID: %w", err)
 	}
+	if q.getAlbumByMusicbrainzIDStmt, err = db.PrepareContext(ctx, getAlbumByMusicbrainzID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAlbumByMusicbrainzID: %w", err)
+	}
+	if q.getAlbumByScanTitleStmt, err = db.PrepareContext(ctx, getAlbumByScanTitle); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAlbumByScanTitle: %w", err)
+	}
 	if q.getAlbumBySpotifyIDStmt, err = db.PrepareContext(ctx, getAlbumBySpotifyID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAlbumBySpotifyID: %w", err)
 	}
@@ -123,6 +267,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getAlbumsByMusicianIDStmt, err = db.PrepareContext(ctx, getAlbumsByMusicianID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAlbumsByMusicianID: %w", err)
 	}
+	if q.getAlbumsByTitleStmt, err = db.PrepareContext(ctx, getAlbumsByTitle); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAlbumsByTitle: %w", err)
+	}
 	if q.getAlbumsCountStmt, err = db.PrepareContext(ctx, getAlbumsCount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAlbumsCount: %w", err)
 	}
@@ -132,12 +279,108 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getAllTrackPathsAndSizesStmt, err = db.PrepareContext(ctx, getAllTrackPathsAndSizes); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAllTrackPathsAndSizes: %w", err)
 	}
+	if q.getAllTrackScrobbleInfoStmt, err = db.PrepareContext(ctx, getAllTrackScrobbleInfo); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAllTrackScrobbleInfo: %w", err)
+	}
+	if q.getApiCacheEntryStmt, err = db.PrepareContext(ctx, getApiCacheEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query GetApiCacheEntry: %w", err)
+	}
+	if q.getArtistByIDStmt, err = db.PrepareContext(ctx, getArtistByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetArtistByID: %w", err)
+	}
+	if q.getAudioStreamsByMediaVersionIDStmt, err = db.PrepareContext(ctx, getAudioStreamsByMediaVersionID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudioStreamsByMediaVersionID: %w", err)
+	}
+	if q.getAudiobookBookmarksStmt, err = db.PrepareContext(ctx, getAudiobookBookmarks); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobookBookmarks: %w", err)
+	}
+	if q.getAudiobookByIDStmt, err = db.PrepareContext(ctx, getAudiobookByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobookByID: %w", err)
+	}
+	if q.getAudiobookChapterStmt, err = db.PrepareContext(ctx, getAudiobookChapter); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobookChapter: %w", err)
+	}
+	if q.getAudiobookChaptersStmt, err = db.PrepareContext(ctx, getAudiobookChapters); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobookChapters: %w", err)
+	}
+	if q.getAudiobookFileStmt, err = db.PrepareContext(ctx, getAudiobookFile); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobookFile: %w", err)
+	}
+	if q.getAudiobookFilesStmt, err = db.PrepareContext(ctx, getAudiobookFiles); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobookFiles: %w", err)
+	}
+	if q.getAudiobookProgressStmt, err = db.PrepareContext(ctx, getAudiobookProgress); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobookProgress: %w", err)
+	}
+	if q.getAudiobooksAlphabeticalStmt, err = db.PrepareContext(ctx, getAudiobooksAlphabetical); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobooksAlphabetical: %w", err)
+	}
+	if q.getAudiobooksByAuthorStmt, err = db.PrepareContext(ctx, getAudiobooksByAuthor); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobooksByAuthor: %w", err)
+	}
+	if q.getAudiobooksByDirectoryStmt, err = db.PrepareContext(ctx, getAudiobooksByDirectory); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobooksByDirectory: %w", err)
+	}
+	if q.getAudiobooksCountStmt, err = db.PrepareContext(ctx, getAudiobooksCount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobooksCount: %w", err)
+	}
+	if q.getAudiobooksInProgressStmt, err = db.PrepareContext(ctx, getAudiobooksInProgress); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAudiobooksInProgress: %w", err)
+	}
+	if q.getAuthorByIDStmt, err = db.PrepareContext(ctx, getAuthorByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAuthorByID: %w", err)
+	}
+	if q.getAuthorsAlphabeticalStmt, err = db.PrepareContext(ctx, getAuthorsAlphabetical); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAuthorsAlphabetical: %w", err)
+	}
+	if q.getAuthorsCountStmt, err = db.PrepareContext(ctx, getAuthorsCount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAuthorsCount: %w", err)
+	}
+	if q.getCastByArtistIDStmt, err = db.PrepareContext(ctx, getCastByArtistID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCastByArtistID: %w", err)
+	}
 	if q.getCastByMovieIDStmt, err = db.PrepareContext(ctx, getCastByMovieID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetCastByMovieID: %w", err)
 	}
+	if q.getCollectionByIDStmt, err = db.PrepareContext(ctx, getCollectionByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCollectionByID: %w", err)
+	}
+	if q.getCollectionMoviesStmt, err = db.PrepareContext(ctx, getCollectionMovies); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCollectionMovies: %w", err)
+	}
+	if q.getCollectionsStmt, err = db.PrepareContext(ctx, getCollections); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCollections: %w", err)
+	}
+	if q.getCollectionsCountStmt, err = db.PrepareContext(ctx, getCollectionsCount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCollectionsCount: %w", err)
+	}
+	if q.getCrewByArtistIDStmt, err = db.PrepareContext(ctx, getCrewByArtistID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCrewByArtistID: %w", err)
+	}
 	if q.getCrewByMovieIDStmt, err = db.PrepareContext(ctx, getCrewByMovieID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetCrewByMovieID: %w", err)
 	}
+	if q.getDownloadedPodcastEpisodesStmt, err = db.PrepareContext(ctx, getDownloadedPodcastEpisodes); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDownloadedPodcastEpisodes: %w", err)
+	}
+	if q.getDueLastfmScrobblesStmt, err = db.PrepareContext(ctx, getDueLastfmScrobbles); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDueLastfmScrobbles: %w", err)
+	}
+	if q.getDueListenbrainzListensStmt, err = db.PrepareContext(ctx, getDueListenbrainzListens); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDueListenbrainzListens: %w", err)
+	}
+	if q.getFilteredAlbumsCountStmt, err = db.PrepareContext(ctx, getFilteredAlbumsCount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFilteredAlbumsCount: %w", err)
+	}
+	if q.getGenreAliasesByTypeStmt, err = db.PrepareContext(ctx, getGenreAliasesByType); err != nil {
+		return nil, fmt.Errorf("error preparing query GetGenreAliasesByType: %w", err)
+	}
+	if q.getGenreByAliasStmt, err = db.PrepareContext(ctx, getGenreByAlias); err != nil {
+		return nil, fmt.Errorf("error preparing query GetGenreByAlias: %w", err)
+	}
+	if q.getGenreByIDStmt, err = db.PrepareContext(ctx, getGenreByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetGenreByID: %w", err)
+	}
 	if q.getGenresByAlbumIDStmt, err = db.PrepareContext(ctx, getGenresByAlbumID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetGenresByAlbumID: %w", err)
 	}
@@ -150,6 +393,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getGenresByMusicianIDStmt, err = db.PrepareContext(ctx, getGenresByMusicianID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetGenresByMusicianID: %w", err)
 	}
+	if q.getGenresWithCountsStmt, err = db.PrepareContext(ctx, getGenresWithCounts); err != nil {
+		return nil, fmt.Errorf("error preparing query GetGenresWithCounts: %w", err)
+	}
+	if q.getLastfmSessionStmt, err = db.PrepareContext(ctx, getLastfmSession); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastfmSession: %w", err)
+	}
 	if q.getLatestAlbumsStmt, err = db.PrepareContext(ctx, getLatestAlbums); err != nil {
 		return nil, fmt.Errorf("error preparing query GetLatestAlbums: %w", err)
 	}
@@ -162,24 +411,60 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getLikedTracksByUserIDStmt, err = db.PrepareContext(ctx, getLikedTracksByUserID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetLikedTracksByUserID: %w", err)
 	}
+	if q.getListenbrainzAccountStmt, err = db.PrepareContext(ctx, getListenbrainzAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetListenbrainzAccount: %w", err)
+	}
+	if q.getLocalExtraByIDStmt, err = db.PrepareContext(ctx, getLocalExtraByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLocalExtraByID: %w", err)
+	}
+	if q.getLocalExtrasByMovieIDStmt, err = db.PrepareContext(ctx, getLocalExtrasByMovieID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLocalExtrasByMovieID: %w", err)
+	}
 	if q.getMaxPositionStmt, err = db.PrepareContext(ctx, getMaxPosition); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMaxPosition: %w", err)
 	}
+	if q.getMediaVersionByFilePathStmt, err = db.PrepareContext(ctx, getMediaVersionByFilePath); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMediaVersionByFilePath: %w", err)
+	}
+	if q.getMediaVersionByIDStmt, err = db.PrepareContext(ctx, getMediaVersionByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMediaVersionByID: %w", err)
+	}
+	if q.getMediaVersionsByDirectoryStmt, err = db.PrepareContext(ctx, getMediaVersionsByDirectory); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMediaVersionsByDirectory: %w", err)
+	}
+	if q.getMediaVersionsByMovieIDStmt, err = db.PrepareContext(ctx, getMediaVersionsByMovieID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMediaVersionsByMovieID: %w", err)
+	}
+	if q.getMissingCollectionPartsStmt, err = db.PrepareContext(ctx, getMissingCollectionParts); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMissingCollectionParts: %w", err)
+	}
 	if q.getMovieByFilePathStmt, err = db.PrepareContext(ctx, getMovieByFilePath); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMovieByFilePath: %w", err)
 	}
 	if q.getMovieByIDStmt, err = db.PrepareContext(ctx, getMovieByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMovieByID: %w", err)
 	}
+	if q.getMovieByTitleAndYearStmt, err = db.PrepareContext(ctx, getMovieByTitleAndYear); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMovieByTitleAndYear: %w", err)
+	}
 	if q.getMovieByTmdbIDStmt, err = db.PrepareContext(ctx, getMovieByTmdbID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMovieByTmdbID: %w", err)
 	}
 	if q.getMovieExtraVideosStmt, err = db.PrepareContext(ctx, getMovieExtraVideos); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMovieExtraVideos: %w", err)
 	}
+	if q.getMoviesWithoutCollectionStmt, err = db.PrepareContext(ctx, getMoviesWithoutCollection); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMoviesWithoutCollection: %w", err)
+	}
 	if q.getMusicianByIDStmt, err = db.PrepareContext(ctx, getMusicianByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMusicianByID: %w", err)
 	}
+	if q.getMusicianByMusicbrainzIDStmt, err = db.PrepareContext(ctx, getMusicianByMusicbrainzID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMusicianByMusicbrainzID: %w", err)
+	}
+	if q.getMusicianByScanNameStmt, err = db.PrepareContext(ctx, getMusicianByScanName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMusicianByScanName: %w", err)
+	}
 	if q.getMusicianBySpotifyIDStmt, err = db.PrepareContext(ctx, getMusicianBySpotifyID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMusicianBySpotifyID: %w", err)
 	}
@@ -189,6 +474,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getMusiciansByAlbumIDStmt, err = db.PrepareContext(ctx, getMusiciansByAlbumID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMusiciansByAlbumID: %w", err)
 	}
+	if q.getMusiciansByNameStmt, err = db.PrepareContext(ctx, getMusiciansByName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMusiciansByName: %w", err)
+	}
 	if q.getMusiciansCountStmt, err = db.PrepareContext(ctx, getMusiciansCount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetMusiciansCount: %w", err)
 	}
@@ -213,18 +501,66 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getPlaylistsWithCollaboratorAccessStmt, err = db.PrepareContext(ctx, getPlaylistsWithCollaboratorAccess); err != nil {
 		return nil, fmt.Errorf("error preparing query GetPlaylistsWithCollaboratorAccess: %w", err)
 	}
+	if q.getPodcastByFeedURLStmt, err = db.PrepareContext(ctx, getPodcastByFeedURL); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcastByFeedURL: %w", err)
+	}
+	if q.getPodcastByIDStmt, err = db.PrepareContext(ctx, getPodcastByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcastByID: %w", err)
+	}
+	if q.getPodcastEpisodeByIDStmt, err = db.PrepareContext(ctx, getPodcastEpisodeByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcastEpisodeByID: %w", err)
+	}
+	if q.getPodcastEpisodesStmt, err = db.PrepareContext(ctx, getPodcastEpisodes); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcastEpisodes: %w", err)
+	}
+	if q.getPodcastEpisodesCountStmt, err = db.PrepareContext(ctx, getPodcastEpisodesCount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcastEpisodesCount: %w", err)
+	}
+	if q.getPodcastEpisodesToDownloadStmt, err = db.PrepareContext(ctx, getPodcastEpisodesToDownload); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcastEpisodesToDownload: %w", err)
+	}
+	if q.getPodcastsStmt, err = db.PrepareContext(ctx, getPodcasts); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcasts: %w", err)
+	}
+	if q.getPodcastsDueForPollStmt, err = db.PrepareContext(ctx, getPodcastsDueForPoll); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPodcastsDueForPoll: %w", err)
+	}
 	if q.getProductionCompaniesByMovieIDStmt, err = db.PrepareContext(ctx, getProductionCompaniesByMovieID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProductionCompaniesByMovieID: %w", err)
 	}
 	if q.getRandomTracksStmt, err = db.PrepareContext(ctx, getRandomTracks); err != nil {
 		return nil, fmt.Errorf("error preparing query GetRandomTracks: %w", err)
 	}
+	if q.getScanErrorByIDStmt, err = db.PrepareContext(ctx, getScanErrorByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetScanErrorByID: %w", err)
+	}
+	if q.getScanErrorsStmt, err = db.PrepareContext(ctx, getScanErrors); err != nil {
+		return nil, fmt.Errorf("error preparing query GetScanErrors: %w", err)
+	}
+	if q.getScanErrorsCountStmt, err = db.PrepareContext(ctx, getScanErrorsCount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetScanErrorsCount: %w", err)
+	}
 	if q.getSettingsStmt, err = db.PrepareContext(ctx, getSettings); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSettings: %w", err)
 	}
+	if q.getStaleMoviesStmt, err = db.PrepareContext(ctx, getStaleMovies); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStaleMovies: %w", err)
+	}
+	if q.getStaleMusiciansStmt, err = db.PrepareContext(ctx, getStaleMusicians); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStaleMusicians: %w", err)
+	}
+	if q.getSubtitlesByMediaVersionIDStmt, err = db.PrepareContext(ctx, getSubtitlesByMediaVersionID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSubtitlesByMediaVersionID: %w", err)
+	}
 	if q.getTrackStmt, err = db.PrepareContext(ctx, getTrack); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTrack: %w", err)
 	}
+	if q.getTrackLyricsStmt, err = db.PrepareContext(ctx, getTrackLyrics); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTrackLyrics: %w", err)
+	}
+	if q.getTrackScrobbleInfoStmt, err = db.PrepareContext(ctx, getTrackScrobbleInfo); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTrackScrobbleInfo: %w", err)
+	}
 	if q.getTracksAlphabeticalStmt, err = db.PrepareContext(ctx, getTracksAlphabetical); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTracksAlphabetical: %w", err)
 	}
@@ -237,6 +573,15 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getTracksCountStmt, err = db.PrepareContext(ctx, getTracksCount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTracksCount: %w", err)
 	}
+	if q.getUnaliasedGenresStmt, err = db.PrepareContext(ctx, getUnaliasedGenres); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUnaliasedGenres: %w", err)
+	}
+	if q.getUnmatchedAlbumStmt, err = db.PrepareContext(ctx, getUnmatchedAlbum); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUnmatchedAlbum: %w", err)
+	}
+	if q.getUnmatchedMusicianByNameStmt, err = db.PrepareContext(ctx, getUnmatchedMusicianByName); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUnmatchedMusicianByName: %w", err)
+	}
 	if q.getUserStmt, err = db.PrepareContext(ctx, getUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUser: %w", err)
 	}
@@ -249,6 +594,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getUserListeningStatsStmt, err = db.PrepareContext(ctx, getUserListeningStats); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserListeningStats: %w", err)
 	}
+	if q.getUserPlayTimesStmt, err = db.PrepareContext(ctx, getUserPlayTimes); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserPlayTimes: %w", err)
+	}
 	if q.getUserRecentlyPlayedStmt, err = db.PrepareContext(ctx, getUserRecentlyPlayed); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserRecentlyPlayed: %w", err)
 	}
@@ -267,6 +615,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getUserTrackPlayCountStmt, err = db.PrepareContext(ctx, getUserTrackPlayCount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserTrackPlayCount: %w", err)
 	}
+	if q.getVideoStreamsByMediaVersionIDStmt, err = db.PrepareContext(ctx, getVideoStreamsByMediaVersionID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVideoStreamsByMediaVersionID: %w", err)
+	}
+	if q.importPlayEventStmt, err = db.PrepareContext(ctx, importPlayEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query ImportPlayEvent: %w", err)
+	}
 	if q.insertAudioStreamStmt, err = db.PrepareContext(ctx, insertAudioStream); err != nil {
 		return nil, fmt.Errorf("error preparing query InsertAudioStream: %w", err)
 	}
@@ -291,33 +645,168 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.likeTrackStmt, err = db.PrepareContext(ctx, likeTrack); err != nil {
 		return nil, fmt.Errorf("error preparing query LikeTrack: %w", err)
 	}
+	if q.markAlbumCompilationStmt, err = db.PrepareContext(ctx, markAlbumCompilation); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkAlbumCompilation: %w", err)
+	}
+	if q.mergeAlbumGenresStmt, err = db.PrepareContext(ctx, mergeAlbumGenres); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeAlbumGenres: %w", err)
+	}
+	if q.mergeAudioStreamsStmt, err = db.PrepareContext(ctx, mergeAudioStreams); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeAudioStreams: %w", err)
+	}
+	if q.mergeChaptersStmt, err = db.PrepareContext(ctx, mergeChapters); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeChapters: %w", err)
+	}
+	if q.mergeGenreAliasesStmt, err = db.PrepareContext(ctx, mergeGenreAliases); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeGenreAliases: %w", err)
+	}
+	if q.mergeLocalExtrasStmt, err = db.PrepareContext(ctx, mergeLocalExtras); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeLocalExtras: %w", err)
+	}
+	if q.mergeMediaVersionsStmt, err = db.PrepareContext(ctx, mergeMediaVersions); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeMediaVersions: %w", err)
+	}
+	if q.mergeMovieGenresStmt, err = db.PrepareContext(ctx, mergeMovieGenres); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeMovieGenres: %w", err)
+	}
+	if q.mergeMusicianGenresStmt, err = db.PrepareContext(ctx, mergeMusicianGenres); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeMusicianGenres: %w", err)
+	}
+	if q.mergeSubtitlesStmt, err = db.PrepareContext(ctx, mergeSubtitles); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeSubtitles: %w", err)
+	}
+	if q.mergeTrackGenresStmt, err = db.PrepareContext(ctx, mergeTrackGenres); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeTrackGenres: %w", err)
+	}
+	if q.mergeVideoStreamsStmt, err = db.PrepareContext(ctx, mergeVideoStreams); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeVideoStreams: %w", err)
+	}
+	if q.rebuildUserTrackStatsStmt, err = db.PrepareContext(ctx, rebuildUserTrackStats); err != nil {
+		return nil, fmt.Errorf("error preparing query RebuildUserTrackStats: %w", err)
+	}
 	if q.recordPlayEventStmt, err = db.PrepareContext(ctx, recordPlayEvent); err != nil {
 		return nil, fmt.Errorf("error preparing query RecordPlayEvent: %w", err)
 	}
+	if q.recordSampleFileStmt, err = db.PrepareContext(ctx, recordSampleFile); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordSampleFile: %w", err)
+	}
+	if q.recordScanErrorStmt, err = db.PrepareContext(ctx, recordScanError); err != nil {
+		return nil, fmt.Errorf("error preparing query RecordScanError: %w", err)
+	}
+	if q.refreshMovieMetadataStmt, err = db.PrepareContext(ctx, refreshMovieMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query RefreshMovieMetadata: %w", err)
+	}
+	if q.refreshMusicianMetadataStmt, err = db.PrepareContext(ctx, refreshMusicianMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query RefreshMusicianMetadata: %w", err)
+	}
 	if q.removeCollaboratorStmt, err = db.PrepareContext(ctx, removeCollaborator); err != nil {
 		return nil, fmt.Errorf("error preparing query RemoveCollaborator: %w", err)
 	}
 	if q.removeTrackFromPlaylistStmt, err = db.PrepareContext(ctx, removeTrackFromPlaylist); err != nil {
 		return nil, fmt.Errorf("error preparing query RemoveTrackFromPlaylist: %w", err)
 	}
+	if q.retryLastfmScrobbleStmt, err = db.PrepareContext(ctx, retryLastfmScrobble); err != nil {
+		return nil, fmt.Errorf("error preparing query RetryLastfmScrobble: %w", err)
+	}
+	if q.retryListenbrainzListenStmt, err = db.PrepareContext(ctx, retryListenbrainzListen); err != nil {
+		return nil, fmt.Errorf("error preparing query RetryListenbrainzListen: %w", err)
+	}
+	if q.searchArtistsStmt, err = db.PrepareContext(ctx, searchArtists); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchArtists: %w", err)
+	}
+	if q.searchArtistsCountStmt, err = db.PrepareContext(ctx, searchArtistsCount); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchArtistsCount: %w", err)
+	}
+	if q.setAlbumDirectoryStmt, err = db.PrepareContext(ctx, setAlbumDirectory); err != nil {
+		return nil, fmt.Errorf("error preparing query SetAlbumDirectory: %w", err)
+	}
+	if q.setAlbumMusicbrainzIDsStmt, err = db.PrepareContext(ctx, setAlbumMusicbrainzIDs); err != nil {
+		return nil, fmt.Errorf("error preparing query SetAlbumMusicbrainzIDs: %w", err)
+	}
+	if q.setCollectionsBackfilledStmt, err = db.PrepareContext(ctx, setCollectionsBackfilled); err != nil {
+		return nil, fmt.Errorf("error preparing query SetCollectionsBackfilled: %w", err)
+	}
+	if q.setMovieCollectionStmt, err = db.PrepareContext(ctx, setMovieCollection); err != nil {
+		return nil, fmt.Errorf("error preparing query SetMovieCollection: %w", err)
+	}
+	if q.setMusicianMusicbrainzIDStmt, err = db.PrepareContext(ctx, setMusicianMusicbrainzID); err != nil {
+		return nil, fmt.Errorf("error preparing query SetMusicianMusicbrainzID: %w", err)
+	}
+	if q.setPodcastEpisodeFileStmt, err = db.PrepareContext(ctx, setPodcastEpisodeFile); err != nil {
+		return nil, fmt.Errorf("error preparing query SetPodcastEpisodeFile: %w", err)
+	}
+	if q.setTrackLyricsScannedStmt, err = db.PrepareContext(ctx, setTrackLyricsScanned); err != nil {
+		return nil, fmt.Errorf("error preparing query SetTrackLyricsScanned: %w", err)
+	}
 	if q.shiftPositionsDownStmt, err = db.PrepareContext(ctx, shiftPositionsDown); err != nil {
 		return nil, fmt.Errorf("error preparing query ShiftPositionsDown: %w", err)
 	}
 	if q.shiftPositionsUpStmt, err = db.PrepareContext(ctx, shiftPositionsUp); err != nil {
 		return nil, fmt.Errorf("error preparing query ShiftPositionsUp: %w", err)
 	}
+	if q.touchMovieMetadataRefreshedStmt, err = db.PrepareContext(ctx, touchMovieMetadataRefreshed); err != nil {
+		return nil, fmt.Errorf("error preparing query TouchMovieMetadataRefreshed: %w", err)
+	}
+	if q.touchMusicianMetadataRefreshedStmt, err = db.PrepareContext(ctx, touchMusicianMetadataRefreshed); err != nil {
+		return nil, fmt.Errorf("error preparing query TouchMusicianMetadataRefreshed: %w", err)
+	}
 	if q.unlikeTrackStmt, err = db.PrepareContext(ctx, unlikeTrack); err != nil {
 		return nil, fmt.Errorf("error preparing query UnlikeTrack: %w", err)
 	}
+	if q.updateAlbumMetadataStmt, err = db.PrepareContext(ctx, updateAlbumMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateAlbumMetadata: %w", err)
+	}
+	if q.updateAlbumSpotifyMatchStmt, err = db.PrepareContext(ctx, updateAlbumSpotifyMatch); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateAlbumSpotifyMatch: %w", err)
+	}
 	if q.updateCollaboratorPermissionStmt, err = db.PrepareContext(ctx, updateCollaboratorPermission); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateCollaboratorPermission: %w", err)
 	}
+	if q.updateListenbrainzImportStmt, err = db.PrepareContext(ctx, updateListenbrainzImport); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateListenbrainzImport: %w", err)
+	}
+	if q.updateMetadataLanguageSettingsStmt, err = db.PrepareContext(ctx, updateMetadataLanguageSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMetadataLanguageSettings: %w", err)
+	}
+	if q.updateMetadataRefreshSettingsStmt, err = db.PrepareContext(ctx, updateMetadataRefreshSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMetadataRefreshSettings: %w", err)
+	}
+	if q.updateMovieFileStmt, err = db.PrepareContext(ctx, updateMovieFile); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMovieFile: %w", err)
+	}
+	if q.updateMovieMatchStmt, err = db.PrepareContext(ctx, updateMovieMatch); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMovieMatch: %w", err)
+	}
+	if q.updateMovieMetadataStmt, err = db.PrepareContext(ctx, updateMovieMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMovieMetadata: %w", err)
+	}
+	if q.updateMusicianMetadataStmt, err = db.PrepareContext(ctx, updateMusicianMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateMusicianMetadata: %w", err)
+	}
 	if q.updatePlaylistStmt, err = db.PrepareContext(ctx, updatePlaylist); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdatePlaylist: %w", err)
 	}
 	if q.updatePlaylistTimestampStmt, err = db.PrepareContext(ctx, updatePlaylistTimestamp); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdatePlaylistTimestamp: %w", err)
 	}
+	if q.updatePodcastDownloadSettingsStmt, err = db.PrepareContext(ctx, updatePodcastDownloadSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePodcastDownloadSettings: %w", err)
+	}
+	if q.updatePodcastFeedStmt, err = db.PrepareContext(ctx, updatePodcastFeed); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePodcastFeed: %w", err)
+	}
+	if q.updatePodcastPolledStmt, err = db.PrepareContext(ctx, updatePodcastPolled); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePodcastPolled: %w", err)
+	}
+	if q.updatePodcastSettingsStmt, err = db.PrepareContext(ctx, updatePodcastSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePodcastSettings: %w", err)
+	}
+	if q.updateScannerSettingsStmt, err = db.PrepareContext(ctx, updateScannerSettings); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateScannerSettings: %w", err)
+	}
+	if q.updateTrackMetadataStmt, err = db.PrepareContext(ctx, updateTrackMetadata); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateTrackMetadata: %w", err)
+	}
 	if q.updateTrackPositionStmt, err = db.PrepareContext(ctx, updateTrackPosition); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateTrackPosition: %w", err)
 	}
@@ -336,18 +825,51 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.upsertAlbumGenreStmt, err = db.PrepareContext(ctx, upsertAlbumGenre); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertAlbumGenre: %w", err)
 	}
+	if q.upsertApiCacheEntryStmt, err = db.PrepareContext(ctx, upsertApiCacheEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertApiCacheEntry: %w", err)
+	}
 	if q.upsertArtistStmt, err = db.PrepareContext(ctx, upsertArtist); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertArtist: %w", err)
 	}
+	if q.upsertAudiobookStmt, err = db.PrepareContext(ctx, upsertAudiobook); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertAudiobook: %w", err)
+	}
+	if q.upsertAudiobookFileStmt, err = db.PrepareContext(ctx, upsertAudiobookFile); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertAudiobookFile: %w", err)
+	}
+	if q.upsertAudiobookProgressStmt, err = db.PrepareContext(ctx, upsertAudiobookProgress); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertAudiobookProgress: %w", err)
+	}
+	if q.upsertAuthorStmt, err = db.PrepareContext(ctx, upsertAuthor); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertAuthor: %w", err)
+	}
 	if q.upsertCastStmt, err = db.PrepareContext(ctx, upsertCast); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertCast: %w", err)
 	}
+	if q.upsertCollectionStmt, err = db.PrepareContext(ctx, upsertCollection); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertCollection: %w", err)
+	}
 	if q.upsertCrewStmt, err = db.PrepareContext(ctx, upsertCrew); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertCrew: %w", err)
 	}
 	if q.upsertExtraVideoStmt, err = db.PrepareContext(ctx, upsertExtraVideo); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertExtraVideo: %w", err)
 	}
+	if q.upsertGenreAliasStmt, err = db.PrepareContext(ctx, upsertGenreAlias); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertGenreAlias: %w", err)
+	}
+	if q.upsertLastfmSessionStmt, err = db.PrepareContext(ctx, upsertLastfmSession); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertLastfmSession: %w", err)
+	}
+	if q.upsertListenbrainzAccountStmt, err = db.PrepareContext(ctx, upsertListenbrainzAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertListenbrainzAccount: %w", err)
+	}
+	if q.upsertLocalExtraStmt, err = db.PrepareContext(ctx, upsertLocalExtra); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertLocalExtra: %w", err)
+	}
+	if q.upsertMediaVersionStmt, err = db.PrepareContext(ctx, upsertMediaVersion); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertMediaVersion: %w", err)
+	}
 	if q.upsertMovieStmt, err = db.PrepareContext(ctx, upsertMovie); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertMovie: %w", err)
 	}
@@ -357,12 +879,24 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.upsertMusicianGenreStmt, err = db.PrepareContext(ctx, upsertMusicianGenre); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertMusicianGenre: %w", err)
 	}
+	if q.upsertPodcastEpisodeStmt, err = db.PrepareContext(ctx, upsertPodcastEpisode); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertPodcastEpisode: %w", err)
+	}
+	if q.upsertPodcastEpisodeProgressStmt, err = db.PrepareContext(ctx, upsertPodcastEpisodeProgress); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertPodcastEpisodeProgress: %w", err)
+	}
 	if q.upsertProductionCompanyStmt, err = db.PrepareContext(ctx, upsertProductionCompany); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertProductionCompany: %w", err)
 	}
+	if q.upsertScannedLyricsStmt, err = db.PrepareContext(ctx, upsertScannedLyrics); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertScannedLyrics: %w", err)
+	}
 	if q.upsertTrackStmt, err = db.PrepareContext(ctx, upsertTrack); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertTrack: %w", err)
 	}
+	if q.upsertUserLyricsStmt, err = db.PrepareContext(ctx, upsertUserLyrics); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertUserLyrics: %w", err)
+	}
 	if q.upsertUserTrackStatsStmt, err = db.PrepareContext(ctx, upsertUserTrackStats); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertUserTrackStats: %w", err)
 	}
@@ -381,16 +915,46 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing addTrackToPlaylistStmt: %w", cerr)
 		}
 	}
+	if q.backfillLibraryDirsStmt != nil {
+		if cerr := q.backfillLibraryDirsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing backfillLibraryDirsStmt: %w", cerr)
+		}
+	}
 	if q.canUserEditPlaylistStmt != nil {
 		if cerr := q.canUserEditPlaylistStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing canUserEditPlaylistStmt: %w", cerr)
 		}
 	}
+	if q.checkAudiobookUnchangedStmt != nil {
+		if cerr := q.checkAudiobookUnchangedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing checkAudiobookUnchangedStmt: %w", cerr)
+		}
+	}
+	if q.checkCueTracksUnchangedStmt != nil {
+		if cerr := q.checkCueTracksUnchangedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing checkCueTracksUnchangedStmt: %w", cerr)
+		}
+	}
+	if q.checkLocalExtraUnchangedStmt != nil {
+		if cerr := q.checkLocalExtraUnchangedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing checkLocalExtraUnchangedStmt: %w", cerr)
+		}
+	}
+	if q.checkLyricsUnchangedStmt != nil {
+		if cerr := q.checkLyricsUnchangedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing checkLyricsUnchangedStmt: %w", cerr)
+		}
+	}
 	if q.checkMovieUnchangedStmt != nil {
 		if cerr := q.checkMovieUnchangedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing checkMovieUnchangedStmt: %w", cerr)
 		}
 	}
+	if q.checkSampleFileUnchangedStmt != nil {
+		if cerr := q.checkSampleFileUnchangedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing checkSampleFileUnchangedStmt: %w", cerr)
+		}
+	}
 	if q.checkTrackUnchangedStmt != nil {
 		if cerr := q.checkTrackUnchangedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing checkTrackUnchangedStmt: %w", cerr)
@@ -401,6 +965,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing clearPlaylistStmt: %w", cerr)
 		}
 	}
+	if q.clearPodcastEpisodeFileStmt != nil {
+		if cerr := q.clearPodcastEpisodeFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing clearPodcastEpisodeFileStmt: %w", cerr)
+		}
+	}
+	if q.countLastfmScrobblesStmt != nil {
+		if cerr := q.countLastfmScrobblesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countLastfmScrobblesStmt: %w", cerr)
+		}
+	}
+	if q.countListenbrainzListensStmt != nil {
+		if cerr := q.countListenbrainzListensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countListenbrainzListensStmt: %w", cerr)
+		}
+	}
 	if q.countPlaylistTracksStmt != nil {
 		if cerr := q.countPlaylistTracksStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing countPlaylistTracksStmt: %w", cerr)
@@ -411,6 +990,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing countPlaylistsByUserIdStmt: %w", cerr)
 		}
 	}
+	if q.createAudiobookBookmarkStmt != nil {
+		if cerr := q.createAudiobookBookmarkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAudiobookBookmarkStmt: %w", cerr)
+		}
+	}
+	if q.createAudiobookChapterStmt != nil {
+		if cerr := q.createAudiobookChapterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAudiobookChapterStmt: %w", cerr)
+		}
+	}
+	if q.createCollectionPartStmt != nil {
+		if cerr := q.createCollectionPartStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCollectionPartStmt: %w", cerr)
+		}
+	}
+	if q.createLastfmScrobbleStmt != nil {
+		if cerr := q.createLastfmScrobbleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createLastfmScrobbleStmt: %w", cerr)
+		}
+	}
+	if q.createListenbrainzListenStmt != nil {
+		if cerr := q.createListenbrainzListenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createListenbrainzListenStmt: %w", cerr)
+		}
+	}
 	if q.createMovieExtraVideoStmt != nil {
 		if cerr := q.createMovieExtraVideoStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createMovieExtraVideoStmt: %w", cerr)
@@ -436,6 +1040,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createPlaylistStmt: %w", cerr)
 		}
 	}
+	if q.createPodcastStmt != nil {
+		if cerr := q.createPodcastStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPodcastStmt: %w", cerr)
+		}
+	}
 	if q.createSettingsStmt != nil {
 		if cerr := q.createSettingsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createSettingsStmt: %w", cerr)
@@ -456,111 +1065,441 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing deleteAlbumStmt: %w", cerr)
 		}
 	}
-	if q.deleteMovieAudioStreamsStmt != nil {
-		if cerr := q.deleteMovieAudioStreamsStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteMovieAudioStreamsStmt: %w", cerr)
+	if q.deleteAlbumGenresStmt != nil {
+		if cerr := q.deleteAlbumGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAlbumGenresStmt: %w", cerr)
 		}
 	}
-	if q.deleteMovieChaptersStmt != nil {
-		if cerr := q.deleteMovieChaptersStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteMovieChaptersStmt: %w", cerr)
+	if q.deleteAudiobookStmt != nil {
+		if cerr := q.deleteAudiobookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAudiobookStmt: %w", cerr)
 		}
 	}
-	if q.deleteMovieExtraVideosStmt != nil {
-		if cerr := q.deleteMovieExtraVideosStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteMovieExtraVideosStmt: %w", cerr)
+	if q.deleteAudiobookBookmarkStmt != nil {
+		if cerr := q.deleteAudiobookBookmarkStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAudiobookBookmarkStmt: %w", cerr)
 		}
 	}
-	if q.deleteMovieGenresStmt != nil {
-		if cerr := q.deleteMovieGenresStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteMovieGenresStmt: %w", cerr)
+	if q.deleteAudiobookChaptersStmt != nil {
+		if cerr := q.deleteAudiobookChaptersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAudiobookChaptersStmt: %w", cerr)
 		}
 	}
-	if q.deleteMovieProductionCompaniesStmt != nil {
-		if cerr := q.deleteMovieProductionCompaniesStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteMovieProductionCompaniesStmt: %w", cerr)
+	if q.deleteCastMemberStmt != nil {
+		if cerr := q.deleteCastMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCastMemberStmt: %w", cerr)
 		}
 	}
-	if q.deleteMovieSubtitlesStmt != nil {
-		if cerr := q.deleteMovieSubtitlesStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteMovieSubtitlesStmt: %w", cerr)
+	if q.deleteCollectionPartsStmt != nil {
+		if cerr := q.deleteCollectionPartsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCollectionPartsStmt: %w", cerr)
 		}
 	}
-	if q.deleteMovieVideoStreamsStmt != nil {
-		if cerr := q.deleteMovieVideoStreamsStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteMovieVideoStreamsStmt: %w", cerr)
+	if q.deleteCrewMemberStmt != nil {
+		if cerr := q.deleteCrewMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCrewMemberStmt: %w", cerr)
 		}
 	}
-	if q.deletePlaylistStmt != nil {
-		if cerr := q.deletePlaylistStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deletePlaylistStmt: %w", cerr)
+	if q.deleteCueTracksStmt != nil {
+		if cerr := q.deleteCueTracksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCueTracksStmt: %w", cerr)
 		}
 	}
-	if q.deleteTrackGenresStmt != nil {
-		if cerr := q.deleteTrackGenresStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteTrackGenresStmt: %w", cerr)
+	if q.deleteExpiredApiCacheEntriesStmt != nil {
+		if cerr := q.deleteExpiredApiCacheEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredApiCacheEntriesStmt: %w", cerr)
 		}
 	}
-	if q.deleteTrackGenresExceptStmt != nil {
-		if cerr := q.deleteTrackGenresExceptStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteTrackGenresExceptStmt: %w", cerr)
+	if q.deleteExpiredLastfmScrobblesStmt != nil {
+		if cerr := q.deleteExpiredLastfmScrobblesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredLastfmScrobblesStmt: %w", cerr)
 		}
 	}
-	if q.deleteUserStmt != nil {
-		if cerr := q.deleteUserStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
+	if q.deleteGenreStmt != nil {
+		if cerr := q.deleteGenreStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteGenreStmt: %w", cerr)
 		}
 	}
-	if q.getAdminUserStmt != nil {
-		if cerr := q.getAdminUserStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAdminUserStmt: %w", cerr)
+	if q.deleteLastfmScrobbleStmt != nil {
+		if cerr := q.deleteLastfmScrobbleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteLastfmScrobbleStmt: %w", cerr)
 		}
 	}
-	if q.getAlbumByIDStmt != nil {
-		if cerr := q.getAlbumByIDStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAlbumByIDStmt: %w", cerr)
+	if q.deleteLastfmScrobblesByUserStmt != nil {
+		if cerr := q.deleteLastfmScrobblesByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteLastfmScrobblesByUserStmt: %w", cerr)
 		}
 	}
-	if q.getAlbumBySpotifyIDStmt != nil {
-		if cerr := q.getAlbumBySpotifyIDStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAlbumBySpotifyIDStmt: %w", cerr)
+	if q.deleteLastfmSessionStmt != nil {
+		if cerr := q.deleteLastfmSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteLastfmSessionStmt: %w", cerr)
 		}
 	}
-	if q.getAlbumsAlphabeticalStmt != nil {
-		if cerr := q.getAlbumsAlphabeticalStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAlbumsAlphabeticalStmt: %w", cerr)
+	if q.deleteListenbrainzAccountStmt != nil {
+		if cerr := q.deleteListenbrainzAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteListenbrainzAccountStmt: %w", cerr)
 		}
 	}
-	if q.getAlbumsByMusicianIDStmt != nil {
-		if cerr := q.getAlbumsByMusicianIDStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAlbumsByMusicianIDStmt: %w", cerr)
+	if q.deleteListenbrainzListenStmt != nil {
+		if cerr := q.deleteListenbrainzListenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteListenbrainzListenStmt: %w", cerr)
 		}
 	}
-	if q.getAlbumsCountStmt != nil {
-		if cerr := q.getAlbumsCountStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAlbumsCountStmt: %w", cerr)
+	if q.deleteListenbrainzListensByUserStmt != nil {
+		if cerr := q.deleteListenbrainzListensByUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteListenbrainzListensByUserStmt: %w", cerr)
 		}
 	}
-	if q.getAllPlaylistTracksStmt != nil {
-		if cerr := q.getAllPlaylistTracksStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAllPlaylistTracksStmt: %w", cerr)
+	if q.deleteMediaVersionAudioStreamsStmt != nil {
+		if cerr := q.deleteMediaVersionAudioStreamsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMediaVersionAudioStreamsStmt: %w", cerr)
 		}
 	}
-	if q.getAllTrackPathsAndSizesStmt != nil {
-		if cerr := q.getAllTrackPathsAndSizesStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAllTrackPathsAndSizesStmt: %w", cerr)
+	if q.deleteMediaVersionByFilePathStmt != nil {
+		if cerr := q.deleteMediaVersionByFilePathStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMediaVersionByFilePathStmt: %w", cerr)
 		}
 	}
-	if q.getCastByMovieIDStmt != nil {
-		if cerr := q.getCastByMovieIDStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getCastByMovieIDStmt: %w", cerr)
+	if q.deleteMediaVersionChaptersStmt != nil {
+		if cerr := q.deleteMediaVersionChaptersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMediaVersionChaptersStmt: %w", cerr)
 		}
 	}
-	if q.getCrewByMovieIDStmt != nil {
+	if q.deleteMediaVersionSubtitlesStmt != nil {
+		if cerr := q.deleteMediaVersionSubtitlesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMediaVersionSubtitlesStmt: %w", cerr)
+		}
+	}
+	if q.deleteMediaVersionVideoStreamsStmt != nil {
+		if cerr := q.deleteMediaVersionVideoStreamsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMediaVersionVideoStreamsStmt: %w", cerr)
+		}
+	}
+	if q.deleteMovieStmt != nil {
+		if cerr := q.deleteMovieStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMovieStmt: %w", cerr)
+		}
+	}
+	if q.deleteMovieCollectionStmt != nil {
+		if cerr := q.deleteMovieCollectionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMovieCollectionStmt: %w", cerr)
+		}
+	}
+	if q.deleteMovieExtraVideosStmt != nil {
+		if cerr := q.deleteMovieExtraVideosStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMovieExtraVideosStmt: %w", cerr)
+		}
+	}
+	if q.deleteMovieGenreStmt != nil {
+		if cerr := q.deleteMovieGenreStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMovieGenreStmt: %w", cerr)
+		}
+	}
+	if q.deleteMovieGenresStmt != nil {
+		if cerr := q.deleteMovieGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMovieGenresStmt: %w", cerr)
+		}
+	}
+	if q.deleteMovieProductionCompaniesStmt != nil {
+		if cerr := q.deleteMovieProductionCompaniesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMovieProductionCompaniesStmt: %w", cerr)
+		}
+	}
+	if q.deleteMovieProductionCompanyStmt != nil {
+		if cerr := q.deleteMovieProductionCompanyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMovieProductionCompanyStmt: %w", cerr)
+		}
+	}
+	if q.deleteMusicianGenresStmt != nil {
+		if cerr := q.deleteMusicianGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMusicianGenresStmt: %w", cerr)
+		}
+	}
+	if q.deletePlaylistStmt != nil {
+		if cerr := q.deletePlaylistStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePlaylistStmt: %w", cerr)
+		}
+	}
+	if q.deletePodcastStmt != nil {
+		if cerr := q.deletePodcastStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePodcastStmt: %w", cerr)
+		}
+	}
+	if q.deleteSampleFilesByLibraryStmt != nil {
+		if cerr := q.deleteSampleFilesByLibraryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSampleFilesByLibraryStmt: %w", cerr)
+		}
+	}
+	if q.deleteScanErrorStmt != nil {
+		if cerr := q.deleteScanErrorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteScanErrorStmt: %w", cerr)
+		}
+	}
+	if q.deleteScannedLyricsStmt != nil {
+		if cerr := q.deleteScannedLyricsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteScannedLyricsStmt: %w", cerr)
+		}
+	}
+	if q.deleteStaleAudiobookFilesStmt != nil {
+		if cerr := q.deleteStaleAudiobookFilesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteStaleAudiobookFilesStmt: %w", cerr)
+		}
+	}
+	if q.deleteStaleCueTracksStmt != nil {
+		if cerr := q.deleteStaleCueTracksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteStaleCueTracksStmt: %w", cerr)
+		}
+	}
+	if q.deleteTrackByFilePathStmt != nil {
+		if cerr := q.deleteTrackByFilePathStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTrackByFilePathStmt: %w", cerr)
+		}
+	}
+	if q.deleteTrackGenresStmt != nil {
+		if cerr := q.deleteTrackGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTrackGenresStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserStmt != nil {
+		if cerr := q.deleteUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
+		}
+	}
+	if q.fillMovieMetadataStmt != nil {
+		if cerr := q.fillMovieMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing fillMovieMetadataStmt: %w", cerr)
+		}
+	}
+	if q.getAdminUserStmt != nil {
+		if cerr := q.getAdminUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAdminUserStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumByDirectoryStmt != nil {
+		if cerr := q.getAlbumByDirectoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumByDirectoryStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumByIDStmt != nil {
+		if cerr := q.getAlbumByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumByIDStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumByMusicbrainzIDStmt != nil {
+		if cerr := q.getAlbumByMusicbrainzIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumByMusicbrainzIDStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumByScanTitleStmt != nil {
+		if cerr := q.getAlbumByScanTitleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumByScanTitleStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumBySpotifyIDStmt != nil {
+		if cerr := q.getAlbumBySpotifyIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumBySpotifyIDStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumsAlphabeticalStmt != nil {
+		if cerr := q.getAlbumsAlphabeticalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumsAlphabeticalStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumsByMusicianIDStmt != nil {
+		if cerr := q.getAlbumsByMusicianIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumsByMusicianIDStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumsByTitleStmt != nil {
+		if cerr := q.getAlbumsByTitleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumsByTitleStmt: %w", cerr)
+		}
+	}
+	if q.getAlbumsCountStmt != nil {
+		if cerr := q.getAlbumsCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAlbumsCountStmt: %w", cerr)
+		}
+	}
+	if q.getAllPlaylistTracksStmt != nil {
+		if cerr := q.getAllPlaylistTracksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAllPlaylistTracksStmt: %w", cerr)
+		}
+	}
+	if q.getAllTrackPathsAndSizesStmt != nil {
+		if cerr := q.getAllTrackPathsAndSizesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAllTrackPathsAndSizesStmt: %w", cerr)
+		}
+	}
+	if q.getAllTrackScrobbleInfoStmt != nil {
+		if cerr := q.getAllTrackScrobbleInfoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAllTrackScrobbleInfoStmt: %w", cerr)
+		}
+	}
+	if q.getApiCacheEntryStmt != nil {
+		if cerr := q.getApiCacheEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getApiCacheEntryStmt: %w", cerr)
+		}
+	}
+	if q.getArtistByIDStmt != nil {
+		if cerr := q.getArtistByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getArtistByIDStmt: %w", cerr)
+		}
+	}
+	if q.getAudioStreamsByMediaVersionIDStmt != nil {
+		if cerr := q.getAudioStreamsByMediaVersionIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudioStreamsByMediaVersionIDStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobookBookmarksStmt != nil {
+		if cerr := q.getAudiobookBookmarksStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobookBookmarksStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobookByIDStmt != nil {
+		if cerr := q.getAudiobookByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobookByIDStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobookChapterStmt != nil {
+		if cerr := q.getAudiobookChapterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobookChapterStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobookChaptersStmt != nil {
+		if cerr := q.getAudiobookChaptersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobookChaptersStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobookFileStmt != nil {
+		if cerr := q.getAudiobookFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobookFileStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobookFilesStmt != nil {
+		if cerr := q.getAudiobookFilesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobookFilesStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobookProgressStmt != nil {
+		if cerr := q.getAudiobookProgressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobookProgressStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobooksAlphabeticalStmt != nil {
+		if cerr := q.getAudiobooksAlphabeticalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobooksAlphabeticalStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobooksByAuthorStmt != nil {
+		if cerr := q.getAudiobooksByAuthorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobooksByAuthorStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobooksByDirectoryStmt != nil {
+		if cerr := q.getAudiobooksByDirectoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobooksByDirectoryStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobooksCountStmt != nil {
+		if cerr := q.getAudiobooksCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobooksCountStmt: %w", cerr)
+		}
+	}
+	if q.getAudiobooksInProgressStmt != nil {
+		if cerr := q.getAudiobooksInProgressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAudiobooksInProgressStmt: %w", cerr)
+		}
+	}
+	if q.getAuthorByIDStmt != nil {
+		if cerr := q.getAuthorByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAuthorByIDStmt: %w", cerr)
+		}
+	}
+	if q.getAuthorsAlphabeticalStmt != nil {
+		if cerr := q.getAuthorsAlphabeticalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAuthorsAlphabeticalStmt: %w", cerr)
+		}
+	}
+	if q.getAuthorsCountStmt != nil {
+		if cerr := q.getAuthorsCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAuthorsCountStmt: %w", cerr)
+		}
+	}
+	if q.getCastByArtistIDStmt != nil {
+		if cerr := q.getCastByArtistIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCastByArtistIDStmt: %w", cerr)
+		}
+	}
+	if q.getCastByMovieIDStmt != nil {
+		if cerr := q.getCastByMovieIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCastByMovieIDStmt: %w", cerr)
+		}
+	}
+	if q.getCollectionByIDStmt != nil {
+		if cerr := q.getCollectionByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCollectionByIDStmt: %w", cerr)
+		}
+	}
+	if q.getCollectionMoviesStmt != nil {
+		if cerr := q.getCollectionMoviesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCollectionMoviesStmt: %w", cerr)
+		}
+	}
+	if q.getCollectionsStmt != nil {
+		if cerr := q.getCollectionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCollectionsStmt: %w", cerr)
+		}
+	}
+	if q.getCollectionsCountStmt != nil {
+		if cerr := q.getCollectionsCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCollectionsCountStmt: %w", cerr)
+		}
+	}
+	if q.getCrewByArtistIDStmt != nil {
+		if cerr := q.getCrewByArtistIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCrewByArtistIDStmt: %w", cerr)
+		}
+	}
+	if q.getCrewByMovieIDStmt != nil {
 		if cerr := q.getCrewByMovieIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getCrewByMovieIDStmt: %w", cerr)
 		}
 	}
+	if q.getDownloadedPodcastEpisodesStmt != nil {
+		if cerr := q.getDownloadedPodcastEpisodesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDownloadedPodcastEpisodesStmt: %w", cerr)
+		}
+	}
+	if q.getDueLastfmScrobblesStmt != nil {
+		if cerr := q.getDueLastfmScrobblesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDueLastfmScrobblesStmt: %w", cerr)
+		}
+	}
+	if q.getDueListenbrainzListensStmt != nil {
+		if cerr := q.getDueListenbrainzListensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDueListenbrainzListensStmt: %w", cerr)
+		}
+	}
+	if q.getFilteredAlbumsCountStmt != nil {
+		if cerr := q.getFilteredAlbumsCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFilteredAlbumsCountStmt: %w", cerr)
+		}
+	}
+	if q.getGenreAliasesByTypeStmt != nil {
+		if cerr := q.getGenreAliasesByTypeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getGenreAliasesByTypeStmt: %w", cerr)
+		}
+	}
+	if q.getGenreByAliasStmt != nil {
+		if cerr := q.getGenreByAliasStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getGenreByAliasStmt: %w", cerr)
+		}
+	}
+	if q.getGenreByIDStmt != nil {
+		if cerr := q.getGenreByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getGenreByIDStmt: %w", cerr)
+		}
+	}
 	if q.getGenresByAlbumIDStmt != nil {
 		if cerr := q.getGenresByAlbumIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getGenresByAlbumIDStmt: %w", cerr)
@@ -581,6 +1520,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getGenresByMusicianIDStmt: %w", cerr)
 		}
 	}
+	if q.getGenresWithCountsStmt != nil {
+		if cerr := q.getGenresWithCountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getGenresWithCountsStmt: %w", cerr)
+		}
+	}
+	if q.getLastfmSessionStmt != nil {
+		if cerr := q.getLastfmSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastfmSessionStmt: %w", cerr)
+		}
+	}
 	if q.getLatestAlbumsStmt != nil {
 		if cerr := q.getLatestAlbumsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getLatestAlbumsStmt: %w", cerr)
@@ -601,11 +1550,51 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getLikedTracksByUserIDStmt: %w", cerr)
 		}
 	}
+	if q.getListenbrainzAccountStmt != nil {
+		if cerr := q.getListenbrainzAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getListenbrainzAccountStmt: %w", cerr)
+		}
+	}
+	if q.getLocalExtraByIDStmt != nil {
+		if cerr := q.getLocalExtraByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLocalExtraByIDStmt: %w", cerr)
+		}
+	}
+	if q.getLocalExtrasByMovieIDStmt != nil {
+		if cerr := q.getLocalExtrasByMovieIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLocalExtrasByMovieIDStmt: %w", cerr)
+		}
+	}
 	if q.getMaxPositionStmt != nil {
 		if cerr := q.getMaxPositionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMaxPositionStmt: %w", cerr)
 		}
 	}
+	if q.getMediaVersionByFilePathStmt != nil {
+		if cerr := q.getMediaVersionByFilePathStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMediaVersionByFilePathStmt: %w", cerr)
+		}
+	}
+	if q.getMediaVersionByIDStmt != nil {
+		if cerr := q.getMediaVersionByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMediaVersionByIDStmt: %w", cerr)
+		}
+	}
+	if q.getMediaVersionsByDirectoryStmt != nil {
+		if cerr := q.getMediaVersionsByDirectoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMediaVersionsByDirectoryStmt: %w", cerr)
+		}
+	}
+	if q.getMediaVersionsByMovieIDStmt != nil {
+		if cerr := q.getMediaVersionsByMovieIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMediaVersionsByMovieIDStmt: %w", cerr)
+		}
+	}
+	if q.getMissingCollectionPartsStmt != nil {
+		if cerr := q.getMissingCollectionPartsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMissingCollectionPartsStmt: %w", cerr)
+		}
+	}
 	if q.getMovieByFilePathStmt != nil {
 		if cerr := q.getMovieByFilePathStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMovieByFilePathStmt: %w", cerr)
@@ -616,6 +1605,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getMovieByIDStmt: %w", cerr)
 		}
 	}
+	if q.getMovieByTitleAndYearStmt != nil {
+		if cerr := q.getMovieByTitleAndYearStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMovieByTitleAndYearStmt: %w", cerr)
+		}
+	}
 	if q.getMovieByTmdbIDStmt != nil {
 		if cerr := q.getMovieByTmdbIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMovieByTmdbIDStmt: %w", cerr)
@@ -626,11 +1620,26 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getMovieExtraVideosStmt: %w", cerr)
 		}
 	}
+	if q.getMoviesWithoutCollectionStmt != nil {
+		if cerr := q.getMoviesWithoutCollectionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMoviesWithoutCollectionStmt: %w", cerr)
+		}
+	}
 	if q.getMusicianByIDStmt != nil {
 		if cerr := q.getMusicianByIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMusicianByIDStmt: %w", cerr)
 		}
 	}
+	if q.getMusicianByMusicbrainzIDStmt != nil {
+		if cerr := q.getMusicianByMusicbrainzIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMusicianByMusicbrainzIDStmt: %w", cerr)
+		}
+	}
+	if q.getMusicianByScanNameStmt != nil {
+		if cerr := q.getMusicianByScanNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMusicianByScanNameStmt: %w", cerr)
+		}
+	}
 	if q.getMusicianBySpotifyIDStmt != nil {
 		if cerr := q.getMusicianBySpotifyIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMusicianBySpotifyIDStmt: %w", cerr)
@@ -646,6 +1655,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getMusiciansByAlbumIDStmt: %w", cerr)
 		}
 	}
+	if q.getMusiciansByNameStmt != nil {
+		if cerr := q.getMusiciansByNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMusiciansByNameStmt: %w", cerr)
+		}
+	}
 	if q.getMusiciansCountStmt != nil {
 		if cerr := q.getMusiciansCountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getMusiciansCountStmt: %w", cerr)
@@ -686,6 +1700,46 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getPlaylistsWithCollaboratorAccessStmt: %w", cerr)
 		}
 	}
+	if q.getPodcastByFeedURLStmt != nil {
+		if cerr := q.getPodcastByFeedURLStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastByFeedURLStmt: %w", cerr)
+		}
+	}
+	if q.getPodcastByIDStmt != nil {
+		if cerr := q.getPodcastByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastByIDStmt: %w", cerr)
+		}
+	}
+	if q.getPodcastEpisodeByIDStmt != nil {
+		if cerr := q.getPodcastEpisodeByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastEpisodeByIDStmt: %w", cerr)
+		}
+	}
+	if q.getPodcastEpisodesStmt != nil {
+		if cerr := q.getPodcastEpisodesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastEpisodesStmt: %w", cerr)
+		}
+	}
+	if q.getPodcastEpisodesCountStmt != nil {
+		if cerr := q.getPodcastEpisodesCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastEpisodesCountStmt: %w", cerr)
+		}
+	}
+	if q.getPodcastEpisodesToDownloadStmt != nil {
+		if cerr := q.getPodcastEpisodesToDownloadStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastEpisodesToDownloadStmt: %w", cerr)
+		}
+	}
+	if q.getPodcastsStmt != nil {
+		if cerr := q.getPodcastsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastsStmt: %w", cerr)
+		}
+	}
+	if q.getPodcastsDueForPollStmt != nil {
+		if cerr := q.getPodcastsDueForPollStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPodcastsDueForPollStmt: %w", cerr)
+		}
+	}
 	if q.getProductionCompaniesByMovieIDStmt != nil {
 		if cerr := q.getProductionCompaniesByMovieIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getProductionCompaniesByMovieIDStmt: %w", cerr)
@@ -696,16 +1750,56 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getRandomTracksStmt: %w", cerr)
 		}
 	}
+	if q.getScanErrorByIDStmt != nil {
+		if cerr := q.getScanErrorByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getScanErrorByIDStmt: %w", cerr)
+		}
+	}
+	if q.getScanErrorsStmt != nil {
+		if cerr := q.getScanErrorsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getScanErrorsStmt: %w", cerr)
+		}
+	}
+	if q.getScanErrorsCountStmt != nil {
+		if cerr := q.getScanErrorsCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getScanErrorsCountStmt: %w", cerr)
+		}
+	}
 	if q.getSettingsStmt != nil {
 		if cerr := q.getSettingsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getSettingsStmt: %w", cerr)
 		}
 	}
+	if q.getStaleMoviesStmt != nil {
+		if cerr := q.getStaleMoviesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStaleMoviesStmt: %w", cerr)
+		}
+	}
+	if q.getStaleMusiciansStmt != nil {
+		if cerr := q.getStaleMusiciansStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStaleMusiciansStmt: %w", cerr)
+		}
+	}
+	if q.getSubtitlesByMediaVersionIDStmt != nil {
+		if cerr := q.getSubtitlesByMediaVersionIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSubtitlesByMediaVersionIDStmt: %w", cerr)
+		}
+	}
 	if q.getTrackStmt != nil {
 		if cerr := q.getTrackStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTrackStmt: %w", cerr)
 		}
 	}
+	if q.getTrackLyricsStmt != nil {
+		if cerr := q.getTrackLyricsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTrackLyricsStmt: %w", cerr)
+		}
+	}
+	if q.getTrackScrobbleInfoStmt != nil {
+		if cerr := q.getTrackScrobbleInfoStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTrackScrobbleInfoStmt: %w", cerr)
+		}
+	}
 	if q.getTracksAlphabeticalStmt != nil {
 		if cerr := q.getTracksAlphabeticalStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTracksAlphabeticalStmt: %w", cerr)
@@ -726,6 +1820,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getTracksCountStmt: %w", cerr)
 		}
 	}
+	if q.getUnaliasedGenresStmt != nil {
+		if cerr := q.getUnaliasedGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUnaliasedGenresStmt: %w", cerr)
+		}
+	}
+	if q.getUnmatchedAlbumStmt != nil {
+		if cerr := q.getUnmatchedAlbumStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUnmatchedAlbumStmt: %w", cerr)
+		}
+	}
+	if q.getUnmatchedMusicianByNameStmt != nil {
+		if cerr := q.getUnmatchedMusicianByNameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUnmatchedMusicianByNameStmt: %w", cerr)
+		}
+	}
 	if q.getUserStmt != nil {
 		if cerr := q.getUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserStmt: %w", cerr)
@@ -746,6 +1855,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserListeningStatsStmt: %w", cerr)
 		}
 	}
+	if q.getUserPlayTimesStmt != nil {
+		if cerr := q.getUserPlayTimesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserPlayTimesStmt: %w", cerr)
+		}
+	}
 	if q.getUserRecentlyPlayedStmt != nil {
 		if cerr := q.getUserRecentlyPlayedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserRecentlyPlayedStmt: %w", cerr)
@@ -776,6 +1890,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserTrackPlayCountStmt: %w", cerr)
 		}
 	}
+	if q.getVideoStreamsByMediaVersionIDStmt != nil {
+		if cerr := q.getVideoStreamsByMediaVersionIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVideoStreamsByMediaVersionIDStmt: %w", cerr)
+		}
+	}
+	if q.importPlayEventStmt != nil {
+		if cerr := q.importPlayEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing importPlayEventStmt: %w", cerr)
+		}
+	}
 	if q.insertAudioStreamStmt != nil {
 		if cerr := q.insertAudioStreamStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing insertAudioStreamStmt: %w", cerr)
@@ -816,11 +1940,96 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing likeTrackStmt: %w", cerr)
 		}
 	}
+	if q.markAlbumCompilationStmt != nil {
+		if cerr := q.markAlbumCompilationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markAlbumCompilationStmt: %w", cerr)
+		}
+	}
+	if q.mergeAlbumGenresStmt != nil {
+		if cerr := q.mergeAlbumGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeAlbumGenresStmt: %w", cerr)
+		}
+	}
+	if q.mergeAudioStreamsStmt != nil {
+		if cerr := q.mergeAudioStreamsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeAudioStreamsStmt: %w", cerr)
+		}
+	}
+	if q.mergeChaptersStmt != nil {
+		if cerr := q.mergeChaptersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeChaptersStmt: %w", cerr)
+		}
+	}
+	if q.mergeGenreAliasesStmt != nil {
+		if cerr := q.mergeGenreAliasesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeGenreAliasesStmt: %w", cerr)
+		}
+	}
+	if q.mergeLocalExtrasStmt != nil {
+		if cerr := q.mergeLocalExtrasStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeLocalExtrasStmt: %w", cerr)
+		}
+	}
+	if q.mergeMediaVersionsStmt != nil {
+		if cerr := q.mergeMediaVersionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeMediaVersionsStmt: %w", cerr)
+		}
+	}
+	if q.mergeMovieGenresStmt != nil {
+		if cerr := q.mergeMovieGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeMovieGenresStmt: %w", cerr)
+		}
+	}
+	if q.mergeMusicianGenresStmt != nil {
+		if cerr := q.mergeMusicianGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeMusicianGenresStmt: %w", cerr)
+		}
+	}
+	if q.mergeSubtitlesStmt != nil {
+		if cerr := q.mergeSubtitlesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeSubtitlesStmt: %w", cerr)
+		}
+	}
+	if q.mergeTrackGenresStmt != nil {
+		if cerr := q.mergeTrackGenresStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeTrackGenresStmt: %w", cerr)
+		}
+	}
+	if q.mergeVideoStreamsStmt != nil {
+		if cerr := q.mergeVideoStreamsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeVideoStreamsStmt: %w", cerr)
+		}
+	}
+	if q.rebuildUserTrackStatsStmt != nil {
+		if cerr := q.rebuildUserTrackStatsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing rebuildUserTrackStatsStmt: %w", cerr)
+		}
+	}
 	if q.recordPlayEventStmt != nil {
 		if cerr := q.recordPlayEventStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing recordPlayEventStmt: %w", cerr)
 		}
 	}
+	if q.recordSampleFileStmt != nil {
+		if cerr := q.recordSampleFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordSampleFileStmt: %w", cerr)
+		}
+	}
+	if q.recordScanErrorStmt != nil {
+		if cerr := q.recordScanErrorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing recordScanErrorStmt: %w", cerr)
+		}
+	}
+	if q.refreshMovieMetadataStmt != nil {
+		if cerr := q.refreshMovieMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing refreshMovieMetadataStmt: %w", cerr)
+		}
+	}
+	if q.refreshMusicianMetadataStmt != nil {
+		if cerr := q.refreshMusicianMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing refreshMusicianMetadataStmt: %w", cerr)
+		}
+	}
 	if q.removeCollaboratorStmt != nil {
 		if cerr := q.removeCollaboratorStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing removeCollaboratorStmt: %w", cerr)
@@ -831,6 +2040,61 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing removeTrackFromPlaylistStmt: %w", cerr)
 		}
 	}
+	if q.retryLastfmScrobbleStmt != nil {
+		if cerr := q.retryLastfmScrobbleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing retryLastfmScrobbleStmt: %w", cerr)
+		}
+	}
+	if q.retryListenbrainzListenStmt != nil {
+		if cerr := q.retryListenbrainzListenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing retryListenbrainzListenStmt: %w", cerr)
+		}
+	}
+	if q.searchArtistsStmt != nil {
+		if cerr := q.searchArtistsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchArtistsStmt: %w", cerr)
+		}
+	}
+	if q.searchArtistsCountStmt != nil {
+		if cerr := q.searchArtistsCountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchArtistsCountStmt: %w", cerr)
+		}
+	}
+	if q.setAlbumDirectoryStmt != nil {
+		if cerr := q.setAlbumDirectoryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setAlbumDirectoryStmt: %w", cerr)
+		}
+	}
+	if q.setAlbumMusicbrainzIDsStmt != nil {
+		if cerr := q.setAlbumMusicbrainzIDsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setAlbumMusicbrainzIDsStmt: %w", cerr)
+		}
+	}
+	if q.setCollectionsBackfilledStmt != nil {
+		if cerr := q.setCollectionsBackfilledStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setCollectionsBackfilledStmt: %w", cerr)
+		}
+	}
+	if q.setMovieCollectionStmt != nil {
+		if cerr := q.setMovieCollectionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setMovieCollectionStmt: %w", cerr)
+		}
+	}
+	if q.setMusicianMusicbrainzIDStmt != nil {
+		if cerr := q.setMusicianMusicbrainzIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setMusicianMusicbrainzIDStmt: %w", cerr)
+		}
+	}
+	if q.setPodcastEpisodeFileStmt != nil {
+		if cerr := q.setPodcastEpisodeFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setPodcastEpisodeFileStmt: %w", cerr)
+		}
+	}
+	if q.setTrackLyricsScannedStmt != nil {
+		if cerr := q.setTrackLyricsScannedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setTrackLyricsScannedStmt: %w", cerr)
+		}
+	}
 	if q.shiftPositionsDownStmt != nil {
 		if cerr := q.shiftPositionsDownStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing shiftPositionsDownStmt: %w", cerr)
@@ -841,16 +2105,71 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing shiftPositionsUpStmt: %w", cerr)
 		}
 	}
+	if q.touchMovieMetadataRefreshedStmt != nil {
+		if cerr := q.touchMovieMetadataRefreshedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing touchMovieMetadataRefreshedStmt: %w", cerr)
+		}
+	}
+	if q.touchMusicianMetadataRefreshedStmt != nil {
+		if cerr := q.touchMusicianMetadataRefreshedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing touchMusicianMetadataRefreshedStmt: %w", cerr)
+		}
+	}
 	if q.unlikeTrackStmt != nil {
 		if cerr := q.unlikeTrackStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing unlikeTrackStmt: %w", cerr)
 		}
 	}
+	if q.updateAlbumMetadataStmt != nil {
+		if cerr := q.updateAlbumMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateAlbumMetadataStmt: %w", cerr)
+		}
+	}
+	if q.updateAlbumSpotifyMatchStmt != nil {
+		if cerr := q.updateAlbumSpotifyMatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateAlbumSpotifyMatchStmt: %w", cerr)
+		}
+	}
 	if q.updateCollaboratorPermissionStmt != nil {
 		if cerr := q.updateCollaboratorPermissionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateCollaboratorPermissionStmt: %w", cerr)
 		}
 	}
+	if q.updateListenbrainzImportStmt != nil {
+		if cerr := q.updateListenbrainzImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateListenbrainzImportStmt: %w", cerr)
+		}
+	}
+	if q.updateMetadataLanguageSettingsStmt != nil {
+		if cerr := q.updateMetadataLanguageSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMetadataLanguageSettingsStmt: %w", cerr)
+		}
+	}
+	if q.updateMetadataRefreshSettingsStmt != nil {
+		if cerr := q.updateMetadataRefreshSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMetadataRefreshSettingsStmt: %w", cerr)
+		}
+	}
+	if q.updateMovieFileStmt != nil {
+		if cerr := q.updateMovieFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMovieFileStmt: %w", cerr)
+		}
+	}
+	if q.updateMovieMatchStmt != nil {
+		if cerr := q.updateMovieMatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMovieMatchStmt: %w", cerr)
+		}
+	}
+	if q.updateMovieMetadataStmt != nil {
+		if cerr := q.updateMovieMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMovieMetadataStmt: %w", cerr)
+		}
+	}
+	if q.updateMusicianMetadataStmt != nil {
+		if cerr := q.updateMusicianMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateMusicianMetadataStmt: %w", cerr)
+		}
+	}
 	if q.updatePlaylistStmt != nil {
 		if cerr := q.updatePlaylistStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updatePlaylistStmt: %w", cerr)
@@ -861,6 +2180,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updatePlaylistTimestampStmt: %w", cerr)
 		}
 	}
+	if q.updatePodcastDownloadSettingsStmt != nil {
+		if cerr := q.updatePodcastDownloadSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePodcastDownloadSettingsStmt: %w", cerr)
+		}
+	}
+	if q.updatePodcastFeedStmt != nil {
+		if cerr := q.updatePodcastFeedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePodcastFeedStmt: %w", cerr)
+		}
+	}
+	if q.updatePodcastPolledStmt != nil {
+		if cerr := q.updatePodcastPolledStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePodcastPolledStmt: %w", cerr)
+		}
+	}
+	if q.updatePodcastSettingsStmt != nil {
+		if cerr := q.updatePodcastSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePodcastSettingsStmt: %w", cerr)
+		}
+	}
+	if q.updateScannerSettingsStmt != nil {
+		if cerr := q.updateScannerSettingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateScannerSettingsStmt: %w", cerr)
+		}
+	}
+	if q.updateTrackMetadataStmt != nil {
+		if cerr := q.updateTrackMetadataStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateTrackMetadataStmt: %w", cerr)
+		}
+	}
 	if q.updateTrackPositionStmt != nil {
 		if cerr := q.updateTrackPositionStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateTrackPositionStmt: %w", cerr)
@@ -891,16 +2240,46 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing upsertAlbumGenreStmt: %w", cerr)
 		}
 	}
+	if q.upsertApiCacheEntryStmt != nil {
+		if cerr := q.upsertApiCacheEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertApiCacheEntryStmt: %w", cerr)
+		}
+	}
 	if q.upsertArtistStmt != nil {
 		if cerr := q.upsertArtistStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertArtistStmt: %w", cerr)
 		}
 	}
+	if q.upsertAudiobookStmt != nil {
+		if cerr := q.upsertAudiobookStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertAudiobookStmt: %w", cerr)
+		}
+	}
+	if q.upsertAudiobookFileStmt != nil {
+		if cerr := q.upsertAudiobookFileStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertAudiobookFileStmt: %w", cerr)
+		}
+	}
+	if q.upsertAudiobookProgressStmt != nil {
+		if cerr := q.upsertAudiobookProgressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertAudiobookProgressStmt: %w", cerr)
+		}
+	}
+	if q.upsertAuthorStmt != nil {
+		if cerr := q.upsertAuthorStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertAuthorStmt: %w", cerr)
+		}
+	}
 	if q.upsertCastStmt != nil {
 		if cerr := q.upsertCastStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertCastStmt: %w", cerr)
 		}
 	}
+	if q.upsertCollectionStmt != nil {
+		if cerr := q.upsertCollectionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertCollectionStmt: %w", cerr)
+		}
+	}
 	if q.upsertCrewStmt != nil {
 		if cerr := q.upsertCrewStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertCrewStmt: %w", cerr)
@@ -911,6 +2290,31 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing upsertExtraVideoStmt: %w", cerr)
 		}
 	}
+	if q.upsertGenreAliasStmt != nil {
+		if cerr := q.upsertGenreAliasStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertGenreAliasStmt: %w", cerr)
+		}
+	}
+	if q.upsertLastfmSessionStmt != nil {
+		if cerr := q.upsertLastfmSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertLastfmSessionStmt: %w", cerr)
+		}
+	}
+	if q.upsertListenbrainzAccountStmt != nil {
+		if cerr := q.upsertListenbrainzAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertListenbrainzAccountStmt: %w", cerr)
+		}
+	}
+	if q.upsertLocalExtraStmt != nil {
+		if cerr := q.upsertLocalExtraStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertLocalExtraStmt: %w", cerr)
+		}
+	}
+	if q.upsertMediaVersionStmt != nil {
+		if cerr := q.upsertMediaVersionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertMediaVersionStmt: %w", cerr)
+		}
+	}
 	if q.upsertMovieStmt != nil {
 		if cerr := q.upsertMovieStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertMovieStmt: %w", cerr)
@@ -926,16 +2330,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing upsertMusicianGenreStmt: %w", cerr)
 		}
 	}
+	if q.upsertPodcastEpisodeStmt != nil {
+		if cerr := q.upsertPodcastEpisodeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertPodcastEpisodeStmt: %w", cerr)
+		}
+	}
+	if q.upsertPodcastEpisodeProgressStmt != nil {
+		if cerr := q.upsertPodcastEpisodeProgressStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertPodcastEpisodeProgressStmt: %w", cerr)
+		}
+	}
 	if q.upsertProductionCompanyStmt != nil {
 		if cerr := q.upsertProductionCompanyStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertProductionCompanyStmt: %w", cerr)
 		}
 	}
+	if q.upsertScannedLyricsStmt != nil {
+		if cerr := q.upsertScannedLyricsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertScannedLyricsStmt: %w", cerr)
+		}
+	}
 	if q.upsertTrackStmt != nil {
 		if cerr := q.upsertTrackStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertTrackStmt: %w", cerr)
 		}
 	}
+	if q.upsertUserLyricsStmt != nil {
+		if cerr := q.upsertUserLyricsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertUserLyricsStmt: %w", cerr)
+		}
+	}
 	if q.upsertUserTrackStatsStmt != nil {
 		if cerr := q.upsertUserTrackStatsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertUserTrackStatsStmt: %w", cerr)
@@ -982,59 +2406,155 @@ type Queries struct {
 	tx                                     *sql.Tx
 	addCollaboratorStmt                    *sql.Stmt
 	addTrackToPlaylistStmt                 *sql.Stmt
+	backfillLibraryDirsStmt                *sql.Stmt
 	canUserEditPlaylistStmt                *sql.Stmt
+	checkAudiobookUnchangedStmt            *sql.Stmt
+	checkCueTracksUnchangedStmt            *sql.Stmt
+	checkLocalExtraUnchangedStmt           *sql.Stmt
+	checkLyricsUnchangedStmt               *sql.Stmt
 	checkMovieUnchangedStmt                *sql.Stmt
+	checkSampleFileUnchangedStmt           *sql.Stmt
 	checkTrackUnchangedStmt                *sql.Stmt
 	clearPlaylistStmt                      *sql.Stmt
+	clearPodcastEpisodeFileStmt            *sql.Stmt
+	countLastfmScrobblesStmt               *sql.Stmt
+	countListenbrainzListensStmt           *sql.Stmt
 	countPlaylistTracksStmt                *sql.Stmt
 	countPlaylistsByUserIdStmt             *sql.Stmt
+	createAudiobookBookmarkStmt            *sql.Stmt
+	createAudiobookChapterStmt             *sql.Stmt
+	createCollectionPartStmt               *sql.Stmt
+	createLastfmScrobbleStmt               *sql.Stmt
+	createListenbrainzListenStmt           *sql.Stmt
 	createMovieExtraVideoStmt              *sql.Stmt
 	createMovieGenreStmt                   *sql.Stmt
 	createMovieProductionCompanyStmt       *sql.Stmt
 	createMusicianAlbumStmt                *sql.Stmt
 	createPlaylistStmt                     *sql.Stmt
+	createPodcastStmt                      *sql.Stmt
 	createSettingsStmt                     *sql.Stmt
 	createTrackGenreStmt                   *sql.Stmt
 	createUserStmt                         *sql.Stmt
 	deleteAlbumStmt                        *sql.Stmt
-	deleteMovieAudioStreamsStmt            *sql.Stmt
-	deleteMovieChaptersStmt                *sql.Stmt
+	deleteAlbumGenresStmt                  *sql.Stmt
+	deleteAudiobookStmt                    *sql.Stmt
+	deleteAudiobookBookmarkStmt            *sql.Stmt
+	deleteAudiobookChaptersStmt            *sql.Stmt
+	deleteCastMemberStmt                   *sql.Stmt
+	deleteCollectionPartsStmt              *sql.Stmt
+	deleteCrewMemberStmt                   *sql.Stmt
+	deleteCueTracksStmt                    *sql.Stmt
+	deleteExpiredApiCacheEntriesStmt       *sql.Stmt
+	deleteExpiredLastfmScrobblesStmt       *sql.Stmt
+	deleteGenreStmt                        *sql.Stmt
+	deleteLastfmScrobbleStmt               *sql.Stmt
+	deleteLastfmScrobblesByUserStmt        *sql.Stmt
+	deleteLastfmSessionStmt                *sql.Stmt
+	deleteListenbrainzAccountStmt          *sql.Stmt
+	deleteListenbrainzListenStmt           *sql.Stmt
+	deleteListenbrainzListensByUserStmt    *sql.Stmt
+	deleteMediaVersionAudioStreamsStmt     *sql.Stmt
+	deleteMediaVersionByFilePathStmt       *sql.Stmt
+	deleteMediaVersionChaptersStmt         *sql.Stmt
+	deleteMediaVersionSubtitlesStmt        *sql.Stmt
+	deleteMediaVersionVideoStreamsStmt     *sql.Stmt
+	deleteMovieStmt                        *sql.Stmt
+	deleteMovieCollectionStmt              *sql.Stmt
 	deleteMovieExtraVideosStmt             *sql.Stmt
+	deleteMovieGenreStmt                   *sql.Stmt
 	deleteMovieGenresStmt                  *sql.Stmt
 	deleteMovieProductionCompaniesStmt     *sql.Stmt
-	deleteMovieSubtitlesStmt               *sql.Stmt
-	deleteMovieVideoStreamsStmt            *sql.Stmt
+	deleteMovieProductionCompanyStmt       *sql.Stmt
+	deleteMusicianGenresStmt               *sql.Stmt
 	deletePlaylistStmt                     *sql.Stmt
+	deletePodcastStmt                      *sql.Stmt
+	deleteSampleFilesByLibraryStmt         *sql.Stmt
+	deleteScanErrorStmt                    *sql.Stmt
+	deleteScannedLyricsStmt                *sql.Stmt
+	deleteStaleAudiobookFilesStmt          *sql.Stmt
+	deleteStaleCueTracksStmt               *sql.Stmt
+	deleteTrackByFilePathStmt              *sql.Stmt
 	deleteTrackGenresStmt                  *sql.Stmt
-	deleteTrackGenresExceptStmt            *sql.Stmt
 	deleteUserStmt                         *sql.Stmt
+	fillMovieMetadataStmt                  *sql.Stmt
 	getAdminUserStmt                       *sql.Stmt
+	getAlbumByDirectoryStmt                *sql.Stmt
 	getAlbumByIDStmt                       *sql.Stmt
+	getAlbumByMusicbrainzIDStmt            *sql.Stmt
+	getAlbumByScanTitleStmt                *sql.Stmt
 	getAlbumBySpotifyIDStmt                *sql.Stmt
 	getAlbumsAlphabeticalStmt              *sql.Stmt
 	getAlbumsByMusicianIDStmt              *sql.Stmt
+	getAlbumsByTitleStmt                   *sql.Stmt
 	getAlbumsCountStmt                     *sql.Stmt
 	getAllPlaylistTracksStmt               *sql.Stmt
 	getAllTrackPathsAndSizesStmt           *sql.Stmt
+	getAllTrackScrobbleInfoStmt            *sql.Stmt
+	getApiCacheEntryStmt                   *sql.Stmt
+	getArtistByIDStmt                      *sql.Stmt
+	getAudioStreamsByMediaVersionIDStmt    *sql.Stmt
+	getAudiobookBookmarksStmt              *sql.Stmt
+	getAudiobookByIDStmt                   *sql.Stmt
+	getAudiobookChapterStmt                *sql.Stmt
+	getAudiobookChaptersStmt               *sql.Stmt
+	getAudiobookFileStmt                   *sql.Stmt
+	getAudiobookFilesStmt                  *sql.Stmt
+	getAudiobookProgressStmt               *sql.Stmt
+	getAudiobooksAlphabeticalStmt          *sql.Stmt
+	getAudiobooksByAuthorStmt              *sql.Stmt
+	getAudiobooksByDirectoryStmt           *sql.Stmt
+	getAudiobooksCountStmt                 *sql.Stmt
+	getAudiobooksInProgressStmt            *sql.Stmt
+	getAuthorByIDStmt                      *sql.Stmt
+	getAuthorsAlphabeticalStmt             *sql.Stmt
+	getAuthorsCountStmt                    *sql.Stmt
+	getCastByArtistIDStmt                  *sql.Stmt
 	getCastByMovieIDStmt                   *sql.Stmt
+	getCollectionByIDStmt                  *sql.Stmt
+	getCollectionMoviesStmt                *sql.Stmt
+	getCollectionsStmt                     *sql.Stmt
+	getCollectionsCountStmt                *sql.Stmt
+	getCrewByArtistIDStmt                  *sql.Stmt
 	getCrewByMovieIDStmt                   *sql.Stmt
+	getDownloadedPodcastEpisodesStmt       *sql.Stmt
+	getDueLastfmScrobblesStmt              *sql.Stmt
+	getDueListenbrainzListensStmt          *sql.Stmt
+	getFilteredAlbumsCountStmt             *sql.Stmt
+	getGenreAliasesByTypeStmt              *sql.Stmt
+	getGenreByAliasStmt                    *sql.Stmt
+	getGenreByIDStmt                       *sql.Stmt
 	getGenresByAlbumIDStmt                 *sql.Stmt
 	getGenresByAlbumIDDirectStmt           *sql.Stmt
 	getGenresByMovieIDStmt                 *sql.Stmt
 	getGenresByMusicianIDStmt              *sql.Stmt
+	getGenresWithCountsStmt                *sql.Stmt
+	getLastfmSessionStmt                   *sql.Stmt
 	getLatestAlbumsStmt                    *sql.Stmt
 	getLatestMoviesStmt                    *sql.Stmt
 	getLikedTrackIDsByUserIDStmt           *sql.Stmt
 	getLikedTracksByUserIDStmt             *sql.Stmt
+	getListenbrainzAccountStmt             *sql.Stmt
+	getLocalExtraByIDStmt                  *sql.Stmt
+	getLocalExtrasByMovieIDStmt            *sql.Stmt
 	getMaxPositionStmt                     *sql.Stmt
+	getMediaVersionByFilePathStmt          *sql.Stmt
+	getMediaVersionByIDStmt                *sql.Stmt
+	getMediaVersionsByDirectoryStmt        *sql.Stmt
+	getMediaVersionsByMovieIDStmt          *sql.Stmt
+	getMissingCollectionPartsStmt          *sql.Stmt
 	getMovieByFilePathStmt                 *sql.Stmt
 	getMovieByIDStmt                       *sql.Stmt
+	getMovieByTitleAndYearStmt             *sql.Stmt
 	getMovieByTmdbIDStmt                   *sql.Stmt
 	getMovieExtraVideosStmt                *sql.Stmt
+	getMoviesWithoutCollectionStmt         *sql.Stmt
 	getMusicianByIDStmt                    *sql.Stmt
+	getMusicianByMusicbrainzIDStmt         *sql.Stmt
+	getMusicianByScanNameStmt              *sql.Stmt
 	getMusicianBySpotifyIDStmt             *sql.Stmt
 	getMusiciansAlphabeticalStmt           *sql.Stmt
 	getMusiciansByAlbumIDStmt              *sql.Stmt
+	getMusiciansByNameStmt                 *sql.Stmt
 	getMusiciansCountStmt                  *sql.Stmt
 	getOrCreateGenreStmt                   *sql.Stmt
 	getPlaylistByIdStmt                    *sql.Stmt
@@ -1043,24 +2563,46 @@ type Queries struct {
 	getPlaylistTracksInfiniteStmt          *sql.Stmt
 	getPlaylistsByUserIdStmt               *sql.Stmt
 	getPlaylistsWithCollaboratorAccessStmt *sql.Stmt
+	getPodcastByFeedURLStmt                *sql.Stmt
+	getPodcastByIDStmt                     *sql.Stmt
+	getPodcastEpisodeByIDStmt              *sql.Stmt
+	getPodcastEpisodesStmt                 *sql.Stmt
+	getPodcastEpisodesCountStmt            *sql.Stmt
+	getPodcastEpisodesToDownloadStmt       *sql.Stmt
+	getPodcastsStmt                        *sql.Stmt
+	getPodcastsDueForPollStmt              *sql.Stmt
 	getProductionCompaniesByMovieIDStmt    *sql.Stmt
 	getRandomTracksStmt                    *sql.Stmt
+	getScanErrorByIDStmt                   *sql.Stmt
+	getScanErrorsStmt                      *sql.Stmt
+	getScanErrorsCountStmt                 *sql.Stmt
 	getSettingsStmt                        *sql.Stmt
+	getStaleMoviesStmt                     *sql.Stmt
+	getStaleMusiciansStmt                  *sql.Stmt
+	getSubtitlesByMediaVersionIDStmt       *sql.Stmt
 	getTrackStmt                           *sql.Stmt
+	getTrackLyricsStmt                     *sql.Stmt
+	getTrackScrobbleInfoStmt               *sql.Stmt
 	getTracksAlphabeticalStmt              *sql.Stmt
 	getTracksByAlbumIDStmt                 *sql.Stmt
 	getTracksByMusicianIDStmt              *sql.Stmt
 	getTracksCountStmt                     *sql.Stmt
+	getUnaliasedGenresStmt                 *sql.Stmt
+	getUnmatchedAlbumStmt                  *sql.Stmt
+	getUnmatchedMusicianByNameStmt         *sql.Stmt
 	getUserStmt                            *sql.Stmt
 	getUserByEmailStmt                     *sql.Stmt
 	getUserListeningHistoryByPeriodStmt    *sql.Stmt
 	getUserListeningStatsStmt              *sql.Stmt
+	getUserPlayTimesStmt                   *sql.Stmt
 	getUserRecentlyPlayedStmt              *sql.Stmt
 	getUserTopAlbumsStmt                   *sql.Stmt
 	getUserTopGenresStmt                   *sql.Stmt
 	getUserTopMusiciansStmt                *sql.Stmt
 	getUserTopTracksStmt                   *sql.Stmt
 	getUserTrackPlayCountStmt              *sql.Stmt
+	getVideoStreamsByMediaVersionIDStmt    *sql.Stmt
+	importPlayEventStmt                    *sql.Stmt
 	insertAudioStreamStmt                  *sql.Stmt
 	insertChapterStmt                      *sql.Stmt
 	insertSubtitleStmt                     *sql.Stmt
@@ -1069,30 +2611,90 @@ type Queries struct {
 	isTrackLikedStmt                       *sql.Stmt
 	isUserCollaboratorStmt                 *sql.Stmt
 	likeTrackStmt                          *sql.Stmt
+	markAlbumCompilationStmt               *sql.Stmt
+	mergeAlbumGenresStmt                   *sql.Stmt
+	mergeAudioStreamsStmt                  *sql.Stmt
+	mergeChaptersStmt                      *sql.Stmt
+	mergeGenreAliasesStmt                  *sql.Stmt
+	mergeLocalExtrasStmt                   *sql.Stmt
+	mergeMediaVersionsStmt                 *sql.Stmt
+	mergeMovieGenresStmt                   *sql.Stmt
+	mergeMusicianGenresStmt                *sql.Stmt
+	mergeSubtitlesStmt                     *sql.Stmt
+	mergeTrackGenresStmt                   *sql.Stmt
+	mergeVideoStreamsStmt                  *sql.Stmt
+	rebuildUserTrackStatsStmt              *sql.Stmt
 	recordPlayEventStmt                    *sql.Stmt
+	recordSampleFileStmt                   *sql.Stmt
+	recordScanErrorStmt                    *sql.Stmt
+	refreshMovieMetadataStmt               *sql.Stmt
+	refreshMusicianMetadataStmt            *sql.Stmt
 	removeCollaboratorStmt                 *sql.Stmt
 	removeTrackFromPlaylistStmt            *sql.Stmt
+	retryLastfmScrobbleStmt                *sql.Stmt
+	retryListenbrainzListenStmt            *sql.Stmt
+	searchArtistsStmt                      *sql.Stmt
+	searchArtistsCountStmt                 *sql.Stmt
+	setAlbumDirectoryStmt                  *sql.Stmt
+	setAlbumMusicbrainzIDsStmt             *sql.Stmt
+	setCollectionsBackfilledStmt           *sql.Stmt
+	setMovieCollectionStmt                 *sql.Stmt
+	setMusicianMusicbrainzIDStmt           *sql.Stmt
+	setPodcastEpisodeFileStmt              *sql.Stmt
+	setTrackLyricsScannedStmt              *sql.Stmt
 	shiftPositionsDownStmt                 *sql.Stmt
 	shiftPositionsUpStmt                   *sql.Stmt
+	touchMovieMetadataRefreshedStmt        *sql.Stmt
+	touchMusicianMetadataRefreshedStmt     *sql.Stmt
 	unlikeTrackStmt                        *sql.Stmt
+	updateAlbumMetadataStmt                *sql.Stmt
+	updateAlbumSpotifyMatchStmt            *sql.Stmt
 	updateCollaboratorPermissionStmt       *sql.Stmt
+	updateListenbrainzImportStmt           *sql.Stmt
+	updateMetadataLanguageSettingsStmt     *sql.Stmt
+	updateMetadataRefreshSettingsStmt      *sql.Stmt
+	updateMovieFileStmt                    *sql.Stmt
+	updateMovieMatchStmt                   *sql.Stmt
+	updateMovieMetadataStmt                *sql.Stmt
+	updateMusicianMetadataStmt             *sql.Stmt
 	updatePlaylistStmt                     *sql.Stmt
 	updatePlaylistTimestampStmt            *sql.Stmt
+	updatePodcastDownloadSettingsStmt      *sql.Stmt
+	updatePodcastFeedStmt                  *sql.Stmt
+	updatePodcastPolledStmt                *sql.Stmt
+	updatePodcastSettingsStmt              *sql.Stmt
+	updateScannerSettingsStmt              *sql.Stmt
+	updateTrackMetadataStmt                *sql.Stmt
 	updateTrackPositionStmt                *sql.Stmt
 	updateUserAvatarStmt                   *sql.Stmt
 	updateUserNameStmt                     *sql.Stmt
 	updateUserPasswordStmt                 *sql.Stmt
 	upsertAlbumStmt                        *sql.Stmt
 	upsertAlbumGenreStmt                   *sql.Stmt
+	upsertApiCacheEntryStmt                *sql.Stmt
 	upsertArtistStmt                       *sql.Stmt
+	upsertAudiobookStmt                    *sql.Stmt
+	upsertAudiobookFileStmt                *sql.Stmt
+	upsertAudiobookProgressStmt            *sql.Stmt
+	upsertAuthorStmt                       *sql.Stmt
 	upsertCastStmt                         *sql.Stmt
+	upsertCollectionStmt                   *sql.Stmt
 	upsertCrewStmt                         *sql.Stmt
 	upsertExtraVideoStmt                   *sql.Stmt
+	upsertGenreAliasStmt                   *sql.Stmt
+	upsertLastfmSessionStmt                *sql.Stmt
+	upsertListenbrainzAccountStmt          *sql.Stmt
+	upsertLocalExtraStmt                   *sql.Stmt
+	upsertMediaVersionStmt                 *sql.Stmt
 	upsertMovieStmt                        *sql.Stmt
 	upsertMusicianStmt                     *sql.Stmt
 	upsertMusicianGenreStmt                *sql.Stmt
+	upsertPodcastEpisodeStmt               *sql.Stmt
+	upsertPodcastEpisodeProgressStmt       *sql.Stmt
 	upsertProductionCompanyStmt            *sql.Stmt
+	upsertScannedLyricsStmt                *sql.Stmt
 	upsertTrackStmt                        *sql.Stmt
+	upsertUserLyricsStmt                   *sql.Stmt
 	upsertUserTrackStatsStmt               *sql.Stmt
 }
 
@@ -1102,59 +2704,155 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		tx:                                     tx,
 		addCollaboratorStmt:                    q.addCollaboratorStmt,
 		addTrackToPlaylistStmt:                 q.addTrackToPlaylistStmt,
+		backfillLibraryDirsStmt:                q.backfillLibraryDirsStmt,
 		canUserEditPlaylistStmt:                q.canUserEditPlaylistStmt,
+		checkAudiobookUnchangedStmt:            q.checkAudiobookUnchangedStmt,
+		checkCueTracksUnchangedStmt:            q.checkCueTracksUnchangedStmt,
+		checkLocalExtraUnchangedStmt:           q.checkLocalExtraUnchangedStmt,
+		checkLyricsUnchangedStmt:               q.checkLyricsUnchangedStmt,
 		checkMovieUnchangedStmt:                q.checkMovieUnchangedStmt,
+		checkSampleFileUnchangedStmt:           q.checkSampleFileUnchangedStmt,
 		checkTrackUnchangedStmt:                q.checkTrackUnchangedStmt,
 		clearPlaylistStmt:                      q.clearPlaylistStmt,
+		clearPodcastEpisodeFileStmt:            q.clearPodcastEpisodeFileStmt,
+		countLastfmScrobblesStmt:               q.countLastfmScrobblesStmt,
+		countListenbrainzListensStmt:           q.countListenbrainzListensStmt,
 		countPlaylistTracksStmt:                q.countPlaylistTracksStmt,
 		countPlaylistsByUserIdStmt:             q.countPlaylistsByUserIdStmt,
+		createAudiobookBookmarkStmt:            q.createAudiobookBookmarkStmt,
+		createAudiobookChapterStmt:             q.createAudiobookChapterStmt,
+		createCollectionPartStmt:               q.createCollectionPartStmt,
+		createLastfmScrobbleStmt:               q.createLastfmScrobbleStmt,
+		createListenbrainzListenStmt:           q.createListenbrainzListenStmt,
 		createMovieExtraVideoStmt:              q.createMovieExtraVideoStmt,
 		createMovieGenreStmt:                   q.createMovieGenreStmt,
 		createMovieProductionCompanyStmt:       q.createMovieProductionCompanyStmt,
 		createMusicianAlbumStmt:                q.createMusicianAlbumStmt,
 		createPlaylistStmt:                     q.createPlaylistStmt,
+		createPodcastStmt:                      q.createPodcastStmt,
 		createSettingsStmt:                     q.createSettingsStmt,
 		createTrackGenreStmt:                   q.createTrackGenreStmt,
 		createUserStmt:                         q.createUserStmt,
 		deleteAlbumStmt:                        q.deleteAlbumStmt,
-		deleteMovieAudioStreamsStmt:            q.deleteMovieAudioStreamsStmt,
-		deleteMovieChaptersStmt:                q.deleteMovieChaptersStmt,
+		deleteAlbumGenresStmt:                  q.deleteAlbumGenresStmt,
+		deleteAudiobookStmt:                    q.deleteAudiobookStmt,
+		deleteAudiobookBookmarkStmt:            q.deleteAudiobookBookmarkStmt,
+		deleteAudiobookChaptersStmt:            q.deleteAudiobookChaptersStmt,
+		deleteCastMemberStmt:                   q.deleteCastMemberStmt,
+		deleteCollectionPartsStmt:              q.deleteCollectionPartsStmt,
+		deleteCrewMemberStmt:                   q.deleteCrewMemberStmt,
+		deleteCueTracksStmt:                    q.deleteCueTracksStmt,
+		deleteExpiredApiCacheEntriesStmt:       q.deleteExpiredApiCacheEntriesStmt,
+		deleteExpiredLastfmScrobblesStmt:       q.deleteExpiredLastfmScrobblesStmt,
+		deleteGenreStmt:                        q.deleteGenreStmt,
+		deleteLastfmScrobbleStmt:               q.deleteLastfmScrobbleStmt,
+		deleteLastfmScrobblesByUserStmt:        q.deleteLastfmScrobblesByUserStmt,
+		deleteLastfmSessionStmt:                q.deleteLastfmSessionStmt,
+		deleteListenbrainzAccountStmt:          q.deleteListenbrainzAccountStmt,
+		deleteListenbrainzListenStmt:           q.deleteListenbrainzListenStmt,
+		deleteListenbrainzListensByUserStmt:    q.deleteListenbrainzListensByUserStmt,
+		deleteMediaVersionAudioStreamsStmt:     q.deleteMediaVersionAudioStreamsStmt,
+		deleteMediaVersionByFilePathStmt:       q.deleteMediaVersionByFilePathStmt,
+		deleteMediaVersionChaptersStmt:         q.deleteMediaVersionChaptersStmt,
+		deleteMediaVersionSubtitlesStmt:        q.deleteMediaVersionSubtitlesStmt,
+		deleteMediaVersionVideoStreamsStmt:     q.deleteMediaVersionVideoStreamsStmt,
+		deleteMovieStmt:                        q.deleteMovieStmt,
+		deleteMovieCollectionStmt:              q.deleteMovieCollectionStmt,
 		deleteMovieExtraVideosStmt:             q.deleteMovieExtraVideosStmt,
+		deleteMovieGenreStmt:                   q.deleteMovieGenreStmt,
 		deleteMovieGenresStmt:                  q.deleteMovieGenresStmt,
 		deleteMovieProductionCompaniesStmt:     q.deleteMovieProductionCompaniesStmt,
-		deleteMovieSubtitlesStmt:               q.deleteMovieSubtitlesStmt,
-		deleteMovieVideoStreamsStmt:            q.deleteMovieVideoStreamsStmt,
+		deleteMovieProductionCompanyStmt:       q.deleteMovieProductionCompanyStmt,
+		deleteMusicianGenresStmt:               q.deleteMusicianGenresStmt,
 		deletePlaylistStmt:                     q.deletePlaylistStmt,
+		deletePodcastStmt:                      q.deletePodcastStmt,
+		deleteSampleFilesByLibraryStmt:         q.deleteSampleFilesByLibraryStmt,
+		deleteScanErrorStmt:                    q.deleteScanErrorStmt,
+		deleteScannedLyricsStmt:                q.deleteScannedLyricsStmt,
+		deleteStaleAudiobookFilesStmt:          q.deleteStaleAudiobookFilesStmt,
+		deleteStaleCueTracksStmt:               q.deleteStaleCueTracksStmt,
+		deleteTrackByFilePathStmt:              q.deleteTrackByFilePathStmt,
 		deleteTrackGenresStmt:                  q.deleteTrackGenresStmt,
-		deleteTrackGenresExceptStmt:            q.deleteTrackGenresExceptStmt,
 		deleteUserStmt:                         q.deleteUserStmt,
+		fillMovieMetadataStmt:                  q.fillMovieMetadataStmt,
 		getAdminUserStmt:                       q.getAdminUserStmt,
+		getAlbumByDirectoryStmt:                q.getAlbumByDirectoryStmt,
 		getAlbumByIDStmt:                       q.getAlbumByIDStmt,
+		getAlbumByMusicbrainzIDStmt:            q.getAlbumByMusicbrainzIDStmt,
+		getAlbumByScanTitleStmt:                q.getAlbumByScanTitleStmt,
 		getAlbumBySpotifyIDStmt:                q.getAlbumBySpotifyIDStmt,
 		getAlbumsAlphabeticalStmt:              q.getAlbumsAlphabeticalStmt,
 		getAlbumsByMusicianIDStmt:              q.getAlbumsByMusicianIDStmt,
+		getAlbumsByTitleStmt:                   q.getAlbumsByTitleStmt,
 		getAlbumsCountStmt:                     q.getAlbumsCountStmt,
 		getAllPlaylistTracksStmt:               q.getAllPlaylistTracksStmt,
 		getAllTrackPathsAndSizesStmt:           q.getAllTrackPathsAndSizesStmt,
+		getAllTrackScrobbleInfoStmt:            q.getAllTrackScrobbleInfoStmt,
+		getApiCacheEntryStmt:                   q.getApiCacheEntryStmt,
+		getArtistByIDStmt:                      q.getArtistByIDStmt,
+		getAudioStreamsByMediaVersionIDStmt:    q.getAudioStreamsByMediaVersionIDStmt,
+		getAudiobookBookmarksStmt:              q.getAudiobookBookmarksStmt,
+		getAudiobookByIDStmt:                   q.getAudiobookByIDStmt,
+		getAudiobookChapterStmt:                q.getAudiobookChapterStmt,
+		getAudiobookChaptersStmt:               q.getAudiobookChaptersStmt,
+		getAudiobookFileStmt:                   q.getAudiobookFileStmt,
+		getAudiobookFilesStmt:                  q.getAudiobookFilesStmt,
+		getAudiobookProgressStmt:               q.getAudiobookProgressStmt,
+		getAudiobooksAlphabeticalStmt:          q.getAudiobooksAlphabeticalStmt,
+		getAudiobooksByAuthorStmt:              q.getAudiobooksByAuthorStmt,
+		getAudiobooksByDirectoryStmt:           q.getAudiobooksByDirectoryStmt,
+		getAudiobooksCountStmt:                 q.getAudiobooksCountStmt,
+		getAudiobooksInProgressStmt:            q.getAudiobooksInProgressStmt,
+		getAuthorByIDStmt:                      q.getAuthorByIDStmt,
+		getAuthorsAlphabeticalStmt:             q.getAuthorsAlphabeticalStmt,
+		getAuthorsCountStmt:                    q.getAuthorsCountStmt,
+		getCastByArtistIDStmt:                  q.getCastByArtistIDStmt,
 		getCastByMovieIDStmt:                   q.getCastByMovieIDStmt,
+		getCollectionByIDStmt:                  q.getCollectionByIDStmt,
+		getCollectionMoviesStmt:                q.getCollectionMoviesStmt,
+		getCollectionsStmt:                     q.getCollectionsStmt,
+		getCollectionsCountStmt:                q.getCollectionsCountStmt,
+		getCrewByArtistIDStmt:                  q.getCrewByArtistIDStmt,
 		getCrewByMovieIDStmt:                   q.getCrewByMovieIDStmt,
+		getDownloadedPodcastEpisodesStmt:       q.getDownloadedPodcastEpisodesStmt,
+		getDueLastfmScrobblesStmt:              q.getDueLastfmScrobblesStmt,
+		getDueListenbrainzListensStmt:          q.getDueListenbrainzListensStmt,
+		getFilteredAlbumsCountStmt:             q.getFilteredAlbumsCountStmt,
+		getGenreAliasesByTypeStmt:              q.getGenreAliasesByTypeStmt,
+		getGenreByAliasStmt:                    q.getGenreByAliasStmt,
+		getGenreByIDStmt:                       q.getGenreByIDStmt,
 		getGenresByAlbumIDStmt:                 q.getGenresByAlbumIDStmt,
 		getGenresByAlbumIDDirectStmt:           q.getGenresByAlbumIDDirectStmt,
 		getGenresByMovieIDStmt:                 q.getGenresByMovieIDStmt,
 		getGenresByMusicianIDStmt:              q.getGenresByMusicianIDStmt,
+		getGenresWithCountsStmt:                q.getGenresWithCountsStmt,
+		getLastfmSessionStmt:                   q.getLastfmSessionStmt,
 		getLatestAlbumsStmt:                    q.getLatestAlbumsStmt,
 		getLatestMoviesStmt:                    q.getLatestMoviesStmt,
 		getLikedTrackIDsByUserIDStmt:           q.getLikedTrackIDsByUserIDStmt,
 		getLikedTracksByUserIDStmt:             q.getLikedTracksByUserIDStmt,
+		getListenbrainzAccountStmt:             q.getListenbrainzAccountStmt,
+		getLocalExtraByIDStmt:                  q.getLocalExtraByIDStmt,
+		getLocalExtrasByMovieIDStmt:            q.getLocalExtrasByMovieIDStmt,
 		getMaxPositionStmt:                     q.getMaxPositionStmt,
+		getMediaVersionByFilePathStmt:          q.getMediaVersionByFilePathStmt,
+		getMediaVersionByIDStmt:                q.getMediaVersionByIDStmt,
+		getMediaVersionsByDirectoryStmt:        q.getMediaVersionsByDirectoryStmt,
+		getMediaVersionsByMovieIDStmt:          q.getMediaVersionsByMovieIDStmt,
+		getMissingCollectionPartsStmt:          q.getMissingCollectionPartsStmt,
 		getMovieByFilePathStmt:                 q.getMovieByFilePathStmt,
 		getMovieByIDStmt:                       q.getMovieByIDStmt,
+		getMovieByTitleAndYearStmt:             q.getMovieByTitleAndYearStmt,
 		getMovieByTmdbIDStmt:                   q.getMovieByTmdbIDStmt,
 		getMovieExtraVideosStmt:                q.getMovieExtraVideosStmt,
+		getMoviesWithoutCollectionStmt:         q.getMoviesWithoutCollectionStmt,
 		getMusicianByIDStmt:                    q.getMusicianByIDStmt,
+		getMusicianByMusicbrainzIDStmt:         q.getMusicianByMusicbrainzIDStmt,
+		getMusicianByScanNameStmt:              q.getMusicianByScanNameStmt,
 		getMusicianBySpotifyIDStmt:             q.getMusicianBySpotifyIDStmt,
 		getMusiciansAlphabeticalStmt:           q.getMusiciansAlphabeticalStmt,
 		getMusiciansByAlbumIDStmt:              q.getMusiciansByAlbumIDStmt,
+		getMusiciansByNameStmt:                 q.getMusiciansByNameStmt,
 		getMusiciansCountStmt:                  q.getMusiciansCountStmt,
 		getOrCreateGenreStmt:                   q.getOrCreateGenreStmt,
 		getPlaylistByIdStmt:                    q.getPlaylistByIdStmt,
@@ -1163,24 +2861,46 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getPlaylistTracksInfiniteStmt:          q.getPlaylistTracksInfiniteStmt,
 		getPlaylistsByUserIdStmt:               q.getPlaylistsByUserIdStmt,
 		getPlaylistsWithCollaboratorAccessStmt: q.getPlaylistsWithCollaboratorAccessStmt,
+		getPodcastByFeedURLStmt:                q.getPodcastByFeedURLStmt,
+		getPodcastByIDStmt:                     q.getPodcastByIDStmt,
+		getPodcastEpisodeByIDStmt:              q.getPodcastEpisodeByIDStmt,
+		getPodcastEpisodesStmt:                 q.getPodcastEpisodesStmt,
+		getPodcastEpisodesCountStmt:            q.getPodcastEpisodesCountStmt,
+		getPodcastEpisodesToDownloadStmt:       q.getPodcastEpisodesToDownloadStmt,
+		getPodcastsStmt:                        q.getPodcastsStmt,
+		getPodcastsDueForPollStmt:              q.getPodcastsDueForPollStmt,
 		getProductionCompaniesByMovieIDStmt:    q.getProductionCompaniesByMovieIDStmt,
 		getRandomTracksStmt:                    q.getRandomTracksStmt,
+		getScanErrorByIDStmt:                   q.getScanErrorByIDStmt,
+		getScanErrorsStmt:                      q.getScanErrorsStmt,
+		getScanErrorsCountStmt:                 q.getScanErrorsCountStmt,
 		getSettingsStmt:                        q.getSettingsStmt,
+		getStaleMoviesStmt:                     q.getStaleMoviesStmt,
+		getStaleMusiciansStmt:                  q.getStaleMusiciansStmt,
+		getSubtitlesByMediaVersionIDStmt:       q.getSubtitlesByMediaVersionIDStmt,
 		getTrackStmt:                           q.getTrackStmt,
+		getTrackLyricsStmt:                     q.getTrackLyricsStmt,
+		getTrackScrobbleInfoStmt:               q.getTrackScrobbleInfoStmt,
 		getTracksAlphabeticalStmt:              q.getTracksAlphabeticalStmt,
 		getTracksByAlbumIDStmt:                 q.getTracksByAlbumIDStmt,
 		getTracksByMusicianIDStmt:              q.getTracksByMusicianIDStmt,
 		getTracksCountStmt:                     q.getTracksCountStmt,
+		getUnaliasedGenresStmt:                 q.getUnaliasedGenresStmt,
+		getUnmatchedAlbumStmt:                  q.getUnmatchedAlbumStmt,
+		getUnmatchedMusicianByNameStmt:         q.getUnmatchedMusicianByNameStmt,
 		getUserStmt:                            q.getUserStmt,
 		getUserByEmailStmt:                     q.getUserByEmailStmt,
 		getUserListeningHistoryByPeriodStmt:    q.getUserListeningHistoryByPeriodStmt,
 		getUserListeningStatsStmt:              q.getUserListeningStatsStmt,
+		getUserPlayTimesStmt:                   q.getUserPlayTimesStmt,
 		getUserRecentlyPlayedStmt:              q.getUserRecentlyPlayedStmt,
 		getUserTopAlbumsStmt:                   q.getUserTopAlbumsStmt,
 		getUserTopGenresStmt:                   q.getUserTopGenresStmt,
 		getUserTopMusiciansStmt:                q.getUserTopMusiciansStmt,
 		getUserTopTracksStmt:                   q.getUserTopTracksStmt,
 		getUserTrackPlayCountStmt:              q.getUserTrackPlayCountStmt,
+		getVideoStreamsByMediaVersionIDStmt:    q.getVideoStreamsByMediaVersionIDStmt,
+		importPlayEventStmt:                    q.importPlayEventStmt,
 		insertAudioStreamStmt:                  q.insertAudioStreamStmt,
 		insertChapterStmt:                      q.insertChapterStmt,
 		insertSubtitleStmt:                     q.insertSubtitleStmt,
@@ -1189,30 +2909,90 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		isTrackLikedStmt:                       q.isTrackLikedStmt,
 		isUserCollaboratorStmt:                 q.isUserCollaboratorStmt,
 		likeTrackStmt:                          q.likeTrackStmt,
+		markAlbumCompilationStmt:               q.markAlbumCompilationStmt,
+		mergeAlbumGenresStmt:                   q.mergeAlbumGenresStmt,
+		mergeAudioStreamsStmt:                  q.mergeAudioStreamsStmt,
+		mergeChaptersStmt:                      q.mergeChaptersStmt,
+		mergeGenreAliasesStmt:                  q.mergeGenreAliasesStmt,
+		mergeLocalExtrasStmt:                   q.mergeLocalExtrasStmt,
+		mergeMediaVersionsStmt:                 q.mergeMediaVersionsStmt,
+		mergeMovieGenresStmt:                   q.mergeMovieGenresStmt,
+		mergeMusicianGenresStmt:                q.mergeMusicianGenresStmt,
+		mergeSubtitlesStmt:                     q.mergeSubtitlesStmt,
+		mergeTrackGenresStmt:                   q.mergeTrackGenresStmt,
+		mergeVideoStreamsStmt:                  q.mergeVideoStreamsStmt,
+		rebuildUserTrackStatsStmt:              q.rebuildUserTrackStatsStmt,
 		recordPlayEventStmt:                    q.recordPlayEventStmt,
+		recordSampleFileStmt:                   q.recordSampleFileStmt,
+		recordScanErrorStmt:                    q.recordScanErrorStmt,
+		refreshMovieMetadataStmt:               q.refreshMovieMetadataStmt,
+		refreshMusicianMetadataStmt:            q.refreshMusicianMetadataStmt,
 		removeCollaboratorStmt:                 q.removeCollaboratorStmt,
 		removeTrackFromPlaylistStmt:            q.removeTrackFromPlaylistStmt,
+		retryLastfmScrobbleStmt:                q.retryLastfmScrobbleStmt,
+		retryListenbrainzListenStmt:            q.retryListenbrainzListenStmt,
+		searchArtistsStmt:                      q.searchArtistsStmt,
+		searchArtistsCountStmt:                 q.searchArtistsCountStmt,
+		setAlbumDirectoryStmt:                  q.setAlbumDirectoryStmt,
+		setAlbumMusicbrainzIDsStmt:             q.setAlbumMusicbrainzIDsStmt,
+		setCollectionsBackfilledStmt:           q.setCollectionsBackfilledStmt,
+		setMovieCollectionStmt:                 q.setMovieCollectionStmt,
+		setMusicianMusicbrainzIDStmt:           q.setMusicianMusicbrainzIDStmt,
+		setPodcastEpisodeFileStmt:              q.setPodcastEpisodeFileStmt,
+		setTrackLyricsScannedStmt:              q.setTrackLyricsScannedStmt,
 		shiftPositionsDownStmt:                 q.shiftPositionsDownStmt,
 		shiftPositionsUpStmt:                   q.shiftPositionsUpStmt,
+		touchMovieMetadataRefreshedStmt:        q.touchMovieMetadataRefreshedStmt,
+		touchMusicianMetadataRefreshedStmt:     q.touchMusicianMetadataRefreshedStmt,
 		unlikeTrackStmt:                        q.unlikeTrackStmt,
+		updateAlbumMetadataStmt:                q.updateAlbumMetadataStmt,
+		updateAlbumSpotifyMatchStmt:            q.updateAlbumSpotifyMatchStmt,
 		updateCollaboratorPermissionStmt:       q.updateCollaboratorPermissionStmt,
+		updateListenbrainzImportStmt:           q.updateListenbrainzImportStmt,
+		updateMetadataLanguageSettingsStmt:     q.updateMetadataLanguageSettingsStmt,
+		updateMetadataRefreshSettingsStmt:      q.updateMetadataRefreshSettingsStmt,
+		updateMovieFileStmt:                    q.updateMovieFileStmt,
+		updateMovieMatchStmt:                   q.updateMovieMatchStmt,
+		updateMovieMetadataStmt:                q.updateMovieMetadataStmt,
+		updateMusicianMetadataStmt:             q.updateMusicianMetadataStmt,
 		updatePlaylistStmt:                     q.updatePlaylistStmt,
 		updatePlaylistTimestampStmt:            q.updatePlaylistTimestampStmt,
+		updatePodcastDownloadSettingsStmt:      q.updatePodcastDownloadSettingsStmt,
+		updatePodcastFeedStmt:                  q.updatePodcastFeedStmt,
+		updatePodcastPolledStmt:                q.updatePodcastPolledStmt,
+		updatePodcastSettingsStmt:              q.updatePodcastSettingsStmt,
+		updateScannerSettingsStmt:              q.updateScannerSettingsStmt,
+		updateTrackMetadataStmt:                q.updateTrackMetadataStmt,
 		updateTrackPositionStmt:                q.updateTrackPositionStmt,
 		updateUserAvatarStmt:                   q.updateUserAvatarStmt,
 		updateUserNameStmt:                     q.updateUserNameStmt,
 		updateUserPasswordStmt:                 q.updateUserPasswordStmt,
 		upsertAlbumStmt:                        q.upsertAlbumStmt,
 		upsertAlbumGenreStmt:                   q.upsertAlbumGenreStmt,
+		upsertApiCacheEntryStmt:                q.upsertApiCacheEntryStmt,
 		upsertArtistStmt:                       q.upsertArtistStmt,
+		upsertAudiobookStmt:                    q.upsertAudiobookStmt,
+		upsertAudiobookFileStmt:                q.upsertAudiobookFileStmt,
+		upsertAudiobookProgressStmt:            q.upsertAudiobookProgressStmt,
+		upsertAuthorStmt:                       q.upsertAuthorStmt,
 		upsertCastStmt:                         q.upsertCastStmt,
+		upsertCollectionStmt:                   q.upsertCollectionStmt,
 		upsertCrewStmt:                         q.upsertCrewStmt,
 		upsertExtraVideoStmt:                   q.upsertExtraVideoStmt,
+		upsertGenreAliasStmt:                   q.upsertGenreAliasStmt,
+		upsertLastfmSessionStmt:                q.upsertLastfmSessionStmt,
+		upsertListenbrainzAccountStmt:          q.upsertListenbrainzAccountStmt,
+		upsertLocalExtraStmt:                   q.upsertLocalExtraStmt,
+		upsertMediaVersionStmt:                 q.upsertMediaVersionStmt,
 		upsertMovieStmt:                        q.upsertMovieStmt,
 		upsertMusicianStmt:                     q.upsertMusicianStmt,
 		upsertMusicianGenreStmt:                q.upsertMusicianGenreStmt,
+		upsertPodcastEpisodeStmt:               q.upsertPodcastEpisodeStmt,
+		upsertPodcastEpisodeProgressStmt:       q.upsertPodcastEpisodeProgressStmt,
 		upsertProductionCompanyStmt:            q.upsertProductionCompanyStmt,
+		upsertScannedLyricsStmt:                q.upsertScannedLyricsStmt,
 		upsertTrackStmt:                        q.upsertTrackStmt,
+		upsertUserLyricsStmt:                   q.upsertUserLyricsStmt,
 		upsertUserTrackStatsStmt:               q.upsertUserTrackStatsStmt,
 	}
 }
@@ -9,6 +9,127 @@ import (
 	"context"
 )
 
+const deleteAlbumGenres = `-- name: DeleteAlbumGenres :exec
+DELETE FROM album_genres WHERE album_id = ?
+`
+
+// Removes every genre link of an album, before its genres are replaced
+func (q *Queries) DeleteAlbumGenres(ctx context.Context, albumID int64) error {
+	_, err := q.exec(ctx, q.deleteAlbumGenresStmt, deleteAlbumGenres, albumID)
+	return err
+}
+
+const deleteGenre = `-- name: DeleteGenre :exec
+DELETE FROM genres WHERE id = ?
+`
+
+// Deleting a genre cascades to its remaining track, album, musician, movie and alias links
+func (q *Queries) DeleteGenre(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.deleteGenreStmt, deleteGenre, id)
+	return err
+}
+
+const deleteMusicianGenres = `-- name: DeleteMusicianGenres :exec
+DELETE FROM musician_genres WHERE musician_id = ?
+`
+
+// Removes every genre link of a musician, before its genres are replaced
+func (q *Queries) DeleteMusicianGenres(ctx context.Context, musicianID int64) error {
+	_, err := q.exec(ctx, q.deleteMusicianGenresStmt, deleteMusicianGenres, musicianID)
+	return err
+}
+
+const getGenreAliasesByType = `-- name: GetGenreAliasesByType :many
+SELECT genre_id, alias FROM genre_aliases WHERE genre_type = ? ORDER BY alias ASC
+`
+
+type GetGenreAliasesByTypeRow struct {
+	GenreID int64  `json:"genre_id"`
+	Alias   string `json:"alias"`
+}
+
+// Returns the normalized keys of every genre of a type, grouped by genre in GetGenres
+func (q *Queries) GetGenreAliasesByType(ctx context.Context, genreType string) ([]GetGenreAliasesByTypeRow, error) {
+	rows, err := q.query(ctx, q.getGenreAliasesByTypeStmt, getGenreAliasesByType, genreType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetGenreAliasesByTypeRow{}
+	for rows.Next() {
+		var i GetGenreAliasesByTypeRow
+		if err := rows.Scan(
+			&i.GenreID,
+			&i.Alias,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGenreByAlias = `-- name: GetGenreByAlias :one
+SELECT
+  id, tag, genre_type, created_at, updated_at
+FROM
+  genres
+WHERE
+  id = (
+    SELECT
+      ga.genre_id
+    FROM
+      genre_aliases ga
+    WHERE
+      ga.alias = ?
+      AND ga.genre_type = ?
+  )
+LIMIT
+  1
+`
+
+type GetGenreByAliasParams struct {
+	Alias     string `json:"alias"`
+	GenreType string `json:"genre_type"`
+}
+
+// Resolves a normalized genre key (see helpers.NormalizeKey) to its canonical genre
+func (q *Queries) GetGenreByAlias(ctx context.Context, arg GetGenreByAliasParams) (Genre, error) {
+	row := q.queryRow(ctx, q.getGenreByAliasStmt, getGenreByAlias, arg.Alias, arg.GenreType)
+	var i Genre
+	err := row.Scan(
+		&i.ID,
+		&i.Tag,
+		&i.GenreType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getGenreByID = `-- name: GetGenreByID :one
+SELECT id, tag, genre_type, created_at, updated_at FROM genres WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetGenreByID(ctx context.Context, id int64) (Genre, error) {
+	row := q.queryRow(ctx, q.getGenreByIDStmt, getGenreByID, id)
+	var i Genre
+	err := row.Scan(
+		&i.ID,
+		&i.Tag,
+		&i.GenreType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getGenresByAlbumIDDirect = `-- name: GetGenresByAlbumIDDirect :many
 SELECT
   g.id,
@@ -93,6 +214,65 @@ func (q *Queries) GetGenresByMusicianID(ctx context.Context, musicianID int64) (
 	return items, nil
 }
 
+const getGenresWithCounts = `-- name: GetGenresWithCounts :many
+SELECT
+  g.id,
+  g.tag,
+  g.genre_type,
+  (SELECT COUNT(*) FROM track_genres tg WHERE tg.genre_id = g.id) AS track_count,
+  (SELECT COUNT(*) FROM album_genres ag WHERE ag.genre_id = g.id) AS album_count,
+  (SELECT COUNT(*) FROM musician_genres mg WHERE mg.genre_id = g.id) AS musician_count,
+  (SELECT COUNT(*) FROM movie_genres mvg WHERE mvg.genre_id = g.id) AS movie_count
+FROM
+  genres g
+WHERE
+  g.genre_type = ?
+ORDER BY
+  g.tag ASC
+`
+
+type GetGenresWithCountsRow struct {
+	ID            int64  `json:"id"`
+	Tag           string `json:"tag"`
+	GenreType     string `json:"genre_type"`
+	TrackCount    int64  `json:"track_count"`
+	AlbumCount    int64  `json:"album_count"`
+	MusicianCount int64  `json:"musician_count"`
+	MovieCount    int64  `json:"movie_count"`
+}
+
+// Returns all genres of a type with how many tracks, albums, musicians and movies use each
+func (q *Queries) GetGenresWithCounts(ctx context.Context, genreType string) ([]GetGenresWithCountsRow, error) {
+	rows, err := q.query(ctx, q.getGenresWithCountsStmt, getGenresWithCounts, genreType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetGenresWithCountsRow{}
+	for rows.Next() {
+		var i GetGenresWithCountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Tag,
+			&i.GenreType,
+			&i.TrackCount,
+			&i.AlbumCount,
+			&i.MusicianCount,
+			&i.MovieCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getOrCreateGenre = `-- name: GetOrCreateGenre :one
 INSERT INTO
   genres (tag, genre_type)
@@ -121,6 +301,152 @@ func (q *Queries) GetOrCreateGenre(ctx context.Context, arg GetOrCreateGenrePara
 	return i, err
 }
 
+const getUnaliasedGenres = `-- name: GetUnaliasedGenres :many
+SELECT
+  id, tag, genre_type, created_at, updated_at
+FROM
+  genres g
+WHERE
+  NOT EXISTS (
+    SELECT
+      1
+    FROM
+      genre_aliases ga
+    WHERE
+      ga.genre_id = g.id
+  )
+ORDER BY
+  g.id ASC
+`
+
+// Returns the genres no normalized key resolves to yet, oldest first, e.g. genres
+// created before aliases existed
+func (q *Queries) GetUnaliasedGenres(ctx context.Context) ([]Genre, error) {
+	rows, err := q.query(ctx, q.getUnaliasedGenresStmt, getUnaliasedGenres)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Genre{}
+	for rows.Next() {
+		var i Genre
+		if err := rows.Scan(
+			&i.ID,
+			&i.Tag,
+			&i.GenreType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mergeAlbumGenres = `-- name: MergeAlbumGenres :exec
+UPDATE OR IGNORE album_genres
+SET
+  genre_id = ?
+WHERE
+  genre_id = ?
+`
+
+type MergeAlbumGenresParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Re-links albums from the source genre to the target genre (see MergeTrackGenres)
+func (q *Queries) MergeAlbumGenres(ctx context.Context, arg MergeAlbumGenresParams) error {
+	_, err := q.exec(ctx, q.mergeAlbumGenresStmt, mergeAlbumGenres, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeGenreAliases = `-- name: MergeGenreAliases :exec
+UPDATE genre_aliases
+SET
+  genre_id = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  genre_id = ?
+`
+
+type MergeGenreAliasesParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Points every alias of the source genre at the target genre
+func (q *Queries) MergeGenreAliases(ctx context.Context, arg MergeGenreAliasesParams) error {
+	_, err := q.exec(ctx, q.mergeGenreAliasesStmt, mergeGenreAliases, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeMovieGenres = `-- name: MergeMovieGenres :exec
+UPDATE OR IGNORE movie_genres
+SET
+  genre_id = ?
+WHERE
+  genre_id = ?
+`
+
+type MergeMovieGenresParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Re-links movies from the source genre to the target genre (see MergeTrackGenres)
+func (q *Queries) MergeMovieGenres(ctx context.Context, arg MergeMovieGenresParams) error {
+	_, err := q.exec(ctx, q.mergeMovieGenresStmt, mergeMovieGenres, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeMusicianGenres = `-- name: MergeMusicianGenres :exec
+UPDATE OR IGNORE musician_genres
+SET
+  genre_id = ?
+WHERE
+  genre_id = ?
+`
+
+type MergeMusicianGenresParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Re-links musicians from the source genre to the target genre (see MergeTrackGenres)
+func (q *Queries) MergeMusicianGenres(ctx context.Context, arg MergeMusicianGenresParams) error {
+	_, err := q.exec(ctx, q.mergeMusicianGenresStmt, mergeMusicianGenres, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeTrackGenres = `-- name: MergeTrackGenres :exec
+UPDATE OR IGNORE track_genres
+SET
+  genre_id = ?
+WHERE
+  genre_id = ?
+`
+
+type MergeTrackGenresParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Re-links tracks from the source genre to the target genre.
+// Rows whose track already has the target genre are left behind and removed by DeleteGenre's cascade.
+func (q *Queries) MergeTrackGenres(ctx context.Context, arg MergeTrackGenresParams) error {
+	_, err := q.exec(ctx, q.mergeTrackGenresStmt, mergeTrackGenres, arg.TargetID, arg.SourceID)
+	return err
+}
+
 const upsertAlbumGenre = `-- name: UpsertAlbumGenre :exec
 INSERT INTO album_genres (album_id, genre_id)
 VALUES (?, ?)
@@ -138,6 +464,26 @@ func (q *Queries) UpsertAlbumGenre(ctx context.Context, arg UpsertAlbumGenrePara
 	return err
 }
 
+const upsertGenreAlias = `-- name: UpsertGenreAlias :exec
+INSERT INTO genre_aliases (alias, genre_type, genre_id)
+VALUES (?, ?, ?)
+ON CONFLICT (alias, genre_type) DO UPDATE SET
+  genre_id = excluded.genre_id,
+  updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertGenreAliasParams struct {
+	Alias     string `json:"alias"`
+	GenreType string `json:"genre_type"`
+	GenreID   int64  `json:"genre_id"`
+}
+
+// Points a normalized genre key at a canonical genre, replacing any previous target
+func (q *Queries) UpsertGenreAlias(ctx context.Context, arg UpsertGenreAliasParams) error {
+	_, err := q.exec(ctx, q.upsertGenreAliasStmt, upsertGenreAlias, arg.Alias, arg.GenreType, arg.GenreID)
+	return err
+}
+
 const upsertMusicianGenre = `-- name: UpsertMusicianGenre :exec
 INSERT INTO musician_genres (musician_id, genre_id)
 VALUES (?, ?)
@@ -9,18 +9,34 @@ import (
 )
 
 type Album struct {
-	ID                int64           `json:"id"`
-	Title             string          `json:"title"`
-	SortTitle         string          `json:"sort_title"`
-	Musician          sql.NullString  `json:"musician"`
-	SpotifyID         sql.NullString  `json:"spotify_id"`
-	SpotifyPopularity sql.NullFloat64 `json:"spotify_popularity"`
-	ReleaseDate       sql.NullString  `json:"release_date"`
-	Year              sql.NullInt64   `json:"year"`
-	TotalTracks       sql.NullInt64   `json:"total_tracks"`
-	Cover             sql.NullString  `json:"cover"`
-	CreatedAt         string          `json:"created_at"`
-	UpdatedAt         string          `json:"updated_at"`
+	ID                        int64           `json:"id"`
+	Title                     string          `json:"title"`
+	SortTitle                 string          `json:"sort_title"`
+	Musician                  sql.NullString  `json:"musician"`
+	SpotifyID                 sql.NullString  `json:"spotify_id"`
+	SpotifyPopularity         sql.NullFloat64 `json:"spotify_popularity"`
+	ReleaseDate               sql.NullString  `json:"release_date"`
+	Year                      sql.NullInt64   `json:"year"`
+	TotalTracks               sql.NullInt64   `json:"total_tracks"`
+	Cover                     sql.NullString  `json:"cover"`
+	ScanTitle                 sql.NullString  `json:"scan_title"`
+	LockedFields              string          `json:"locked_fields"`
+	IsCompilation             bool            `json:"is_compilation"`
+	Directory                 sql.NullString  `json:"directory"`
+	MusicbrainzAlbumID        sql.NullString  `json:"musicbrainz_album_id"`
+	MusicbrainzReleaseGroupID sql.NullString  `json:"musicbrainz_release_group_id"`
+	SpotifyMatchScore         sql.NullFloat64 `json:"spotify_match_score"`
+	SpotifyMatchLocked        bool            `json:"spotify_match_locked"`
+	CreatedAt                 string          `json:"created_at"`
+	UpdatedAt                 string          `json:"updated_at"`
+}
+
+type ApiCache struct {
+	Provider  string `json:"provider"`
+	CacheKey  string `json:"cache_key"`
+	Body      []byte `json:"body"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
 }
 
 type Artist struct {
@@ -33,19 +49,89 @@ type Artist struct {
 }
 
 type AudioStream struct {
-	ID            int64          `json:"id"`
-	MovieID       int64          `json:"movie_id"`
-	StreamIndex   int64          `json:"stream_index"`
-	Codec         string         `json:"codec"`
-	CodecProfile  sql.NullString `json:"codec_profile"`
-	BitRate       int64          `json:"bit_rate"`
-	SampleRate    sql.NullInt64  `json:"sample_rate"`
-	Channels      int64          `json:"channels"`
-	ChannelLayout sql.NullString `json:"channel_layout"`
-	Language      sql.NullString `json:"language"`
-	Title         sql.NullString `json:"title"`
-	CreatedAt     string         `json:"created_at"`
-	UpdatedAt     string         `json:"updated_at"`
+	ID             int64          `json:"id"`
+	MovieID        int64          `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
+	StreamIndex    int64          `json:"stream_index"`
+	Codec          string         `json:"codec"`
+	CodecProfile   sql.NullString `json:"codec_profile"`
+	BitRate        int64          `json:"bit_rate"`
+	SampleRate     sql.NullInt64  `json:"sample_rate"`
+	Channels       int64          `json:"channels"`
+	ChannelLayout  sql.NullString `json:"channel_layout"`
+	Language       sql.NullString `json:"language"`
+	Title          sql.NullString `json:"title"`
+	CreatedAt      string         `json:"created_at"`
+	UpdatedAt      string         `json:"updated_at"`
+}
+
+type Audiobook struct {
+	ID          int64           `json:"id"`
+	Title       string          `json:"title"`
+	SortTitle   string          `json:"sort_title"`
+	Path        string          `json:"path"`
+	AuthorID    sql.NullInt64   `json:"author_id"`
+	Narrator    sql.NullString  `json:"narrator"`
+	Series      sql.NullString  `json:"series"`
+	SeriesIndex sql.NullFloat64 `json:"series_index"`
+	Description sql.NullString  `json:"description"`
+	Year        sql.NullInt64   `json:"year"`
+	Duration    int64           `json:"duration"`
+	Size        int64           `json:"size"`
+	CreatedAt   string          `json:"created_at"`
+	UpdatedAt   string          `json:"updated_at"`
+}
+
+type AudiobookBookmark struct {
+	ID          int64  `json:"id"`
+	UserID      int64  `json:"user_id"`
+	AudiobookID int64  `json:"audiobook_id"`
+	Position    int64  `json:"position"`
+	Note        string `json:"note"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type AudiobookChapter struct {
+	ID           int64  `json:"id"`
+	AudiobookID  int64  `json:"audiobook_id"`
+	FileID       int64  `json:"file_id"`
+	ChapterIndex int64  `json:"chapter_index"`
+	Title        string `json:"title"`
+	StartTime    int64  `json:"start_time"`
+	EndTime      int64  `json:"end_time"`
+}
+
+type AudiobookFile struct {
+	ID          int64  `json:"id"`
+	AudiobookID int64  `json:"audiobook_id"`
+	FilePath    string `json:"file_path"`
+	FileName    string `json:"file_name"`
+	FileIndex   int64  `json:"file_index"`
+	Container   string `json:"container"`
+	MimeType    string `json:"mime_type"`
+	Codec       string `json:"codec"`
+	Size        int64  `json:"size"`
+	Duration    int64  `json:"duration"`
+	BitRate     int64  `json:"bit_rate"`
+	StartOffset int64  `json:"start_offset"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type AudiobookProgress struct {
+	UserID      int64  `json:"user_id"`
+	AudiobookID int64  `json:"audiobook_id"`
+	Position    int64  `json:"position"`
+	Finished    bool   `json:"finished"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type Author struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	SortName  string `json:"sort_name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 }
 
 type Cast struct {
@@ -59,11 +145,32 @@ type Cast struct {
 }
 
 type Chapter struct {
-	ID        int64          `json:"id"`
-	Title     string         `json:"title"`
-	StartTime int64          `json:"start_time"`
-	Thumb     sql.NullString `json:"thumb"`
-	MovieID   sql.NullInt64  `json:"movie_id"`
+	ID             int64          `json:"id"`
+	Title          string         `json:"title"`
+	StartTime      int64          `json:"start_time"`
+	Thumb          sql.NullString `json:"thumb"`
+	MovieID        sql.NullInt64  `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
+}
+
+type Collection struct {
+	ID           int64          `json:"id"`
+	TmdbID       int64          `json:"tmdb_id"`
+	Name         string         `json:"name"`
+	Overview     sql.NullString `json:"overview"`
+	PosterPath   sql.NullString `json:"poster_path"`
+	BackdropPath sql.NullString `json:"backdrop_path"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+}
+
+type CollectionPart struct {
+	CollectionID int64          `json:"collection_id"`
+	TmdbID       int64          `json:"tmdb_id"`
+	Title        string         `json:"title"`
+	ReleaseDate  sql.NullString `json:"release_date"`
+	Overview     sql.NullString `json:"overview"`
+	PosterPath   sql.NullString `json:"poster_path"`
 }
 
 type Crew struct {
@@ -96,45 +203,140 @@ type Genre struct {
 	UpdatedAt string `json:"updated_at"`
 }
 
+type LastfmScrobble struct {
+	ID            int64          `json:"id"`
+	UserID        int64          `json:"user_id"`
+	Artist        string         `json:"artist"`
+	Track         string         `json:"track"`
+	Album         sql.NullString `json:"album"`
+	AlbumArtist   sql.NullString `json:"album_artist"`
+	TrackNumber   sql.NullInt64  `json:"track_number"`
+	Duration      sql.NullInt64  `json:"duration"`
+	Mbid          sql.NullString `json:"mbid"`
+	PlayedAt      int64          `json:"played_at"`
+	Attempts      int64          `json:"attempts"`
+	LastError     sql.NullString `json:"last_error"`
+	NextAttemptAt string         `json:"next_attempt_at"`
+	CreatedAt     string         `json:"created_at"`
+}
+
+type LastfmSession struct {
+	UserID     int64  `json:"user_id"`
+	Username   string `json:"username"`
+	SessionKey string `json:"session_key"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type ListenbrainzAccount struct {
+	UserID           int64          `json:"user_id"`
+	Username         string         `json:"username"`
+	Token            string         `json:"token"`
+	ImportedAt       sql.NullString `json:"imported_at"`
+	ImportedListens  int64          `json:"imported_listens"`
+	UnmatchedListens int64          `json:"unmatched_listens"`
+	CreatedAt        string         `json:"created_at"`
+}
+
+type ListenbrainzListen struct {
+	ID            int64          `json:"id"`
+	UserID        int64          `json:"user_id"`
+	Artist        string         `json:"artist"`
+	Track         string         `json:"track"`
+	Release       sql.NullString `json:"release"`
+	RecordingMbid sql.NullString `json:"recording_mbid"`
+	ReleaseMbid   sql.NullString `json:"release_mbid"`
+	ArtistMbid    sql.NullString `json:"artist_mbid"`
+	TrackNumber   sql.NullInt64  `json:"track_number"`
+	Duration      sql.NullInt64  `json:"duration"`
+	ListenedAt    int64          `json:"listened_at"`
+	Attempts      int64          `json:"attempts"`
+	LastError     sql.NullString `json:"last_error"`
+	NextAttemptAt string         `json:"next_attempt_at"`
+	CreatedAt     string         `json:"created_at"`
+}
+
+type LocalExtra struct {
+	ID        int64  `json:"id"`
+	MovieID   int64  `json:"movie_id"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	FilePath  string `json:"file_path"`
+	FileName  string `json:"file_name"`
+	Size      int64  `json:"size"`
+	Container string `json:"container"`
+	MimeType  string `json:"mime_type"`
+	Duration  int64  `json:"duration"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type MediaVersion struct {
+	ID        int64          `json:"id"`
+	MovieID   int64          `json:"movie_id"`
+	FilePath  string         `json:"file_path"`
+	FileName  string         `json:"file_name"`
+	Size      int64          `json:"size"`
+	Container string         `json:"container"`
+	MimeType  string         `json:"mime_type"`
+	Edition   sql.NullString `json:"edition"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at"`
+}
+
 type Movie struct {
-	ID             int64           `json:"id"`
-	Title          string          `json:"title"`
-	FilePath       string          `json:"file_path"`
-	FileName       string          `json:"file_name"`
-	Size           int64           `json:"size"`
-	Container      string          `json:"container"`
-	MimeType       string          `json:"mime_type"`
-	Adult          bool            `json:"adult"`
-	TmdbID         sql.NullInt64   `json:"tmdb_id"`
-	ImdbID         sql.NullString  `json:"imdb_id"`
-	PosterPath     sql.NullString  `json:"poster_path"`
-	BackdropPath   sql.NullString  `json:"backdrop_path"`
-	Language       sql.NullString  `json:"language"`
-	Year           sql.NullInt64   `json:"year"`
-	ReleaseDate    sql.NullString  `json:"release_date"`
-	Overview       sql.NullString  `json:"overview"`
-	TagLine        sql.NullString  `json:"tag_line"`
-	Certification  sql.NullString  `json:"certification"`
-	CriticRating   sql.NullFloat64 `json:"critic_rating"`
-	AudienceRating sql.NullFloat64 `json:"audience_rating"`
-	Revenue        sql.NullFloat64 `json:"revenue"`
-	Budget         sql.NullFloat64 `json:"budget"`
-	RunTime        sql.NullInt64   `json:"run_time"`
-	CreatedAt      string          `json:"created_at"`
-	UpdatedAt      string          `json:"updated_at"`
+	ID                  int64           `json:"id"`
+	Title               string          `json:"title"`
+	FilePath            string          `json:"file_path"`
+	FileName            string          `json:"file_name"`
+	Size                int64           `json:"size"`
+	Container           string          `json:"container"`
+	MimeType            string          `json:"mime_type"`
+	Adult               bool            `json:"adult"`
+	TmdbID              sql.NullInt64   `json:"tmdb_id"`
+	ImdbID              sql.NullString  `json:"imdb_id"`
+	PosterPath          sql.NullString  `json:"poster_path"`
+	BackdropPath        sql.NullString  `json:"backdrop_path"`
+	Language            sql.NullString  `json:"language"`
+	Year                sql.NullInt64   `json:"year"`
+	ReleaseDate         sql.NullString  `json:"release_date"`
+	Overview            sql.NullString  `json:"overview"`
+	TagLine             sql.NullString  `json:"tag_line"`
+	Certification       sql.NullString  `json:"certification"`
+	CriticRating        sql.NullFloat64 `json:"critic_rating"`
+	AudienceRating      sql.NullFloat64 `json:"audience_rating"`
+	Revenue             sql.NullFloat64 `json:"revenue"`
+	Budget              sql.NullFloat64 `json:"budget"`
+	RunTime             sql.NullInt64   `json:"run_time"`
+	MatchLocked         bool            `json:"match_locked"`
+	LockedFields        string          `json:"locked_fields"`
+	MetadataRefreshedAt sql.NullString  `json:"metadata_refreshed_at"`
+	OriginalTitle       sql.NullString  `json:"original_title"`
+	SortTitle           sql.NullString  `json:"sort_title"`
+	CreatedAt           string          `json:"created_at"`
+	UpdatedAt           string          `json:"updated_at"`
+}
+
+type MovieCollection struct {
+	MovieID      int64  `json:"movie_id"`
+	CollectionID int64  `json:"collection_id"`
+	CreatedAt    string `json:"created_at"`
 }
 
 type Musician struct {
-	ID                int64           `json:"id"`
-	Name              string          `json:"name"`
-	SortName          string          `json:"sort_name"`
-	Summary           sql.NullString  `json:"summary"`
-	SpotifyPopularity sql.NullFloat64 `json:"spotify_popularity"`
-	SpotifyFollowers  sql.NullInt64   `json:"spotify_followers"`
-	SpotifyID         sql.NullString  `json:"spotify_id"`
-	Thumb             sql.NullString  `json:"thumb"`
-	CreatedAt         string          `json:"created_at"`
-	UpdatedAt         string          `json:"updated_at"`
+	ID                  int64           `json:"id"`
+	Name                string          `json:"name"`
+	SortName            string          `json:"sort_name"`
+	Summary             sql.NullString  `json:"summary"`
+	SpotifyPopularity   sql.NullFloat64 `json:"spotify_popularity"`
+	SpotifyFollowers    sql.NullInt64   `json:"spotify_followers"`
+	SpotifyID           sql.NullString  `json:"spotify_id"`
+	Thumb               sql.NullString  `json:"thumb"`
+	ScanName            sql.NullString  `json:"scan_name"`
+	LockedFields        string          `json:"locked_fields"`
+	MetadataRefreshedAt sql.NullString  `json:"metadata_refreshed_at"`
+	MusicbrainzID       sql.NullString  `json:"musicbrainz_id"`
+	CreatedAt           string          `json:"created_at"`
+	UpdatedAt           string          `json:"updated_at"`
 }
 
 type Playlist struct {
@@ -167,6 +369,53 @@ type PlaylistTrack struct {
 	AddedAt    string        `json:"added_at"`
 }
 
+type Podcast struct {
+	ID           int64          `json:"id"`
+	FeedUrl      string         `json:"feed_url"`
+	Title        string         `json:"title"`
+	Description  sql.NullString `json:"description"`
+	Author       sql.NullString `json:"author"`
+	ImageUrl     sql.NullString `json:"image_url"`
+	Link         sql.NullString `json:"link"`
+	Language     sql.NullString `json:"language"`
+	Etag         sql.NullString `json:"etag"`
+	LastModified sql.NullString `json:"last_modified"`
+	LastPolledAt sql.NullString `json:"last_polled_at"`
+	LastError    sql.NullString `json:"last_error"`
+	AutoDownload bool           `json:"auto_download"`
+	KeepEpisodes int64          `json:"keep_episodes"`
+	KeepDays     int64          `json:"keep_days"`
+	DeletePlayed bool           `json:"delete_played"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+}
+
+type PodcastEpisode struct {
+	ID            int64          `json:"id"`
+	PodcastID     int64          `json:"podcast_id"`
+	Guid          string         `json:"guid"`
+	Title         string         `json:"title"`
+	Description   sql.NullString `json:"description"`
+	PublishedAt   sql.NullString `json:"published_at"`
+	Duration      int64          `json:"duration"`
+	EnclosureUrl  string         `json:"enclosure_url"`
+	EnclosureType string         `json:"enclosure_type"`
+	EnclosureSize int64          `json:"enclosure_size"`
+	FilePath      sql.NullString `json:"file_path"`
+	FileSize      int64          `json:"file_size"`
+	DownloadedAt  sql.NullString `json:"downloaded_at"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at"`
+}
+
+type PodcastEpisodeProgress struct {
+	UserID    int64  `json:"user_id"`
+	EpisodeID int64  `json:"episode_id"`
+	Position  int64  `json:"position"`
+	Played    bool   `json:"played"`
+	UpdatedAt string `json:"updated_at"`
+}
+
 type ProductionCompany struct {
 	ID        int64          `json:"id"`
 	Name      string         `json:"name"`
@@ -177,64 +426,114 @@ type ProductionCompany struct {
 	UpdatedAt string         `json:"updated_at"`
 }
 
+type ScanError struct {
+	ID          int64  `json:"id"`
+	FilePath    string `json:"file_path"`
+	Library     string `json:"library"`
+	Phase       string `json:"phase"`
+	Error       string `json:"error"`
+	Attempts    int64  `json:"attempts"`
+	FirstSeenAt string `json:"first_seen_at"`
+	LastSeenAt  string `json:"last_seen_at"`
+}
+
 type Setting struct {
-	ID                         int64          `json:"id"`
-	TmdbKey                    sql.NullString `json:"tmdb_key"`
-	JellyfinToken              sql.NullString `json:"jellyfin_token"`
-	SpotifyClientID            sql.NullString `json:"spotify_client_id"`
-	SpotifyClientSecret        sql.NullString `json:"spotify_client_secret"`
-	HardwareAccelerationDevice sql.NullString `json:"hardware_acceleration_device"`
-	EnableLogger               bool           `json:"enable_logger"`
-	EnableWatcher              bool           `json:"enable_watcher"`
-	DownloadImages             bool           `json:"download_images"`
-	MoviesDir                  sql.NullString `json:"movies_dir"`
-	ShowsDir                   sql.NullString `json:"shows_dir"`
-	MusicDir                   sql.NullString `json:"music_dir"`
-	StaticDir                  string         `json:"static_dir"`
-	LogsDir                    string         `json:"logs_dir"`
-	CreatedAt                  string         `json:"created_at"`
-	UpdatedAt                  string         `json:"updated_at"`
+	ID                             int64          `json:"id"`
+	TmdbKey                        sql.NullString `json:"tmdb_key"`
+	JellyfinToken                  sql.NullString `json:"jellyfin_token"`
+	SpotifyClientID                sql.NullString `json:"spotify_client_id"`
+	SpotifyClientSecret            sql.NullString `json:"spotify_client_secret"`
+	HardwareAccelerationDevice     sql.NullString `json:"hardware_acceleration_device"`
+	EnableLogger                   bool           `json:"enable_logger"`
+	EnableWatcher                  bool           `json:"enable_watcher"`
+	DownloadImages                 bool           `json:"download_images"`
+	MoviesDir                      sql.NullString `json:"movies_dir"`
+	ShowsDir                       sql.NullString `json:"shows_dir"`
+	MusicDir                       sql.NullString `json:"music_dir"`
+	AudiobooksDir                  sql.NullString `json:"audiobooks_dir"`
+	PodcastsDir                    sql.NullString `json:"podcasts_dir"`
+	StaticDir                      string         `json:"static_dir"`
+	LogsDir                        string         `json:"logs_dir"`
+	MoviesIgnorePatterns           sql.NullString `json:"movies_ignore_patterns"`
+	MusicIgnorePatterns            sql.NullString `json:"music_ignore_patterns"`
+	AudiobooksIgnorePatterns       sql.NullString `json:"audiobooks_ignore_patterns"`
+	MoviesMinSize                  int64          `json:"movies_min_size"`
+	MoviesMinDuration              int64          `json:"movies_min_duration"`
+	MusicMinSize                   int64          `json:"music_min_size"`
+	MusicMinDuration               int64          `json:"music_min_duration"`
+	MoviesMetadataProviders        string         `json:"movies_metadata_providers"`
+	MetadataLanguage               string         `json:"metadata_language"`
+	MetadataFallbackLanguage       string         `json:"metadata_fallback_language"`
+	CertificationCountry           string         `json:"certification_country"`
+	MoviesMetadataLanguage         sql.NullString `json:"movies_metadata_language"`
+	MoviesMetadataFallbackLanguage sql.NullString `json:"movies_metadata_fallback_language"`
+	MoviesCertificationCountry     sql.NullString `json:"movies_certification_country"`
+	MetadataRefreshDays            int64          `json:"metadata_refresh_days"`
+	MetadataRefreshRate            int64          `json:"metadata_refresh_rate"`
+	VariousArtistsName             string         `json:"various_artists_name"`
+	MusicSpotifyEnrichment         bool           `json:"music_spotify_enrichment"`
+	PodcastPollMinutes             int64          `json:"podcast_poll_minutes"`
+	CollectionsBackfilledAt        sql.NullString `json:"collections_backfilled_at"`
+	CreatedAt                      string         `json:"created_at"`
+	UpdatedAt                      string         `json:"updated_at"`
 }
 
 type Subtitle struct {
-	ID          int64          `json:"id"`
-	MovieID     int64          `json:"movie_id"`
-	StreamIndex int64          `json:"stream_index"`
-	Codec       string         `json:"codec"`
-	Language    sql.NullString `json:"language"`
-	Title       sql.NullString `json:"title"`
-	IsForced    bool           `json:"is_forced"`
-	IsDefault   bool           `json:"is_default"`
-	CreatedAt   string         `json:"created_at"`
-	UpdatedAt   string         `json:"updated_at"`
+	ID             int64          `json:"id"`
+	MovieID        int64          `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
+	StreamIndex    int64          `json:"stream_index"`
+	Codec          string         `json:"codec"`
+	Language       sql.NullString `json:"language"`
+	Title          sql.NullString `json:"title"`
+	IsForced       bool           `json:"is_forced"`
+	IsDefault      bool           `json:"is_default"`
+	CreatedAt      string         `json:"created_at"`
+	UpdatedAt      string         `json:"updated_at"`
 }
 
 type Track struct {
-	ID            int64          `json:"id"`
-	Title         string         `json:"title"`
-	SortTitle     string         `json:"sort_title"`
-	FilePath      string         `json:"file_path"`
-	FileName      string         `json:"file_name"`
-	Container     string         `json:"container"`
-	MimeType      string         `json:"mime_type"`
-	Codec         string         `json:"codec"`
-	Size          int64          `json:"size"`
-	TrackIndex    int64          `json:"track_index"`
-	Duration      int64          `json:"duration"`
-	Disc          int64          `json:"disc"`
-	Channels      string         `json:"channels"`
-	ChannelLayout string         `json:"channel_layout"`
-	BitRate       int64          `json:"bit_rate"`
-	Profile       string         `json:"profile"`
-	ReleaseDate   sql.NullString `json:"release_date"`
-	Year          sql.NullInt64  `json:"year"`
-	Composer      sql.NullString `json:"composer"`
-	Copyright     sql.NullString `json:"copyright"`
-	Language      sql.NullString `json:"language"`
-	AlbumID       sql.NullInt64  `json:"album_id"`
-	MusicianID    sql.NullInt64  `json:"musician_id"`
-	CreatedAt     string         `json:"created_at"`
-	UpdatedAt     string         `json:"updated_at"`
+	ID                 int64          `json:"id"`
+	Title              string         `json:"title"`
+	SortTitle          string         `json:"sort_title"`
+	FilePath           string         `json:"file_path"`
+	FileName           string         `json:"file_name"`
+	Container          string         `json:"container"`
+	MimeType           string         `json:"mime_type"`
+	Codec              string         `json:"codec"`
+	Size               int64          `json:"size"`
+	TrackIndex         int64          `json:"track_index"`
+	Duration           int64          `json:"duration"`
+	Disc               int64          `json:"disc"`
+	Channels           string         `json:"channels"`
+	ChannelLayout      string         `json:"channel_layout"`
+	BitRate            int64          `json:"bit_rate"`
+	Profile            string         `json:"profile"`
+	ReleaseDate        sql.NullString `json:"release_date"`
+	Year               sql.NullInt64  `json:"year"`
+	Composer           sql.NullString `json:"composer"`
+	Copyright          sql.NullString `json:"copyright"`
+	Language           sql.NullString `json:"language"`
+	AlbumID            sql.NullInt64  `json:"album_id"`
+	MusicianID         sql.NullInt64  `json:"musician_id"`
+	LockedFields       string         `json:"locked_fields"`
+	SourcePath         sql.NullString `json:"source_path"`
+	StartOffset        sql.NullInt64  `json:"start_offset"`
+	EndOffset          sql.NullInt64  `json:"end_offset"`
+	MusicbrainzTrackID sql.NullString `json:"musicbrainz_track_id"`
+	LyricsScannedAt    sql.NullString `json:"lyrics_scanned_at"`
+	CreatedAt          string         `json:"created_at"`
+	UpdatedAt          string         `json:"updated_at"`
+}
+
+type TrackLyric struct {
+	TrackID   int64          `json:"track_id"`
+	Content   string         `json:"content"`
+	Synced    bool           `json:"synced"`
+	Language  sql.NullString `json:"language"`
+	Source    string         `json:"source"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at"`
 }
 
 type User struct {
@@ -251,6 +550,7 @@ type User struct {
 type VideoStream struct {
 	ID             int64          `json:"id"`
 	MovieID        int64          `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
 	StreamIndex    int64          `json:"stream_index"`
 	Codec          string         `json:"codec"`
 	CodecProfile   sql.NullString `json:"codec_profile"`
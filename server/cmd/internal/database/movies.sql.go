@@ -14,10 +14,18 @@ const checkMovieUnchanged = `-- name: CheckMovieUnchanged :one
 SELECT
   1
 FROM
-  movies
+  media_versions
 WHERE
   file_path = ?
   AND size = ?
+  AND NOT EXISTS (
+    SELECT
+      1
+    FROM
+      scan_errors
+    WHERE
+      scan_errors.file_path = media_versions.file_path
+  )
 LIMIT
   1
 `
@@ -27,7 +35,8 @@ type CheckMovieUnchangedParams struct {
 	Size     int64  `json:"size"`
 }
 
-// Quick check if movie exists with same path and size (likely unchanged)
+// Quick check if a movie version exists with same path and size (likely unchanged).
+// Versions left in scan_errors, e.g. by a failed TMDB lookup, are scanned again.
 func (q *Queries) CheckMovieUnchanged(ctx context.Context, arg CheckMovieUnchangedParams) (int64, error) {
 	row := q.queryRow(ctx, q.checkMovieUnchangedStmt, checkMovieUnchanged, arg.FilePath, arg.Size)
 	var column_1 int64
@@ -89,27 +98,86 @@ func (q *Queries) CreateMovieProductionCompany(ctx context.Context, arg CreateMo
 	return err
 }
 
-const deleteMovieAudioStreams = `-- name: DeleteMovieAudioStreams :exec
+const deleteCastMember = `-- name: DeleteCastMember :exec
+DELETE FROM cast
+WHERE
+  id = ?
+`
+
+// Remove one cast member of a movie
+func (q *Queries) DeleteCastMember(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.deleteCastMemberStmt, deleteCastMember, id)
+	return err
+}
+
+const deleteCrewMember = `-- name: DeleteCrewMember :exec
+DELETE FROM crew
+WHERE
+  id = ?
+`
+
+// Remove one crew member of a movie
+func (q *Queries) DeleteCrewMember(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.deleteCrewMemberStmt, deleteCrewMember, id)
+	return err
+}
+
+const deleteMediaVersionAudioStreams = `-- name: DeleteMediaVersionAudioStreams :exec
 DELETE FROM audio_streams
 WHERE
-  movie_id = ?
+  media_version_id = ?
 `
 
-// Delete all audio streams for a movie
-func (q *Queries) DeleteMovieAudioStreams(ctx context.Context, movieID int64) error {
-	_, err := q.exec(ctx, q.deleteMovieAudioStreamsStmt, deleteMovieAudioStreams, movieID)
+// Delete all audio streams for a movie version
+func (q *Queries) DeleteMediaVersionAudioStreams(ctx context.Context, mediaVersionID sql.NullInt64) error {
+	_, err := q.exec(ctx, q.deleteMediaVersionAudioStreamsStmt, deleteMediaVersionAudioStreams, mediaVersionID)
 	return err
 }
 
-const deleteMovieChapters = `-- name: DeleteMovieChapters :exec
+const deleteMediaVersionChapters = `-- name: DeleteMediaVersionChapters :exec
 DELETE FROM chapters
 WHERE
-  movie_id = ?
+  media_version_id = ?
 `
 
-// Delete all chapters for a movie
-func (q *Queries) DeleteMovieChapters(ctx context.Context, movieID sql.NullInt64) error {
-	_, err := q.exec(ctx, q.deleteMovieChaptersStmt, deleteMovieChapters, movieID)
+// Delete all chapters for a movie version
+func (q *Queries) DeleteMediaVersionChapters(ctx context.Context, mediaVersionID sql.NullInt64) error {
+	_, err := q.exec(ctx, q.deleteMediaVersionChaptersStmt, deleteMediaVersionChapters, mediaVersionID)
+	return err
+}
+
+const deleteMediaVersionSubtitles = `-- name: DeleteMediaVersionSubtitles :exec
+DELETE FROM subtitles
+WHERE
+  media_version_id = ?
+`
+
+// Delete all subtitles for a movie version
+func (q *Queries) DeleteMediaVersionSubtitles(ctx context.Context, mediaVersionID sql.NullInt64) error {
+	_, err := q.exec(ctx, q.deleteMediaVersionSubtitlesStmt, deleteMediaVersionSubtitles, mediaVersionID)
+	return err
+}
+
+const deleteMediaVersionVideoStreams = `-- name: DeleteMediaVersionVideoStreams :exec
+DELETE FROM video_streams
+WHERE
+  media_version_id = ?
+`
+
+// Delete all video streams for a movie version
+func (q *Queries) DeleteMediaVersionVideoStreams(ctx context.Context, mediaVersionID sql.NullInt64) error {
+	_, err := q.exec(ctx, q.deleteMediaVersionVideoStreamsStmt, deleteMediaVersionVideoStreams, mediaVersionID)
+	return err
+}
+
+const deleteMovie = `-- name: DeleteMovie :exec
+DELETE FROM movies
+WHERE
+  id = ?
+`
+
+func (q *Queries) DeleteMovie(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.deleteMovieStmt, deleteMovie, id)
 	return err
 }
 
@@ -125,6 +193,24 @@ func (q *Queries) DeleteMovieExtraVideos(ctx context.Context, movieID int64) err
 	return err
 }
 
+const deleteMovieGenre = `-- name: DeleteMovieGenre :exec
+DELETE FROM movie_genres
+WHERE
+  movie_id = ?
+  AND genre_id = ?
+`
+
+type DeleteMovieGenreParams struct {
+	MovieID int64 `json:"movie_id"`
+	GenreID int64 `json:"genre_id"`
+}
+
+// Remove one genre link of a movie
+func (q *Queries) DeleteMovieGenre(ctx context.Context, arg DeleteMovieGenreParams) error {
+	_, err := q.exec(ctx, q.deleteMovieGenreStmt, deleteMovieGenre, arg.MovieID, arg.GenreID)
+	return err
+}
+
 const deleteMovieGenres = `-- name: DeleteMovieGenres :exec
 DELETE FROM movie_genres
 WHERE
@@ -149,28 +235,127 @@ func (q *Queries) DeleteMovieProductionCompanies(ctx context.Context, movieID in
 	return err
 }
 
-const deleteMovieSubtitles = `-- name: DeleteMovieSubtitles :exec
-DELETE FROM subtitles
+const deleteMovieProductionCompany = `-- name: DeleteMovieProductionCompany :exec
+DELETE FROM movie_production_companies
 WHERE
   movie_id = ?
+  AND production_company_id = ?
 `
 
-// Delete all subtitles for a movie
-func (q *Queries) DeleteMovieSubtitles(ctx context.Context, movieID int64) error {
-	_, err := q.exec(ctx, q.deleteMovieSubtitlesStmt, deleteMovieSubtitles, movieID)
+type DeleteMovieProductionCompanyParams struct {
+	MovieID             int64 `json:"movie_id"`
+	ProductionCompanyID int64 `json:"production_company_id"`
+}
+
+// Remove one production company link of a movie
+func (q *Queries) DeleteMovieProductionCompany(ctx context.Context, arg DeleteMovieProductionCompanyParams) error {
+	_, err := q.exec(ctx, q.deleteMovieProductionCompanyStmt, deleteMovieProductionCompany, arg.MovieID, arg.ProductionCompanyID)
 	return err
 }
 
-const deleteMovieVideoStreams = `-- name: DeleteMovieVideoStreams :exec
-DELETE FROM video_streams
+const fillMovieMetadata = `-- name: FillMovieMetadata :one
+UPDATE movies
+SET
+  original_title = COALESCE(movies.original_title, ?),
+  tmdb_id = COALESCE(movies.tmdb_id, ?),
+  imdb_id = COALESCE(movies.imdb_id, ?),
+  poster_path = COALESCE(movies.poster_path, ?),
+  backdrop_path = COALESCE(movies.backdrop_path, ?),
+  language = COALESCE(movies.language, ?),
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE COALESCE(movies.year, ?)
+  END,
+  release_date = COALESCE(movies.release_date, ?),
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE COALESCE(movies.overview, ?)
+  END,
+  tag_line = COALESCE(movies.tag_line, ?),
+  certification = COALESCE(movies.certification, ?),
+  critic_rating = COALESCE(movies.critic_rating, ?),
+  revenue = COALESCE(movies.revenue, ?),
+  budget = COALESCE(movies.budget, ?),
+  run_time = COALESCE(movies.run_time, ?),
+  updated_at = CURRENT_TIMESTAMP
 WHERE
-  movie_id = ?
+  id = ? RETURNING id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
 `
 
-// Delete all video streams for a movie
-func (q *Queries) DeleteMovieVideoStreams(ctx context.Context, movieID int64) error {
-	_, err := q.exec(ctx, q.deleteMovieVideoStreamsStmt, deleteMovieVideoStreams, movieID)
-	return err
+type FillMovieMetadataParams struct {
+	OriginalTitle sql.NullString  `json:"original_title"`
+	TmdbID        sql.NullInt64   `json:"tmdb_id"`
+	ImdbID        sql.NullString  `json:"imdb_id"`
+	PosterPath    sql.NullString  `json:"poster_path"`
+	BackdropPath  sql.NullString  `json:"backdrop_path"`
+	Language      sql.NullString  `json:"language"`
+	Year          sql.NullInt64   `json:"year"`
+	ReleaseDate   sql.NullString  `json:"release_date"`
+	Overview      sql.NullString  `json:"overview"`
+	TagLine       sql.NullString  `json:"tag_line"`
+	Certification sql.NullString  `json:"certification"`
+	CriticRating  sql.NullFloat64 `json:"critic_rating"`
+	Revenue       sql.NullFloat64 `json:"revenue"`
+	Budget        sql.NullFloat64 `json:"budget"`
+	RunTime       sql.NullInt64   `json:"run_time"`
+	ID            int64           `json:"id"`
+}
+
+// Fills the fields the primary file of a movie left empty with the metadata of another
+// of its versions. Fields already set and hand-edited fields are kept.
+func (q *Queries) FillMovieMetadata(ctx context.Context, arg FillMovieMetadataParams) (Movie, error) {
+	row := q.queryRow(ctx, q.fillMovieMetadataStmt, fillMovieMetadata,
+		arg.OriginalTitle,
+		arg.TmdbID,
+		arg.ImdbID,
+		arg.PosterPath,
+		arg.BackdropPath,
+		arg.Language,
+		arg.Year,
+		arg.ReleaseDate,
+		arg.Overview,
+		arg.TagLine,
+		arg.Certification,
+		arg.CriticRating,
+		arg.Revenue,
+		arg.Budget,
+		arg.RunTime,
+		arg.ID,
+	)
+	var i Movie
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.FilePath,
+		&i.FileName,
+		&i.Size,
+		&i.Container,
+		&i.MimeType,
+		&i.Adult,
+		&i.TmdbID,
+		&i.ImdbID,
+		&i.PosterPath,
+		&i.BackdropPath,
+		&i.Language,
+		&i.Year,
+		&i.ReleaseDate,
+		&i.Overview,
+		&i.TagLine,
+		&i.Certification,
+		&i.CriticRating,
+		&i.AudienceRating,
+		&i.Revenue,
+		&i.Budget,
+		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
 const getCastByMovieID = `-- name: GetCastByMovieID :many
@@ -390,7 +575,7 @@ func (q *Queries) GetLatestMovies(ctx context.Context) ([]GetLatestMoviesRow, er
 
 const getMovieByFilePath = `-- name: GetMovieByFilePath :one
 SELECT
-  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, created_at, updated_at
+  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
 FROM
   movies
 WHERE
@@ -426,6 +611,11 @@ func (q *Queries) GetMovieByFilePath(ctx context.Context, filePath string) (Movi
 		&i.Revenue,
 		&i.Budget,
 		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -434,7 +624,7 @@ func (q *Queries) GetMovieByFilePath(ctx context.Context, filePath string) (Movi
 
 const getMovieByID = `-- name: GetMovieByID :one
 SELECT
-  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, created_at, updated_at
+  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
 FROM
   movies
 WHERE
@@ -470,6 +660,69 @@ func (q *Queries) GetMovieByID(ctx context.Context, id int64) (Movie, error) {
 		&i.Revenue,
 		&i.Budget,
 		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMovieByTitleAndYear = `-- name: GetMovieByTitleAndYear :one
+SELECT
+  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
+FROM
+  movies
+WHERE
+  title = ?
+  AND year IS ?
+ORDER BY
+  id ASC
+LIMIT
+  1
+`
+
+type GetMovieByTitleAndYearParams struct {
+	Title string        `json:"title"`
+	Year  sql.NullInt64 `json:"year"`
+}
+
+// Used to group edition-tagged files of a movie that has no TMDB match.
+func (q *Queries) GetMovieByTitleAndYear(ctx context.Context, arg GetMovieByTitleAndYearParams) (Movie, error) {
+	row := q.queryRow(ctx, q.getMovieByTitleAndYearStmt, getMovieByTitleAndYear, arg.Title, arg.Year)
+	var i Movie
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.FilePath,
+		&i.FileName,
+		&i.Size,
+		&i.Container,
+		&i.MimeType,
+		&i.Adult,
+		&i.TmdbID,
+		&i.ImdbID,
+		&i.PosterPath,
+		&i.BackdropPath,
+		&i.Language,
+		&i.Year,
+		&i.ReleaseDate,
+		&i.Overview,
+		&i.TagLine,
+		&i.Certification,
+		&i.CriticRating,
+		&i.AudienceRating,
+		&i.Revenue,
+		&i.Budget,
+		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -478,7 +731,7 @@ func (q *Queries) GetMovieByID(ctx context.Context, id int64) (Movie, error) {
 
 const getMovieByTmdbID = `-- name: GetMovieByTmdbID :one
 SELECT
-  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, created_at, updated_at
+  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
 FROM
   movies
 WHERE
@@ -517,6 +770,11 @@ func (q *Queries) GetMovieByTmdbID(ctx context.Context, tmdbID sql.NullInt64) (M
 		&i.Revenue,
 		&i.Budget,
 		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -632,10 +890,91 @@ func (q *Queries) GetProductionCompaniesByMovieID(ctx context.Context, movieID i
 	return items, nil
 }
 
+const getStaleMovies = `-- name: GetStaleMovies :many
+SELECT
+  id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
+FROM
+  movies
+WHERE
+  tmdb_id IS NOT NULL
+  AND COALESCE(metadata_refreshed_at, created_at) < ?
+  AND id > ?
+ORDER BY
+  id ASC
+LIMIT
+  ?
+`
+
+type GetStaleMoviesParams struct {
+	Cutoff  string `json:"cutoff"`
+	AfterID int64  `json:"after_id"`
+	Limit   int64  `json:"limit"`
+}
+
+// Returns TMDB-matched movies last refreshed before the cutoff, paged by id.
+func (q *Queries) GetStaleMovies(ctx context.Context, arg GetStaleMoviesParams) ([]Movie, error) {
+	rows, err := q.query(ctx, q.getStaleMoviesStmt, getStaleMovies,
+		arg.Cutoff,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Movie{}
+	for rows.Next() {
+		var i Movie
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.FilePath,
+			&i.FileName,
+			&i.Size,
+			&i.Container,
+			&i.MimeType,
+			&i.Adult,
+			&i.TmdbID,
+			&i.ImdbID,
+			&i.PosterPath,
+			&i.BackdropPath,
+			&i.Language,
+			&i.Year,
+			&i.ReleaseDate,
+			&i.Overview,
+			&i.TagLine,
+			&i.Certification,
+			&i.CriticRating,
+			&i.AudienceRating,
+			&i.Revenue,
+			&i.Budget,
+			&i.RunTime,
+			&i.MatchLocked,
+			&i.LockedFields,
+			&i.MetadataRefreshedAt,
+			&i.OriginalTitle,
+			&i.SortTitle,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertAudioStream = `-- name: InsertAudioStream :one
 INSERT INTO
   audio_streams (
     movie_id,
+    media_version_id,
     stream_index,
     codec,
     codec_profile,
@@ -647,25 +986,27 @@ INSERT INTO
     title
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, movie_id, stream_index, codec, codec_profile, bit_rate, sample_rate, channels, channel_layout, language, title, created_at, updated_at
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, movie_id, media_version_id, stream_index, codec, codec_profile, bit_rate, sample_rate, channels, channel_layout, language, title, created_at, updated_at
 `
 
 type InsertAudioStreamParams struct {
-	MovieID       int64          `json:"movie_id"`
-	StreamIndex   int64          `json:"stream_index"`
-	Codec         string         `json:"codec"`
-	CodecProfile  sql.NullString `json:"codec_profile"`
-	BitRate       int64          `json:"bit_rate"`
-	SampleRate    sql.NullInt64  `json:"sample_rate"`
-	Channels      int64          `json:"channels"`
-	ChannelLayout sql.NullString `json:"channel_layout"`
-	Language      sql.NullString `json:"language"`
-	Title         sql.NullString `json:"title"`
+	MovieID        int64          `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
+	StreamIndex    int64          `json:"stream_index"`
+	Codec          string         `json:"codec"`
+	CodecProfile   sql.NullString `json:"codec_profile"`
+	BitRate        int64          `json:"bit_rate"`
+	SampleRate     sql.NullInt64  `json:"sample_rate"`
+	Channels       int64          `json:"channels"`
+	ChannelLayout  sql.NullString `json:"channel_layout"`
+	Language       sql.NullString `json:"language"`
+	Title          sql.NullString `json:"title"`
 }
 
 func (q *Queries) InsertAudioStream(ctx context.Context, arg InsertAudioStreamParams) (AudioStream, error) {
 	row := q.queryRow(ctx, q.insertAudioStreamStmt, insertAudioStream,
 		arg.MovieID,
+		arg.MediaVersionID,
 		arg.StreamIndex,
 		arg.Codec,
 		arg.CodecProfile,
@@ -680,6 +1021,7 @@ func (q *Queries) InsertAudioStream(ctx context.Context, arg InsertAudioStreamPa
 	err := row.Scan(
 		&i.ID,
 		&i.MovieID,
+		&i.MediaVersionID,
 		&i.StreamIndex,
 		&i.Codec,
 		&i.CodecProfile,
@@ -697,21 +1039,23 @@ func (q *Queries) InsertAudioStream(ctx context.Context, arg InsertAudioStreamPa
 
 const insertChapter = `-- name: InsertChapter :one
 INSERT INTO
-  chapters (movie_id, title, start_time, thumb)
+  chapters (movie_id, media_version_id, title, start_time, thumb)
 VALUES
-  (?, ?, ?, ?) RETURNING id, title, start_time, thumb, movie_id
+  (?, ?, ?, ?, ?) RETURNING id, title, start_time, thumb, movie_id, media_version_id
 `
 
 type InsertChapterParams struct {
-	MovieID   sql.NullInt64  `json:"movie_id"`
-	Title     string         `json:"title"`
-	StartTime int64          `json:"start_time"`
-	Thumb     sql.NullString `json:"thumb"`
+	MovieID        sql.NullInt64  `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
+	Title          string         `json:"title"`
+	StartTime      int64          `json:"start_time"`
+	Thumb          sql.NullString `json:"thumb"`
 }
 
 func (q *Queries) InsertChapter(ctx context.Context, arg InsertChapterParams) (Chapter, error) {
 	row := q.queryRow(ctx, q.insertChapterStmt, insertChapter,
 		arg.MovieID,
+		arg.MediaVersionID,
 		arg.Title,
 		arg.StartTime,
 		arg.Thumb,
@@ -723,6 +1067,7 @@ func (q *Queries) InsertChapter(ctx context.Context, arg InsertChapterParams) (C
 		&i.StartTime,
 		&i.Thumb,
 		&i.MovieID,
+		&i.MediaVersionID,
 	)
 	return i, err
 }
@@ -731,6 +1076,7 @@ const insertSubtitle = `-- name: InsertSubtitle :one
 INSERT INTO
   subtitles (
     movie_id,
+    media_version_id,
     stream_index,
     codec,
     language,
@@ -739,22 +1085,24 @@ INSERT INTO
     is_default
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?) RETURNING id, movie_id, stream_index, codec, language, title, is_forced, is_default, created_at, updated_at
+  (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, movie_id, media_version_id, stream_index, codec, language, title, is_forced, is_default, created_at, updated_at
 `
 
 type InsertSubtitleParams struct {
-	MovieID     int64          `json:"movie_id"`
-	StreamIndex int64          `json:"stream_index"`
-	Codec       string         `json:"codec"`
-	Language    sql.NullString `json:"language"`
-	Title       sql.NullString `json:"title"`
-	IsForced    bool           `json:"is_forced"`
-	IsDefault   bool           `json:"is_default"`
+	MovieID        int64          `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
+	StreamIndex    int64          `json:"stream_index"`
+	Codec          string         `json:"codec"`
+	Language       sql.NullString `json:"language"`
+	Title          sql.NullString `json:"title"`
+	IsForced       bool           `json:"is_forced"`
+	IsDefault      bool           `json:"is_default"`
 }
 
 func (q *Queries) InsertSubtitle(ctx context.Context, arg InsertSubtitleParams) (Subtitle, error) {
 	row := q.queryRow(ctx, q.insertSubtitleStmt, insertSubtitle,
 		arg.MovieID,
+		arg.MediaVersionID,
 		arg.StreamIndex,
 		arg.Codec,
 		arg.Language,
@@ -766,6 +1114,7 @@ func (q *Queries) InsertSubtitle(ctx context.Context, arg InsertSubtitleParams)
 	err := row.Scan(
 		&i.ID,
 		&i.MovieID,
+		&i.MediaVersionID,
 		&i.StreamIndex,
 		&i.Codec,
 		&i.Language,
@@ -782,6 +1131,7 @@ const insertVideoStream = `-- name: InsertVideoStream :one
 INSERT INTO
   video_streams (
     movie_id,
+    media_version_id,
     stream_index,
     codec,
     codec_profile,
@@ -823,12 +1173,14 @@ VALUES
     ?,
     ?,
     ?,
+    ?,
     ?
-  ) RETURNING id, movie_id, stream_index, codec, codec_profile, codec_level, bit_rate, width, height, coded_width, coded_height, aspect_ratio, frame_rate, avg_frame_rate, bit_depth, color_range, color_space, color_primaries, color_transfer, language, title, created_at, updated_at
+  ) RETURNING id, movie_id, media_version_id, stream_index, codec, codec_profile, codec_level, bit_rate, width, height, coded_width, coded_height, aspect_ratio, frame_rate, avg_frame_rate, bit_depth, color_range, color_space, color_primaries, color_transfer, language, title, created_at, updated_at
 `
 
 type InsertVideoStreamParams struct {
 	MovieID        int64          `json:"movie_id"`
+	MediaVersionID sql.NullInt64  `json:"media_version_id"`
 	StreamIndex    int64          `json:"stream_index"`
 	Codec          string         `json:"codec"`
 	CodecProfile   sql.NullString `json:"codec_profile"`
@@ -853,6 +1205,7 @@ type InsertVideoStreamParams struct {
 func (q *Queries) InsertVideoStream(ctx context.Context, arg InsertVideoStreamParams) (VideoStream, error) {
 	row := q.queryRow(ctx, q.insertVideoStreamStmt, insertVideoStream,
 		arg.MovieID,
+		arg.MediaVersionID,
 		arg.StreamIndex,
 		arg.Codec,
 		arg.CodecProfile,
@@ -877,6 +1230,7 @@ func (q *Queries) InsertVideoStream(ctx context.Context, arg InsertVideoStreamPa
 	err := row.Scan(
 		&i.ID,
 		&i.MovieID,
+		&i.MediaVersionID,
 		&i.StreamIndex,
 		&i.Codec,
 		&i.CodecProfile,
@@ -902,6 +1256,419 @@ func (q *Queries) InsertVideoStream(ctx context.Context, arg InsertVideoStreamPa
 	return i, err
 }
 
+const mergeAudioStreams = `-- name: MergeAudioStreams :exec
+UPDATE audio_streams
+SET
+  movie_id = ?
+WHERE
+  movie_id = ?
+`
+
+type MergeAudioStreamsParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Moves the audio streams of the source movie's versions along with them (see MergeMediaVersions)
+func (q *Queries) MergeAudioStreams(ctx context.Context, arg MergeAudioStreamsParams) error {
+	_, err := q.exec(ctx, q.mergeAudioStreamsStmt, mergeAudioStreams, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeChapters = `-- name: MergeChapters :exec
+UPDATE chapters
+SET
+  movie_id = ?
+WHERE
+  movie_id = ?
+`
+
+type MergeChaptersParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Moves the chapters of the source movie's versions along with them (see MergeMediaVersions)
+func (q *Queries) MergeChapters(ctx context.Context, arg MergeChaptersParams) error {
+	_, err := q.exec(ctx, q.mergeChaptersStmt, mergeChapters, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeSubtitles = `-- name: MergeSubtitles :exec
+UPDATE subtitles
+SET
+  movie_id = ?
+WHERE
+  movie_id = ?
+`
+
+type MergeSubtitlesParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Moves the subtitles of the source movie's versions along with them (see MergeMediaVersions)
+func (q *Queries) MergeSubtitles(ctx context.Context, arg MergeSubtitlesParams) error {
+	_, err := q.exec(ctx, q.mergeSubtitlesStmt, mergeSubtitles, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeVideoStreams = `-- name: MergeVideoStreams :exec
+UPDATE video_streams
+SET
+  movie_id = ?
+WHERE
+  movie_id = ?
+`
+
+type MergeVideoStreamsParams struct {
+	TargetID int64 `json:"target_id"`
+	SourceID int64 `json:"source_id"`
+}
+
+// Moves the video streams of the source movie's versions along with them (see MergeMediaVersions)
+func (q *Queries) MergeVideoStreams(ctx context.Context, arg MergeVideoStreamsParams) error {
+	_, err := q.exec(ctx, q.mergeVideoStreamsStmt, mergeVideoStreams, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const refreshMovieMetadata = `-- name: RefreshMovieMetadata :one
+UPDATE movies
+SET
+  title = CASE
+    WHEN 'title' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.title
+    ELSE ?
+  END,
+  original_title = ?,
+  imdb_id = ?,
+  poster_path = ?,
+  backdrop_path = ?,
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE ?
+  END,
+  release_date = ?,
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE ?
+  END,
+  tag_line = ?,
+  certification = ?,
+  critic_rating = ?,
+  revenue = ?,
+  budget = ?,
+  run_time = ?,
+  metadata_refreshed_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
+`
+
+type RefreshMovieMetadataParams struct {
+	Title         string          `json:"title"`
+	OriginalTitle sql.NullString  `json:"original_title"`
+	ImdbID        sql.NullString  `json:"imdb_id"`
+	PosterPath    sql.NullString  `json:"poster_path"`
+	BackdropPath  sql.NullString  `json:"backdrop_path"`
+	Year          sql.NullInt64   `json:"year"`
+	ReleaseDate   sql.NullString  `json:"release_date"`
+	Overview      sql.NullString  `json:"overview"`
+	TagLine       sql.NullString  `json:"tag_line"`
+	Certification sql.NullString  `json:"certification"`
+	CriticRating  sql.NullFloat64 `json:"critic_rating"`
+	Revenue       sql.NullFloat64 `json:"revenue"`
+	Budget        sql.NullFloat64 `json:"budget"`
+	RunTime       sql.NullInt64   `json:"run_time"`
+	ID            int64           `json:"id"`
+}
+
+// Stores re-fetched TMDB metadata. Hand-edited fields are kept.
+func (q *Queries) RefreshMovieMetadata(ctx context.Context, arg RefreshMovieMetadataParams) (Movie, error) {
+	row := q.queryRow(ctx, q.refreshMovieMetadataStmt, refreshMovieMetadata,
+		arg.Title,
+		arg.OriginalTitle,
+		arg.ImdbID,
+		arg.PosterPath,
+		arg.BackdropPath,
+		arg.Year,
+		arg.ReleaseDate,
+		arg.Overview,
+		arg.TagLine,
+		arg.Certification,
+		arg.CriticRating,
+		arg.Revenue,
+		arg.Budget,
+		arg.RunTime,
+		arg.ID,
+	)
+	var i Movie
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.FilePath,
+		&i.FileName,
+		&i.Size,
+		&i.Container,
+		&i.MimeType,
+		&i.Adult,
+		&i.TmdbID,
+		&i.ImdbID,
+		&i.PosterPath,
+		&i.BackdropPath,
+		&i.Language,
+		&i.Year,
+		&i.ReleaseDate,
+		&i.Overview,
+		&i.TagLine,
+		&i.Certification,
+		&i.CriticRating,
+		&i.AudienceRating,
+		&i.Revenue,
+		&i.Budget,
+		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const touchMovieMetadataRefreshed = `-- name: TouchMovieMetadataRefreshed :exec
+UPDATE movies
+SET
+  metadata_refreshed_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?
+`
+
+// Marks a movie as refreshed when TMDB had nothing new.
+func (q *Queries) TouchMovieMetadataRefreshed(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.touchMovieMetadataRefreshedStmt, touchMovieMetadataRefreshed, id)
+	return err
+}
+
+const updateMovieFile = `-- name: UpdateMovieFile :exec
+UPDATE movies
+SET
+  file_path = ?,
+  file_name = ?,
+  size = ?,
+  container = ?,
+  mime_type = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?
+`
+
+type UpdateMovieFileParams struct {
+	FilePath  string `json:"file_path"`
+	FileName  string `json:"file_name"`
+	Size      int64  `json:"size"`
+	Container string `json:"container"`
+	MimeType  string `json:"mime_type"`
+	ID        int64  `json:"id"`
+}
+
+// Points a movie at another of its versions, after the file it mirrored is gone.
+func (q *Queries) UpdateMovieFile(ctx context.Context, arg UpdateMovieFileParams) error {
+	_, err := q.exec(ctx, q.updateMovieFileStmt, updateMovieFile,
+		arg.FilePath,
+		arg.FileName,
+		arg.Size,
+		arg.Container,
+		arg.MimeType,
+		arg.ID,
+	)
+	return err
+}
+
+const updateMovieMatch = `-- name: UpdateMovieMatch :one
+UPDATE movies
+SET
+  title = CASE
+    WHEN 'title' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.title
+    ELSE ?
+  END,
+  original_title = ?,
+  adult = ?,
+  tmdb_id = ?,
+  imdb_id = ?,
+  poster_path = ?,
+  backdrop_path = ?,
+  language = ?,
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE ?
+  END,
+  release_date = ?,
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE ?
+  END,
+  tag_line = ?,
+  certification = ?,
+  critic_rating = ?,
+  revenue = ?,
+  budget = ?,
+  run_time = ?,
+  match_locked = 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
+`
+
+type UpdateMovieMatchParams struct {
+	Title         string          `json:"title"`
+	OriginalTitle sql.NullString  `json:"original_title"`
+	Adult         bool            `json:"adult"`
+	TmdbID        sql.NullInt64   `json:"tmdb_id"`
+	ImdbID        sql.NullString  `json:"imdb_id"`
+	PosterPath    sql.NullString  `json:"poster_path"`
+	BackdropPath  sql.NullString  `json:"backdrop_path"`
+	Language      sql.NullString  `json:"language"`
+	Year          sql.NullInt64   `json:"year"`
+	ReleaseDate   sql.NullString  `json:"release_date"`
+	Overview      sql.NullString  `json:"overview"`
+	TagLine       sql.NullString  `json:"tag_line"`
+	Certification sql.NullString  `json:"certification"`
+	CriticRating  sql.NullFloat64 `json:"critic_rating"`
+	Revenue       sql.NullFloat64 `json:"revenue"`
+	Budget        sql.NullFloat64 `json:"budget"`
+	RunTime       sql.NullInt64   `json:"run_time"`
+	ID            int64           `json:"id"`
+}
+
+// Applies a manual TMDB match: every TMDB field is replaced rather than merged,
+// and the match is locked so later scans keep it.
+func (q *Queries) UpdateMovieMatch(ctx context.Context, arg UpdateMovieMatchParams) (Movie, error) {
+	row := q.queryRow(ctx, q.updateMovieMatchStmt, updateMovieMatch,
+		arg.Title,
+		arg.OriginalTitle,
+		arg.Adult,
+		arg.TmdbID,
+		arg.ImdbID,
+		arg.PosterPath,
+		arg.BackdropPath,
+		arg.Language,
+		arg.Year,
+		arg.ReleaseDate,
+		arg.Overview,
+		arg.TagLine,
+		arg.Certification,
+		arg.CriticRating,
+		arg.Revenue,
+		arg.Budget,
+		arg.RunTime,
+		arg.ID,
+	)
+	var i Movie
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.FilePath,
+		&i.FileName,
+		&i.Size,
+		&i.Container,
+		&i.MimeType,
+		&i.Adult,
+		&i.TmdbID,
+		&i.ImdbID,
+		&i.PosterPath,
+		&i.BackdropPath,
+		&i.Language,
+		&i.Year,
+		&i.ReleaseDate,
+		&i.Overview,
+		&i.TagLine,
+		&i.Certification,
+		&i.CriticRating,
+		&i.AudienceRating,
+		&i.Revenue,
+		&i.Budget,
+		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateMovieMetadata = `-- name: UpdateMovieMetadata :one
+UPDATE movies
+SET
+  title = ?,
+  sort_title = ?,
+  year = ?,
+  overview = ?,
+  locked_fields = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
+`
+
+type UpdateMovieMetadataParams struct {
+	Title        string         `json:"title"`
+	SortTitle    sql.NullString `json:"sort_title"`
+	Year         sql.NullInt64  `json:"year"`
+	Overview     sql.NullString `json:"overview"`
+	LockedFields string         `json:"locked_fields"`
+	ID           int64          `json:"id"`
+}
+
+// Applies a hand edit. The caller passes every editable field and the new lock set.
+func (q *Queries) UpdateMovieMetadata(ctx context.Context, arg UpdateMovieMetadataParams) (Movie, error) {
+	row := q.queryRow(ctx, q.updateMovieMetadataStmt, updateMovieMetadata,
+		arg.Title,
+		arg.SortTitle,
+		arg.Year,
+		arg.Overview,
+		arg.LockedFields,
+		arg.ID,
+	)
+	var i Movie
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.FilePath,
+		&i.FileName,
+		&i.Size,
+		&i.Container,
+		&i.MimeType,
+		&i.Adult,
+		&i.TmdbID,
+		&i.ImdbID,
+		&i.PosterPath,
+		&i.BackdropPath,
+		&i.Language,
+		&i.Year,
+		&i.ReleaseDate,
+		&i.Overview,
+		&i.TagLine,
+		&i.Certification,
+		&i.CriticRating,
+		&i.AudienceRating,
+		&i.Revenue,
+		&i.Budget,
+		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const upsertArtist = `-- name: UpsertArtist :one
 INSERT INTO
   artist (name, tmdb_id, profile)
@@ -1063,6 +1830,7 @@ const upsertMovie = `-- name: UpsertMovie :one
 INSERT INTO
   movies (
     title,
+    original_title,
     file_path,
     file_name,
     size,
@@ -1108,11 +1876,16 @@ VALUES
     ?,
     ?,
     ?,
+    ?,
     ?
   ) ON CONFLICT (file_path) DO
 UPDATE
 SET
-  title = excluded.title,
+  title = CASE
+    WHEN 'title' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.title
+    ELSE excluded.title
+  END,
+  original_title = COALESCE(excluded.original_title, movies.original_title),
   file_name = excluded.file_name,
   size = excluded.size,
   container = excluded.container,
@@ -1122,9 +1895,15 @@ SET
   imdb_id = COALESCE(excluded.imdb_id, movies.imdb_id),
   poster_path = COALESCE(excluded.poster_path, movies.poster_path),
   language = COALESCE(excluded.language, movies.language),
-  year = COALESCE(excluded.year, movies.year),
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE COALESCE(excluded.year, movies.year)
+  END,
   release_date = COALESCE(excluded.release_date, movies.release_date),
-  overview = COALESCE(excluded.overview, movies.overview),
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE COALESCE(excluded.overview, movies.overview)
+  END,
   tag_line = COALESCE(excluded.tag_line, movies.tag_line),
   certification = COALESCE(excluded.certification, movies.certification),
   critic_rating = COALESCE(excluded.critic_rating, movies.critic_rating),
@@ -1132,11 +1911,12 @@ SET
   revenue = COALESCE(excluded.revenue, movies.revenue),
   budget = COALESCE(excluded.budget, movies.budget),
   run_time = COALESCE(excluded.run_time, movies.run_time),
-  updated_at = CURRENT_TIMESTAMP RETURNING id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, created_at, updated_at
+  updated_at = CURRENT_TIMESTAMP RETURNING id, title, file_path, file_name, size, container, mime_type, adult, tmdb_id, imdb_id, poster_path, backdrop_path, language, year, release_date, overview, tag_line, certification, critic_rating, audience_rating, revenue, budget, run_time, match_locked, locked_fields, metadata_refreshed_at, original_title, sort_title, created_at, updated_at
 `
 
 type UpsertMovieParams struct {
 	Title          string          `json:"title"`
+	OriginalTitle  sql.NullString  `json:"original_title"`
 	FilePath       string          `json:"file_path"`
 	FileName       string          `json:"file_name"`
 	Size           int64           `json:"size"`
@@ -1163,6 +1943,7 @@ type UpsertMovieParams struct {
 func (q *Queries) UpsertMovie(ctx context.Context, arg UpsertMovieParams) (Movie, error) {
 	row := q.queryRow(ctx, q.upsertMovieStmt, upsertMovie,
 		arg.Title,
+		arg.OriginalTitle,
 		arg.FilePath,
 		arg.FileName,
 		arg.Size,
@@ -1210,6 +1991,11 @@ func (q *Queries) UpsertMovie(ctx context.Context, arg UpsertMovieParams) (Movie
 		&i.Revenue,
 		&i.Budget,
 		&i.RunTime,
+		&i.MatchLocked,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.OriginalTitle,
+		&i.SortTitle,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -18,6 +18,7 @@ SELECT
   a.year,
   a.release_date,
   a.spotify_popularity,
+  a.musicbrainz_album_id,
   (SELECT COUNT(*) FROM tracks t WHERE t.album_id = a.id) as track_count
 FROM
   albums a
@@ -31,13 +32,14 @@ ORDER BY
 `
 
 type GetAlbumsByMusicianIDRow struct {
-	ID                int64           `json:"id"`
-	Title             string          `json:"title"`
-	Cover             sql.NullString  `json:"cover"`
-	Year              sql.NullInt64   `json:"year"`
-	ReleaseDate       sql.NullString  `json:"release_date"`
-	SpotifyPopularity sql.NullFloat64 `json:"spotify_popularity"`
-	TrackCount        int64           `json:"track_count"`
+	ID                 int64           `json:"id"`
+	Title              string          `json:"title"`
+	Cover              sql.NullString  `json:"cover"`
+	Year               sql.NullInt64   `json:"year"`
+	ReleaseDate        sql.NullString  `json:"release_date"`
+	SpotifyPopularity  sql.NullFloat64 `json:"spotify_popularity"`
+	MusicbrainzAlbumID sql.NullString  `json:"musicbrainz_album_id"`
+	TrackCount         int64           `json:"track_count"`
 }
 
 // Returns all albums associated with a musician via the musician_albums join table
@@ -58,6 +60,7 @@ func (q *Queries) GetAlbumsByMusicianID(ctx context.Context, musicianID int64) (
 			&i.Year,
 			&i.ReleaseDate,
 			&i.SpotifyPopularity,
+			&i.MusicbrainzAlbumID,
 			&i.TrackCount,
 		); err != nil {
 			return nil, err
@@ -74,7 +77,7 @@ func (q *Queries) GetAlbumsByMusicianID(ctx context.Context, musicianID int64) (
 }
 
 const getMusicianByID = `-- name: GetMusicianByID :one
-SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, created_at, updated_at FROM musicians WHERE id = ? LIMIT 1
+SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at FROM musicians WHERE id = ? LIMIT 1
 `
 
 // Returns a single musician by ID with full details
@@ -90,6 +93,63 @@ func (q *Queries) GetMusicianByID(ctx context.Context, id int64) (Musician, erro
 		&i.SpotifyFollowers,
 		&i.SpotifyID,
 		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMusicianByMusicbrainzID = `-- name: GetMusicianByMusicbrainzID :one
+SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at FROM musicians WHERE musicbrainz_id = ? LIMIT 1
+`
+
+func (q *Queries) GetMusicianByMusicbrainzID(ctx context.Context, musicbrainzID sql.NullString) (Musician, error) {
+	row := q.queryRow(ctx, q.getMusicianByMusicbrainzIDStmt, getMusicianByMusicbrainzID, musicbrainzID)
+	var i Musician
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SortName,
+		&i.Summary,
+		&i.SpotifyPopularity,
+		&i.SpotifyFollowers,
+		&i.SpotifyID,
+		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMusicianByScanName = `-- name: GetMusicianByScanName :one
+SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at FROM musicians WHERE scan_name = ? LIMIT 1
+`
+
+// Finds a musician renamed by hand through the tag name the scanner still reads.
+func (q *Queries) GetMusicianByScanName(ctx context.Context, scanName sql.NullString) (Musician, error) {
+	row := q.queryRow(ctx, q.getMusicianByScanNameStmt, getMusicianByScanName, scanName)
+	var i Musician
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SortName,
+		&i.Summary,
+		&i.SpotifyPopularity,
+		&i.SpotifyFollowers,
+		&i.SpotifyID,
+		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -97,7 +157,7 @@ func (q *Queries) GetMusicianByID(ctx context.Context, id int64) (Musician, erro
 }
 
 const getMusicianBySpotifyID = `-- name: GetMusicianBySpotifyID :one
-SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, created_at, updated_at FROM musicians WHERE spotify_id = ? LIMIT 1
+SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at FROM musicians WHERE spotify_id = ? LIMIT 1
 `
 
 func (q *Queries) GetMusicianBySpotifyID(ctx context.Context, spotifyID sql.NullString) (Musician, error) {
@@ -112,6 +172,10 @@ func (q *Queries) GetMusicianBySpotifyID(ctx context.Context, spotifyID sql.Null
 		&i.SpotifyFollowers,
 		&i.SpotifyID,
 		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -189,7 +253,8 @@ SELECT
   m.id,
   m.name,
   m.thumb,
-  m.spotify_id
+  m.spotify_id,
+  m.musicbrainz_id
 FROM
   musicians m
   INNER JOIN musician_albums ma ON m.id = ma.musician_id
@@ -200,10 +265,11 @@ ORDER BY
 `
 
 type GetMusiciansByAlbumIDRow struct {
-	ID        int64          `json:"id"`
-	Name      string         `json:"name"`
-	Thumb     sql.NullString `json:"thumb"`
-	SpotifyID sql.NullString `json:"spotify_id"`
+	ID            int64          `json:"id"`
+	Name          string         `json:"name"`
+	Thumb         sql.NullString `json:"thumb"`
+	SpotifyID     sql.NullString `json:"spotify_id"`
+	MusicbrainzID sql.NullString `json:"musicbrainz_id"`
 }
 
 func (q *Queries) GetMusiciansByAlbumID(ctx context.Context, albumID int64) ([]GetMusiciansByAlbumIDRow, error) {
@@ -220,6 +286,107 @@ func (q *Queries) GetMusiciansByAlbumID(ctx context.Context, albumID int64) ([]G
 			&i.Name,
 			&i.Thumb,
 			&i.SpotifyID,
+			&i.MusicbrainzID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMusiciansByName = `-- name: GetMusiciansByName :many
+SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at FROM musicians WHERE name = ? ORDER BY id ASC
+`
+
+func (q *Queries) GetMusiciansByName(ctx context.Context, name string) ([]Musician, error) {
+	rows, err := q.query(ctx, q.getMusiciansByNameStmt, getMusiciansByName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Musician{}
+	for rows.Next() {
+		var i Musician
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.SortName,
+			&i.Summary,
+			&i.SpotifyPopularity,
+			&i.SpotifyFollowers,
+			&i.SpotifyID,
+			&i.Thumb,
+			&i.ScanName,
+			&i.LockedFields,
+			&i.MetadataRefreshedAt,
+			&i.MusicbrainzID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStaleMusicians = `-- name: GetStaleMusicians :many
+SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at FROM musicians
+WHERE spotify_id IS NOT NULL
+  AND COALESCE(metadata_refreshed_at, created_at) < ?
+  AND id > ?
+ORDER BY id ASC
+LIMIT ?
+`
+
+type GetStaleMusiciansParams struct {
+	Cutoff  string `json:"cutoff"`
+	AfterID int64  `json:"after_id"`
+	Limit   int64  `json:"limit"`
+}
+
+// Returns Spotify-matched musicians last refreshed before the cutoff, paged by id.
+func (q *Queries) GetStaleMusicians(ctx context.Context, arg GetStaleMusiciansParams) ([]Musician, error) {
+	rows, err := q.query(ctx, q.getStaleMusiciansStmt, getStaleMusicians,
+		arg.Cutoff,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Musician{}
+	for rows.Next() {
+		var i Musician
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.SortName,
+			&i.Summary,
+			&i.SpotifyPopularity,
+			&i.SpotifyFollowers,
+			&i.SpotifyID,
+			&i.Thumb,
+			&i.ScanName,
+			&i.LockedFields,
+			&i.MetadataRefreshedAt,
+			&i.MusicbrainzID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -245,6 +412,7 @@ SELECT
   t.file_path,
   t.track_index,
   t.disc,
+  t.musicbrainz_track_id,
   a.id as album_id,
   a.title as album_title,
   a.cover as album_cover
@@ -255,18 +423,19 @@ ORDER BY t.sort_title ASC
 `
 
 type GetTracksByMusicianIDRow struct {
-	ID         int64          `json:"id"`
-	Title      string         `json:"title"`
-	SortTitle  string         `json:"sort_title"`
-	Duration   int64          `json:"duration"`
-	Codec      string         `json:"codec"`
-	BitRate    int64          `json:"bit_rate"`
-	FilePath   string         `json:"file_path"`
-	TrackIndex int64          `json:"track_index"`
-	Disc       int64          `json:"disc"`
-	AlbumID    sql.NullInt64  `json:"album_id"`
-	AlbumTitle sql.NullString `json:"album_title"`
-	AlbumCover sql.NullString `json:"album_cover"`
+	ID                 int64          `json:"id"`
+	Title              string         `json:"title"`
+	SortTitle          string         `json:"sort_title"`
+	Duration           int64          `json:"duration"`
+	Codec              string         `json:"codec"`
+	BitRate            int64          `json:"bit_rate"`
+	FilePath           string         `json:"file_path"`
+	TrackIndex         int64          `json:"track_index"`
+	Disc               int64          `json:"disc"`
+	MusicbrainzTrackID sql.NullString `json:"musicbrainz_track_id"`
+	AlbumID            sql.NullInt64  `json:"album_id"`
+	AlbumTitle         sql.NullString `json:"album_title"`
+	AlbumCover         sql.NullString `json:"album_cover"`
 }
 
 // Returns all tracks by a musician, sorted alphabetically by sort_title
@@ -289,6 +458,7 @@ func (q *Queries) GetTracksByMusicianID(ctx context.Context, musicianID sql.Null
 			&i.FilePath,
 			&i.TrackIndex,
 			&i.Disc,
+			&i.MusicbrainzTrackID,
 			&i.AlbumID,
 			&i.AlbumTitle,
 			&i.AlbumCover,
@@ -306,18 +476,185 @@ func (q *Queries) GetTracksByMusicianID(ctx context.Context, musicianID sql.Null
 	return items, nil
 }
 
+const getUnmatchedMusicianByName = `-- name: GetUnmatchedMusicianByName :one
+SELECT id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at FROM musicians
+WHERE musicbrainz_id IS NULL AND (name = ?1 OR scan_name = ?1)
+ORDER BY scan_name IS NULL
+LIMIT 1
+`
+
+// Finds a musician without a MusicBrainz id by name, or by the tag name of one renamed by hand.
+func (q *Queries) GetUnmatchedMusicianByName(ctx context.Context, name string) (Musician, error) {
+	row := q.queryRow(ctx, q.getUnmatchedMusicianByNameStmt, getUnmatchedMusicianByName, name)
+	var i Musician
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SortName,
+		&i.Summary,
+		&i.SpotifyPopularity,
+		&i.SpotifyFollowers,
+		&i.SpotifyID,
+		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const refreshMusicianMetadata = `-- name: RefreshMusicianMetadata :one
+UPDATE musicians
+SET summary = CASE WHEN 'summary' IN (SELECT value FROM json_each(musicians.locked_fields)) THEN musicians.summary ELSE ? END,
+  spotify_popularity = ?, spotify_followers = ?, thumb = ?,
+  metadata_refreshed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at
+`
+
+type RefreshMusicianMetadataParams struct {
+	Summary           sql.NullString  `json:"summary"`
+	SpotifyPopularity sql.NullFloat64 `json:"spotify_popularity"`
+	SpotifyFollowers  sql.NullInt64   `json:"spotify_followers"`
+	Thumb             sql.NullString  `json:"thumb"`
+	ID                int64           `json:"id"`
+}
+
+// Stores re-fetched Spotify metadata. A hand-edited summary is kept.
+func (q *Queries) RefreshMusicianMetadata(ctx context.Context, arg RefreshMusicianMetadataParams) (Musician, error) {
+	row := q.queryRow(ctx, q.refreshMusicianMetadataStmt, refreshMusicianMetadata,
+		arg.Summary,
+		arg.SpotifyPopularity,
+		arg.SpotifyFollowers,
+		arg.Thumb,
+		arg.ID,
+	)
+	var i Musician
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SortName,
+		&i.Summary,
+		&i.SpotifyPopularity,
+		&i.SpotifyFollowers,
+		&i.SpotifyID,
+		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setMusicianMusicbrainzID = `-- name: SetMusicianMusicbrainzID :one
+UPDATE musicians SET musicbrainz_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+RETURNING id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at
+`
+
+type SetMusicianMusicbrainzIDParams struct {
+	MusicbrainzID sql.NullString `json:"musicbrainz_id"`
+	ID            int64          `json:"id"`
+}
+
+func (q *Queries) SetMusicianMusicbrainzID(ctx context.Context, arg SetMusicianMusicbrainzIDParams) (Musician, error) {
+	row := q.queryRow(ctx, q.setMusicianMusicbrainzIDStmt, setMusicianMusicbrainzID,
+		arg.MusicbrainzID,
+		arg.ID,
+	)
+	var i Musician
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SortName,
+		&i.Summary,
+		&i.SpotifyPopularity,
+		&i.SpotifyFollowers,
+		&i.SpotifyID,
+		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const touchMusicianMetadataRefreshed = `-- name: TouchMusicianMetadataRefreshed :exec
+UPDATE musicians SET metadata_refreshed_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+// Marks a musician as refreshed when Spotify had nothing new.
+func (q *Queries) TouchMusicianMetadataRefreshed(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.touchMusicianMetadataRefreshedStmt, touchMusicianMetadataRefreshed, id)
+	return err
+}
+
+const updateMusicianMetadata = `-- name: UpdateMusicianMetadata :one
+UPDATE musicians
+SET name = ?, sort_name = ?, summary = ?, scan_name = ?, locked_fields = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at
+`
+
+type UpdateMusicianMetadataParams struct {
+	Name         string         `json:"name"`
+	SortName     string         `json:"sort_name"`
+	Summary      sql.NullString `json:"summary"`
+	ScanName     sql.NullString `json:"scan_name"`
+	LockedFields string         `json:"locked_fields"`
+	ID           int64          `json:"id"`
+}
+
+// Applies a hand edit. The caller passes every editable field and the new lock set.
+func (q *Queries) UpdateMusicianMetadata(ctx context.Context, arg UpdateMusicianMetadataParams) (Musician, error) {
+	row := q.queryRow(ctx, q.updateMusicianMetadataStmt, updateMusicianMetadata,
+		arg.Name,
+		arg.SortName,
+		arg.Summary,
+		arg.ScanName,
+		arg.LockedFields,
+		arg.ID,
+	)
+	var i Musician
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.SortName,
+		&i.Summary,
+		&i.SpotifyPopularity,
+		&i.SpotifyFollowers,
+		&i.SpotifyID,
+		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const upsertMusician = `-- name: UpsertMusician :one
-INSERT INTO musicians (name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb)
-VALUES (?, ?, ?, ?, ?, ?, ?)
-ON CONFLICT (name) DO UPDATE SET
-  sort_name = excluded.sort_name,
-  summary = COALESCE(excluded.summary, musicians.summary),
+INSERT INTO musicians (name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, musicbrainz_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (name) WHERE musicbrainz_id IS NULL DO UPDATE SET
+  sort_name = CASE WHEN 'sort_name' IN (SELECT value FROM json_each(musicians.locked_fields)) THEN musicians.sort_name ELSE excluded.sort_name END,
+  summary = CASE WHEN 'summary' IN (SELECT value FROM json_each(musicians.locked_fields)) THEN musicians.summary ELSE COALESCE(excluded.summary, musicians.summary) END,
   spotify_popularity = COALESCE(excluded.spotify_popularity, musicians.spotify_popularity),
   spotify_followers = COALESCE(excluded.spotify_followers, musicians.spotify_followers),
   spotify_id = COALESCE(excluded.spotify_id, musicians.spotify_id),
   thumb = COALESCE(excluded.thumb, musicians.thumb),
   updated_at = CURRENT_TIMESTAMP
-RETURNING id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, created_at, updated_at
+RETURNING id, name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, scan_name, locked_fields, metadata_refreshed_at, musicbrainz_id, created_at, updated_at
 `
 
 type UpsertMusicianParams struct {
@@ -328,8 +665,11 @@ type UpsertMusicianParams struct {
 	SpotifyFollowers  sql.NullInt64   `json:"spotify_followers"`
 	SpotifyID         sql.NullString  `json:"spotify_id"`
 	Thumb             sql.NullString  `json:"thumb"`
+	MusicbrainzID     sql.NullString  `json:"musicbrainz_id"`
 }
 
+// Musicians with a MusicBrainz id are never merged by name, only musicians without one
+// conflict (see idx_musician_name_unmatched).
 func (q *Queries) UpsertMusician(ctx context.Context, arg UpsertMusicianParams) (Musician, error) {
 	row := q.queryRow(ctx, q.upsertMusicianStmt, upsertMusician,
 		arg.Name,
@@ -339,6 +679,7 @@ func (q *Queries) UpsertMusician(ctx context.Context, arg UpsertMusicianParams)
 		arg.SpotifyFollowers,
 		arg.SpotifyID,
 		arg.Thumb,
+		arg.MusicbrainzID,
 	)
 	var i Musician
 	err := row.Scan(
@@ -350,6 +691,10 @@ func (q *Queries) UpsertMusician(ctx context.Context, arg UpsertMusicianParams)
 		&i.SpotifyFollowers,
 		&i.SpotifyID,
 		&i.Thumb,
+		&i.ScanName,
+		&i.LockedFields,
+		&i.MetadataRefreshedAt,
+		&i.MusicbrainzID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -12,14 +12,44 @@ import (
 type Querier interface {
 	AddCollaborator(ctx context.Context, arg AddCollaboratorParams) (PlaylistCollaborator, error)
 	AddTrackToPlaylist(ctx context.Context, arg AddTrackToPlaylistParams) (PlaylistTrack, error)
+	// Sets the library folders added after the settings were created from the
+	// environment, keeping the ones already set.
+	BackfillLibraryDirs(ctx context.Context, arg BackfillLibraryDirsParams) (Setting, error)
 	CanUserEditPlaylist(ctx context.Context, arg CanUserEditPlaylistParams) (int64, error)
-	// Quick check if movie exists with same path and size (likely unchanged)
+	// Quick check if a book exists with the same total size and number of files (likely unchanged)
+	CheckAudiobookUnchanged(ctx context.Context, arg CheckAudiobookUnchangedParams) (int64, error)
+	// Quick check for an audio file split by a CUE sheet: its virtual tracks exist with the
+	// same file size, were scanned after the sheet was last modified and hold no scanned
+	// lyrics from another source than the file has now
+	CheckCueTracksUnchanged(ctx context.Context, arg CheckCueTracksUnchangedParams) (int64, error)
+	// Quick check if an extra exists with same path and size (likely unchanged)
+	CheckLocalExtraUnchanged(ctx context.Context, arg CheckLocalExtraUnchangedParams) (int64, error)
+	// Quick check that the lyrics of a track are up to date: they were read after the .lrc
+	// sidecar, if any, was last modified and from the source the file has now, tracks
+	// without lyrics counting as read from their tags. Lyrics entered by a user are never
+	// replaced by a scan.
+	CheckLyricsUnchanged(ctx context.Context, arg CheckLyricsUnchangedParams) (int64, error)
+	// Quick check if a movie version exists with same path and size (likely unchanged).
+	// Versions left in scan_errors, e.g. by a failed TMDB lookup, are scanned again.
 	CheckMovieUnchanged(ctx context.Context, arg CheckMovieUnchangedParams) (int64, error)
+	// Quick check if a file was skipped as a sample with the same size (likely unchanged)
+	CheckSampleFileUnchanged(ctx context.Context, arg CheckSampleFileUnchangedParams) (int64, error)
 	// Quick check if track exists with same path and size (likely unchanged)
 	CheckTrackUnchanged(ctx context.Context, arg CheckTrackUnchangedParams) (int64, error)
 	ClearPlaylist(ctx context.Context, playlistID int64) error
+	// Forgets a deleted download, downloaded_at is kept so it isn't downloaded again.
+	ClearPodcastEpisodeFile(ctx context.Context, id int64) error
+	// Plays of a user waiting to be scrobbled.
+	CountLastfmScrobbles(ctx context.Context, userID int64) (int64, error)
+	// Listens of a user waiting to be submitted.
+	CountListenbrainzListens(ctx context.Context, userID int64) (int64, error)
 	CountPlaylistTracks(ctx context.Context, playlistID int64) (int64, error)
 	CountPlaylistsByUserId(ctx context.Context, userID int64) (int64, error)
+	CreateAudiobookBookmark(ctx context.Context, arg CreateAudiobookBookmarkParams) (AudiobookBookmark, error)
+	CreateAudiobookChapter(ctx context.Context, arg CreateAudiobookChapterParams) error
+	CreateCollectionPart(ctx context.Context, arg CreateCollectionPartParams) error
+	CreateLastfmScrobble(ctx context.Context, arg CreateLastfmScrobbleParams) error
+	CreateListenbrainzListen(ctx context.Context, arg CreateListenbrainzListenParams) error
 	// Link a movie to an extra video (trailer/special feature). Idempotent.
 	CreateMovieExtraVideo(ctx context.Context, arg CreateMovieExtraVideoParams) error
 	// Link movie to genre via junction table
@@ -28,49 +58,156 @@ type Querier interface {
 	CreateMovieProductionCompany(ctx context.Context, arg CreateMovieProductionCompanyParams) error
 	CreateMusicianAlbum(ctx context.Context, arg CreateMusicianAlbumParams) error
 	CreatePlaylist(ctx context.Context, arg CreatePlaylistParams) (Playlist, error)
+	CreatePodcast(ctx context.Context, arg CreatePodcastParams) (Podcast, error)
 	CreateSettings(ctx context.Context, arg CreateSettingsParams) (Setting, error)
 	CreateTrackGenre(ctx context.Context, arg CreateTrackGenreParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	// Deleting an album will cascade delete all associated tracks
 	DeleteAlbum(ctx context.Context, id int64) error
-	// Delete all audio streams for a movie
-	DeleteMovieAudioStreams(ctx context.Context, movieID int64) error
-	// Delete all chapters for a movie
-	DeleteMovieChapters(ctx context.Context, movieID sql.NullInt64) error
+	// Removes every genre link of an album, before its genres are replaced
+	DeleteAlbumGenres(ctx context.Context, albumID int64) error
+	DeleteAudiobook(ctx context.Context, id int64) error
+	// Deletes one of the user's bookmarks in a book, returning its id so a missing bookmark can be told apart.
+	DeleteAudiobookBookmark(ctx context.Context, arg DeleteAudiobookBookmarkParams) (int64, error)
+	DeleteAudiobookChapters(ctx context.Context, audiobookID int64) error
+	// Remove one cast member of a movie
+	DeleteCastMember(ctx context.Context, id int64) error
+	DeleteCollectionParts(ctx context.Context, collectionID int64) error
+	// Remove one crew member of a movie
+	DeleteCrewMember(ctx context.Context, id int64) error
+	// Removes the virtual tracks of an audio file that is no longer split by a CUE sheet
+	DeleteCueTracks(ctx context.Context, sourcePath sql.NullString) error
+	DeleteExpiredApiCacheEntries(ctx context.Context, expiresAt string) error
+	// Drops plays too old for Last.fm to take, cutoff is a Unix time.
+	DeleteExpiredLastfmScrobbles(ctx context.Context, cutoff int64) error
+	// Deleting a genre cascades to its remaining track, album, musician, movie and alias links
+	DeleteGenre(ctx context.Context, id int64) error
+	DeleteLastfmScrobble(ctx context.Context, id int64) error
+	DeleteLastfmScrobblesByUser(ctx context.Context, userID int64) error
+	DeleteLastfmSession(ctx context.Context, userID int64) error
+	DeleteListenbrainzAccount(ctx context.Context, userID int64) error
+	DeleteListenbrainzListen(ctx context.Context, id int64) error
+	DeleteListenbrainzListensByUser(ctx context.Context, userID int64) error
+	// Delete all audio streams for a movie version
+	DeleteMediaVersionAudioStreams(ctx context.Context, mediaVersionID sql.NullInt64) error
+	DeleteMediaVersionByFilePath(ctx context.Context, filePath string) error
+	// Delete all chapters for a movie version
+	DeleteMediaVersionChapters(ctx context.Context, mediaVersionID sql.NullInt64) error
+	// Delete all subtitles for a movie version
+	DeleteMediaVersionSubtitles(ctx context.Context, mediaVersionID sql.NullInt64) error
+	// Delete all video streams for a movie version
+	DeleteMediaVersionVideoStreams(ctx context.Context, mediaVersionID sql.NullInt64) error
+	DeleteMovie(ctx context.Context, id int64) error
+	DeleteMovieCollection(ctx context.Context, movieID int64) error
 	// Remove all extra-video links for a movie (e.g. before re-scanning).
 	DeleteMovieExtraVideos(ctx context.Context, movieID int64) error
+	// Remove one genre link of a movie
+	DeleteMovieGenre(ctx context.Context, arg DeleteMovieGenreParams) error
 	// Remove all genre links for a movie
 	DeleteMovieGenres(ctx context.Context, movieID int64) error
 	// Remove all production company links for a movie
 	DeleteMovieProductionCompanies(ctx context.Context, movieID int64) error
-	// Delete all subtitles for a movie
-	DeleteMovieSubtitles(ctx context.Context, movieID int64) error
-	// Delete all video streams for a movie
-	DeleteMovieVideoStreams(ctx context.Context, movieID int64) error
+	// Remove one production company link of a movie
+	DeleteMovieProductionCompany(ctx context.Context, arg DeleteMovieProductionCompanyParams) error
+	// Removes every genre link of a musician, before its genres are replaced
+	DeleteMusicianGenres(ctx context.Context, musicianID int64) error
 	DeletePlaylist(ctx context.Context, arg DeletePlaylistParams) error
+	DeletePodcast(ctx context.Context, id int64) error
+	// Forgets the samples of a library, so they are probed again against a new minimum duration.
+	DeleteSampleFilesByLibrary(ctx context.Context, library string) error
+	// Clears a file's entry once it scans successfully.
+	DeleteScanError(ctx context.Context, filePath string) error
+	// Drops scanned lyrics the file no longer has. Lyrics entered by a user are kept.
+	DeleteScannedLyrics(ctx context.Context, trackID int64) error
+	// Removes the files that are no longer part of a book. file_paths is a JSON array of its current files.
+	DeleteStaleAudiobookFiles(ctx context.Context, arg DeleteStaleAudiobookFilesParams) error
+	// Removes the virtual tracks of an audio file that are no longer in its CUE sheet.
+	// file_paths is a JSON array of the file paths of the tracks still in the sheet.
+	DeleteStaleCueTracks(ctx context.Context, arg DeleteStaleCueTracksParams) error
+	// Removes a track indexed as a whole file before a CUE sheet split it
+	DeleteTrackByFilePath(ctx context.Context, filePath string) error
 	DeleteTrackGenres(ctx context.Context, trackID int64) error
-	// Deletes all genre relationships for a track except the specified genre.
-	// Used to efficiently update genres: only removes stale relationships.
-	DeleteTrackGenresExcept(ctx context.Context, arg DeleteTrackGenresExceptParams) error
 	DeleteUser(ctx context.Context, id int64) error
+	// Fills the fields the primary file of a movie left empty with the metadata of another
+	// of its versions. Fields already set and hand-edited fields are kept.
+	FillMovieMetadata(ctx context.Context, arg FillMovieMetadataParams) (Movie, error)
 	GetAdminUser(ctx context.Context) (User, error)
+	// Finds an album without an album artist by the folder holding its tracks.
+	GetAlbumByDirectory(ctx context.Context, arg GetAlbumByDirectoryParams) (Album, error)
 	GetAlbumByID(ctx context.Context, id int64) (Album, error)
+	GetAlbumByMusicbrainzID(ctx context.Context, musicbrainzAlbumID sql.NullString) (Album, error)
+	// Finds an album renamed by hand through the tag title the scanner still reads.
+	GetAlbumByScanTitle(ctx context.Context, arg GetAlbumByScanTitleParams) (Album, error)
 	GetAlbumBySpotifyID(ctx context.Context, spotifyID sql.NullString) (Album, error)
 	// Returns albums sorted alphabetically by title with pagination.
 	// Non-alphabetic titles (numbers, symbols) are grouped under '#' and sorted first.
+	// A NULL is_compilation returns compilations and regular albums alike.
 	GetAlbumsAlphabetical(ctx context.Context, arg GetAlbumsAlphabeticalParams) ([]GetAlbumsAlphabeticalRow, error)
 	// Returns all albums associated with a musician via the musician_albums join table
 	// Sorted by release date (newest first), then by title
 	GetAlbumsByMusicianID(ctx context.Context, musicianID int64) ([]GetAlbumsByMusicianIDRow, error)
+	GetAlbumsByTitle(ctx context.Context, arg GetAlbumsByTitleParams) ([]Album, error)
 	GetAlbumsCount(ctx context.Context) (int64, error)
 	GetAllPlaylistTracks(ctx context.Context, playlistID int64) ([]GetAllPlaylistTracksRow, error)
 	// Returns all track file paths and sizes for efficient batch skip-checking during scans.
 	// Used to pre-load existing tracks into memory, replacing N individual queries with 1.
 	GetAllTrackPathsAndSizes(ctx context.Context) ([]GetAllTrackPathsAndSizesRow, error)
+	// The scrobble info of every track, to match listens of an imported history.
+	GetAllTrackScrobbleInfo(ctx context.Context) ([]GetAllTrackScrobbleInfoRow, error)
+	GetApiCacheEntry(ctx context.Context, arg GetApiCacheEntryParams) ([]byte, error)
+	GetArtistByID(ctx context.Context, id int64) (Artist, error)
+	GetAudioStreamsByMediaVersionID(ctx context.Context, mediaVersionID sql.NullInt64) ([]AudioStream, error)
+	GetAudiobookBookmarks(ctx context.Context, arg GetAudiobookBookmarksParams) ([]AudiobookBookmark, error)
+	GetAudiobookByID(ctx context.Context, id int64) (GetAudiobookByIDRow, error)
+	GetAudiobookChapter(ctx context.Context, arg GetAudiobookChapterParams) (AudiobookChapter, error)
+	GetAudiobookChapters(ctx context.Context, audiobookID int64) ([]AudiobookChapter, error)
+	GetAudiobookFile(ctx context.Context, id int64) (AudiobookFile, error)
+	GetAudiobookFiles(ctx context.Context, audiobookID int64) ([]AudiobookFile, error)
+	GetAudiobookProgress(ctx context.Context, arg GetAudiobookProgressParams) (AudiobookProgress, error)
+	// Returns books sorted by title with the user's progress, position 0 when not started.
+	GetAudiobooksAlphabetical(ctx context.Context, arg GetAudiobooksAlphabeticalParams) ([]GetAudiobooksAlphabeticalRow, error)
+	// Returns an author's books with the user's progress, grouped by series in reading order.
+	GetAudiobooksByAuthor(ctx context.Context, arg GetAudiobooksByAuthorParams) ([]GetAudiobooksByAuthorRow, error)
+	// Books stored under a directory (pass it with a trailing separator), used to find
+	// the books gone from it.
+	GetAudiobooksByDirectory(ctx context.Context, dir string) ([]GetAudiobooksByDirectoryRow, error)
+	GetAudiobooksCount(ctx context.Context) (int64, error)
+	// Returns the books the user started but hasn't finished, most recently played first.
+	GetAudiobooksInProgress(ctx context.Context, arg GetAudiobooksInProgressParams) ([]GetAudiobooksInProgressRow, error)
+	GetAuthorByID(ctx context.Context, id int64) (Author, error)
+	// Returns the authors with at least one book, sorted by name, with their book counts.
+	GetAuthorsAlphabetical(ctx context.Context, arg GetAuthorsAlphabeticalParams) ([]GetAuthorsAlphabeticalRow, error)
+	GetAuthorsCount(ctx context.Context) (int64, error)
+	// Roles of a person in the library's movies, newest first, undated ones last.
+	GetCastByArtistID(ctx context.Context, artistID int64) ([]GetCastByArtistIDRow, error)
 	// Cast for a movie with artist name and profile (for details view).
 	GetCastByMovieID(ctx context.Context, movieID int64) ([]GetCastByMovieIDRow, error)
+	GetCollectionByID(ctx context.Context, id int64) (Collection, error)
+	// Returns the movies of a collection in the library by release date, undated ones last.
+	GetCollectionMovies(ctx context.Context, collectionID int64) ([]GetCollectionMoviesRow, error)
+	// Returns the collections with movies in the library sorted by name, with how many
+	// of their movies the library has and how many it misses.
+	GetCollections(ctx context.Context, arg GetCollectionsParams) ([]GetCollectionsRow, error)
+	GetCollectionsCount(ctx context.Context) (int64, error)
+	// Jobs of a person in the library's movies by department, newest first, undated ones last.
+	GetCrewByArtistID(ctx context.Context, artistID int64) ([]GetCrewByArtistIDRow, error)
 	// Crew for a movie with artist name and profile (for details view).
 	GetCrewByMovieID(ctx context.Context, movieID int64) ([]GetCrewByMovieIDRow, error)
+	// Returns the downloaded episodes of a podcast, newest first, with how many users
+	// played them and how many are still listening.
+	GetDownloadedPodcastEpisodes(ctx context.Context, podcastID int64) ([]GetDownloadedPodcastEpisodesRow, error)
+	// Queued plays of linked accounts whose next attempt is due, grouped by user and
+	// oldest first as Last.fm expects them.
+	GetDueLastfmScrobbles(ctx context.Context, arg GetDueLastfmScrobblesParams) ([]GetDueLastfmScrobblesRow, error)
+	// Queued listens of users with a token whose next attempt is due, grouped by user and
+	// oldest first.
+	GetDueListenbrainzListens(ctx context.Context, arg GetDueListenbrainzListensParams) ([]GetDueListenbrainzListensRow, error)
+	GetFilteredAlbumsCount(ctx context.Context, isCompilation sql.NullBool) (int64, error)
+	// Returns the normalized keys of every genre of a type, grouped by genre in GetGenres
+	GetGenreAliasesByType(ctx context.Context, genreType string) ([]GetGenreAliasesByTypeRow, error)
+	// Resolves a normalized genre key (see helpers.NormalizeKey) to its canonical genre
+	GetGenreByAlias(ctx context.Context, arg GetGenreByAliasParams) (Genre, error)
+	GetGenreByID(ctx context.Context, id int64) (Genre, error)
 	GetGenresByAlbumID(ctx context.Context, albumID sql.NullInt64) ([]GetGenresByAlbumIDRow, error)
 	// Returns genres directly associated with an album via album_genres table
 	GetGenresByAlbumIDDirect(ctx context.Context, albumID int64) ([]GetGenresByAlbumIDDirectRow, error)
@@ -78,24 +215,50 @@ type Querier interface {
 	GetGenresByMovieID(ctx context.Context, movieID int64) ([]GetGenresByMovieIDRow, error)
 	// Returns all genres associated with a musician
 	GetGenresByMusicianID(ctx context.Context, musicianID int64) ([]GetGenresByMusicianIDRow, error)
+	// Returns all genres of a type with how many tracks, albums, musicians and movies use each
+	GetGenresWithCounts(ctx context.Context, genreType string) ([]GetGenresWithCountsRow, error)
+	GetLastfmSession(ctx context.Context, userID int64) (LastfmSession, error)
 	GetLatestAlbums(ctx context.Context) ([]GetLatestAlbumsRow, error)
 	GetLatestMovies(ctx context.Context) ([]GetLatestMoviesRow, error)
 	GetLikedTrackIDsByUserID(ctx context.Context, userID int64) ([]int64, error)
 	GetLikedTracksByUserID(ctx context.Context, userID int64) ([]GetLikedTracksByUserIDRow, error)
+	GetListenbrainzAccount(ctx context.Context, userID int64) (ListenbrainzAccount, error)
+	GetLocalExtraByID(ctx context.Context, id int64) (LocalExtra, error)
+	// Local extras of a movie (for details view).
+	GetLocalExtrasByMovieID(ctx context.Context, movieID int64) ([]LocalExtra, error)
 	GetMaxPosition(ctx context.Context, playlistID int64) (interface{}, error)
+	GetMediaVersionByFilePath(ctx context.Context, filePath string) (MediaVersion, error)
+	GetMediaVersionByID(ctx context.Context, id int64) (MediaVersion, error)
+	// Versions stored under a directory (pass it with a trailing separator), used to
+	// find the movie that extras found next to it belong to, and the files gone from it.
+	GetMediaVersionsByDirectory(ctx context.Context, dir string) ([]GetMediaVersionsByDirectoryRow, error)
+	// Versions of a movie with the properties of their first video stream,
+	// used to list versions and pick one for playback.
+	GetMediaVersionsByMovieID(ctx context.Context, movieID int64) ([]GetMediaVersionsByMovieIDRow, error)
+	// Returns the movies of a collection the library doesn't have by release date,
+	// undated ones last.
+	GetMissingCollectionParts(ctx context.Context, collectionID int64) ([]CollectionPart, error)
 	GetMovieByFilePath(ctx context.Context, filePath string) (Movie, error)
 	GetMovieByID(ctx context.Context, id int64) (Movie, error)
+	// Used to group edition-tagged files of a movie that has no TMDB match.
+	GetMovieByTitleAndYear(ctx context.Context, arg GetMovieByTitleAndYearParams) (Movie, error)
 	// When multiple rows share the same tmdb_id, returns the one with smallest id.
 	GetMovieByTmdbID(ctx context.Context, tmdbID sql.NullInt64) (Movie, error)
 	// List all extra videos (trailers, special features) linked to a movie.
 	GetMovieExtraVideos(ctx context.Context, movieID int64) ([]ExtraVideo, error)
+	// Returns TMDB-matched movies linked to no collection, paged by id.
+	GetMoviesWithoutCollection(ctx context.Context, arg GetMoviesWithoutCollectionParams) ([]GetMoviesWithoutCollectionRow, error)
 	// Returns a single musician by ID with full details
 	GetMusicianByID(ctx context.Context, id int64) (Musician, error)
+	GetMusicianByMusicbrainzID(ctx context.Context, musicbrainzID sql.NullString) (Musician, error)
+	// Finds a musician renamed by hand through the tag name the scanner still reads.
+	GetMusicianByScanName(ctx context.Context, scanName sql.NullString) (Musician, error)
 	GetMusicianBySpotifyID(ctx context.Context, spotifyID sql.NullString) (Musician, error)
 	// Returns musicians sorted alphabetically by sort_name with pagination.
 	// Non-alphabetic names (numbers, symbols) are grouped under '#' and sorted first.
 	GetMusiciansAlphabetical(ctx context.Context, arg GetMusiciansAlphabeticalParams) ([]GetMusiciansAlphabeticalRow, error)
 	GetMusiciansByAlbumID(ctx context.Context, albumID int64) ([]GetMusiciansByAlbumIDRow, error)
+	GetMusiciansByName(ctx context.Context, name string) ([]Musician, error)
 	GetMusiciansCount(ctx context.Context) (int64, error)
 	GetOrCreateGenre(ctx context.Context, arg GetOrCreateGenreParams) (Genre, error)
 	GetPlaylistById(ctx context.Context, id int64) (Playlist, error)
@@ -104,22 +267,57 @@ type Querier interface {
 	GetPlaylistTracksInfinite(ctx context.Context, arg GetPlaylistTracksInfiniteParams) ([]GetPlaylistTracksInfiniteRow, error)
 	GetPlaylistsByUserId(ctx context.Context, userID int64) ([]GetPlaylistsByUserIdRow, error)
 	GetPlaylistsWithCollaboratorAccess(ctx context.Context, arg GetPlaylistsWithCollaboratorAccessParams) ([]GetPlaylistsWithCollaboratorAccessRow, error)
+	GetPodcastByFeedURL(ctx context.Context, feedUrl string) (Podcast, error)
+	GetPodcastByID(ctx context.Context, id int64) (Podcast, error)
+	GetPodcastEpisodeByID(ctx context.Context, id int64) (PodcastEpisode, error)
+	// Returns a podcast's episodes newest first with the user's progress.
+	GetPodcastEpisodes(ctx context.Context, arg GetPodcastEpisodesParams) ([]GetPodcastEpisodesRow, error)
+	GetPodcastEpisodesCount(ctx context.Context, podcastID int64) (int64, error)
+	// Returns the episodes among the podcast's newest that were never downloaded.
+	GetPodcastEpisodesToDownload(ctx context.Context, arg GetPodcastEpisodesToDownloadParams) ([]PodcastEpisode, error)
+	// Returns the subscribed podcasts sorted by title, with their episode counts.
+	GetPodcasts(ctx context.Context) ([]GetPodcastsRow, error)
+	// Returns the podcasts never polled or last polled before the cutoff, oldest first.
+	GetPodcastsDueForPoll(ctx context.Context, cutoff string) ([]Podcast, error)
 	// Production companies linked to a movie (for details view).
 	GetProductionCompaniesByMovieID(ctx context.Context, movieID int64) ([]GetProductionCompaniesByMovieIDRow, error)
 	GetRandomTracks(ctx context.Context, limit int64) ([]GetRandomTracksRow, error)
+	GetScanErrorByID(ctx context.Context, id int64) (ScanError, error)
+	// Returns the most recent failures first. An empty library returns both libraries.
+	GetScanErrors(ctx context.Context, arg GetScanErrorsParams) ([]ScanError, error)
+	GetScanErrorsCount(ctx context.Context, library string) (int64, error)
 	GetSettings(ctx context.Context) (Setting, error)
+	// Returns TMDB-matched movies last refreshed before the cutoff, paged by id.
+	GetStaleMovies(ctx context.Context, arg GetStaleMoviesParams) ([]Movie, error)
+	// Returns Spotify-matched musicians last refreshed before the cutoff, paged by id.
+	GetStaleMusicians(ctx context.Context, arg GetStaleMusiciansParams) ([]Musician, error)
+	GetSubtitlesByMediaVersionID(ctx context.Context, mediaVersionID sql.NullInt64) ([]Subtitle, error)
 	GetTrack(ctx context.Context, id int64) (Track, error)
+	GetTrackLyrics(ctx context.Context, trackID int64) (TrackLyric, error)
+	// What scrobbling services are told about a track: its artist, album and album artist,
+	// and their MusicBrainz IDs.
+	GetTrackScrobbleInfo(ctx context.Context, id int64) (GetTrackScrobbleInfoRow, error)
 	GetTracksAlphabetical(ctx context.Context, arg GetTracksAlphabeticalParams) ([]GetTracksAlphabeticalRow, error)
 	GetTracksByAlbumID(ctx context.Context, albumID sql.NullInt64) ([]Track, error)
 	// Returns all tracks by a musician, sorted alphabetically by sort_title
 	GetTracksByMusicianID(ctx context.Context, musicianID sql.NullInt64) ([]GetTracksByMusicianIDRow, error)
 	GetTracksCount(ctx context.Context) (int64, error)
+	// Returns the genres no normalized key resolves to yet, oldest first, e.g. genres
+	// created before aliases existed
+	GetUnaliasedGenres(ctx context.Context) ([]Genre, error)
+	// Finds an album without a MusicBrainz release id by title and album artist, or by the
+	// tag title of one renamed by hand.
+	GetUnmatchedAlbum(ctx context.Context, arg GetUnmatchedAlbumParams) (Album, error)
+	// Finds a musician without a MusicBrainz id by name, or by the tag name of one renamed by hand.
+	GetUnmatchedMusicianByName(ctx context.Context, name string) (Musician, error)
 	GetUser(ctx context.Context, id int64) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	// Returns listening stats grouped by date for charts
 	GetUserListeningHistoryByPeriod(ctx context.Context, arg GetUserListeningHistoryByPeriodParams) ([]GetUserListeningHistoryByPeriodRow, error)
 	// Returns overall listening statistics for a user
 	GetUserListeningStats(ctx context.Context, arg GetUserListeningStatsParams) (GetUserListeningStatsRow, error)
+	// Returns when the user played each track, to skip plays imported before
+	GetUserPlayTimes(ctx context.Context, userID int64) ([]GetUserPlayTimesRow, error)
 	// Returns the user's recently played tracks
 	GetUserRecentlyPlayed(ctx context.Context, arg GetUserRecentlyPlayedParams) ([]GetUserRecentlyPlayedRow, error)
 	// Returns the user's most listened albums
@@ -135,6 +333,9 @@ type Querier interface {
 	GetUserTopTracks(ctx context.Context, arg GetUserTopTracksParams) ([]GetUserTopTracksRow, error)
 	// Returns the play count for a specific track
 	GetUserTrackPlayCount(ctx context.Context, arg GetUserTrackPlayCountParams) (int64, error)
+	GetVideoStreamsByMediaVersionID(ctx context.Context, mediaVersionID sql.NullInt64) ([]VideoStream, error)
+	// Records a play of an imported listening history at the time it happened
+	ImportPlayEvent(ctx context.Context, arg ImportPlayEventParams) error
 	InsertAudioStream(ctx context.Context, arg InsertAudioStreamParams) (AudioStream, error)
 	InsertChapter(ctx context.Context, arg InsertChapterParams) (Chapter, error)
 	InsertSubtitle(ctx context.Context, arg InsertSubtitleParams) (Subtitle, error)
@@ -143,38 +344,145 @@ type Querier interface {
 	IsTrackLiked(ctx context.Context, arg IsTrackLikedParams) (bool, error)
 	IsUserCollaborator(ctx context.Context, arg IsUserCollaboratorParams) (int64, error)
 	LikeTrack(ctx context.Context, arg LikeTrackParams) error
+	MarkAlbumCompilation(ctx context.Context, id int64) error
+	// Re-links albums from the source genre to the target genre (see MergeTrackGenres)
+	MergeAlbumGenres(ctx context.Context, arg MergeAlbumGenresParams) error
+	// Moves the audio streams of the source movie's versions along with them (see MergeMediaVersions)
+	MergeAudioStreams(ctx context.Context, arg MergeAudioStreamsParams) error
+	// Moves the chapters of the source movie's versions along with them (see MergeMediaVersions)
+	MergeChapters(ctx context.Context, arg MergeChaptersParams) error
+	// Points every alias of the source genre at the target genre
+	MergeGenreAliases(ctx context.Context, arg MergeGenreAliasesParams) error
+	// Moves the local extras of the source movie to the target movie (see MergeMediaVersions)
+	MergeLocalExtras(ctx context.Context, arg MergeLocalExtrasParams) error
+	// Moves the versions of the source movie to the target movie, e.g. when both are matched to one film
+	MergeMediaVersions(ctx context.Context, arg MergeMediaVersionsParams) error
+	// Re-links movies from the source genre to the target genre (see MergeTrackGenres)
+	MergeMovieGenres(ctx context.Context, arg MergeMovieGenresParams) error
+	// Re-links musicians from the source genre to the target genre (see MergeTrackGenres)
+	MergeMusicianGenres(ctx context.Context, arg MergeMusicianGenresParams) error
+	// Moves the subtitles of the source movie's versions along with them (see MergeMediaVersions)
+	MergeSubtitles(ctx context.Context, arg MergeSubtitlesParams) error
+	// Re-links tracks from the source genre to the target genre.
+	// Rows whose track already has the target genre are left behind and removed by DeleteGenre's cascade.
+	MergeTrackGenres(ctx context.Context, arg MergeTrackGenresParams) error
+	// Moves the video streams of the source movie's versions along with them (see MergeMediaVersions)
+	MergeVideoStreams(ctx context.Context, arg MergeVideoStreamsParams) error
+	// Recomputes the user's aggregated stats from the play history, e.g. after an import
+	RebuildUserTrackStats(ctx context.Context, userID int64) error
 	// ============================================================================
 	// PLAY HISTORY RECORDING
 	// ============================================================================
 	// Records a new play event when a track is played
 	RecordPlayEvent(ctx context.Context, arg RecordPlayEventParams) error
+	RecordSampleFile(ctx context.Context, arg RecordSampleFileParams) error
+	// Records a failed file, counting the attempts since it first failed.
+	RecordScanError(ctx context.Context, arg RecordScanErrorParams) error
+	// Stores re-fetched TMDB metadata. Hand-edited fields are kept.
+	RefreshMovieMetadata(ctx context.Context, arg RefreshMovieMetadataParams) (Movie, error)
+	// Stores re-fetched Spotify metadata. A hand-edited summary is kept.
+	RefreshMusicianMetadata(ctx context.Context, arg RefreshMusicianMetadataParams) (Musician, error)
 	RemoveCollaborator(ctx context.Context, arg RemoveCollaboratorParams) error
 	RemoveTrackFromPlaylist(ctx context.Context, arg RemoveTrackFromPlaylistParams) error
+	// Puts a play Last.fm couldn't take back in the queue until next_attempt_at.
+	RetryLastfmScrobble(ctx context.Context, arg RetryLastfmScrobbleParams) error
+	// Puts a listen ListenBrainz couldn't take back in the queue until next_attempt_at.
+	RetryListenbrainzListen(ctx context.Context, arg RetryListenbrainzListenParams) error
+	// People credited in the library's movies whose name contains the query (an empty one
+	// matches everyone), the most credited first, with the number of movies they are in.
+	SearchArtists(ctx context.Context, arg SearchArtistsParams) ([]SearchArtistsRow, error)
+	SearchArtistsCount(ctx context.Context, query string) (int64, error)
+	SetAlbumDirectory(ctx context.Context, arg SetAlbumDirectoryParams) error
+	SetAlbumMusicbrainzIDs(ctx context.Context, arg SetAlbumMusicbrainzIDsParams) (Album, error)
+	SetCollectionsBackfilled(ctx context.Context, id int64) (Setting, error)
+	SetMovieCollection(ctx context.Context, arg SetMovieCollectionParams) error
+	SetMusicianMusicbrainzID(ctx context.Context, arg SetMusicianMusicbrainzIDParams) (Musician, error)
+	SetPodcastEpisodeFile(ctx context.Context, arg SetPodcastEpisodeFileParams) error
+	SetTrackLyricsScanned(ctx context.Context, id int64) error
 	ShiftPositionsDown(ctx context.Context, arg ShiftPositionsDownParams) error
 	ShiftPositionsUp(ctx context.Context, arg ShiftPositionsUpParams) error
+	// Marks a movie as refreshed when TMDB had nothing new.
+	TouchMovieMetadataRefreshed(ctx context.Context, id int64) error
+	// Marks a musician as refreshed when Spotify had nothing new.
+	TouchMusicianMetadataRefreshed(ctx context.Context, id int64) error
 	UnlikeTrack(ctx context.Context, arg UnlikeTrackParams) error
+	// Applies a hand edit. The caller passes every editable field and the new lock set.
+	UpdateAlbumMetadata(ctx context.Context, arg UpdateAlbumMetadataParams) (Album, error)
+	// Matches an album to a Spotify album chosen by hand and locks the match, so scans
+	// keep it.
+	UpdateAlbumSpotifyMatch(ctx context.Context, arg UpdateAlbumSpotifyMatchParams) (Album, error)
 	UpdateCollaboratorPermission(ctx context.Context, arg UpdateCollaboratorPermissionParams) error
+	// Stores the outcome of a finished history import.
+	UpdateListenbrainzImport(ctx context.Context, arg UpdateListenbrainzImportParams) error
+	UpdateMetadataLanguageSettings(ctx context.Context, arg UpdateMetadataLanguageSettingsParams) (Setting, error)
+	UpdateMetadataRefreshSettings(ctx context.Context, arg UpdateMetadataRefreshSettingsParams) (Setting, error)
+	// Points a movie at another of its versions, after the file it mirrored is gone.
+	UpdateMovieFile(ctx context.Context, arg UpdateMovieFileParams) error
+	// Applies a manual TMDB match: every TMDB field is replaced rather than merged,
+	// and the match is locked so later scans keep it.
+	UpdateMovieMatch(ctx context.Context, arg UpdateMovieMatchParams) (Movie, error)
+	// Applies a hand edit. The caller passes every editable field and the new lock set.
+	UpdateMovieMetadata(ctx context.Context, arg UpdateMovieMetadataParams) (Movie, error)
+	// Applies a hand edit. The caller passes every editable field and the new lock set.
+	UpdateMusicianMetadata(ctx context.Context, arg UpdateMusicianMetadataParams) (Musician, error)
 	UpdatePlaylist(ctx context.Context, arg UpdatePlaylistParams) (Playlist, error)
 	UpdatePlaylistTimestamp(ctx context.Context, id int64) error
+	UpdatePodcastDownloadSettings(ctx context.Context, arg UpdatePodcastDownloadSettingsParams) (Podcast, error)
+	// Stores a successfully fetched feed with the validators for the next conditional request.
+	UpdatePodcastFeed(ctx context.Context, arg UpdatePodcastFeedParams) (Podcast, error)
+	// Records a poll that didn't change the feed: unmodified, or failed with last_error.
+	UpdatePodcastPolled(ctx context.Context, arg UpdatePodcastPolledParams) error
+	UpdatePodcastSettings(ctx context.Context, arg UpdatePodcastSettingsParams) (Setting, error)
+	UpdateScannerSettings(ctx context.Context, arg UpdateScannerSettingsParams) (Setting, error)
+	// Applies a hand edit. The caller passes every editable field and the new lock set.
+	UpdateTrackMetadata(ctx context.Context, arg UpdateTrackMetadataParams) (Track, error)
 	UpdateTrackPosition(ctx context.Context, arg UpdateTrackPositionParams) error
 	UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) (User, error)
 	UpdateUserName(ctx context.Context, arg UpdateUserNameParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	// Albums with a MusicBrainz release id are never merged by title and album artist, only
+	// albums without one conflict (see idx_album_title_musician_unmatched). A scored Spotify
+	// lookup replaces the Spotify fields, rejected matches clearing them; without a score
+	// they are kept.
 	UpsertAlbum(ctx context.Context, arg UpsertAlbumParams) (Album, error)
 	// Creates a relationship between an album and a genre (idempotent)
 	UpsertAlbumGenre(ctx context.Context, arg UpsertAlbumGenreParams) error
+	UpsertApiCacheEntry(ctx context.Context, arg UpsertApiCacheEntryParams) error
 	UpsertArtist(ctx context.Context, arg UpsertArtistParams) (Artist, error)
+	UpsertAudiobook(ctx context.Context, arg UpsertAudiobookParams) (Audiobook, error)
+	UpsertAudiobookFile(ctx context.Context, arg UpsertAudiobookFileParams) (AudiobookFile, error)
+	UpsertAudiobookProgress(ctx context.Context, arg UpsertAudiobookProgressParams) (AudiobookProgress, error)
+	UpsertAuthor(ctx context.Context, arg UpsertAuthorParams) (Author, error)
 	UpsertCast(ctx context.Context, arg UpsertCastParams) (Cast, error)
+	UpsertCollection(ctx context.Context, arg UpsertCollectionParams) (Collection, error)
 	UpsertCrew(ctx context.Context, arg UpsertCrewParams) (Crew, error)
 	// Insert or update an extra video by external_id (e.g. TMDB video id). Use for trailers/special features.
 	// Call with a non-null external_id so conflicts are detected; then link via CreateMovieExtraVideo.
 	UpsertExtraVideo(ctx context.Context, arg UpsertExtraVideoParams) (ExtraVideo, error)
+	// Points a normalized genre key at a canonical genre, replacing any previous target
+	UpsertGenreAlias(ctx context.Context, arg UpsertGenreAliasParams) error
+	// Links a Last.fm account, replacing the one the user linked before.
+	UpsertLastfmSession(ctx context.Context, arg UpsertLastfmSessionParams) (LastfmSession, error)
+	// Saves the token of a user, replacing the one entered before. The outcome of the
+	// last import is kept.
+	UpsertListenbrainzAccount(ctx context.Context, arg UpsertListenbrainzAccountParams) (ListenbrainzAccount, error)
+	UpsertLocalExtra(ctx context.Context, arg UpsertLocalExtraParams) (LocalExtra, error)
+	// Insert or update the version backed by a file. A file re-scanned into a different
+	// logical movie (e.g. after a TMDB rematch) moves with it.
+	UpsertMediaVersion(ctx context.Context, arg UpsertMediaVersionParams) (MediaVersion, error)
 	UpsertMovie(ctx context.Context, arg UpsertMovieParams) (Movie, error)
+	// Musicians with a MusicBrainz id are never merged by name, only musicians without one
+	// conflict (see idx_musician_name_unmatched).
 	UpsertMusician(ctx context.Context, arg UpsertMusicianParams) (Musician, error)
 	// Creates a relationship between a musician and a genre (idempotent)
 	UpsertMusicianGenre(ctx context.Context, arg UpsertMusicianGenreParams) error
+	UpsertPodcastEpisode(ctx context.Context, arg UpsertPodcastEpisodeParams) error
+	UpsertPodcastEpisodeProgress(ctx context.Context, arg UpsertPodcastEpisodeProgressParams) (PodcastEpisodeProgress, error)
 	UpsertProductionCompany(ctx context.Context, arg UpsertProductionCompanyParams) (ProductionCompany, error)
+	// Stores lyrics read by the scanner unless a user has entered lyrics for the track.
+	UpsertScannedLyrics(ctx context.Context, arg UpsertScannedLyricsParams) error
 	UpsertTrack(ctx context.Context, arg UpsertTrackParams) (Track, error)
+	UpsertUserLyrics(ctx context.Context, arg UpsertUserLyricsParams) (TrackLyric, error)
 	// Updates aggregated stats when a play event is recorded
 	UpsertUserTrackStats(ctx context.Context, arg UpsertUserTrackStatsParams) error
 }
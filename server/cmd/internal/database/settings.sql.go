@@ -10,6 +10,70 @@ import (
 	"database/sql"
 )
 
+const backfillLibraryDirs = `-- name: BackfillLibraryDirs :one
+UPDATE settings
+SET
+  audiobooks_dir = COALESCE(audiobooks_dir, ?),
+  podcasts_dir = COALESCE(podcasts_dir, ?),
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
+`
+
+type BackfillLibraryDirsParams struct {
+	AudiobooksDir sql.NullString `json:"audiobooks_dir"`
+	PodcastsDir   sql.NullString `json:"podcasts_dir"`
+	ID            int64          `json:"id"`
+}
+
+// Sets the library folders added after the settings were created from the
+// environment, keeping the ones already set.
+func (q *Queries) BackfillLibraryDirs(ctx context.Context, arg BackfillLibraryDirsParams) (Setting, error) {
+	row := q.queryRow(ctx, q.backfillLibraryDirsStmt, backfillLibraryDirs, arg.AudiobooksDir, arg.PodcastsDir, arg.ID)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.TmdbKey,
+		&i.JellyfinToken,
+		&i.SpotifyClientID,
+		&i.SpotifyClientSecret,
+		&i.HardwareAccelerationDevice,
+		&i.EnableLogger,
+		&i.EnableWatcher,
+		&i.DownloadImages,
+		&i.MoviesDir,
+		&i.ShowsDir,
+		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
+		&i.StaticDir,
+		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createSettings = `-- name: CreateSettings :one
 INSERT INTO
   settings (
@@ -24,11 +88,13 @@ INSERT INTO
     movies_dir,
     shows_dir,
     music_dir,
+    audiobooks_dir,
+    podcasts_dir,
     static_dir,
     logs_dir
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, static_dir, logs_dir, created_at, updated_at
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
 `
 
 type CreateSettingsParams struct {
@@ -43,6 +109,8 @@ type CreateSettingsParams struct {
 	MoviesDir                  sql.NullString `json:"movies_dir"`
 	ShowsDir                   sql.NullString `json:"shows_dir"`
 	MusicDir                   sql.NullString `json:"music_dir"`
+	AudiobooksDir              sql.NullString `json:"audiobooks_dir"`
+	PodcastsDir                sql.NullString `json:"podcasts_dir"`
 	StaticDir                  string         `json:"static_dir"`
 	LogsDir                    string         `json:"logs_dir"`
 }
@@ -60,6 +128,8 @@ func (q *Queries) CreateSettings(ctx context.Context, arg CreateSettingsParams)
 		arg.MoviesDir,
 		arg.ShowsDir,
 		arg.MusicDir,
+		arg.AudiobooksDir,
+		arg.PodcastsDir,
 		arg.StaticDir,
 		arg.LogsDir,
 	)
@@ -77,8 +147,30 @@ func (q *Queries) CreateSettings(ctx context.Context, arg CreateSettingsParams)
 		&i.MoviesDir,
 		&i.ShowsDir,
 		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
 		&i.StaticDir,
 		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -87,7 +179,7 @@ func (q *Queries) CreateSettings(ctx context.Context, arg CreateSettingsParams)
 
 const getSettings = `-- name: GetSettings :one
 SELECT
-  id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, static_dir, logs_dir, created_at, updated_at
+  id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
 FROM
   settings
 LIMIT
@@ -110,8 +202,381 @@ func (q *Queries) GetSettings(ctx context.Context) (Setting, error) {
 		&i.MoviesDir,
 		&i.ShowsDir,
 		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
+		&i.StaticDir,
+		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setCollectionsBackfilled = `-- name: SetCollectionsBackfilled :one
+UPDATE settings
+SET
+  collections_backfilled_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
+`
+
+func (q *Queries) SetCollectionsBackfilled(ctx context.Context, id int64) (Setting, error) {
+	row := q.queryRow(ctx, q.setCollectionsBackfilledStmt, setCollectionsBackfilled, id)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.TmdbKey,
+		&i.JellyfinToken,
+		&i.SpotifyClientID,
+		&i.SpotifyClientSecret,
+		&i.HardwareAccelerationDevice,
+		&i.EnableLogger,
+		&i.EnableWatcher,
+		&i.DownloadImages,
+		&i.MoviesDir,
+		&i.ShowsDir,
+		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
+		&i.StaticDir,
+		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateMetadataLanguageSettings = `-- name: UpdateMetadataLanguageSettings :one
+UPDATE settings
+SET
+  metadata_language = ?,
+  metadata_fallback_language = ?,
+  certification_country = ?,
+  movies_metadata_language = ?,
+  movies_metadata_fallback_language = ?,
+  movies_certification_country = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
+`
+
+type UpdateMetadataLanguageSettingsParams struct {
+	MetadataLanguage               string         `json:"metadata_language"`
+	MetadataFallbackLanguage       string         `json:"metadata_fallback_language"`
+	CertificationCountry           string         `json:"certification_country"`
+	MoviesMetadataLanguage         sql.NullString `json:"movies_metadata_language"`
+	MoviesMetadataFallbackLanguage sql.NullString `json:"movies_metadata_fallback_language"`
+	MoviesCertificationCountry     sql.NullString `json:"movies_certification_country"`
+	ID                             int64          `json:"id"`
+}
+
+func (q *Queries) UpdateMetadataLanguageSettings(ctx context.Context, arg UpdateMetadataLanguageSettingsParams) (Setting, error) {
+	row := q.queryRow(ctx, q.updateMetadataLanguageSettingsStmt, updateMetadataLanguageSettings,
+		arg.MetadataLanguage,
+		arg.MetadataFallbackLanguage,
+		arg.CertificationCountry,
+		arg.MoviesMetadataLanguage,
+		arg.MoviesMetadataFallbackLanguage,
+		arg.MoviesCertificationCountry,
+		arg.ID,
+	)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.TmdbKey,
+		&i.JellyfinToken,
+		&i.SpotifyClientID,
+		&i.SpotifyClientSecret,
+		&i.HardwareAccelerationDevice,
+		&i.EnableLogger,
+		&i.EnableWatcher,
+		&i.DownloadImages,
+		&i.MoviesDir,
+		&i.ShowsDir,
+		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
+		&i.StaticDir,
+		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateMetadataRefreshSettings = `-- name: UpdateMetadataRefreshSettings :one
+UPDATE settings
+SET
+  metadata_refresh_days = ?,
+  metadata_refresh_rate = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
+`
+
+type UpdateMetadataRefreshSettingsParams struct {
+	MetadataRefreshDays int64 `json:"metadata_refresh_days"`
+	MetadataRefreshRate int64 `json:"metadata_refresh_rate"`
+	ID                  int64 `json:"id"`
+}
+
+func (q *Queries) UpdateMetadataRefreshSettings(ctx context.Context, arg UpdateMetadataRefreshSettingsParams) (Setting, error) {
+	row := q.queryRow(ctx, q.updateMetadataRefreshSettingsStmt, updateMetadataRefreshSettings,
+		arg.MetadataRefreshDays,
+		arg.MetadataRefreshRate,
+		arg.ID,
+	)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.TmdbKey,
+		&i.JellyfinToken,
+		&i.SpotifyClientID,
+		&i.SpotifyClientSecret,
+		&i.HardwareAccelerationDevice,
+		&i.EnableLogger,
+		&i.EnableWatcher,
+		&i.DownloadImages,
+		&i.MoviesDir,
+		&i.ShowsDir,
+		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
+		&i.StaticDir,
+		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updatePodcastSettings = `-- name: UpdatePodcastSettings :one
+UPDATE settings
+SET
+  podcast_poll_minutes = ?,
+  podcasts_dir = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
+`
+
+type UpdatePodcastSettingsParams struct {
+	PodcastPollMinutes int64          `json:"podcast_poll_minutes"`
+	PodcastsDir        sql.NullString `json:"podcasts_dir"`
+	ID                 int64          `json:"id"`
+}
+
+func (q *Queries) UpdatePodcastSettings(ctx context.Context, arg UpdatePodcastSettingsParams) (Setting, error) {
+	row := q.queryRow(ctx, q.updatePodcastSettingsStmt, updatePodcastSettings, arg.PodcastPollMinutes, arg.PodcastsDir, arg.ID)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.TmdbKey,
+		&i.JellyfinToken,
+		&i.SpotifyClientID,
+		&i.SpotifyClientSecret,
+		&i.HardwareAccelerationDevice,
+		&i.EnableLogger,
+		&i.EnableWatcher,
+		&i.DownloadImages,
+		&i.MoviesDir,
+		&i.ShowsDir,
+		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
+		&i.StaticDir,
+		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateScannerSettings = `-- name: UpdateScannerSettings :one
+UPDATE settings
+SET
+  movies_ignore_patterns = ?,
+  music_ignore_patterns = ?,
+  audiobooks_ignore_patterns = ?,
+  movies_min_size = ?,
+  movies_min_duration = ?,
+  music_min_size = ?,
+  music_min_duration = ?,
+  movies_metadata_providers = ?,
+  various_artists_name = ?,
+  music_spotify_enrichment = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING id, tmdb_key, jellyfin_token, spotify_client_id, spotify_client_secret, hardware_acceleration_device, enable_logger, enable_watcher, download_images, movies_dir, shows_dir, music_dir, audiobooks_dir, podcasts_dir, static_dir, logs_dir, movies_ignore_patterns, music_ignore_patterns, audiobooks_ignore_patterns, movies_min_size, movies_min_duration, music_min_size, music_min_duration, movies_metadata_providers, metadata_language, metadata_fallback_language, certification_country, movies_metadata_language, movies_metadata_fallback_language, movies_certification_country, metadata_refresh_days, metadata_refresh_rate, various_artists_name, music_spotify_enrichment, podcast_poll_minutes, collections_backfilled_at, created_at, updated_at
+`
+
+type UpdateScannerSettingsParams struct {
+	MoviesIgnorePatterns     sql.NullString `json:"movies_ignore_patterns"`
+	MusicIgnorePatterns      sql.NullString `json:"music_ignore_patterns"`
+	AudiobooksIgnorePatterns sql.NullString `json:"audiobooks_ignore_patterns"`
+	MoviesMinSize            int64          `json:"movies_min_size"`
+	MoviesMinDuration        int64          `json:"movies_min_duration"`
+	MusicMinSize             int64          `json:"music_min_size"`
+	MusicMinDuration         int64          `json:"music_min_duration"`
+	MoviesMetadataProviders  string         `json:"movies_metadata_providers"`
+	VariousArtistsName       string         `json:"various_artists_name"`
+	MusicSpotifyEnrichment   bool           `json:"music_spotify_enrichment"`
+	ID                       int64          `json:"id"`
+}
+
+func (q *Queries) UpdateScannerSettings(ctx context.Context, arg UpdateScannerSettingsParams) (Setting, error) {
+	row := q.queryRow(ctx, q.updateScannerSettingsStmt, updateScannerSettings,
+		arg.MoviesIgnorePatterns,
+		arg.MusicIgnorePatterns,
+		arg.AudiobooksIgnorePatterns,
+		arg.MoviesMinSize,
+		arg.MoviesMinDuration,
+		arg.MusicMinSize,
+		arg.MusicMinDuration,
+		arg.MoviesMetadataProviders,
+		arg.VariousArtistsName,
+		arg.MusicSpotifyEnrichment,
+		arg.ID,
+	)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.TmdbKey,
+		&i.JellyfinToken,
+		&i.SpotifyClientID,
+		&i.SpotifyClientSecret,
+		&i.HardwareAccelerationDevice,
+		&i.EnableLogger,
+		&i.EnableWatcher,
+		&i.DownloadImages,
+		&i.MoviesDir,
+		&i.ShowsDir,
+		&i.MusicDir,
+		&i.AudiobooksDir,
+		&i.PodcastsDir,
 		&i.StaticDir,
 		&i.LogsDir,
+		&i.MoviesIgnorePatterns,
+		&i.MusicIgnorePatterns,
+		&i.AudiobooksIgnorePatterns,
+		&i.MoviesMinSize,
+		&i.MoviesMinDuration,
+		&i.MusicMinSize,
+		&i.MusicMinDuration,
+		&i.MoviesMetadataProviders,
+		&i.MetadataLanguage,
+		&i.MetadataFallbackLanguage,
+		&i.CertificationCountry,
+		&i.MoviesMetadataLanguage,
+		&i.MoviesMetadataFallbackLanguage,
+		&i.MoviesCertificationCountry,
+		&i.MetadataRefreshDays,
+		&i.MetadataRefreshRate,
+		&i.VariousArtistsName,
+		&i.MusicSpotifyEnrichment,
+		&i.PodcastPollMinutes,
+		&i.CollectionsBackfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -10,6 +10,33 @@ import (
 	"database/sql"
 )
 
+const checkCueTracksUnchanged = `-- name: CheckCueTracksUnchanged :one
+SELECT 1 FROM tracks t WHERE t.source_path = ? AND t.size = ? AND t.updated_at >= ?
+  AND t.lyrics_scanned_at IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM tracks st INNER JOIN track_lyrics l ON l.track_id = st.id
+    WHERE st.source_path = t.source_path AND l.source NOT IN ('user', ?)
+  )
+LIMIT 1
+`
+
+type CheckCueTracksUnchangedParams struct {
+	SourcePath   sql.NullString `json:"source_path"`
+	Size         int64          `json:"size"`
+	UpdatedAt    string         `json:"updated_at"`
+	LyricsSource string         `json:"lyrics_source"`
+}
+
+// Quick check for an audio file split by a CUE sheet: its virtual tracks exist with the
+// same file size, were scanned after the sheet was last modified and hold no scanned
+// lyrics from another source than the file has now
+func (q *Queries) CheckCueTracksUnchanged(ctx context.Context, arg CheckCueTracksUnchangedParams) (int64, error) {
+	row := q.queryRow(ctx, q.checkCueTracksUnchangedStmt, checkCueTracksUnchanged, arg.SourcePath, arg.Size, arg.UpdatedAt, arg.LyricsSource)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const checkTrackUnchanged = `-- name: CheckTrackUnchanged :one
 SELECT 1 FROM tracks WHERE file_path = ? AND size = ? LIMIT 1
 `
@@ -27,6 +54,44 @@ func (q *Queries) CheckTrackUnchanged(ctx context.Context, arg CheckTrackUnchang
 	return column_1, err
 }
 
+const deleteCueTracks = `-- name: DeleteCueTracks :exec
+DELETE FROM tracks WHERE source_path = ?
+`
+
+// Removes the virtual tracks of an audio file that is no longer split by a CUE sheet
+func (q *Queries) DeleteCueTracks(ctx context.Context, sourcePath sql.NullString) error {
+	_, err := q.exec(ctx, q.deleteCueTracksStmt, deleteCueTracks, sourcePath)
+	return err
+}
+
+const deleteStaleCueTracks = `-- name: DeleteStaleCueTracks :exec
+DELETE FROM tracks
+WHERE source_path = ?
+  AND file_path NOT IN (SELECT value FROM json_each(?))
+`
+
+type DeleteStaleCueTracksParams struct {
+	SourcePath sql.NullString `json:"source_path"`
+	FilePaths  interface{}    `json:"file_paths"`
+}
+
+// Removes the virtual tracks of an audio file that are no longer in its CUE sheet.
+// file_paths is a JSON array of the file paths of the tracks still in the sheet.
+func (q *Queries) DeleteStaleCueTracks(ctx context.Context, arg DeleteStaleCueTracksParams) error {
+	_, err := q.exec(ctx, q.deleteStaleCueTracksStmt, deleteStaleCueTracks, arg.SourcePath, arg.FilePaths)
+	return err
+}
+
+const deleteTrackByFilePath = `-- name: DeleteTrackByFilePath :exec
+DELETE FROM tracks WHERE file_path = ?
+`
+
+// Removes a track indexed as a whole file before a CUE sheet split it
+func (q *Queries) DeleteTrackByFilePath(ctx context.Context, filePath string) error {
+	_, err := q.exec(ctx, q.deleteTrackByFilePathStmt, deleteTrackByFilePath, filePath)
+	return err
+}
+
 const getAlbumsCount = `-- name: GetAlbumsCount :one
 SELECT COUNT(*) FROM albums
 `
@@ -72,6 +137,72 @@ func (q *Queries) GetAllTrackPathsAndSizes(ctx context.Context) ([]GetAllTrackPa
 	return items, nil
 }
 
+const getAllTrackScrobbleInfo = `-- name: GetAllTrackScrobbleInfo :many
+SELECT
+  t.id,
+  t.title,
+  t.duration,
+  t.track_index,
+  t.musicbrainz_track_id,
+  m.name AS artist,
+  a.title AS album,
+  a.musician AS album_artist,
+  m.musicbrainz_id AS artist_mbid,
+  a.musicbrainz_album_id AS album_mbid
+FROM
+  tracks t
+  LEFT JOIN musicians m ON m.id = t.musician_id
+  LEFT JOIN albums a ON a.id = t.album_id
+`
+
+type GetAllTrackScrobbleInfoRow struct {
+	ID                 int64          `json:"id"`
+	Title              string         `json:"title"`
+	Duration           int64          `json:"duration"`
+	TrackIndex         int64          `json:"track_index"`
+	MusicbrainzTrackID sql.NullString `json:"musicbrainz_track_id"`
+	Artist             sql.NullString `json:"artist"`
+	Album              sql.NullString `json:"album"`
+	AlbumArtist        sql.NullString `json:"album_artist"`
+	ArtistMbid         sql.NullString `json:"artist_mbid"`
+	AlbumMbid          sql.NullString `json:"album_mbid"`
+}
+
+// The scrobble info of every track, to match listens of an imported history.
+func (q *Queries) GetAllTrackScrobbleInfo(ctx context.Context) ([]GetAllTrackScrobbleInfoRow, error) {
+	rows, err := q.query(ctx, q.getAllTrackScrobbleInfoStmt, getAllTrackScrobbleInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAllTrackScrobbleInfoRow{}
+	for rows.Next() {
+		var i GetAllTrackScrobbleInfoRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Duration,
+			&i.TrackIndex,
+			&i.MusicbrainzTrackID,
+			&i.Artist,
+			&i.Album,
+			&i.AlbumArtist,
+			&i.ArtistMbid,
+			&i.AlbumMbid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMusiciansCount = `-- name: GetMusiciansCount :one
 SELECT COUNT(*) FROM musicians
 `
@@ -91,6 +222,7 @@ SELECT
   t.duration,
   t.codec,
   t.bit_rate,
+  t.musicbrainz_track_id,
   a.id AS album_id,
   a.title AS album_title,
   a.cover AS album_cover,
@@ -104,17 +236,18 @@ LIMIT ?
 `
 
 type GetRandomTracksRow struct {
-	ID           int64          `json:"id"`
-	Title        string         `json:"title"`
-	FilePath     string         `json:"file_path"`
-	Duration     int64          `json:"duration"`
-	Codec        string         `json:"codec"`
-	BitRate      int64          `json:"bit_rate"`
-	AlbumID      sql.NullInt64  `json:"album_id"`
-	AlbumTitle   sql.NullString `json:"album_title"`
-	AlbumCover   sql.NullString `json:"album_cover"`
-	MusicianID   sql.NullInt64  `json:"musician_id"`
-	MusicianName sql.NullString `json:"musician_name"`
+	ID                 int64          `json:"id"`
+	Title              string         `json:"title"`
+	FilePath           string         `json:"file_path"`
+	Duration           int64          `json:"duration"`
+	Codec              string         `json:"codec"`
+	BitRate            int64          `json:"bit_rate"`
+	MusicbrainzTrackID sql.NullString `json:"musicbrainz_track_id"`
+	AlbumID            sql.NullInt64  `json:"album_id"`
+	AlbumTitle         sql.NullString `json:"album_title"`
+	AlbumCover         sql.NullString `json:"album_cover"`
+	MusicianID         sql.NullInt64  `json:"musician_id"`
+	MusicianName       sql.NullString `json:"musician_name"`
 }
 
 func (q *Queries) GetRandomTracks(ctx context.Context, limit int64) ([]GetRandomTracksRow, error) {
@@ -133,6 +266,7 @@ func (q *Queries) GetRandomTracks(ctx context.Context, limit int64) ([]GetRandom
 			&i.Duration,
 			&i.Codec,
 			&i.BitRate,
+			&i.MusicbrainzTrackID,
 			&i.AlbumID,
 			&i.AlbumTitle,
 			&i.AlbumCover,
@@ -153,7 +287,7 @@ func (q *Queries) GetRandomTracks(ctx context.Context, limit int64) ([]GetRandom
 }
 
 const getTrack = `-- name: GetTrack :one
-SELECT id, title, sort_title, file_path, file_name, container, mime_type, codec, size, track_index, duration, disc, channels, channel_layout, bit_rate, profile, release_date, year, composer, copyright, language, album_id, musician_id, created_at, updated_at FROM tracks WHERE id = ? LIMIT 1
+SELECT id, title, sort_title, file_path, file_name, container, mime_type, codec, size, track_index, duration, disc, channels, channel_layout, bit_rate, profile, release_date, year, composer, copyright, language, album_id, musician_id, locked_fields, source_path, start_offset, end_offset, musicbrainz_track_id, lyrics_scanned_at, created_at, updated_at FROM tracks WHERE id = ? LIMIT 1
 `
 
 func (q *Queries) GetTrack(ctx context.Context, id int64) (Track, error) {
@@ -183,12 +317,68 @@ func (q *Queries) GetTrack(ctx context.Context, id int64) (Track, error) {
 		&i.Language,
 		&i.AlbumID,
 		&i.MusicianID,
+		&i.LockedFields,
+		&i.SourcePath,
+		&i.StartOffset,
+		&i.EndOffset,
+		&i.MusicbrainzTrackID,
+		&i.LyricsScannedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getTrackScrobbleInfo = `-- name: GetTrackScrobbleInfo :one
+SELECT
+  t.title,
+  t.duration,
+  t.track_index,
+  t.musicbrainz_track_id,
+  m.name AS artist,
+  a.title AS album,
+  a.musician AS album_artist,
+  m.musicbrainz_id AS artist_mbid,
+  a.musicbrainz_album_id AS album_mbid
+FROM
+  tracks t
+  LEFT JOIN musicians m ON m.id = t.musician_id
+  LEFT JOIN albums a ON a.id = t.album_id
+WHERE
+  t.id = ?
+`
+
+type GetTrackScrobbleInfoRow struct {
+	Title              string         `json:"title"`
+	Duration           int64          `json:"duration"`
+	TrackIndex         int64          `json:"track_index"`
+	MusicbrainzTrackID sql.NullString `json:"musicbrainz_track_id"`
+	Artist             sql.NullString `json:"artist"`
+	Album              sql.NullString `json:"album"`
+	AlbumArtist        sql.NullString `json:"album_artist"`
+	ArtistMbid         sql.NullString `json:"artist_mbid"`
+	AlbumMbid          sql.NullString `json:"album_mbid"`
+}
+
+// What scrobbling services are told about a track: its artist, album and album artist,
+// and their MusicBrainz IDs.
+func (q *Queries) GetTrackScrobbleInfo(ctx context.Context, id int64) (GetTrackScrobbleInfoRow, error) {
+	row := q.queryRow(ctx, q.getTrackScrobbleInfoStmt, getTrackScrobbleInfo, id)
+	var i GetTrackScrobbleInfoRow
+	err := row.Scan(
+		&i.Title,
+		&i.Duration,
+		&i.TrackIndex,
+		&i.MusicbrainzTrackID,
+		&i.Artist,
+		&i.Album,
+		&i.AlbumArtist,
+		&i.ArtistMbid,
+		&i.AlbumMbid,
+	)
+	return i, err
+}
+
 const getTracksAlphabetical = `-- name: GetTracksAlphabetical :many
 SELECT
   t.id,
@@ -197,6 +387,7 @@ SELECT
   t.codec,
   t.bit_rate,
   t.file_path,
+  t.musicbrainz_track_id,
   a.id as album_id,
   a.title as album_title,
   a.cover as album_cover,
@@ -221,17 +412,18 @@ type GetTracksAlphabeticalParams struct {
 }
 
 type GetTracksAlphabeticalRow struct {
-	ID           int64          `json:"id"`
-	Title        string         `json:"title"`
-	Duration     int64          `json:"duration"`
-	Codec        string         `json:"codec"`
-	BitRate      int64          `json:"bit_rate"`
-	FilePath     string         `json:"file_path"`
-	AlbumID      sql.NullInt64  `json:"album_id"`
-	AlbumTitle   sql.NullString `json:"album_title"`
-	AlbumCover   sql.NullString `json:"album_cover"`
-	MusicianID   sql.NullInt64  `json:"musician_id"`
-	MusicianName sql.NullString `json:"musician_name"`
+	ID                 int64          `json:"id"`
+	Title              string         `json:"title"`
+	Duration           int64          `json:"duration"`
+	Codec              string         `json:"codec"`
+	BitRate            int64          `json:"bit_rate"`
+	FilePath           string         `json:"file_path"`
+	MusicbrainzTrackID sql.NullString `json:"musicbrainz_track_id"`
+	AlbumID            sql.NullInt64  `json:"album_id"`
+	AlbumTitle         sql.NullString `json:"album_title"`
+	AlbumCover         sql.NullString `json:"album_cover"`
+	MusicianID         sql.NullInt64  `json:"musician_id"`
+	MusicianName       sql.NullString `json:"musician_name"`
 }
 
 func (q *Queries) GetTracksAlphabetical(ctx context.Context, arg GetTracksAlphabeticalParams) ([]GetTracksAlphabeticalRow, error) {
@@ -250,6 +442,7 @@ func (q *Queries) GetTracksAlphabetical(ctx context.Context, arg GetTracksAlphab
 			&i.Codec,
 			&i.BitRate,
 			&i.FilePath,
+			&i.MusicbrainzTrackID,
 			&i.AlbumID,
 			&i.AlbumTitle,
 			&i.AlbumCover,
@@ -271,7 +464,7 @@ func (q *Queries) GetTracksAlphabetical(ctx context.Context, arg GetTracksAlphab
 
 const getTracksByAlbumID = `-- name: GetTracksByAlbumID :many
 SELECT
-  id, title, sort_title, file_path, file_name, container, mime_type, codec, size, track_index, duration, disc, channels, channel_layout, bit_rate, profile, release_date, year, composer, copyright, language, album_id, musician_id, created_at, updated_at
+  id, title, sort_title, file_path, file_name, container, mime_type, codec, size, track_index, duration, disc, channels, channel_layout, bit_rate, profile, release_date, year, composer, copyright, language, album_id, musician_id, locked_fields, source_path, start_offset, end_offset, musicbrainz_track_id, lyrics_scanned_at, created_at, updated_at
 FROM
   tracks
 WHERE
@@ -314,6 +507,12 @@ func (q *Queries) GetTracksByAlbumID(ctx context.Context, albumID sql.NullInt64)
 			&i.Language,
 			&i.AlbumID,
 			&i.MusicianID,
+			&i.LockedFields,
+			&i.SourcePath,
+			&i.StartOffset,
+			&i.EndOffset,
+			&i.MusicbrainzTrackID,
+			&i.LyricsScannedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -341,15 +540,86 @@ func (q *Queries) GetTracksCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const setTrackLyricsScanned = `-- name: SetTrackLyricsScanned :exec
+UPDATE tracks SET lyrics_scanned_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+func (q *Queries) SetTrackLyricsScanned(ctx context.Context, id int64) error {
+	_, err := q.exec(ctx, q.setTrackLyricsScannedStmt, setTrackLyricsScanned, id)
+	return err
+}
+
+const updateTrackMetadata = `-- name: UpdateTrackMetadata :one
+UPDATE tracks
+SET title = ?, sort_title = ?, year = ?, locked_fields = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, title, sort_title, file_path, file_name, container, mime_type, codec, size, track_index, duration, disc, channels, channel_layout, bit_rate, profile, release_date, year, composer, copyright, language, album_id, musician_id, locked_fields, source_path, start_offset, end_offset, musicbrainz_track_id, lyrics_scanned_at, created_at, updated_at
+`
+
+type UpdateTrackMetadataParams struct {
+	Title        string        `json:"title"`
+	SortTitle    string        `json:"sort_title"`
+	Year         sql.NullInt64 `json:"year"`
+	LockedFields string        `json:"locked_fields"`
+	ID           int64         `json:"id"`
+}
+
+// Applies a hand edit. The caller passes every editable field and the new lock set.
+func (q *Queries) UpdateTrackMetadata(ctx context.Context, arg UpdateTrackMetadataParams) (Track, error) {
+	row := q.queryRow(ctx, q.updateTrackMetadataStmt, updateTrackMetadata,
+		arg.Title,
+		arg.SortTitle,
+		arg.Year,
+		arg.LockedFields,
+		arg.ID,
+	)
+	var i Track
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.SortTitle,
+		&i.FilePath,
+		&i.FileName,
+		&i.Container,
+		&i.MimeType,
+		&i.Codec,
+		&i.Size,
+		&i.TrackIndex,
+		&i.Duration,
+		&i.Disc,
+		&i.Channels,
+		&i.ChannelLayout,
+		&i.BitRate,
+		&i.Profile,
+		&i.ReleaseDate,
+		&i.Year,
+		&i.Composer,
+		&i.Copyright,
+		&i.Language,
+		&i.AlbumID,
+		&i.MusicianID,
+		&i.LockedFields,
+		&i.SourcePath,
+		&i.StartOffset,
+		&i.EndOffset,
+		&i.MusicbrainzTrackID,
+		&i.LyricsScannedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const upsertTrack = `-- name: UpsertTrack :one
 INSERT INTO tracks (
   title, sort_title, file_path, file_name, container, mime_type, codec, size,
   track_index, duration, disc, channels, channel_layout, bit_rate, profile,
-  release_date, year, composer, copyright, language, album_id, musician_id
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+  release_date, year, composer, copyright, language, album_id, musician_id,
+  source_path, start_offset, end_offset, musicbrainz_track_id
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (file_path) DO UPDATE SET
-  title = excluded.title,
-  sort_title = excluded.sort_title,
+  title = CASE WHEN 'title' IN (SELECT value FROM json_each(tracks.locked_fields)) THEN tracks.title ELSE excluded.title END,
+  sort_title = CASE WHEN 'sort_title' IN (SELECT value FROM json_each(tracks.locked_fields)) THEN tracks.sort_title ELSE excluded.sort_title END,
   file_name = excluded.file_name,
   container = excluded.container,
   mime_type = excluded.mime_type,
@@ -363,39 +633,47 @@ ON CONFLICT (file_path) DO UPDATE SET
   bit_rate = excluded.bit_rate,
   profile = excluded.profile,
   release_date = COALESCE(excluded.release_date, tracks.release_date),
-  year = COALESCE(excluded.year, tracks.year),
+  year = CASE WHEN 'year' IN (SELECT value FROM json_each(tracks.locked_fields)) THEN tracks.year ELSE COALESCE(excluded.year, tracks.year) END,
   composer = COALESCE(excluded.composer, tracks.composer),
   copyright = COALESCE(excluded.copyright, tracks.copyright),
   language = COALESCE(excluded.language, tracks.language),
   album_id = COALESCE(excluded.album_id, tracks.album_id),
   musician_id = COALESCE(excluded.musician_id, tracks.musician_id),
+  source_path = excluded.source_path,
+  start_offset = excluded.start_offset,
+  end_offset = excluded.end_offset,
+  musicbrainz_track_id = excluded.musicbrainz_track_id,
   updated_at = CURRENT_TIMESTAMP
-RETURNING id, title, sort_title, file_path, file_name, container, mime_type, codec, size, track_index, duration, disc, channels, channel_layout, bit_rate, profile, release_date, year, composer, copyright, language, album_id, musician_id, created_at, updated_at
+RETURNING id, title, sort_title, file_path, file_name, container, mime_type, codec, size, track_index, duration, disc, channels, channel_layout, bit_rate, profile, release_date, year, composer, copyright, language, album_id, musician_id, locked_fields, source_path, start_offset, end_offset, musicbrainz_track_id, lyrics_scanned_at, created_at, updated_at
 `
 
 type UpsertTrackParams struct {
-	Title         string         `json:"title"`
-	SortTitle     string         `json:"sort_title"`
-	FilePath      string         `json:"file_path"`
-	FileName      string         `json:"file_name"`
-	Container     string         `json:"container"`
-	MimeType      string         `json:"mime_type"`
-	Codec         string         `json:"codec"`
-	Size          int64          `json:"size"`
-	TrackIndex    int64          `json:"track_index"`
-	Duration      int64          `json:"duration"`
-	Disc          int64          `json:"disc"`
-	Channels      string         `json:"channels"`
-	ChannelLayout string         `json:"channel_layout"`
-	BitRate       int64          `json:"bit_rate"`
-	Profile       string         `json:"profile"`
-	ReleaseDate   sql.NullString `json:"release_date"`
-	Year          sql.NullInt64  `json:"year"`
-	Composer      sql.NullString `json:"composer"`
-	Copyright     sql.NullString `json:"copyright"`
-	Language      sql.NullString `json:"language"`
-	AlbumID       sql.NullInt64  `json:"album_id"`
-	MusicianID    sql.NullInt64  `json:"musician_id"`
+	Title              string         `json:"title"`
+	SortTitle          string         `json:"sort_title"`
+	FilePath           string         `json:"file_path"`
+	FileName           string         `json:"file_name"`
+	Container          string         `json:"container"`
+	MimeType           string         `json:"mime_type"`
+	Codec              string         `json:"codec"`
+	Size               int64          `json:"size"`
+	TrackIndex         int64          `json:"track_index"`
+	Duration           int64          `json:"duration"`
+	Disc               int64          `json:"disc"`
+	Channels           string         `json:"channels"`
+	ChannelLayout      string         `json:"channel_layout"`
+	BitRate            int64          `json:"bit_rate"`
+	Profile            string         `json:"profile"`
+	ReleaseDate        sql.NullString `json:"release_date"`
+	Year               sql.NullInt64  `json:"year"`
+	Composer           sql.NullString `json:"composer"`
+	Copyright          sql.NullString `json:"copyright"`
+	Language           sql.NullString `json:"language"`
+	AlbumID            sql.NullInt64  `json:"album_id"`
+	MusicianID         sql.NullInt64  `json:"musician_id"`
+	SourcePath         sql.NullString `json:"source_path"`
+	StartOffset        sql.NullInt64  `json:"start_offset"`
+	EndOffset          sql.NullInt64  `json:"end_offset"`
+	MusicbrainzTrackID sql.NullString `json:"musicbrainz_track_id"`
 }
 
 func (q *Queries) UpsertTrack(ctx context.Context, arg UpsertTrackParams) (Track, error) {
@@ -422,6 +700,10 @@ func (q *Queries) UpsertTrack(ctx context.Context, arg UpsertTrackParams) (Track
 		arg.Language,
 		arg.AlbumID,
 		arg.MusicianID,
+		arg.SourcePath,
+		arg.StartOffset,
+		arg.EndOffset,
+		arg.MusicbrainzTrackID,
 	)
 	var i Track
 	err := row.Scan(
@@ -448,6 +730,12 @@ func (q *Queries) UpsertTrack(ctx context.Context, arg UpsertTrackParams) (Track
 		&i.Language,
 		&i.AlbumID,
 		&i.MusicianID,
+		&i.LockedFields,
+		&i.SourcePath,
+		&i.StartOffset,
+		&i.EndOffset,
+		&i.MusicbrainzTrackID,
+		&i.LyricsScannedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -94,6 +94,44 @@ func (q *Queries) GetUserListeningStats(ctx context.Context, arg GetUserListenin
 	return i, err
 }
 
+const getUserPlayTimes = `-- name: GetUserPlayTimes :many
+SELECT track_id, played_at
+FROM user_play_history
+WHERE user_id = ?
+`
+
+type GetUserPlayTimesRow struct {
+	TrackID  int64  `json:"track_id"`
+	PlayedAt string `json:"played_at"`
+}
+
+// Returns when the user played each track, to skip plays imported before
+func (q *Queries) GetUserPlayTimes(ctx context.Context, userID int64) ([]GetUserPlayTimesRow, error) {
+	rows, err := q.query(ctx, q.getUserPlayTimesStmt, getUserPlayTimes, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUserPlayTimesRow{}
+	for rows.Next() {
+		var i GetUserPlayTimesRow
+		if err := rows.Scan(
+			&i.TrackID,
+			&i.PlayedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserRecentlyPlayed = `-- name: GetUserRecentlyPlayed :many
 SELECT
     uph.played_at,
@@ -468,6 +506,51 @@ func (q *Queries) GetUserTrackPlayCount(ctx context.Context, arg GetUserTrackPla
 	return play_count, err
 }
 
+const importPlayEvent = `-- name: ImportPlayEvent :exec
+INSERT INTO user_play_history (user_id, track_id, played_at, duration_played, completed)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type ImportPlayEventParams struct {
+	UserID         int64  `json:"user_id"`
+	TrackID        int64  `json:"track_id"`
+	PlayedAt       string `json:"played_at"`
+	DurationPlayed int64  `json:"duration_played"`
+	Completed      bool   `json:"completed"`
+}
+
+// Records a play of an imported listening history at the time it happened
+func (q *Queries) ImportPlayEvent(ctx context.Context, arg ImportPlayEventParams) error {
+	_, err := q.exec(ctx, q.importPlayEventStmt, importPlayEvent,
+		arg.UserID,
+		arg.TrackID,
+		arg.PlayedAt,
+		arg.DurationPlayed,
+		arg.Completed,
+	)
+	return err
+}
+
+const rebuildUserTrackStats = `-- name: RebuildUserTrackStats :exec
+INSERT INTO user_track_stats (user_id, track_id, play_count, total_time_played, last_played_at, first_played_at)
+SELECT user_id, track_id, COUNT(*), SUM(duration_played), MAX(played_at), MIN(played_at)
+FROM user_play_history
+WHERE user_id = ?
+GROUP BY user_id, track_id
+ON CONFLICT (user_id, track_id) DO UPDATE SET
+    play_count = excluded.play_count,
+    total_time_played = excluded.total_time_played,
+    last_played_at = excluded.last_played_at,
+    first_played_at = excluded.first_played_at,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+// Recomputes the user's aggregated stats from the play history, e.g. after an import
+func (q *Queries) RebuildUserTrackStats(ctx context.Context, userID int64) error {
+	_, err := q.exec(ctx, q.rebuildUserTrackStatsStmt, rebuildUserTrackStats, userID)
+	return err
+}
+
 const recordPlayEvent = `-- name: RecordPlayEvent :exec
 
 INSERT INTO user_play_history (user_id, track_id, duration_played, completed)
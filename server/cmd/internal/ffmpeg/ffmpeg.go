@@ -1,16 +1,27 @@
 package ffmpeg
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 )
 
+type FfmpegInterface interface {
+	TranscodeAudio(ctx context.Context, filePath string, w io.Writer) error
+	TranscodeAudioRange(ctx context.Context, filePath string, start, end time.Duration, w io.Writer) error
+}
+
 type FFmpeg struct {
 	bin string
 }
 
+// Compile-time check to ensure FFmpeg implements FfmpegInterface.
+var _ FfmpegInterface = (*FFmpeg)(nil)
+
 var (
 	instance     *FFmpeg
 	instanceMu   sync.Mutex
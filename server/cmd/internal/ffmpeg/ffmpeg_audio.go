@@ -0,0 +1,65 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TranscodeAudio decodes an audio file and writes it to w as FLAC
+// (see helpers.AUDIO_TRANSCODE_MIME_TYPE). Only the first audio stream is kept,
+// so embedded cover art is dropped. The process is killed when ctx is cancelled,
+// e.g. when the client disconnects mid-stream.
+func (f *FFmpeg) TranscodeAudio(ctx context.Context, filePath string, w io.Writer) error {
+	return f.TranscodeAudioRange(ctx, filePath, 0, 0, w)
+}
+
+// TranscodeAudioRange is TranscodeAudio limited to the part of the file between
+// start and end, used for tracks cut from a single file by a CUE sheet.
+// An end of 0 transcodes to the end of the file.
+func (f *FFmpeg) TranscodeAudioRange(ctx context.Context, filePath string, start, end time.Duration, w io.Writer) error {
+	args := []string{"-v", "error"}
+
+	// -ss before -i seeks the input, which is both fast and sample accurate for audio
+	if start > 0 {
+		args = append(args, "-ss", formatSeconds(start))
+	}
+	if end > 0 {
+		args = append(args, "-to", formatSeconds(end))
+	}
+
+	args = append(args,
+		"-i", filePath,
+		"-map", "0:a:0",
+		"-map_metadata", "-1",
+		"-c:a", "flac",
+		"-f", "flac",
+		"pipe:1")
+
+	cmd := exec.CommandContext(ctx, f.bin, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return fmt.Errorf("ffmpeg transcode failed for %s: %w: %s", filePath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// formatSeconds formats d as seconds with millisecond precision, as ffmpeg expects.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
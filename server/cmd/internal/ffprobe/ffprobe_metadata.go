@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"sort"
+	"strings"
 )
 
 type FfprobeResult struct {
@@ -37,12 +39,56 @@ type Stream struct {
 	ColorPrimaries string `json:"color_primaries"`
 	ColorSpace     string `json:"color_space"`
 
-	Tags StreamTags `json:"tags"`
+	Disposition Disposition `json:"disposition"`
+	Tags        StreamTags  `json:"tags"`
 }
 
+type Disposition struct {
+	// AttachedPic is 1 for embedded cover art, which ffprobe reports as a video stream
+	AttachedPic int `json:"attached_pic"`
+}
+
+// StreamTags holds per-stream tags. Ogg Vorbis and Opus files keep their
+// comments on the audio stream instead of the container, so the music
+// fields are present here too (see FfprobeResult.AudioTags).
 type StreamTags struct {
-	Title    string `json:"title"`
-	Language string `json:"language"`
+	Title       string `json:"title"`
+	Language    string `json:"language"`
+	Artist      string `json:"artist"`
+	AlbumArtist string `json:"album_artist"`
+	Composer    string `json:"composer"`
+	Album       string `json:"album"`
+	Genre       string `json:"genre"`
+	Track       string `json:"track"`
+	Disc        string `json:"disc"`
+	Date        string `json:"date"`
+	Copyright   string `json:"copyright"`
+	Compilation string `json:"compilation"`
+	Narrator    string `json:"narrator"`
+	Series      string `json:"series"`
+	SeriesPart  string `json:"series-part"`
+	Description string `json:"description"`
+	Comment     string `json:"comment"`
+
+	MusicBrainzTrackID        string `json:"musicbrainz_trackid"`
+	MusicBrainzAlbumID        string `json:"musicbrainz_albumid"`
+	MusicBrainzArtistID       string `json:"musicbrainz_artistid"`
+	MusicBrainzReleaseGroupID string `json:"musicbrainz_releasegroupid"`
+
+	// Lyrics and LyricsLanguage are read by UnmarshalJSON, see FormatTags
+	Lyrics         string `json:"-"`
+	LyricsLanguage string `json:"-"`
+}
+
+// UnmarshalJSON decodes the stream tags and picks out the lyrics.
+func (t *StreamTags) UnmarshalJSON(data []byte) error {
+	type streamTags StreamTags
+	if err := json.Unmarshal(data, (*streamTags)(t)); err != nil {
+		return err
+	}
+
+	t.Lyrics, t.LyricsLanguage = lyricsTag(data)
+	return nil
 }
 
 type Format struct {
@@ -70,6 +116,97 @@ type FormatTags struct {
 	SortName     string `json:"sort_name"`
 	SortAlbum    string `json:"sort_album"`
 	SortArtist   string `json:"sort_artist"`
+	// Compilation is "1" on compilations (ID3 TCMP, MP4 cpil, Vorbis COMPILATION)
+	Compilation string `json:"compilation"`
+
+	// Audiobook tags. Narrator and series are custom tags (ID3 TXXX frames, MP4 freeform
+	// atoms, Vorbis comments) as written by Mp3tag and audiobook taggers; MP4 files keep
+	// a short description in desc and the full one in ldes, which ffmpeg names synopsis.
+	Narrator    string `json:"narrator"`
+	Series      string `json:"series"`
+	SeriesPart  string `json:"series-part"`
+	Description string `json:"description"`
+	Synopsis    string `json:"synopsis"`
+	Comment     string `json:"comment"`
+
+	// MusicBrainz identifiers written by Picard. Vorbis comments and APE tags name them
+	// MUSICBRAINZ_*, ID3 TXXX frames and MP4 freeform atoms "MusicBrainz * Id"; AudioTags
+	// folds the second spelling into the first. ARTISTID may list several ids.
+	MusicBrainzTrackID        string `json:"musicbrainz_trackid"`
+	MusicBrainzAlbumID        string `json:"musicbrainz_albumid"`
+	MusicBrainzArtistID       string `json:"musicbrainz_artistid"`
+	MusicBrainzReleaseGroupID string `json:"musicbrainz_releasegroupid"`
+
+	ID3MusicBrainzTrackID        string `json:"MusicBrainz Track Id"`
+	ID3MusicBrainzAlbumID        string `json:"MusicBrainz Album Id"`
+	ID3MusicBrainzArtistID       string `json:"MusicBrainz Artist Id"`
+	ID3MusicBrainzReleaseGroupID string `json:"MusicBrainz Release Group Id"`
+
+	// Unsynchronized lyrics: Vorbis LYRICS or UNSYNCEDLYRICS, MP4 ©lyr, or an ID3 USLT
+	// frame, which ffmpeg names after its description and language ("lyrics-eng").
+	// The key varies, so UnmarshalJSON fills these.
+	Lyrics         string `json:"-"`
+	LyricsLanguage string `json:"-"`
+}
+
+// UnmarshalJSON decodes the container tags and picks out the lyrics.
+func (t *FormatTags) UnmarshalJSON(data []byte) error {
+	type formatTags FormatTags
+	if err := json.Unmarshal(data, (*formatTags)(t)); err != nil {
+		return err
+	}
+
+	t.Lyrics, t.LyricsLanguage = lyricsTag(data)
+	return nil
+}
+
+// lyricsTag returns the lyrics and their language from a tags object. A plain
+// "lyrics" key wins over a USLT key, which wins over "unsyncedlyrics". The language
+// is only known for USLT frames, "xxx" and "und" mean it wasn't set.
+func lyricsTag(data []byte) (string, string) {
+	var tags map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return "", ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	best, bestRank, language := "", 3, ""
+	for _, key := range keys {
+		lower := strings.ToLower(key)
+
+		rank := 3
+		switch {
+		case lower == "lyrics":
+			rank = 0
+		case strings.HasPrefix(lower, "lyrics-"):
+			rank = 1
+		case lower == "unsyncedlyrics" || lower == "unsynced lyrics":
+			rank = 2
+		}
+		if rank >= bestRank {
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(tags[key], &value); err != nil || strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		best, bestRank, language = value, rank, ""
+		if rank == 1 {
+			parts := strings.Split(lower, "-")
+			if lang := parts[len(parts)-1]; len(lang) == 3 && lang != "xxx" && lang != "und" {
+				language = lang
+			}
+		}
+	}
+
+	return best, language
 }
 
 type Chapter struct {
@@ -83,6 +220,67 @@ type Chapter struct {
 	} `json:"tags"`
 }
 
+// AudioStream returns the first audio stream, which is the one players decode.
+// Returns false when the file has no audio stream.
+func (r *FfprobeResult) AudioStream() (Stream, bool) {
+	for _, stream := range r.Streams {
+		if stream.CodecType == "audio" {
+			return stream, true
+		}
+	}
+
+	return Stream{}, false
+}
+
+// AudioTags returns the container tags with any empty field filled in from the
+// audio stream's tags, so Ogg Vorbis and Opus files read the same as mp3/flac/m4a.
+func (r *FfprobeResult) AudioTags() FormatTags {
+	tags := r.Format.Tags
+
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+
+	fill(&tags.MusicBrainzTrackID, tags.ID3MusicBrainzTrackID)
+	fill(&tags.MusicBrainzAlbumID, tags.ID3MusicBrainzAlbumID)
+	fill(&tags.MusicBrainzArtistID, tags.ID3MusicBrainzArtistID)
+	fill(&tags.MusicBrainzReleaseGroupID, tags.ID3MusicBrainzReleaseGroupID)
+
+	stream, ok := r.AudioStream()
+	if !ok {
+		return tags
+	}
+
+	fill(&tags.Title, stream.Tags.Title)
+	fill(&tags.Artist, stream.Tags.Artist)
+	fill(&tags.AlbumArtist, stream.Tags.AlbumArtist)
+	fill(&tags.Composer, stream.Tags.Composer)
+	fill(&tags.Album, stream.Tags.Album)
+	fill(&tags.Genre, stream.Tags.Genre)
+	fill(&tags.Track, stream.Tags.Track)
+	fill(&tags.Disc, stream.Tags.Disc)
+	fill(&tags.Date, stream.Tags.Date)
+	fill(&tags.Copyright, stream.Tags.Copyright)
+	fill(&tags.Compilation, stream.Tags.Compilation)
+	fill(&tags.Narrator, stream.Tags.Narrator)
+	fill(&tags.Series, stream.Tags.Series)
+	fill(&tags.SeriesPart, stream.Tags.SeriesPart)
+	fill(&tags.Description, stream.Tags.Description)
+	fill(&tags.Comment, stream.Tags.Comment)
+	fill(&tags.MusicBrainzTrackID, stream.Tags.MusicBrainzTrackID)
+	fill(&tags.MusicBrainzAlbumID, stream.Tags.MusicBrainzAlbumID)
+	fill(&tags.MusicBrainzArtistID, stream.Tags.MusicBrainzArtistID)
+	fill(&tags.MusicBrainzReleaseGroupID, stream.Tags.MusicBrainzReleaseGroupID)
+
+	if tags.Lyrics == "" {
+		tags.Lyrics, tags.LyricsLanguage = stream.Tags.Lyrics, stream.Tags.LyricsLanguage
+	}
+
+	return tags
+}
+
 func (f *ffprobe) GetMetadata(filePath string) (*FfprobeResult, error) {
 	cmd := exec.Command(f.bin,
 		"-v", "quiet",
@@ -1,6 +1,7 @@
 package ffprobe
 
 import (
+	"encoding/json"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -179,3 +180,121 @@ func TestGetMetadata_MultipleCallsUseSameInstance(t *testing.T) {
 		t.Error("Expected same duration from both calls")
 	}
 }
+
+func TestAudioTags_MergesStreamTags(t *testing.T) {
+	// Trimmed ffprobe output for an Opus file: the Vorbis comments live on the
+	// audio stream (in upper case), the embedded cover is an attached picture.
+	output := `{
+		"streams": [
+			{"codec_name": "opus", "codec_type": "audio", "tags": {"TITLE": "Song", "ARTIST": "Band", "album_artist": "Band", "track": "3/10", "GENRE": "Rock;Indie", "COMPILATION": "1"}},
+			{"codec_name": "mjpeg", "codec_type": "video", "disposition": {"attached_pic": 1}}
+		],
+		"format": {"format_name": "ogg", "tags": {"album": "Record"}}
+	}`
+
+	var result FfprobeResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse ffprobe output: %v", err)
+	}
+
+	stream, ok := result.AudioStream()
+	if !ok || stream.CodecName != "opus" {
+		t.Fatalf("Expected opus audio stream, got %q (found %v)", stream.CodecName, ok)
+	}
+
+	if result.Streams[1].Disposition.AttachedPic != 1 {
+		t.Error("Expected cover art stream to be marked as attached picture")
+	}
+
+	tags := result.AudioTags()
+
+	expected := FormatTags{
+		Title:       "Song",
+		Artist:      "Band",
+		AlbumArtist: "Band",
+		Album:       "Record",
+		Genre:       "Rock;Indie",
+		Track:       "3/10",
+		Compilation: "1",
+	}
+
+	if tags != expected {
+		t.Errorf("AudioTags() = %+v, want %+v", tags, expected)
+	}
+}
+
+func TestAudioTags_MusicBrainzIDs(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		// FLAC Vorbis comments keep Picard's upper case names
+		{"vorbis", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {
+			"MUSICBRAINZ_TRACKID": "track", "MUSICBRAINZ_ALBUMID": "album",
+			"MUSICBRAINZ_ARTISTID": "artist", "MUSICBRAINZ_RELEASEGROUPID": "group"}}}`},
+		// Opus keeps them on the audio stream
+		{"opus", `{"streams": [{"codec_type": "audio", "tags": {
+			"MUSICBRAINZ_TRACKID": "track", "MUSICBRAINZ_ALBUMID": "album",
+			"MUSICBRAINZ_ARTISTID": "artist", "MUSICBRAINZ_RELEASEGROUPID": "group"}}], "format": {}}`},
+		// ID3 TXXX frames and MP4 freeform atoms use descriptive names
+		{"id3", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {
+			"MusicBrainz Track Id": "track", "MusicBrainz Album Id": "album",
+			"MusicBrainz Artist Id": "artist", "MusicBrainz Release Group Id": "group"}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result FfprobeResult
+			if err := json.Unmarshal([]byte(tt.output), &result); err != nil {
+				t.Fatalf("Failed to parse ffprobe output: %v", err)
+			}
+
+			tags := result.AudioTags()
+			if tags.MusicBrainzTrackID != "track" || tags.MusicBrainzAlbumID != "album" ||
+				tags.MusicBrainzArtistID != "artist" || tags.MusicBrainzReleaseGroupID != "group" {
+				t.Errorf("Unexpected MusicBrainz ids: %+v", tags)
+			}
+		})
+	}
+}
+
+func TestAudioTags_PrefersFormatTags(t *testing.T) {
+	result := FfprobeResult{
+		Streams: []Stream{{CodecType: "audio", Tags: StreamTags{Title: "Stream Title"}}},
+		Format:  Format{Tags: FormatTags{Title: "Format Title"}},
+	}
+
+	if got := result.AudioTags().Title; got != "Format Title" {
+		t.Errorf("Expected format title to win, got %q", got)
+	}
+}
+
+func TestAudioTags_Lyrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		lyrics   string
+		language string
+	}{
+		{"vorbis", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {"LYRICS": "Hello"}}}`, "Hello", ""},
+		{"opus", `{"streams": [{"codec_type": "audio", "tags": {"UNSYNCEDLYRICS": "Hello"}}], "format": {}}`, "Hello", ""},
+		// ffmpeg names USLT frames after their description and language
+		{"id3", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {"lyrics-eng": "Hello"}}}`, "Hello", "eng"},
+		{"id3 description", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {"lyrics-Album Version-deu": "Hallo"}}}`, "Hallo", "deu"},
+		{"id3 unknown language", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {"lyrics-XXX": "Hello"}}}`, "Hello", ""},
+		{"preference", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {"unsyncedlyrics": "Old", "lyrics": "New"}}}`, "New", ""},
+		{"none", `{"streams": [{"codec_type": "audio"}], "format": {"tags": {"title": "Hello"}}}`, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result FfprobeResult
+			if err := json.Unmarshal([]byte(tt.output), &result); err != nil {
+				t.Fatalf("Failed to parse ffprobe output: %v", err)
+			}
+
+			tags := result.AudioTags()
+			if tags.Lyrics != tt.lyrics || tags.LyricsLanguage != tt.language {
+				t.Errorf("Expected %q (%q), got %q (%q)", tt.lyrics, tt.language, tags.Lyrics, tags.LyricsLanguage)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package helpers
 
+import "time"
+
 const (
 	// logger
 	LOGGER_MAX_LINES = 500
@@ -12,14 +14,90 @@ const (
 
 	// media scanner
 	SCANNER_BATCH_SIZE = 54
+	// IGNORE_FILE_NAME holds gitignore-style patterns for the directory it's in
+	IGNORE_FILE_NAME = ".iglooignore"
+	// NOMEDIA_FILE_NAME excludes the directory it's in and everything below it
+	NOMEDIA_FILE_NAME = ".nomedia"
+
+	// scan errors: the library a failed file belongs to and the step it failed in
+	SCAN_LIBRARY_MOVIES     = "movies"
+	SCAN_LIBRARY_MUSIC      = "music"
+	SCAN_LIBRARY_AUDIOBOOKS = "audiobooks"
+	SCAN_PHASE_FFPROBE      = "ffprobe"
+	SCAN_PHASE_TMDB         = "tmdb"
+	SCAN_PHASE_MATCH        = "match"
+	SCAN_PHASE_DB           = "db"
+
+	// movie scanner
+	// MOVIES_METADATA_PROVIDERS is the default metadata provider priority, highest first
+	MOVIES_METADATA_PROVIDERS = "nfo,tmdb,embedded"
+	// MOVIE_NFO_FILE_NAME describes the movie of a directory when its video has no
+	// NFO of its own
+	MOVIE_NFO_FILE_NAME = "movie.nfo"
+
+	// music scanner
+	// VARIOUS_ARTISTS_NAME is the default pseudo-musician compilations are filed under
+	VARIOUS_ARTISTS_NAME = "Various Artists"
+	// lyrics: where a track's lyrics came from. Scans never replace LYRICS_SOURCE_USER
+	LYRICS_SOURCE_EMBEDDED = "embedded"
+	LYRICS_SOURCE_SIDECAR  = "sidecar"
+	LYRICS_SOURCE_USER     = "user"
+	// LYRICS_MAX_UPLOAD_SIZE limits uploaded .lrc and text files
+	LYRICS_MAX_UPLOAD_SIZE = 1 << 20
+
+	// metadata refresh
+	// METADATA_REFRESH_CHECK_INTERVAL is how often the refresh job looks for stale metadata
+	METADATA_REFRESH_CHECK_INTERVAL = 6 * time.Hour
+
+	// metadata locale
+	// METADATA_LANGUAGE and CERTIFICATION_COUNTRY are the default language of titles and
+	// overviews and the country whose age ratings are preferred
+	METADATA_LANGUAGE     = "en-US"
+	CERTIFICATION_COUNTRY = "US"
+
+	// audio streaming
+	// AUDIO_TRANSCODE_MIME_TYPE is the format served for tracks browsers can't play natively.
+	// Every format that needs transcoding is lossless, so FLAC keeps the original quality.
+	AUDIO_TRANSCODE_MIME_TYPE = "audio/flac"
+
+	// podcasts
+	// PODCAST_POLL_CHECK_INTERVAL is how often the poller looks for feeds due for a poll
+	PODCAST_POLL_CHECK_INTERVAL = time.Minute
+	// PODCAST_FEED_TIMEOUT and PODCAST_FEED_MAX_SIZE bound a single feed fetch
+	PODCAST_FEED_TIMEOUT  = 30 * time.Second
+	PODCAST_FEED_MAX_SIZE = 20 << 20
+	// PODCAST_EPISODE_MAX_SIZE bounds a single episode download, well above the few
+	// hundred megabytes of a long episode
+	PODCAST_EPISODE_MAX_SIZE = 2 << 30
+	// PODCAST_AUTO_DOWNLOAD_LIMIT is how many of the newest episodes are downloaded when
+	// a podcast keeps all its downloads
+	PODCAST_AUTO_DOWNLOAD_LIMIT = 3
 
 	// spotify
-	SPOTIFY_ARTIST_MAX_CACHE = 100
-	SPOTIFY_ALBUM_MAX_CACHE  = 200
+	// SPOTIFY_RATE_LIMIT and SPOTIFY_RATE_BURST bound the requests per second sent to
+	// Spotify, which allows about 180 per rolling 30 seconds
+	SPOTIFY_RATE_LIMIT = 5
+	SPOTIFY_RATE_BURST = 10
+	// SPOTIFY_MAX_RETRIES is how often a 429 answer is retried after the Retry-After it
+	// asks for, or SPOTIFY_RETRY_DELAY without one; one above SPOTIFY_MAX_RETRY_DELAY is
+	// not waited for
+	SPOTIFY_MAX_RETRIES     = 3
+	SPOTIFY_RETRY_DELAY     = 5 * time.Second
+	SPOTIFY_MAX_RETRY_DELAY = time.Minute
+	// SPOTIFY_CACHE_TTL is how long artist and album searches are cached, and
+	// SPOTIFY_NOT_FOUND_CACHE_TTL how long searches without results are
+	SPOTIFY_CACHE_TTL           = 30 * 24 * time.Hour
+	SPOTIFY_NOT_FOUND_CACHE_TTL = 7 * 24 * time.Hour
+	// SPOTIFY_ALBUM_SEARCH_LIMIT is how many candidates an album search scores, and
+	// SPOTIFY_ALBUM_MATCH_THRESHOLD the score (0 to 1) the best one needs to be matched
+	SPOTIFY_ALBUM_SEARCH_LIMIT    = 10
+	SPOTIFY_ALBUM_MATCH_THRESHOLD = 0.7
 
 	// auth keys
 	COOKIE_USER_ID              = "user_id"
+	COOKIE_LASTFM_STATE         = "lastfm_state"
 	NOT_AUTHORIZED_MESSAGE      = "not authorized"
+	FORBIDDEN_MESSAGE           = "admin access required"
 	INVALID_CREDENTIALS_MESSAGE = "invalid email or password provided"
 
 	// error messages
@@ -36,4 +114,68 @@ const (
 	// TMDB_YEAR_MATCH_SCORE is the score bonus for exact year matches in TMDB search results.
 	// This ensures exact year matches are prioritized over popularity/vote average.
 	TMDB_YEAR_MATCH_SCORE = 10000.0
+	// TMDB_RATE_LIMIT and TMDB_RATE_BURST bound the requests per second sent to TMDB,
+	// well below the ~50 per second it starts answering 429 at
+	TMDB_RATE_LIMIT = 20
+	TMDB_RATE_BURST = 10
+	// TMDB_MAX_RETRIES is how often a 429 or 5xx answer is retried. Retries wait the
+	// Retry-After the answer asks for, or a jittered backoff doubling from
+	// TMDB_RETRY_BASE_DELAY; a Retry-After above TMDB_MAX_RETRY_DELAY is not waited for
+	TMDB_MAX_RETRIES      = 3
+	TMDB_RETRY_BASE_DELAY = 500 * time.Millisecond
+	TMDB_MAX_RETRY_DELAY  = 30 * time.Second
+	TMDB_REQUEST_TIMEOUT  = 30 * time.Second
+	// TMDB_CACHE_TTL is how long movie details are cached. Searches and the in
+	// theaters and popular lists change more often
+	TMDB_CACHE_TTL        = 7 * 24 * time.Hour
+	TMDB_SEARCH_CACHE_TTL = 24 * time.Hour
+	TMDB_LIST_CACHE_TTL   = 6 * time.Hour
+
+	// constants for last.fm
+	LASTFM_BASE_API_URL    = "https://ws.audioscrobbler.com/2.0/"
+	LASTFM_AUTH_URL        = "https://www.last.fm/api/auth/"
+	LASTFM_REQUEST_TIMEOUT = 15 * time.Second
+	// LASTFM_MIN_TRACK_DURATION is the length below which Last.fm takes no scrobbles, and
+	// a play counts once half the track or LASTFM_SCROBBLE_PLAYED_TIME was listened to
+	LASTFM_MIN_TRACK_DURATION   = 30 * time.Second
+	LASTFM_SCROBBLE_PLAYED_TIME = 4 * time.Minute
+	// LASTFM_SCROBBLE_INTERVAL is how often the scrobbler sends the queued plays that are
+	// due, at most LASTFM_SCROBBLE_BATCH_SIZE per request
+	LASTFM_SCROBBLE_INTERVAL   = time.Minute
+	LASTFM_SCROBBLE_BATCH_SIZE = 50
+	// LASTFM_RETRY_BASE_DELAY is how long a failed scrobble waits, doubled on each
+	// attempt up to LASTFM_MAX_RETRY_DELAY. Plays older than LASTFM_SCROBBLE_MAX_AGE are
+	// dropped, Last.fm ignores them, and so are the ones Last.fm refused
+	// LASTFM_SCROBBLE_MAX_ATTEMPTS times while it was reachable
+	LASTFM_RETRY_BASE_DELAY      = time.Minute
+	LASTFM_MAX_RETRY_DELAY       = 6 * time.Hour
+	LASTFM_SCROBBLE_MAX_AGE      = 14 * 24 * time.Hour
+	LASTFM_SCROBBLE_MAX_ATTEMPTS = 5
+
+	// constants for listenbrainz
+	LISTENBRAINZ_BASE_API_URL    = "https://api.listenbrainz.org"
+	LISTENBRAINZ_REQUEST_TIMEOUT = 30 * time.Second
+	// LISTENBRAINZ_RATE_LIMIT and LISTENBRAINZ_RATE_BURST bound the requests per second,
+	// on top of the waits ListenBrainz asks for in its X-RateLimit headers
+	LISTENBRAINZ_RATE_LIMIT = 2
+	LISTENBRAINZ_RATE_BURST = 5
+	// LISTENBRAINZ_SUBMISSION_CLIENT names igloo in the listens it submits, the history
+	// import skips them as they are in the play history already
+	LISTENBRAINZ_SUBMISSION_CLIENT = "igloo"
+	// LISTENBRAINZ_LISTEN_PLAYED_TIME caps the half of a track a play needs to count
+	LISTENBRAINZ_LISTEN_PLAYED_TIME = 4 * time.Minute
+	// LISTENBRAINZ_SUBMIT_INTERVAL is how often the queued listens that are due are
+	// submitted, at most LISTENBRAINZ_SUBMIT_BATCH_SIZE per request
+	LISTENBRAINZ_SUBMIT_INTERVAL   = time.Minute
+	LISTENBRAINZ_SUBMIT_BATCH_SIZE = 100
+	// LISTENBRAINZ_RETRY_BASE_DELAY is how long a failed listen waits, doubled on each
+	// attempt up to LISTENBRAINZ_MAX_RETRY_DELAY
+	LISTENBRAINZ_RETRY_BASE_DELAY = time.Minute
+	LISTENBRAINZ_MAX_RETRY_DELAY  = 6 * time.Hour
+	// LISTENBRAINZ_IMPORT_PAGE_SIZE is how many listens a history request returns, the
+	// API's maximum. A failed page is requested again LISTENBRAINZ_IMPORT_MAX_RETRIES
+	// times, LISTENBRAINZ_IMPORT_RETRY_DELAY apart
+	LISTENBRAINZ_IMPORT_PAGE_SIZE   = 1000
+	LISTENBRAINZ_IMPORT_MAX_RETRIES = 3
+	LISTENBRAINZ_IMPORT_RETRY_DELAY = 10 * time.Second
 )
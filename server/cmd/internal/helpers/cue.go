@@ -0,0 +1,199 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CueSheet is a parsed CUE sheet: album-level metadata and the audio files it splits.
+type CueSheet struct {
+	Title     string
+	Performer string
+	Date      string // REM DATE
+	Genre     string // REM GENRE
+	Files     []CueFile
+}
+
+// CueFile is one FILE entry of a CUE sheet. Name is as written in the sheet,
+// usually relative to the sheet's directory.
+type CueFile struct {
+	Name   string
+	Tracks []CueTrack
+}
+
+// CueTrack is one TRACK entry. Start is the INDEX 01 offset in milliseconds.
+type CueTrack struct {
+	Number     int
+	Title      string
+	Performer  string
+	Songwriter string
+	Start      int64
+}
+
+// ParseCueSheet parses the FILE, TRACK, INDEX, TITLE, PERFORMER, SONGWRITER and
+// REM DATE/GENRE commands of a CUE sheet; other commands are ignored. Sheets are
+// often written by Windows rippers in a legacy code page, so content that isn't
+// valid UTF-8 is read as Latin-1.
+func ParseCueSheet(data []byte) (*CueSheet, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
+
+	sheet := &CueSheet{}
+	var file *CueFile
+	var track *CueTrack
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := cueFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		arg := func(i int) string {
+			if i < len(fields) {
+				return fields[i]
+			}
+			return ""
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "FILE":
+			sheet.Files = append(sheet.Files, CueFile{Name: arg(1)})
+			file = &sheet.Files[len(sheet.Files)-1]
+			track = nil
+		case "TRACK":
+			if file == nil {
+				return nil, fmt.Errorf("line %d: TRACK before FILE", line)
+			}
+			number, err := strconv.Atoi(arg(1))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid track number %q", line, arg(1))
+			}
+			// A start of -1 marks a track whose INDEX 01 hasn't been seen yet
+			file.Tracks = append(file.Tracks, CueTrack{Number: number, Start: -1})
+			track = &file.Tracks[len(file.Tracks)-1]
+		case "INDEX":
+			if track == nil || arg(1) != "01" {
+				continue
+			}
+			start, err := ParseCueTime(arg(2))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			track.Start = start
+		case "TITLE":
+			if track != nil {
+				track.Title = arg(1)
+			} else {
+				sheet.Title = arg(1)
+			}
+		case "PERFORMER":
+			if track != nil {
+				track.Performer = arg(1)
+			} else {
+				sheet.Performer = arg(1)
+			}
+		case "SONGWRITER":
+			if track != nil {
+				track.Songwriter = arg(1)
+			}
+		case "REM":
+			switch strings.ToUpper(arg(1)) {
+			case "DATE":
+				sheet.Date = arg(2)
+			case "GENRE":
+				sheet.Genre = arg(2)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	tracks := 0
+	for _, f := range sheet.Files {
+		for _, t := range f.Tracks {
+			if t.Start < 0 {
+				return nil, fmt.Errorf("track %d has no INDEX 01", t.Number)
+			}
+		}
+		tracks += len(f.Tracks)
+	}
+
+	if tracks == 0 {
+		return nil, errors.New("cue sheet has no tracks")
+	}
+
+	return sheet, nil
+}
+
+// ParseCueTime parses a CUE "mm:ss:ff" timestamp (75 frames per second) into milliseconds.
+func ParseCueTime(s string) (int64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid cue time %q", s)
+	}
+
+	var values [3]int64
+	for i, part := range parts {
+		v, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid cue time %q", s)
+		}
+		values[i] = v
+	}
+
+	if values[1] >= 60 || values[2] >= 75 {
+		return 0, fmt.Errorf("invalid cue time %q", s)
+	}
+
+	return values[0]*60_000 + values[1]*1000 + values[2]*1000/75, nil
+}
+
+// cueFields splits a CUE line into its command and arguments. Double-quoted
+// arguments may contain spaces.
+func cueFields(line string) []string {
+	var fields []string
+	line = strings.TrimSpace(line)
+
+	for line != "" {
+		if line[0] == '"' {
+			end := strings.IndexByte(line[1:], '"')
+			if end < 0 {
+				fields = append(fields, line[1:])
+				break
+			}
+			fields = append(fields, line[1:end+1])
+			line = strings.TrimSpace(line[end+2:])
+			continue
+		}
+
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			fields = append(fields, line)
+			break
+		}
+		fields = append(fields, line[:end])
+		line = strings.TrimSpace(line[end:])
+	}
+
+	return fields
+}
+
+// latin1ToUTF8 converts ISO-8859-1 bytes to UTF-8.
+func latin1ToUTF8(data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	for _, b := range data {
+		buf.WriteRune(rune(b))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestParseCueSheet(t *testing.T) {
+	data := []byte("\xef\xbb\xbfREM GENRE Classical\r\n" +
+		"REM DATE 1963\r\n" +
+		"PERFORMER \"Berliner Philharmoniker\"\r\n" +
+		"TITLE \"Beethoven: Symphony No. 5\"\r\n" +
+		"FILE \"CD1.flac\" WAVE\r\n" +
+		"  TRACK 01 AUDIO\r\n" +
+		"    TITLE \"I. Allegro con brio\"\r\n" +
+		"    INDEX 01 00:00:00\r\n" +
+		"  TRACK 02 AUDIO\r\n" +
+		"    TITLE \"II. Andante con moto\"\r\n" +
+		"    PERFORMER \"Herbert von Karajan\"\r\n" +
+		"    INDEX 00 07:20:50\r\n" +
+		"    INDEX 01 07:22:37\r\n")
+
+	sheet, err := ParseCueSheet(data)
+	if err != nil {
+		t.Fatalf("ParseCueSheet failed: %v", err)
+	}
+
+	if sheet.Title != "Beethoven: Symphony No. 5" || sheet.Performer != "Berliner Philharmoniker" {
+		t.Errorf("Unexpected album: %q by %q", sheet.Title, sheet.Performer)
+	}
+
+	if sheet.Date != "1963" || sheet.Genre != "Classical" {
+		t.Errorf("Unexpected date and genre: %q, %q", sheet.Date, sheet.Genre)
+	}
+
+	if len(sheet.Files) != 1 || sheet.Files[0].Name != "CD1.flac" || len(sheet.Files[0].Tracks) != 2 {
+		t.Fatalf("Unexpected files: %+v", sheet.Files)
+	}
+
+	second := sheet.Files[0].Tracks[1]
+	if second.Number != 2 || second.Title != "II. Andante con moto" || second.Performer != "Herbert von Karajan" {
+		t.Errorf("Unexpected second track: %+v", second)
+	}
+
+	// INDEX 01, not the pregap INDEX 00: 7m22s plus 37 frames
+	if second.Start != 442_493 {
+		t.Errorf("Expected the second track to start at 442493ms, got %d", second.Start)
+	}
+}
+
+func TestParseCueSheet_Latin1(t *testing.T) {
+	sheet, err := ParseCueSheet([]byte("FILE \"a.flac\" WAVE\nTRACK 01 AUDIO\nTITLE \"Caf\xe9\"\nINDEX 01 00:00:00\n"))
+	if err != nil {
+		t.Fatalf("ParseCueSheet failed: %v", err)
+	}
+
+	if got := sheet.Files[0].Tracks[0].Title; got != "Café" {
+		t.Errorf("Expected the Latin-1 title to be decoded, got %q", got)
+	}
+}
+
+func TestParseCueSheet_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no tracks", "TITLE \"Empty\"\nFILE \"a.flac\" WAVE\n"},
+		{"track before file", "TRACK 01 AUDIO\n"},
+		{"missing index", "FILE \"a.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 00 00:00:00\n"},
+		{"bad time", "FILE \"a.flac\" WAVE\nTRACK 01 AUDIO\nINDEX 01 00:61:00\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCueSheet([]byte(tt.data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseCueTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"00:00:00", 0},
+		{"01:02:00", 62_000},
+		{"00:00:74", 986},
+		{"75:00:00", 4_500_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseCueTime(tt.input)
+			if err != nil || got != tt.expected {
+				t.Errorf("ParseCueTime(%q) = %d, %v, want %d", tt.input, got, err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MovieExtra describes a local extra recognized from its path.
+type MovieExtra struct {
+	Type  string // local_extras.type
+	Title string
+	// MovieDir is the directory holding the movie the extra belongs to
+	MovieDir string
+	// Prefix is the filename before the type suffix ("Movie (2010)" for
+	// "Movie (2010)-trailer.mkv"), empty for extras found in an extras folder
+	Prefix string
+}
+
+// movieExtraFolders maps the extras folders kept next to a movie (compared
+// case-insensitively) to the type of the files inside them.
+var movieExtraFolders = map[string]string{
+	"behind the scenes": "behind_the_scenes",
+	"deleted scenes":    "deleted_scene",
+	"featurettes":       "featurette",
+	"interviews":        "interview",
+	"scenes":            "scene",
+	"shorts":            "short",
+	"trailers":          "trailer",
+	"extras":            "other",
+	"other":             "other",
+}
+
+// movieExtraSuffixes maps filename suffixes (compared case-insensitively) to extra types.
+var movieExtraSuffixes = map[string]string{
+	"-behindthescenes": "behind_the_scenes",
+	"-deleted":         "deleted_scene",
+	"-featurette":      "featurette",
+	"-interview":       "interview",
+	"-scene":           "scene",
+	"-short":           "short",
+	"-trailer":         "trailer",
+	"-other":           "other",
+}
+
+// ParseMovieExtra reports whether a video file is an extra rather than a movie:
+// a file directly inside an extras folder of a movie folder that holds the movie
+// itself ("Movie (2010)/Featurettes/Making Of.mkv", next to "Movie (2010)/Movie (2010).mkv")
+// or a file named with an extra suffix ("Movie (2010)/Movie (2010)-trailer.mkv").
+// An extras-named folder without a movie next to it, like a "Shorts" collection at the
+// top of the library, holds movies.
+func ParseMovieExtra(path string) (MovieExtra, bool) {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+
+	if extraType, ok := movieExtraFolders[strings.ToLower(filepath.Base(dir))]; ok && hasMainVideo(filepath.Dir(dir)) {
+		return MovieExtra{
+			Type:     extraType,
+			Title:    name,
+			MovieDir: filepath.Dir(dir),
+		}, true
+	}
+
+	if prefix, extraType, ok := parseExtraSuffix(name); ok {
+		return MovieExtra{
+			Type:     extraType,
+			Title:    prefix,
+			MovieDir: dir,
+			Prefix:   prefix,
+		}, true
+	}
+
+	return MovieExtra{}, false
+}
+
+// parseExtraSuffix splits a filename without extension into the name before an extra
+// suffix and the type of the extra. Returns false when it has no suffix or no name.
+func parseExtraSuffix(name string) (string, string, bool) {
+	lower := strings.ToLower(name)
+	for suffix, extraType := range movieExtraSuffixes {
+		if !strings.HasSuffix(lower, suffix) {
+			continue
+		}
+
+		prefix := strings.TrimSpace(name[:len(name)-len(suffix)])
+		if prefix == "" {
+			continue
+		}
+
+		return prefix, extraType, true
+	}
+
+	return "", "", false
+}
+
+// hasMainVideo reports whether dir directly holds a video that isn't an extra.
+func hasMainVideo(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !ValidVideoExtensions[strings.ToLower(GetFileExtension(entry.Name()))] {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if _, _, ok := parseExtraSuffix(name); !ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMovieExtra(t *testing.T) {
+	root := t.TempDir()
+
+	// Extras folders only hold extras next to the movie they belong to
+	for _, file := range []string{
+		"Alien (1979)/Alien (1979).mkv",
+		"Heat (1995)/Heat (1995)-trailer.mkv",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		ok       bool
+		expected MovieExtra
+	}{
+		{"movie", "Alien (1979)/Alien (1979).mkv", false, MovieExtra{}},
+		{"movie named like a folder", "Trailers (2010).mkv", false, MovieExtra{}},
+		{
+			"featurettes folder", "Alien (1979)/Featurettes/Making Of.mkv", true,
+			MovieExtra{Type: "featurette", Title: "Making Of", MovieDir: "Alien (1979)"},
+		},
+		{
+			"folder case insensitive", "Alien (1979)/behind the scenes/Design.mp4", true,
+			MovieExtra{Type: "behind_the_scenes", Title: "Design", MovieDir: "Alien (1979)"},
+		},
+		{
+			"deleted scenes folder", "Alien (1979)/Deleted Scenes/Cocoon.mkv", true,
+			MovieExtra{Type: "deleted_scene", Title: "Cocoon", MovieDir: "Alien (1979)"},
+		},
+		{
+			"trailers folder", "Alien (1979)/Trailers/Teaser.mkv", true,
+			MovieExtra{Type: "trailer", Title: "Teaser", MovieDir: "Alien (1979)"},
+		},
+		{"collection folder at the library root", "Shorts/Paperman (2012).mkv", false, MovieExtra{}},
+		{"extras folder next to extras only", "Heat (1995)/Extras/Bank Job.mkv", false, MovieExtra{}},
+		{
+			"trailer suffix", "Alien (1979)/Alien (1979)-trailer.mkv", true,
+			MovieExtra{Type: "trailer", Title: "Alien (1979)", MovieDir: "Alien (1979)", Prefix: "Alien (1979)"},
+		},
+		{
+			"suffix case insensitive", "Alien (1979)-Featurette.mkv", true,
+			MovieExtra{Type: "featurette", Title: "Alien (1979)", MovieDir: ".", Prefix: "Alien (1979)"},
+		},
+		{"suffix without a name", "Alien (1979)/-trailer.mkv", false, MovieExtra{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(root, filepath.FromSlash(tt.path))
+			expected := tt.expected
+			if tt.ok {
+				expected.MovieDir = filepath.Join(root, filepath.FromSlash(expected.MovieDir))
+			}
+
+			extra, ok := ParseMovieExtra(path)
+			if ok != tt.ok || extra != expected {
+				t.Errorf("ParseMovieExtra(%q) = (%+v, %v), want (%+v, %v)", path, extra, ok, expected, tt.ok)
+			}
+		})
+	}
+}
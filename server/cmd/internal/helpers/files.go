@@ -3,6 +3,7 @@ package helpers
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -16,21 +17,152 @@ var ValidAudioExtensions = map[string]bool{
 	"mp3":  true,
 	"flac": true,
 	"m4a":  true,
+	"ogg":  true,
+	"oga":  true,
+	"opus": true,
+	"wav":  true,
+	"aif":  true,
+	"aiff": true,
+	"wv":   true,
+	"ape":  true,
 }
 
-// AudioMimeTypes maps audio file extensions to their MIME types.
+// AudioContainers maps audio file extensions to the container stored on tracks.
+// Alternate extensions for the same format share one container value.
+var AudioContainers = map[string]string{
+	"mp3":  "mp3",
+	"flac": "flac",
+	"m4a":  "m4a",
+	"m4b":  "m4a",
+	"ogg":  "ogg",
+	"oga":  "ogg",
+	"opus": "opus",
+	"wav":  "wav",
+	"aif":  "aiff",
+	"aiff": "aiff",
+	"wv":   "wv",
+	"ape":  "ape",
+}
+
+// AudioMimeTypes maps audio containers to their MIME types.
 var AudioMimeTypes = map[string]string{
 	"mp3":  "audio/mpeg",
 	"flac": "audio/flac",
 	"m4a":  "audio/mp4",
+	"ogg":  "audio/ogg",
+	"opus": "audio/ogg",
+	"wav":  "audio/wav",
+	"aiff": "audio/aiff",
+	"wv":   "audio/x-wavpack",
+	"ape":  "audio/x-ape",
+}
+
+// IsAudiobookExtension reports whether the audiobook scanner reads files with ext:
+// every music format, plus m4b, the MP4 container audiobooks are sold in.
+func IsAudiobookExtension(ext string) bool {
+	return ext == "m4b" || ValidAudioExtensions[ext]
+}
+
+// transcodeAudioContainers are containers no mainstream browser can decode.
+var transcodeAudioContainers = map[string]bool{
+	"aiff": true,
+	"wv":   true,
+	"ape":  true,
 }
 
-var ValidVideoExtensions = map[string]bool{
-	"mp4": true,
-	"avi": true,
-	"mkv": true,
-	"mov": true,
-	"m4v": true,
+// NeedsAudioTranscode reports whether a track has to be transcoded before a browser can play it.
+// ALAC shares the m4a container with AAC, so it can only be told apart by its codec.
+func NeedsAudioTranscode(container, codec string) bool {
+	return transcodeAudioContainers[container] || codec == "alac"
+}
+
+// VideoContainer describes a video container the movie scanner accepts.
+// Name is the value stored in movies.container and must be listed in its CHECK constraint.
+type VideoContainer struct {
+	Name       string
+	MimeType   string
+	Extensions []string
+}
+
+// VideoContainers is the single list of supported video containers.
+// ValidVideoExtensions and DetectVideoContainer are both derived from it.
+var VideoContainers = []VideoContainer{
+	{Name: "mkv", MimeType: "video/x-matroska", Extensions: []string{"mkv"}},
+	{Name: "webm", MimeType: "video/webm", Extensions: []string{"webm"}},
+	{Name: "mp4", MimeType: "video/mp4", Extensions: []string{"mp4"}},
+	{Name: "m4v", MimeType: "video/x-m4v", Extensions: []string{"m4v"}},
+	{Name: "mov", MimeType: "video/quicktime", Extensions: []string{"mov"}},
+	{Name: "avi", MimeType: "video/x-msvideo", Extensions: []string{"avi"}},
+	{Name: "ts", MimeType: "video/mp2t", Extensions: []string{"ts"}},
+	{Name: "m2ts", MimeType: "video/mp2t", Extensions: []string{"m2ts", "mts"}},
+	{Name: "wmv", MimeType: "video/x-ms-wmv", Extensions: []string{"wmv"}},
+	{Name: "mpg", MimeType: "video/mpeg", Extensions: []string{"mpg", "mpeg"}},
+}
+
+// ValidVideoExtensions is the set of file extensions the movie scanner picks up.
+var ValidVideoExtensions = func() map[string]bool {
+	extensions := make(map[string]bool)
+	for _, container := range VideoContainers {
+		for _, ext := range container.Extensions {
+			extensions[ext] = true
+		}
+	}
+	return extensions
+}()
+
+// videoContainerByExtension maps each extension to its container name.
+var videoContainerByExtension = func() map[string]string {
+	containers := make(map[string]string)
+	for _, container := range VideoContainers {
+		for _, ext := range container.Extensions {
+			containers[ext] = container.Name
+		}
+	}
+	return containers
+}()
+
+// videoFormatContainers maps an ffprobe format_name (the demuxer) to the containers it reads.
+// The first container is the default; the file extension picks between the others.
+var videoFormatContainers = map[string][]string{
+	"matroska,webm":           {"mkv", "webm"},
+	"mov,mp4,m4a,3gp,3g2,mj2": {"mp4", "m4v", "mov"},
+	"avi":                     {"avi"},
+	"mpegts":                  {"ts", "m2ts"},
+	"asf":                     {"wmv"},
+	"mpeg":                    {"mpg"},
+	"mpegvideo":               {"mpg"},
+}
+
+// DetectVideoContainer returns the container name for a file from its ffprobe format_name.
+// Demuxers shared by several containers (matroska/webm, the ISO family, MPEG-TS) are
+// told apart by the extension, so a mislabeled file still gets its real container.
+// Returns false when the format isn't a supported video container.
+func DetectVideoContainer(formatName, ext string) (string, bool) {
+	containers, ok := videoFormatContainers[formatName]
+	if !ok {
+		return "", false
+	}
+
+	byExt := videoContainerByExtension[strings.ToLower(ext)]
+	for _, container := range containers {
+		if container == byExt {
+			return container, true
+		}
+	}
+
+	return containers[0], true
+}
+
+// VideoMimeType returns the MIME type for a video container,
+// or "application/octet-stream" for unknown containers.
+func VideoMimeType(container string) string {
+	for _, c := range VideoContainers {
+		if c.Name == container {
+			return c.MimeType
+		}
+	}
+
+	return "application/octet-stream"
 }
 
 // knownNonYearTokens are common dot-separated suffixes that are not a release year.
@@ -119,6 +251,38 @@ func GetTitleAndYearFromFileName(fileName string) (*TitleYearResponse, error) {
 	return &TitleYearResponse{Title: title, Year: 0}, nil
 }
 
+// releaseTag matches the resolution, source and codec tags of release names.
+var releaseTag = regexp.MustCompile(`(?i)(^|[\s._\[(-])(2160p|1080p|720p|576p|480p|blu-?ray|bdrip|brrip|dvdrip|webrip|web-?dl|hdtv|[hx]\.?26[45]|hevc|xvid|remux)($|[\s._\])-])`)
+
+// releaseYear matches a year between dots or underscores, as in "Alien.1979.mkv".
+var releaseYear = regexp.MustCompile(`[._](19|20)\d\d($|[._])`)
+
+// IsReleaseName reports whether s looks like a release name, as rips often carry in
+// their title tag ("Alien.1979.1080p.BluRay.x264-GRP"), rather than a title: it holds
+// a resolution, source or codec tag, or has no spaces and a dot-separated year.
+func IsReleaseName(s string) bool {
+	if releaseTag.MatchString(s) {
+		return true
+	}
+
+	return !strings.ContainsAny(s, " \t") && releaseYear.MatchString(s)
+}
+
+// editionTag matches a Plex-style edition tag, e.g. "Blade Runner (1982) {edition-Final Cut}.mkv"
+var editionTag = regexp.MustCompile(`(?i)\s*\{edition-([^}]*)\}`)
+
+// ParseEdition extracts the edition named in a movie filename and returns the
+// filename with the tag removed, so it doesn't end up in the parsed title.
+// Returns an empty edition when the filename has no tag.
+func ParseEdition(fileName string) (edition, rest string) {
+	match := editionTag.FindStringSubmatch(fileName)
+	if match == nil {
+		return "", fileName
+	}
+
+	return strings.TrimSpace(match[1]), editionTag.ReplaceAllString(fileName, "")
+}
+
 func GetFileExtension(path string) string {
 	ext := filepath.Ext(path)
 
@@ -0,0 +1,135 @@
+package helpers
+
+import "testing"
+
+func TestNeedsAudioTranscode(t *testing.T) {
+	tests := []struct {
+		name      string
+		container string
+		codec     string
+		expected  bool
+	}{
+		{"mp3", "mp3", "mp3", false},
+		{"flac", "flac", "flac", false},
+		{"aac in m4a", "m4a", "aac", false},
+		{"alac in m4a", "m4a", "alac", true},
+		{"vorbis in ogg", "ogg", "vorbis", false},
+		{"opus", "opus", "opus", false},
+		{"pcm wav", "wav", "pcm_s16le", false},
+		{"pcm aiff", "aiff", "pcm_s16be", true},
+		{"wavpack", "wv", "wavpack", true},
+		{"monkey's audio", "ape", "ape", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NeedsAudioTranscode(tt.container, tt.codec)
+			if got != tt.expected {
+				t.Errorf("NeedsAudioTranscode(%q, %q) = %v, want %v", tt.container, tt.codec, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAudioContainersHaveMimeTypes(t *testing.T) {
+	for ext := range ValidAudioExtensions {
+		container, ok := AudioContainers[ext]
+		if !ok {
+			t.Errorf("extension %q has no container", ext)
+			continue
+		}
+
+		if _, ok := AudioMimeTypes[container]; !ok {
+			t.Errorf("container %q has no MIME type", container)
+		}
+	}
+}
+
+func TestDetectVideoContainer(t *testing.T) {
+	tests := []struct {
+		name       string
+		formatName string
+		ext        string
+		expected   string
+		ok         bool
+	}{
+		{"matroska", "matroska,webm", "mkv", "mkv", true},
+		{"webm", "matroska,webm", "webm", "webm", true},
+		{"matroska with wrong extension", "matroska,webm", "mp4", "mkv", true},
+		{"mp4", "mov,mp4,m4a,3gp,3g2,mj2", "mp4", "mp4", true},
+		{"m4v", "mov,mp4,m4a,3gp,3g2,mj2", "m4v", "m4v", true},
+		{"quicktime", "mov,mp4,m4a,3gp,3g2,mj2", "MOV", "mov", true},
+		{"mp4 with wrong extension", "mov,mp4,m4a,3gp,3g2,mj2", "mkv", "mp4", true},
+		{"avi", "avi", "avi", "avi", true},
+		{"transport stream", "mpegts", "ts", "ts", true},
+		{"blu-ray transport stream", "mpegts", "m2ts", "m2ts", true},
+		{"avchd transport stream", "mpegts", "mts", "m2ts", true},
+		{"windows media", "asf", "wmv", "wmv", true},
+		{"mpeg program stream", "mpeg", "mpg", "mpg", true},
+		{"unsupported format", "flv", "flv", "", false},
+		{"empty format", "", "mkv", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DetectVideoContainer(tt.formatName, tt.ext)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("DetectVideoContainer(%q, %q) = (%q, %v), want (%q, %v)", tt.formatName, tt.ext, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVideoFormatContainersAreKnown(t *testing.T) {
+	for formatName, containers := range videoFormatContainers {
+		for _, container := range containers {
+			if VideoMimeType(container) == "application/octet-stream" {
+				t.Errorf("format %q maps to unknown container %q", formatName, container)
+			}
+		}
+	}
+}
+
+func TestParseEdition(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		edition  string
+		rest     string
+	}{
+		{"no tag", "Alien (1979).mkv", "", "Alien (1979).mkv"},
+		{"tag after year", "Alien (1979) {edition-Director's Cut}.mkv", "Director's Cut", "Alien (1979).mkv"},
+		{"tag before year", "Alien {edition-Theatrical} (1979).mkv", "Theatrical", "Alien (1979).mkv"},
+		{"case insensitive", "Alien 1979 {Edition-Extended}.mkv", "Extended", "Alien 1979.mkv"},
+		{"empty edition", "Alien (1979) {edition-}.mkv", "", "Alien (1979).mkv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edition, rest := ParseEdition(tt.fileName)
+			if edition != tt.edition || rest != tt.rest {
+				t.Errorf("ParseEdition(%q) = (%q, %q), want (%q, %q)", tt.fileName, edition, rest, tt.edition, tt.rest)
+			}
+		})
+	}
+}
+
+func TestIsReleaseName(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected bool
+	}{
+		{"Alien", false},
+		{"Blade Runner 2049", false},
+		{"S.W.A.T.", false},
+		{"2001: A Space Odyssey", false},
+		{"Charlotte's Web", false},
+		{"Alien.1979.1080p.BluRay.x264-GRP", true},
+		{"Alien 1979 720p WEB-DL H.264", true},
+		{"Alien.1979", true},
+		{"alien_1979_remux", true},
+		{"Heat [BDRip]", true},
+	}
+	for _, tt := range tests {
+		if got := IsReleaseName(tt.title); got != tt.expected {
+			t.Errorf("IsReleaseName(%q) = %v, want %v", tt.title, got, tt.expected)
+		}
+	}
+}
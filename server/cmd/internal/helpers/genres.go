@@ -0,0 +1,45 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// genreSeparators are the characters taggers use to store several genres in one tag.
+// ffprobe joins multi-value Vorbis comments with ";", ID3v2.4 uses a NUL byte, and
+// Mp3tag writes "\\" or "|". "," is left alone as it is part of real genre names like
+// "Folk, World, & Country".
+var genreSeparators = func(r rune) bool {
+	switch r {
+	case ';', '|', '\\', '\x00':
+		return true
+	default:
+		return false
+	}
+}
+
+// spacedSlash separates genres the way Kodi and many taggers join them, "Crime / Drama".
+// A slash without spaces is part of a genre name like "R&B/Soul".
+var spacedSlash = regexp.MustCompile(`\s+/\s+`)
+
+// SplitGenres splits a raw genre tag into individual genre names.
+// Empty entries are dropped and duplicates (by NormalizeKey) are removed,
+// keeping the first spelling seen. Example: "Rock; Alternative; rock" -> ["Rock", "Alternative"]
+func SplitGenres(s string) []string {
+	parts := strings.FieldsFunc(spacedSlash.ReplaceAllString(s, ";"), genreSeparators)
+	genres := make([]string, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+
+	for _, part := range parts {
+		genre := strings.Join(strings.Fields(part), " ")
+		key := NormalizeKey(genre)
+		if key == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		genres = append(genres, genre)
+	}
+
+	return genres
+}
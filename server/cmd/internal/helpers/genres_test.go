@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitGenres(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"empty", "", []string{}},
+		{"single genre", "Rock", []string{"Rock"}},
+		{"semicolon separated", "Rock; Alternative", []string{"Rock", "Alternative"}},
+		{"pipe and backslash", "Jazz | Blues\\Funk", []string{"Jazz", "Blues", "Funk"}},
+		{"keeps commas", "Folk, World, & Country", []string{"Folk, World, & Country"}},
+		{"spaced slash", "Crime / Drama", []string{"Crime", "Drama"}},
+		{"keeps slashes in names", "R&B/Soul; Pop", []string{"R&B/Soul", "Pop"}},
+		{"null separated", "Pop\x00Dance", []string{"Pop", "Dance"}},
+		{"collapses inner whitespace", "  Hip   Hop  ", []string{"Hip Hop"}},
+		{"drops empty entries", "Rock;;  ; ", []string{"Rock"}},
+		{"dedupes spelling variants", "Hip-Hop; Hip Hop; hiphop", []string{"Hip-Hop"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitGenres(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SplitGenres(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
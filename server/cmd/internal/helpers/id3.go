@@ -0,0 +1,255 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+// ReadSyncedLyrics reads the synchronized lyrics (SYLT frame) of an ID3v2.3 or
+// ID3v2.4 tag at the start of an MP3 file. ffprobe only exposes unsynchronized
+// lyrics, so the frame is parsed here. Returns no lines when the file has no tag,
+// no SYLT frame, or one with timestamps in MPEG frames rather than milliseconds.
+func ReadSyncedLyrics(path string) ([]LyricLine, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(file, header); err != nil || string(header[:3]) != "ID3" {
+		return nil, "", nil
+	}
+
+	version, flags := header[3], header[5]
+	if version != 3 && version != 4 {
+		return nil, "", nil
+	}
+
+	tag := make([]byte, syncsafe(header[6:10]))
+	if _, err := io.ReadFull(file, tag); err != nil {
+		return nil, "", fmt.Errorf("failed to read id3 tag: %w", err)
+	}
+
+	// ID3v2.3 unsynchronizes the whole tag, ID3v2.4 flags each frame instead
+	if version == 3 && flags&0x80 != 0 {
+		tag = removeUnsync(tag)
+	}
+
+	if flags&0x40 != 0 && len(tag) >= 4 {
+		// The v2.3 size excludes its own four bytes, the v2.4 size includes them
+		size := int(binary.BigEndian.Uint32(tag[:4])) + 4
+		if version == 4 {
+			size = syncsafe(tag[:4])
+		}
+		tag = tag[min(size, len(tag)):]
+	}
+
+	var lines []LyricLine
+	var language string
+
+	for len(tag) >= 10 && tag[0] != 0 {
+		id := string(tag[:4])
+		size := int(binary.BigEndian.Uint32(tag[4:8]))
+		if version == 4 {
+			size = syncsafe(tag[4:8])
+		}
+		format := tag[9]
+
+		if size > len(tag)-10 {
+			break
+		}
+		body := tag[10 : 10+size]
+		tag = tag[10+size:]
+
+		if id != "SYLT" {
+			continue
+		}
+
+		if version == 3 {
+			// Compressed or encrypted frames are skipped, a group id precedes the data
+			if format&0xc0 != 0 {
+				continue
+			}
+			if format&0x20 != 0 && len(body) > 0 {
+				body = body[1:]
+			}
+		} else {
+			if format&0x0c != 0 {
+				continue
+			}
+			if format&0x40 != 0 && len(body) > 0 {
+				body = body[1:]
+			}
+			if format&0x01 != 0 && len(body) >= 4 {
+				body = body[4:]
+			}
+			if format&0x02 != 0 {
+				body = removeUnsync(body)
+			}
+		}
+
+		frameLines, frameLanguage, contentType, ok := parseSYLT(body)
+		if !ok || len(frameLines) == 0 {
+			continue
+		}
+
+		// Frames of other content, such as chord or event timings, only serve as a fallback
+		if lines == nil || contentType == 1 {
+			lines, language = frameLines, frameLanguage
+		}
+		if contentType == 1 {
+			break
+		}
+	}
+
+	return lines, language, nil
+}
+
+// parseSYLT parses the body of a SYLT frame into lyric lines. Writers store either
+// one entry per line or one per syllable, where a leading or trailing newline marks
+// where a line starts, so syllables are joined into their lines.
+func parseSYLT(body []byte) (lines []LyricLine, language string, contentType byte, ok bool) {
+	if len(body) < 6 {
+		return nil, "", 0, false
+	}
+
+	encoding := body[0]
+	language = strings.ToLower(strings.Trim(string(body[1:4]), "\x00 "))
+	timestampFormat, contentType := body[4], body[5]
+
+	// Timestamps counted in MPEG frames would need the bitrate to convert
+	if timestampFormat != 2 || encoding > 3 {
+		return nil, "", 0, false
+	}
+
+	decoder := id3TextDecoder{encoding: encoding, bigEndian: encoding == 2}
+
+	// The content descriptor
+	_, rest, ok := decoder.next(body[6:])
+	if !ok {
+		return nil, "", 0, false
+	}
+
+	type entry struct {
+		text string
+		time int64
+	}
+
+	var entries []entry
+	multiline := false
+	for len(rest) > 0 {
+		var text string
+		text, rest, ok = decoder.next(rest)
+		if !ok || len(rest) < 4 {
+			break
+		}
+		time := int64(binary.BigEndian.Uint32(rest[:4]))
+		rest = rest[4:]
+
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		text = strings.ReplaceAll(text, "\r", "\n")
+		multiline = multiline || strings.Contains(text, "\n")
+		entries = append(entries, entry{text: text, time: time})
+	}
+
+	breakNext := true
+	for _, e := range entries {
+		text := e.text
+		if strings.HasPrefix(text, "\n") {
+			breakNext = true
+		}
+		endsLine := strings.HasSuffix(text, "\n")
+		text = strings.Trim(text, "\n")
+
+		if breakNext || !multiline || len(lines) == 0 {
+			lines = append(lines, LyricLine{Time: e.time, Text: text})
+		} else {
+			lines[len(lines)-1].Text += text
+		}
+		breakNext = endsLine
+	}
+
+	for i := range lines {
+		lines[i].Text = strings.TrimSpace(lines[i].Text)
+	}
+
+	if language == "xxx" {
+		language = ""
+	}
+
+	return lines, language, contentType, true
+}
+
+// id3TextDecoder reads the terminated strings of an ID3v2 frame in the frame's text
+// encoding: 0 Latin-1, 1 UTF-16 with a byte order mark, 2 UTF-16BE and 3 UTF-8.
+type id3TextDecoder struct {
+	encoding  byte
+	bigEndian bool
+}
+
+// next decodes the string at the start of data and returns the data after its terminator.
+func (d *id3TextDecoder) next(data []byte) (string, []byte, bool) {
+	if d.encoding == 0 || d.encoding == 3 {
+		end := bytes.IndexByte(data, 0)
+		if end < 0 {
+			return "", nil, false
+		}
+		text := data[:end]
+		if d.encoding == 0 {
+			text = latin1ToUTF8(text)
+		}
+		return string(text), data[end+1:], true
+	}
+
+	end := -1
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 && data[i+1] == 0 {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", nil, false
+	}
+
+	text := data[:end]
+	if d.encoding == 1 && len(text) >= 2 {
+		// Every string carries its own byte order mark, strings without one keep the last
+		switch {
+		case text[0] == 0xff && text[1] == 0xfe:
+			d.bigEndian = false
+			text = text[2:]
+		case text[0] == 0xfe && text[1] == 0xff:
+			d.bigEndian = true
+			text = text[2:]
+		}
+	}
+
+	units := make([]uint16, len(text)/2)
+	for i := range units {
+		if d.bigEndian {
+			units[i] = binary.BigEndian.Uint16(text[2*i:])
+		} else {
+			units[i] = binary.LittleEndian.Uint16(text[2*i:])
+		}
+	}
+
+	return string(utf16.Decode(units)), data[end+2:], true
+}
+
+// syncsafe decodes a 28-bit ID3v2 size stored in four bytes of seven bits each.
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// removeUnsync reverses ID3v2 unsynchronization, which inserts a zero byte after
+// every 0xFF so tag data never looks like an MPEG sync word.
+func removeUnsync(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xff, 0x00}, []byte{0xff})
+}
@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+// id3Tag builds an ID3v2 tag of the given frames, each an id and body.
+func id3Tag(version byte, frames ...[2]any) []byte {
+	var body []byte
+	for _, frame := range frames {
+		data := frame[1].([]byte)
+		header := make([]byte, 10)
+		copy(header, frame[0].(string))
+		if version == 4 {
+			copy(header[4:8], syncsafeBytes(len(data)))
+		} else {
+			binary.BigEndian.PutUint32(header[4:8], uint32(len(data)))
+		}
+		body = append(append(body, header...), data...)
+	}
+
+	tag := append([]byte{'I', 'D', '3', version, 0, 0}, syncsafeBytes(len(body))...)
+	return append(tag, body...)
+}
+
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+func utf16WithBOM(s string) []byte {
+	out := []byte{0xff, 0xfe}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, unit)
+	}
+	return append(out, 0, 0)
+}
+
+func TestReadSyncedLyrics(t *testing.T) {
+	dir := t.TempDir()
+
+	// One entry per syllable, lines start with a newline
+	sylt := append([]byte{1, 'e', 'n', 'g', 2, 1}, utf16WithBOM("")...)
+	for _, entry := range []struct {
+		text string
+		time uint32
+	}{{"Hel", 1000}, {"lo", 1500}, {"\nWorld", 4000}} {
+		sylt = append(sylt, utf16WithBOM(entry.text)...)
+		sylt = binary.BigEndian.AppendUint32(sylt, entry.time)
+	}
+
+	path := filepath.Join(dir, "v23.mp3")
+	data := id3Tag(3, [2]any{"TIT2", []byte("\x00Song")}, [2]any{"SYLT", sylt})
+	if err := os.WriteFile(path, append(data, 0xff, 0xfb), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	lines, language, err := ReadSyncedLyrics(path)
+	if err != nil {
+		t.Fatalf("ReadSyncedLyrics failed: %v", err)
+	}
+
+	expected := []LyricLine{{Time: 1000, Text: "Hello"}, {Time: 4000, Text: "World"}}
+	if !reflect.DeepEqual(lines, expected) || language != "eng" {
+		t.Errorf("Expected %+v (eng), got %+v (%s)", expected, lines, language)
+	}
+
+	// ID3v2.4 with UTF-8 entries, one per line, and a data length indicator
+	sylt = []byte{3, 'X', 'X', 'X', 2, 1, 0}
+	sylt = binary.BigEndian.AppendUint32(append(sylt, "First\x00"...), 500)
+	sylt = binary.BigEndian.AppendUint32(append(sylt, "Second\x00"...), 2500)
+	sylt = append(syncsafeBytes(len(sylt)), sylt...)
+
+	data = id3Tag(4, [2]any{"SYLT", sylt})
+	data[10+9] = 0x01
+
+	path = filepath.Join(dir, "v24.mp3")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	lines, language, err = ReadSyncedLyrics(path)
+	if err != nil {
+		t.Fatalf("ReadSyncedLyrics failed: %v", err)
+	}
+
+	expected = []LyricLine{{Time: 500, Text: "First"}, {Time: 2500, Text: "Second"}}
+	if !reflect.DeepEqual(lines, expected) || language != "" {
+		t.Errorf("Expected %+v, got %+v (%s)", expected, lines, language)
+	}
+
+	// No tag at all
+	path = filepath.Join(dir, "plain.mp3")
+	if err := os.WriteFile(path, []byte{0xff, 0xfb, 0x90, 0x00}, 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if lines, _, err := ReadSyncedLyrics(path); err != nil || lines != nil {
+		t.Errorf("Expected no lyrics, got %+v (%v)", lines, err)
+	}
+}
@@ -0,0 +1,209 @@
+package helpers
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultIgnorePatterns are skipped in every library: NAS metadata and recycle bins,
+// and files still being downloaded.
+var DefaultIgnorePatterns = []string{
+	"@eaDir/",
+	"#recycle/",
+	"#snapshot/",
+	".Trash-*/",
+	"$RECYCLE.BIN/",
+	"lost+found/",
+	"*.part",
+	"*.partial",
+	"*.crdownload",
+	"*.!qB",
+	"*.!ut",
+}
+
+// DefaultMovieIgnorePatterns are also skipped in movie libraries: the sample clips
+// releases ship next to the movie, in a Sample folder or named like "movie-sample.mkv".
+var DefaultMovieIgnorePatterns = []string{
+	"[Ss]ample/",
+	"[Ss]amples/",
+	"[Ss]ample.*",
+	"*[-.][Ss]ample.*",
+	"* - [Ss]ample.*",
+}
+
+// ignoreRule is one gitignore pattern, matched against paths relative to base.
+type ignoreRule struct {
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// IgnoreMatcher matches paths against gitignore-style patterns collected from the
+// library settings and from .iglooignore files found during a walk. Like gitignore,
+// the last matching pattern wins and "!" re-includes a path.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// NewIgnoreMatcher returns a matcher with the default patterns and the given
+// library patterns, both relative to the library root.
+func NewIgnoreMatcher(root string, patterns []string) *IgnoreMatcher {
+	m := &IgnoreMatcher{}
+	m.AddPatterns(root, DefaultIgnorePatterns)
+	m.AddPatterns(root, patterns)
+	return m
+}
+
+// ParseIgnorePatterns splits newline-separated patterns, dropping blank lines and comments.
+func ParseIgnorePatterns(text string) []string {
+	patterns := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+// AddPatterns adds gitignore-style patterns relative to base.
+func (m *IgnoreMatcher) AddPatterns(base string, patterns []string) {
+	for _, pattern := range patterns {
+		if rule, ok := compileIgnorePattern(base, pattern); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// AddIgnoreFile adds the patterns of dir/.iglooignore, if there is one.
+func (m *IgnoreMatcher) AddIgnoreFile(dir string) error {
+	file, err := os.Open(filepath.Join(dir, IGNORE_FILE_NAME))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	m.AddPatterns(dir, ParseIgnorePatterns(strings.Join(lines, "\n")))
+	return nil
+}
+
+// Match reports whether path is ignored.
+func (m *IgnoreMatcher) Match(path string, isDir bool) bool {
+	ignored := false
+
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		rel, err := filepath.Rel(rule.base, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		if rule.re.MatchString(filepath.ToSlash(rel)) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// compileIgnorePattern converts a gitignore pattern to a regular expression.
+// Patterns without a slash match a name at any depth, others are relative to base.
+func compileIgnorePattern(base, pattern string) (ignoreRule, bool) {
+	rule := ignoreRule{base: base}
+
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return rule, false
+	}
+
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\`) {
+		// "\#" and "\!" escape a leading comment or negation marker
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return rule, false
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// A matched directory also covers everything below it
+	b.WriteString("(?:/.*)?$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return rule, false
+	}
+
+	rule.re = re
+	return rule, true
+}
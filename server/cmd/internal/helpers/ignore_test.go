@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := "/media/movies"
+	m := NewIgnoreMatcher(root, ParseIgnorePatterns("# samples\n*sample*.mkv\n\n/Incoming/\nextras/**/*.avi\n!keep-sample.mkv\n"))
+
+	tests := []struct {
+		name     string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"regular movie", "/media/movies/Alien (1979)/Alien (1979).mkv", false, false},
+		{"sample at any depth", "/media/movies/Alien (1979)/alien-sample.mkv", false, true},
+		{"negated", "/media/movies/Alien (1979)/keep-sample.mkv", false, false},
+		{"anchored directory", "/media/movies/Incoming", true, true},
+		{"anchored directory elsewhere", "/media/movies/Alien (1979)/Incoming", true, false},
+		{"anchored directory pattern on a file", "/media/movies/Incoming", false, false},
+		{"double star", "/media/movies/extras/a/b/clip.avi", false, true},
+		{"double star direct child", "/media/movies/extras/clip.avi", false, true},
+		{"default synology folder", "/media/movies/Alien (1979)/@eaDir", true, true},
+		{"default trash folder", "/media/movies/.Trash-1000", true, true},
+		{"default partial download", "/media/movies/Heat (1995).mkv.part", false, true},
+		{"outside root", "/media/music/sample.mkv", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.Match(tt.path, tt.isDir)
+			if got != tt.expected {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIgnoreMatcher_MovieSamples(t *testing.T) {
+	root := "/media/movies"
+	m := NewIgnoreMatcher(root, DefaultMovieIgnorePatterns)
+
+	tests := []struct {
+		name     string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"sample file", "/media/movies/Alien (1979)/sample.mkv", false, true},
+		{"sample folder", "/media/movies/Alien (1979)/Sample", true, true},
+		{"samples folder", "/media/movies/Samples", true, true},
+		{"dash suffix", "/media/movies/Alien (1979)/alien-sample.mkv", false, true},
+		{"dot suffix", "/media/movies/Alien (1979)/Alien.1979.1080p.sample.mkv", false, true},
+		{"spaced suffix", "/media/movies/Alien (1979)/Alien (1979) - Sample.mkv", false, true},
+		{"movie", "/media/movies/Alien (1979)/Alien (1979).mkv", false, false},
+		{"title with the word", "/media/movies/The Sample (2019)/The Sample (2019).mkv", false, false},
+		{"sample named file", "/media/movies/Sample", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.Match(tt.path, tt.isDir)
+			if got != tt.expected {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIgnoreMatcher_AddIgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "Alien (1979)")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, IGNORE_FILE_NAME), []byte("*.mkv\n!/Alien (1979).mkv\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+
+	m := NewIgnoreMatcher(root, nil)
+
+	// Directories without an ignore file are fine
+	if err := m.AddIgnoreFile(root); err != nil {
+		t.Fatalf("AddIgnoreFile(root) failed: %v", err)
+	}
+
+	if err := m.AddIgnoreFile(dir); err != nil {
+		t.Fatalf("AddIgnoreFile failed: %v", err)
+	}
+
+	if !m.Match(filepath.Join(dir, "Alien (1979) - Sample.mkv"), false) {
+		t.Error("Expected file matched by the directory's ignore file to be ignored")
+	}
+
+	if m.Match(filepath.Join(dir, "Alien (1979).mkv"), false) {
+		t.Error("Expected negated file to be kept")
+	}
+
+	// Patterns only apply below the directory holding the ignore file
+	if m.Match(filepath.Join(root, "Heat (1995).mkv"), false) {
+		t.Error("Expected file outside the ignore file's directory to be kept")
+	}
+}
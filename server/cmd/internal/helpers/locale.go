@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	languageTagPattern = regexp.MustCompile(`^([a-z]{2})(?:-([a-z]{2}))?$`)
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// NormalizeLanguageTag returns a language the way TMDB expects it: an ISO 639-1 code,
+// optionally followed by an ISO 3166-1 region. Example: "ES_es" -> "es-ES"
+func NormalizeLanguageTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+
+	m := languageTagPattern.FindStringSubmatch(normalized)
+	if m == nil {
+		return "", fmt.Errorf("invalid language %q, expected a code like es or es-ES", tag)
+	}
+
+	if m[2] == "" {
+		return m[1], nil
+	}
+	return m[1] + "-" + strings.ToUpper(m[2]), nil
+}
+
+// NormalizeCountryCode returns an ISO 3166-1 alpha-2 country code in upper case.
+func NormalizeCountryCode(code string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	if !countryCodePattern.MatchString(normalized) {
+		return "", fmt.Errorf("invalid country %q, expected a code like ES", code)
+	}
+
+	return normalized, nil
+}
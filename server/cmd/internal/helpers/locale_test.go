@@ -0,0 +1,46 @@
+package helpers
+
+import "testing"
+
+func TestNormalizeLanguageTag(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"es-ES", "es-ES", false},
+		{" ES_es ", "es-ES", false},
+		{"pt-br", "pt-BR", false},
+		{"de", "de", false},
+		{"", "", true},
+		{"spanish", "", true},
+		{"es-419", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeLanguageTag(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("NormalizeLanguageTag(%q) = %q, %v; want %q", tt.input, got, err, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeCountryCode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"ES", "ES", false},
+		{" gb ", "GB", false},
+		{"", "", true},
+		{"ESP", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeCountryCode(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("NormalizeCountryCode(%q) = %q, %v; want %q", tt.input, got, err, tt.expected)
+		}
+	}
+}
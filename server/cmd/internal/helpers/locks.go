@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// ParseLockedFields decodes a locked_fields column (a JSON array of field names).
+// A malformed value locks nothing.
+func ParseLockedFields(value string) []string {
+	fields := []string{}
+	if value == "" {
+		return fields
+	}
+
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return []string{}
+	}
+
+	return fields
+}
+
+// FormatLockedFields encodes field names for a locked_fields column, sorted and deduplicated.
+func FormatLockedFields(fields []string) string {
+	sorted := slices.Clone(fields)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	if sorted == nil {
+		sorted = []string{}
+	}
+
+	data, _ := json.Marshal(sorted)
+	return string(data)
+}
+
+// IsFieldLocked reports whether field is in a locked_fields column value.
+func IsFieldLocked(lockedFields, field string) bool {
+	return slices.Contains(ParseLockedFields(lockedFields), field)
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"testing"
+)
+
+func TestLockedFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []string
+		expected string
+	}{
+		{"empty", nil, "[]"},
+		{"sorted", []string{"year", "title"}, `["title","year"]`},
+		{"deduplicated", []string{"genres", "title", "genres"}, `["genres","title"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatLockedFields(tt.fields)
+			if got != tt.expected {
+				t.Errorf("FormatLockedFields(%v) = %s, want %s", tt.fields, got, tt.expected)
+			}
+
+			for _, field := range tt.fields {
+				if !IsFieldLocked(got, field) {
+					t.Errorf("Expected %q to be locked in %s", field, got)
+				}
+			}
+
+			if parsed := ParseLockedFields(got); FormatLockedFields(parsed) != got {
+				t.Errorf("ParseLockedFields(%s) = %v", got, parsed)
+			}
+		})
+	}
+
+	if IsFieldLocked("not json", "title") || IsFieldLocked("", "title") {
+		t.Error("Expected malformed and empty values to lock nothing")
+	}
+}
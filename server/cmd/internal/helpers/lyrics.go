@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LyricLine is one line of synchronized lyrics. Time is the offset in milliseconds.
+type LyricLine struct {
+	Time int64  `json:"time"`
+	Text string `json:"text"`
+}
+
+var (
+	// lrcTimestamp matches a line timestamp: [mm:ss], [mm:ss.xx] or [mm:ss:xx]
+	lrcTimestamp = regexp.MustCompile(`^\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+	// lrcTag matches an ID tag line such as [ar:Artist] or [offset:+250]
+	lrcTag = regexp.MustCompile(`^\[([a-zA-Z#]+):(.*)\]$`)
+	// lrcWordTimestamp matches the per-word <mm:ss.xx> timestamps of enhanced LRC
+	lrcWordTimestamp = regexp.MustCompile(`<\d+:\d{1,2}(?:[.:]\d{1,3})?>`)
+)
+
+// DecodeLyricsText returns lyrics file content as UTF-8 with Unix line endings.
+// A byte order mark is dropped, and content that isn't valid UTF-8 is read as Latin-1,
+// which is what older Windows tools write.
+func DecodeLyricsText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
+
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	return strings.TrimSpace(strings.ReplaceAll(text, "\r", "\n"))
+}
+
+// ParseLRC parses LRC lyrics into lines sorted by time. A line may carry several
+// timestamps when it is repeated, [offset:] tags shift every line and other ID tags
+// are ignored. Returns false when the text has no timestamps, i.e. plain lyrics.
+func ParseLRC(text string) ([]LyricLine, bool) {
+	var lines []LyricLine
+	var offset int64
+
+	for _, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSpace(raw)
+
+		var times []int64
+		for {
+			match := lrcTimestamp.FindStringSubmatch(raw)
+			if match == nil {
+				break
+			}
+			times = append(times, lrcTime(match[1], match[2], match[3]))
+			raw = strings.TrimSpace(raw[len(match[0]):])
+		}
+
+		if len(times) == 0 {
+			if tag := lrcTag.FindStringSubmatch(raw); tag != nil && strings.EqualFold(tag[1], "offset") {
+				// A positive offset makes the lyrics appear sooner
+				if value, err := strconv.ParseInt(strings.TrimSpace(tag[2]), 10, 64); err == nil {
+					offset = value
+				}
+			}
+			continue
+		}
+
+		text := strings.TrimSpace(lrcWordTimestamp.ReplaceAllString(raw, ""))
+		for _, time := range times {
+			lines = append(lines, LyricLine{Time: time, Text: text})
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, false
+	}
+
+	for i := range lines {
+		lines[i].Time = max(lines[i].Time-offset, 0)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time < lines[j].Time
+	})
+
+	return lines, true
+}
+
+// lrcTime converts the minutes, seconds and fraction of an LRC timestamp to
+// milliseconds. The fraction is hundredths with two digits and milliseconds with three.
+func lrcTime(minutes, seconds, fraction string) int64 {
+	m, _ := strconv.ParseInt(minutes, 10, 64)
+	s, _ := strconv.ParseInt(seconds, 10, 64)
+	ms := m*60_000 + s*1000
+
+	if fraction != "" {
+		f, _ := strconv.ParseInt(fraction, 10, 64)
+		switch len(fraction) {
+		case 1:
+			ms += f * 100
+		case 2:
+			ms += f * 10
+		default:
+			ms += f
+		}
+	}
+
+	return ms
+}
+
+// FormatLRC writes synchronized lyrics as LRC with [mm:ss.xx] timestamps.
+func FormatLRC(lines []LyricLine) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "[%02d:%02d.%02d]%s", line.Time/60_000, line.Time/1000%60, line.Time%1000/10, line.Text)
+	}
+	return b.String()
+}
+
+// PlainLyrics joins the text of synchronized lyrics, one line per line.
+func PlainLyrics(lines []LyricLine) string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	return strings.Join(texts, "\n")
+}
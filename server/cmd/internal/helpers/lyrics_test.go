@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLRC(t *testing.T) {
+	text := DecodeLyricsText([]byte("\xef\xbb\xbf[ar:Artist]\r\n" +
+		"[ti:Song]\r\n" +
+		"[offset:+500]\r\n" +
+		"[00:12.00]First line\r\n" +
+		"[00:17.20][01:05.5]Chorus\r\n" +
+		"[00:20.123]<00:20.123>Word <00:21.00>by <00:21.50>word\r\n" +
+		"[00:00.10]\r\n"))
+
+	lines, synced := ParseLRC(text)
+	if !synced {
+		t.Fatal("Expected synced lyrics")
+	}
+
+	// Sorted, repeated for every timestamp and shifted by the offset
+	expected := []LyricLine{
+		{Time: 0, Text: ""},
+		{Time: 11_500, Text: "First line"},
+		{Time: 16_700, Text: "Chorus"},
+		{Time: 19_623, Text: "Word by word"},
+		{Time: 65_000, Text: "Chorus"},
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, lines)
+	}
+
+	if got := FormatLRC(lines[1:3]); got != "[00:11.50]First line\n[00:16.70]Chorus" {
+		t.Errorf("Unexpected LRC: %q", got)
+	}
+
+	if got := PlainLyrics(lines[1:3]); got != "First line\nChorus" {
+		t.Errorf("Unexpected plain lyrics: %q", got)
+	}
+}
+
+func TestParseLRC_Plain(t *testing.T) {
+	if lines, synced := ParseLRC("[ar:Artist]\nJust words\n[Chorus]\nMore words"); synced || lines != nil {
+		t.Errorf("Expected plain lyrics, got %+v", lines)
+	}
+}
+
+func TestDecodeLyricsText_Latin1(t *testing.T) {
+	if got := DecodeLyricsText([]byte("Caf\xe9\r\n")); got != "Café" {
+		t.Errorf("Expected Latin-1 to be decoded, got %q", got)
+	}
+}
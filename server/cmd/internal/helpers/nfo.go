@@ -0,0 +1,161 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotMovieNfo is returned by ParseMovieNfo for files without a <movie> root element,
+// such as URL-only NFOs or the NFOs of release groups.
+var ErrNotMovieNfo = errors.New("not a movie nfo")
+
+// MovieNfo is the metadata of a Kodi movie NFO file. Zero values mean the NFO
+// didn't set the field.
+type MovieNfo struct {
+	Title         string
+	OriginalTitle string
+	Year          int
+	Premiered     string // YYYY-MM-DD
+	Plot          string
+	Tagline       string
+	Runtime       int // minutes
+	Certification string
+	Rating        float64
+	Genres        []string
+	TmdbID        int64
+	ImdbID        string
+}
+
+// nfoMovie mirrors the <movie> element. Ids and ratings come in two generations:
+// <uniqueid type="..."> and <ratings><rating> (Kodi 17+), or the older <tmdbid>,
+// <imdbid>, <id> and <rating> elements that many tools still write.
+type nfoMovie struct {
+	XMLName       xml.Name `xml:"movie"`
+	Title         string   `xml:"title"`
+	OriginalTitle string   `xml:"originaltitle"`
+	Year          string   `xml:"year"`
+	Premiered     string   `xml:"premiered"`
+	ReleaseDate   string   `xml:"releasedate"`
+	Plot          string   `xml:"plot"`
+	Outline       string   `xml:"outline"`
+	Tagline       string   `xml:"tagline"`
+	Runtime       string   `xml:"runtime"`
+	Mpaa          string   `xml:"mpaa"`
+	Certification string   `xml:"certification"`
+	Genres        []string `xml:"genre"`
+	UniqueIDs     []struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"uniqueid"`
+	TmdbID  string `xml:"tmdbid"`
+	ImdbID  string `xml:"imdbid"`
+	ID      string `xml:"id"`
+	Rating  string `xml:"rating"`
+	Ratings []struct {
+		Name    string `xml:"name,attr"`
+		Default bool   `xml:"default,attr"`
+		Value   string `xml:"value"`
+	} `xml:"ratings>rating"`
+}
+
+// ParseMovieNfo parses a Kodi movie NFO. Anything after the closing </movie> tag,
+// like the IMDb URL some tools append, is ignored.
+func ParseMovieNfo(data []byte) (*MovieNfo, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var doc nfoMovie
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotMovieNfo, err)
+	}
+
+	nfo := &MovieNfo{
+		Title:         strings.TrimSpace(doc.Title),
+		OriginalTitle: strings.TrimSpace(doc.OriginalTitle),
+		Premiered:     nfoDate(firstNonBlank(doc.Premiered, doc.ReleaseDate)),
+		Plot:          strings.TrimSpace(firstNonBlank(doc.Plot, doc.Outline)),
+		Tagline:       strings.TrimSpace(doc.Tagline),
+		Certification: nfoCertification(firstNonBlank(doc.Certification, doc.Mpaa)),
+		ImdbID:        strings.TrimSpace(doc.ImdbID),
+	}
+
+	nfo.Year, _ = strconv.Atoi(strings.TrimSpace(doc.Year))
+	if nfo.Year == 0 && len(nfo.Premiered) >= 4 {
+		nfo.Year, _ = strconv.Atoi(nfo.Premiered[:4])
+	}
+
+	nfo.Runtime, _ = strconv.Atoi(strings.TrimSpace(doc.Runtime))
+	nfo.TmdbID, _ = strconv.ParseInt(strings.TrimSpace(doc.TmdbID), 10, 64)
+
+	for _, id := range doc.UniqueIDs {
+		value := strings.TrimSpace(id.Value)
+		switch strings.ToLower(id.Type) {
+		case "tmdb":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				nfo.TmdbID = n
+			}
+		case "imdb":
+			nfo.ImdbID = value
+		}
+	}
+
+	// <id> holds the id of the scraper that wrote the file, only IMDb's are recognizable
+	if id := strings.TrimSpace(doc.ID); nfo.ImdbID == "" && strings.HasPrefix(id, "tt") {
+		nfo.ImdbID = id
+	}
+
+	// The default rating wins, else the first one, else the legacy element
+	rating := doc.Rating
+	for i, r := range doc.Ratings {
+		if r.Default || i == 0 {
+			rating = r.Value
+		}
+		if r.Default {
+			break
+		}
+	}
+	nfo.Rating, _ = strconv.ParseFloat(strings.TrimSpace(rating), 64)
+
+	// Genres repeat the element, but scrapers also join several into one
+	if len(doc.Genres) > 0 {
+		nfo.Genres = SplitGenres(strings.Join(doc.Genres, ";"))
+	}
+
+	return nfo, nil
+}
+
+// nfoDate returns the YYYY-MM-DD date at the start of s, or "" when there is none.
+func nfoDate(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 10 {
+		return ""
+	}
+	if _, err := strconv.Atoi(s[:4]); err != nil || s[4] != '-' || s[7] != '-' {
+		return ""
+	}
+	return s[:10]
+}
+
+// nfoCertification strips the decorations scrapers add to a certification:
+// "Rated PG-13" and "US:PG-13" both become "PG-13".
+func nfoCertification(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		s = s[i+1:]
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "Rated ")
+	return strings.TrimSpace(s)
+}
+
+// firstNonBlank returns the first of values that isn't empty or whitespace.
+func firstNonBlank(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
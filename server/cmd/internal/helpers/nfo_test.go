@@ -0,0 +1,86 @@
+package helpers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseMovieNfo(t *testing.T) {
+	data := []byte("\xef\xbb\xbf" + `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<movie>
+    <title>The Matrix</title>
+    <originaltitle>The Matrix</originaltitle>
+    <ratings>
+        <rating name="imdb" max="10"><value>8.7</value></rating>
+        <rating name="themoviedb" max="10" default="true"><value>8.2</value></rating>
+    </ratings>
+    <plot>A hacker learns the truth.</plot>
+    <tagline>Welcome to the Real World.</tagline>
+    <runtime>136</runtime>
+    <mpaa>Rated R</mpaa>
+    <uniqueid type="imdb" default="true">tt0133093</uniqueid>
+    <uniqueid type="tmdb">603</uniqueid>
+    <genre>Action</genre>
+    <genre>Science Fiction / Thriller</genre>
+    <premiered>1999-03-30</premiered>
+</movie>
+https://www.imdb.com/title/tt0133093/`)
+
+	nfo, err := ParseMovieNfo(data)
+	if err != nil {
+		t.Fatalf("ParseMovieNfo failed: %v", err)
+	}
+
+	expected := &MovieNfo{
+		Title:         "The Matrix",
+		OriginalTitle: "The Matrix",
+		Year:          1999,
+		Premiered:     "1999-03-30",
+		Plot:          "A hacker learns the truth.",
+		Tagline:       "Welcome to the Real World.",
+		Runtime:       136,
+		Certification: "R",
+		Rating:        8.2,
+		Genres:        []string{"Action", "Science Fiction", "Thriller"},
+		TmdbID:        603,
+		ImdbID:        "tt0133093",
+	}
+	if !reflect.DeepEqual(nfo, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, nfo)
+	}
+}
+
+func TestParseMovieNfo_Legacy(t *testing.T) {
+	nfo, err := ParseMovieNfo([]byte(`<movie>
+    <title>Alien</title>
+    <year>1979</year>
+    <outline>In space no one can hear you scream.</outline>
+    <rating>8.4</rating>
+    <mpaa>US:R</mpaa>
+    <id>tt0078748</id>
+    <tmdbid>348</tmdbid>
+</movie>`))
+	if err != nil {
+		t.Fatalf("ParseMovieNfo failed: %v", err)
+	}
+
+	if nfo.Title != "Alien" || nfo.Year != 1979 || nfo.Premiered != "" || nfo.Plot != "In space no one can hear you scream." {
+		t.Errorf("Unexpected movie: %+v", nfo)
+	}
+	if nfo.Rating != 8.4 || nfo.Certification != "R" || nfo.ImdbID != "tt0078748" || nfo.TmdbID != 348 {
+		t.Errorf("Unexpected rating and ids: %+v", nfo)
+	}
+}
+
+func TestParseMovieNfo_NotAMovie(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"https://www.themoviedb.org/movie/603",
+		"<tvshow><title>Not a movie</title></tvshow>",
+	} {
+		if _, err := ParseMovieNfo([]byte(data)); !errors.Is(err, ErrNotMovieNfo) {
+			t.Errorf("Expected ErrNotMovieNfo for %q, got %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package helpers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeKey reduces a name to a comparison key so spelling variants match:
+// "Hip-Hop", "Hip Hop" and "hiphop" all become "hiphop". Letters are lowercased, "&"
+// is treated as "and", and everything that is not a letter or digit is removed.
+func NormalizeKey(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "&", "and")
+
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,30 @@
+package helpers
+
+import "testing"
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"lowercases", "Rock", "rock"},
+		{"hyphen", "Hip-Hop", "hiphop"},
+		{"space", "Hip Hop", "hiphop"},
+		{"already normalized", "hiphop", "hiphop"},
+		{"ampersand", "Drum & Bass", "drumandbass"},
+		{"and spelled out", "Drum and Bass", "drumandbass"},
+		{"keeps digits", "80s Pop", "80spop"},
+		{"keeps accented letters", "Música Latina", "músicalatina"},
+		{"punctuation only", "--", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeKey(tt.input)
+			if got != tt.expected {
+				t.Errorf("NormalizeKey(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
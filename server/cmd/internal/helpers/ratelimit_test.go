@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(20, 2)
+	ctx := context.Background()
+
+	// The burst is free, the next request waits for a token (50ms at 20/s)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the third request to wait about 50ms, took %s", elapsed)
+	}
+}
+
+func TestRateLimiter_Cancel(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with the context, got %v", err)
+	}
+}
+
+// TestRateLimiter_Pause tests that a pause holds back requests that have tokens left.
+func TestRateLimiter_Pause(t *testing.T) {
+	limiter := NewRateLimiter(20, 5)
+	limiter.Pause(50 * time.Millisecond)
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the request to wait out the pause, took %s", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := ParseRetryAfter("5"); d != 5*time.Second {
+		t.Errorf("Expected 5s, got %s", d)
+	}
+
+	if d := ParseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); d < 58*time.Second || d > time.Minute {
+		t.Errorf("Expected about a minute, got %s", d)
+	}
+
+	for _, value := range []string{"", "soon", "-1"} {
+		if d := ParseRetryAfter(value); d != -1 {
+			t.Errorf("ParseRetryAfter(%q) = %s, expected -1", value, d)
+		}
+	}
+}
@@ -38,6 +38,20 @@ func NullFloat64(f float64) sql.NullFloat64 {
 	return sql.NullFloat64{Float64: f, Valid: true}
 }
 
+// SQLiteTime formats t the way SQLite's CURRENT_TIMESTAMP does, in UTC, so it
+// sorts and compares as text with the timestamps the database sets.
+func SQLiteTime(t time.Time) string {
+	return t.UTC().Format(time.DateTime)
+}
+
+// EscapeLike escapes the LIKE wildcards in s, so it matches literally in a pattern
+// declared with ESCAPE '\'.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // ParseSlashNumber parses a "1/12" format string and returns the first number.
 // Used for parsing track numbers and disc numbers from metadata.
 func ParseSlashNumber(s string) (int64, error) {
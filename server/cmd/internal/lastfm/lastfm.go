@@ -0,0 +1,183 @@
+package lastfm
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"igloo/cmd/internal/helpers"
+)
+
+type LastfmInterface interface {
+	AuthURL(callbackURL string) string
+	GetSession(token string) (*Session, error)
+	UpdateNowPlaying(sessionKey string, track Track) error
+	Scrobble(sessionKey string, scrobbles []Scrobble) (*ScrobbleResult, error)
+}
+
+// Session is a linked Last.fm account. Its key doesn't expire, the user revokes it
+// on Last.fm.
+type Session struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// Track describes what is played. Artist and Track are required.
+type Track struct {
+	Artist      string
+	Track       string
+	Album       string
+	AlbumArtist string
+	TrackNumber int
+	// Duration is in seconds
+	Duration int
+	MBID     string
+}
+
+// Scrobble is a play of a track, Timestamp is the Unix time it started.
+type Scrobble struct {
+	Track
+	Timestamp int64
+}
+
+// ScrobbleResult counts the scrobbles Last.fm accepted and the ones it ignored, e.g.
+// for being too old. Ignored scrobbles are not worth sending again.
+type ScrobbleResult struct {
+	Accepted int
+	Ignored  int
+}
+
+// Config holds the optional settings of a client, zero values use the defaults.
+type Config struct {
+	// BaseURL replaces helpers.LASTFM_BASE_API_URL, e.g. to point tests at a local stub
+	BaseURL string
+	// AuthURL replaces helpers.LASTFM_AUTH_URL, the page users grant access on
+	AuthURL string
+}
+
+type lastfmClient struct {
+	key     string
+	secret  string
+	baseURL string
+	authURL string
+	http    *http.Client
+}
+
+func New(apiKey, sharedSecret string, config Config) (LastfmInterface, error) {
+	if apiKey == "" || sharedSecret == "" {
+		return nil, errors.New("LASTFM_API_KEY and LASTFM_SHARED_SECRET environment variables are not set")
+	}
+
+	client := lastfmClient{
+		key:     apiKey,
+		secret:  sharedSecret,
+		baseURL: config.BaseURL,
+		authURL: config.AuthURL,
+		http:    &http.Client{Timeout: helpers.LASTFM_REQUEST_TIMEOUT},
+	}
+
+	if client.baseURL == "" {
+		client.baseURL = helpers.LASTFM_BASE_API_URL
+	}
+
+	if client.authURL == "" {
+		client.authURL = helpers.LASTFM_AUTH_URL
+	}
+
+	return &client, nil
+}
+
+// AuthURL returns the page a user grants access on. Last.fm then redirects to
+// callbackURL with a token for GetSession.
+func (l *lastfmClient) AuthURL(callbackURL string) string {
+	params := url.Values{}
+	params.Set("api_key", l.key)
+	params.Set("cb", callbackURL)
+	return l.authURL + "?" + params.Encode()
+}
+
+// GetSession exchanges the token of the web auth flow for a session.
+func (l *lastfmClient) GetSession(token string) (*Session, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+
+	var res struct {
+		Session Session `json:"session"`
+	}
+
+	params := url.Values{}
+	params.Set("token", token)
+	if err := l.call("auth.getSession", params, &res); err != nil {
+		return nil, err
+	}
+
+	if res.Session.Key == "" {
+		return nil, errors.New("lastfm returned no session key")
+	}
+
+	return &res.Session, nil
+}
+
+// UpdateNowPlaying tells Last.fm the user started playing a track.
+func (l *lastfmClient) UpdateNowPlaying(sessionKey string, track Track) error {
+	params := url.Values{}
+	params.Set("sk", sessionKey)
+	track.setParams(params, "")
+
+	return l.call("track.updateNowPlaying", params, nil)
+}
+
+// Scrobble submits up to helpers.LASTFM_SCROBBLE_BATCH_SIZE plays at once.
+func (l *lastfmClient) Scrobble(sessionKey string, scrobbles []Scrobble) (*ScrobbleResult, error) {
+	if len(scrobbles) == 0 || len(scrobbles) > helpers.LASTFM_SCROBBLE_BATCH_SIZE {
+		return nil, errors.New("invalid number of scrobbles")
+	}
+
+	params := url.Values{}
+	params.Set("sk", sessionKey)
+	for i, scrobble := range scrobbles {
+		suffix := "[" + strconv.Itoa(i) + "]"
+		scrobble.setParams(params, suffix)
+		params.Set("timestamp"+suffix, strconv.FormatInt(scrobble.Timestamp, 10))
+	}
+
+	var res struct {
+		Scrobbles struct {
+			Attr struct {
+				Accepted int `json:"accepted"`
+				Ignored  int `json:"ignored"`
+			} `json:"@attr"`
+		} `json:"scrobbles"`
+	}
+
+	if err := l.call("track.scrobble", params, &res); err != nil {
+		return nil, err
+	}
+
+	return &ScrobbleResult{Accepted: res.Scrobbles.Attr.Accepted, Ignored: res.Scrobbles.Attr.Ignored}, nil
+}
+
+// setParams adds the track's parameters, suffixed with the index of a scrobble batch.
+// Unknown optional fields are left out.
+func (t Track) setParams(params url.Values, suffix string) {
+	params.Set("artist"+suffix, t.Artist)
+	params.Set("track"+suffix, t.Track)
+
+	if t.Album != "" {
+		params.Set("album"+suffix, t.Album)
+	}
+	if t.AlbumArtist != "" {
+		params.Set("albumArtist"+suffix, t.AlbumArtist)
+	}
+	if t.TrackNumber > 0 {
+		params.Set("trackNumber"+suffix, strconv.Itoa(t.TrackNumber))
+	}
+	if t.Duration > 0 {
+		params.Set("duration"+suffix, strconv.Itoa(t.Duration))
+	}
+	if t.MBID != "" {
+		params.Set("mbid"+suffix, t.MBID)
+	}
+}
@@ -0,0 +1,122 @@
+package lastfm
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidSession is returned when the user revoked the session key on Last.fm, the
+// account has to be linked again.
+var ErrInvalidSession = errors.New("lastfm session key is invalid")
+
+// Last.fm error codes worth handling apart, see https://www.last.fm/api/errorcodes
+const (
+	errorInvalidSession    = 9
+	errorServiceOffline    = 11
+	errorTemporaryError    = 16
+	errorRateLimitExceeded = 29
+)
+
+// temporaryError is a failed request worth repeating later: a network error, a 5xx
+// answer, or Last.fm being offline or rate limiting.
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *temporaryError) Unwrap() error {
+	return e.err
+}
+
+// IsTemporary reports whether a request failed because Last.fm was unreachable or
+// overloaded, so it can be sent again later.
+func IsTemporary(err error) bool {
+	var temporary *temporaryError
+	return errors.As(err, &temporary)
+}
+
+// call sends a signed API request and decodes the answer into out, when not nil.
+func (l *lastfmClient) call(method string, params url.Values, out any) error {
+	params.Set("method", method)
+	params.Set("api_key", l.key)
+	params.Set("api_sig", l.sign(params))
+	params.Set("format", "json")
+
+	resp, err := l.http.PostForm(l.baseURL, params)
+	if err != nil {
+		// The error holds the URL, not the form, so the keys don't end up in logs
+		return &temporaryError{err: fmt.Errorf("lastfm request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &temporaryError{err: fmt.Errorf("lastfm request failed: %w", err)}
+	}
+
+	// Errors come as {"error": 9, "message": "..."}, with a 200 or 4xx status
+	var apiErr struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != 0 {
+		err := fmt.Errorf("lastfm %s failed: %s (%d)", method, apiErr.Message, apiErr.Error)
+		switch apiErr.Error {
+		case errorInvalidSession:
+			return fmt.Errorf("%w: %s", ErrInvalidSession, apiErr.Message)
+		case errorServiceOffline, errorTemporaryError, errorRateLimitExceeded:
+			return &temporaryError{err: err}
+		}
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return &temporaryError{err: fmt.Errorf("lastfm returned %s", resp.Status)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("lastfm returned %s", resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode lastfm %s response: %w", method, err)
+	}
+
+	return nil
+}
+
+// sign returns the api_sig of a request: the MD5 of its parameters sorted by name and
+// concatenated as name and value, followed by the shared secret.
+func (l *lastfmClient) sign(params url.Values) string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		if name != "format" && name != "callback" && name != "api_sig" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteString(params.Get(name))
+	}
+	b.WriteString(l.secret)
+
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,116 @@
+package lastfm
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newTestClient returns a client whose requests are answered by handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) LastfmInterface {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New("key", "secret", Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return client
+}
+
+// TestScrobble tests that a batch is sent as signed, indexed parameters and that the
+// accepted and ignored counts are read back.
+func TestScrobble(t *testing.T) {
+	var form url.Values
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		fmt.Fprint(w, `{"scrobbles": {"@attr": {"accepted": 1, "ignored": 1}}}`)
+	})
+
+	result, err := client.Scrobble("session", []Scrobble{
+		{Track: Track{Artist: "Queen", Track: "Bohemian Rhapsody", Album: "A Night at the Opera", TrackNumber: 11, Duration: 354}, Timestamp: 1700000000},
+		{Track: Track{Artist: "ABBA", Track: "Waterloo"}, Timestamp: 1700000400},
+	})
+	if err != nil {
+		t.Fatalf("Scrobble failed: %v", err)
+	}
+
+	if result.Accepted != 1 || result.Ignored != 1 {
+		t.Errorf("Expected 1 accepted and 1 ignored, got %+v", result)
+	}
+
+	expected := map[string]string{
+		"method":         "track.scrobble",
+		"sk":             "session",
+		"format":         "json",
+		"artist[0]":      "Queen",
+		"trackNumber[0]": "11",
+		"duration[0]":    "354",
+		"timestamp[1]":   "1700000400",
+		"track[1]":       "Waterloo",
+	}
+	for name, value := range expected {
+		if form.Get(name) != value {
+			t.Errorf("Expected %s=%q, got %q", name, value, form.Get(name))
+		}
+	}
+	if form.Has("album[1]") {
+		t.Errorf("Expected the unknown album to be left out, got %q", form.Get("album[1]"))
+	}
+
+	// The signature covers every parameter but format, sorted by name
+	signed := "album[0]A Night at the Operaapi_keykeyartist[0]Queenartist[1]ABBAduration[0]354" +
+		"methodtrack.scrobblesksessiontimestamp[0]1700000000timestamp[1]1700000400" +
+		"trackNumber[0]11track[0]Bohemian Rhapsodytrack[1]Waterloosecret"
+	sum := md5.Sum([]byte(signed))
+	if sig := hex.EncodeToString(sum[:]); form.Get("api_sig") != sig {
+		t.Errorf("Expected api_sig %s, got %s", sig, form.Get("api_sig"))
+	}
+}
+
+// TestCallErrors tests that Last.fm error codes are told apart: a revoked session,
+// failures worth retrying, and the rest.
+func TestCallErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		invalid   bool
+		temporary bool
+	}{
+		{"invalid session", http.StatusForbidden, `{"error": 9, "message": "Invalid session key"}`, true, false},
+		{"service offline", http.StatusOK, `{"error": 11, "message": "Service Offline"}`, false, true},
+		{"rate limited", http.StatusOK, `{"error": 29, "message": "Rate limit exceeded"}`, false, true},
+		{"server error", http.StatusBadGateway, `<html>Bad Gateway</html>`, false, true},
+		{"invalid parameters", http.StatusBadRequest, `{"error": 6, "message": "Invalid parameters"}`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			err := client.UpdateNowPlaying("session", Track{Artist: "Queen", Track: "Bohemian Rhapsody"})
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if errors.Is(err, ErrInvalidSession) != tt.invalid {
+				t.Errorf("Expected invalid session %v, got %v", tt.invalid, err)
+			}
+			if IsTemporary(err) != tt.temporary {
+				t.Errorf("Expected temporary %v, got %v", tt.temporary, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package listenbrainz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"igloo/cmd/internal/helpers"
+)
+
+type ListenbrainzInterface interface {
+	ValidateToken(token string) (string, error)
+	SubmitListens(token string, listens []Listen) error
+	GetListens(token, username string, maxTs int64, count int) ([]Listen, error)
+}
+
+// Listen is a play of a recording, ListenedAt is the Unix time it started. Artist and
+// Track are required, the MusicBrainz IDs let ListenBrainz link it without guessing.
+type Listen struct {
+	ListenedAt    int64
+	Artist        string
+	Track         string
+	Release       string
+	RecordingMBID string
+	ReleaseMBID   string
+	ArtistMBIDs   []string
+	TrackNumber   int
+	// Duration is in milliseconds
+	Duration int64
+	// SubmissionClient is the program that submitted a listen of the history
+	SubmissionClient string
+}
+
+// Config holds the optional settings of a client, zero values use the defaults.
+type Config struct {
+	// BaseURL replaces helpers.LISTENBRAINZ_BASE_API_URL, e.g. for a self-hosted server
+	// or a local stub in tests
+	BaseURL string
+}
+
+type listenbrainzClient struct {
+	baseURL string
+	http    *http.Client
+	limiter *helpers.RateLimiter
+}
+
+func New(config Config) ListenbrainzInterface {
+	client := listenbrainzClient{
+		baseURL: strings.TrimSuffix(config.BaseURL, "/"),
+		http:    &http.Client{Timeout: helpers.LISTENBRAINZ_REQUEST_TIMEOUT},
+		limiter: helpers.NewRateLimiter(helpers.LISTENBRAINZ_RATE_LIMIT, helpers.LISTENBRAINZ_RATE_BURST),
+	}
+
+	if client.baseURL == "" {
+		client.baseURL = helpers.LISTENBRAINZ_BASE_API_URL
+	}
+
+	return &client
+}
+
+// ValidateToken returns the name of the user a token belongs to.
+func (l *listenbrainzClient) ValidateToken(token string) (string, error) {
+	if token == "" {
+		return "", errors.New("token cannot be empty")
+	}
+
+	var res struct {
+		Valid    bool   `json:"valid"`
+		UserName string `json:"user_name"`
+	}
+
+	if err := l.request(context.Background(), http.MethodGet, "/1/validate-token", token, nil, &res); err != nil {
+		return "", err
+	}
+
+	if !res.Valid || res.UserName == "" {
+		return "", ErrInvalidToken
+	}
+
+	return res.UserName, nil
+}
+
+// SubmitListens submits up to helpers.LISTENBRAINZ_SUBMIT_BATCH_SIZE listens at once.
+func (l *listenbrainzClient) SubmitListens(token string, listens []Listen) error {
+	if len(listens) == 0 || len(listens) > helpers.LISTENBRAINZ_SUBMIT_BATCH_SIZE {
+		return errors.New("invalid number of listens")
+	}
+
+	// A single listen and a batch are submitted as different types
+	body := submission{ListenType: "single"}
+	if len(listens) > 1 {
+		body.ListenType = "import"
+	}
+
+	for _, listen := range listens {
+		body.Payload = append(body.Payload, newListenPayload(listen))
+	}
+
+	return l.request(context.Background(), http.MethodPost, "/1/submit-listens", token, body, nil)
+}
+
+// GetListens returns up to count listens of a user older than maxTs, newest first.
+// A maxTs of 0 starts from the latest listen.
+func (l *listenbrainzClient) GetListens(token, username string, maxTs int64, count int) ([]Listen, error) {
+	if username == "" {
+		return nil, errors.New("username cannot be empty")
+	}
+
+	params := url.Values{}
+	params.Set("count", strconv.Itoa(count))
+	if maxTs > 0 {
+		params.Set("max_ts", strconv.FormatInt(maxTs, 10))
+	}
+
+	var res struct {
+		Payload struct {
+			Listens []listenPayload `json:"listens"`
+		} `json:"payload"`
+	}
+
+	path := "/1/user/" + url.PathEscape(username) + "/listens?" + params.Encode()
+	if err := l.request(context.Background(), http.MethodGet, path, token, nil, &res); err != nil {
+		return nil, err
+	}
+
+	listens := make([]Listen, 0, len(res.Payload.Listens))
+	for _, p := range res.Payload.Listens {
+		listens = append(listens, p.listen())
+	}
+
+	return listens, nil
+}
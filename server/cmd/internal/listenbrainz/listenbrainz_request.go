@@ -0,0 +1,208 @@
+package listenbrainz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"igloo/cmd/internal/helpers"
+)
+
+// ErrInvalidToken is returned when ListenBrainz doesn't know the user token, e.g. after
+// the user reset it. The new token has to be entered again.
+var ErrInvalidToken = errors.New("listenbrainz token is invalid")
+
+// ErrRejected is returned for a request ListenBrainz will never accept. A rejected
+// submission holds an invalid listen.
+var ErrRejected = errors.New("listenbrainz rejected the request")
+
+// temporaryError is a failed request worth repeating later: a network error, a 5xx
+// answer, or ListenBrainz rate limiting.
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *temporaryError) Unwrap() error {
+	return e.err
+}
+
+// IsTemporary reports whether a request failed because ListenBrainz was unreachable or
+// overloaded, so it can be sent again later.
+func IsTemporary(err error) bool {
+	var temporary *temporaryError
+	return errors.As(err, &temporary)
+}
+
+// submission is the body of a submit-listens request.
+type submission struct {
+	ListenType string          `json:"listen_type"`
+	Payload    []listenPayload `json:"payload"`
+}
+
+type listenPayload struct {
+	ListenedAt    int64         `json:"listened_at,omitempty"`
+	TrackMetadata trackMetadata `json:"track_metadata"`
+}
+
+type trackMetadata struct {
+	ArtistName  string `json:"artist_name"`
+	TrackName   string `json:"track_name"`
+	ReleaseName string `json:"release_name,omitempty"`
+	// AdditionalInfo is free-form, other clients fill it with any types
+	AdditionalInfo map[string]any `json:"additional_info,omitempty"`
+	// MbidMapping holds the MusicBrainz IDs ListenBrainz linked a listen to
+	MbidMapping *struct {
+		RecordingMbid string   `json:"recording_mbid"`
+		ReleaseMbid   string   `json:"release_mbid"`
+		ArtistMbids   []string `json:"artist_mbids"`
+	} `json:"mbid_mapping,omitempty"`
+}
+
+// newListenPayload converts a listen for submission, leaving out unknown fields.
+func newListenPayload(l Listen) listenPayload {
+	info := map[string]any{
+		"submission_client": helpers.LISTENBRAINZ_SUBMISSION_CLIENT,
+		"media_player":      helpers.LISTENBRAINZ_SUBMISSION_CLIENT,
+	}
+	if l.RecordingMBID != "" {
+		info["recording_mbid"] = l.RecordingMBID
+	}
+	if l.ReleaseMBID != "" {
+		info["release_mbid"] = l.ReleaseMBID
+	}
+	if len(l.ArtistMBIDs) > 0 {
+		info["artist_mbids"] = l.ArtistMBIDs
+	}
+	if l.TrackNumber > 0 {
+		info["tracknumber"] = l.TrackNumber
+	}
+	if l.Duration > 0 {
+		info["duration_ms"] = l.Duration
+	}
+
+	return listenPayload{
+		ListenedAt: l.ListenedAt,
+		TrackMetadata: trackMetadata{
+			ArtistName:     l.Artist,
+			TrackName:      l.Track,
+			ReleaseName:    l.Release,
+			AdditionalInfo: info,
+		},
+	}
+}
+
+// listen reads a listen of the history. The IDs the submitting client sent win over
+// the ones ListenBrainz linked.
+func (p listenPayload) listen() Listen {
+	meta := p.TrackMetadata
+	info := func(key string) string {
+		value, _ := meta.AdditionalInfo[key].(string)
+		return value
+	}
+
+	l := Listen{
+		ListenedAt:       p.ListenedAt,
+		Artist:           meta.ArtistName,
+		Track:            meta.TrackName,
+		Release:          meta.ReleaseName,
+		RecordingMBID:    info("recording_mbid"),
+		ReleaseMBID:      info("release_mbid"),
+		SubmissionClient: info("submission_client"),
+	}
+
+	if meta.MbidMapping != nil {
+		if l.RecordingMBID == "" {
+			l.RecordingMBID = meta.MbidMapping.RecordingMbid
+		}
+		if l.ReleaseMBID == "" {
+			l.ReleaseMBID = meta.MbidMapping.ReleaseMbid
+		}
+		l.ArtistMBIDs = meta.MbidMapping.ArtistMbids
+	}
+
+	return l
+}
+
+// request sends an API request with the user token and decodes the answer into out,
+// when not nil. Requests wait for the shared limiter, which ListenBrainz pauses
+// through its X-RateLimit headers.
+func (l *listenbrainzClient) request(ctx context.Context, method, path, token string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode listenbrainz request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	if err := l.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, l.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create listenbrainz request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := l.http.Do(req)
+	if err != nil {
+		return &temporaryError{err: fmt.Errorf("listenbrainz request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	// The headers tell how long until requests are allowed again once none are left
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.StatusCode == http.StatusTooManyRequests {
+		if delay := helpers.ParseRetryAfter(resp.Header.Get("X-RateLimit-Reset-In")); delay > 0 {
+			l.limiter.Pause(delay)
+		}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &temporaryError{err: fmt.Errorf("listenbrainz request failed: %w", err)}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// Errors come as {"code": 400, "error": "..."}
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.Unmarshal(data, &apiErr)
+		if apiErr.Error == "" {
+			apiErr.Error = resp.Status
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized:
+			return fmt.Errorf("%w: %s", ErrInvalidToken, apiErr.Error)
+		case resp.StatusCode == http.StatusBadRequest:
+			return fmt.Errorf("%w: %s", ErrRejected, apiErr.Error)
+		case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests:
+			return &temporaryError{err: fmt.Errorf("listenbrainz returned %s: %s", resp.Status, apiErr.Error)}
+		}
+		return fmt.Errorf("listenbrainz returned %s: %s", resp.Status, apiErr.Error)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode listenbrainz response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package listenbrainz
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestClient returns a client whose requests are answered by handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) ListenbrainzInterface {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return New(Config{BaseURL: server.URL})
+}
+
+// TestSubmitListens tests that listens are sent with the user token, as the type
+// matching their number, and carry their MusicBrainz IDs and igloo as the client.
+func TestSubmitListens(t *testing.T) {
+	var auth string
+	var body struct {
+		ListenType string `json:"listen_type"`
+		Payload    []struct {
+			ListenedAt    int64 `json:"listened_at"`
+			TrackMetadata struct {
+				ArtistName     string         `json:"artist_name"`
+				TrackName      string         `json:"track_name"`
+				ReleaseName    string         `json:"release_name"`
+				AdditionalInfo map[string]any `json:"additional_info"`
+			} `json:"track_metadata"`
+		} `json:"payload"`
+	}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/1/submit-listens" {
+			t.Errorf("Expected POST /1/submit-listens, got %s %s", r.Method, r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"status": "ok"}`)
+	})
+
+	err := client.SubmitListens("token", []Listen{
+		{
+			ListenedAt:    1700000000,
+			Artist:        "Queen",
+			Track:         "Bohemian Rhapsody",
+			Release:       "A Night at the Opera",
+			RecordingMBID: "b1a9c0e9-d987-4042-ae91-78d6a3267d69",
+			ArtistMBIDs:   []string{"0383dadf-2a4e-4d10-a46a-e9e041da8eb3"},
+			TrackNumber:   11,
+			Duration:      354000,
+		},
+		{ListenedAt: 1700000400, Artist: "ABBA", Track: "Waterloo"},
+	})
+	if err != nil {
+		t.Fatalf("SubmitListens failed: %v", err)
+	}
+
+	if auth != "Token token" {
+		t.Errorf("Expected the user token to be sent, got %q", auth)
+	}
+	if body.ListenType != "import" || len(body.Payload) != 2 {
+		t.Fatalf("Expected an import of 2 listens, got %q with %d", body.ListenType, len(body.Payload))
+	}
+
+	first := body.Payload[0]
+	if first.ListenedAt != 1700000000 || first.TrackMetadata.ReleaseName != "A Night at the Opera" {
+		t.Errorf("Expected the listen of A Night at the Opera at 1700000000, got %+v", first)
+	}
+
+	info := first.TrackMetadata.AdditionalInfo
+	expected := map[string]any{
+		"recording_mbid":    "b1a9c0e9-d987-4042-ae91-78d6a3267d69",
+		"submission_client": "igloo",
+		"tracknumber":       float64(11),
+		"duration_ms":       float64(354000),
+	}
+	for key, value := range expected {
+		if info[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, info[key])
+		}
+	}
+	if artists, _ := info["artist_mbids"].([]any); len(artists) != 1 {
+		t.Errorf("Expected one artist MBID, got %v", info["artist_mbids"])
+	}
+	if _, ok := body.Payload[1].TrackMetadata.AdditionalInfo["release_mbid"]; ok {
+		t.Error("Expected the unknown release MBID to be left out")
+	}
+}
+
+// TestGetListens tests that the history is paged with max_ts and that the IDs linked
+// by ListenBrainz fill in the ones the client didn't send.
+func TestGetListens(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/user/freddie/listens" || r.URL.Query().Get("max_ts") != "1700000500" || r.URL.Query().Get("count") != "2" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		fmt.Fprint(w, `{"payload": {"count": 2, "listens": [
+			{"listened_at": 1700000400, "track_metadata": {"artist_name": "ABBA", "track_name": "Waterloo",
+				"additional_info": {"submission_client": "igloo", "recording_mbid": "sent", "tracknumber": 1},
+				"mbid_mapping": {"recording_mbid": "linked", "release_mbid": "release", "artist_mbids": ["abba"]}}},
+			{"listened_at": 1700000000, "track_metadata": {"artist_name": "Queen", "track_name": "Bohemian Rhapsody",
+				"mbid_mapping": {"recording_mbid": "linked", "artist_mbids": ["queen"]}}}
+		]}}`)
+	})
+
+	listens, err := client.GetListens("token", "freddie", 1700000500, 2)
+	if err != nil {
+		t.Fatalf("GetListens failed: %v", err)
+	}
+	if len(listens) != 2 {
+		t.Fatalf("Expected 2 listens, got %d", len(listens))
+	}
+
+	if l := listens[0]; l.RecordingMBID != "sent" || l.ReleaseMBID != "release" || l.SubmissionClient != "igloo" || l.ListenedAt != 1700000400 {
+		t.Errorf("Expected the sent recording MBID and the linked release MBID, got %+v", l)
+	}
+	if l := listens[1]; l.RecordingMBID != "linked" || l.Artist != "Queen" || len(l.ArtistMBIDs) != 1 {
+		t.Errorf("Expected the linked MBIDs of Queen, got %+v", l)
+	}
+}
+
+// TestRequestErrors tests that error answers are told apart: an unknown token, a
+// rejected listen, failures worth retrying, and the rest.
+func TestRequestErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		invalid   bool
+		rejected  bool
+		temporary bool
+	}{
+		{"invalid token", http.StatusUnauthorized, `{"code": 401, "error": "Invalid authorization token."}`, true, false, false},
+		{"invalid listen", http.StatusBadRequest, `{"code": 400, "error": "JSON document may not contain '\\u0000'"}`, false, true, false},
+		{"rate limited", http.StatusTooManyRequests, `{"code": 429, "error": "Too many requests"}`, false, false, true},
+		{"server error", http.StatusServiceUnavailable, `<html>Service Unavailable</html>`, false, false, true},
+		{"not found", http.StatusNotFound, `{"code": 404, "error": "Not found"}`, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			err := client.SubmitListens("token", []Listen{{ListenedAt: 1700000000, Artist: "Queen", Track: "Bohemian Rhapsody"}})
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if errors.Is(err, ErrInvalidToken) != tt.invalid {
+				t.Errorf("Expected invalid token %v, got %v", tt.invalid, err)
+			}
+			if errors.Is(err, ErrRejected) != tt.rejected {
+				t.Errorf("Expected rejected %v, got %v", tt.rejected, err)
+			}
+			if IsTemporary(err) != tt.temporary {
+				t.Errorf("Expected temporary %v, got %v", tt.temporary, err)
+			}
+		})
+	}
+}
+
+// TestValidateToken tests that a token ListenBrainz answers as invalid is reported so.
+func TestValidateToken(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Token good" {
+			fmt.Fprint(w, `{"code": 200, "valid": true, "user_name": "freddie"}`)
+			return
+		}
+		fmt.Fprint(w, `{"code": 200, "valid": false, "message": "Token invalid."}`)
+	})
+
+	if username, err := client.ValidateToken("good"); err != nil || username != "freddie" {
+		t.Errorf("Expected freddie, got %q and %v", username, err)
+	}
+	if _, err := client.ValidateToken("bad"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
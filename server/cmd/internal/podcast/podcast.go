@@ -0,0 +1,161 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"igloo/cmd/internal/helpers"
+	"io"
+	"net/http"
+)
+
+// ErrTooLarge is returned for feeds over helpers.PODCAST_FEED_MAX_SIZE and episodes
+// over helpers.PODCAST_EPISODE_MAX_SIZE.
+var ErrTooLarge = errors.New("response is too large")
+
+type PodcastInterface interface {
+	FetchFeed(ctx context.Context, feedURL, etag, lastModified string) (*FeedResponse, error)
+	Download(ctx context.Context, url string, w io.Writer) (int64, error)
+}
+
+// FeedResponse is the result of a conditional feed request. When the server answers
+// 304 Not Modified, NotModified is set and Feed is nil.
+type FeedResponse struct {
+	Feed         *Feed
+	NotModified  bool
+	ETag         string
+	LastModified string
+}
+
+type podcastClient struct {
+	client *http.Client
+}
+
+func New() PodcastInterface {
+	// no overall timeout, episode downloads can take a while; feed requests get their
+	// own deadline in FetchFeed
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = helpers.PODCAST_FEED_TIMEOUT
+
+	return &podcastClient{
+		client: &http.Client{Transport: transport},
+	}
+}
+
+// FetchFeed downloads and parses the feed. The etag and lastModified validators of
+// the previous fetch are sent back, so unchanged feeds cost a 304 instead of a download.
+func (c *podcastClient) FetchFeed(ctx context.Context, feedURL, etag, lastModified string) (*FeedResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, helpers.PODCAST_FEED_TIMEOUT)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/rss+xml, application/xml;q=0.9, text/xml;q=0.8, */*;q=0.5")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		// servers may omit the validators on a 304, the previous ones still apply
+		return &FeedResponse{
+			NotModified:  true,
+			ETag:         firstNonEmpty(resp.Header.Get("ETag"), etag),
+			LastModified: firstNonEmpty(resp.Header.Get("Last-Modified"), lastModified),
+		}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed request failed: %s", resp.Status)
+	}
+
+	body, err := readLimited(resp, helpers.PODCAST_FEED_MAX_SIZE)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := ParseFeed(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return &FeedResponse{
+		Feed:         feed,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// Download writes the file at url to w and returns the number of bytes written.
+// Files over helpers.PODCAST_EPISODE_MAX_SIZE fail with ErrTooLarge, possibly after
+// part of them was written.
+func (c *podcastClient) Download(ctx context.Context, url string, w io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	if resp.ContentLength > helpers.PODCAST_EPISODE_MAX_SIZE {
+		return 0, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	// one byte over the limit tells a file of exactly the limit from a larger one
+	n, err := io.Copy(w, io.LimitReader(resp.Body, helpers.PODCAST_EPISODE_MAX_SIZE+1))
+	if err != nil {
+		return n, err
+	}
+	if n > helpers.PODCAST_EPISODE_MAX_SIZE {
+		return n, fmt.Errorf("%w: over %d bytes", ErrTooLarge, int64(helpers.PODCAST_EPISODE_MAX_SIZE))
+	}
+
+	return n, nil
+}
+
+// readLimited reads a response body of at most limit bytes, failing with ErrTooLarge
+// rather than cutting a larger body short.
+func readLimited(resp *http.Response, limit int64) ([]byte, error) {
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrTooLarge, limit)
+	}
+
+	return body, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,244 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"igloo/cmd/internal/helpers"
+)
+
+const (
+	itunesNamespace  = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+	contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+)
+
+// ErrNotAFeed is returned by ParseFeed for documents that aren't RSS feeds.
+var ErrNotAFeed = errors.New("document is not an RSS feed")
+
+// ErrUnsupportedEncoding is returned by ParseFeed for feeds not encoded in UTF-8.
+var ErrUnsupportedEncoding = errors.New("unsupported feed encoding")
+
+// Feed is a parsed podcast RSS feed.
+type Feed struct {
+	Title       string
+	Description string
+	Author      string
+	ImageURL    string
+	Link        string
+	Language    string
+	Episodes    []Episode
+}
+
+// Episode is a feed item with an audio enclosure. PublishedAt is UTC
+// "YYYY-MM-DD HH:MM:SS", empty when the item has no readable date, and Duration is
+// in milliseconds.
+type Episode struct {
+	GUID          string
+	Title         string
+	Description   string
+	PublishedAt   string
+	Duration      int64
+	EnclosureURL  string
+	EnclosureType string
+	EnclosureSize int64
+}
+
+// element is any child element the structs below don't name. Feeds mix plain RSS
+// elements with namespaced ones of the same local name (title and itunes:title), which
+// encoding/xml can't tell apart in struct tags, so they're matched by namespace here.
+type element struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+	Href    string `xml:"href,attr"`
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Images   []rssImage `xml:"image"`
+	Items    []rssItem  `xml:"item"`
+	Elements []element  `xml:",any"`
+}
+
+// rssImage is either the RSS <image> with its <url>, or <itunes:image href="">.
+type rssImage struct {
+	XMLName xml.Name
+	URL     string `xml:"url"`
+	Href    string `xml:"href,attr"`
+}
+
+type rssItem struct {
+	Enclosure *rssEnclosure `xml:"enclosure"`
+	Elements  []element     `xml:",any"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// find returns the trimmed text of the first element with the given namespace and
+// name, "" matching plain RSS elements.
+func find(elements []element, space, local string) string {
+	for _, e := range elements {
+		if e.XMLName.Space == space && e.XMLName.Local == local {
+			if value := strings.TrimSpace(e.Value); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// ParseFeed parses an RSS 2.0 podcast feed. Items without an enclosure aren't
+// episodes and are left out.
+func ParseFeed(r io.Reader) (*Feed, error) {
+	decoder := xml.NewDecoder(r)
+	// UTF-8 is read natively, ASCII is a subset of it; reading any other encoding as
+	// UTF-8 would garble the text, so those feeds are refused
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(strings.TrimSpace(label)) {
+		case "utf8", "us-ascii", "ascii":
+			return input, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, label)
+	}
+	decoder.Strict = false
+
+	var doc rssDocument
+	if err := decoder.Decode(&doc); err != nil {
+		if errors.Is(err, ErrUnsupportedEncoding) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNotAFeed, err)
+	}
+
+	channel := doc.Channel
+	feed := &Feed{
+		Title:       find(channel.Elements, "", "title"),
+		Description: firstNonEmpty(find(channel.Elements, "", "description"), find(channel.Elements, itunesNamespace, "summary")),
+		Author:      firstNonEmpty(find(channel.Elements, itunesNamespace, "author"), find(channel.Elements, "", "managingEditor")),
+		Link:        find(channel.Elements, "", "link"),
+		Language:    find(channel.Elements, "", "language"),
+	}
+
+	// itunes:image is usually the larger artwork, so it wins over the RSS image
+	for _, image := range channel.Images {
+		if image.XMLName.Space == itunesNamespace && strings.TrimSpace(image.Href) != "" {
+			feed.ImageURL = strings.TrimSpace(image.Href)
+			break
+		}
+		if feed.ImageURL == "" {
+			feed.ImageURL = strings.TrimSpace(image.URL)
+		}
+	}
+
+	for _, item := range channel.Items {
+		if item.Enclosure == nil || strings.TrimSpace(item.Enclosure.URL) == "" {
+			continue
+		}
+
+		episode := Episode{
+			Title: firstNonEmpty(find(item.Elements, "", "title"), find(item.Elements, itunesNamespace, "title")),
+			Description: firstNonEmpty(
+				find(item.Elements, "", "description"),
+				find(item.Elements, itunesNamespace, "summary"),
+				find(item.Elements, contentNamespace, "encoded"),
+			),
+			EnclosureURL:  strings.TrimSpace(item.Enclosure.URL),
+			EnclosureType: strings.TrimSpace(item.Enclosure.Type),
+			Duration:      ParseDuration(find(item.Elements, itunesNamespace, "duration")),
+		}
+
+		// the enclosure is the most stable identifier for feeds without guids
+		episode.GUID = firstNonEmpty(find(item.Elements, "", "guid"), episode.EnclosureURL)
+
+		if size, err := strconv.ParseInt(strings.TrimSpace(item.Enclosure.Length), 10, 64); err == nil && size > 0 {
+			episode.EnclosureSize = size
+		}
+
+		if published, ok := ParseDate(find(item.Elements, "", "pubDate")); ok {
+			episode.PublishedAt = helpers.SQLiteTime(published)
+		}
+
+		if episode.Title == "" {
+			episode.Title = episode.GUID
+		}
+
+		feed.Episodes = append(feed.Episodes, episode)
+	}
+
+	if feed.Title == "" {
+		feed.Title = feed.Link
+	}
+
+	return feed, nil
+}
+
+// dateLayouts are the pubDate formats found in feeds: RFC 822 as the spec requires,
+// with and without weekday and seconds, and RFC 3339 from feeds that ignore it.
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseDate parses an RSS pubDate.
+func ParseDate(value string) (time.Time, bool) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// ParseDuration parses an itunes:duration, given as HH:MM:SS, MM:SS or seconds, to
+// milliseconds. Unreadable durations are 0.
+func ParseDuration(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0
+	}
+
+	var seconds float64
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+
+	return int64(seconds * 1000)
+}
@@ -0,0 +1,217 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"igloo/cmd/internal/helpers"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Test Show</title>
+    <itunes:title>Ignored Show Title</itunes:title>
+    <link>https://example.com</link>
+    <description>A show about tests</description>
+    <language>en-us</language>
+    <itunes:author>Jane Host</itunes:author>
+    <image><url>https://example.com/small.jpg</url></image>
+    <itunes:image href="https://example.com/large.jpg"/>
+    <item>
+      <title>Episode 2</title>
+      <itunes:title>Second</itunes:title>
+      <guid isPermaLink="false">ep-2</guid>
+      <pubDate>Tue, 02 Jan 2024 10:00:00 +0100</pubDate>
+      <itunes:duration>1:02:03</itunes:duration>
+      <enclosure url="https://example.com/2.mp3" type="audio/mpeg" length="2048"/>
+      <content:encoded><![CDATA[<p>Long notes</p>]]></content:encoded>
+    </item>
+    <item>
+      <itunes:title>First</itunes:title>
+      <pubDate>Mon, 1 Jan 2024 09:30 GMT</pubDate>
+      <itunes:duration>95</itunes:duration>
+      <enclosure url=" https://example.com/1.mp3 " type="audio/mpeg" length=""/>
+    </item>
+    <item>
+      <title>Announcement without audio</title>
+    </item>
+  </channel>
+</rss>`
+
+func TestParseFeed(t *testing.T) {
+	feed, err := ParseFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatalf("Failed to parse feed: %v", err)
+	}
+
+	if feed.Title != "Test Show" || feed.Author != "Jane Host" || feed.Language != "en-us" || feed.Link != "https://example.com" {
+		t.Errorf("Unexpected feed: %+v", feed)
+	}
+	if feed.ImageURL != "https://example.com/large.jpg" {
+		t.Errorf("Expected the itunes image, got %q", feed.ImageURL)
+	}
+
+	if len(feed.Episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(feed.Episodes))
+	}
+
+	second := feed.Episodes[0]
+	expected := Episode{
+		GUID: "ep-2", Title: "Episode 2", Description: "<p>Long notes</p>", PublishedAt: "2024-01-02 09:00:00",
+		Duration: 3_723_000, EnclosureURL: "https://example.com/2.mp3", EnclosureType: "audio/mpeg", EnclosureSize: 2048,
+	}
+	if second != expected {
+		t.Errorf("Expected %+v, got %+v", expected, second)
+	}
+
+	first := feed.Episodes[1]
+	if first.GUID != "https://example.com/1.mp3" || first.Title != "First" || first.PublishedAt != "2024-01-01 09:30:00" || first.Duration != 95_000 {
+		t.Errorf("Unexpected episode without a guid: %+v", first)
+	}
+}
+
+func TestParseFeed_NotAFeed(t *testing.T) {
+	for _, doc := range []string{"<html><body>Not found</body></html>", "", "not xml at all"} {
+		if _, err := ParseFeed(strings.NewReader(doc)); !errors.Is(err, ErrNotAFeed) {
+			t.Errorf("Expected ErrNotAFeed for %q, got %v", doc, err)
+		}
+	}
+}
+
+func TestParseFeed_Encoding(t *testing.T) {
+	ascii := strings.Replace(testFeed, `encoding="UTF-8"`, `encoding="US-ASCII"`, 1)
+	if _, err := ParseFeed(strings.NewReader(ascii)); err != nil {
+		t.Errorf("Expected an ASCII feed to parse, got %v", err)
+	}
+
+	latin1 := strings.Replace(testFeed, `encoding="UTF-8"`, `encoding="ISO-8859-1"`, 1)
+	if _, err := ParseFeed(strings.NewReader(latin1)); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("Expected ErrUnsupportedEncoding for a Latin-1 feed, got %v", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]int64{
+		"3600":     3_600_000,
+		"59:30":    3_570_000,
+		"01:02:03": 3_723_000,
+		"12.5":     12_500,
+		"":         0,
+		"1:2:3:4":  0,
+		"soon":     0,
+	}
+
+	for value, expected := range tests {
+		if got := ParseDuration(value); got != expected {
+			t.Errorf("ParseDuration(%q) = %d, expected %d", value, got, expected)
+		}
+	}
+}
+
+// TestFetchFeed tests that the validators of a fetch are sent back on the next one,
+// and that an unchanged feed is reported as not modified.
+func TestFetchFeed(t *testing.T) {
+	var requests []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Clone())
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 10:00:00 GMT")
+		w.Write([]byte(testFeed))
+	}))
+	defer server.Close()
+
+	client := New()
+	ctx := context.Background()
+
+	res, err := client.FetchFeed(ctx, server.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to fetch feed: %v", err)
+	}
+	if res.NotModified || res.Feed == nil || len(res.Feed.Episodes) != 2 || res.ETag != `"v1"` {
+		t.Fatalf("Unexpected response: %+v", res)
+	}
+
+	res, err = client.FetchFeed(ctx, server.URL, res.ETag, res.LastModified)
+	if err != nil {
+		t.Fatalf("Failed to fetch feed: %v", err)
+	}
+	if !res.NotModified || res.Feed != nil {
+		t.Errorf("Expected the feed to be unchanged, got %+v", res)
+	}
+	if res.ETag != `"v1"` || res.LastModified != "Mon, 01 Jan 2024 10:00:00 GMT" {
+		t.Errorf("Expected the previous validators to be kept, got %q and %q", res.ETag, res.LastModified)
+	}
+
+	if len(requests) != 2 || requests[0].Get("If-None-Match") != "" || requests[1].Get("If-Modified-Since") != "Mon, 01 Jan 2024 10:00:00 GMT" {
+		t.Errorf("Unexpected request headers: %v", requests)
+	}
+}
+
+func TestFetchFeed_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/large" {
+			w.Write(bytes.Repeat([]byte(" "), helpers.PODCAST_FEED_MAX_SIZE+1))
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	client := New()
+
+	if _, err := client.FetchFeed(context.Background(), server.URL+"/missing", "", ""); err == nil {
+		t.Error("Expected an error for a missing feed")
+	}
+	if _, err := client.FetchFeed(context.Background(), server.URL, "", ""); !errors.Is(err, ErrNotAFeed) {
+		t.Errorf("Expected ErrNotAFeed for an HTML page, got %v", err)
+	}
+	if _, err := client.FetchFeed(context.Background(), server.URL+"/large", "", ""); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge for an oversized feed, got %v", err)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/huge.mp3" {
+			w.Header().Set("Content-Length", strconv.FormatInt(helpers.PODCAST_EPISODE_MAX_SIZE+1, 10))
+			return
+		}
+		if r.URL.Path != "/episode.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("episode audio"))
+	}))
+	defer server.Close()
+
+	client := New()
+
+	var buf bytes.Buffer
+	n, err := client.Download(context.Background(), server.URL+"/episode.mp3", &buf)
+	if err != nil || n != 13 || buf.String() != "episode audio" {
+		t.Errorf("Unexpected download: %d %q (%v)", n, buf.String(), err)
+	}
+
+	if _, err := client.Download(context.Background(), server.URL+"/gone.mp3", &buf); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if _, err := client.Download(context.Background(), server.URL+"/huge.mp3", &buf); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected ErrTooLarge for an oversized file, got %v", err)
+	}
+}
@@ -2,57 +2,78 @@ package spotify
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"net/http"
+	"time"
 
 	"igloo/cmd/internal/helpers"
 
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
+// ErrNotFound is returned when a search has no results.
+var ErrNotFound = errors.New("not found on spotify")
+
 type SpotifyInterface interface {
-	SearchAndGetAlbumDetails(query string) (*spotify.FullAlbum, error)
+	SearchAlbums(title, artist string) ([]spotify.SimpleAlbum, error)
+	GetAlbumByID(id string) (*spotify.FullAlbum, error)
 	SearchArtistByName(artistName string) (*spotify.FullArtist, error)
-	ClearAllCaches()
+	GetArtistByID(id string) (*spotify.FullArtist, error)
 }
 
 // Compile-time check to ensure spotifyClient implements SpotifyInterface
 var _ SpotifyInterface = (*spotifyClient)(nil)
 
+// Cache stores search results across scans and restarts, searches without results
+// included.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// Config holds the optional settings of a client, zero values use the defaults.
+type Config struct {
+	// Cache keeps search results for their TTL, nil disables caching
+	Cache Cache
+	// Limiter spaces requests, a new one allowing SPOTIFY_RATE_LIMIT per second when nil
+	Limiter *helpers.RateLimiter
+}
+
 type spotifyClient struct {
-	client      *spotify.Client
-	artistCache map[string]*spotify.FullArtist
-	artistKeys  []string
-	artistMu    sync.RWMutex
-	albumCache  map[string]*spotify.FullAlbum
-	albumKeys   []string
-	albumMu     sync.RWMutex
+	client *spotify.Client
+	cache  Cache
 }
 
-func New(clientID, clientSecret string) (SpotifyInterface, error) {
-	ctx := context.Background()
+func New(clientID, clientSecret string, config Config) (SpotifyInterface, error) {
+	limiter := config.Limiter
+	if limiter == nil {
+		limiter = helpers.NewRateLimiter(helpers.SPOTIFY_RATE_LIMIT, helpers.SPOTIFY_RATE_BURST)
+	}
+
+	// The OAuth client sends API requests through the rate limited one
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: &rateLimitedTransport{base: http.DefaultTransport, limiter: limiter},
+	})
 
-	config := &clientcredentials.Config{
+	credentials := &clientcredentials.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
 		TokenURL:     spotifyauth.TokenURL,
 	}
 
-	token, err := config.Token(ctx)
+	token, err := credentials.Token(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
 
 	httpClient := spotifyauth.New().Client(ctx, token)
-	client := spotify.New(httpClient)
 
 	return &spotifyClient{
-		client:      client,
-		artistCache: make(map[string]*spotify.FullArtist),
-		artistKeys:  make([]string, 0, helpers.SPOTIFY_ARTIST_MAX_CACHE),
-		albumCache:  make(map[string]*spotify.FullAlbum),
-		albumKeys:   make([]string, 0, helpers.SPOTIFY_ALBUM_MAX_CACHE),
+		client: spotify.New(httpClient),
+		cache:  config.Cache,
 	}, nil
 }
@@ -4,39 +4,66 @@ import (
 	"context"
 	"fmt"
 
+	"igloo/cmd/internal/helpers"
+
 	"github.com/zmb3/spotify/v2"
 )
 
-func (s *spotifyClient) SearchAndGetAlbumDetails(query string) (*spotify.FullAlbum, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+// SearchAlbums returns the albums matching title by artist, best first. Without an
+// artist only the title is searched for.
+func (s *spotifyClient) SearchAlbums(title, artist string) ([]spotify.SimpleAlbum, error) {
+	if title == "" {
+		return nil, fmt.Errorf("album title cannot be empty")
 	}
 
-	// Check cache first
-	if cached, exists := s.getAlbum(query); exists {
-		return cached, nil
+	query := fmt.Sprintf("album:%q", title)
+	if artist != "" {
+		query += fmt.Sprintf(" artist:%q", artist)
 	}
 
-	ctx := context.Background()
+	// Check cache first, searches without results included
+	cacheKey := "albums:" + query
+	if cached, exists := getCached[[]spotify.SimpleAlbum](s.cache, cacheKey); exists {
+		if cached == nil {
+			return nil, fmt.Errorf("no albums found for query '%s': %w", query, ErrNotFound)
+		}
+		return *cached, nil
+	}
 
-	results, err := s.client.Search(ctx, query, spotify.SearchTypeAlbum, spotify.Limit(1))
+	results, err := s.client.Search(context.Background(), query, spotify.SearchTypeAlbum, spotify.Limit(helpers.SPOTIFY_ALBUM_SEARCH_LIMIT))
 	if err != nil {
 		return nil, fmt.Errorf("failed to search albums for query '%s': %w", query, err)
 	}
 
 	if len(results.Albums.Albums) == 0 {
-		return nil, fmt.Errorf("no albums found for query '%s'", query)
+		setCached[[]spotify.SimpleAlbum](s.cache, cacheKey, nil)
+		return nil, fmt.Errorf("no albums found for query '%s': %w", query, ErrNotFound)
 	}
 
-	albumID := results.Albums.Albums[0].ID.String()
+	albums := results.Albums.Albums
+
+	setCached(s.cache, cacheKey, &albums)
+
+	return albums, nil
+}
+
+// GetAlbumByID fetches the full details of an album by Spotify id.
+func (s *spotifyClient) GetAlbumByID(id string) (*spotify.FullAlbum, error) {
+	if id == "" {
+		return nil, fmt.Errorf("album id cannot be empty")
+	}
+
+	cacheKey := "album:" + id
+	if cached, exists := getCached[spotify.FullAlbum](s.cache, cacheKey); exists && cached != nil {
+		return cached, nil
+	}
 
-	album, err := s.client.GetAlbum(ctx, spotify.ID(albumID))
+	album, err := s.client.GetAlbum(context.Background(), spotify.ID(id))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get album details for ID %s: %w", albumID, err)
+		return nil, fmt.Errorf("failed to get album details for ID %s: %w", id, err)
 	}
 
-	// Store in cache
-	s.setAlbum(query, album)
+	setCached(s.cache, cacheKey, album)
 
 	return album, nil
 }
@@ -12,8 +12,12 @@ func (s *spotifyClient) SearchArtistByName(artistName string) (*spotify.FullArti
 		return nil, fmt.Errorf("artist name cannot be empty")
 	}
 
-	// Check cache first
-	if cached, exists := s.getArtist(artistName); exists {
+	// Check cache first, searches without results included
+	cacheKey := "artist:" + artistName
+	if cached, exists := getCached[spotify.FullArtist](s.cache, cacheKey); exists {
+		if cached == nil {
+			return nil, fmt.Errorf("no artists found for name '%s': %w", artistName, ErrNotFound)
+		}
 		return cached, nil
 	}
 
@@ -25,13 +29,28 @@ func (s *spotifyClient) SearchArtistByName(artistName string) (*spotify.FullArti
 	}
 
 	if len(results.Artists.Artists) == 0 {
-		return nil, fmt.Errorf("no artists found for name '%s'", artistName)
+		setCached[spotify.FullArtist](s.cache, cacheKey, nil)
+		return nil, fmt.Errorf("no artists found for name '%s': %w", artistName, ErrNotFound)
 	}
 
 	artist := &results.Artists.Artists[0]
 
-	// Store in cache
-	s.setArtist(artistName, artist)
+	setCached(s.cache, cacheKey, artist)
+
+	return artist, nil
+}
+
+// GetArtistByID fetches an artist by Spotify id. The cache is bypassed since callers
+// want current popularity and follower counts.
+func (s *spotifyClient) GetArtistByID(id string) (*spotify.FullArtist, error) {
+	if id == "" {
+		return nil, fmt.Errorf("artist id cannot be empty")
+	}
+
+	artist, err := s.client.GetArtist(context.Background(), spotify.ID(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artist '%s': %w", id, err)
+	}
 
 	return artist, nil
 }
@@ -1,82 +1,53 @@
 package spotify
 
 import (
-	"igloo/cmd/internal/helpers"
+	"encoding/json"
 
-	"github.com/zmb3/spotify/v2"
+	"igloo/cmd/internal/helpers"
 )
 
-func (c *spotifyClient) getArtist(key string) (*spotify.FullArtist, bool) {
-	c.artistMu.RLock()
-	defer c.artistMu.RUnlock()
-
-	artist, exists := c.artistCache[key]
-	return artist, exists
-}
-
-func (c *spotifyClient) setArtist(key string, artist *spotify.FullArtist) {
-	c.artistMu.Lock()
-	defer c.artistMu.Unlock()
+// notFound is the cached body of a search without results.
+const notFound = "null"
 
-	if _, exists := c.artistCache[key]; exists {
-		c.artistCache[key] = artist
-		return
+// getCached returns the cached result for key. A cached search without results is
+// reported as found with a nil value.
+func getCached[T any](cache Cache, key string) (*T, bool) {
+	if cache == nil {
+		return nil, false
 	}
 
-	if len(c.artistCache) >= helpers.SPOTIFY_ARTIST_MAX_CACHE {
-		oldestKey := c.artistKeys[0]
-		c.artistKeys = c.artistKeys[1:]
-		delete(c.artistCache, oldestKey)
+	body, ok := cache.Get(key)
+	if !ok {
+		return nil, false
 	}
 
-	c.artistCache[key] = artist
-	c.artistKeys = append(c.artistKeys, key)
-}
+	if string(body) == notFound {
+		return nil, true
+	}
 
-func (c *spotifyClient) getAlbum(key string) (*spotify.FullAlbum, bool) {
-	c.albumMu.RLock()
-	defer c.albumMu.RUnlock()
+	var value T
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, false
+	}
 
-	album, exists := c.albumCache[key]
-	return album, exists
+	return &value, true
 }
 
-func (c *spotifyClient) setAlbum(key string, album *spotify.FullAlbum) {
-	c.albumMu.Lock()
-	defer c.albumMu.Unlock()
-
-	if _, exists := c.albumCache[key]; exists {
-		c.albumCache[key] = album
+// setCached caches a search result, or a nil one for a search without results.
+func setCached[T any](cache Cache, key string, value *T) {
+	if cache == nil {
 		return
 	}
 
-	if len(c.albumCache) >= helpers.SPOTIFY_ALBUM_MAX_CACHE {
-		oldestKey := c.albumKeys[0]
-		c.albumKeys = c.albumKeys[1:]
-		delete(c.albumCache, oldestKey)
+	if value == nil {
+		cache.Set(key, []byte(notFound), helpers.SPOTIFY_NOT_FOUND_CACHE_TTL)
+		return
 	}
 
-	c.albumCache[key] = album
-	c.albumKeys = append(c.albumKeys, key)
-}
-
-func (c *spotifyClient) clearArtistCache() {
-	c.artistMu.Lock()
-	defer c.artistMu.Unlock()
-
-	c.artistCache = make(map[string]*spotify.FullArtist)
-	c.artistKeys = make([]string, 0, helpers.SPOTIFY_ARTIST_MAX_CACHE)
-}
-
-func (c *spotifyClient) clearAlbumCache() {
-	c.albumMu.Lock()
-	defer c.albumMu.Unlock()
-
-	c.albumCache = make(map[string]*spotify.FullAlbum)
-	c.albumKeys = make([]string, 0, helpers.SPOTIFY_ALBUM_MAX_CACHE)
-}
+	body, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
 
-func (c *spotifyClient) ClearAllCaches() {
-	c.clearArtistCache()
-	c.clearAlbumCache()
+	cache.Set(key, body, helpers.SPOTIFY_CACHE_TTL)
 }
@@ -0,0 +1,159 @@
+package spotify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/helpers"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// memoryCache records the TTL of every entry.
+type memoryCache struct {
+	bodies map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{bodies: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	body, ok := c.bodies[key]
+	return body, ok
+}
+
+func (c *memoryCache) Set(key string, body []byte, ttl time.Duration) {
+	c.bodies[key] = body
+	c.ttls[key] = ttl
+}
+
+// newTestClient returns a client for a mock Spotify API served by handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc, cache Cache) *spotifyClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	httpClient := &http.Client{Transport: &rateLimitedTransport{
+		base:    http.DefaultTransport,
+		limiter: helpers.NewRateLimiter(1000, 100),
+	}}
+
+	return &spotifyClient{
+		client: spotify.New(httpClient, spotify.WithBaseURL(server.URL+"/")),
+		cache:  cache,
+	}
+}
+
+// TestSearchArtistByName_Cache tests that results and searches without results are
+// cached, the latter for a shorter time.
+func TestSearchArtistByName_Cache(t *testing.T) {
+	var requests atomic.Int32
+	cache := newMemoryCache()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("q") == "Jerry Goldsmith" {
+			w.Write([]byte(`{"artists":{"items":[{"id":"goldsmith","name":"Jerry Goldsmith","popularity":55}]}}`))
+			return
+		}
+		w.Write([]byte(`{"artists":{"items":[]}}`))
+	}, cache)
+
+	for range 2 {
+		artist, err := client.SearchArtistByName("Jerry Goldsmith")
+		if err != nil {
+			t.Fatalf("SearchArtistByName failed: %v", err)
+		}
+		if artist.ID != "goldsmith" || artist.Popularity != 55 {
+			t.Errorf("Unexpected artist: %+v", artist)
+		}
+
+		if _, err := client.SearchArtistByName("Nobody"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	}
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Expected one request per name, got %d", n)
+	}
+
+	if ttl := cache.ttls["artist:Jerry Goldsmith"]; ttl != helpers.SPOTIFY_CACHE_TTL {
+		t.Errorf("Expected the artist to be cached for %s, got %s", helpers.SPOTIFY_CACHE_TTL, ttl)
+	}
+	if ttl := cache.ttls["artist:Nobody"]; ttl != helpers.SPOTIFY_NOT_FOUND_CACHE_TTL {
+		t.Errorf("Expected the missing artist to be cached for %s, got %s", helpers.SPOTIFY_NOT_FOUND_CACHE_TTL, ttl)
+	}
+}
+
+// TestRateLimitedTransport_RetryAfter tests that a 429 is retried after its
+// Retry-After, and given up on when the wait is too long.
+func TestRateLimitedTransport_RetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	retryAfter := "0"
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"id":"goldsmith","name":"Jerry Goldsmith"}`))
+	}, nil)
+
+	if _, err := client.GetArtistByID("goldsmith"); err != nil {
+		t.Fatalf("GetArtistByID failed: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("Expected the request to be retried once, got %d requests", n)
+	}
+
+	requests.Store(0)
+	retryAfter = "3600"
+	if _, err := client.GetArtistByID("goldsmith"); err == nil {
+		t.Error("Expected an error when the Retry-After is too long")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected a single request, got %d", n)
+	}
+}
+
+// TestSearchAlbums tests that albums are searched by title and artist, and that the
+// candidates are cached together.
+func TestSearchAlbums(t *testing.T) {
+	var requests atomic.Int32
+	var query string
+	cache := newMemoryCache()
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		query = r.URL.Query().Get("q")
+		w.Write([]byte(`{"albums":{"items":[
+			{"id":"abba-hits","name":"Greatest Hits","artists":[{"name":"ABBA"}],"total_tracks":14},
+			{"id":"queen-hits","name":"Greatest Hits","artists":[{"name":"Queen"}],"total_tracks":17}
+		]}}`))
+	}, cache)
+
+	for range 2 {
+		albums, err := client.SearchAlbums("Greatest Hits", "Queen")
+		if err != nil {
+			t.Fatalf("SearchAlbums failed: %v", err)
+		}
+		if len(albums) != 2 || albums[1].ID != "queen-hits" || albums[1].TotalTracks != 17 {
+			t.Errorf("Unexpected albums: %+v", albums)
+		}
+	}
+
+	if want := `album:"Greatest Hits" artist:"Queen"`; query != want {
+		t.Errorf("Expected query %q, got %q", want, query)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("Expected the search to be cached, got %d requests", n)
+	}
+}
@@ -0,0 +1,40 @@
+package spotify
+
+import (
+	"net/http"
+
+	"igloo/cmd/internal/helpers"
+)
+
+// rateLimitedTransport spaces requests with a limiter shared by every caller, so the
+// scanner and the metadata refresher stay within Spotify's limit together. A 429
+// answer pauses the limiter for its Retry-After and the request is sent again.
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *helpers.RateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		// Only bodiless requests are repeated, a body is consumed by the first attempt
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || req.Body != nil {
+			return resp, err
+		}
+
+		delay := helpers.ParseRetryAfter(resp.Header.Get("Retry-After"))
+		if delay < 0 {
+			delay = helpers.SPOTIFY_RETRY_DELAY
+		}
+		if attempt >= helpers.SPOTIFY_MAX_RETRIES || delay > helpers.SPOTIFY_MAX_RETRY_DELAY {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		t.limiter.Pause(delay)
+	}
+}
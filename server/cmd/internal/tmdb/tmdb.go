@@ -2,27 +2,81 @@ package tmdb
 
 import (
 	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"igloo/cmd/internal/helpers"
 )
 
 type TmdbInterface interface {
-	GetTmdbMovieByID(movie *TmdbMovie) error
+	GetTmdbMovieByID(movie *TmdbMovie, locale Locale) error
+	RefreshTmdbMovieByID(movie *TmdbMovie, locale Locale) error
 	GetTmdbMovieByTitle(movie *TmdbMovie) error
-	SearchMoviesByTitleAndYear(title string, year ...int) ([]TmdbMovie, error)
-	GetMoviesInTheaters() ([]*TmdbMovie, error)
-	GetTmdbPopularMovies(region ...string) ([]*TmdbMovie, error)
+	SearchMoviesByTitleAndYear(title string, year int, locale Locale) ([]TmdbMovie, error)
+	GetMoviesInTheaters(locale Locale) ([]*TmdbMovie, error)
+	GetTmdbPopularMovies(locale Locale) ([]*TmdbMovie, error)
+	GetTmdbCollection(id int, locale Locale) (*TmdbCollection, error)
+}
+
+// Locale selects the language of titles, overviews and taglines, and the country whose
+// age rating is preferred. Empty fields leave the choice to TMDB, which answers in English.
+type Locale struct {
+	// Language is an ISO 639-1 code with an optional region, e.g. es or es-ES
+	Language string
+	// FallbackLanguage fills the texts TMDB has no translation for in Language
+	FallbackLanguage string
+	// Country is the ISO 3166-1 code Certification looks for first, e.g. ES
+	Country string
+}
+
+// Cache stores raw TMDB responses across requests and restarts. Keys are the request
+// path and query without the API key.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// Config holds the optional settings of a client, zero values use the defaults.
+type Config struct {
+	// BaseURL replaces helpers.TMDB_BASE_API_URL, e.g. to point tests at a mock server
+	BaseURL string
+	// Cache keeps responses for their TTL, nil disables caching
+	Cache Cache
+	// Limiter spaces requests, a new one allowing TMDB_RATE_LIMIT per second when nil
+	Limiter *helpers.RateLimiter
 }
 
 type tmdbClient struct {
-	key string
+	key     string
+	baseURL string
+	http    *http.Client
+	cache   Cache
+	limiter *helpers.RateLimiter
+	// retryBase is the backoff before the first retry, doubled on each one
+	retryBase time.Duration
 }
 
-func New(apiKey string) (TmdbInterface, error) {
+func New(apiKey string, config Config) (TmdbInterface, error) {
 	if apiKey == "" {
 		return nil, errors.New("TMDB_API_KEY environment variable is not set")
 	}
 
 	client := tmdbClient{
-		key: apiKey,
+		key:       apiKey,
+		baseURL:   strings.TrimSuffix(config.BaseURL, "/"),
+		http:      &http.Client{Timeout: helpers.TMDB_REQUEST_TIMEOUT},
+		cache:     config.Cache,
+		limiter:   config.Limiter,
+		retryBase: helpers.TMDB_RETRY_BASE_DELAY,
+	}
+
+	if client.baseURL == "" {
+		client.baseURL = helpers.TMDB_BASE_API_URL
+	}
+
+	if client.limiter == nil {
+		client.limiter = helpers.NewRateLimiter(helpers.TMDB_RATE_LIMIT, helpers.TMDB_RATE_BURST)
 	}
 
 	return &client, nil
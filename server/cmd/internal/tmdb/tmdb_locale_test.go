@@ -0,0 +1,137 @@
+package tmdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestGetTmdbMovieByID_Locale tests that the locale's language is requested and that
+// a title or overview without a translation is taken from the fallback language,
+// while a tagline isn't.
+func TestGetTmdbMovieByID_Locale(t *testing.T) {
+	var queries []string
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		queries = append(queries, query.Get("language")+"|"+query.Get("include_video_language"))
+
+		movie := map[string]any{"id": 348, "original_title": "Alien"}
+		switch query.Get("language") {
+		case "es-ES":
+			movie["title"] = "Alien, el octavo pasajero"
+		case "es-MX":
+			movie["title"] = "Alien, el octavo pasajero"
+			movie["overview"] = "La tripulación del Nostromo..."
+		case "en-US":
+			movie["title"] = "Alien"
+			movie["overview"] = "The crew of the Nostromo..."
+			movie["tagline"] = "In space no one can hear you scream."
+		}
+		json.NewEncoder(w).Encode(movie)
+	}, nil)
+
+	movie := &TmdbMovie{TmdbID: 348}
+	if err := client.GetTmdbMovieByID(movie, Locale{Language: "es-ES", FallbackLanguage: "en-US", Country: "ES"}); err != nil {
+		t.Fatalf("GetTmdbMovieByID failed: %v", err)
+	}
+
+	if movie.Title != "Alien, el octavo pasajero" || movie.OriginalTitle != "Alien" {
+		t.Errorf("Expected the Spanish title and the original one, got %q and %q", movie.Title, movie.OriginalTitle)
+	}
+	if movie.Overview != "The crew of the Nostromo..." {
+		t.Errorf("Expected the untranslated overview in English, got %q", movie.Overview)
+	}
+	if movie.Tagline != "" {
+		t.Errorf("Expected no tagline in another language, got %q", movie.Tagline)
+	}
+
+	expected := []string{"es-ES|es,en,null", "en-US|"}
+	if len(queries) != len(expected) || queries[0] != expected[0] || queries[1] != expected[1] {
+		t.Errorf("Expected requests %v, got %v", expected, queries)
+	}
+
+	// A translated title and overview need no fallback request, even without a tagline
+	queries = nil
+	movie = &TmdbMovie{TmdbID: 348}
+	if err := client.GetTmdbMovieByID(movie, Locale{Language: "es-MX", FallbackLanguage: "en-US"}); err != nil {
+		t.Fatalf("GetTmdbMovieByID failed: %v", err)
+	}
+	if len(queries) != 1 || movie.Tagline != "" {
+		t.Errorf("Expected a single request and no tagline, got %v and %q", queries, movie.Tagline)
+	}
+}
+
+func TestSearchMoviesByTitleAndYear_Locale(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("language") != "es-ES" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"results":[{"id":348,"title":"Alien, el octavo pasajero","original_title":"Alien","release_date":"1979-05-25"}]}`))
+	}, nil)
+
+	movies, err := client.SearchMoviesByTitleAndYear("Alien", 1979, Locale{Language: "es-ES"})
+	if err != nil {
+		t.Fatalf("SearchMoviesByTitleAndYear failed: %v", err)
+	}
+
+	if len(movies) != 1 || movies[0].Title != "Alien, el octavo pasajero" {
+		t.Errorf("Expected the Spanish title, got %+v", movies)
+	}
+}
+
+func TestGetMoviesInTheaters_Locale(t *testing.T) {
+	var queries []string
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		queries = append(queries, query.Get("language")+"|"+query.Get("region"))
+		w.Write([]byte(`{"results":[{"id":348,"title":"Alien, el octavo pasajero"}]}`))
+	}, nil)
+
+	if _, err := client.GetMoviesInTheaters(Locale{Language: "es-ES", Country: "es"}); err != nil {
+		t.Fatalf("GetMoviesInTheaters failed: %v", err)
+	}
+	if _, err := client.GetTmdbPopularMovies(Locale{}); err != nil {
+		t.Fatalf("GetTmdbPopularMovies failed: %v", err)
+	}
+
+	expected := []string{"es-ES|ES", "en-US|US"}
+	if len(queries) != len(expected) || queries[0] != expected[0] || queries[1] != expected[1] {
+		t.Errorf("Expected requests %v, got %v", expected, queries)
+	}
+}
+
+func TestCertification(t *testing.T) {
+	var movie TmdbMovie
+	data := `{"release_dates":{"results":[
+		{"iso_3166_1":"DE","release_dates":[{"certification":"16"}]},
+		{"iso_3166_1":"ES","release_dates":[{"certification":""},{"certification":"18"}]},
+		{"iso_3166_1":"US","release_dates":[{"certification":"R"}]}
+	]}}`
+	if err := json.Unmarshal([]byte(data), &movie); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	tests := []struct {
+		country  string
+		expected string
+	}{
+		{"ES", "18"},
+		{"es", "18"},
+		{"FR", "R"},
+		{"", "R"},
+	}
+
+	for _, tt := range tests {
+		if got := movie.Certification(tt.country); got != tt.expected {
+			t.Errorf("Certification(%q) = %q, expected %q", tt.country, got, tt.expected)
+		}
+	}
+
+	movie.ReleaseDates.Results = movie.ReleaseDates.Results[:1]
+	if got := movie.Certification("ES"); got != "16" {
+		t.Errorf("Expected the only certification, got %q", got)
+	}
+}
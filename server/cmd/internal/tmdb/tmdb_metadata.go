@@ -1,17 +1,21 @@
 package tmdb
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"igloo/cmd/internal/helpers"
-	"io"
-	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// ErrNoMoviesFound is returned by SearchMoviesByTitleAndYear when the search has no results.
+var ErrNoMoviesFound = errors.New("no movies found with the given query")
+
 // TmdbVideoResult is a single video (trailer, featurette, etc.) from TMDB videos.results.
 type TmdbVideoResult struct {
 	ID       string `json:"id"`
@@ -22,6 +26,17 @@ type TmdbVideoResult struct {
 	Official bool   `json:"official"`
 }
 
+// TmdbCollection is a franchise like the Star Wars saga. Movies only reference theirs
+// by id, name and images; Overview and Parts, its movies, come with GetTmdbCollection.
+type TmdbCollection struct {
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Overview     string      `json:"overview"`
+	PosterPath   string      `json:"poster_path"`
+	BackdropPath string      `json:"backdrop_path"`
+	Parts        []TmdbMovie `json:"parts"`
+}
+
 type TmdbMovie struct {
 	TmdbID              int     `json:"id"`
 	Title               string  `json:"title"`
@@ -50,7 +65,8 @@ type TmdbMovie struct {
 		Name          string `json:"name"`
 		OriginCountry string `json:"origin_country"`
 	} `json:"production_companies"`
-	Genres []struct {
+	BelongsToCollection *TmdbCollection `json:"belongs_to_collection"`
+	Genres              []struct {
 		ID   int    `json:"id"`
 		Name string `json:"name"`
 	} `json:"genres"`
@@ -84,9 +100,11 @@ type TmdbMovie struct {
 }
 
 // Certification returns the parental rating (e.g. PG-13, R) from TMDB release_dates.
-// Prefers US certification; otherwise returns the first non-empty certification from any country.
-func (m *TmdbMovie) Certification() string {
-	var usCert, firstCert string
+// Prefers the certification of country, then the US one; otherwise returns the first
+// non-empty certification from any country.
+func (m *TmdbMovie) Certification(country string) string {
+	certs := make(map[string]string)
+	var firstCert string
 	for _, r := range m.ReleaseDates.Results {
 		for _, rd := range r.ReleaseDates {
 			c := strings.TrimSpace(rd.Certification)
@@ -96,295 +114,259 @@ func (m *TmdbMovie) Certification() string {
 			if firstCert == "" {
 				firstCert = c
 			}
-			if r.ISO3166_1 == "US" {
-				usCert = c
-				break
+			if _, ok := certs[r.ISO3166_1]; !ok {
+				certs[r.ISO3166_1] = c
 			}
 		}
-		if usCert != "" {
-			break
-		}
 	}
-	if usCert != "" {
-		return usCert
+
+	for _, code := range []string{strings.ToUpper(country), "US"} {
+		if cert := certs[code]; cert != "" {
+			return cert
+		}
 	}
 	return firstCert
 }
 
-func (t *tmdbClient) GetTmdbMovieByID(movie *TmdbMovie) error {
+// GetTmdbMovieByID fetches a movie with its credits, videos and release dates in the
+// locale's language. A title or overview without a translation is taken from the
+// fallback language.
+func (t *tmdbClient) GetTmdbMovieByID(movie *TmdbMovie, locale Locale) error {
+	return t.getMovie(movie, locale, t.get)
+}
+
+// RefreshTmdbMovieByID is GetTmdbMovieByID bypassing the cache, so metadata refreshes
+// and manual matches see changes made on TMDB since the movie was cached.
+func (t *tmdbClient) RefreshTmdbMovieByID(movie *TmdbMovie, locale Locale) error {
+	return t.getMovie(movie, locale, t.getFresh)
+}
+
+func (t *tmdbClient) getMovie(movie *TmdbMovie, locale Locale, get func(string, url.Values, time.Duration) ([]byte, error)) error {
 	if movie.TmdbID == 0 {
 		return errors.New("tmdb id is required")
 	}
 
 	params := url.Values{}
-	params.Add("api_key", t.key)
 	params.Add("append_to_response", "credits,videos,release_dates")
 
-	requestURL := fmt.Sprintf("%s/movie/%d?%s", helpers.TMDB_BASE_API_URL, movie.TmdbID, params.Encode())
-
-	req, err := http.NewRequest("GET", requestURL, nil)
-	if err != nil {
-		return err
+	if locale.Language != "" {
+		params.Add("language", locale.Language)
+		// Videos are filtered by language too, and most trailers are only in English
+		params.Add("include_video_language", videoLanguages(locale))
 	}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	body, err := get(fmt.Sprintf("/movie/%d", movie.TmdbID), params, helpers.TMDB_CACHE_TTL)
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to get movie from tmdb: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return errors.New("rate limit exceeded for tmdb")
-		}
-		return errors.New("unable to get movie from tmdb")
+	if err := json.Unmarshal(body, movie); err != nil {
+		return err
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	if locale.FallbackLanguage == "" || strings.EqualFold(locale.FallbackLanguage, locale.Language) {
+		return nil
 	}
 
-	err = json.Unmarshal(bodyBytes, movie)
-	if err != nil {
-		return err
+	// Many movies have no tagline in any language, and one in another language reads
+	// oddly next to translated texts, so taglines are left as they are
+	if movie.Title == "" || movie.Overview == "" {
+		t.fillUntranslated(movie, locale.FallbackLanguage, get)
 	}
 
 	return nil
 }
 
-func (t *tmdbClient) GetTmdbMovieByTitle(movie *TmdbMovie) error {
-	if movie.Title == "" {
-		return errors.New("movie title is required")
-	}
-
+// fillUntranslated fills the title and overview TMDB left empty for lack of a
+// translation with the ones in language. The movie is complete without them, so failures are ignored.
+func (t *tmdbClient) fillUntranslated(movie *TmdbMovie, language string, get func(string, url.Values, time.Duration) ([]byte, error)) {
 	params := url.Values{}
-	params.Add("api_key", t.key)
-	params.Add("query", movie.Title)
-	params.Add("include_adult", "false")
-
-	requestURL := fmt.Sprintf("%s/search/movie?%s", helpers.TMDB_BASE_API_URL, params.Encode())
+	params.Add("language", language)
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	body, err := get(fmt.Sprintf("/movie/%d", movie.TmdbID), params, helpers.TMDB_CACHE_TTL)
 	if err != nil {
-		return err
+		return
 	}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
+	var fallback TmdbMovie
+	if err := json.Unmarshal(body, &fallback); err != nil {
+		return
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+	if movie.Title == "" {
+		movie.Title = fallback.Title
+	}
+	if movie.Overview == "" {
+		movie.Overview = fallback.Overview
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return errors.New("rate limit exceeded for tmdb")
+// videoLanguages returns the include_video_language value for a locale: the languages
+// of the locale without their region, and null for videos without a language.
+func videoLanguages(locale Locale) string {
+	var languages []string
+	for _, tag := range []string{locale.Language, locale.FallbackLanguage} {
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if language != "" && !slices.Contains(languages, language) {
+			languages = append(languages, language)
 		}
-		return errors.New("unable to search movie by title from tmdb")
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
+	return strings.Join(append(languages, "null"), ",")
+}
 
-	var searchResult struct {
-		Results []TmdbMovie `json:"results"`
+func (t *tmdbClient) GetTmdbMovieByTitle(movie *TmdbMovie) error {
+	if movie.Title == "" {
+		return errors.New("movie title is required")
 	}
 
-	err = json.Unmarshal(bodyBytes, &searchResult)
+	params := url.Values{}
+	params.Add("query", movie.Title)
+	params.Add("include_adult", "false")
+
+	results, err := t.getResults("/search/movie", params, helpers.TMDB_SEARCH_CACHE_TTL)
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to search movie by title from tmdb: %w", err)
 	}
 
-	if len(searchResult.Results) == 0 {
+	if len(results) == 0 {
 		return errors.New("no movie found with the given title")
 	}
 
-	*movie = searchResult.Results[0]
+	*movie = results[0]
 	return nil
 }
 
-func (t *tmdbClient) SearchMoviesByTitleAndYear(title string, year ...int) ([]TmdbMovie, error) {
+// SearchMoviesByTitleAndYear searches movies by title, keeping the ones released in
+// year unless it is 0. Titles and overviews are in the locale's language.
+func (t *tmdbClient) SearchMoviesByTitleAndYear(title string, year int, locale Locale) ([]TmdbMovie, error) {
 	if title == "" {
 		return nil, errors.New("movie title is required")
 	}
 
 	params := url.Values{}
-	params.Add("api_key", t.key)
 	params.Add("query", title)
 	params.Add("include_adult", "false")
 
-	if len(year) > 0 && year[0] > 0 {
-		params.Add("year", fmt.Sprintf("%d", year[0]))
+	if locale.Language != "" {
+		params.Add("language", locale.Language)
 	}
 
-	requestURL := fmt.Sprintf("%s/search/movie?%s", helpers.TMDB_BASE_API_URL, params.Encode())
-
-	req, err := http.NewRequest("GET", requestURL, nil)
-	if err != nil {
-		return nil, err
+	if year > 0 {
+		params.Add("year", fmt.Sprintf("%d", year))
 	}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	results, err := t.getResults("/search/movie", params, helpers.TMDB_SEARCH_CACHE_TTL)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to search movies from tmdb: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, errors.New("rate limit exceeded for tmdb")
-		}
-
-		return nil, errors.New("unable to search movies from tmdb")
+	if len(results) == 0 {
+		return nil, ErrNoMoviesFound
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var searchResult struct {
-		Results []TmdbMovie `json:"results"`
-	}
-
-	err = json.Unmarshal(bodyBytes, &searchResult)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(searchResult.Results) == 0 {
-		return nil, errors.New("no movies found with the given query")
-	}
-
-	if len(year) > 0 && year[0] > 0 {
+	if year > 0 {
 		var filteredResults []TmdbMovie
-		for _, movie := range searchResult.Results {
+		for _, movie := range results {
 			if len(movie.ReleaseDate) >= 4 {
 				movieYear, err := strconv.Atoi(movie.ReleaseDate[:4])
-				if err == nil && movieYear == year[0] {
+				if err == nil && movieYear == year {
 					filteredResults = append(filteredResults, movie)
 				}
 			}
 		}
 
 		if len(filteredResults) == 0 {
-			return nil, fmt.Errorf("no movies found with title '%s' from year %d", title, year[0])
+			return nil, fmt.Errorf("%w: title '%s' from year %d", ErrNoMoviesFound, title, year)
 		}
 
 		return filteredResults, nil
 	}
 
-	return searchResult.Results, nil
+	return results, nil
 }
 
-func (t *tmdbClient) GetMoviesInTheaters() ([]*TmdbMovie, error) {
-	params := url.Values{}
-	params.Add("api_key", t.key)
-	params.Add("language", "en-US")
-	params.Add("page", "1")
-	params.Add("region", "US")
-
-	requestURL := fmt.Sprintf("%s/movie/now_playing?%s", helpers.TMDB_BASE_API_URL, params.Encode())
+// GetMoviesInTheaters returns the movies playing in the locale's country, with texts
+// in its language.
+func (t *tmdbClient) GetMoviesInTheaters(locale Locale) ([]*TmdbMovie, error) {
+	params := listParams(locale)
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	results, err := t.getResults("/movie/now_playing", params, helpers.TMDB_LIST_CACHE_TTL)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to get movies in theaters from tmdb: %w", err)
 	}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	if len(results) == 0 {
+		return nil, errors.New("no movies found in theaters")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, errors.New("rate limit exceeded for tmdb")
-		}
-		return nil, errors.New("unable to get movies in theaters from tmdb")
+	movies := make([]*TmdbMovie, len(results))
+	for i, movie := range results {
+		movies[i] = &movie
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return movies, nil
+}
 
-	var response struct {
-		Results []TmdbMovie `json:"results"`
-	}
+// GetTmdbPopularMovies returns the movies popular in the locale's country, with texts
+// in its language.
+func (t *tmdbClient) GetTmdbPopularMovies(locale Locale) ([]*TmdbMovie, error) {
+	params := listParams(locale)
 
-	err = json.Unmarshal(bodyBytes, &response)
+	results, err := t.getResults("/movie/popular", params, helpers.TMDB_LIST_CACHE_TTL)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to get popular movies from tmdb: %w", err)
 	}
 
-	if len(response.Results) == 0 {
-		return nil, errors.New("no movies found in theaters")
+	if len(results) == 0 {
+		return nil, errors.New("no popular movies found")
 	}
 
-	movies := make([]*TmdbMovie, len(response.Results))
-	for i, movie := range response.Results {
+	movies := make([]*TmdbMovie, len(results))
+	for i, movie := range results {
 		movies[i] = &movie
 	}
 
 	return movies, nil
 }
 
-func (t *tmdbClient) GetTmdbPopularMovies(region ...string) ([]*TmdbMovie, error) {
-	params := url.Values{}
-	params.Add("api_key", t.key)
-	params.Add("language", "en-US")
-	params.Add("page", "1")
-
-	regionCode := "US"
-	if len(region) > 0 && region[0] != "" {
-		regionCode = region[0]
+// GetTmdbCollection fetches a collection with its parts in the locale's language.
+func (t *tmdbClient) GetTmdbCollection(id int, locale Locale) (*TmdbCollection, error) {
+	if id == 0 {
+		return nil, errors.New("tmdb collection id is required")
 	}
-	params.Add("region", regionCode)
 
-	requestURL := fmt.Sprintf("%s/movie/popular?%s", helpers.TMDB_BASE_API_URL, params.Encode())
+	params := url.Values{}
+	if locale.Language != "" {
+		params.Add("language", locale.Language)
+	}
 
-	req, err := http.NewRequest("GET", requestURL, nil)
+	body, err := t.get(fmt.Sprintf("/collection/%d", id), params, helpers.TMDB_CACHE_TTL)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to get collection from tmdb: %w", err)
 	}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
+	var collection TmdbCollection
+	if err := json.Unmarshal(body, &collection); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return nil, errors.New("rate limit exceeded for tmdb")
-		}
-		return nil, errors.New("unable to get popular movies from tmdb")
-	}
+	return &collection, nil
+}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+// listParams returns the parameters of the now playing and popular lists in a locale,
+// falling back to the defaults where the locale leaves the language or country empty.
+func listParams(locale Locale) url.Values {
+	params := url.Values{}
+	params.Add("language", cmp.Or(locale.Language, helpers.METADATA_LANGUAGE))
+	params.Add("page", "1")
+	params.Add("region", strings.ToUpper(cmp.Or(locale.Country, helpers.CERTIFICATION_COUNTRY)))
+	return params
+}
+
+// getResults returns the results of a search or list endpoint.
+func (t *tmdbClient) getResults(path string, params url.Values, ttl time.Duration) ([]TmdbMovie, error) {
+	body, err := t.get(path, params, ttl)
 	if err != nil {
 		return nil, err
 	}
@@ -393,19 +375,9 @@ func (t *tmdbClient) GetTmdbPopularMovies(region ...string) ([]*TmdbMovie, error
 		Results []TmdbMovie `json:"results"`
 	}
 
-	err = json.Unmarshal(bodyBytes, &response)
-	if err != nil {
+	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, err
 	}
 
-	if len(response.Results) == 0 {
-		return nil, errors.New("no popular movies found")
-	}
-
-	movies := make([]*TmdbMovie, len(response.Results))
-	for i, movie := range response.Results {
-		movies[i] = &movie
-	}
-
-	return movies, nil
+	return response.Results, nil
 }
@@ -25,14 +25,14 @@ func loadEnv(t *testing.T) string {
 
 func TestNew(t *testing.T) {
 	t.Run("returns error when API key is empty", func(t *testing.T) {
-		_, err := New("")
+		_, err := New("", Config{})
 		if err == nil {
 			t.Error("Expected error when API key is empty")
 		}
 	})
 
 	t.Run("returns client when API key is provided", func(t *testing.T) {
-		client, err := New("test-api-key")
+		client, err := New("test-api-key", Config{})
 		if err != nil {
 			t.Errorf("Unexpected error: %v", err)
 		}
@@ -45,14 +45,14 @@ func TestNew(t *testing.T) {
 func TestGetTmdbMovieByID(t *testing.T) {
 	apiKey := loadEnv(t)
 
-	client, err := New(apiKey)
+	client, err := New(apiKey, Config{})
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
 	t.Run("returns error when TmdbID is zero", func(t *testing.T) {
 		movie := &TmdbMovie{TmdbID: 0}
-		err := client.GetTmdbMovieByID(movie)
+		err := client.GetTmdbMovieByID(movie, Locale{})
 		if err == nil {
 			t.Error("Expected error when TmdbID is zero")
 		}
@@ -62,7 +62,7 @@ func TestGetTmdbMovieByID(t *testing.T) {
 		// The Matrix (1999) - TMDB ID: 603
 		// This is a well-known movie with complete data, ideal for testing field mapping
 		movie := &TmdbMovie{TmdbID: 603}
-		err := client.GetTmdbMovieByID(movie)
+		err := client.GetTmdbMovieByID(movie, Locale{})
 		if err != nil {
 			t.Fatalf("Failed to get movie: %v", err)
 		}
@@ -211,7 +211,7 @@ func TestGetTmdbMovieByID(t *testing.T) {
 
 	t.Run("returns error for non-existent movie ID", func(t *testing.T) {
 		movie := &TmdbMovie{TmdbID: 999999999}
-		err := client.GetTmdbMovieByID(movie)
+		err := client.GetTmdbMovieByID(movie, Locale{})
 		if err == nil {
 			t.Error("Expected error for non-existent movie ID")
 		}
@@ -221,7 +221,7 @@ func TestGetTmdbMovieByID(t *testing.T) {
 func TestGetTmdbMovieByTitle(t *testing.T) {
 	apiKey := loadEnv(t)
 
-	client, err := New(apiKey)
+	client, err := New(apiKey, Config{})
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
@@ -262,20 +262,20 @@ func TestGetTmdbMovieByTitle(t *testing.T) {
 func TestSearchMoviesByTitleAndYear(t *testing.T) {
 	apiKey := loadEnv(t)
 
-	client, err := New(apiKey)
+	client, err := New(apiKey, Config{})
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
 	t.Run("returns error when title is empty", func(t *testing.T) {
-		_, err := client.SearchMoviesByTitleAndYear("")
+		_, err := client.SearchMoviesByTitleAndYear("", 0, Locale{})
 		if err == nil {
 			t.Error("Expected error when title is empty")
 		}
 	})
 
 	t.Run("searches movies by title only", func(t *testing.T) {
-		movies, err := client.SearchMoviesByTitleAndYear("Inception")
+		movies, err := client.SearchMoviesByTitleAndYear("Inception", 0, Locale{})
 		if err != nil {
 			t.Fatalf("Failed to search movies: %v", err)
 		}
@@ -286,7 +286,7 @@ func TestSearchMoviesByTitleAndYear(t *testing.T) {
 	})
 
 	t.Run("searches movies by title and year", func(t *testing.T) {
-		movies, err := client.SearchMoviesByTitleAndYear("The Matrix", 1999)
+		movies, err := client.SearchMoviesByTitleAndYear("The Matrix", 1999, Locale{})
 		if err != nil {
 			t.Fatalf("Failed to search movies: %v", err)
 		}
@@ -310,7 +310,7 @@ func TestSearchMoviesByTitleAndYear(t *testing.T) {
 
 	t.Run("returns error when no movies match year filter", func(t *testing.T) {
 		// Search for a movie with an impossible year
-		_, err := client.SearchMoviesByTitleAndYear("The Matrix", 1850)
+		_, err := client.SearchMoviesByTitleAndYear("The Matrix", 1850, Locale{})
 		if err == nil {
 			t.Error("Expected error when no movies match year filter")
 		}
@@ -320,13 +320,13 @@ func TestSearchMoviesByTitleAndYear(t *testing.T) {
 func TestGetMoviesInTheaters(t *testing.T) {
 	apiKey := loadEnv(t)
 
-	client, err := New(apiKey)
+	client, err := New(apiKey, Config{})
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
 	t.Run("fetches movies currently in theaters", func(t *testing.T) {
-		movies, err := client.GetMoviesInTheaters()
+		movies, err := client.GetMoviesInTheaters(Locale{})
 		if err != nil {
 			t.Fatalf("Failed to get movies in theaters: %v", err)
 		}
@@ -349,13 +349,13 @@ func TestGetMoviesInTheaters(t *testing.T) {
 func TestGetTmdbPopularMovies(t *testing.T) {
 	apiKey := loadEnv(t)
 
-	client, err := New(apiKey)
+	client, err := New(apiKey, Config{})
 	if err != nil {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
 	t.Run("fetches popular movies with default region", func(t *testing.T) {
-		movies, err := client.GetTmdbPopularMovies()
+		movies, err := client.GetTmdbPopularMovies(Locale{})
 		if err != nil {
 			t.Fatalf("Failed to get popular movies: %v", err)
 		}
@@ -375,7 +375,7 @@ func TestGetTmdbPopularMovies(t *testing.T) {
 	})
 
 	t.Run("fetches popular movies with custom region", func(t *testing.T) {
-		movies, err := client.GetTmdbPopularMovies("GB")
+		movies, err := client.GetTmdbPopularMovies(Locale{Country: "GB"})
 		if err != nil {
 			t.Fatalf("Failed to get popular movies for GB: %v", err)
 		}
@@ -0,0 +1,133 @@
+package tmdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"time"
+
+	"igloo/cmd/internal/helpers"
+)
+
+// ErrRateLimited is returned when TMDB still answers 429 after every retry.
+var ErrRateLimited = errors.New("rate limit exceeded for tmdb")
+
+// get returns the body of a TMDB API response, from the cache when it holds one.
+// Requests are rate limited, and 429 and 5xx answers and network errors are retried.
+// Successful responses are cached for ttl.
+func (t *tmdbClient) get(path string, params url.Values, ttl time.Duration) ([]byte, error) {
+	return t.request(path, params, ttl, true)
+}
+
+// getFresh is get without reading the cache, for callers that want TMDB's current
+// answer. The answer still replaces the cached one.
+func (t *tmdbClient) getFresh(path string, params url.Values, ttl time.Duration) ([]byte, error) {
+	return t.request(path, params, ttl, false)
+}
+
+func (t *tmdbClient) request(path string, params url.Values, ttl time.Duration, cached bool) ([]byte, error) {
+	// The key is left out of the cache key, so a new key keeps the cache
+	cacheKey := path
+	if len(params) > 0 {
+		cacheKey += "?" + params.Encode()
+	}
+
+	if t.cache != nil && cached {
+		if body, ok := t.cache.Get(cacheKey); ok {
+			return body, nil
+		}
+	}
+
+	query := url.Values{}
+	for name, values := range params {
+		query[name] = values
+	}
+	query.Set("api_key", t.key)
+	requestURL := fmt.Sprintf("%s%s?%s", t.baseURL, path, query.Encode())
+
+	ctx := context.Background()
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		body, retryAfter, err := t.do(ctx, requestURL)
+		if err == nil {
+			if t.cache != nil {
+				t.cache.Set(cacheKey, body, ttl)
+			}
+			return body, nil
+		}
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) {
+			return nil, err
+		}
+
+		delay := retryAfter
+		if delay < 0 {
+			// Full jitter keeps concurrent clients from retrying in lockstep
+			delay = rand.N(t.retryBase << attempt)
+		}
+		if attempt >= helpers.TMDB_MAX_RETRIES || delay > helpers.TMDB_MAX_RETRY_DELAY {
+			return nil, retryable.err
+		}
+
+		time.Sleep(delay)
+	}
+}
+
+// retryableError is a failed request worth repeating: a network error, or a 429 or
+// 5xx answer.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// do sends one request. retryAfter is the delay a 429 or 503 answer asked for, or -1.
+func (t *tmdbClient) do(ctx context.Context, requestURL string) (body []byte, retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		// The error holds the URL, which holds the API key
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, -1, &retryableError{err: fmt.Errorf("tmdb request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, -1, &retryableError{err: err}
+		}
+		return body, -1, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, helpers.ParseRetryAfter(resp.Header.Get("Retry-After")), &retryableError{err: ErrRateLimited}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, helpers.ParseRetryAfter(resp.Header.Get("Retry-After")), &retryableError{err: fmt.Errorf("tmdb returned %s", resp.Status)}
+	default:
+		return nil, -1, fmt.Errorf("tmdb returned %s", resp.Status)
+	}
+}
@@ -0,0 +1,146 @@
+package tmdb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"igloo/cmd/internal/helpers"
+)
+
+// memoryCache is a Cache that ignores TTLs.
+type memoryCache map[string][]byte
+
+func (c memoryCache) Get(key string) ([]byte, bool) {
+	body, ok := c[key]
+	return body, ok
+}
+
+func (c memoryCache) Set(key string, body []byte, ttl time.Duration) {
+	c[key] = body
+}
+
+// newTestClient returns a client for a mock server that retries without waiting.
+func newTestClient(t *testing.T, handler http.HandlerFunc, cache Cache) *tmdbClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := New("test-key", Config{BaseURL: server.URL + "/", Cache: cache})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	c := client.(*tmdbClient)
+	c.retryBase = time.Millisecond
+	return c
+}
+
+// TestGetTmdbMovieByID_RetriesAndCaches tests that 429 and 5xx answers are retried
+// and that the response is then served from the cache.
+func TestGetTmdbMovieByID_RetriesAndCaches(t *testing.T) {
+	var requests atomic.Int32
+	cache := memoryCache{}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/movie/603" || r.URL.Query().Get("api_key") != "test-key" {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch requests.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"id": 603, "title": "The Matrix"}`))
+		}
+	}, cache)
+
+	for i := 0; i < 2; i++ {
+		movie := &TmdbMovie{TmdbID: 603}
+		if err := client.GetTmdbMovieByID(movie, Locale{}); err != nil {
+			t.Fatalf("GetTmdbMovieByID failed: %v", err)
+		}
+		if movie.Title != "The Matrix" {
+			t.Errorf("Expected The Matrix, got %q", movie.Title)
+		}
+	}
+
+	if n := requests.Load(); n != 3 {
+		t.Errorf("Expected 3 requests, got %d", n)
+	}
+
+	if _, ok := cache["/movie/603?append_to_response=credits%2Cvideos%2Crelease_dates"]; !ok {
+		t.Errorf("Expected the response to be cached without the api key, got %v", cache)
+	}
+}
+
+// TestRefreshTmdbMovieByID_BypassesCache tests that a refresh asks TMDB even when the
+// movie is cached, and that its answer replaces the cached one.
+func TestRefreshTmdbMovieByID_BypassesCache(t *testing.T) {
+	var requests atomic.Int32
+	cache := memoryCache{}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Write([]byte(`{"id": 603, "title": "The Matrix"}`))
+			return
+		}
+		w.Write([]byte(`{"id": 603, "title": "The Matrix (updated)"}`))
+	}, cache)
+
+	movie := &TmdbMovie{TmdbID: 603}
+	if err := client.GetTmdbMovieByID(movie, Locale{}); err != nil {
+		t.Fatalf("GetTmdbMovieByID failed: %v", err)
+	}
+
+	movie = &TmdbMovie{TmdbID: 603}
+	if err := client.RefreshTmdbMovieByID(movie, Locale{}); err != nil {
+		t.Fatalf("RefreshTmdbMovieByID failed: %v", err)
+	}
+	if movie.Title != "The Matrix (updated)" {
+		t.Errorf("Expected the refreshed title, got %q", movie.Title)
+	}
+
+	movie = &TmdbMovie{TmdbID: 603}
+	if err := client.GetTmdbMovieByID(movie, Locale{}); err != nil {
+		t.Fatalf("GetTmdbMovieByID failed: %v", err)
+	}
+	if movie.Title != "The Matrix (updated)" || requests.Load() != 2 {
+		t.Errorf("Expected the refreshed movie from the cache, got %q after %d requests", movie.Title, requests.Load())
+	}
+}
+
+func TestGetTmdbMovieByID_GivesUp(t *testing.T) {
+	var requests atomic.Int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/movie/1" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		http.NotFound(w, r)
+	}, nil)
+
+	err := client.GetTmdbMovieByID(&TmdbMovie{TmdbID: 1}, Locale{})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+	if n := requests.Load(); n != helpers.TMDB_MAX_RETRIES+1 {
+		t.Errorf("Expected %d requests, got %d", helpers.TMDB_MAX_RETRIES+1, n)
+	}
+
+	// Client errors aren't retried
+	requests.Store(0)
+	if err := client.GetTmdbMovieByID(&TmdbMovie{TmdbID: 2}, Locale{}); err == nil || requests.Load() != 1 {
+		t.Errorf("Expected one failed request for a missing movie, got %d (%v)", requests.Load(), err)
+	}
+}
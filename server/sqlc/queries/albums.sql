@@ -35,14 +35,19 @@ LIMIT
 -- name: GetAlbumsAlphabetical :many
 -- Returns albums sorted alphabetically by title with pagination.
 -- Non-alphabetic titles (numbers, symbols) are grouped under '#' and sorted first.
+-- A NULL is_compilation returns compilations and regular albums alike.
 SELECT
   id,
   title,
   cover,
   musician,
-  year
+  year,
+  is_compilation
 FROM
   albums
+WHERE
+  sqlc.narg(is_compilation) IS NULL
+  OR is_compilation = sqlc.narg(is_compilation)
 ORDER BY
   CASE
     WHEN UPPER(SUBSTR(title, 1, 1)) BETWEEN 'A' AND 'Z'
@@ -53,6 +58,10 @@ ORDER BY
 LIMIT ? OFFSET ?;
 
 -- name: UpsertAlbum :one
+-- Albums with a MusicBrainz release id are never merged by title and album artist, only
+-- albums without one conflict (see idx_album_title_musician_unmatched). A scored Spotify
+-- lookup replaces the Spotify fields, rejected matches clearing them; without a score
+-- they are kept.
 INSERT INTO
   albums (
     title,
@@ -63,24 +72,185 @@ INSERT INTO
     release_date,
     year,
     total_tracks,
-    cover
+    cover,
+    musicbrainz_album_id,
+    musicbrainz_release_group_id,
+    spotify_match_score
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (title, musician) DO
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (title, musician)
+WHERE
+  musicbrainz_album_id IS NULL DO
 UPDATE
 SET
-  sort_title = excluded.sort_title,
-  spotify_id = COALESCE(excluded.spotify_id, albums.spotify_id),
-  spotify_popularity = COALESCE(
-    excluded.spotify_popularity,
-    albums.spotify_popularity
+  sort_title = CASE
+    WHEN 'sort_title' IN (SELECT value FROM json_each(albums.locked_fields)) THEN albums.sort_title
+    ELSE excluded.sort_title
+  END,
+  spotify_id = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.spotify_id
+    ELSE excluded.spotify_id
+  END,
+  spotify_popularity = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.spotify_popularity
+    ELSE excluded.spotify_popularity
+  END,
+  release_date = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.release_date
+    ELSE excluded.release_date
+  END,
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(albums.locked_fields)) THEN albums.year
+    ELSE COALESCE(excluded.year, albums.year)
+  END,
+  total_tracks = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.total_tracks
+    ELSE excluded.total_tracks
+  END,
+  cover = CASE
+    WHEN excluded.spotify_match_score IS NULL THEN albums.cover
+    ELSE excluded.cover
+  END,
+  musicbrainz_release_group_id = COALESCE(
+    excluded.musicbrainz_release_group_id,
+    albums.musicbrainz_release_group_id
+  ),
+  spotify_match_score = COALESCE(
+    excluded.spotify_match_score,
+    albums.spotify_match_score
   ),
-  release_date = COALESCE(excluded.release_date, albums.release_date),
-  year = COALESCE(excluded.year, albums.year),
-  total_tracks = COALESCE(excluded.total_tracks, albums.total_tracks),
-  cover = COALESCE(excluded.cover, albums.cover),
   updated_at = CURRENT_TIMESTAMP RETURNING *;
 
 -- name: DeleteAlbum :exec
 -- Deleting an album will cascade delete all associated tracks
-DELETE FROM albums WHERE id = ?;
\ No newline at end of file
+DELETE FROM albums WHERE id = ?;
+
+-- name: UpdateAlbumMetadata :one
+-- Applies a hand edit. The caller passes every editable field and the new lock set.
+UPDATE albums
+SET
+  title = ?,
+  sort_title = ?,
+  year = ?,
+  scan_title = ?,
+  locked_fields = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: GetAlbumByScanTitle :one
+-- Finds an album renamed by hand through the tag title the scanner still reads.
+SELECT
+  *
+FROM
+  albums
+WHERE
+  scan_title = ?
+  AND musician IS ?
+LIMIT
+  1;
+
+-- name: GetFilteredAlbumsCount :one
+SELECT
+  COUNT(*)
+FROM
+  albums
+WHERE
+  sqlc.narg(is_compilation) IS NULL
+  OR is_compilation = sqlc.narg(is_compilation);
+
+-- name: GetAlbumByDirectory :one
+-- Finds an album grouped by the folder holding its tracks.
+SELECT
+  *
+FROM
+  albums
+WHERE
+  directory = sqlc.arg(directory)
+  AND (
+    title = sqlc.arg(title)
+    OR scan_title = sqlc.arg(title)
+  )
+LIMIT
+  1;
+
+-- name: SetAlbumDirectory :exec
+UPDATE albums
+SET
+  directory = ?
+WHERE
+  id = ?;
+
+-- name: MarkAlbumCompilation :exec
+UPDATE albums
+SET
+  is_compilation = 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: GetAlbumByMusicbrainzID :one
+SELECT
+  *
+FROM
+  albums
+WHERE
+  musicbrainz_album_id = ?
+LIMIT
+  1;
+
+-- name: GetUnmatchedAlbum :one
+-- Finds an album without a MusicBrainz release id by title and album artist, or by the
+-- tag title of one renamed by hand.
+SELECT
+  *
+FROM
+  albums
+WHERE
+  musicbrainz_album_id IS NULL
+  AND musician IS sqlc.arg(musician)
+  AND (
+    title = sqlc.arg(title)
+    OR scan_title = sqlc.arg(title)
+  )
+ORDER BY
+  scan_title IS NULL
+LIMIT
+  1;
+
+-- name: GetAlbumsByTitle :many
+SELECT
+  *
+FROM
+  albums
+WHERE
+  title = ?
+  AND musician IS ?
+ORDER BY
+  id ASC;
+
+-- name: SetAlbumMusicbrainzIDs :one
+UPDATE albums
+SET
+  musicbrainz_album_id = ?,
+  musicbrainz_release_group_id = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdateAlbumSpotifyMatch :one
+-- Matches an album to a Spotify album chosen by hand and locks the match, so scans
+-- keep it.
+UPDATE albums
+SET
+  spotify_id = ?,
+  spotify_popularity = ?,
+  release_date = ?,
+  year = ?,
+  total_tracks = ?,
+  cover = ?,
+  spotify_match_score = NULL,
+  spotify_match_locked = 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
@@ -0,0 +1,25 @@
+-- name: DeleteExpiredApiCacheEntries :exec
+DELETE FROM api_cache
+WHERE
+  expires_at <= ?;
+
+-- name: GetApiCacheEntry :one
+SELECT
+  body
+FROM
+  api_cache
+WHERE
+  provider = ?
+  AND cache_key = ?
+  AND expires_at > ?;
+
+-- name: UpsertApiCacheEntry :exec
+INSERT INTO
+  api_cache (provider, cache_key, body, expires_at)
+VALUES
+  (?, ?, ?, ?) ON CONFLICT (provider, cache_key) DO
+UPDATE
+SET
+  body = excluded.body,
+  expires_at = excluded.expires_at,
+  created_at = CURRENT_TIMESTAMP;
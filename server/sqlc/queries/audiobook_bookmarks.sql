@@ -0,0 +1,24 @@
+-- name: CreateAudiobookBookmark :one
+INSERT INTO
+  audiobook_bookmarks (user_id, audiobook_id, position, note)
+VALUES
+  (?, ?, ?, ?) RETURNING *;
+
+-- name: DeleteAudiobookBookmark :one
+-- Deletes one of the user's bookmarks in a book, returning its id so a missing bookmark can be told apart.
+DELETE FROM audiobook_bookmarks
+WHERE
+  id = ?
+  AND user_id = ?
+  AND audiobook_id = ? RETURNING id;
+
+-- name: GetAudiobookBookmarks :many
+SELECT
+  *
+FROM
+  audiobook_bookmarks
+WHERE
+  user_id = ?
+  AND audiobook_id = ?
+ORDER BY
+  position;
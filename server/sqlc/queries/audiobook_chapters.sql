@@ -0,0 +1,38 @@
+-- name: CreateAudiobookChapter :exec
+INSERT INTO
+  audiobook_chapters (
+    audiobook_id,
+    file_id,
+    chapter_index,
+    title,
+    start_time,
+    end_time
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?);
+
+-- name: DeleteAudiobookChapters :exec
+DELETE FROM audiobook_chapters
+WHERE
+  audiobook_id = ?;
+
+-- name: GetAudiobookChapter :one
+SELECT
+  *
+FROM
+  audiobook_chapters
+WHERE
+  audiobook_id = ?
+  AND chapter_index = ?
+LIMIT
+  1;
+
+-- name: GetAudiobookChapters :many
+SELECT
+  *
+FROM
+  audiobook_chapters
+WHERE
+  audiobook_id = ?
+ORDER BY
+  chapter_index;
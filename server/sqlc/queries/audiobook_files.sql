@@ -0,0 +1,62 @@
+-- name: UpsertAudiobookFile :one
+INSERT INTO
+  audiobook_files (
+    audiobook_id,
+    file_path,
+    file_name,
+    file_index,
+    container,
+    mime_type,
+    codec,
+    size,
+    duration,
+    bit_rate,
+    start_offset
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (file_path) DO
+UPDATE
+SET
+  audiobook_id = excluded.audiobook_id,
+  file_name = excluded.file_name,
+  file_index = excluded.file_index,
+  container = excluded.container,
+  mime_type = excluded.mime_type,
+  codec = excluded.codec,
+  size = excluded.size,
+  duration = excluded.duration,
+  bit_rate = excluded.bit_rate,
+  start_offset = excluded.start_offset,
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
+
+-- name: DeleteStaleAudiobookFiles :exec
+-- Removes the files that are no longer part of a book. file_paths is a JSON array of its current files.
+DELETE FROM audiobook_files
+WHERE
+  audiobook_id = ?
+  AND file_path NOT IN (
+    SELECT
+      value
+    FROM
+      json_each(CAST(sqlc.arg(file_paths) AS TEXT))
+  );
+
+-- name: GetAudiobookFile :one
+SELECT
+  *
+FROM
+  audiobook_files
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetAudiobookFiles :many
+SELECT
+  *
+FROM
+  audiobook_files
+WHERE
+  audiobook_id = ?
+ORDER BY
+  file_index;
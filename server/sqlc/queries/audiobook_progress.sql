@@ -0,0 +1,21 @@
+-- name: GetAudiobookProgress :one
+SELECT
+  *
+FROM
+  audiobook_progress
+WHERE
+  user_id = ?
+  AND audiobook_id = ?
+LIMIT
+  1;
+
+-- name: UpsertAudiobookProgress :one
+INSERT INTO
+  audiobook_progress (user_id, audiobook_id, position, finished)
+VALUES
+  (?, ?, ?, ?) ON CONFLICT (user_id, audiobook_id) DO
+UPDATE
+SET
+  position = excluded.position,
+  finished = excluded.finished,
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
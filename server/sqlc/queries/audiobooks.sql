@@ -0,0 +1,143 @@
+-- name: CheckAudiobookUnchanged :one
+-- Quick check if a book exists with the same total size and number of files (likely unchanged)
+SELECT
+  1
+FROM
+  audiobooks a
+WHERE
+  a.path = ?
+  AND a.size = ?
+  AND (
+    SELECT
+      COUNT(*)
+    FROM
+      audiobook_files f
+    WHERE
+      f.audiobook_id = a.id
+  ) = CAST(sqlc.arg(file_count) AS INTEGER);
+
+-- name: UpsertAudiobook :one
+INSERT INTO
+  audiobooks (
+    title,
+    sort_title,
+    path,
+    author_id,
+    narrator,
+    series,
+    series_index,
+    description,
+    year,
+    duration,
+    size
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (path) DO
+UPDATE
+SET
+  title = excluded.title,
+  sort_title = excluded.sort_title,
+  author_id = excluded.author_id,
+  narrator = excluded.narrator,
+  series = excluded.series,
+  series_index = excluded.series_index,
+  description = excluded.description,
+  year = excluded.year,
+  duration = excluded.duration,
+  size = excluded.size,
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
+
+-- name: GetAudiobookByID :one
+SELECT
+  b.*,
+  au.name AS author_name
+FROM
+  audiobooks b
+  LEFT JOIN authors au ON au.id = b.author_id
+WHERE
+  b.id = ?
+LIMIT
+  1;
+
+-- name: GetAudiobooksAlphabetical :many
+-- Returns books sorted by title with the user's progress, position 0 when not started.
+SELECT
+  b.*,
+  au.name AS author_name,
+  COALESCE(p.position, 0) AS position,
+  COALESCE(p.finished, false) AS finished
+FROM
+  audiobooks b
+  LEFT JOIN authors au ON au.id = b.author_id
+  LEFT JOIN audiobook_progress p ON p.audiobook_id = b.id
+  AND p.user_id = ?
+ORDER BY
+  b.sort_title COLLATE NOCASE
+LIMIT
+  ?
+OFFSET
+  ?;
+
+-- name: GetAudiobooksByAuthor :many
+-- Returns an author's books with the user's progress, grouped by series in reading order.
+SELECT
+  b.*,
+  au.name AS author_name,
+  COALESCE(p.position, 0) AS position,
+  COALESCE(p.finished, false) AS finished
+FROM
+  audiobooks b
+  LEFT JOIN authors au ON au.id = b.author_id
+  LEFT JOIN audiobook_progress p ON p.audiobook_id = b.id
+  AND p.user_id = ?
+WHERE
+  b.author_id = ?
+ORDER BY
+  b.series IS NULL,
+  b.series COLLATE NOCASE,
+  b.series_index,
+  b.sort_title COLLATE NOCASE;
+
+-- name: GetAudiobooksInProgress :many
+-- Returns the books the user started but hasn't finished, most recently played first.
+SELECT
+  b.*,
+  au.name AS author_name,
+  p.position,
+  p.finished
+FROM
+  audiobook_progress p
+  INNER JOIN audiobooks b ON b.id = p.audiobook_id
+  LEFT JOIN authors au ON au.id = b.author_id
+WHERE
+  p.user_id = ?
+  AND NOT p.finished
+  AND p.position > 0
+ORDER BY
+  p.updated_at DESC
+LIMIT
+  ?;
+
+-- name: GetAudiobooksCount :one
+SELECT
+  COUNT(*)
+FROM
+  audiobooks;
+
+-- name: GetAudiobooksByDirectory :many
+-- Books stored under a directory (pass it with a trailing separator), used to find
+-- the books gone from it.
+SELECT
+  id,
+  path
+FROM
+  audiobooks
+WHERE
+  instr(path, CAST(sqlc.arg(dir) AS TEXT)) = 1
+ORDER BY
+  id;
+
+-- name: DeleteAudiobook :exec
+DELETE FROM audiobooks
+WHERE
+  id = ?;
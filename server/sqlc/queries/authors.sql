@@ -0,0 +1,42 @@
+-- name: UpsertAuthor :one
+INSERT INTO
+  authors (name, sort_name)
+VALUES
+  (?, ?) ON CONFLICT (name) DO
+UPDATE
+SET
+  sort_name = excluded.sort_name,
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
+
+-- name: GetAuthorByID :one
+SELECT
+  *
+FROM
+  authors
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetAuthorsAlphabetical :many
+-- Returns the authors with at least one book, sorted by name, with their book counts.
+SELECT
+  a.*,
+  COUNT(b.id) AS book_count
+FROM
+  authors a
+  INNER JOIN audiobooks b ON b.author_id = a.id
+GROUP BY
+  a.id
+ORDER BY
+  a.sort_name COLLATE NOCASE
+LIMIT
+  ?
+OFFSET
+  ?;
+
+-- name: GetAuthorsCount :one
+SELECT
+  COUNT(DISTINCT author_id)
+FROM
+  audiobooks;
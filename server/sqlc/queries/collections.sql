@@ -0,0 +1,158 @@
+-- name: CreateCollectionPart :exec
+INSERT INTO
+  collection_parts (
+    collection_id,
+    tmdb_id,
+    title,
+    release_date,
+    overview,
+    poster_path
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?) ON CONFLICT (collection_id, tmdb_id) DO NOTHING;
+
+-- name: DeleteCollectionParts :exec
+DELETE FROM collection_parts
+WHERE
+  collection_id = ?;
+
+-- name: DeleteMovieCollection :exec
+DELETE FROM movie_collections
+WHERE
+  movie_id = ?;
+
+-- name: GetCollectionByID :one
+SELECT
+  *
+FROM
+  collections
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetCollectionMovies :many
+-- Returns the movies of a collection in the library by release date, undated ones last.
+SELECT
+  m.id,
+  m.title,
+  m.tmdb_id,
+  m.poster_path,
+  m.year,
+  m.release_date
+FROM
+  movies m
+  INNER JOIN movie_collections mc ON mc.movie_id = m.id
+WHERE
+  mc.collection_id = ?
+ORDER BY
+  m.release_date IS NULL,
+  m.release_date,
+  COALESCE(m.sort_title, m.title);
+
+-- name: GetCollections :many
+-- Returns the collections with movies in the library sorted by name, with how many
+-- of their movies the library has and how many it misses.
+SELECT
+  c.*,
+  COUNT(mc.movie_id) AS movie_count,
+  (
+    SELECT
+      COUNT(*)
+    FROM
+      collection_parts cp
+    WHERE
+      cp.collection_id = c.id
+      AND cp.tmdb_id NOT IN (
+        SELECT
+          tmdb_id
+        FROM
+          movies
+        WHERE
+          tmdb_id IS NOT NULL
+      )
+  ) AS missing_count
+FROM
+  collections c
+  INNER JOIN movie_collections mc ON mc.collection_id = c.id
+GROUP BY
+  c.id
+ORDER BY
+  c.name COLLATE NOCASE
+LIMIT
+  ?
+OFFSET
+  ?;
+
+-- name: GetCollectionsCount :one
+SELECT
+  COUNT(DISTINCT collection_id)
+FROM
+  movie_collections;
+
+-- name: GetMissingCollectionParts :many
+-- Returns the movies of a collection the library doesn't have by release date,
+-- undated ones last.
+SELECT
+  *
+FROM
+  collection_parts
+WHERE
+  collection_id = ?
+  AND tmdb_id NOT IN (
+    SELECT
+      tmdb_id
+    FROM
+      movies
+    WHERE
+      tmdb_id IS NOT NULL
+  )
+ORDER BY
+  release_date IS NULL,
+  release_date,
+  title;
+
+-- name: GetMoviesWithoutCollection :many
+-- Returns TMDB-matched movies linked to no collection, paged by id.
+SELECT
+  id,
+  tmdb_id
+FROM
+  movies m
+WHERE
+  tmdb_id IS NOT NULL
+  AND NOT EXISTS (
+    SELECT
+      1
+    FROM
+      movie_collections mc
+    WHERE
+      mc.movie_id = m.id
+  )
+  AND id > sqlc.arg(after_id)
+ORDER BY
+  id ASC
+LIMIT
+  ?;
+
+-- name: SetMovieCollection :exec
+INSERT INTO
+  movie_collections (movie_id, collection_id)
+VALUES
+  (?, ?) ON CONFLICT (movie_id) DO
+UPDATE
+SET
+  collection_id = excluded.collection_id;
+
+-- name: UpsertCollection :one
+INSERT INTO
+  collections (tmdb_id, name, overview, poster_path, backdrop_path)
+VALUES
+  (?, ?, ?, ?, ?) ON CONFLICT (tmdb_id) DO
+UPDATE
+SET
+  name = excluded.name,
+  overview = COALESCE(excluded.overview, collections.overview),
+  poster_path = COALESCE(excluded.poster_path, collections.poster_path),
+  backdrop_path = COALESCE(excluded.backdrop_path, collections.backdrop_path),
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
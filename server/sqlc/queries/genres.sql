@@ -43,4 +43,128 @@ FROM
 WHERE
   ag.album_id = ?
 ORDER BY
-  g.tag ASC;
\ No newline at end of file
+  g.tag ASC;
+
+-- name: GetGenreByID :one
+SELECT * FROM genres WHERE id = ? LIMIT 1;
+
+-- name: GetGenreByAlias :one
+-- Resolves a normalized genre key (see helpers.NormalizeKey) to its canonical genre
+SELECT
+  *
+FROM
+  genres
+WHERE
+  id = (
+    SELECT
+      ga.genre_id
+    FROM
+      genre_aliases ga
+    WHERE
+      ga.alias = ?
+      AND ga.genre_type = ?
+  )
+LIMIT
+  1;
+
+-- name: UpsertGenreAlias :exec
+-- Points a normalized genre key at a canonical genre, replacing any previous target
+INSERT INTO genre_aliases (alias, genre_type, genre_id)
+VALUES (?, ?, ?)
+ON CONFLICT (alias, genre_type) DO UPDATE SET
+  genre_id = excluded.genre_id,
+  updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetGenreAliasesByType :many
+-- Returns the normalized keys of every genre of a type, grouped by genre in GetGenres
+SELECT genre_id, alias FROM genre_aliases WHERE genre_type = ? ORDER BY alias ASC;
+
+-- name: GetUnaliasedGenres :many
+-- Returns the genres no normalized key resolves to yet, oldest first, e.g. genres
+-- created before aliases existed
+SELECT
+  *
+FROM
+  genres g
+WHERE
+  NOT EXISTS (
+    SELECT
+      1
+    FROM
+      genre_aliases ga
+    WHERE
+      ga.genre_id = g.id
+  )
+ORDER BY
+  g.id ASC;
+
+-- name: GetGenresWithCounts :many
+-- Returns all genres of a type with how many tracks, albums, musicians and movies use each
+SELECT
+  g.id,
+  g.tag,
+  g.genre_type,
+  (SELECT COUNT(*) FROM track_genres tg WHERE tg.genre_id = g.id) AS track_count,
+  (SELECT COUNT(*) FROM album_genres ag WHERE ag.genre_id = g.id) AS album_count,
+  (SELECT COUNT(*) FROM musician_genres mg WHERE mg.genre_id = g.id) AS musician_count,
+  (SELECT COUNT(*) FROM movie_genres mvg WHERE mvg.genre_id = g.id) AS movie_count
+FROM
+  genres g
+WHERE
+  g.genre_type = ?
+ORDER BY
+  g.tag ASC;
+
+-- name: MergeTrackGenres :exec
+-- Re-links tracks from the source genre to the target genre.
+-- Rows whose track already has the target genre are left behind and removed by DeleteGenre's cascade.
+UPDATE OR IGNORE track_genres
+SET
+  genre_id = sqlc.arg(target_id)
+WHERE
+  genre_id = sqlc.arg(source_id);
+
+-- name: MergeAlbumGenres :exec
+-- Re-links albums from the source genre to the target genre (see MergeTrackGenres)
+UPDATE OR IGNORE album_genres
+SET
+  genre_id = sqlc.arg(target_id)
+WHERE
+  genre_id = sqlc.arg(source_id);
+
+-- name: MergeMusicianGenres :exec
+-- Re-links musicians from the source genre to the target genre (see MergeTrackGenres)
+UPDATE OR IGNORE musician_genres
+SET
+  genre_id = sqlc.arg(target_id)
+WHERE
+  genre_id = sqlc.arg(source_id);
+
+-- name: MergeMovieGenres :exec
+-- Re-links movies from the source genre to the target genre (see MergeTrackGenres)
+UPDATE OR IGNORE movie_genres
+SET
+  genre_id = sqlc.arg(target_id)
+WHERE
+  genre_id = sqlc.arg(source_id);
+
+-- name: MergeGenreAliases :exec
+-- Points every alias of the source genre at the target genre
+UPDATE genre_aliases
+SET
+  genre_id = sqlc.arg(target_id),
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  genre_id = sqlc.arg(source_id);
+
+-- name: DeleteGenre :exec
+-- Deleting a genre cascades to its remaining track, album, musician, movie and alias links
+DELETE FROM genres WHERE id = ?;
+
+-- name: DeleteAlbumGenres :exec
+-- Removes every genre link of an album, before its genres are replaced
+DELETE FROM album_genres WHERE album_id = ?;
+
+-- name: DeleteMusicianGenres :exec
+-- Removes every genre link of a musician, before its genres are replaced
+DELETE FROM musician_genres WHERE musician_id = ?;
@@ -0,0 +1,67 @@
+-- name: CountLastfmScrobbles :one
+-- Plays of a user waiting to be scrobbled.
+SELECT
+  COUNT(*)
+FROM
+  lastfm_scrobbles
+WHERE
+  user_id = ?;
+
+-- name: CreateLastfmScrobble :exec
+INSERT INTO
+  lastfm_scrobbles (
+    user_id,
+    artist,
+    track,
+    album,
+    album_artist,
+    track_number,
+    duration,
+    mbid,
+    played_at
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: DeleteExpiredLastfmScrobbles :exec
+-- Drops plays too old for Last.fm to take, cutoff is a Unix time.
+DELETE FROM lastfm_scrobbles
+WHERE
+  played_at < sqlc.arg(cutoff);
+
+-- name: DeleteLastfmScrobble :exec
+DELETE FROM lastfm_scrobbles
+WHERE
+  id = ?;
+
+-- name: DeleteLastfmScrobblesByUser :exec
+DELETE FROM lastfm_scrobbles
+WHERE
+  user_id = ?;
+
+-- name: GetDueLastfmScrobbles :many
+-- Queued plays of linked accounts whose next attempt is due, grouped by user and
+-- oldest first as Last.fm expects them.
+SELECT
+  s.*,
+  ls.session_key
+FROM
+  lastfm_scrobbles s
+  INNER JOIN lastfm_sessions ls ON ls.user_id = s.user_id
+WHERE
+  s.next_attempt_at <= sqlc.arg(now)
+ORDER BY
+  s.user_id,
+  s.played_at
+LIMIT
+  ?;
+
+-- name: RetryLastfmScrobble :exec
+-- Puts a play Last.fm couldn't take back in the queue until next_attempt_at.
+UPDATE lastfm_scrobbles
+SET
+  attempts = attempts + 1,
+  last_error = ?,
+  next_attempt_at = ?
+WHERE
+  id = ?;
@@ -0,0 +1,24 @@
+-- name: DeleteLastfmSession :exec
+DELETE FROM lastfm_sessions
+WHERE
+  user_id = ?;
+
+-- name: GetLastfmSession :one
+SELECT
+  *
+FROM
+  lastfm_sessions
+WHERE
+  user_id = ?;
+
+-- name: UpsertLastfmSession :one
+-- Links a Last.fm account, replacing the one the user linked before.
+INSERT INTO
+  lastfm_sessions (user_id, username, session_key)
+VALUES
+  (?, ?, ?) ON CONFLICT (user_id) DO
+UPDATE
+SET
+  username = excluded.username,
+  session_key = excluded.session_key,
+  created_at = CURRENT_TIMESTAMP RETURNING *;
@@ -0,0 +1,34 @@
+-- name: DeleteListenbrainzAccount :exec
+DELETE FROM listenbrainz_accounts
+WHERE
+  user_id = ?;
+
+-- name: GetListenbrainzAccount :one
+SELECT
+  *
+FROM
+  listenbrainz_accounts
+WHERE
+  user_id = ?;
+
+-- name: UpdateListenbrainzImport :exec
+-- Stores the outcome of a finished history import.
+UPDATE listenbrainz_accounts
+SET
+  imported_at = CURRENT_TIMESTAMP,
+  imported_listens = ?,
+  unmatched_listens = ?
+WHERE
+  user_id = ?;
+
+-- name: UpsertListenbrainzAccount :one
+-- Saves the token of a user, replacing the one entered before. The outcome of the
+-- last import is kept.
+INSERT INTO
+  listenbrainz_accounts (user_id, username, token)
+VALUES
+  (?, ?, ?) ON CONFLICT (user_id) DO
+UPDATE
+SET
+  username = excluded.username,
+  token = excluded.token RETURNING *;
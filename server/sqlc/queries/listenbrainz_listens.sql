@@ -0,0 +1,62 @@
+-- name: CountListenbrainzListens :one
+-- Listens of a user waiting to be submitted.
+SELECT
+  COUNT(*)
+FROM
+  listenbrainz_listens
+WHERE
+  user_id = ?;
+
+-- name: CreateListenbrainzListen :exec
+INSERT INTO
+  listenbrainz_listens (
+    user_id,
+    artist,
+    track,
+    release,
+    recording_mbid,
+    release_mbid,
+    artist_mbid,
+    track_number,
+    duration,
+    listened_at
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: DeleteListenbrainzListen :exec
+DELETE FROM listenbrainz_listens
+WHERE
+  id = ?;
+
+-- name: DeleteListenbrainzListensByUser :exec
+DELETE FROM listenbrainz_listens
+WHERE
+  user_id = ?;
+
+-- name: GetDueListenbrainzListens :many
+-- Queued listens of users with a token whose next attempt is due, grouped by user and
+-- oldest first.
+SELECT
+  l.*,
+  la.token
+FROM
+  listenbrainz_listens l
+  INNER JOIN listenbrainz_accounts la ON la.user_id = l.user_id
+WHERE
+  l.next_attempt_at <= sqlc.arg(now)
+ORDER BY
+  l.user_id,
+  l.listened_at
+LIMIT
+  ?;
+
+-- name: RetryListenbrainzListen :exec
+-- Puts a listen ListenBrainz couldn't take back in the queue until next_attempt_at.
+UPDATE listenbrainz_listens
+SET
+  attempts = attempts + 1,
+  last_error = ?,
+  next_attempt_at = ?
+WHERE
+  id = ?;
@@ -0,0 +1,68 @@
+-- name: CheckLocalExtraUnchanged :one
+-- Quick check if an extra exists with same path and size (likely unchanged)
+SELECT
+  1
+FROM
+  local_extras
+WHERE
+  file_path = ?
+  AND size = ?
+LIMIT
+  1;
+
+-- name: UpsertLocalExtra :one
+INSERT INTO
+  local_extras (
+    movie_id,
+    title,
+    type,
+    file_path,
+    file_name,
+    size,
+    container,
+    mime_type,
+    duration
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (file_path) DO
+UPDATE
+SET
+  movie_id = excluded.movie_id,
+  title = excluded.title,
+  type = excluded.type,
+  file_name = excluded.file_name,
+  size = excluded.size,
+  container = excluded.container,
+  mime_type = excluded.mime_type,
+  duration = excluded.duration,
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
+
+-- name: GetLocalExtraByID :one
+SELECT
+  *
+FROM
+  local_extras
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetLocalExtrasByMovieID :many
+-- Local extras of a movie (for details view).
+SELECT
+  *
+FROM
+  local_extras
+WHERE
+  movie_id = ?
+ORDER BY
+  type,
+  title;
+
+-- name: MergeLocalExtras :exec
+-- Moves the local extras of the source movie to the target movie (see MergeMediaVersions)
+UPDATE local_extras
+SET
+  movie_id = sqlc.arg(target_id)
+WHERE
+  movie_id = sqlc.arg(source_id);
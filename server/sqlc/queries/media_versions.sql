@@ -0,0 +1,137 @@
+-- name: UpsertMediaVersion :one
+-- Insert or update the version backed by a file. A file re-scanned into a different
+-- logical movie (e.g. after a TMDB rematch) moves with it.
+INSERT INTO
+  media_versions (
+    movie_id,
+    file_path,
+    file_name,
+    size,
+    container,
+    mime_type,
+    edition
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?, ?) ON CONFLICT (file_path) DO
+UPDATE
+SET
+  movie_id = excluded.movie_id,
+  file_name = excluded.file_name,
+  size = excluded.size,
+  container = excluded.container,
+  mime_type = excluded.mime_type,
+  edition = excluded.edition,
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
+
+-- name: GetMediaVersionByID :one
+SELECT
+  *
+FROM
+  media_versions
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetMediaVersionByFilePath :one
+SELECT
+  *
+FROM
+  media_versions
+WHERE
+  file_path = ?
+LIMIT
+  1;
+
+-- name: GetMediaVersionsByMovieID :many
+-- Versions of a movie with the properties of their first video stream,
+-- used to list versions and pick one for playback.
+SELECT
+  mv.id,
+  mv.movie_id,
+  mv.file_path,
+  mv.file_name,
+  mv.size,
+  mv.container,
+  mv.mime_type,
+  mv.edition,
+  vs.codec AS video_codec,
+  vs.width,
+  vs.height,
+  vs.bit_rate,
+  vs.color_transfer
+FROM
+  media_versions mv
+  LEFT JOIN video_streams vs ON vs.id = (
+    SELECT
+      v.id
+    FROM
+      video_streams v
+    WHERE
+      v.media_version_id = mv.id
+    ORDER BY
+      v.stream_index
+    LIMIT
+      1
+  )
+WHERE
+  mv.movie_id = ?
+ORDER BY
+  vs.height DESC,
+  mv.id ASC;
+
+-- name: DeleteMediaVersionByFilePath :exec
+DELETE FROM media_versions
+WHERE
+  file_path = ?;
+
+-- name: MergeMediaVersions :exec
+-- Moves the versions of the source movie to the target movie, e.g. when both are matched to one film
+UPDATE media_versions
+SET
+  movie_id = sqlc.arg(target_id)
+WHERE
+  movie_id = sqlc.arg(source_id);
+
+-- name: GetVideoStreamsByMediaVersionID :many
+SELECT
+  *
+FROM
+  video_streams
+WHERE
+  media_version_id = ?
+ORDER BY
+  stream_index;
+
+-- name: GetAudioStreamsByMediaVersionID :many
+SELECT
+  *
+FROM
+  audio_streams
+WHERE
+  media_version_id = ?
+ORDER BY
+  stream_index;
+
+-- name: GetSubtitlesByMediaVersionID :many
+SELECT
+  *
+FROM
+  subtitles
+WHERE
+  media_version_id = ?
+ORDER BY
+  stream_index;
+
+-- name: GetMediaVersionsByDirectory :many
+-- Versions stored under a directory (pass it with a trailing separator), used to
+-- find the movie that extras found next to it belong to, and the files gone from it.
+SELECT
+  movie_id,
+  file_path
+FROM
+  media_versions
+WHERE
+  instr(file_path, CAST(sqlc.arg(dir) AS TEXT)) = 1
+ORDER BY
+  id;
@@ -1,12 +1,21 @@
 -- name: CheckMovieUnchanged :one
--- Quick check if movie exists with same path and size (likely unchanged)
+-- Quick check if a movie version exists with same path and size (likely unchanged).
+-- Versions left in scan_errors, e.g. by a failed TMDB lookup, are scanned again.
 SELECT
   1
 FROM
-  movies
+  media_versions
 WHERE
   file_path = ?
   AND size = ?
+  AND NOT EXISTS (
+    SELECT
+      1
+    FROM
+      scan_errors
+    WHERE
+      scan_errors.file_path = media_versions.file_path
+  )
 LIMIT
   1;
 
@@ -43,6 +52,20 @@ ORDER BY
 LIMIT
   1;
 
+-- name: GetMovieByTitleAndYear :one
+-- Used to group edition-tagged files of a movie that has no TMDB match.
+SELECT
+  *
+FROM
+  movies
+WHERE
+  title = ?
+  AND year IS ?
+ORDER BY
+  id ASC
+LIMIT
+  1;
+
 -- name: GetLatestMovies :many
 SELECT
   id,
@@ -61,6 +84,7 @@ LIMIT
 INSERT INTO
   movies (
     title,
+    original_title,
     file_path,
     file_name,
     size,
@@ -106,11 +130,16 @@ VALUES
     ?,
     ?,
     ?,
+    ?,
     ?
   ) ON CONFLICT (file_path) DO
 UPDATE
 SET
-  title = excluded.title,
+  title = CASE
+    WHEN 'title' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.title
+    ELSE excluded.title
+  END,
+  original_title = COALESCE(excluded.original_title, movies.original_title),
   file_name = excluded.file_name,
   size = excluded.size,
   container = excluded.container,
@@ -120,9 +149,15 @@ SET
   imdb_id = COALESCE(excluded.imdb_id, movies.imdb_id),
   poster_path = COALESCE(excluded.poster_path, movies.poster_path),
   language = COALESCE(excluded.language, movies.language),
-  year = COALESCE(excluded.year, movies.year),
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE COALESCE(excluded.year, movies.year)
+  END,
   release_date = COALESCE(excluded.release_date, movies.release_date),
-  overview = COALESCE(excluded.overview, movies.overview),
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE COALESCE(excluded.overview, movies.overview)
+  END,
   tag_line = COALESCE(excluded.tag_line, movies.tag_line),
   certification = COALESCE(excluded.certification, movies.certification),
   critic_rating = COALESCE(excluded.critic_rating, movies.critic_rating),
@@ -132,6 +167,86 @@ SET
   run_time = COALESCE(excluded.run_time, movies.run_time),
   updated_at = CURRENT_TIMESTAMP RETURNING *;
 
+-- name: FillMovieMetadata :one
+-- Fills the fields the primary file of a movie left empty with the metadata of another
+-- of its versions. Fields already set and hand-edited fields are kept.
+UPDATE movies
+SET
+  original_title = COALESCE(movies.original_title, ?),
+  tmdb_id = COALESCE(movies.tmdb_id, ?),
+  imdb_id = COALESCE(movies.imdb_id, ?),
+  poster_path = COALESCE(movies.poster_path, ?),
+  backdrop_path = COALESCE(movies.backdrop_path, ?),
+  language = COALESCE(movies.language, ?),
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE COALESCE(movies.year, ?)
+  END,
+  release_date = COALESCE(movies.release_date, ?),
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE COALESCE(movies.overview, ?)
+  END,
+  tag_line = COALESCE(movies.tag_line, ?),
+  certification = COALESCE(movies.certification, ?),
+  critic_rating = COALESCE(movies.critic_rating, ?),
+  revenue = COALESCE(movies.revenue, ?),
+  budget = COALESCE(movies.budget, ?),
+  run_time = COALESCE(movies.run_time, ?),
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdateMovieFile :exec
+-- Points a movie at another of its versions, after the file it mirrored is gone.
+UPDATE movies
+SET
+  file_path = ?,
+  file_name = ?,
+  size = ?,
+  container = ?,
+  mime_type = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: DeleteMovie :exec
+DELETE FROM movies
+WHERE
+  id = ?;
+
+-- name: MergeVideoStreams :exec
+-- Moves the video streams of the source movie's versions along with them (see MergeMediaVersions)
+UPDATE video_streams
+SET
+  movie_id = sqlc.arg(target_id)
+WHERE
+  movie_id = sqlc.arg(source_id);
+
+-- name: MergeAudioStreams :exec
+-- Moves the audio streams of the source movie's versions along with them (see MergeMediaVersions)
+UPDATE audio_streams
+SET
+  movie_id = sqlc.arg(target_id)
+WHERE
+  movie_id = sqlc.arg(source_id);
+
+-- name: MergeSubtitles :exec
+-- Moves the subtitles of the source movie's versions along with them (see MergeMediaVersions)
+UPDATE subtitles
+SET
+  movie_id = sqlc.arg(target_id)
+WHERE
+  movie_id = sqlc.arg(source_id);
+
+-- name: MergeChapters :exec
+-- Moves the chapters of the source movie's versions along with them (see MergeMediaVersions)
+UPDATE chapters
+SET
+  movie_id = sqlc.arg(target_id)
+WHERE
+  movie_id = sqlc.arg(source_id);
+
 -- name: UpsertProductionCompany :one
 INSERT INTO
   production_companies (name, tmdb_id, logo, country)
@@ -187,16 +302,24 @@ DELETE FROM movie_production_companies
 WHERE
   movie_id = ?;
 
--- name: DeleteMovieVideoStreams :exec
--- Delete all video streams for a movie
+-- name: DeleteMovieProductionCompany :exec
+-- Remove one production company link of a movie
+DELETE FROM movie_production_companies
+WHERE
+  movie_id = ?
+  AND production_company_id = ?;
+
+-- name: DeleteMediaVersionVideoStreams :exec
+-- Delete all video streams for a movie version
 DELETE FROM video_streams
 WHERE
-  movie_id = ?;
+  media_version_id = ?;
 
 -- name: InsertVideoStream :one
 INSERT INTO
   video_streams (
     movie_id,
+    media_version_id,
     stream_index,
     codec,
     codec_profile,
@@ -238,19 +361,21 @@ VALUES
     ?,
     ?,
     ?,
+    ?,
     ?
   ) RETURNING *;
 
--- name: DeleteMovieAudioStreams :exec
--- Delete all audio streams for a movie
+-- name: DeleteMediaVersionAudioStreams :exec
+-- Delete all audio streams for a movie version
 DELETE FROM audio_streams
 WHERE
-  movie_id = ?;
+  media_version_id = ?;
 
 -- name: InsertAudioStream :one
 INSERT INTO
   audio_streams (
     movie_id,
+    media_version_id,
     stream_index,
     codec,
     codec_profile,
@@ -262,18 +387,19 @@ INSERT INTO
     title
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
 
--- name: DeleteMovieSubtitles :exec
--- Delete all subtitles for a movie
+-- name: DeleteMediaVersionSubtitles :exec
+-- Delete all subtitles for a movie version
 DELETE FROM subtitles
 WHERE
-  movie_id = ?;
+  media_version_id = ?;
 
 -- name: InsertSubtitle :one
 INSERT INTO
   subtitles (
     movie_id,
+    media_version_id,
     stream_index,
     codec,
     language,
@@ -282,19 +408,19 @@ INSERT INTO
     is_default
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?) RETURNING *;
+  (?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
 
--- name: DeleteMovieChapters :exec
--- Delete all chapters for a movie
+-- name: DeleteMediaVersionChapters :exec
+-- Delete all chapters for a movie version
 DELETE FROM chapters
 WHERE
-  movie_id = ?;
+  media_version_id = ?;
 
 -- name: InsertChapter :one
 INSERT INTO
-  chapters (movie_id, title, start_time, thumb)
+  chapters (movie_id, media_version_id, title, start_time, thumb)
 VALUES
-  (?, ?, ?, ?) RETURNING *;
+  (?, ?, ?, ?, ?) RETURNING *;
 
 -- name: CreateMovieGenre :exec
 -- Link movie to genre via junction table
@@ -303,12 +429,31 @@ INSERT INTO
 VALUES
   (?, ?) ON CONFLICT (movie_id, genre_id) DO NOTHING;
 
+-- name: DeleteCastMember :exec
+-- Remove one cast member of a movie
+DELETE FROM cast
+WHERE
+  id = ?;
+
+-- name: DeleteCrewMember :exec
+-- Remove one crew member of a movie
+DELETE FROM crew
+WHERE
+  id = ?;
+
 -- name: DeleteMovieGenres :exec
 -- Remove all genre links for a movie
 DELETE FROM movie_genres
 WHERE
   movie_id = ?;
 
+-- name: DeleteMovieGenre :exec
+-- Remove one genre link of a movie
+DELETE FROM movie_genres
+WHERE
+  movie_id = ?
+  AND genre_id = ?;
+
 -- name: UpsertExtraVideo :one
 -- Insert or update an extra video by external_id (e.g. TMDB video id). Use for trailers/special features.
 -- Call with a non-null external_id so conflicts are detected; then link via CreateMovieExtraVideo.
@@ -423,4 +568,108 @@ WHERE
   movie_extra_videos.movie_id = ?
 ORDER BY
   extra_videos.type,
-  extra_videos.title;
\ No newline at end of file
+  extra_videos.title;
+
+-- name: UpdateMovieMatch :one
+-- Applies a manual TMDB match: every TMDB field is replaced rather than merged,
+-- and the match is locked so later scans keep it.
+UPDATE movies
+SET
+  title = CASE
+    WHEN 'title' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.title
+    ELSE ?
+  END,
+  original_title = ?,
+  adult = ?,
+  tmdb_id = ?,
+  imdb_id = ?,
+  poster_path = ?,
+  backdrop_path = ?,
+  language = ?,
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE ?
+  END,
+  release_date = ?,
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE ?
+  END,
+  tag_line = ?,
+  certification = ?,
+  critic_rating = ?,
+  revenue = ?,
+  budget = ?,
+  run_time = ?,
+  match_locked = 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdateMovieMetadata :one
+-- Applies a hand edit. The caller passes every editable field and the new lock set.
+UPDATE movies
+SET
+  title = ?,
+  sort_title = ?,
+  year = ?,
+  overview = ?,
+  locked_fields = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: GetStaleMovies :many
+-- Returns TMDB-matched movies last refreshed before the cutoff, paged by id.
+SELECT
+  *
+FROM
+  movies
+WHERE
+  tmdb_id IS NOT NULL
+  AND COALESCE(metadata_refreshed_at, created_at) < sqlc.arg(cutoff)
+  AND id > sqlc.arg(after_id)
+ORDER BY
+  id ASC
+LIMIT
+  ?;
+
+-- name: RefreshMovieMetadata :one
+-- Stores re-fetched TMDB metadata. Hand-edited fields are kept.
+UPDATE movies
+SET
+  title = CASE
+    WHEN 'title' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.title
+    ELSE ?
+  END,
+  original_title = ?,
+  imdb_id = ?,
+  poster_path = ?,
+  backdrop_path = ?,
+  year = CASE
+    WHEN 'year' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.year
+    ELSE ?
+  END,
+  release_date = ?,
+  overview = CASE
+    WHEN 'overview' IN (SELECT value FROM json_each(movies.locked_fields)) THEN movies.overview
+    ELSE ?
+  END,
+  tag_line = ?,
+  certification = ?,
+  critic_rating = ?,
+  revenue = ?,
+  budget = ?,
+  run_time = ?,
+  metadata_refreshed_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: TouchMovieMetadataRefreshed :exec
+-- Marks a movie as refreshed when TMDB had nothing new.
+UPDATE movies
+SET
+  metadata_refreshed_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
@@ -2,11 +2,13 @@
 SELECT * FROM musicians WHERE spotify_id = ? LIMIT 1;
 
 -- name: UpsertMusician :one
-INSERT INTO musicians (name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb)
-VALUES (?, ?, ?, ?, ?, ?, ?)
-ON CONFLICT (name) DO UPDATE SET
-  sort_name = excluded.sort_name,
-  summary = COALESCE(excluded.summary, musicians.summary),
+-- Musicians with a MusicBrainz id are never merged by name, only musicians without one
+-- conflict (see idx_musician_name_unmatched).
+INSERT INTO musicians (name, sort_name, summary, spotify_popularity, spotify_followers, spotify_id, thumb, musicbrainz_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (name) WHERE musicbrainz_id IS NULL DO UPDATE SET
+  sort_name = CASE WHEN 'sort_name' IN (SELECT value FROM json_each(musicians.locked_fields)) THEN musicians.sort_name ELSE excluded.sort_name END,
+  summary = CASE WHEN 'summary' IN (SELECT value FROM json_each(musicians.locked_fields)) THEN musicians.summary ELSE COALESCE(excluded.summary, musicians.summary) END,
   spotify_popularity = COALESCE(excluded.spotify_popularity, musicians.spotify_popularity),
   spotify_followers = COALESCE(excluded.spotify_followers, musicians.spotify_followers),
   spotify_id = COALESCE(excluded.spotify_id, musicians.spotify_id),
@@ -19,7 +21,8 @@ SELECT
   m.id,
   m.name,
   m.thumb,
-  m.spotify_id
+  m.spotify_id,
+  m.musicbrainz_id
 FROM
   musicians m
   INNER JOIN musician_albums ma ON m.id = ma.musician_id
@@ -63,6 +66,7 @@ SELECT
   a.year,
   a.release_date,
   a.spotify_popularity,
+  a.musicbrainz_album_id,
   (SELECT COUNT(*) FROM tracks t WHERE t.album_id = a.id) as track_count
 FROM
   albums a
@@ -86,6 +90,7 @@ SELECT
   t.file_path,
   t.track_index,
   t.disc,
+  t.musicbrainz_track_id,
   a.id as album_id,
   a.title as album_title,
   a.cover as album_cover
@@ -93,3 +98,53 @@ FROM tracks t
 LEFT JOIN albums a ON t.album_id = a.id
 WHERE t.musician_id = ?
 ORDER BY t.sort_title ASC;
+
+-- name: UpdateMusicianMetadata :one
+-- Applies a hand edit. The caller passes every editable field and the new lock set.
+UPDATE musicians
+SET name = ?, sort_name = ?, summary = ?, scan_name = ?, locked_fields = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: GetMusicianByScanName :one
+-- Finds a musician renamed by hand through the tag name the scanner still reads.
+SELECT * FROM musicians WHERE scan_name = ? LIMIT 1;
+
+-- name: GetStaleMusicians :many
+-- Returns Spotify-matched musicians last refreshed before the cutoff, paged by id.
+SELECT * FROM musicians
+WHERE spotify_id IS NOT NULL
+  AND COALESCE(metadata_refreshed_at, created_at) < sqlc.arg(cutoff)
+  AND id > sqlc.arg(after_id)
+ORDER BY id ASC
+LIMIT ?;
+
+-- name: RefreshMusicianMetadata :one
+-- Stores re-fetched Spotify metadata. A hand-edited summary is kept.
+UPDATE musicians
+SET summary = CASE WHEN 'summary' IN (SELECT value FROM json_each(musicians.locked_fields)) THEN musicians.summary ELSE ? END,
+  spotify_popularity = ?, spotify_followers = ?, thumb = ?,
+  metadata_refreshed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: TouchMusicianMetadataRefreshed :exec
+-- Marks a musician as refreshed when Spotify had nothing new.
+UPDATE musicians SET metadata_refreshed_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: GetMusicianByMusicbrainzID :one
+SELECT * FROM musicians WHERE musicbrainz_id = ? LIMIT 1;
+
+-- name: GetUnmatchedMusicianByName :one
+-- Finds a musician without a MusicBrainz id by name, or by the tag name of one renamed by hand.
+SELECT * FROM musicians
+WHERE musicbrainz_id IS NULL AND (name = sqlc.arg(name) OR scan_name = sqlc.arg(name))
+ORDER BY scan_name IS NULL
+LIMIT 1;
+
+-- name: GetMusiciansByName :many
+SELECT * FROM musicians WHERE name = ? ORDER BY id ASC;
+
+-- name: SetMusicianMusicbrainzID :one
+UPDATE musicians SET musicbrainz_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+RETURNING *;
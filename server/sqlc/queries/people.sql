@@ -0,0 +1,119 @@
+-- name: GetArtistByID :one
+SELECT
+  *
+FROM
+  artist
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetCastByArtistID :many
+-- Roles of a person in the library's movies, newest first, undated ones last.
+SELECT
+  m.id AS movie_id,
+  m.title,
+  m.poster_path,
+  m.year,
+  m.release_date,
+  c.character,
+  c.cast_order
+FROM
+  cast c
+  INNER JOIN movies m ON m.id = c.movie_id
+WHERE
+  c.artist_id = ?
+ORDER BY
+  m.release_date IS NULL,
+  m.release_date DESC,
+  m.title,
+  c.cast_order;
+
+-- name: GetCrewByArtistID :many
+-- Jobs of a person in the library's movies by department, newest first, undated ones last.
+SELECT
+  m.id AS movie_id,
+  m.title,
+  m.poster_path,
+  m.year,
+  m.release_date,
+  c.job,
+  c.department
+FROM
+  crew c
+  INNER JOIN movies m ON m.id = c.movie_id
+WHERE
+  c.artist_id = ?
+ORDER BY
+  c.department,
+  m.release_date IS NULL,
+  m.release_date DESC,
+  m.title,
+  c.job;
+
+-- name: SearchArtists :many
+-- People credited in the library's movies whose name contains the query (an empty one
+-- matches everyone), the most credited first, with the number of movies they are in.
+SELECT
+  a.*,
+  (
+    SELECT
+      COUNT(*)
+    FROM
+      (
+        SELECT
+          movie_id
+        FROM
+          cast
+        WHERE
+          artist_id = a.id
+        UNION
+        SELECT
+          movie_id
+        FROM
+          crew
+        WHERE
+          artist_id = a.id
+      )
+  ) AS movie_count
+FROM
+  artist a
+WHERE
+  a.name LIKE '%' || CAST(sqlc.arg(query) AS TEXT) || '%' ESCAPE '\'
+  AND a.id IN (
+    SELECT
+      artist_id
+    FROM
+      cast
+    UNION
+    SELECT
+      artist_id
+    FROM
+      crew
+  )
+ORDER BY
+  movie_count DESC,
+  a.name COLLATE NOCASE
+LIMIT
+  ?
+OFFSET
+  ?;
+
+-- name: SearchArtistsCount :one
+SELECT
+  COUNT(*)
+FROM
+  artist a
+WHERE
+  a.name LIKE '%' || CAST(sqlc.arg(query) AS TEXT) || '%' ESCAPE '\'
+  AND a.id IN (
+    SELECT
+      artist_id
+    FROM
+      cast
+    UNION
+    SELECT
+      artist_id
+    FROM
+      crew
+  );
@@ -0,0 +1,10 @@
+-- name: UpsertPodcastEpisodeProgress :one
+INSERT INTO
+  podcast_episode_progress (user_id, episode_id, position, played)
+VALUES
+  (?, ?, ?, ?) ON CONFLICT (user_id, episode_id) DO
+UPDATE
+SET
+  position = excluded.position,
+  played = excluded.played,
+  updated_at = CURRENT_TIMESTAMP RETURNING *;
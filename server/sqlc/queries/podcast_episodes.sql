@@ -0,0 +1,137 @@
+-- name: ClearPodcastEpisodeFile :exec
+-- Forgets a deleted download, downloaded_at is kept so it isn't downloaded again.
+UPDATE podcast_episodes
+SET
+  file_path = NULL,
+  file_size = 0,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: GetDownloadedPodcastEpisodes :many
+-- Returns the downloaded episodes of a podcast, newest first, with how many users
+-- played them and how many are still listening.
+SELECT
+  e.*,
+  (
+    SELECT
+      COUNT(*)
+    FROM
+      podcast_episode_progress pr
+    WHERE
+      pr.episode_id = e.id
+      AND pr.played
+  ) AS played_count,
+  (
+    SELECT
+      COUNT(*)
+    FROM
+      podcast_episode_progress pr
+    WHERE
+      pr.episode_id = e.id
+      AND NOT pr.played
+      AND pr.position > 0
+  ) AS in_progress_count
+FROM
+  podcast_episodes e
+WHERE
+  e.podcast_id = ?
+  AND e.file_path IS NOT NULL
+ORDER BY
+  e.published_at DESC,
+  e.id DESC;
+
+-- name: GetPodcastEpisodeByID :one
+SELECT
+  *
+FROM
+  podcast_episodes
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetPodcastEpisodes :many
+-- Returns a podcast's episodes newest first with the user's progress.
+SELECT
+  e.*,
+  COALESCE(pr.position, 0) AS position,
+  COALESCE(pr.played, false) AS played
+FROM
+  podcast_episodes e
+  LEFT JOIN podcast_episode_progress pr ON pr.episode_id = e.id
+  AND pr.user_id = ?
+WHERE
+  e.podcast_id = ?
+ORDER BY
+  e.published_at DESC,
+  e.id DESC
+LIMIT
+  ?
+OFFSET
+  ?;
+
+-- name: GetPodcastEpisodesCount :one
+SELECT
+  COUNT(*)
+FROM
+  podcast_episodes
+WHERE
+  podcast_id = ?;
+
+-- name: GetPodcastEpisodesToDownload :many
+-- Returns the episodes among the podcast's newest that were never downloaded.
+SELECT
+  *
+FROM
+  podcast_episodes e
+WHERE
+  e.podcast_id = ?
+  AND e.downloaded_at IS NULL
+  AND (
+    SELECT
+      COUNT(*)
+    FROM
+      podcast_episodes n
+    WHERE
+      n.podcast_id = e.podcast_id
+      AND n.published_at > e.published_at
+  ) < ?
+ORDER BY
+  e.published_at DESC;
+
+-- name: SetPodcastEpisodeFile :exec
+UPDATE podcast_episodes
+SET
+  file_path = ?,
+  file_size = ?,
+  downloaded_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ?;
+
+-- name: UpsertPodcastEpisode :exec
+INSERT INTO
+  podcast_episodes (
+    podcast_id,
+    guid,
+    title,
+    description,
+    published_at,
+    duration,
+    enclosure_url,
+    enclosure_type,
+    enclosure_size
+  )
+VALUES
+  (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (podcast_id, guid) DO
+UPDATE
+SET
+  title = excluded.title,
+  description = excluded.description,
+  published_at = excluded.published_at,
+  duration = excluded.duration,
+  enclosure_url = excluded.enclosure_url,
+  enclosure_type = excluded.enclosure_type,
+  enclosure_size = excluded.enclosure_size,
+  updated_at = CURRENT_TIMESTAMP;
@@ -0,0 +1,93 @@
+-- name: CreatePodcast :one
+INSERT INTO
+  podcasts (feed_url, title)
+VALUES
+  (?, ?) RETURNING *;
+
+-- name: DeletePodcast :exec
+DELETE FROM podcasts
+WHERE
+  id = ?;
+
+-- name: GetPodcastByFeedURL :one
+SELECT
+  *
+FROM
+  podcasts
+WHERE
+  feed_url = ?
+LIMIT
+  1;
+
+-- name: GetPodcastByID :one
+SELECT
+  *
+FROM
+  podcasts
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetPodcasts :many
+-- Returns the subscribed podcasts sorted by title, with their episode counts.
+SELECT
+  p.*,
+  COUNT(e.id) AS episode_count
+FROM
+  podcasts p
+  LEFT JOIN podcast_episodes e ON e.podcast_id = p.id
+GROUP BY
+  p.id
+ORDER BY
+  p.title COLLATE NOCASE;
+
+-- name: GetPodcastsDueForPoll :many
+-- Returns the podcasts never polled or last polled before the cutoff, oldest first.
+SELECT
+  *
+FROM
+  podcasts
+WHERE
+  last_polled_at IS NULL
+  OR last_polled_at < CAST(sqlc.arg(cutoff) AS TEXT)
+ORDER BY
+  last_polled_at;
+
+-- name: UpdatePodcastDownloadSettings :one
+UPDATE podcasts
+SET
+  auto_download = ?,
+  keep_episodes = ?,
+  keep_days = ?,
+  delete_played = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdatePodcastFeed :one
+-- Stores a successfully fetched feed with the validators for the next conditional request.
+UPDATE podcasts
+SET
+  title = ?,
+  description = ?,
+  author = ?,
+  image_url = ?,
+  link = ?,
+  language = ?,
+  etag = ?,
+  last_modified = ?,
+  last_polled_at = CURRENT_TIMESTAMP,
+  last_error = NULL,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdatePodcastPolled :exec
+-- Records a poll that didn't change the feed: unmodified, or failed with last_error.
+UPDATE podcasts
+SET
+  last_polled_at = CURRENT_TIMESTAMP,
+  last_error = ?
+WHERE
+  id = ?;
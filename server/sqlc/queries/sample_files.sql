@@ -0,0 +1,27 @@
+-- name: CheckSampleFileUnchanged :one
+-- Quick check if a file was skipped as a sample with the same size (likely unchanged)
+SELECT
+  1
+FROM
+  sample_files
+WHERE
+  file_path = ?
+  AND size = ?
+LIMIT
+  1;
+
+-- name: DeleteSampleFilesByLibrary :exec
+-- Forgets the samples of a library, so they are probed again against a new minimum duration.
+DELETE FROM sample_files
+WHERE
+  library = ?;
+
+-- name: RecordSampleFile :exec
+INSERT INTO
+  sample_files (file_path, library, size)
+VALUES
+  (?, ?, ?) ON CONFLICT (file_path) DO
+UPDATE
+SET
+  library = excluded.library,
+  size = excluded.size;
@@ -0,0 +1,55 @@
+-- name: RecordScanError :exec
+-- Records a failed file, counting the attempts since it first failed.
+INSERT INTO
+  scan_errors (file_path, library, phase, error)
+VALUES
+  (?, ?, ?, ?) ON CONFLICT (file_path) DO
+UPDATE
+SET
+  library = excluded.library,
+  phase = excluded.phase,
+  error = excluded.error,
+  attempts = scan_errors.attempts + 1,
+  last_seen_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteScanError :exec
+-- Clears a file's entry once it scans successfully.
+DELETE FROM scan_errors
+WHERE
+  file_path = ?;
+
+-- name: GetScanErrorByID :one
+SELECT
+  *
+FROM
+  scan_errors
+WHERE
+  id = ?
+LIMIT
+  1;
+
+-- name: GetScanErrors :many
+-- Returns the most recent failures first. An empty library returns both libraries.
+SELECT
+  *
+FROM
+  scan_errors
+WHERE
+  sqlc.arg(library) = ''
+  OR library = sqlc.arg(library)
+ORDER BY
+  last_seen_at DESC,
+  id DESC
+LIMIT
+  ?
+OFFSET
+  ?;
+
+-- name: GetScanErrorsCount :one
+SELECT
+  COUNT(*)
+FROM
+  scan_errors
+WHERE
+  sqlc.arg(library) = ''
+  OR library = sqlc.arg(library);
@@ -20,8 +20,77 @@ INSERT INTO
     movies_dir,
     shows_dir,
     music_dir,
+    audiobooks_dir,
+    podcasts_dir,
     static_dir,
     logs_dir
   )
 VALUES
-  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
\ No newline at end of file
+  (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
+
+-- name: BackfillLibraryDirs :one
+-- Sets the library folders added after the settings were created from the
+-- environment, keeping the ones already set.
+UPDATE settings
+SET
+  audiobooks_dir = COALESCE(audiobooks_dir, ?),
+  podcasts_dir = COALESCE(podcasts_dir, ?),
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdateScannerSettings :one
+UPDATE settings
+SET
+  movies_ignore_patterns = ?,
+  music_ignore_patterns = ?,
+  audiobooks_ignore_patterns = ?,
+  movies_min_size = ?,
+  movies_min_duration = ?,
+  music_min_size = ?,
+  music_min_duration = ?,
+  movies_metadata_providers = ?,
+  various_artists_name = ?,
+  music_spotify_enrichment = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdateMetadataLanguageSettings :one
+UPDATE settings
+SET
+  metadata_language = ?,
+  metadata_fallback_language = ?,
+  certification_country = ?,
+  movies_metadata_language = ?,
+  movies_metadata_fallback_language = ?,
+  movies_certification_country = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdateMetadataRefreshSettings :one
+UPDATE settings
+SET
+  metadata_refresh_days = ?,
+  metadata_refresh_rate = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: SetCollectionsBackfilled :one
+UPDATE settings
+SET
+  collections_backfilled_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
+
+-- name: UpdatePodcastSettings :one
+UPDATE settings
+SET
+  podcast_poll_minutes = ?,
+  podcasts_dir = ?,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  id = ? RETURNING *;
@@ -6,11 +6,6 @@ ON CONFLICT (track_id, genre_id) DO NOTHING;
 -- name: DeleteTrackGenres :exec
 DELETE FROM track_genres WHERE track_id = ?;
 
--- name: DeleteTrackGenresExcept :exec
--- Deletes all genre relationships for a track except the specified genre.
--- Used to efficiently update genres: only removes stale relationships.
-DELETE FROM track_genres WHERE track_id = ? AND genre_id != ?;
-
 -- name: GetGenresByAlbumID :many
 SELECT
   tg.track_id,
@@ -0,0 +1,69 @@
+-- name: GetTrackLyrics :one
+SELECT
+  *
+FROM
+  track_lyrics
+WHERE
+  track_id = ?
+LIMIT
+  1;
+
+-- name: CheckLyricsUnchanged :one
+-- Quick check that the lyrics of a track are up to date: they were read after the .lrc
+-- sidecar, if any, was last modified and from the source the file has now, tracks
+-- without lyrics counting as read from their tags. Lyrics entered by a user are never
+-- replaced by a scan.
+SELECT
+  1
+FROM
+  tracks t
+  LEFT JOIN track_lyrics l ON l.track_id = t.id
+WHERE
+  t.file_path = sqlc.arg(file_path)
+  AND (
+    l.source = 'user'
+    OR (
+      t.lyrics_scanned_at >= sqlc.arg(modified_at)
+      AND COALESCE(l.source, 'embedded') = sqlc.arg(lyrics_source)
+    )
+  )
+LIMIT
+  1;
+
+-- name: UpsertScannedLyrics :exec
+-- Stores lyrics read by the scanner unless a user has entered lyrics for the track.
+INSERT INTO
+  track_lyrics (track_id, content, synced, language, source)
+VALUES
+  (?, ?, ?, ?, ?) ON CONFLICT (track_id) DO
+UPDATE
+SET
+  content = excluded.content,
+  synced = excluded.synced,
+  language = excluded.language,
+  source = excluded.source,
+  updated_at = CURRENT_TIMESTAMP
+WHERE
+  track_lyrics.source != 'user';
+
+-- name: DeleteScannedLyrics :exec
+-- Drops scanned lyrics the file no longer has. Lyrics entered by a user are kept.
+DELETE FROM track_lyrics
+WHERE
+  track_id = ?
+  AND source != 'user';
+
+-- name: UpsertUserLyrics :one
+INSERT INTO
+  track_lyrics (track_id, content, synced, language, source)
+VALUES
+  (?, ?, ?, ?, 'user') ON CONFLICT (track_id) DO
+UPDATE
+SET
+  content = excluded.content,
+  synced = excluded.synced,
+  language = excluded.language,
+  source = excluded.source,
+  updated_at = CURRENT_TIMESTAMP
+RETURNING
+  *;
@@ -1,6 +1,21 @@
 -- name: GetTrack :one
 SELECT * FROM tracks WHERE id = ? LIMIT 1;
 
+-- name: CheckCueTracksUnchanged :one
+-- Quick check for an audio file split by a CUE sheet: its virtual tracks exist with the
+-- same file size, were scanned after the sheet was last modified and hold no scanned
+-- lyrics from another source than the file has now
+SELECT 1 FROM tracks t WHERE t.source_path = ? AND t.size = ? AND t.updated_at >= ?
+  AND t.lyrics_scanned_at IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM tracks st INNER JOIN track_lyrics l ON l.track_id = st.id
+    WHERE st.source_path = t.source_path AND l.source NOT IN ('user', sqlc.arg(lyrics_source))
+  )
+LIMIT 1;
+
+-- name: SetTrackLyricsScanned :exec
+UPDATE tracks SET lyrics_scanned_at = CURRENT_TIMESTAMP WHERE id = ?;
+
 -- name: CheckTrackUnchanged :one
 -- Quick check if track exists with same path and size (likely unchanged)
 SELECT 1 FROM tracks WHERE file_path = ? AND size = ? LIMIT 1;
@@ -14,11 +29,12 @@ SELECT file_path, size FROM tracks;
 INSERT INTO tracks (
   title, sort_title, file_path, file_name, container, mime_type, codec, size,
   track_index, duration, disc, channels, channel_layout, bit_rate, profile,
-  release_date, year, composer, copyright, language, album_id, musician_id
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+  release_date, year, composer, copyright, language, album_id, musician_id,
+  source_path, start_offset, end_offset, musicbrainz_track_id
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (file_path) DO UPDATE SET
-  title = excluded.title,
-  sort_title = excluded.sort_title,
+  title = CASE WHEN 'title' IN (SELECT value FROM json_each(tracks.locked_fields)) THEN tracks.title ELSE excluded.title END,
+  sort_title = CASE WHEN 'sort_title' IN (SELECT value FROM json_each(tracks.locked_fields)) THEN tracks.sort_title ELSE excluded.sort_title END,
   file_name = excluded.file_name,
   container = excluded.container,
   mime_type = excluded.mime_type,
@@ -32,15 +48,57 @@ ON CONFLICT (file_path) DO UPDATE SET
   bit_rate = excluded.bit_rate,
   profile = excluded.profile,
   release_date = COALESCE(excluded.release_date, tracks.release_date),
-  year = COALESCE(excluded.year, tracks.year),
+  year = CASE WHEN 'year' IN (SELECT value FROM json_each(tracks.locked_fields)) THEN tracks.year ELSE COALESCE(excluded.year, tracks.year) END,
   composer = COALESCE(excluded.composer, tracks.composer),
   copyright = COALESCE(excluded.copyright, tracks.copyright),
   language = COALESCE(excluded.language, tracks.language),
   album_id = COALESCE(excluded.album_id, tracks.album_id),
   musician_id = COALESCE(excluded.musician_id, tracks.musician_id),
+  source_path = excluded.source_path,
+  start_offset = excluded.start_offset,
+  end_offset = excluded.end_offset,
+  musicbrainz_track_id = excluded.musicbrainz_track_id,
   updated_at = CURRENT_TIMESTAMP
 RETURNING *;
 
+-- name: GetAllTrackScrobbleInfo :many
+-- The scrobble info of every track, to match listens of an imported history.
+SELECT
+  t.id,
+  t.title,
+  t.duration,
+  t.track_index,
+  t.musicbrainz_track_id,
+  m.name AS artist,
+  a.title AS album,
+  a.musician AS album_artist,
+  m.musicbrainz_id AS artist_mbid,
+  a.musicbrainz_album_id AS album_mbid
+FROM
+  tracks t
+  LEFT JOIN musicians m ON m.id = t.musician_id
+  LEFT JOIN albums a ON a.id = t.album_id;
+
+-- name: GetTrackScrobbleInfo :one
+-- What scrobbling services are told about a track: its artist, album and album artist,
+-- and their MusicBrainz IDs.
+SELECT
+  t.title,
+  t.duration,
+  t.track_index,
+  t.musicbrainz_track_id,
+  m.name AS artist,
+  a.title AS album,
+  a.musician AS album_artist,
+  m.musicbrainz_id AS artist_mbid,
+  a.musicbrainz_album_id AS album_mbid
+FROM
+  tracks t
+  LEFT JOIN musicians m ON m.id = t.musician_id
+  LEFT JOIN albums a ON a.id = t.album_id
+WHERE
+  t.id = ?;
+
 -- name: GetTracksByAlbumID :many
 SELECT
   *
@@ -60,6 +118,7 @@ SELECT
   t.codec,
   t.bit_rate,
   t.file_path,
+  t.musicbrainz_track_id,
   a.id as album_id,
   a.title as album_title,
   a.cover as album_cover,
@@ -94,6 +153,7 @@ SELECT
   t.duration,
   t.codec,
   t.bit_rate,
+  t.musicbrainz_track_id,
   a.id AS album_id,
   a.title AS album_title,
   a.cover AS album_cover,
@@ -104,3 +164,25 @@ LEFT JOIN albums a ON t.album_id = a.id
 LEFT JOIN musicians m ON t.musician_id = m.id
 ORDER BY RANDOM()
 LIMIT ?;
+
+-- name: UpdateTrackMetadata :one
+-- Applies a hand edit. The caller passes every editable field and the new lock set.
+UPDATE tracks
+SET title = ?, sort_title = ?, year = ?, locked_fields = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteCueTracks :exec
+-- Removes the virtual tracks of an audio file that is no longer split by a CUE sheet
+DELETE FROM tracks WHERE source_path = ?;
+
+-- name: DeleteStaleCueTracks :exec
+-- Removes the virtual tracks of an audio file that are no longer in its CUE sheet.
+-- file_paths is a JSON array of the file paths of the tracks still in the sheet.
+DELETE FROM tracks
+WHERE source_path = sqlc.arg(source_path)
+  AND file_path NOT IN (SELECT value FROM json_each(sqlc.arg(file_paths)));
+
+-- name: DeleteTrackByFilePath :exec
+-- Removes a track indexed as a whole file before a CUE sheet split it
+DELETE FROM tracks WHERE file_path = ?;
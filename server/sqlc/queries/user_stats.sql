@@ -17,6 +17,31 @@ ON CONFLICT (user_id, track_id) DO UPDATE SET
     last_played_at = CURRENT_TIMESTAMP,
     updated_at = CURRENT_TIMESTAMP;
 
+-- name: ImportPlayEvent :exec
+-- Records a play of an imported listening history at the time it happened
+INSERT INTO user_play_history (user_id, track_id, played_at, duration_played, completed)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetUserPlayTimes :many
+-- Returns when the user played each track, to skip plays imported before
+SELECT track_id, played_at
+FROM user_play_history
+WHERE user_id = ?;
+
+-- name: RebuildUserTrackStats :exec
+-- Recomputes the user's aggregated stats from the play history, e.g. after an import
+INSERT INTO user_track_stats (user_id, track_id, play_count, total_time_played, last_played_at, first_played_at)
+SELECT user_id, track_id, COUNT(*), SUM(duration_played), MAX(played_at), MIN(played_at)
+FROM user_play_history
+WHERE user_id = ?
+GROUP BY user_id, track_id
+ON CONFLICT (user_id, track_id) DO UPDATE SET
+    play_count = excluded.play_count,
+    total_time_played = excluded.total_time_played,
+    last_played_at = excluded.last_played_at,
+    first_played_at = excluded.first_played_at,
+    updated_at = CURRENT_TIMESTAMP;
+
 -- ============================================================================
 -- USER STATISTICS QUERIES
 -- ============================================================================
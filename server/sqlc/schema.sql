@@ -30,8 +30,42 @@ CREATE TABLE
     movies_dir TEXT,
     shows_dir TEXT,
     music_dir TEXT,
+    audiobooks_dir TEXT,
+    podcasts_dir TEXT,
     static_dir TEXT NOT NULL DEFAULT 'static',
     logs_dir TEXT NOT NULL DEFAULT 'logs',
+    -- scanner ignore rules: newline-separated gitignore-style patterns, and thresholds
+    -- (bytes, seconds; 0 disables) below which files are treated as samples
+    movies_ignore_patterns TEXT,
+    music_ignore_patterns TEXT,
+    audiobooks_ignore_patterns TEXT,
+    movies_min_size INTEGER NOT NULL DEFAULT 0,
+    movies_min_duration INTEGER NOT NULL DEFAULT 0,
+    music_min_size INTEGER NOT NULL DEFAULT 0,
+    music_min_duration INTEGER NOT NULL DEFAULT 0,
+    -- comma-separated movie metadata providers (nfo, tmdb, embedded) in priority order
+    movies_metadata_providers TEXT NOT NULL DEFAULT 'nfo,tmdb,embedded',
+    -- metadata locale: language of titles and overviews, the language filling untranslated
+    -- texts (empty disables it) and the country whose age ratings are preferred
+    metadata_language TEXT NOT NULL DEFAULT 'en-US',
+    metadata_fallback_language TEXT NOT NULL DEFAULT 'en-US',
+    certification_country TEXT NOT NULL DEFAULT 'US',
+    -- movies library overrides of the metadata locale, NULL keeps the global value
+    movies_metadata_language TEXT,
+    movies_metadata_fallback_language TEXT,
+    movies_certification_country TEXT,
+    -- metadata refresh: days before TMDB/Spotify data is re-fetched (0 disables the
+    -- periodic job) and the provider requests allowed per minute
+    metadata_refresh_days INTEGER NOT NULL DEFAULT 30,
+    metadata_refresh_rate INTEGER NOT NULL DEFAULT 30,
+    -- pseudo-musician compilation albums are filed under
+    various_artists_name TEXT NOT NULL DEFAULT 'Various Artists',
+    -- whether the music scanner and the metadata refresh look musicians and albums up on Spotify
+    music_spotify_enrichment BOOLEAN NOT NULL DEFAULT true,
+    -- minutes between podcast feed polls (0 disables polling)
+    podcast_poll_minutes INTEGER NOT NULL DEFAULT 60,
+    -- when the movies scanned before collections were supported were linked to theirs
+    collections_backfilled_at TEXT DEFAULT CURRENT_TIMESTAMP,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
   );
@@ -40,19 +74,34 @@ CREATE TABLE
 CREATE TABLE
   IF NOT EXISTS musicians (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
-    name TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
     sort_name TEXT NOT NULL,
     summary TEXT,
     spotify_popularity REAL,
     spotify_followers INTEGER,
     spotify_id TEXT UNIQUE,
     thumb TEXT,
+    -- the tag name the scanner knows a renamed musician by (NULL until the name is edited)
+    scan_name TEXT,
+    -- JSON array of hand-edited fields that scans and metadata refreshes leave alone
+    locked_fields TEXT NOT NULL DEFAULT '[]',
+    -- last metadata refresh from Spotify (NULL until the first one, created_at counts instead)
+    metadata_refreshed_at TEXT,
+    -- MusicBrainz artist id from the tags. Musicians sharing a name are told apart by it,
+    -- so names are only unique among musicians without one (idx_musician_name_unmatched)
+    musicbrainz_id TEXT,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
   );
 
 CREATE INDEX IF NOT EXISTS idx_musician_name ON musicians (name);
 
+CREATE UNIQUE INDEX IF NOT EXISTS idx_musician_name_unmatched ON musicians (name)
+WHERE
+  musicbrainz_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_musician_musicbrainz_id ON musicians (musicbrainz_id);
+
 -- albums
 CREATE TABLE
   IF NOT EXISTS albums (
@@ -66,13 +115,37 @@ CREATE TABLE
     year INTEGER,
     total_tracks INTEGER,
     cover TEXT,
+    -- the tag title the scanner knows a renamed album by (NULL until the title is edited)
+    scan_title TEXT,
+    -- JSON array of hand-edited fields that scans and metadata refreshes leave alone
+    locked_fields TEXT NOT NULL DEFAULT '[]',
+    -- compilations are filed under the Various Artists pseudo-musician
+    is_compilation BOOLEAN NOT NULL DEFAULT 0,
+    -- albums without an album artist are told apart by the folder holding their tracks
+    directory TEXT,
+    -- MusicBrainz release and release group ids from the tags. Editions sharing a title and
+    -- album artist are told apart by the release id, so that pair is only unique among
+    -- albums without one (idx_album_title_musician_unmatched)
+    musicbrainz_album_id TEXT,
+    musicbrainz_release_group_id TEXT,
+    -- confidence (0 to 1) of the Spotify match the scanner made, or of the best candidate
+    -- it rejected. Matches made by hand have none and are locked, scans keep them
+    spotify_match_score REAL,
+    spotify_match_locked BOOLEAN NOT NULL DEFAULT 0,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    UNIQUE (title, musician)
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
   );
 
 CREATE INDEX IF NOT EXISTS idx_album_title ON albums (title);
 
+CREATE UNIQUE INDEX IF NOT EXISTS idx_album_title_musician_unmatched ON albums (title, musician)
+WHERE
+  musicbrainz_album_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_album_musicbrainz_album_id ON albums (musicbrainz_album_id);
+
+CREATE INDEX IF NOT EXISTS idx_album_directory ON albums (directory, title);
+
 -- tracks
 CREATE TABLE
   IF NOT EXISTS tracks (
@@ -81,9 +154,30 @@ CREATE TABLE
     sort_title TEXT NOT NULL,
     file_path TEXT NOT NULL UNIQUE,
     file_name TEXT NOT NULL,
-    container TEXT NOT NULL CHECK (container IN ('mp3', 'flac', 'm4a')),
+    container TEXT NOT NULL CHECK (
+      container IN (
+        'mp3',
+        'flac',
+        'm4a',
+        'ogg',
+        'opus',
+        'wav',
+        'aiff',
+        'wv',
+        'ape'
+      )
+    ),
     mime_type TEXT NOT NULL CHECK (
-      mime_type IN ('audio/mpeg', 'audio/flac', 'audio/mp4')
+      mime_type IN (
+        'audio/mpeg',
+        'audio/flac',
+        'audio/mp4',
+        'audio/ogg',
+        'audio/wav',
+        'audio/aiff',
+        'audio/x-wavpack',
+        'audio/x-ape'
+      )
     ),
     codec TEXT NOT NULL,
     size INTEGER NOT NULL,
@@ -101,6 +195,18 @@ CREATE TABLE
     language TEXT,
     album_id INTEGER,
     musician_id INTEGER,
+    -- JSON array of hand-edited fields that scans and metadata refreshes leave alone
+    locked_fields TEXT NOT NULL DEFAULT '[]',
+    -- CUE sheet virtual tracks: the audio file the track is cut from and its range in
+    -- milliseconds (end_offset NULL plays to the end of the file). NULL for regular tracks.
+    source_path TEXT,
+    start_offset INTEGER,
+    end_offset INTEGER,
+    -- MusicBrainz recording id from the tags
+    musicbrainz_track_id TEXT,
+    -- last time the scanner read the lyrics of the file, NULL for tracks scanned
+    -- before lyrics were supported
+    lyrics_scanned_at TEXT,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     FOREIGN KEY (album_id) REFERENCES albums (id) ON DELETE CASCADE ON UPDATE CASCADE,
@@ -113,6 +219,10 @@ CREATE INDEX IF NOT EXISTS idx_track_album ON tracks (album_id);
 
 CREATE INDEX IF NOT EXISTS idx_track_musician ON tracks (musician_id);
 
+CREATE INDEX IF NOT EXISTS idx_track_source_path ON tracks (source_path);
+
+CREATE INDEX IF NOT EXISTS idx_track_musicbrainz_track_id ON tracks (musicbrainz_track_id);
+
 -- movies
 CREATE TABLE
   IF NOT EXISTS movies (
@@ -121,7 +231,20 @@ CREATE TABLE
     file_path TEXT NOT NULL UNIQUE,
     file_name TEXT NOT NULL,
     size INTEGER NOT NULL,
-    container TEXT NOT NULL CHECK (container IN ('mkv', 'mp4', 'avi', 'webm')),
+    container TEXT NOT NULL CHECK (
+      container IN (
+        'mkv',
+        'webm',
+        'mp4',
+        'm4v',
+        'mov',
+        'avi',
+        'ts',
+        'm2ts',
+        'wmv',
+        'mpg'
+      )
+    ),
     mime_type TEXT NOT NULL,
     adult BOOLEAN NOT NULL,
     tmdb_id INTEGER,
@@ -139,6 +262,16 @@ CREATE TABLE
     revenue REAL,
     budget REAL,
     run_time INTEGER,
+    -- set by a manual fix-match: scans re-fetch this tmdb_id instead of searching by file name
+    match_locked BOOLEAN NOT NULL DEFAULT 0,
+    -- JSON array of hand-edited fields that scans and metadata refreshes leave alone
+    locked_fields TEXT NOT NULL DEFAULT '[]',
+    -- last metadata refresh from TMDB (NULL until the first one, created_at counts instead)
+    metadata_refreshed_at TEXT,
+    -- title in the original language when title holds a translation
+    original_title TEXT,
+    -- hand-set title to sort by, NULL sorts by title
+    sort_title TEXT,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
   );
@@ -149,6 +282,26 @@ CREATE INDEX IF NOT EXISTS idx_movies_tmdb_id ON movies (tmdb_id);
 
 CREATE INDEX IF NOT EXISTS idx_movies_imdb_id ON movies (imdb_id);
 
+-- media_versions
+-- One row per file of a movie. Copies of the same film (4K HDR and 1080p, a Director's Cut
+-- and the Theatrical cut) share one movies row; the movies file columns mirror the first version.
+CREATE TABLE
+  IF NOT EXISTS media_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    movie_id INTEGER NOT NULL,
+    file_path TEXT NOT NULL UNIQUE,
+    file_name TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    container TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    edition TEXT,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_media_versions_movie ON media_versions (movie_id);
+
 -- production_companies
 CREATE TABLE
   IF NOT EXISTS production_companies (
@@ -185,6 +338,22 @@ CREATE TABLE
     UNIQUE (tag, genre_type)
   );
 
+-- genre_aliases maps a normalized genre key (e.g. "hiphop") to its canonical genre,
+-- so spelling variants such as "Hip-Hop", "Hip Hop" and "hiphop" resolve to one genres row.
+CREATE TABLE
+  IF NOT EXISTS genre_aliases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    alias TEXT NOT NULL,
+    genre_type TEXT NOT NULL CHECK (genre_type IN ('movie', 'show', 'music')),
+    genre_id INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (alias, genre_type),
+    FOREIGN KEY (genre_id) REFERENCES genres (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_genre_aliases_genre ON genre_aliases (genre_id);
+
 -- tables for extras for movies and tv shows
 -- this include trailers, special features and others
 CREATE TABLE
@@ -207,6 +376,7 @@ CREATE TABLE
   IF NOT EXISTS video_streams (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     movie_id INTEGER NOT NULL,
+    media_version_id INTEGER,
     stream_index INTEGER NOT NULL,
     codec TEXT NOT NULL,
     codec_profile TEXT,
@@ -228,18 +398,22 @@ CREATE TABLE
     title TEXT,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE
+    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (media_version_id) REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE
   );
 
 CREATE INDEX IF NOT EXISTS idx_video_streams_movie ON video_streams (movie_id);
 
 CREATE INDEX IF NOT EXISTS idx_video_streams_index ON video_streams (movie_id, stream_index);
 
+CREATE INDEX IF NOT EXISTS idx_video_streams_version ON video_streams (media_version_id, stream_index);
+
 -- audio_streams
 CREATE TABLE
   IF NOT EXISTS audio_streams (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     movie_id INTEGER NOT NULL,
+    media_version_id INTEGER,
     stream_index INTEGER NOT NULL,
     codec TEXT NOT NULL,
     codec_profile TEXT,
@@ -251,13 +425,16 @@ CREATE TABLE
     title TEXT,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE
+    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (media_version_id) REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE
   );
 
 CREATE INDEX IF NOT EXISTS idx_audio_streams_movie ON audio_streams (movie_id);
 
 CREATE INDEX IF NOT EXISTS idx_audio_streams_index ON audio_streams (movie_id, stream_index);
 
+CREATE INDEX IF NOT EXISTS idx_audio_streams_version ON audio_streams (media_version_id, stream_index);
+
 CREATE INDEX IF NOT EXISTS idx_audio_streams_language ON audio_streams (movie_id, language);
 
 -- subtitles
@@ -265,6 +442,7 @@ CREATE TABLE
   IF NOT EXISTS subtitles (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     movie_id INTEGER NOT NULL,
+    media_version_id INTEGER,
     stream_index INTEGER NOT NULL,
     codec TEXT NOT NULL,
     language TEXT,
@@ -273,13 +451,16 @@ CREATE TABLE
     is_default BOOLEAN NOT NULL DEFAULT false,
     created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE
+    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (media_version_id) REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE
   );
 
 CREATE INDEX IF NOT EXISTS idx_subtitles_movie ON subtitles (movie_id);
 
 CREATE INDEX IF NOT EXISTS idx_subtitles_index ON subtitles (movie_id, stream_index);
 
+CREATE INDEX IF NOT EXISTS idx_subtitles_version ON subtitles (media_version_id, stream_index);
+
 CREATE INDEX IF NOT EXISTS idx_subtitles_language ON subtitles (movie_id, language);
 
 -- chapters
@@ -290,7 +471,9 @@ CREATE TABLE
     start_time INTEGER NOT NULL,
     thumb TEXT,
     movie_id INTEGER,
-    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE
+    media_version_id INTEGER,
+    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (media_version_id) REFERENCES media_versions (id) ON DELETE CASCADE ON UPDATE CASCADE
   );
 
 -- cast
@@ -381,6 +564,38 @@ CREATE INDEX IF NOT EXISTS idx_movie_extra_videos_movie ON movie_extra_videos (m
 
 CREATE INDEX IF NOT EXISTS idx_movie_extra_videos_extra ON movie_extra_videos (extra_video_id);
 
+-- local_extras: extras ripped next to a movie, either in an extras folder (Featurettes/,
+-- Behind The Scenes/, Deleted Scenes/, Trailers/...) or named with a suffix such as -trailer.
+CREATE TABLE
+  IF NOT EXISTS local_extras (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    movie_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (
+      type IN (
+        'trailer',
+        'featurette',
+        'behind_the_scenes',
+        'deleted_scene',
+        'interview',
+        'scene',
+        'short',
+        'other'
+      )
+    ),
+    file_path TEXT NOT NULL UNIQUE,
+    file_name TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    container TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    duration INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_local_extras_movie ON local_extras (movie_id);
+
 -- musician_genres
 CREATE TABLE
   IF NOT EXISTS musician_genres (
@@ -429,6 +644,21 @@ CREATE INDEX IF NOT EXISTS idx_track_genres_track ON track_genres (track_id);
 
 CREATE INDEX IF NOT EXISTS idx_track_genres_genre ON track_genres (genre_id);
 
+-- track_lyrics: one set of lyrics per track, read from the file or a .lrc sidecar, or
+-- entered by a user. Scans never overwrite lyrics with source 'user'.
+CREATE TABLE
+  IF NOT EXISTS track_lyrics (
+    track_id INTEGER PRIMARY KEY,
+    -- LRC when synced, plain text otherwise
+    content TEXT NOT NULL,
+    synced BOOLEAN NOT NULL DEFAULT false,
+    language TEXT,
+    source TEXT NOT NULL CHECK (source IN ('embedded', 'sidecar', 'user')),
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (track_id) REFERENCES tracks (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
 -- album_genres
 CREATE TABLE
   IF NOT EXISTS album_genres (
@@ -564,4 +794,341 @@ CREATE INDEX IF NOT EXISTS idx_user_track_stats_user ON user_track_stats (user_i
 
 CREATE INDEX IF NOT EXISTS idx_user_track_stats_play_count ON user_track_stats (user_id, play_count DESC);
 
-CREATE INDEX IF NOT EXISTS idx_user_track_stats_last_played ON user_track_stats (user_id, last_played_at DESC);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_user_track_stats_last_played ON user_track_stats (user_id, last_played_at DESC);
+
+-- scan_errors: files that failed to scan, kept until a later scan of the file succeeds
+CREATE TABLE
+  IF NOT EXISTS scan_errors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    file_path TEXT NOT NULL UNIQUE,
+    library TEXT NOT NULL CHECK (library IN ('movies', 'music', 'audiobooks')),
+    -- the scanner step that failed: ffprobe, tmdb, match or db
+    phase TEXT NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    first_seen_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX IF NOT EXISTS idx_scan_errors_library ON scan_errors (library, last_seen_at DESC);
+
+-- sample_files: files skipped for being shorter than their library's minimum duration,
+-- so unchanged ones aren't probed again. Cleared when the minimum duration changes.
+CREATE TABLE
+  IF NOT EXISTS sample_files (
+    file_path TEXT PRIMARY KEY,
+    library TEXT NOT NULL CHECK (library IN ('movies', 'music')),
+    size INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+-- authors: audiobook authors, read from the album artist or artist tag
+CREATE TABLE
+  IF NOT EXISTS authors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    sort_name TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+-- audiobooks: a folder of audio files, or a single file such as an m4b, read as one book
+CREATE TABLE
+  IF NOT EXISTS audiobooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    sort_title TEXT NOT NULL,
+    -- the book's folder, or the file itself for single-file books
+    path TEXT NOT NULL UNIQUE,
+    author_id INTEGER,
+    narrator TEXT,
+    series TEXT,
+    series_index REAL,
+    description TEXT,
+    year INTEGER,
+    -- totals over all files: milliseconds and bytes
+    duration INTEGER NOT NULL DEFAULT 0,
+    size INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (author_id) REFERENCES authors (id) ON DELETE SET NULL ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_audiobook_author ON audiobooks (author_id);
+
+CREATE INDEX IF NOT EXISTS idx_audiobook_sort_title ON audiobooks (sort_title);
+
+-- audiobook_files: the audio files of a book in playback order
+CREATE TABLE
+  IF NOT EXISTS audiobook_files (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    audiobook_id INTEGER NOT NULL,
+    file_path TEXT NOT NULL UNIQUE,
+    file_name TEXT NOT NULL,
+    file_index INTEGER NOT NULL,
+    container TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    codec TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    duration INTEGER NOT NULL DEFAULT 0,
+    bit_rate INTEGER NOT NULL DEFAULT 0,
+    -- where the file starts in the book, in milliseconds
+    start_offset INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (audiobook_id) REFERENCES audiobooks (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_audiobook_files_audiobook ON audiobook_files (audiobook_id, file_index);
+
+-- audiobook_chapters: chapter times are milliseconds from the start of the book
+CREATE TABLE
+  IF NOT EXISTS audiobook_chapters (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    audiobook_id INTEGER NOT NULL,
+    file_id INTEGER NOT NULL,
+    chapter_index INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    start_time INTEGER NOT NULL,
+    end_time INTEGER NOT NULL,
+    UNIQUE (audiobook_id, chapter_index),
+    FOREIGN KEY (audiobook_id) REFERENCES audiobooks (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (file_id) REFERENCES audiobook_files (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+-- audiobook_progress: where each user stopped listening, in milliseconds from the start of the book
+CREATE TABLE
+  IF NOT EXISTS audiobook_progress (
+    user_id INTEGER NOT NULL,
+    audiobook_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    finished BOOLEAN NOT NULL DEFAULT false,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, audiobook_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (audiobook_id) REFERENCES audiobooks (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_audiobook_progress_updated ON audiobook_progress (user_id, updated_at DESC);
+
+-- audiobook_bookmarks: positions a user saved in a book, in milliseconds from its start
+CREATE TABLE
+  IF NOT EXISTS audiobook_bookmarks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    audiobook_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (audiobook_id) REFERENCES audiobooks (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_audiobook_bookmarks_user ON audiobook_bookmarks (user_id, audiobook_id);
+
+-- podcasts: subscribed RSS feeds. etag and last_modified are sent back when the feed
+-- is polled so unchanged feeds answer 304 Not Modified
+CREATE TABLE
+  IF NOT EXISTS podcasts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    feed_url TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    description TEXT,
+    author TEXT,
+    image_url TEXT,
+    link TEXT,
+    language TEXT,
+    etag TEXT,
+    last_modified TEXT,
+    last_polled_at TEXT,
+    last_error TEXT,
+    -- downloads: new episodes are downloaded when auto_download is set. Retention keeps
+    -- the newest keep_episodes downloads and those published in the last keep_days
+    -- (0 keeps all), delete_played removes downloads once played and nobody is listening
+    auto_download BOOLEAN NOT NULL DEFAULT false,
+    keep_episodes INTEGER NOT NULL DEFAULT 0,
+    keep_days INTEGER NOT NULL DEFAULT 0,
+    delete_played BOOLEAN NOT NULL DEFAULT false,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX IF NOT EXISTS idx_podcasts_polled ON podcasts (last_polled_at);
+
+-- podcast_episodes: published_at is UTC "YYYY-MM-DD HH:MM:SS" and duration is in
+-- milliseconds. downloaded_at stays set when retention deletes the file, so the
+-- episode isn't downloaded again automatically
+CREATE TABLE
+  IF NOT EXISTS podcast_episodes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    podcast_id INTEGER NOT NULL,
+    guid TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    published_at TEXT,
+    duration INTEGER NOT NULL DEFAULT 0,
+    enclosure_url TEXT NOT NULL,
+    enclosure_type TEXT NOT NULL DEFAULT '',
+    enclosure_size INTEGER NOT NULL DEFAULT 0,
+    file_path TEXT,
+    file_size INTEGER NOT NULL DEFAULT 0,
+    downloaded_at TEXT,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (podcast_id, guid),
+    FOREIGN KEY (podcast_id) REFERENCES podcasts (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_podcast_episodes_published ON podcast_episodes (podcast_id, published_at DESC);
+
+-- podcast_episode_progress: where each user stopped listening, in milliseconds
+CREATE TABLE
+  IF NOT EXISTS podcast_episode_progress (
+    user_id INTEGER NOT NULL,
+    episode_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    played BOOLEAN NOT NULL DEFAULT false,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, episode_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (episode_id) REFERENCES podcast_episodes (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+-- api_cache: raw responses of metadata providers (e.g. TMDB) by request, so rescans and
+-- refreshes don't fetch them again. Spotify searches are stored as their JSON result,
+-- null when nothing was found. expires_at is UTC "YYYY-MM-DD HH:MM:SS"
+CREATE TABLE
+  IF NOT EXISTS api_cache (
+    provider TEXT NOT NULL,
+    cache_key TEXT NOT NULL,
+    body BLOB NOT NULL,
+    expires_at TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, cache_key)
+  );
+
+CREATE INDEX IF NOT EXISTS idx_api_cache_expires ON api_cache (expires_at);
+
+-- collections: TMDB franchises like the Star Wars saga. poster_path and backdrop_path
+-- are TMDB image paths like the movies'
+CREATE TABLE
+  IF NOT EXISTS collections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tmdb_id INTEGER NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    overview TEXT,
+    poster_path TEXT,
+    backdrop_path TEXT,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX IF NOT EXISTS idx_collection_name ON collections (name);
+
+-- movie_collections: the collection of a movie, TMDB puts a movie in one at most
+CREATE TABLE
+  IF NOT EXISTS movie_collections (
+    movie_id INTEGER PRIMARY KEY,
+    collection_id INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (movie_id) REFERENCES movies (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (collection_id) REFERENCES collections (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_movie_collections_collection ON movie_collections (collection_id);
+
+-- collection_parts: every movie of a collection on TMDB, owned or not, so the entries
+-- missing from the library can be listed
+CREATE TABLE
+  IF NOT EXISTS collection_parts (
+    collection_id INTEGER NOT NULL,
+    tmdb_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    release_date TEXT,
+    overview TEXT,
+    poster_path TEXT,
+    PRIMARY KEY (collection_id, tmdb_id),
+    FOREIGN KEY (collection_id) REFERENCES collections (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+-- lastfm_sessions: the Last.fm account a user linked through the web auth flow. The
+-- session key doesn't expire, the user revokes it on Last.fm
+CREATE TABLE
+  IF NOT EXISTS lastfm_sessions (
+    user_id INTEGER PRIMARY KEY,
+    username TEXT NOT NULL,
+    session_key TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+-- lastfm_scrobbles: plays waiting to be scrobbled, kept until Last.fm takes them so
+-- none are lost while it is unreachable. The track is copied so later edits or
+-- deletions don't change what was played. duration is in seconds, played_at the Unix
+-- time the play started and next_attempt_at UTC "YYYY-MM-DD HH:MM:SS"
+CREATE TABLE
+  IF NOT EXISTS lastfm_scrobbles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    artist TEXT NOT NULL,
+    track TEXT NOT NULL,
+    album TEXT,
+    album_artist TEXT,
+    track_number INTEGER,
+    duration INTEGER,
+    mbid TEXT,
+    played_at INTEGER NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_lastfm_scrobbles_due ON lastfm_scrobbles (next_attempt_at);
+
+CREATE INDEX IF NOT EXISTS idx_lastfm_scrobbles_user ON lastfm_scrobbles (user_id, played_at);
+
+-- listenbrainz_accounts: the ListenBrainz user token a user entered, and the outcome
+-- of their last history import: when it finished and how many listens it matched to
+-- local tracks or not
+CREATE TABLE
+  IF NOT EXISTS listenbrainz_accounts (
+    user_id INTEGER PRIMARY KEY,
+    username TEXT NOT NULL,
+    token TEXT NOT NULL,
+    imported_at TEXT,
+    imported_listens INTEGER NOT NULL DEFAULT 0,
+    unmatched_listens INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+-- listenbrainz_listens: listens waiting to be submitted, kept until ListenBrainz takes
+-- them so none are lost while it is unreachable. The track and its MusicBrainz IDs are
+-- copied so later edits or deletions don't change what was played. duration is in
+-- milliseconds, listened_at the Unix time the play started and next_attempt_at UTC
+-- "YYYY-MM-DD HH:MM:SS"
+CREATE TABLE
+  IF NOT EXISTS listenbrainz_listens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    artist TEXT NOT NULL,
+    track TEXT NOT NULL,
+    release TEXT,
+    recording_mbid TEXT,
+    release_mbid TEXT,
+    artist_mbid TEXT,
+    track_number INTEGER,
+    duration INTEGER,
+    listened_at INTEGER NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+  );
+
+CREATE INDEX IF NOT EXISTS idx_listenbrainz_listens_due ON listenbrainz_listens (next_attempt_at);
+
+CREATE INDEX IF NOT EXISTS idx_listenbrainz_listens_user ON listenbrainz_listens (user_id, listened_at);
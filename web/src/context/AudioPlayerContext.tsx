@@ -9,6 +9,7 @@ import {
   getShuffleTracks,
   getTracksPaginated,
   recordPlayEvent,
+  sendNowPlaying,
 } from "@/lib/api";
 import {
   convertToAudioTrack,
@@ -18,7 +19,11 @@ import {
 } from "@/lib/audio-utils";
 
 // Play tracking constants
+// A play counts after 30s or 80% completion. It is recorded once half the track or
+// 4 minutes were heard, when Last.fm and ListenBrainz take it, or when it stops
+// before that, so the server judges each play on the time it was heard
 const MINIMUM_PLAY_SECONDS = 30;
+const MAXIMUM_PLAY_SECONDS = 240;
 const COMPLETION_THRESHOLD = 0.8;
 const PLAY_CHECK_INTERVAL_MS = 5000;
 
@@ -67,6 +72,7 @@ export function AudioPlayerProvider({
   // Play tracking refs (using refs to avoid cascading renders)
   const playStartTimeRef = useRef<number | null>(null);
   const hasRecordedPlayRef = useRef(false);
+  const hasSentNowPlayingRef = useRef(false);
   const currentTrackIdRef = useRef<number | null>(null);
 
   // Maps for special playback modes - track ID to album cover and musician name
@@ -184,6 +190,7 @@ export function AudioPlayerProvider({
     if (trackId !== currentTrackIdRef.current) {
       currentTrackIdRef.current = trackId;
       hasRecordedPlayRef.current = false;
+      hasSentNowPlayingRef.current = false;
       playStartTimeRef.current = isPlaying && trackId ? Date.now() : null;
     }
 
@@ -194,12 +201,20 @@ export function AudioPlayerProvider({
       playStartTimeRef.current = null;
     }
 
+    // Tell linked scrobbling services once the track starts playing
+    if (isPlaying && trackId && !hasSentNowPlayingRef.current) {
+      hasSentNowPlayingRef.current = true;
+      sendNowPlaying(trackId).catch(() => {
+        // Silently fail - don't interrupt playback for scrobbling
+      });
+    }
+
     // Don't set up interval if not playing or already recorded
     if (!isPlaying || !trackId || hasRecordedPlayRef.current) {
       return;
     }
 
-    const checkAndRecordPlay = () => {
+    const checkAndRecordPlay = (stopped: boolean) => {
       const audio = audioRef.current;
       const startTime = playStartTimeRef.current;
 
@@ -211,8 +226,14 @@ export function AudioPlayerProvider({
       const progress =
         audio.duration > 0 ? audio.currentTime / audio.duration : 0;
       const isCompleted = progress >= COMPLETION_THRESHOLD;
-
-      if (elapsedSeconds >= MINIMUM_PLAY_SECONDS || isCompleted) {
+      const requiredSeconds = stopped
+        ? MINIMUM_PLAY_SECONDS
+        : Math.min(
+            Math.max(MINIMUM_PLAY_SECONDS, (audio.duration || 0) / 2),
+            MAXIMUM_PLAY_SECONDS
+          );
+
+      if (elapsedSeconds >= requiredSeconds || isCompleted) {
         hasRecordedPlayRef.current = true;
         recordPlayEvent(trackId, Math.floor(elapsedSeconds), isCompleted).catch(
           () => {
@@ -222,8 +243,16 @@ export function AudioPlayerProvider({
       }
     };
 
-    const interval = setInterval(checkAndRecordPlay, PLAY_CHECK_INTERVAL_MS);
-    return () => clearInterval(interval);
+    const interval = setInterval(
+      () => checkAndRecordPlay(false),
+      PLAY_CHECK_INTERVAL_MS
+    );
+    // Pausing or changing the track records a play of at least 30s that wasn't
+    // recorded yet
+    return () => {
+      clearInterval(interval);
+      checkAndRecordPlay(true);
+    };
   }, [isPlaying, state.currentTrack?.id]);
 
   // Play a specific track with a playlist (exits shuffle/play all mode)
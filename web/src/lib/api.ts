@@ -3,8 +3,10 @@ import type {
   AlbumsListResponseType,
   ApiFailureType,
   ApiResponseType,
+  LastfmStatusType,
   LatestMovieType,
   LibraryMovieDetailsMovieType,
+  ListenbrainzStatusType,
   MovieDetailsType,
   MusicianDetailsResponseType,
   MusicStatsType,
@@ -146,6 +148,36 @@ export const deleteUserAccount = () =>
     method: "DELETE",
   });
 
+export const getLastfmStatus = () =>
+  apiRequest<LastfmStatusType>("/api/lastfm");
+
+export const getLastfmAuthURL = () =>
+  apiRequest<{ url: string }>("/api/lastfm/auth");
+
+export const unlinkLastfm = () =>
+  apiRequest("/api/lastfm", {
+    method: "DELETE",
+  });
+
+export const getListenbrainzStatus = () =>
+  apiRequest<ListenbrainzStatusType>("/api/listenbrainz");
+
+export const setListenbrainzToken = (token: string) =>
+  apiRequest("/api/listenbrainz/token", {
+    method: "PUT",
+    body: { token },
+  });
+
+export const unlinkListenbrainz = () =>
+  apiRequest("/api/listenbrainz", {
+    method: "DELETE",
+  });
+
+export const importListenbrainzHistory = () =>
+  apiRequest("/api/listenbrainz/import", {
+    method: "POST",
+  });
+
 // ============================================================================
 // Home Page API
 // ============================================================================
@@ -307,6 +339,12 @@ export const recordPlayEvent = (
     },
   });
 
+export const sendNowPlaying = (trackId: number) =>
+  apiRequest<{ sent: boolean }>("/api/music/user-stats/now-playing", {
+    method: "POST",
+    body: { track_id: trackId },
+  });
+
 export const getUserListeningStats = () =>
   apiRequest<UserListeningStatsResponseType>("/api/music/user-stats/overview");
 
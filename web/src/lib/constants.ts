@@ -17,6 +17,8 @@ export const ALBUMS_PAGINATED_KEY = "albums-paginated";
 export const MUSICIANS_PAGINATED_KEY = "musicians-paginated";
 export const MUSIC_STATS_KEY = "music-stats";
 export const SETTINGS_KEY = "settings";
+export const LASTFM_STATUS_KEY = "lastfm-status";
+export const LISTENBRAINZ_STATUS_KEY = "listenbrainz-status";
 
 // tmdb
 export const TMDB_IMAGE_BASE = "https://image.tmdb.org/t/p";
@@ -3,8 +3,10 @@ import {
   getAlbumDetails,
   getAlbumsPaginated,
   getAuthUser,
+  getLastfmStatus,
   getLatestAlbums,
   getLatestMovies,
+  getListenbrainzStatus,
   getMovieDetails,
   getMovieInTheaterDetails,
   getMoviesInTheaters,
@@ -21,9 +23,11 @@ import {
   ALBUM_DETAILS_KEY,
   ALBUMS_PAGINATED_KEY,
   AUTH_USER_KEY,
+  LASTFM_STATUS_KEY,
   LATEST_ALBUMS_KEY,
   LATEST_MOVIES_KEY,
   LIBRARY_MOVIE_DETAILS_KEY,
+  LISTENBRAINZ_STATUS_KEY,
   MOVIE_DETAILS_KEY,
   MOVIES_IN_THEATERS_KEY,
   MUSICIAN_DETAILS_KEY,
@@ -43,6 +47,20 @@ export function authUserQueryOpts() {
   });
 }
 
+export function lastfmStatusQueryOpts() {
+  return queryOptions({
+    queryKey: [LASTFM_STATUS_KEY],
+    queryFn: getLastfmStatus,
+  });
+}
+
+export function listenbrainzStatusQueryOpts() {
+  return queryOptions({
+    queryKey: [LISTENBRAINZ_STATUS_KEY],
+    queryFn: getListenbrainzStatus,
+  });
+}
+
 export function latestAlbumsQueryOpts() {
   return queryOptions({
     queryKey: [LATEST_ALBUMS_KEY],
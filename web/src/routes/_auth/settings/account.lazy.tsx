@@ -29,10 +29,25 @@ import {
   Upload,
   Trash2,
   AlertTriangle,
+  Radio,
+  Headphones,
 } from "lucide-react";
-import { authUserQueryOpts } from "@/lib/query-opts";
-import { AUTH_USER_KEY } from "@/lib/constants";
 import {
+  authUserQueryOpts,
+  lastfmStatusQueryOpts,
+  listenbrainzStatusQueryOpts,
+} from "@/lib/query-opts";
+import {
+  AUTH_USER_KEY,
+  LASTFM_STATUS_KEY,
+  LISTENBRAINZ_STATUS_KEY,
+} from "@/lib/constants";
+import {
+  getLastfmAuthURL,
+  unlinkLastfm,
+  setListenbrainzToken,
+  unlinkListenbrainz,
+  importListenbrainzHistory,
   updateUserName,
   updateUserPassword,
   updateUserAvatar,
@@ -40,6 +55,7 @@ import {
   deleteUserAccount,
 } from "@/lib/api";
 import { showSuccess, showError, showActionFailed } from "@/lib/toast-helpers";
+import { formatDate } from "@/lib/format";
 import { useNavigate } from "@tanstack/react-router";
 import { logout } from "@/lib/api";
 import type { AuthUser } from "@/types";
@@ -632,6 +648,10 @@ function AccountSettings() {
         </CardContent>
       </Card>
 
+      <LastfmSettings />
+
+      <ListenbrainzSettings />
+
       {/* Danger Zone */}
       <Card className='border-red-500/50 bg-red-950/20'>
         <CardHeader>
@@ -720,3 +740,248 @@ function AccountSettings() {
     </div>
   );
 }
+
+// Links a Last.fm account through Last.fm's web auth flow, which redirects back to
+// this page with ?lastfm=linked or ?lastfm=error
+function LastfmSettings() {
+  const queryClient = useQueryClient();
+  const { data: statusData } = useQuery(lastfmStatusQueryOpts());
+  const status = statusData?.error === false ? statusData.data : null;
+
+  const linkMutation = useMutation({
+    mutationFn: getLastfmAuthURL,
+    onSuccess: res => {
+      if (res.error) {
+        showActionFailed("link Last.fm", res.message);
+        return;
+      }
+      window.location.href = res.data.url;
+    },
+    onError: err => {
+      showActionFailed(
+        "link Last.fm",
+        err instanceof Error ? err.message : "An error occurred",
+      );
+    },
+  });
+
+  const unlinkMutation = useMutation({
+    mutationFn: unlinkLastfm,
+    onSuccess: res => {
+      if (res.error) {
+        showActionFailed("unlink Last.fm", res.message);
+        return;
+      }
+      showSuccess("Last.fm account unlinked");
+      queryClient.invalidateQueries({ queryKey: [LASTFM_STATUS_KEY] });
+    },
+    onError: err => {
+      showActionFailed(
+        "unlink Last.fm",
+        err instanceof Error ? err.message : "An error occurred",
+      );
+    },
+  });
+
+  if (!status || (!status.configured && !status.linked)) {
+    return null;
+  }
+
+  return (
+    <Card className='border-slate-700/50 bg-slate-800/30'>
+      <CardHeader>
+        <CardTitle className='flex items-center gap-2 text-white'>
+          <Radio className='size-5 text-amber-400' aria-hidden='true' />
+          Last.fm
+        </CardTitle>
+        <CardDescription className='text-slate-300'>
+          Scrobble the music you play to your Last.fm profile
+        </CardDescription>
+      </CardHeader>
+      <CardContent className='space-y-4'>
+        {status.linked ? (
+          <>
+            <p className='text-sm text-slate-300'>
+              Linked to <span className='text-white'>{status.username}</span>
+              {status.pending > 0 &&
+                `, ${status.pending} plays waiting to be scrobbled`}
+            </p>
+            <Button
+              onClick={() => unlinkMutation.mutate()}
+              disabled={unlinkMutation.isPending}
+              variant='outline'
+            >
+              {unlinkMutation.isPending ? "Unlinking..." : "Unlink Account"}
+            </Button>
+          </>
+        ) : (
+          <Button
+            onClick={() => linkMutation.mutate()}
+            disabled={linkMutation.isPending}
+            variant='accent'
+          >
+            {linkMutation.isPending ? "Redirecting..." : "Link Account"}
+          </Button>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
+
+// Submits listens with the user token from listenbrainz.org/settings, and imports the
+// listen history once linked. The status is polled while an import runs.
+function ListenbrainzSettings() {
+  const queryClient = useQueryClient();
+  const [token, setToken] = useState("");
+  const { data: statusData } = useQuery({
+    ...listenbrainzStatusQueryOpts(),
+    refetchInterval: query =>
+      query.state.data?.error === false && query.state.data.data.importing
+        ? 5000
+        : false,
+  });
+  const status = statusData?.error === false ? statusData.data : null;
+
+  const invalidateStatus = () =>
+    queryClient.invalidateQueries({ queryKey: [LISTENBRAINZ_STATUS_KEY] });
+
+  const saveTokenMutation = useMutation({
+    mutationFn: (token: string) => setListenbrainzToken(token),
+    onSuccess: res => {
+      if (res.error) {
+        showActionFailed("save ListenBrainz token", res.message);
+        return;
+      }
+      showSuccess("ListenBrainz account linked");
+      setToken("");
+      invalidateStatus();
+    },
+    onError: err => {
+      showActionFailed(
+        "save ListenBrainz token",
+        err instanceof Error ? err.message : "An error occurred",
+      );
+    },
+  });
+
+  const unlinkMutation = useMutation({
+    mutationFn: unlinkListenbrainz,
+    onSuccess: res => {
+      if (res.error) {
+        showActionFailed("unlink ListenBrainz", res.message);
+        return;
+      }
+      showSuccess("ListenBrainz account unlinked");
+      invalidateStatus();
+    },
+    onError: err => {
+      showActionFailed(
+        "unlink ListenBrainz",
+        err instanceof Error ? err.message : "An error occurred",
+      );
+    },
+  });
+
+  const importMutation = useMutation({
+    mutationFn: importListenbrainzHistory,
+    onSuccess: res => {
+      if (res.error) {
+        showActionFailed("import ListenBrainz history", res.message);
+        return;
+      }
+      showSuccess("Importing your ListenBrainz history");
+      invalidateStatus();
+    },
+    onError: err => {
+      showActionFailed(
+        "import ListenBrainz history",
+        err instanceof Error ? err.message : "An error occurred",
+      );
+    },
+  });
+
+  if (!status) {
+    return null;
+  }
+
+  return (
+    <Card className='border-slate-700/50 bg-slate-800/30'>
+      <CardHeader>
+        <CardTitle className='flex items-center gap-2 text-white'>
+          <Headphones className='size-5 text-amber-400' aria-hidden='true' />
+          ListenBrainz
+        </CardTitle>
+        <CardDescription className='text-slate-300'>
+          Submit the music you play to your ListenBrainz profile
+        </CardDescription>
+      </CardHeader>
+      <CardContent className='space-y-4'>
+        {status.linked ? (
+          <>
+            <p className='text-sm text-slate-300'>
+              Linked to <span className='text-white'>{status.username}</span>
+              {status.pending > 0 &&
+                `, ${status.pending} listens waiting to be submitted`}
+            </p>
+            <Button
+              onClick={() => unlinkMutation.mutate()}
+              disabled={unlinkMutation.isPending}
+              variant='outline'
+            >
+              {unlinkMutation.isPending ? "Unlinking..." : "Unlink Account"}
+            </Button>
+
+            <Separator className='bg-slate-700/50' />
+
+            <div className='space-y-2'>
+              <p className='text-sm font-medium text-slate-300'>
+                Listen History
+              </p>
+              <p className='text-xs text-slate-400'>
+                {status.importing
+                  ? "Importing your listens..."
+                  : status.imported_at
+                    ? `Last imported ${formatDate(status.imported_at.replace(" ", "T") + "Z")}: ${status.imported_listens} plays added, ${status.unmatched_listens} listens not in your library`
+                    : "Add the listens of tracks in your library to your play history"}
+              </p>
+              <Button
+                onClick={() => importMutation.mutate()}
+                disabled={importMutation.isPending || status.importing}
+                variant='accent'
+              >
+                {status.importing ? "Importing..." : "Import History"}
+              </Button>
+            </div>
+          </>
+        ) : (
+          <div className='space-y-2'>
+            <Label htmlFor='listenbrainz-token' className='text-slate-300'>
+              User Token
+            </Label>
+            <div className='flex gap-2'>
+              <Input
+                id='listenbrainz-token'
+                type='password'
+                value={token}
+                onChange={e => setToken(e.target.value)}
+                placeholder='Paste your ListenBrainz user token'
+                className='flex-1'
+                aria-label='ListenBrainz user token'
+              />
+              <Button
+                onClick={() => saveTokenMutation.mutate(token.trim())}
+                disabled={saveTokenMutation.isPending || !token.trim()}
+                variant='accent'
+              >
+                {saveTokenMutation.isPending ? "Saving..." : "Save"}
+              </Button>
+            </div>
+            <p className='text-xs text-slate-400'>
+              Find it on your ListenBrainz settings page
+            </p>
+          </div>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
@@ -77,4 +77,9 @@ export type {
 } from "./api";
 
 // User types
-export type { AuthUser, AuthUserResponseType } from "./user";
+export type {
+  AuthUser,
+  AuthUserResponseType,
+  LastfmStatusType,
+  ListenbrainzStatusType,
+} from "./user";
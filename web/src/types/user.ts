@@ -14,3 +14,23 @@ export type AuthUser = {
 export type AuthUserResponseType = {
   user: AuthUser;
 };
+
+// Last.fm account of the user, pending counts the plays waiting to be scrobbled
+export type LastfmStatusType = {
+  configured: boolean;
+  linked: boolean;
+  username: string;
+  pending: number;
+};
+
+// ListenBrainz account of the user, pending counts the listens waiting to be submitted
+// and the import fields describe the last history import
+export type ListenbrainzStatusType = {
+  linked: boolean;
+  username: string;
+  pending: number;
+  importing: boolean;
+  imported_at: string | null;
+  imported_listens: number;
+  unmatched_listens: number;
+};